@@ -0,0 +1,452 @@
+package flux
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/drone/envsubst"
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1"
+	fluxmeta "github.com/fluxcd/pkg/apis/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// MaxArtifactSize caps the size of a downloaded source artifact, compressed and extracted.
+	MaxArtifactSize = 64 << 20
+
+	// SubstituteAnnotation disables postBuild substitution for an object when set to "disabled".
+	SubstituteAnnotation = "kustomize.toolkit.fluxcd.io/substitute"
+
+	// ReconcileAnnotation disables reconciliation of an object when set to "disabled".
+	ReconcileAnnotation = "kustomize.toolkit.fluxcd.io/reconcile"
+
+	// SSAAnnotation controls how kustomize-controller applies an object ("IfNotPresent", "Ignore").
+	SSAAnnotation = "kustomize.toolkit.fluxcd.io/ssa"
+
+	// FieldManager is the server-side apply field manager used by kustomize-controller.
+	FieldManager = "kustomize-controller"
+)
+
+// artifactRoot is where the artifact is extracted in the in-memory build filesystem.
+const artifactRoot = "/artifact"
+
+var (
+	varNamePattern = regexp.MustCompile(`^[_[:alpha:]][_[:alpha:][:digit:]]*$`)
+	bareVarPattern = regexp.MustCompile(`\$\$|\$[^{]|\$$`)
+)
+
+// SourceArtifact returns the artifact of the source a Kustomization reads from.
+func (fc *FluxClient) SourceArtifact(ctx context.Context, ks *kustomizev1.Kustomization) (*fluxmeta.Artifact, error) {
+	ref := ks.Spec.SourceRef
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = ks.Namespace
+	}
+
+	var artifact *fluxmeta.Artifact
+	switch ref.Kind {
+	case "GitRepository":
+		src, err := fc.GetGitRepository(ctx, namespace, ref.Name)
+		if err != nil {
+			return nil, err
+		}
+		artifact = src.Status.Artifact
+	case "OCIRepository":
+		src, err := fc.GetOCIRepository(ctx, namespace, ref.Name)
+		if err != nil {
+			return nil, err
+		}
+		artifact = src.Status.Artifact
+	case "Bucket":
+		src, err := fc.GetBucket(ctx, namespace, ref.Name)
+		if err != nil {
+			return nil, err
+		}
+		artifact = src.Status.Artifact
+	default:
+		return nil, fmt.Errorf("source kind %s is not supported", ref.Kind)
+	}
+	if artifact == nil || artifact.URL == "" {
+		return nil, fmt.Errorf("%s %s/%s has no artifact yet", ref.Kind, namespace, ref.Name)
+	}
+	return artifact, nil
+}
+
+// DownloadArtifact fetches a source-controller artifact and verifies it against digest
+// ("<algorithm>:<hex>"). Digests with an unsupported algorithm are not verified.
+func DownloadArtifact(ctx context.Context, httpClient *http.Client, url, digest string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("downloading %s: HTTP %d", url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxArtifactSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", url, err)
+	}
+	if len(data) > MaxArtifactSize {
+		return nil, fmt.Errorf("artifact is larger than %d MiB", MaxArtifactSize>>20)
+	}
+	if err := VerifyDigest(data, digest); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// VerifyDigest checks data against a "<algorithm>:<hex>" digest. An empty digest or an
+// algorithm other than sha256, sha384 or sha512 is accepted without verification.
+func VerifyDigest(data []byte, digest string) error {
+	algo, want, ok := strings.Cut(digest, ":")
+	if !ok {
+		return nil
+	}
+	var h hash.Hash
+	switch algo {
+	case "sha256":
+		h = sha256.New()
+	case "sha384":
+		h = sha512.New384()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return nil
+	}
+	h.Write(data)
+	if got := hex.EncodeToString(h.Sum(nil)); got != want {
+		return fmt.Errorf("artifact digest mismatch: expected %s, got %s:%s", digest, algo, got)
+	}
+	return nil
+}
+
+// PostBuildVariables returns the variables kustomize-controller substitutes after the build:
+// spec.postBuild.substituteFrom in order, then spec.postBuild.substitute, later values winning.
+// lookup returns the data of a ConfigMap or Secret in the Kustomization's namespace and
+// found=false when it does not exist; missing references are an error unless optional.
+func PostBuildVariables(ks *kustomizev1.Kustomization, lookup func(kind, name string) (map[string]string, bool, error)) (map[string]string, error) {
+	vars := map[string]string{}
+	if ks.Spec.PostBuild == nil {
+		return vars, nil
+	}
+	for _, ref := range ks.Spec.PostBuild.SubstituteFrom {
+		data, found, err := lookup(ref.Kind, ref.Name)
+		if err != nil {
+			return nil, fmt.Errorf("reading substituteFrom %s/%s: %w", ref.Kind, ref.Name, err)
+		}
+		if !found {
+			if ref.Optional {
+				continue
+			}
+			return nil, fmt.Errorf("substituteFrom %s/%s not found", ref.Kind, ref.Name)
+		}
+		for k, v := range data {
+			vars[k] = v
+		}
+	}
+	for k, v := range ks.Spec.PostBuild.Substitute {
+		vars[k] = v
+	}
+	for k := range vars {
+		if !varNamePattern.MatchString(k) {
+			return nil, fmt.Errorf("'%s' is not a valid substitution variable name", k)
+		}
+	}
+	return vars, nil
+}
+
+// BuildKustomization runs a kustomize build of spec.path inside a tar.gz artifact the way
+// kustomize-controller does: a kustomization.yaml is generated when the path has none,
+// targetNamespace, namePrefix/nameSuffix, patches, images and components are added to it,
+// postBuild variables are substituted (when spec.postBuild is set) and commonMetadata is
+// applied to every object.
+func BuildKustomization(archive []byte, ks *kustomizev1.Kustomization, vars map[string]string) ([]*unstructured.Unstructured, error) {
+	fs := filesys.MakeFsInMemory()
+	if err := extractTarGz(archive, fs, artifactRoot); err != nil {
+		return nil, err
+	}
+	dir := path.Join(artifactRoot, path.Clean("/"+ks.Spec.Path))
+	if !fs.IsDir(dir) {
+		return nil, fmt.Errorf("path %q not found in the artifact", ks.Spec.Path)
+	}
+	if err := prepareKustomization(fs, dir, ks); err != nil {
+		return nil, err
+	}
+
+	opts := krusty.MakeDefaultOptions()
+	opts.LoadRestrictions = types.LoadRestrictionsNone
+	resMap, err := krusty.MakeKustomizer(opts).Run(fs, dir)
+	if err != nil {
+		return nil, fmt.Errorf("kustomize build failed: %w", err)
+	}
+
+	var objects []*unstructured.Unstructured
+	for _, res := range resMap.Resources() {
+		data, err := res.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(data); err != nil {
+			return nil, err
+		}
+		if ks.Spec.PostBuild != nil && !substitutionDisabled(obj) {
+			if obj, err = substitute(obj, vars); err != nil {
+				return nil, err
+			}
+		}
+		if cm := ks.Spec.CommonMetadata; cm != nil {
+			obj.SetLabels(mergeStringMaps(obj.GetLabels(), cm.Labels))
+			obj.SetAnnotations(mergeStringMaps(obj.GetAnnotations(), cm.Annotations))
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+// extractTarGz writes the regular files of a tar.gz archive under root. Entries that would
+// escape root and non-regular files (symlinks, devices) are skipped.
+func extractTarGz(archive []byte, fs filesys.FileSystem, root string) error {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return fmt.Errorf("artifact is not a gzip archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+	var total int64
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("reading artifact: %w", err)
+		}
+		name := path.Clean("/" + hdr.Name)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := fs.MkdirAll(path.Join(root, name)); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			total += hdr.Size
+			if total > MaxArtifactSize {
+				return fmt.Errorf("extracted artifact is larger than %d MiB", MaxArtifactSize>>20)
+			}
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				return fmt.Errorf("reading %s: %w", hdr.Name, err)
+			}
+			if err := fs.MkdirAll(path.Join(root, path.Dir(name))); err != nil {
+				return err
+			}
+			if err := fs.WriteFile(path.Join(root, name), data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// prepareKustomization makes sure dir has a kustomization file and adds the Kustomization's
+// build options to it.
+func prepareKustomization(fs filesys.FileSystem, dir string, ks *kustomizev1.Kustomization) error {
+	file := ""
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		if fs.Exists(path.Join(dir, name)) {
+			file = path.Join(dir, name)
+			break
+		}
+	}
+
+	kus := map[string]interface{}{}
+	if file == "" {
+		file = path.Join(dir, konfig.DefaultKustomizationFileName())
+		resources, err := scanManifests(fs, dir)
+		if err != nil {
+			return err
+		}
+		kus["apiVersion"] = types.KustomizationVersion
+		kus["kind"] = types.KustomizationKind
+		kus["resources"] = resources
+	} else {
+		data, err := fs.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if err := yaml.Unmarshal(data, &kus); err != nil {
+			return fmt.Errorf("parsing %s: %w", strings.TrimPrefix(file, artifactRoot+"/"), err)
+		}
+		if kus == nil {
+			kus = map[string]interface{}{}
+		}
+	}
+
+	spec := ks.Spec
+	if spec.TargetNamespace != "" {
+		kus["namespace"] = spec.TargetNamespace
+	}
+	if spec.NamePrefix != "" {
+		kus["namePrefix"] = spec.NamePrefix
+	}
+	if spec.NameSuffix != "" {
+		kus["nameSuffix"] = spec.NameSuffix
+	}
+	for field, items := range map[string]interface{}{"patches": spec.Patches, "images": spec.Images, "components": spec.Components} {
+		extra, err := toInterfaceSlice(items)
+		if err != nil {
+			return err
+		}
+		if len(extra) > 0 {
+			existing, _ := kus[field].([]interface{})
+			kus[field] = append(existing, extra...)
+		}
+	}
+
+	data, err := yaml.Marshal(kus)
+	if err != nil {
+		return err
+	}
+	return fs.WriteFile(file, data)
+}
+
+// scanManifests lists the resources for a generated kustomization: YAML files that contain
+// Kubernetes objects, and subdirectories that have their own kustomization file.
+func scanManifests(fs filesys.FileSystem, dir string) ([]interface{}, error) {
+	var resources []string
+	err := fs.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if p == dir {
+				return nil
+			}
+			for _, name := range konfig.RecognizedKustomizationFileNames() {
+				if fs.Exists(path.Join(p, name)) {
+					resources = append(resources, strings.TrimPrefix(p, dir+"/"))
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+		if ext := path.Ext(p); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		data, err := fs.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if looksLikeManifest(data) {
+			resources = append(resources, strings.TrimPrefix(p, dir+"/"))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(resources)
+	out := make([]interface{}, len(resources))
+	for i, r := range resources {
+		out[i] = r
+	}
+	return out, nil
+}
+
+// looksLikeManifest reports whether a YAML file has at least one document with apiVersion and kind.
+func looksLikeManifest(data []byte) bool {
+	for _, doc := range strings.Split(string(data), "\n---") {
+		var m map[string]interface{}
+		if err := yaml.Unmarshal([]byte(doc), &m); err != nil {
+			continue
+		}
+		if m["apiVersion"] != nil && m["kind"] != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func toInterfaceSlice(v interface{}) ([]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out []interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func substitutionDisabled(obj *unstructured.Unstructured) bool {
+	return obj.GetLabels()[SubstituteAnnotation] == "disabled" || obj.GetAnnotations()[SubstituteAnnotation] == "disabled"
+}
+
+// substitute replaces ${var} references in the object's YAML; undefined variables without
+// a default become empty strings, as in kustomize-controller's non-strict mode.
+func substitute(obj *unstructured.Unstructured, vars map[string]string) (*unstructured.Unstructured, error) {
+	data, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return nil, err
+	}
+	// Only the braced ${var} form is substituted; bare $var (shell snippets) is left alone.
+	escaped := bareVarPattern.ReplaceAllStringFunc(string(data), func(m string) string {
+		if m == "$$" {
+			return m
+		}
+		return "$" + m
+	})
+	out, err := envsubst.Eval(escaped, func(name string) string { return vars[name] })
+	if err != nil {
+		return nil, fmt.Errorf("variable substitution failed for %s/%s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+	jsonData, err := yaml.YAMLToJSON([]byte(out))
+	if err == nil {
+		result := &unstructured.Unstructured{}
+		if err = result.UnmarshalJSON(jsonData); err == nil {
+			return result, nil
+		}
+	}
+	return nil, fmt.Errorf("variable substitution produced invalid YAML for %s/%s: %w", obj.GetKind(), obj.GetName(), err)
+}
+
+func mergeStringMaps(base, extra map[string]string) map[string]string {
+	if len(extra) == 0 {
+		return base
+	}
+	out := make(map[string]string, len(base)+len(extra))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range extra {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,228 @@
+package flux
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func makeArtifact(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+const testDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: ${replicas:=1}
+  template:
+    spec:
+      containers:
+      - name: app
+        image: web:${version}
+        command: ["sh", "-c", "echo $HOME"]
+`
+
+const testConfigMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  annotations:
+    kustomize.toolkit.fluxcd.io/substitute: disabled
+data:
+  template: "${not_substituted}"
+`
+
+func findObject(objects []*unstructured.Unstructured, kind, name string) *unstructured.Unstructured {
+	for _, obj := range objects {
+		if obj.GetKind() == kind && obj.GetName() == name {
+			return obj
+		}
+	}
+	return nil
+}
+
+func TestBuildKustomization(t *testing.T) {
+	archive := makeArtifact(t, map[string]string{
+		"apps/web/deployment.yaml": testDeployment,
+		"apps/web/settings.yaml":   testConfigMap,
+		"apps/web/README.md":       "not a manifest",
+		"apps/web/values.yaml":     "replicas: 3\n",
+		"apps/other/service.yaml":  "apiVersion: v1\nkind: Service\nmetadata:\n  name: other\n",
+	})
+	ks := &kustomizev1.Kustomization{Spec: kustomizev1.KustomizationSpec{
+		Path:            "./apps/web",
+		TargetNamespace: "shop",
+		NamePrefix:      "prod-",
+		PostBuild:       &kustomizev1.PostBuild{Substitute: map[string]string{"version": "1.2.3"}},
+		CommonMetadata:  &kustomizev1.CommonMetadata{Labels: map[string]string{"team": "shop"}},
+	}}
+
+	objects, err := BuildKustomization(archive, ks, map[string]string{"version": "1.2.3"})
+	if err != nil {
+		t.Fatalf("BuildKustomization() error = %v", err)
+	}
+	if len(objects) != 2 {
+		t.Fatalf("expected 2 objects, got %d", len(objects))
+	}
+
+	deploy := findObject(objects, "Deployment", "prod-web")
+	if deploy == nil {
+		t.Fatal("expected Deployment prod-web")
+	}
+	if deploy.GetNamespace() != "shop" || deploy.GetLabels()["team"] != "shop" {
+		t.Errorf("namespace/labels not applied: %s %v", deploy.GetNamespace(), deploy.GetLabels())
+	}
+	if replicas, _, _ := unstructured.NestedInt64(deploy.Object, "spec", "replicas"); replicas != 1 {
+		t.Errorf("expected the default replicas 1, got %d", replicas)
+	}
+	containers, _, _ := unstructured.NestedSlice(deploy.Object, "spec", "template", "spec", "containers")
+	container := containers[0].(map[string]interface{})
+	if image := container["image"]; image != "web:1.2.3" {
+		t.Errorf("expected image web:1.2.3, got %v", image)
+	}
+	if cmd := container["command"].([]interface{}); cmd[2] != "echo $HOME" {
+		t.Errorf("bare $HOME should be left alone, got %v", cmd[2])
+	}
+
+	cm := findObject(objects, "ConfigMap", "prod-settings")
+	if v, _, _ := unstructured.NestedString(cm.Object, "data", "template"); v != "${not_substituted}" {
+		t.Errorf("substitution should be disabled for the ConfigMap, got %q", v)
+	}
+
+	ks.Spec.Path = "./missing"
+	if _, err := BuildKustomization(archive, ks, nil); err == nil || !strings.Contains(err.Error(), "not found in the artifact") {
+		t.Errorf("expected a missing path error, got %v", err)
+	}
+}
+
+func TestBuildKustomizationWithKustomizationFile(t *testing.T) {
+	archive := makeArtifact(t, map[string]string{
+		"base/kustomization.yaml":    "resources:\n- deployment.yaml\n",
+		"base/deployment.yaml":       testDeployment,
+		"overlay/kustomization.yaml": "resources:\n- ../base\nnamespace: staging\n",
+	})
+	ks := &kustomizev1.Kustomization{Spec: kustomizev1.KustomizationSpec{Path: "overlay"}}
+	objects, err := BuildKustomization(archive, ks, nil)
+	if err != nil {
+		t.Fatalf("BuildKustomization() error = %v", err)
+	}
+	if len(objects) != 1 || objects[0].GetNamespace() != "staging" {
+		t.Fatalf("expected one object in staging, got %v", objects)
+	}
+	// Without spec.postBuild nothing is substituted.
+	if replicas, _, _ := unstructured.NestedString(objects[0].Object, "spec", "replicas"); replicas != "${replicas:=1}" {
+		t.Errorf("expected an unsubstituted replicas field, got %q", replicas)
+	}
+}
+
+func TestPostBuildVariables(t *testing.T) {
+	ks := &kustomizev1.Kustomization{Spec: kustomizev1.KustomizationSpec{PostBuild: &kustomizev1.PostBuild{
+		Substitute: map[string]string{"region": "eu-west-1"},
+		SubstituteFrom: []kustomizev1.SubstituteReference{
+			{Kind: "ConfigMap", Name: "cluster-vars"},
+			{Kind: "Secret", Name: "cluster-secrets"},
+			{Kind: "ConfigMap", Name: "optional-vars", Optional: true},
+		},
+	}}}
+	data := map[string]map[string]string{
+		"ConfigMap/cluster-vars": {"region": "us-east-1", "cluster": "prod"},
+		"Secret/cluster-secrets": {"token": "abc", "cluster": "prod-2"},
+	}
+	lookup := func(kind, name string) (map[string]string, bool, error) {
+		d, ok := data[kind+"/"+name]
+		return d, ok, nil
+	}
+
+	vars, err := PostBuildVariables(ks, lookup)
+	if err != nil {
+		t.Fatalf("PostBuildVariables() error = %v", err)
+	}
+	if vars["region"] != "eu-west-1" || vars["cluster"] != "prod-2" || vars["token"] != "abc" {
+		t.Errorf("unexpected variables %v", vars)
+	}
+
+	ks.Spec.PostBuild.SubstituteFrom[2].Optional = false
+	if _, err := PostBuildVariables(ks, lookup); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected a missing reference error, got %v", err)
+	}
+
+	ks.Spec.PostBuild.SubstituteFrom = nil
+	ks.Spec.PostBuild.Substitute["bad-name"] = "x"
+	if _, err := PostBuildVariables(ks, lookup); err == nil || !strings.Contains(err.Error(), "bad-name") {
+		t.Errorf("expected an invalid variable name error, got %v", err)
+	}
+}
+
+func TestDownloadArtifact(t *testing.T) {
+	archive := makeArtifact(t, map[string]string{"app/cm.yaml": testConfigMap})
+	sum := sha256.Sum256(archive)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/gitrepository/flux-system/app/abc.tar.gz" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(archive)
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	data, err := DownloadArtifact(ctx, server.Client(), server.URL+"/gitrepository/flux-system/app/abc.tar.gz", digest)
+	if err != nil || !bytes.Equal(data, archive) {
+		t.Fatalf("DownloadArtifact() error = %v", err)
+	}
+	if _, err := DownloadArtifact(ctx, server.Client(), server.URL+"/gitrepository/flux-system/app/abc.tar.gz", "sha256:00"); err == nil || !strings.Contains(err.Error(), "digest mismatch") {
+		t.Errorf("expected a digest mismatch, got %v", err)
+	}
+	if _, err := DownloadArtifact(ctx, server.Client(), server.URL+"/missing.tar.gz", ""); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected an HTTP 404 error, got %v", err)
+	}
+}
+
+func TestVerifyDigest(t *testing.T) {
+	data := []byte("artifact")
+	sum := sha256.Sum256(data)
+	if err := VerifyDigest(data, "sha256:"+hex.EncodeToString(sum[:])); err != nil {
+		t.Errorf("VerifyDigest() error = %v", err)
+	}
+	if err := VerifyDigest(data, ""); err != nil {
+		t.Errorf("an empty digest should be accepted, got %v", err)
+	}
+	if err := VerifyDigest(data, "blake3:abcd"); err != nil {
+		t.Errorf("an unsupported algorithm should be accepted, got %v", err)
+	}
+	if err := VerifyDigest(data, "sha512:abcd"); err == nil {
+		t.Error("expected a sha512 mismatch")
+	}
+}
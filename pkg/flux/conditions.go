@@ -2,6 +2,7 @@ package flux
 
 import (
 	fluxmeta "github.com/fluxcd/pkg/apis/meta"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/health"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -18,38 +19,26 @@ const (
 )
 
 // GetFluxHealth interprets Flux status conditions into a single health status.
-// The evaluation order follows Flux conventions: Suspended > Stalled > Reconciling > Ready.
+// Suspension is Flux-specific and checked first; everything else is computed by
+// health.FromConditions, so Flux objects follow the same kstatus rules as any
+// other resource: Stalled > Reconciling > Ready.
 func GetFluxHealth(conditions []metav1.Condition, generation, observedGeneration int64, suspended bool) FluxHealthStatus {
 	if suspended {
 		return HealthSuspended
 	}
 
-	stalled := findCondition(conditions, fluxmeta.StalledCondition)
-	if stalled != nil && stalled.Status == metav1.ConditionTrue {
-		return HealthStalled
-	}
-
-	reconciling := findCondition(conditions, fluxmeta.ReconcilingCondition)
-	if reconciling != nil && reconciling.Status == metav1.ConditionTrue {
-		return HealthReconciling
-	}
-
-	ready := findCondition(conditions, fluxmeta.ReadyCondition)
-	if ready == nil {
-		return HealthUnknown
-	}
-
-	if ready.Status == metav1.ConditionTrue {
-		if generation != observedGeneration {
-			return HealthReconciling
+	res := health.FromConditions(conditions, generation, observedGeneration)
+	switch res.Status {
+	case health.Failed:
+		if stalled := findCondition(conditions, fluxmeta.StalledCondition); stalled != nil && stalled.Status == metav1.ConditionTrue {
+			return HealthStalled
 		}
-		return HealthReady
-	}
-
-	if ready.Status == metav1.ConditionFalse {
 		return HealthFailed
+	case health.InProgress:
+		return HealthReconciling
+	case health.Current:
+		return HealthReady
 	}
-
 	return HealthUnknown
 }
 
@@ -0,0 +1,55 @@
+package flux
+
+import (
+	"fmt"
+	"strings"
+
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
+)
+
+// ParseInventoryID parses a Flux inventory entry ID of the form
+// <namespace>_<name>_<group>_<kind> together with the entry's API version.
+// Cluster-scoped objects have an empty namespace and core objects an empty
+// group; colons in names (RBAC system: roles) are encoded as "__".
+func ParseInventoryID(id, version string) (k8s.ObjectRef, error) {
+	invalid := fmt.Errorf("invalid inventory ID %q: expected <namespace>_<name>_<group>_<kind>", id)
+
+	first := strings.Index(id, "_")
+	last := strings.LastIndex(id, "_")
+	if first < 0 || last <= first {
+		return k8s.ObjectRef{}, invalid
+	}
+	namespace, kind := id[:first], id[last+1:]
+	rest := id[first+1 : last]
+
+	sep := strings.LastIndex(rest, "_")
+	if sep < 0 {
+		return k8s.ObjectRef{}, invalid
+	}
+	name, group := strings.ReplaceAll(rest[:sep], "__", ":"), rest[sep+1:]
+	if name == "" || kind == "" {
+		return k8s.ObjectRef{}, invalid
+	}
+	return k8s.ObjectRef{Group: group, Version: version, Kind: kind, Namespace: namespace, Name: name}, nil
+}
+
+// KustomizationInventory returns the objects recorded in a Kustomization's
+// inventory, along with any entry IDs that could not be parsed.
+func KustomizationInventory(ks *kustomizev1.Kustomization) ([]k8s.ObjectRef, []string) {
+	if ks.Status.Inventory == nil {
+		return nil, nil
+	}
+	var refs []k8s.ObjectRef
+	var invalid []string
+	for _, entry := range ks.Status.Inventory.Entries {
+		ref, err := ParseInventoryID(entry.ID, entry.Version)
+		if err != nil {
+			invalid = append(invalid, entry.ID)
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	return refs, invalid
+}
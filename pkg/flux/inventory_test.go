@@ -0,0 +1,57 @@
+package flux
+
+import (
+	"testing"
+
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
+)
+
+func TestParseInventoryID(t *testing.T) {
+	tests := []struct {
+		id, version string
+		want        k8s.ObjectRef
+		wantErr     bool
+	}{
+		{id: "shop_web_apps_Deployment", version: "v1", want: k8s.ObjectRef{Group: "apps", Version: "v1", Kind: "Deployment", Namespace: "shop", Name: "web"}},
+		{id: "shop_web__Service", version: "v1", want: k8s.ObjectRef{Version: "v1", Kind: "Service", Namespace: "shop", Name: "web"}},
+		{id: "_shop__Namespace", version: "v1", want: k8s.ObjectRef{Version: "v1", Kind: "Namespace", Name: "shop"}},
+		{id: "_system__controller__kube-scheduler_rbac.authorization.k8s.io_ClusterRole", version: "v1",
+			want: k8s.ObjectRef{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole", Name: "system:controller:kube-scheduler"}},
+		{id: "garbage", wantErr: true},
+		{id: "shop_Deployment", wantErr: true},
+		{id: "shop__apps_Deployment", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseInventoryID(tt.id, tt.version)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseInventoryID(%q) expected an error, got %+v", tt.id, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseInventoryID(%q) = %+v, %v; want %+v", tt.id, got, err, tt.want)
+		}
+	}
+}
+
+func TestKustomizationInventory(t *testing.T) {
+	ks := &kustomizev1.Kustomization{Status: kustomizev1.KustomizationStatus{Inventory: &kustomizev1.ResourceInventory{
+		Entries: []kustomizev1.ResourceRef{
+			{ID: "shop_web_apps_Deployment", Version: "v1"},
+			{ID: "not-an-id", Version: "v1"},
+		},
+	}}}
+	refs, invalid := KustomizationInventory(ks)
+	if len(refs) != 1 || refs[0].String() != "Deployment/shop/web" {
+		t.Errorf("unexpected refs: %+v", refs)
+	}
+	if len(invalid) != 1 || invalid[0] != "not-an-id" {
+		t.Errorf("unexpected invalid IDs: %v", invalid)
+	}
+	if refs, invalid := KustomizationInventory(&kustomizev1.Kustomization{}); refs != nil || invalid != nil {
+		t.Error("expected no refs for a Kustomization without inventory")
+	}
+}
@@ -0,0 +1,257 @@
+package health
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// failingWaitReasons are container waiting reasons that will not clear without
+// a change to the pod spec, image, registry or configuration.
+var failingWaitReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+	"RunContainerError":          true,
+}
+
+// Pod computes pod health. Succeeded pods are Current, Running pods are Current
+// when every container is ready, and containers stuck in CrashLoopBackOff,
+// image pull or config errors make the pod Failed.
+func Pod(p *corev1.Pod) Result {
+	if p.DeletionTimestamp != nil {
+		return terminating(p.DeletionTimestamp, p.Finalizers)
+	}
+
+	switch p.Status.Phase {
+	case corev1.PodSucceeded:
+		return result(Current, "PodCompleted", "pod ran to completion")
+	case corev1.PodFailed:
+		return Result{Status: Failed, Reason: valueOr(p.Status.Reason, "PodFailed"), Message: p.Status.Message}
+	}
+
+	statuses := append(append([]corev1.ContainerStatus{}, p.Status.InitContainerStatuses...), p.Status.ContainerStatuses...)
+	for _, cs := range statuses {
+		if w := cs.State.Waiting; w != nil && failingWaitReasons[w.Reason] {
+			msg := fmt.Sprintf("container %s is waiting", cs.Name)
+			if w.Message != "" {
+				msg += ": " + w.Message
+			}
+			return Result{Status: Failed, Reason: w.Reason, Message: msg}
+		}
+	}
+
+	switch p.Status.Phase {
+	case corev1.PodRunning:
+		ready := 0
+		for _, cs := range p.Status.ContainerStatuses {
+			if cs.Ready && cs.State.Waiting == nil {
+				ready++
+			}
+		}
+		if ready < len(p.Status.ContainerStatuses) {
+			return result(InProgress, "ContainersNotReady", "%d/%d containers ready", ready, len(p.Status.ContainerStatuses))
+		}
+		return Result{Status: Current}
+	case corev1.PodPending:
+		for _, c := range p.Status.Conditions {
+			if c.Type == corev1.PodScheduled && c.Status == corev1.ConditionFalse {
+				return Result{Status: InProgress, Reason: valueOr(c.Reason, "PodNotScheduled"), Message: c.Message}
+			}
+		}
+		return result(InProgress, "PodPending", "pod is pending")
+	}
+	return result(Unknown, "PodPhaseUnknown", "pod phase is %q", p.Status.Phase)
+}
+
+// Deployment computes deployment health from the rollout counters. A rollout
+// that exceeded its progress deadline, or a ReplicaFailure, is Failed.
+func Deployment(d *appsv1.Deployment) Result {
+	if d.DeletionTimestamp != nil {
+		return terminating(d.DeletionTimestamp, d.Finalizers)
+	}
+	if d.Status.ObservedGeneration < d.Generation {
+		return generationLag(d.Generation, d.Status.ObservedGeneration)
+	}
+	for _, c := range d.Status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing && c.Status == corev1.ConditionFalse && c.Reason == "ProgressDeadlineExceeded" {
+			return Result{Status: Failed, Reason: c.Reason, Message: c.Message}
+		}
+		if c.Type == appsv1.DeploymentReplicaFailure && c.Status == corev1.ConditionTrue {
+			return Result{Status: Failed, Reason: valueOr(c.Reason, "ReplicaFailure"), Message: c.Message}
+		}
+	}
+
+	desired := replicasOrOne(d.Spec.Replicas)
+	s := d.Status
+	switch {
+	case s.UpdatedReplicas < desired:
+		return result(InProgress, "RolloutInProgress", "%d/%d replicas updated", s.UpdatedReplicas, desired)
+	case s.Replicas > s.UpdatedReplicas:
+		return result(InProgress, "RolloutInProgress", "%d old replicas pending termination", s.Replicas-s.UpdatedReplicas)
+	case s.AvailableReplicas < desired:
+		return result(InProgress, "ReplicasUnavailable", "%d/%d replicas available", s.AvailableReplicas, desired)
+	case s.ReadyReplicas < desired:
+		return result(InProgress, "ReplicasNotReady", "%d/%d replicas ready", s.ReadyReplicas, desired)
+	}
+	return Result{Status: Current}
+}
+
+// StatefulSet computes statefulset health, honouring OnDelete updates and
+// rolling update partitions.
+func StatefulSet(s *appsv1.StatefulSet) Result {
+	if s.DeletionTimestamp != nil {
+		return terminating(s.DeletionTimestamp, s.Finalizers)
+	}
+	if s.Status.ObservedGeneration < s.Generation {
+		return generationLag(s.Generation, s.Status.ObservedGeneration)
+	}
+
+	desired := replicasOrOne(s.Spec.Replicas)
+	if s.Status.ReadyReplicas < desired {
+		return result(InProgress, "ReplicasNotReady", "%d/%d replicas ready", s.Status.ReadyReplicas, desired)
+	}
+	if s.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+		return Result{Status: Current}
+	}
+	if ru := s.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.Partition != nil && *ru.Partition > 0 {
+		if want := desired - *ru.Partition; s.Status.UpdatedReplicas < want {
+			return result(InProgress, "PartitionRollout", "%d/%d replicas above partition %d updated", s.Status.UpdatedReplicas, want, *ru.Partition)
+		}
+		return Result{Status: Current}
+	}
+	if s.Status.UpdateRevision != "" && s.Status.CurrentRevision != s.Status.UpdateRevision {
+		return result(InProgress, "RolloutInProgress", "%d/%d replicas updated to revision %s", s.Status.UpdatedReplicas, desired, s.Status.UpdateRevision)
+	}
+	return Result{Status: Current}
+}
+
+// DaemonSet computes daemonset health from the scheduled, updated and available counters.
+func DaemonSet(d *appsv1.DaemonSet) Result {
+	if d.DeletionTimestamp != nil {
+		return terminating(d.DeletionTimestamp, d.Finalizers)
+	}
+	if d.Status.ObservedGeneration < d.Generation {
+		return generationLag(d.Generation, d.Status.ObservedGeneration)
+	}
+
+	s := d.Status
+	switch {
+	case s.UpdatedNumberScheduled < s.DesiredNumberScheduled:
+		return result(InProgress, "RolloutInProgress", "%d/%d nodes updated", s.UpdatedNumberScheduled, s.DesiredNumberScheduled)
+	case s.NumberAvailable < s.DesiredNumberScheduled:
+		return result(InProgress, "PodsUnavailable", "%d/%d pods available", s.NumberAvailable, s.DesiredNumberScheduled)
+	case s.NumberReady < s.DesiredNumberScheduled:
+		return result(InProgress, "PodsNotReady", "%d/%d pods ready", s.NumberReady, s.DesiredNumberScheduled)
+	}
+	return Result{Status: Current}
+}
+
+// ReplicaSet computes replicaset health.
+func ReplicaSet(rs *appsv1.ReplicaSet) Result {
+	if rs.DeletionTimestamp != nil {
+		return terminating(rs.DeletionTimestamp, rs.Finalizers)
+	}
+	if rs.Status.ObservedGeneration < rs.Generation {
+		return generationLag(rs.Generation, rs.Status.ObservedGeneration)
+	}
+	for _, c := range rs.Status.Conditions {
+		if c.Type == appsv1.ReplicaSetReplicaFailure && c.Status == corev1.ConditionTrue {
+			return Result{Status: Failed, Reason: valueOr(c.Reason, "ReplicaFailure"), Message: c.Message}
+		}
+	}
+	desired := replicasOrOne(rs.Spec.Replicas)
+	if rs.Status.AvailableReplicas < desired {
+		return result(InProgress, "ReplicasUnavailable", "%d/%d replicas available", rs.Status.AvailableReplicas, desired)
+	}
+	return Result{Status: Current}
+}
+
+// Job computes job health: Complete is Current, Failed is Failed, a suspended
+// job is Current and anything else is still running.
+func Job(j *batchv1.Job) Result {
+	if j.DeletionTimestamp != nil {
+		return terminating(j.DeletionTimestamp, j.Finalizers)
+	}
+	for _, c := range j.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobFailed:
+			return Result{Status: Failed, Reason: valueOr(c.Reason, "JobFailed"), Message: c.Message}
+		case batchv1.JobComplete:
+			return result(Current, "JobComplete", "%d pods succeeded", j.Status.Succeeded)
+		case batchv1.JobSuspended:
+			return result(Current, "JobSuspended", "job is suspended")
+		}
+	}
+	completions := int32(1)
+	if j.Spec.Completions != nil {
+		completions = *j.Spec.Completions
+	}
+	return result(InProgress, "JobRunning", "%d active, %d/%d succeeded, %d failed",
+		j.Status.Active, j.Status.Succeeded, completions, j.Status.Failed)
+}
+
+// PVC computes persistent volume claim health from its phase.
+func PVC(pvc *corev1.PersistentVolumeClaim) Result {
+	if pvc.DeletionTimestamp != nil {
+		return terminating(pvc.DeletionTimestamp, pvc.Finalizers)
+	}
+	switch pvc.Status.Phase {
+	case corev1.ClaimBound:
+		return Result{Status: Current}
+	case corev1.ClaimLost:
+		return result(Failed, "ClaimLost", "bound volume %s no longer exists", pvc.Spec.VolumeName)
+	case corev1.ClaimPending:
+		return result(InProgress, "ClaimPending", "waiting for a volume to be provisioned or bound")
+	}
+	return result(Unknown, "ClaimPhaseUnknown", "claim phase is %q", pvc.Status.Phase)
+}
+
+// Node computes node health from the kubelet's Ready condition. A node whose
+// kubelet stopped posting status (Ready=Unknown) is Unknown rather than Failed,
+// since the node controller cannot tell a network partition from a dead node.
+func Node(n *corev1.Node) Result {
+	if n.DeletionTimestamp != nil {
+		return terminating(n.DeletionTimestamp, n.Finalizers)
+	}
+	for _, c := range n.Status.Conditions {
+		if c.Type != corev1.NodeReady {
+			continue
+		}
+		switch c.Status {
+		case corev1.ConditionTrue:
+			return Result{Status: Current}
+		case corev1.ConditionFalse:
+			return Result{Status: Failed, Reason: valueOr(c.Reason, "NodeNotReady"), Message: c.Message}
+		}
+		return Result{Status: Unknown, Reason: valueOr(c.Reason, "NodeStatusUnknown"), Message: c.Message}
+	}
+	return result(Unknown, ReasonNoReadyCondition, "node has not reported a Ready condition")
+}
+
+// Service computes service health. Only LoadBalancer services can be in
+// progress, while waiting for the cloud provider to assign an address.
+func Service(svc *corev1.Service) Result {
+	if svc.DeletionTimestamp != nil {
+		return terminating(svc.DeletionTimestamp, svc.Finalizers)
+	}
+	if svc.Spec.Type == corev1.ServiceTypeLoadBalancer && len(svc.Status.LoadBalancer.Ingress) == 0 {
+		return result(InProgress, "LoadBalancerPending", "waiting for the load balancer address")
+	}
+	return Result{Status: Current}
+}
+
+func replicasOrOne(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
@@ -0,0 +1,227 @@
+package health
+
+import (
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Status is the computed health of an object, following the kstatus conventions
+// (sigs.k8s.io/cli-utils/pkg/kstatus).
+type Status string
+
+const (
+	// Current means the object is fully reconciled and its desired state is met.
+	Current Status = "Current"
+	// InProgress means the object is still being reconciled (rollout, binding, provisioning).
+	InProgress Status = "InProgress"
+	// Failed means the controller gave up or the object reports an error that will not resolve itself.
+	Failed Status = "Failed"
+	// Terminating means the object has a deletion timestamp.
+	Terminating Status = "Terminating"
+	// Unknown means the status could not be determined.
+	Unknown Status = "Unknown"
+)
+
+// Condition types used by the kstatus conventions.
+const (
+	ConditionReady       = "Ready"
+	ConditionStalled     = "Stalled"
+	ConditionReconciling = "Reconciling"
+)
+
+// Reasons set by FromConditions and Compute when no condition reason applies.
+const (
+	ReasonStalled                     = "Stalled"
+	ReasonReconciling                 = "Reconciling"
+	ReasonNoReadyCondition            = "NoReadyCondition"
+	ReasonLatestGenerationNotObserved = "LatestGenerationNotObserved"
+	ReasonDeletionRequested           = "DeletionRequested"
+	ReasonConversionFailed            = "ConversionFailed"
+)
+
+// Result is the computed health of a single object.
+type Result struct {
+	Status  Status
+	Reason  string
+	Message string
+}
+
+// Healthy reports whether the object is Current.
+func (r Result) Healthy() bool {
+	return r.Status == Current
+}
+
+// String renders the result as "Status (Reason): Message", omitting empty parts.
+func (r Result) String() string {
+	s := string(r.Status)
+	if r.Reason != "" {
+		s += " (" + r.Reason + ")"
+	}
+	if r.Message != "" {
+		s += ": " + r.Message
+	}
+	return s
+}
+
+func result(status Status, reason, format string, args ...interface{}) Result {
+	return Result{Status: status, Reason: reason, Message: fmt.Sprintf(format, args...)}
+}
+
+// FromConditions computes health for objects whose controller reports a Ready
+// condition (Flux, cert-manager, Cluster API and most operator CRDs).
+// Evaluation order: Stalled=True is Failed, Reconciling=True is InProgress,
+// a missing Ready condition is Unknown, an unobserved generation is InProgress,
+// and finally Ready decides. Ready=False after the controller has observed the
+// latest generation is treated as Failed, where kstatus would report InProgress,
+// because the controller has finished its attempt and reported an error.
+// Pass observedGeneration < 0 when the object does not report one.
+func FromConditions(conditions []metav1.Condition, generation, observedGeneration int64) Result {
+	if c := findCondition(conditions, ConditionStalled); c != nil && c.Status == metav1.ConditionTrue {
+		return Result{Status: Failed, Reason: valueOr(c.Reason, ReasonStalled), Message: c.Message}
+	}
+	if c := findCondition(conditions, ConditionReconciling); c != nil && c.Status == metav1.ConditionTrue {
+		return Result{Status: InProgress, Reason: valueOr(c.Reason, ReasonReconciling), Message: c.Message}
+	}
+
+	ready := findCondition(conditions, ConditionReady)
+	if ready == nil {
+		return result(Unknown, ReasonNoReadyCondition, "controller has not reported a Ready condition")
+	}
+	if observedGeneration >= 0 && generation != observedGeneration {
+		return generationLag(generation, observedGeneration)
+	}
+
+	switch ready.Status {
+	case metav1.ConditionTrue:
+		return Result{Status: Current, Reason: ready.Reason, Message: ready.Message}
+	case metav1.ConditionFalse:
+		return Result{Status: Failed, Reason: ready.Reason, Message: ready.Message}
+	default:
+		return Result{Status: InProgress, Reason: ready.Reason, Message: ready.Message}
+	}
+}
+
+// Compute returns the health of any object. Deletion is checked first, built-in
+// kinds use their own rules, and everything else falls back to the generic
+// observedGeneration and Ready/Stalled/Reconciling condition checks.
+func Compute(obj *unstructured.Unstructured) Result {
+	if ts := obj.GetDeletionTimestamp(); ts != nil {
+		return terminating(ts, obj.GetFinalizers())
+	}
+
+	gvk := obj.GroupVersionKind()
+	switch gvk.Group + "/" + gvk.Kind {
+	case "/Pod":
+		return computeTyped(obj, &corev1.Pod{}, func(o *corev1.Pod) Result { return Pod(o) })
+	case "/PersistentVolumeClaim":
+		return computeTyped(obj, &corev1.PersistentVolumeClaim{}, func(o *corev1.PersistentVolumeClaim) Result { return PVC(o) })
+	case "/Node":
+		return computeTyped(obj, &corev1.Node{}, func(o *corev1.Node) Result { return Node(o) })
+	case "/Service":
+		return computeTyped(obj, &corev1.Service{}, func(o *corev1.Service) Result { return Service(o) })
+	case "apps/Deployment":
+		return computeTyped(obj, &appsv1.Deployment{}, func(o *appsv1.Deployment) Result { return Deployment(o) })
+	case "apps/StatefulSet":
+		return computeTyped(obj, &appsv1.StatefulSet{}, func(o *appsv1.StatefulSet) Result { return StatefulSet(o) })
+	case "apps/DaemonSet":
+		return computeTyped(obj, &appsv1.DaemonSet{}, func(o *appsv1.DaemonSet) Result { return DaemonSet(o) })
+	case "apps/ReplicaSet":
+		return computeTyped(obj, &appsv1.ReplicaSet{}, func(o *appsv1.ReplicaSet) Result { return ReplicaSet(o) })
+	case "batch/Job":
+		return computeTyped(obj, &batchv1.Job{}, func(o *batchv1.Job) Result { return Job(o) })
+	}
+	return generic(obj)
+}
+
+func computeTyped[T any](obj *unstructured.Unstructured, into *T, fn func(*T) Result) Result {
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, into); err != nil {
+		return result(Unknown, ReasonConversionFailed, "cannot read %s status: %v", obj.GetKind(), err)
+	}
+	return fn(into)
+}
+
+// generic applies the kstatus rules for kinds without built-in knowledge.
+// Objects without any of the standard conditions (ConfigMaps, RBAC, most
+// CRDs without a controller) are Current once their generation is observed.
+func generic(obj *unstructured.Unstructured) Result {
+	conditions := unstructuredConditions(obj)
+	generation := obj.GetGeneration()
+	observed, found, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	if !found {
+		observed = -1
+		if ready := findCondition(conditions, ConditionReady); ready != nil && ready.ObservedGeneration > 0 {
+			observed = ready.ObservedGeneration
+		}
+	}
+
+	for _, t := range []string{ConditionReady, ConditionStalled, ConditionReconciling} {
+		if findCondition(conditions, t) != nil {
+			return FromConditions(conditions, generation, observed)
+		}
+	}
+	if observed >= 0 && generation != observed {
+		return generationLag(generation, observed)
+	}
+	return Result{Status: Current}
+}
+
+// unstructuredConditions reads status.conditions leniently; entries without a
+// type are skipped and non-string fields are ignored.
+func unstructuredConditions(obj *unstructured.Unstructured) []metav1.Condition {
+	raw, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	var out []metav1.Condition
+	for _, item := range raw {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		c := metav1.Condition{}
+		c.Type, _ = m["type"].(string)
+		if c.Type == "" {
+			continue
+		}
+		status, _ := m["status"].(string)
+		c.Status = metav1.ConditionStatus(status)
+		c.Reason, _ = m["reason"].(string)
+		c.Message, _ = m["message"].(string)
+		c.ObservedGeneration, _, _ = unstructured.NestedInt64(m, "observedGeneration")
+		out = append(out, c)
+	}
+	return out
+}
+
+func generationLag(generation, observed int64) Result {
+	return result(InProgress, ReasonLatestGenerationNotObserved,
+		"controller has not observed the latest spec (generation %d, observed %d)", generation, observed)
+}
+
+func terminating(ts *metav1.Time, finalizers []string) Result {
+	if len(finalizers) > 0 {
+		return result(Terminating, ReasonDeletionRequested, "deletion requested at %s, waiting on finalizers: %s",
+			ts.UTC().Format("2006-01-02T15:04:05Z"), strings.Join(finalizers, ", "))
+	}
+	return result(Terminating, ReasonDeletionRequested, "deletion requested at %s", ts.UTC().Format("2006-01-02T15:04:05Z"))
+}
+
+func findCondition(conditions []metav1.Condition, condType string) *metav1.Condition {
+	for i := range conditions {
+		if conditions[i].Type == condType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+func valueOr(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
@@ -0,0 +1,212 @@
+package health
+
+import (
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func int32Ptr(i int32) *int32 { return &i }
+
+func TestFromConditions(t *testing.T) {
+	ready := func(s metav1.ConditionStatus) metav1.Condition {
+		return metav1.Condition{Type: ConditionReady, Status: s, Reason: "Reason"}
+	}
+	tests := []struct {
+		name           string
+		conditions     []metav1.Condition
+		gen, observed  int64
+		want           Status
+		wantReasonPart string
+	}{
+		{name: "ready", conditions: []metav1.Condition{ready(metav1.ConditionTrue)}, gen: 1, observed: 1, want: Current},
+		{name: "not ready", conditions: []metav1.Condition{ready(metav1.ConditionFalse)}, gen: 1, observed: 1, want: Failed},
+		{name: "ready unknown", conditions: []metav1.Condition{ready(metav1.ConditionUnknown)}, gen: 1, observed: 1, want: InProgress},
+		{name: "generation lag", conditions: []metav1.Condition{ready(metav1.ConditionTrue)}, gen: 3, observed: 2, want: InProgress, wantReasonPart: ReasonLatestGenerationNotObserved},
+		{name: "no observed generation", conditions: []metav1.Condition{ready(metav1.ConditionTrue)}, gen: 3, observed: -1, want: Current},
+		{name: "no ready", gen: 1, observed: 0, want: Unknown, wantReasonPart: ReasonNoReadyCondition},
+		{name: "stalled wins", conditions: []metav1.Condition{
+			{Type: ConditionReconciling, Status: metav1.ConditionTrue},
+			{Type: ConditionStalled, Status: metav1.ConditionTrue, Reason: "DependencyNotReady"},
+			ready(metav1.ConditionFalse),
+		}, gen: 2, observed: 1, want: Failed, wantReasonPart: "DependencyNotReady"},
+		{name: "reconciling", conditions: []metav1.Condition{
+			{Type: ConditionReconciling, Status: metav1.ConditionTrue},
+			ready(metav1.ConditionFalse),
+		}, gen: 1, observed: 1, want: InProgress, wantReasonPart: ReasonReconciling},
+	}
+	for _, tt := range tests {
+		got := FromConditions(tt.conditions, tt.gen, tt.observed)
+		if got.Status != tt.want || !strings.Contains(got.Reason, tt.wantReasonPart) {
+			t.Errorf("%s: FromConditions() = %+v, want %s (%s)", tt.name, got, tt.want, tt.wantReasonPart)
+		}
+	}
+}
+
+func TestPod(t *testing.T) {
+	running := func(statuses ...corev1.ContainerStatus) *corev1.Pod {
+		return &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: statuses}}
+	}
+	crashing := corev1.ContainerStatus{Name: "app", State: corev1.ContainerState{
+		Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff", Message: "back-off 5m0s"}}}
+	now := metav1.Now()
+
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want Status
+	}{
+		{"running ready", running(corev1.ContainerStatus{Name: "app", Ready: true}), Current},
+		{"running not ready", running(corev1.ContainerStatus{Name: "app"}), InProgress},
+		{"crash loop", running(crashing), Failed},
+		{"succeeded", &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodSucceeded}}, Current},
+		{"failed", &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted"}}, Failed},
+		{"unschedulable", &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodPending, Conditions: []corev1.PodCondition{
+			{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: "Unschedulable"}}}}, InProgress},
+		{"init image pull", &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodPending, InitContainerStatuses: []corev1.ContainerStatus{
+			{Name: "init", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}}}}}, Failed},
+		{"terminating", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now}, Status: corev1.PodStatus{Phase: corev1.PodRunning}}, Terminating},
+		{"unknown", &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodUnknown}}, Unknown},
+	}
+	for _, tt := range tests {
+		if got := Pod(tt.pod); got.Status != tt.want {
+			t.Errorf("%s: Pod() = %+v, want %s", tt.name, got, tt.want)
+		}
+	}
+	if got := Pod(running(crashing)); got.Reason != "CrashLoopBackOff" || !strings.Contains(got.Message, "container app") {
+		t.Errorf("expected the crash-looping container in the result, got %+v", got)
+	}
+}
+
+func TestWorkloads(t *testing.T) {
+	deploy := func(status appsv1.DeploymentStatus, conds ...appsv1.DeploymentCondition) *appsv1.Deployment {
+		status.ObservedGeneration = 2
+		status.Conditions = conds
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Generation: 2}, Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(3)}, Status: status}
+	}
+	healthy := appsv1.DeploymentStatus{Replicas: 3, UpdatedReplicas: 3, ReadyReplicas: 3, AvailableReplicas: 3}
+
+	if got := Deployment(deploy(healthy)); got.Status != Current {
+		t.Errorf("healthy deployment: %+v", got)
+	}
+	if got := Deployment(deploy(appsv1.DeploymentStatus{Replicas: 4, UpdatedReplicas: 2, ReadyReplicas: 3, AvailableReplicas: 3})); got.Status != InProgress || got.Reason != "RolloutInProgress" {
+		t.Errorf("rolling deployment: %+v", got)
+	}
+	stuck := appsv1.DeploymentCondition{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"}
+	if got := Deployment(deploy(appsv1.DeploymentStatus{Replicas: 3, UpdatedReplicas: 1}, stuck)); got.Status != Failed {
+		t.Errorf("deadline exceeded deployment: %+v", got)
+	}
+	lagging := deploy(healthy)
+	lagging.Generation = 3
+	if got := Deployment(lagging); got.Reason != ReasonLatestGenerationNotObserved {
+		t.Errorf("lagging deployment: %+v", got)
+	}
+
+	sts := &appsv1.StatefulSet{
+		Spec:   appsv1.StatefulSetSpec{Replicas: int32Ptr(3)},
+		Status: appsv1.StatefulSetStatus{ReadyReplicas: 3, UpdatedReplicas: 1, CurrentRevision: "web-1", UpdateRevision: "web-2"},
+	}
+	if got := StatefulSet(sts); got.Status != InProgress {
+		t.Errorf("statefulset mid-rollout: %+v", got)
+	}
+	sts.Spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateStatefulSetStrategy{Partition: int32Ptr(2)}
+	if got := StatefulSet(sts); got.Status != Current {
+		t.Errorf("statefulset with partition reached: %+v", got)
+	}
+
+	ds := &appsv1.DaemonSet{Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 4, UpdatedNumberScheduled: 4, NumberAvailable: 3, NumberReady: 3}}
+	if got := DaemonSet(ds); got.Status != InProgress || got.Message != "3/4 pods available" {
+		t.Errorf("daemonset: %+v", got)
+	}
+
+	job := &batchv1.Job{Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{
+		{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded"}}}}
+	if got := Job(job); got.Status != Failed || got.Reason != "BackoffLimitExceeded" {
+		t.Errorf("failed job: %+v", got)
+	}
+	if got := Job(&batchv1.Job{Status: batchv1.JobStatus{Active: 1}}); got.Status != InProgress {
+		t.Errorf("running job: %+v", got)
+	}
+
+	if got := PVC(&corev1.PersistentVolumeClaim{Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimLost}}); got.Status != Failed {
+		t.Errorf("lost PVC: %+v", got)
+	}
+	node := func(status corev1.ConditionStatus, reason string) *corev1.Node {
+		return &corev1.Node{Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse},
+			{Type: corev1.NodeReady, Status: status, Reason: reason},
+		}}}
+	}
+	if got := Node(node(corev1.ConditionTrue, "KubeletReady")); got.Status != Current {
+		t.Errorf("ready node: %+v", got)
+	}
+	if got := Node(node(corev1.ConditionFalse, "KubeletNotReady")); got.Status != Failed || got.Reason != "KubeletNotReady" {
+		t.Errorf("not ready node: %+v", got)
+	}
+	if got := Node(node(corev1.ConditionUnknown, "")); got.Status != Unknown || got.Reason != "NodeStatusUnknown" {
+		t.Errorf("unreachable node: %+v", got)
+	}
+	if got := Node(&corev1.Node{}); got.Reason != ReasonNoReadyCondition {
+		t.Errorf("node without conditions: %+v", got)
+	}
+
+	lb := &corev1.Service{Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer}}
+	if got := Service(lb); got.Status != InProgress || got.Reason != "LoadBalancerPending" {
+		t.Errorf("pending load balancer: %+v", got)
+	}
+}
+
+func TestCompute(t *testing.T) {
+	obj := func(apiVersion, kind string, content map[string]interface{}) *unstructured.Unstructured {
+		o := &unstructured.Unstructured{Object: content}
+		o.SetAPIVersion(apiVersion)
+		o.SetKind(kind)
+		o.SetName("x")
+		return o
+	}
+
+	deploy := obj("apps/v1", "Deployment", map[string]interface{}{
+		"spec":   map[string]interface{}{"replicas": int64(2)},
+		"status": map[string]interface{}{"replicas": int64(2), "updatedReplicas": int64(2), "readyReplicas": int64(1), "availableReplicas": int64(1)},
+	})
+	if got := Compute(deploy); got.Status != InProgress || got.Message != "1/2 replicas available" {
+		t.Errorf("unstructured deployment: %+v", got)
+	}
+
+	cert := obj("cert-manager.io/v1", "Certificate", map[string]interface{}{
+		"status": map[string]interface{}{"conditions": []interface{}{
+			map[string]interface{}{"type": "Ready", "status": "False", "reason": "DoesNotExist", "message": "Issuing certificate"},
+		}},
+	})
+	if got := Compute(cert); got.Status != Failed || got.String() != "Failed (DoesNotExist): Issuing certificate" {
+		t.Errorf("certificate: %+v", got)
+	}
+
+	cert.SetGeneration(2)
+	_ = unstructured.SetNestedField(cert.Object, int64(1), "status", "observedGeneration")
+	if got := Compute(cert); got.Status != InProgress {
+		t.Errorf("certificate with generation lag: %+v", got)
+	}
+
+	if got := Compute(obj("v1", "ConfigMap", map[string]interface{}{"data": map[string]interface{}{"a": "b"}})); got.Status != Current {
+		t.Errorf("configmap: %+v", got)
+	}
+
+	deleting := obj("v1", "ConfigMap", map[string]interface{}{})
+	ts := metav1.Now()
+	deleting.SetDeletionTimestamp(&ts)
+	deleting.SetFinalizers([]string{"example.com/cleanup"})
+	if got := Compute(deleting); got.Status != Terminating || !strings.Contains(got.Message, "example.com/cleanup") {
+		t.Errorf("terminating: %+v", got)
+	}
+
+	bad := obj("apps/v1", "Deployment", map[string]interface{}{"spec": map[string]interface{}{"replicas": "two"}})
+	if got := Compute(bad); got.Status != Unknown || got.Reason != ReasonConversionFailed {
+		t.Errorf("malformed deployment: %+v", got)
+	}
+}
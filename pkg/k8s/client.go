@@ -6,7 +6,9 @@ import (
 	"path/filepath"
 
 	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	metricsv "k8s.io/metrics/pkg/client/clientset/versioned"
@@ -17,6 +19,8 @@ type ClusterClient struct {
 	Clientset           kubernetes.Interface
 	MetricsClient       metricsv.Interface
 	ApiextensionsClient apiextensionsclient.Interface
+	DynamicClient       dynamic.Interface
+	MetadataClient      metadata.Interface
 	Config              *rest.Config
 	ContextName         string
 }
@@ -54,10 +58,18 @@ func NewClusterClient(contextName string) (*ClusterClient, error) {
 	// API extensions client for CRDs; may not be available
 	apiextClient, _ := apiextensionsclient.NewForConfig(config)
 
+	// Dynamic client for custom resources (snapshots, backups, etc.)
+	dynamicClient, _ := dynamic.NewForConfig(config)
+
+	// Metadata-only client for objects whose contents must not be read (Secrets)
+	metadataClient, _ := metadata.NewForConfig(config)
+
 	return &ClusterClient{
 		Clientset:           clientset,
 		MetricsClient:       metricsClient,
 		ApiextensionsClient: apiextClient,
+		DynamicClient:       dynamicClient,
+		MetadataClient:      metadataClient,
 		Config:              config,
 		ContextName:         contextName,
 	}, nil
@@ -82,6 +94,16 @@ func NewClusterClientForTestingWithApiext(clientset kubernetes.Interface, metric
 	}
 }
 
+// NewClusterClientForTestingWithDynamic creates a ClusterClient with injected fakes including apiextensions and dynamic clients for unit tests.
+func NewClusterClientForTestingWithDynamic(clientset kubernetes.Interface, apiextClient apiextensionsclient.Interface, dynamicClient dynamic.Interface) *ClusterClient {
+	return &ClusterClient{
+		Clientset:           clientset,
+		ApiextensionsClient: apiextClient,
+		DynamicClient:       dynamicClient,
+		ContextName:         "test-context",
+	}
+}
+
 // ListAvailableContexts returns all contexts from the kubeconfig file
 // and the name of the current context.
 func ListAvailableContexts() ([]string, string, error) {
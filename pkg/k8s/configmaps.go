@@ -0,0 +1,18 @@
+package k8s
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+// GetConfigMap returns a single ConfigMap.
+func (c *ClusterClient) GetConfigMap(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error) {
+	ctx, cancel := context.WithTimeout(ctx, util.DefaultTimeout)
+	defer cancel()
+
+	return c.Clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+}
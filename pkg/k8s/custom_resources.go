@@ -0,0 +1,62 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+// ListCustomResources returns objects of the given resource in a namespace (empty for all namespaces).
+func (c *ClusterClient) ListCustomResources(ctx context.Context, gvr schema.GroupVersionResource, namespace string) ([]unstructured.Unstructured, error) {
+	if c.DynamicClient == nil {
+		return nil, fmt.Errorf("dynamic client not available")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, util.DefaultTimeout)
+	defer cancel()
+
+	list, err := c.DynamicClient.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// FindCRD returns the CRD with the given name (e.g. "volumesnapshots.snapshot.storage.k8s.io"), or nil if absent.
+func FindCRD(crds []apiextensionsv1.CustomResourceDefinition, name string) *apiextensionsv1.CustomResourceDefinition {
+	for i := range crds {
+		if crds[i].Name == name {
+			return &crds[i]
+		}
+	}
+	return nil
+}
+
+// CRDGroupVersionResource returns the GVR for a CRD using its storage version (falling back to the first served version).
+func CRDGroupVersionResource(crd *apiextensionsv1.CustomResourceDefinition) schema.GroupVersionResource {
+	version := ""
+	for _, v := range crd.Spec.Versions {
+		if v.Storage {
+			version = v.Name
+			break
+		}
+		if version == "" && v.Served {
+			version = v.Name
+		}
+	}
+	return schema.GroupVersionResource{Group: crd.Spec.Group, Version: version, Resource: crd.Spec.Names.Plural}
+}
+
+// APIServiceGVR identifies aggregated APIService objects (apiregistration.k8s.io).
+var APIServiceGVR = schema.GroupVersionResource{Group: "apiregistration.k8s.io", Version: "v1", Resource: "apiservices"}
+
+// ListAPIServices returns aggregated APIServices as unstructured objects.
+func (c *ClusterClient) ListAPIServices(ctx context.Context) ([]unstructured.Unstructured, error) {
+	return c.ListCustomResources(ctx, APIServiceGVR, "")
+}
@@ -0,0 +1,97 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestListCustomResources(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "snapshot.storage.k8s.io", Version: "v1", Resource: "volumesnapshots"}
+	snap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "snapshot.storage.k8s.io/v1",
+		"kind":       "VolumeSnapshot",
+		"metadata":   map[string]interface{}{"name": "data-snap", "namespace": "default"},
+		"spec": map[string]interface{}{
+			"source": map[string]interface{}{"persistentVolumeClaimName": "data"},
+		},
+	}}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "VolumeSnapshotList"}, snap)
+
+	client := NewClusterClientForTestingWithDynamic(fake.NewSimpleClientset(), nil, dyn)
+
+	items, err := client.ListCustomResources(context.Background(), gvr, "default")
+	if err != nil {
+		t.Fatalf("ListCustomResources() error = %v", err)
+	}
+	if len(items) != 1 || items[0].GetName() != "data-snap" {
+		t.Errorf("unexpected items: %+v", items)
+	}
+}
+
+func TestListCustomResourcesNoDynamicClient(t *testing.T) {
+	client := NewClusterClientForTesting(fake.NewSimpleClientset(), nil)
+
+	_, err := client.ListCustomResources(context.Background(), schema.GroupVersionResource{Resource: "x"}, "")
+	if err == nil {
+		t.Error("expected error when dynamic client is nil")
+	}
+}
+
+func TestCRDGroupVersionResource(t *testing.T) {
+	crds := []apiextensionsv1.CustomResourceDefinition{{
+		ObjectMeta: metav1.ObjectMeta{Name: "backups.velero.io"},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: "velero.io",
+			Names: apiextensionsv1.CustomResourceDefinitionNames{Plural: "backups"},
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+				{Name: "v1beta1", Served: true},
+				{Name: "v1", Served: true, Storage: true},
+			},
+		},
+	}}
+
+	if FindCRD(crds, "schedules.velero.io") != nil {
+		t.Error("expected nil for missing CRD")
+	}
+	crd := FindCRD(crds, "backups.velero.io")
+	if crd == nil {
+		t.Fatal("expected to find backups.velero.io")
+	}
+	gvr := CRDGroupVersionResource(crd)
+	want := schema.GroupVersionResource{Group: "velero.io", Version: "v1", Resource: "backups"}
+	if gvr != want {
+		t.Errorf("got %v, want %v", gvr, want)
+	}
+}
+
+func TestListAPIServices(t *testing.T) {
+	apiService := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiregistration.k8s.io/v1",
+		"kind":       "APIService",
+		"metadata":   map[string]interface{}{"name": "v1beta1.metrics.k8s.io"},
+		"spec": map[string]interface{}{
+			"service": map[string]interface{}{"name": "metrics-server", "namespace": "kube-system"},
+		},
+	}}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{APIServiceGVR: "APIServiceList"}, apiService)
+
+	client := NewClusterClientForTestingWithDynamic(fake.NewSimpleClientset(), nil, dyn)
+
+	items, err := client.ListAPIServices(context.Background())
+	if err != nil {
+		t.Fatalf("ListAPIServices() error = %v", err)
+	}
+	if len(items) != 1 || items[0].GetName() != "v1beta1.metrics.k8s.io" {
+		t.Errorf("unexpected APIServices: %+v", items)
+	}
+}
@@ -0,0 +1,584 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+const (
+	// CoreDNSNamespace is where the cluster DNS add-on runs.
+	CoreDNSNamespace = "kube-system"
+	// DefaultClusterDomain is used when the Corefile does not name one.
+	DefaultClusterDomain = "cluster.local"
+	// DefaultNdots is the ndots value kubelet writes into pod resolv.conf.
+	DefaultNdots = 5
+)
+
+// coreDNSConfigMaps lists the ConfigMaps holding the Corefile, in lookup order.
+// coredns-custom is the AKS extension point for *.server and *.override snippets.
+var coreDNSConfigMaps = []string{"coredns", "coredns-custom"}
+
+// DNSIssue is a problem found in cluster DNS configuration.
+type DNSIssue struct {
+	Severity string
+	Problem  string
+}
+
+// --- Corefile parsing ---
+
+// CorefilePlugin is a single plugin directive in a Corefile server block.
+type CorefilePlugin struct {
+	Name  string
+	Args  []string
+	Block []string // raw lines inside the plugin's { } block
+}
+
+// Line renders the directive on one line, e.g. "forward . /etc/resolv.conf".
+func (p CorefilePlugin) Line() string {
+	return strings.TrimSpace(p.Name + " " + strings.Join(p.Args, " "))
+}
+
+// CorefileServer is a server block: the zones it serves and its plugin chain.
+type CorefileServer struct {
+	Zones   []string
+	Port    string
+	Plugins []CorefilePlugin
+}
+
+// Plugin returns the first directive with the given name, or nil.
+func (s *CorefileServer) Plugin(name string) *CorefilePlugin {
+	for i := range s.Plugins {
+		if s.Plugins[i].Name == name {
+			return &s.Plugins[i]
+		}
+	}
+	return nil
+}
+
+// IsRoot reports whether the block serves the root zone.
+func (s *CorefileServer) IsRoot() bool {
+	for _, z := range s.Zones {
+		if z == "." {
+			return true
+		}
+	}
+	return false
+}
+
+// Corefile is a parsed CoreDNS configuration.
+type Corefile struct {
+	Source  string // ConfigMap and key the servers came from
+	Servers []CorefileServer
+}
+
+// CorefileDirective is a plugin directive together with the zones of its server block.
+type CorefileDirective struct {
+	Zones  []string
+	Plugin CorefilePlugin
+}
+
+// Directives returns every directive named name across all server blocks.
+func (cf *Corefile) Directives(name string) []CorefileDirective {
+	var out []CorefileDirective
+	for _, s := range cf.Servers {
+		for _, p := range s.Plugins {
+			if p.Name == name {
+				out = append(out, CorefileDirective{Zones: s.Zones, Plugin: p})
+			}
+		}
+	}
+	return out
+}
+
+// ClusterDomain returns the first zone of the kubernetes plugin, or DefaultClusterDomain.
+func (cf *Corefile) ClusterDomain() string {
+	for _, d := range cf.Directives("kubernetes") {
+		if len(d.Plugin.Args) > 0 {
+			return strings.TrimSuffix(d.Plugin.Args[0], ".")
+		}
+	}
+	return DefaultClusterDomain
+}
+
+// StubDomains returns forward directives in server blocks for zones other than the root:
+// the Corefile equivalent of kube-dns stubDomains.
+func (cf *Corefile) StubDomains() []CorefileDirective {
+	var out []CorefileDirective
+	for _, s := range cf.Servers {
+		if s.IsRoot() {
+			continue
+		}
+		for _, p := range s.Plugins {
+			if p.Name == "forward" {
+				out = append(out, CorefileDirective{Zones: s.Zones, Plugin: p})
+			}
+		}
+	}
+	return out
+}
+
+// ParseCorefile parses Corefile text into server blocks. It understands the subset of
+// Caddyfile syntax CoreDNS configurations use: comments, server blocks with zone:port
+// keys, and one level of plugin sub-blocks.
+func ParseCorefile(text string) (*Corefile, error) {
+	cf := &Corefile{}
+	var server *CorefileServer
+	var plugin *CorefilePlugin
+	for n, raw := range strings.Split(text, "\n") {
+		line := raw
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		opens := strings.HasSuffix(line, "{")
+		body := strings.TrimSpace(strings.TrimSuffix(line, "{"))
+
+		switch {
+		case line == "}":
+			if plugin != nil {
+				plugin = nil
+			} else if server != nil {
+				cf.Servers = append(cf.Servers, *server)
+				server = nil
+			} else {
+				return nil, fmt.Errorf("line %d: unexpected '}'", n+1)
+			}
+		case server == nil:
+			if !opens {
+				return nil, fmt.Errorf("line %d: expected a server block, got %q", n+1, line)
+			}
+			server = &CorefileServer{}
+			for _, key := range strings.Fields(body) {
+				zone, port := key, "53"
+				if i := strings.LastIndex(key, ":"); i >= 0 {
+					zone, port = key[:i], key[i+1:]
+				}
+				zone = strings.TrimPrefix(strings.TrimPrefix(zone, "dns://"), ".")
+				if zone == "" {
+					zone = "."
+				}
+				server.Zones = append(server.Zones, zone)
+				server.Port = port
+			}
+		case plugin != nil:
+			plugin.Block = append(plugin.Block, line)
+		default:
+			fields := strings.Fields(body)
+			if len(fields) == 0 {
+				return nil, fmt.Errorf("line %d: empty directive", n+1)
+			}
+			server.Plugins = append(server.Plugins, CorefilePlugin{Name: fields[0], Args: fields[1:]})
+			if opens {
+				plugin = &server.Plugins[len(server.Plugins)-1]
+			}
+		}
+	}
+	if server != nil {
+		return nil, fmt.Errorf("unterminated server block for %s", strings.Join(server.Zones, " "))
+	}
+	return cf, nil
+}
+
+// GetCorefile reads and parses the CoreDNS configuration from kube-system. Server blocks
+// from coredns-custom (*.server keys) are appended after the main Corefile.
+func (c *ClusterClient) GetCorefile(ctx context.Context) (*Corefile, error) {
+	ctx, cancel := context.WithTimeout(ctx, util.DefaultTimeout)
+	defer cancel()
+
+	cm, err := c.Clientset.CoreV1().ConfigMaps(CoreDNSNamespace).Get(ctx, coreDNSConfigMaps[0], metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	cf, err := ParseCorefile(cm.Data["Corefile"])
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s/%s Corefile: %w", CoreDNSNamespace, cm.Name, err)
+	}
+	cf.Source = CoreDNSNamespace + "/" + cm.Name
+
+	if custom, err := c.Clientset.CoreV1().ConfigMaps(CoreDNSNamespace).Get(ctx, coreDNSConfigMaps[1], metav1.GetOptions{}); err == nil {
+		keys := make([]string, 0, len(custom.Data))
+		for key := range custom.Data {
+			if strings.HasSuffix(key, ".server") {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if extra, err := ParseCorefile(custom.Data[key]); err == nil {
+				cf.Servers = append(cf.Servers, extra.Servers...)
+			}
+		}
+	}
+	return cf, nil
+}
+
+// CheckCorefile flags configurations that break or degrade cluster DNS.
+func CheckCorefile(cf *Corefile) []DNSIssue {
+	var issues []DNSIssue
+	if len(cf.Directives("kubernetes")) == 0 {
+		issues = append(issues, DNSIssue{"CRITICAL", "No server block loads the kubernetes plugin — Service and Pod names will not resolve"})
+	}
+
+	var root *CorefileServer
+	for i := range cf.Servers {
+		if cf.Servers[i].IsRoot() {
+			root = &cf.Servers[i]
+			break
+		}
+	}
+	if root == nil {
+		issues = append(issues, DNSIssue{"WARNING", "No server block for the root zone '.' — names outside the configured zones get REFUSED"})
+		return issues
+	}
+
+	if fwd := root.Plugin("forward"); fwd == nil && root.Plugin("proxy") == nil {
+		issues = append(issues, DNSIssue{"WARNING", "Root server block has no forward plugin — external names cannot be resolved"})
+	} else if fwd != nil && len(fwd.Args) > 1 {
+		for _, target := range fwd.Args[1:] {
+			if ip := net.ParseIP(strings.TrimPrefix(target, "dns://")); ip != nil && ip.IsLoopback() {
+				issues = append(issues, DNSIssue{"CRITICAL", fmt.Sprintf("Root zone forwards to loopback %s — CoreDNS will forward to itself and loop", target)})
+			}
+		}
+	}
+
+	if cache := root.Plugin("cache"); cache == nil {
+		issues = append(issues, DNSIssue{"WARNING", "Root server block has no cache plugin — every query is sent upstream"})
+	} else if len(cache.Args) > 0 {
+		if ttl, err := strconv.Atoi(cache.Args[0]); err == nil && ttl > 300 {
+			issues = append(issues, DNSIssue{"INFO", fmt.Sprintf("Cache TTL is %ds — changes to Services or external records may be served stale for minutes", ttl)})
+		}
+	}
+	if root.Plugin("loop") == nil {
+		issues = append(issues, DNSIssue{"INFO", "loop plugin is not enabled — forwarding loops will not be detected"})
+	}
+	if root.Plugin("health") == nil || root.Plugin("ready") == nil {
+		issues = append(issues, DNSIssue{"INFO", "health or ready plugin missing — CoreDNS liveness/readiness probes may fail"})
+	}
+	if root.Plugin("log") != nil {
+		issues = append(issues, DNSIssue{"INFO", "log plugin is enabled — every query is logged, which is costly at scale"})
+	}
+	for _, d := range cf.Directives("rewrite") {
+		issues = append(issues, DNSIssue{"INFO", fmt.Sprintf("Rewrite rule in %s: %s — can make names resolve differently than expected",
+			strings.Join(d.Zones, " "), d.Plugin.Line())})
+	}
+	return issues
+}
+
+// --- NodeLocal DNSCache ---
+
+// NodeLocalDNSInfo describes a NodeLocal DNSCache deployment.
+type NodeLocalDNSInfo struct {
+	Name    string
+	Desired int32
+	Ready   int32
+	LocalIP string // link-local address pods are pointed at, from the bind directive
+}
+
+// GetNodeLocalDNS returns the NodeLocal DNSCache DaemonSet in kube-system, or nil when the
+// cache is not installed.
+func (c *ClusterClient) GetNodeLocalDNS(ctx context.Context) (*NodeLocalDNSInfo, error) {
+	dss, err := c.ListDaemonSets(ctx, CoreDNSNamespace, metav1.ListOptions{LabelSelector: "k8s-app=node-local-dns"})
+	if err != nil {
+		return nil, err
+	}
+	if len(dss) == 0 {
+		all, err := c.ListDaemonSets(ctx, CoreDNSNamespace, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, ds := range all {
+			if strings.Contains(ds.Name, "node-local-dns") || strings.Contains(ds.Name, "nodelocaldns") {
+				dss = append(dss, ds)
+			}
+		}
+	}
+	if len(dss) == 0 {
+		return nil, nil
+	}
+
+	ds := dss[0]
+	info := &NodeLocalDNSInfo{Name: ds.Name, Desired: ds.Status.DesiredNumberScheduled, Ready: ds.Status.NumberReady}
+	cmCtx, cancel := context.WithTimeout(ctx, util.DefaultTimeout)
+	defer cancel()
+	if cm, err := c.Clientset.CoreV1().ConfigMaps(CoreDNSNamespace).Get(cmCtx, "node-local-dns", metav1.GetOptions{}); err == nil {
+		if cf, err := ParseCorefile(cm.Data["Corefile"]); err == nil {
+			if binds := cf.Directives("bind"); len(binds) > 0 && len(binds[0].Plugin.Args) > 0 {
+				info.LocalIP = binds[0].Plugin.Args[0]
+			}
+		}
+	}
+	return info, nil
+}
+
+// --- Pod DNS settings ---
+
+// PodDNSSettings is the resolver configuration a pod ends up with.
+type PodDNSSettings struct {
+	Policy      string // effective policy after hostNetwork adjustment
+	UsesCluster bool   // queries go to cluster DNS
+	Nameservers []string
+	Searches    []string
+	Ndots       int
+	// QueriesPerExternalLookup is the worst-case number of queries (A and AAAA) for an
+	// external name with fewer dots than ndots that only resolves as an absolute name.
+	QueriesPerExternalLookup int
+}
+
+// EffectivePodDNS computes a pod's resolver settings the way kubelet writes resolv.conf.
+// Node search domains are not visible through the API and are not included.
+func EffectivePodDNS(pod *corev1.Pod, clusterDomain string) PodDNSSettings {
+	policy := pod.Spec.DNSPolicy
+	if policy == "" {
+		policy = corev1.DNSClusterFirst
+	}
+	s := PodDNSSettings{Policy: string(policy), Ndots: DefaultNdots}
+	switch {
+	case policy == corev1.DNSClusterFirstWithHostNet,
+		policy == corev1.DNSClusterFirst && !pod.Spec.HostNetwork:
+		s.Policy = string(corev1.DNSClusterFirst)
+		s.UsesCluster = true
+		s.Searches = []string{pod.Namespace + ".svc." + clusterDomain, "svc." + clusterDomain, clusterDomain}
+	case policy == corev1.DNSClusterFirst:
+		// hostNetwork pods silently fall back to the node's resolver
+		s.Policy = string(corev1.DNSDefault)
+		s.Ndots = 1
+	default:
+		s.Ndots = 1
+	}
+
+	if cfg := pod.Spec.DNSConfig; cfg != nil {
+		s.Nameservers = append(s.Nameservers, cfg.Nameservers...)
+		s.Searches = append(s.Searches, cfg.Searches...)
+		for _, opt := range cfg.Options {
+			if opt.Name == "ndots" && opt.Value != nil {
+				if n, err := strconv.Atoi(*opt.Value); err == nil {
+					s.Ndots = n
+				}
+			}
+		}
+	}
+	s.QueriesPerExternalLookup = 2 * (len(s.Searches) + 1)
+	return s
+}
+
+// AnalyzePodDNS flags dnsPolicy and dnsConfig settings that break cluster name resolution
+// or multiply query load through search-path expansion.
+func AnalyzePodDNS(pod *corev1.Pod, clusterDomain string) (PodDNSSettings, []DNSIssue) {
+	s := EffectivePodDNS(pod, clusterDomain)
+	var issues []DNSIssue
+	switch {
+	case pod.Spec.HostNetwork && (pod.Spec.DNSPolicy == "" || pod.Spec.DNSPolicy == corev1.DNSClusterFirst):
+		issues = append(issues, DNSIssue{"WARNING", "hostNetwork pod with dnsPolicy ClusterFirst uses the node's resolver — Service names will not resolve; use ClusterFirstWithHostNet"})
+	case pod.Spec.DNSPolicy == corev1.DNSNone && len(s.Nameservers) == 0:
+		issues = append(issues, DNSIssue{"CRITICAL", "dnsPolicy None without dnsConfig.nameservers — the pod has no resolver"})
+	case pod.Spec.DNSPolicy == corev1.DNSNone && !s.UsesCluster:
+		issues = append(issues, DNSIssue{"INFO", fmt.Sprintf("dnsPolicy None with nameservers %s — cluster names resolve only if these point at cluster DNS",
+			strings.Join(s.Nameservers, ", "))})
+	case pod.Spec.DNSPolicy == corev1.DNSDefault:
+		issues = append(issues, DNSIssue{"INFO", "dnsPolicy Default uses the node's resolver — Service names will not resolve"})
+	}
+	if s.Ndots > DefaultNdots {
+		issues = append(issues, DNSIssue{"WARNING", fmt.Sprintf("ndots:%d is above the default %d — more names walk the whole search path before being tried as absolute", s.Ndots, DefaultNdots)})
+	}
+	if len(s.Searches) > 6 {
+		issues = append(issues, DNSIssue{"WARNING", fmt.Sprintf("%d search domains — older resolvers (musl, glibc < 2.26) ignore entries beyond 6", len(s.Searches))})
+	}
+	if s.UsesCluster && s.QueriesPerExternalLookup > 2*(3+1) {
+		issues = append(issues, DNSIssue{"WARNING", fmt.Sprintf("Search-path amplification: an external lookup can cost up to %d queries (%d search domains x A/AAAA)",
+			s.QueriesPerExternalLookup, len(s.Searches))})
+	}
+	return s, issues
+}
+
+// --- Resolution ---
+
+// DNSQueryResult is the outcome of one A query.
+type DNSQueryResult struct {
+	Name     string
+	RCode    string // NOERROR, NXDOMAIN, SERVFAIL, ... or empty when the query failed
+	Answers  []string
+	Err      error
+	Duration time.Duration
+}
+
+// Resolved reports whether the query returned at least one address.
+func (r DNSQueryResult) Resolved() bool {
+	return r.Err == nil && r.RCode == "NOERROR" && len(r.Answers) > 0
+}
+
+// QueryDNS sends a single A query for fqdn to server (host or host:port) over UDP,
+// retrying over TCP when the answer is truncated.
+func QueryDNS(ctx context.Context, server, fqdn string) DNSQueryResult {
+	res := DNSQueryResult{Name: fqdn}
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+	name, err := dnsmessage.NewName(dnsFQDN(fqdn))
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	msg := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: uint16(time.Now().UnixNano()), RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET}},
+	}
+	query, err := msg.Pack()
+	if err != nil {
+		res.Err = err
+		return res
+	}
+
+	start := time.Now()
+	reply, err := exchangeDNS(ctx, "udp", server, query)
+	if err == nil {
+		var hdr dnsmessage.Header
+		var p dnsmessage.Parser
+		if hdr, err = p.Start(reply); err == nil && hdr.Truncated {
+			reply, err = exchangeDNS(ctx, "tcp", server, query)
+		}
+	}
+	res.Duration = time.Since(start)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+
+	var p dnsmessage.Parser
+	hdr, err := p.Start(reply)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	res.RCode = rcodeName(hdr.RCode)
+	if err := p.SkipAllQuestions(); err != nil {
+		res.Err = err
+		return res
+	}
+	answers, err := p.AllAnswers()
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	for _, a := range answers {
+		switch body := a.Body.(type) {
+		case *dnsmessage.AResource:
+			res.Answers = append(res.Answers, net.IP(body.A[:]).String())
+		case *dnsmessage.CNAMEResource:
+			res.Answers = append(res.Answers, "CNAME "+body.CNAME.String())
+		}
+	}
+	return res
+}
+
+// ResolveWithSearch resolves name the way a pod's stub resolver would: names with at least
+// ndots dots (or a trailing dot) are tried as absolute first, others walk the search list
+// first. It stops at the first query that returns an address, or at the first transport
+// error since the server is then unreachable, and returns every query made.
+func ResolveWithSearch(ctx context.Context, server, name string, searches []string, ndots int) []DNSQueryResult {
+	var candidates []string
+	if strings.HasSuffix(name, ".") {
+		candidates = []string{name}
+	} else {
+		var searched []string
+		for _, s := range searches {
+			searched = append(searched, name+"."+strings.TrimSuffix(s, "."))
+		}
+		if strings.Count(name, ".") >= ndots {
+			candidates = append([]string{name}, searched...)
+		} else {
+			candidates = append(searched, name)
+		}
+	}
+
+	var results []DNSQueryResult
+	for _, c := range candidates {
+		r := QueryDNS(ctx, server, c)
+		results = append(results, r)
+		if r.Resolved() || r.Err != nil {
+			break
+		}
+	}
+	return results
+}
+
+func exchangeDNS(ctx context.Context, network, server string, query []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, network, server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if network == "tcp" {
+		framed := make([]byte, 2+len(query))
+		framed[0], framed[1] = byte(len(query)>>8), byte(len(query))
+		copy(framed[2:], query)
+		if _, err := conn.Write(framed); err != nil {
+			return nil, err
+		}
+		var size [2]byte
+		if _, err := io.ReadFull(conn, size[:]); err != nil {
+			return nil, err
+		}
+		buf := make([]byte, int(size[0])<<8|int(size[1]))
+		_, err := io.ReadFull(conn, buf)
+		return buf, err
+	}
+
+	if _, err := conn.Write(query); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// rcodeName returns the conventional (dig-style) name of a response code.
+func rcodeName(rc dnsmessage.RCode) string {
+	switch rc {
+	case dnsmessage.RCodeSuccess:
+		return "NOERROR"
+	case dnsmessage.RCodeFormatError:
+		return "FORMERR"
+	case dnsmessage.RCodeServerFailure:
+		return "SERVFAIL"
+	case dnsmessage.RCodeNameError:
+		return "NXDOMAIN"
+	case dnsmessage.RCodeNotImplemented:
+		return "NOTIMP"
+	case dnsmessage.RCodeRefused:
+		return "REFUSED"
+	}
+	return fmt.Sprintf("RCODE%d", rc)
+}
+
+func dnsFQDN(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
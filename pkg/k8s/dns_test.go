@@ -0,0 +1,219 @@
+package k8s
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const testCorefile = `.:53 {
+    errors
+    health {
+       lameduck 5s
+    }
+    ready
+    kubernetes cluster.local in-addr.arpa ip6.arpa {
+       pods insecure
+       fallthrough in-addr.arpa ip6.arpa
+    }
+    rewrite name legacy.shop.svc.cluster.local web.shop.svc.cluster.local
+    forward . /etc/resolv.conf {
+       max_concurrent 1000
+    }
+    cache 30
+    loop
+    reload
+}
+corp.example.com:53 {
+    forward . 10.1.0.10 10.1.0.11
+    cache 600
+}
+`
+
+func TestParseCorefile(t *testing.T) {
+	cf, err := ParseCorefile(testCorefile)
+	if err != nil {
+		t.Fatalf("ParseCorefile() error = %v", err)
+	}
+	if len(cf.Servers) != 2 {
+		t.Fatalf("expected 2 server blocks, got %d", len(cf.Servers))
+	}
+	root := cf.Servers[0]
+	if !root.IsRoot() || root.Port != "53" {
+		t.Errorf("unexpected root block: %+v", root)
+	}
+	if fwd := root.Plugin("forward"); fwd == nil || fwd.Line() != "forward . /etc/resolv.conf" || len(fwd.Block) != 1 {
+		t.Errorf("unexpected forward directive: %+v", fwd)
+	}
+	if got := cf.ClusterDomain(); got != "cluster.local" {
+		t.Errorf("ClusterDomain() = %q", got)
+	}
+	stubs := cf.StubDomains()
+	if len(stubs) != 1 || stubs[0].Zones[0] != "corp.example.com" || len(stubs[0].Plugin.Args) != 3 {
+		t.Errorf("unexpected stub domains: %+v", stubs)
+	}
+
+	issues := CheckCorefile(cf)
+	if len(issues) != 1 || issues[0].Severity != "INFO" {
+		t.Errorf("expected only the rewrite note, got %+v", issues)
+	}
+
+	if _, err := ParseCorefile(".:53 {\n    errors\n"); err == nil {
+		t.Error("expected an error for an unterminated server block")
+	}
+}
+
+func TestCheckCorefileProblems(t *testing.T) {
+	cf, err := ParseCorefile(".:53 {\n    errors\n    forward . 127.0.0.1\n}\n")
+	if err != nil {
+		t.Fatalf("ParseCorefile() error = %v", err)
+	}
+	critical := 0
+	for _, i := range CheckCorefile(cf) {
+		if i.Severity == "CRITICAL" {
+			critical++
+		}
+	}
+	// Missing kubernetes plugin and a forwarding loop to loopback.
+	if critical != 2 {
+		t.Errorf("expected 2 critical issues, got %+v", CheckCorefile(cf))
+	}
+}
+
+func TestGetCorefileAndNodeLocalDNS(t *testing.T) {
+	client := NewClusterClientForTesting(fake.NewSimpleClientset(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: "kube-system"},
+			Data: map[string]string{"Corefile": testCorefile}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "coredns-custom", Namespace: "kube-system"},
+			Data: map[string]string{"internal.server": "internal.io:53 {\n    forward . 10.2.0.10\n}\n", "log.override": "log"}},
+	), nil)
+
+	cf, err := client.GetCorefile(context.Background())
+	if err != nil {
+		t.Fatalf("GetCorefile() error = %v", err)
+	}
+	if len(cf.Servers) != 3 || len(cf.StubDomains()) != 2 {
+		t.Errorf("expected coredns-custom server block to be merged, got %+v", cf.Servers)
+	}
+
+	info, err := client.GetNodeLocalDNS(context.Background())
+	if err != nil || info != nil {
+		t.Errorf("expected no NodeLocal DNSCache, got %+v, %v", info, err)
+	}
+}
+
+func TestAnalyzePodDNS(t *testing.T) {
+	five, eight := "5", "8"
+	tests := []struct {
+		name       string
+		spec       corev1.PodSpec
+		wantPolicy string
+		wantIssues int
+		wantCount  int
+	}{
+		{"default", corev1.PodSpec{}, "ClusterFirst", 0, 8},
+		{"hostNetwork without ClusterFirstWithHostNet", corev1.PodSpec{HostNetwork: true}, "Default", 1, 2},
+		{"ClusterFirstWithHostNet", corev1.PodSpec{HostNetwork: true, DNSPolicy: corev1.DNSClusterFirstWithHostNet}, "ClusterFirst", 0, 8},
+		{"None without nameservers", corev1.PodSpec{DNSPolicy: corev1.DNSNone}, "None", 1, 2},
+		{"high ndots and extra searches", corev1.PodSpec{DNSConfig: &corev1.PodDNSConfig{
+			Searches: []string{"corp.example.com", "example.com"},
+			Options:  []corev1.PodDNSConfigOption{{Name: "ndots", Value: &eight}},
+		}}, "ClusterFirst", 2, 12},
+		{"explicit default ndots", corev1.PodSpec{DNSConfig: &corev1.PodDNSConfig{
+			Options: []corev1.PodDNSConfigOption{{Name: "ndots", Value: &five}},
+		}}, "ClusterFirst", 0, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p", Namespace: "shop"}, Spec: tt.spec}
+			s, issues := AnalyzePodDNS(pod, "cluster.local")
+			if s.Policy != tt.wantPolicy || len(issues) != tt.wantIssues || s.QueriesPerExternalLookup != tt.wantCount {
+				t.Errorf("got policy=%s queries=%d issues=%+v", s.Policy, s.QueriesPerExternalLookup, issues)
+			}
+		})
+	}
+}
+
+// startTestDNSServer runs a stand-in DNS server answering A queries from records and
+// NXDOMAIN for everything else.
+func startTestDNSServer(t *testing.T, records map[string]string) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var req dnsmessage.Message
+			if err := req.Unpack(buf[:n]); err != nil || len(req.Questions) != 1 {
+				continue
+			}
+			q := req.Questions[0]
+			resp := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: req.ID, Response: true, RCode: dnsmessage.RCodeNameError},
+				Questions: req.Questions,
+			}
+			if ip, ok := records[q.Name.String()]; ok {
+				resp.RCode = dnsmessage.RCodeSuccess
+				var a [4]byte
+				copy(a[:], net.ParseIP(ip).To4())
+				resp.Answers = []dnsmessage.Resource{{
+					Header: dnsmessage.ResourceHeader{Name: q.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 30},
+					Body:   &dnsmessage.AResource{A: a},
+				}}
+			}
+			out, err := resp.Pack()
+			if err != nil {
+				continue
+			}
+			_, _ = conn.WriteTo(out, addr)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestResolveWithSearch(t *testing.T) {
+	server := startTestDNSServer(t, map[string]string{
+		"web.shop.svc.cluster.local.": "10.0.0.5",
+		"api.example.com.":            "93.184.216.34",
+	})
+	searches := []string{"shop.svc.cluster.local", "svc.cluster.local", "cluster.local"}
+	ctx := context.Background()
+
+	results := ResolveWithSearch(ctx, server, "web", searches, 5)
+	if len(results) != 1 || !results[0].Resolved() || results[0].Answers[0] != "10.0.0.5" {
+		t.Errorf("expected web to resolve on the first search domain, got %+v", results)
+	}
+
+	results = ResolveWithSearch(ctx, server, "api.example.com", searches, 5)
+	if len(results) != 4 || !results[3].Resolved() {
+		t.Fatalf("expected 3 NXDOMAIN search queries then the absolute name, got %+v", results)
+	}
+	for _, r := range results[:3] {
+		if r.RCode != "NXDOMAIN" {
+			t.Errorf("expected NXDOMAIN for %s, got %s", r.Name, r.RCode)
+		}
+	}
+
+	results = ResolveWithSearch(ctx, server, "api.example.com.", searches, 5)
+	if len(results) != 1 || !results[0].Resolved() {
+		t.Errorf("expected a fully-qualified name to skip the search path, got %+v", results)
+	}
+
+	results = ResolveWithSearch(ctx, server, "missing", searches, 5)
+	if len(results) != 4 || results[3].Resolved() {
+		t.Errorf("expected every candidate to fail, got %+v", results)
+	}
+}
@@ -0,0 +1,540 @@
+package k8s
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+// Kinds of field drift.
+const (
+	DriftChanged = "changed" // the live value differs from the desired value
+	DriftRemoved = "removed" // the desired field is absent from the live object
+	DriftAdded   = "added"   // an interactive client added a field the desired state does not set
+)
+
+// FieldDrift is one field where the live object differs from its desired state.
+type FieldDrift struct {
+	Path    string
+	Type    string // DriftChanged, DriftRemoved or DriftAdded
+	Desired string
+	Live    string
+	Manager string // field manager owning the live value; empty when unknown
+}
+
+// DriftReport is the result of comparing a desired object with its live counterpart.
+type DriftReport struct {
+	Drifts []FieldDrift
+	// Ignored lists differing fields owned by other controllers (HPA, operators, webhooks).
+	Ignored []string
+}
+
+// interactiveManagers are field manager prefixes of tools people use to change objects by hand.
+var interactiveManagers = []string{"kubectl", "k9s", "Mozilla", "kubernetes-dashboard", "headlamp", "lens"}
+
+// IsInteractiveFieldManager reports whether a field manager is a client people use to edit
+// objects by hand (kubectl edit/patch/scale/set, k9s, dashboards) rather than a controller.
+func IsInteractiveFieldManager(manager string) bool {
+	lower := strings.ToLower(manager)
+	for _, p := range interactiveManagers {
+		if strings.HasPrefix(lower, strings.ToLower(p)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoredMetadataFields are set by the apiserver and never part of a desired state.
+var ignoredMetadataFields = map[string]bool{
+	"managedFields": true, "resourceVersion": true, "uid": true, "generation": true,
+	"creationTimestamp": true, "selfLink": true, "deletionTimestamp": true,
+	"deletionGracePeriodSeconds": true, "ownerReferences": true,
+}
+
+// ignoredAnnotations are written by clients as bookkeeping, not as configuration.
+var ignoredAnnotations = map[string]bool{
+	LastAppliedAnnotation:               true,
+	"deployment.kubernetes.io/revision": true,
+}
+
+// ownerNode is the part of one field manager's managedFields tree that covers the current path.
+type ownerNode struct {
+	manager string
+	node    map[string]interface{}
+}
+
+// DiffLiveObject compares every field set in desired with the live object, using the live
+// object's managedFields to attribute each difference to a field manager. applyManager is the
+// manager that applies desired (kustomize-controller for Flux). A differing field owned only by
+// another, non-interactive manager is treated as legitimately managed elsewhere and recorded in
+// Ignored. Fields that interactive clients added on top of the desired state are reported as
+// DriftAdded. Secret values are never included in the report.
+func DiffLiveObject(desired, live *unstructured.Unstructured, applyManager string) DriftReport {
+	desiredObj := desired.DeepCopy()
+	isSecret := desired.GetKind() == "Secret" && desired.GetAPIVersion() == "v1"
+	if isSecret {
+		mergeStringData(desiredObj)
+	}
+
+	var roots []ownerNode
+	for _, mf := range live.GetManagedFields() {
+		if mf.FieldsV1 == nil || mf.Subresource == "status" {
+			continue
+		}
+		var tree map[string]interface{}
+		if err := json.Unmarshal(mf.FieldsV1.Raw, &tree); err != nil {
+			continue
+		}
+		roots = append(roots, ownerNode{manager: mf.Manager, node: tree})
+	}
+
+	d := &differ{applyManager: applyManager, secret: isSecret}
+	d.walk(desiredObj.Object, live.Object, "", roots)
+	for _, root := range roots {
+		if IsInteractiveFieldManager(root.manager) {
+			d.added(root.manager, root.node, desiredObj.Object, live.Object, "")
+		}
+	}
+	sort.SliceStable(d.report.Drifts, func(i, j int) bool { return d.report.Drifts[i].Path < d.report.Drifts[j].Path })
+	return d.report
+}
+
+type differ struct {
+	applyManager string
+	secret       bool
+	report       DriftReport
+}
+
+func (d *differ) walk(desired, live interface{}, path string, nodes []ownerNode) {
+	switch dv := desired.(type) {
+	case map[string]interface{}:
+		liveMap, ok := live.(map[string]interface{})
+		if !ok {
+			d.leaf(desired, live, path, nodes)
+			return
+		}
+		for _, key := range sortedKeys(dv) {
+			if skipField(path, key) {
+				continue
+			}
+			childPath := joinPath(path, key)
+			childNodes := childOwners(nodes, "f:"+key)
+			lv, found := liveMap[key]
+			if !found {
+				if !isZeroValue(dv[key]) {
+					d.add(FieldDrift{Path: childPath, Type: DriftRemoved, Desired: d.display(childPath, dv[key]), Live: "<unset>"})
+				}
+				continue
+			}
+			d.walk(dv[key], lv, childPath, childNodes)
+		}
+	case []interface{}:
+		liveList, ok := live.([]interface{})
+		keyFields := listKeyFields(dv, nodes)
+		if !ok || keyFields == nil {
+			d.leaf(desired, live, path, nodes)
+			return
+		}
+		for i, item := range dv {
+			itemMap := item.(map[string]interface{})
+			keyVals := map[string]interface{}{}
+			for _, f := range keyFields {
+				keyVals[f] = itemMap[f]
+			}
+			itemPath := fmt.Sprintf("%s[%s]", path, formatListKey(keyFields, keyVals))
+			liveItem := findListItem(liveList, keyVals)
+			if liveItem == nil {
+				d.add(FieldDrift{Path: itemPath, Type: DriftRemoved, Desired: d.display(itemPath, dv[i]), Live: "<unset>"})
+				continue
+			}
+			d.walk(item, liveItem, itemPath, childOwnersByKey(nodes, keyVals))
+		}
+	default:
+		d.leaf(desired, live, path, nodes)
+	}
+}
+
+// leaf compares a scalar or atomic value and attributes a difference to its owners.
+func (d *differ) leaf(desired, live interface{}, path string, nodes []ownerNode) {
+	if valuesEqual(desired, live) {
+		return
+	}
+	drift := FieldDrift{Path: path, Type: DriftChanged, Desired: d.display(path, desired), Live: d.display(path, live)}
+	var owners []string
+	for _, n := range nodes {
+		owners = append(owners, n.manager)
+	}
+	for _, o := range owners {
+		if IsInteractiveFieldManager(o) {
+			drift.Manager = o
+			d.add(drift)
+			return
+		}
+	}
+	for _, o := range owners {
+		if o == d.applyManager {
+			drift.Manager = o
+			d.add(drift)
+			return
+		}
+	}
+	if len(owners) > 0 {
+		d.report.Ignored = append(d.report.Ignored, fmt.Sprintf("%s (owned by %s)", path, strings.Join(dedupeStrings(owners), ", ")))
+		return
+	}
+	d.add(drift)
+}
+
+// added reports leaves an interactive manager owns that are not part of the desired state.
+func (d *differ) added(manager string, node map[string]interface{}, desired, live interface{}, path string) {
+	desiredMap, _ := desired.(map[string]interface{})
+	liveMap, _ := live.(map[string]interface{})
+	desiredList, _ := desired.([]interface{})
+	liveList, _ := live.([]interface{})
+
+	for _, key := range sortedKeys(node) {
+		child, _ := node[key].(map[string]interface{})
+		switch {
+		case strings.HasPrefix(key, "f:"):
+			name := strings.TrimPrefix(key, "f:")
+			if skipField(path, name) {
+				continue
+			}
+			childPath := joinPath(path, name)
+			dChild, inDesired := desiredMap[name]
+			if !inDesired {
+				if lv, ok := liveMap[name]; ok {
+					d.add(FieldDrift{Path: childPath, Type: DriftAdded, Desired: "<unset>", Live: d.display(childPath, lv), Manager: manager})
+				}
+				continue
+			}
+			if hasChildren(child) {
+				d.added(manager, child, dChild, liveMap[name], childPath)
+			}
+		case strings.HasPrefix(key, "k:"):
+			var keyVals map[string]interface{}
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(key, "k:")), &keyVals); err != nil {
+				continue
+			}
+			fields := sortedKeys(keyVals)
+			itemPath := fmt.Sprintf("%s[%s]", path, formatListKey(fields, keyVals))
+			liveItem := findListItem(liveList, keyVals)
+			desiredItem := findListItem(desiredList, keyVals)
+			if desiredItem == nil {
+				if liveItem != nil {
+					d.add(FieldDrift{Path: itemPath, Type: DriftAdded, Desired: "<unset>", Live: d.display(itemPath, liveItem), Manager: manager})
+				}
+				continue
+			}
+			if hasChildren(child) {
+				d.added(manager, child, desiredItem, liveItem, itemPath)
+			}
+		case strings.HasPrefix(key, "v:"):
+			var value interface{}
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(key, "v:")), &value); err != nil {
+				continue
+			}
+			if desiredList != nil && !containsJSONValue(desiredList, value) {
+				d.add(FieldDrift{Path: path, Type: DriftAdded, Desired: "<unset>", Live: d.display(path, value), Manager: manager})
+			}
+		}
+	}
+}
+
+func (d *differ) add(drift FieldDrift) {
+	for _, existing := range d.report.Drifts {
+		if existing.Path == drift.Path && existing.Type == drift.Type && existing.Live == drift.Live {
+			return
+		}
+	}
+	d.report.Drifts = append(d.report.Drifts, drift)
+}
+
+// display renders a value for the report, redacting Secret data.
+func (d *differ) display(path string, v interface{}) string {
+	if d.secret && (strings.HasPrefix(path, "data") || strings.HasPrefix(path, "stringData")) {
+		return "<redacted>"
+	}
+	switch val := v.(type) {
+	case nil:
+		return "<unset>"
+	case string:
+		return util.TruncateString(val, 80)
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(val)
+		return util.TruncateString(string(data), 80)
+	}
+	return fmt.Sprint(v)
+}
+
+// skipField reports whether a field is never part of a desired state.
+func skipField(path, key string) bool {
+	switch path {
+	case "":
+		return key == "status"
+	case "metadata":
+		return ignoredMetadataFields[key]
+	case "metadata.annotations":
+		return ignoredAnnotations[key]
+	}
+	return false
+}
+
+func childOwners(nodes []ownerNode, key string) []ownerNode {
+	var out []ownerNode
+	for _, n := range nodes {
+		if child, ok := n.node[key].(map[string]interface{}); ok {
+			out = append(out, ownerNode{manager: n.manager, node: child})
+		}
+	}
+	return out
+}
+
+// childOwnersByKey finds the "k:{...}" entries that identify the list item with keyVals.
+func childOwnersByKey(nodes []ownerNode, keyVals map[string]interface{}) []ownerNode {
+	var out []ownerNode
+	for _, n := range nodes {
+		for key, child := range n.node {
+			if !strings.HasPrefix(key, "k:") {
+				continue
+			}
+			var kv map[string]interface{}
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(key, "k:")), &kv); err != nil {
+				continue
+			}
+			if keysMatch(kv, keyVals) {
+				if m, ok := child.(map[string]interface{}); ok {
+					out = append(out, ownerNode{manager: n.manager, node: m})
+				}
+			}
+		}
+	}
+	return out
+}
+
+// listKeyFields returns the merge key fields of a list of objects, taken from the managedFields
+// "k:" entries when present and falling back to "name". Nil means the list is compared atomically.
+func listKeyFields(items []interface{}, nodes []ownerNode) []string {
+	if len(items) == 0 {
+		return nil
+	}
+	for _, item := range items {
+		if _, ok := item.(map[string]interface{}); !ok {
+			return nil
+		}
+	}
+	for _, n := range nodes {
+		for key := range n.node {
+			if !strings.HasPrefix(key, "k:") {
+				continue
+			}
+			var kv map[string]interface{}
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(key, "k:")), &kv); err == nil && len(kv) > 0 {
+				return sortedKeys(kv)
+			}
+		}
+	}
+	for _, item := range items {
+		if _, ok := item.(map[string]interface{})["name"]; !ok {
+			return nil
+		}
+	}
+	return []string{"name"}
+}
+
+func findListItem(items []interface{}, keyVals map[string]interface{}) map[string]interface{} {
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if ok && keysMatch(keyVals, m) {
+			return m
+		}
+	}
+	return nil
+}
+
+// keysMatch reports whether every field of want has an equal value in item. Missing protocol
+// fields default to TCP, as the apiserver does for ports.
+func keysMatch(want, item map[string]interface{}) bool {
+	for k, v := range want {
+		got, ok := item[k]
+		if !ok && k == "protocol" {
+			got = "TCP"
+		}
+		if !valuesEqual(v, got) {
+			return false
+		}
+	}
+	return true
+}
+
+func formatListKey(fields []string, keyVals map[string]interface{}) string {
+	parts := make([]string, 0, len(fields))
+	for _, f := range fields {
+		parts = append(parts, fmt.Sprintf("%s=%v", f, keyVals[f]))
+	}
+	return strings.Join(parts, ",")
+}
+
+var simpleKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// joinPath appends a field to a display path, quoting keys such as label names.
+func joinPath(path, key string) string {
+	if !simpleKey.MatchString(key) {
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// valuesEqual compares JSON values, treating numbers of different types and equivalent
+// resource quantities ("0.5" and "500m") as equal.
+func valuesEqual(a, b interface{}) bool {
+	if isZeroValue(a) && isZeroValue(b) {
+		return true
+	}
+	if fa, ok := toFloat(a); ok {
+		if fb, ok := toFloat(b); ok {
+			return fa == fb
+		}
+	}
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k, v := range av {
+			if !valuesEqual(v, bv[k]) {
+				return false
+			}
+		}
+		for k, v := range bv {
+			if _, ok := av[k]; !ok && !isZeroValue(v) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !valuesEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	sa, sb := fmt.Sprint(a), fmt.Sprint(b)
+	if sa == sb {
+		return true
+	}
+	qa, errA := resource.ParseQuantity(sa)
+	qb, errB := resource.ParseQuantity(sb)
+	return errA == nil && errB == nil && qa.Cmp(qb) == 0
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// isZeroValue reports whether v is a value the apiserver omits when stored (empty, false, 0).
+func isZeroValue(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return val == ""
+	case bool:
+		return !val
+	case map[string]interface{}:
+		return len(val) == 0
+	case []interface{}:
+		return len(val) == 0
+	}
+	if f, ok := toFloat(v); ok {
+		return f == 0
+	}
+	return false
+}
+
+func containsJSONValue(items []interface{}, v interface{}) bool {
+	for _, item := range items {
+		if valuesEqual(item, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasChildren(node map[string]interface{}) bool {
+	for k := range node {
+		if k != "." {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeStringData folds a Secret's stringData into data, as the apiserver does on write.
+func mergeStringData(obj *unstructured.Unstructured) {
+	stringData, found, _ := unstructured.NestedStringMap(obj.Object, "stringData")
+	if !found {
+		return
+	}
+	data, _, _ := unstructured.NestedMap(obj.Object, "data")
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	for k, v := range stringData {
+		data[k] = base64.StdEncoding.EncodeToString([]byte(v))
+	}
+	_ = unstructured.SetNestedMap(obj.Object, data, "data")
+	unstructured.RemoveNestedField(obj.Object, "stringData")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func dedupeStrings(items []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, s := range items {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,125 @@
+package k8s
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func fromJSON(t *testing.T, s string) *unstructured.Unstructured {
+	t.Helper()
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON([]byte(s)); err != nil {
+		t.Fatalf("UnmarshalJSON() error = %v", err)
+	}
+	return obj
+}
+
+func managedFields(t *testing.T, entries map[string]string) []metav1.ManagedFieldsEntry {
+	t.Helper()
+	var out []metav1.ManagedFieldsEntry
+	for manager, fields := range entries {
+		if !json.Valid([]byte(fields)) {
+			t.Fatalf("invalid fieldsV1 for %s", manager)
+		}
+		out = append(out, metav1.ManagedFieldsEntry{
+			Manager: manager, Operation: metav1.ManagedFieldsOperationUpdate,
+			FieldsType: "FieldsV1", FieldsV1: &metav1.FieldsV1{Raw: []byte(fields)},
+		})
+	}
+	return out
+}
+
+func findDrift(report DriftReport, path string) *FieldDrift {
+	for i := range report.Drifts {
+		if report.Drifts[i].Path == path {
+			return &report.Drifts[i]
+		}
+	}
+	return nil
+}
+
+func TestDiffLiveObject(t *testing.T) {
+	desired := fromJSON(t, `{"apiVersion":"apps/v1","kind":"Deployment",
+		"metadata":{"name":"web","namespace":"shop","labels":{"app":"web"},"annotations":{"team":"shop"}},
+		"spec":{"replicas":2,"template":{"spec":{"containers":[
+			{"name":"app","image":"web:1.0","env":[{"name":"MODE","value":"prod"}],"resources":{"limits":{"cpu":"0.5"}}}]}}}}`)
+	live := fromJSON(t, `{"apiVersion":"apps/v1","kind":"Deployment",
+		"metadata":{"name":"web","namespace":"shop","uid":"1","resourceVersion":"9",
+			"labels":{"app":"web","debug":"true"},"annotations":{"team":"payments","deployment.kubernetes.io/revision":"3"}},
+		"spec":{"replicas":5,"template":{"spec":{"containers":[
+			{"name":"app","image":"web:hotfix","resources":{"limits":{"cpu":"500m"}}}]}}},
+		"status":{"replicas":5}}`)
+	live.SetManagedFields(managedFields(t, map[string]string{
+		"kustomize-controller": `{"f:metadata":{"f:labels":{"f:app":{}}},"f:spec":{"f:template":{"f:spec":{"f:containers":{
+			"k:{\"name\":\"app\"}":{".":{},"f:name":{},"f:resources":{"f:limits":{"f:cpu":{}}}}}}}}}`,
+		"kubectl-edit": `{"f:metadata":{"f:labels":{"f:debug":{}}},"f:spec":{"f:template":{"f:spec":{"f:containers":{
+			"k:{\"name\":\"app\"}":{"f:image":{}}}}}}}`,
+		"horizontal-pod-autoscaler": `{"f:spec":{"f:replicas":{}}}`,
+	}))
+
+	report := DiffLiveObject(desired, live, "kustomize-controller")
+
+	if d := findDrift(report, "spec.template.spec.containers[name=app].image"); d == nil || d.Type != DriftChanged || d.Manager != "kubectl-edit" || d.Live != "web:hotfix" {
+		t.Errorf("image: expected a change by kubectl-edit, got %+v", d)
+	}
+	if d := findDrift(report, "spec.template.spec.containers[name=app].env"); d == nil || d.Type != DriftRemoved {
+		t.Errorf("env: expected a removed field, got %+v", d)
+	}
+	if d := findDrift(report, "metadata.labels.debug"); d == nil || d.Type != DriftAdded || d.Manager != "kubectl-edit" {
+		t.Errorf("debug label: expected an added field, got %+v", d)
+	}
+	if d := findDrift(report, "metadata.annotations.team"); d == nil || d.Type != DriftChanged || d.Manager != "" {
+		t.Errorf("team annotation: expected an unattributed change, got %+v", d)
+	}
+	if d := findDrift(report, "spec.template.spec.containers[name=app].resources.limits.cpu"); d != nil {
+		t.Errorf("0.5 and 500m are the same quantity, got %+v", d)
+	}
+	if d := findDrift(report, "spec.replicas"); d != nil {
+		t.Errorf("replicas owned by the HPA should be ignored, got %+v", d)
+	}
+	if len(report.Ignored) != 1 || !strings.Contains(report.Ignored[0], "horizontal-pod-autoscaler") {
+		t.Errorf("expected replicas in Ignored, got %v", report.Ignored)
+	}
+	if len(report.Drifts) != 4 {
+		t.Errorf("expected 4 drifts, got %+v", report.Drifts)
+	}
+}
+
+func TestDiffLiveObjectSecret(t *testing.T) {
+	desired := fromJSON(t, `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"db","namespace":"shop"},
+		"stringData":{"password":"s3cret"},"data":{"user":"YWRtaW4="}}`)
+	live := fromJSON(t, `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"db","namespace":"shop"},
+		"data":{"user":"YWRtaW4=","password":"b3RoZXI="}}`)
+	live.SetManagedFields(managedFields(t, map[string]string{
+		"kubectl-edit": `{"f:data":{"f:password":{}}}`,
+	}))
+
+	report := DiffLiveObject(desired, live, "kustomize-controller")
+	if len(report.Drifts) != 1 {
+		t.Fatalf("expected 1 drift, got %+v", report.Drifts)
+	}
+	d := report.Drifts[0]
+	if d.Path != "data.password" || d.Manager != "kubectl-edit" || d.Desired != "<redacted>" || d.Live != "<redacted>" {
+		t.Errorf("expected a redacted password change, got %+v", d)
+	}
+
+	live.Object["data"] = map[string]interface{}{"user": "YWRtaW4=", "password": "czNjcmV0"}
+	if report := DiffLiveObject(desired, live, "kustomize-controller"); len(report.Drifts) != 0 {
+		t.Errorf("stringData should match the encoded data, got %+v", report.Drifts)
+	}
+}
+
+func TestIsInteractiveFieldManager(t *testing.T) {
+	for manager, want := range map[string]bool{
+		"kubectl-edit": true, "kubectl-client-side-apply": true, "k9s": true, "Mozilla": true,
+		"kustomize-controller": false, "kube-controller-manager": false, "helm": false,
+	} {
+		if got := IsInteractiveFieldManager(manager); got != want {
+			t.Errorf("IsInteractiveFieldManager(%q) = %v, want %v", manager, got, want)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+const (
+	// EndpointSliceControllerName is the managed-by value of slices owned by kube-controller-manager.
+	EndpointSliceControllerName = "endpointslice-controller.k8s.io"
+	// EndpointSliceMirroringControllerName is the managed-by value of slices mirrored from
+	// manually maintained core/v1 Endpoints.
+	EndpointSliceMirroringControllerName = "endpointslicemirroring-controller.k8s.io"
+
+	// Endpoint health sources reported in EndpointHealth.Source.
+	EndpointSourceSlices = "EndpointSlice"
+	EndpointSourceCore   = "Endpoints"
+)
+
+// EndpointSliceInfo summarizes a single EndpointSlice backing a service.
+type EndpointSliceInfo struct {
+	Name        string
+	AddressType string
+	ManagedBy   string
+	Endpoints   int
+	Ports       []string
+}
+
+// CustomManaged reports whether the slice is maintained by something other than the
+// built-in EndpointSlice controller (a mesh, a custom controller, or mirrored Endpoints).
+func (s EndpointSliceInfo) CustomManaged() bool {
+	return s.ManagedBy != EndpointSliceControllerName
+}
+
+// ZoneEndpoints counts endpoints in a single zone.
+type ZoneEndpoints struct {
+	Zone     string
+	Ready    int
+	NotReady int
+}
+
+// ListServiceEndpointSlices returns the EndpointSlices labelled for a service.
+func (c *ClusterClient) ListServiceEndpointSlices(ctx context.Context, namespace, serviceName string) ([]discoveryv1.EndpointSlice, error) {
+	ctx, cancel := context.WithTimeout(ctx, util.DefaultTimeout)
+	defer cancel()
+
+	list, err := c.Clientset.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + serviceName,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(list.Items, func(i, j int) bool { return list.Items[i].Name < list.Items[j].Name })
+	return list.Items, nil
+}
+
+// endpointHealthFromSlices builds endpoint health from EndpointSlices. Endpoints that appear
+// in several slices (one per address family in dual-stack services, or briefly while the
+// controller rebalances) are merged by target so each pod is counted once.
+func endpointHealthFromSlices(namespace, serviceName string, slices []discoveryv1.EndpointSlice) *EndpointHealth {
+	health := &EndpointHealth{
+		ServiceName: serviceName,
+		ServiceNS:   namespace,
+		Source:      EndpointSourceSlices,
+	}
+
+	families := make(map[string]bool)
+	byKey := make(map[string]*EndpointAddress)
+	var order []string
+	for _, s := range slices {
+		info := EndpointSliceInfo{
+			Name:        s.Name,
+			AddressType: string(s.AddressType),
+			ManagedBy:   s.Labels[discoveryv1.LabelManagedBy],
+			Endpoints:   len(s.Endpoints),
+		}
+		for _, p := range s.Ports {
+			info.Ports = append(info.Ports, formatSlicePort(p))
+			health.Ports = appendUnique(health.Ports, formatSlicePort(p))
+		}
+		health.Slices = append(health.Slices, info)
+		if !families[info.AddressType] {
+			families[info.AddressType] = true
+			health.AddressFamilies = append(health.AddressFamilies, info.AddressType)
+		}
+
+		for _, ep := range s.Endpoints {
+			key := endpointKey(ep)
+			ea, ok := byKey[key]
+			if !ok {
+				ea = &EndpointAddress{
+					Ready:       ep.Conditions.Ready == nil || *ep.Conditions.Ready,
+					Serving:     ep.Conditions.Serving == nil || *ep.Conditions.Serving,
+					Terminating: ep.Conditions.Terminating != nil && *ep.Conditions.Terminating,
+				}
+				if ep.TargetRef != nil {
+					ea.PodName = ep.TargetRef.Name
+				}
+				if ep.NodeName != nil {
+					ea.NodeName = *ep.NodeName
+				}
+				if ep.Zone != nil {
+					ea.Zone = *ep.Zone
+				}
+				if ep.Hints != nil {
+					for _, z := range ep.Hints.ForZones {
+						ea.ZoneHints = append(ea.ZoneHints, z.Name)
+					}
+				}
+				byKey[key] = ea
+				order = append(order, key)
+			}
+			for _, addr := range ep.Addresses {
+				if ea.IP == "" {
+					ea.IP = addr
+				}
+				ea.IPs = appendUnique(ea.IPs, addr)
+			}
+		}
+	}
+	sort.Strings(health.AddressFamilies)
+
+	for _, key := range order {
+		ea := *byKey[key]
+		health.addEndpoint(ea)
+	}
+	return health
+}
+
+// endpointHealthFromEndpoints builds endpoint health from a core/v1 Endpoints object, used
+// when no EndpointSlices exist for the service.
+func endpointHealthFromEndpoints(namespace, serviceName string, ep *corev1.Endpoints) *EndpointHealth {
+	health := &EndpointHealth{
+		ServiceName: serviceName,
+		ServiceNS:   namespace,
+		Source:      EndpointSourceCore,
+	}
+	families := make(map[string]bool)
+	for _, subset := range ep.Subsets {
+		for _, p := range subset.Ports {
+			port := fmt.Sprintf("%d/%s", p.Port, p.Protocol)
+			if p.Name != "" {
+				port = p.Name + ":" + port
+			}
+			health.Ports = appendUnique(health.Ports, port)
+		}
+		for _, addr := range subset.Addresses {
+			ea := coreEndpointAddress(addr)
+			ea.Ready, ea.Serving = true, true
+			families[addressFamily(addr.IP)] = true
+			health.addEndpoint(ea)
+		}
+		for _, addr := range subset.NotReadyAddresses {
+			ea := coreEndpointAddress(addr)
+			families[addressFamily(addr.IP)] = true
+			health.addEndpoint(ea)
+		}
+	}
+	for f := range families {
+		health.AddressFamilies = append(health.AddressFamilies, f)
+	}
+	sort.Strings(health.AddressFamilies)
+	return health
+}
+
+// addEndpoint files an endpoint under ready, not-ready or terminating. Terminating endpoints
+// are kept out of the totals, matching what the core Endpoints controller publishes.
+func (h *EndpointHealth) addEndpoint(ea EndpointAddress) {
+	switch {
+	case ea.Terminating:
+		h.Terminating = append(h.Terminating, ea)
+		h.TerminatingCount++
+		if ea.Serving {
+			h.ServingCount++
+		}
+		return
+	case ea.Ready:
+		h.ReadyAddresses = append(h.ReadyAddresses, ea)
+		h.ReadyCount++
+	default:
+		h.NotReadyPods = append(h.NotReadyPods, ea)
+		h.NotReadyCount++
+	}
+	if ea.Serving {
+		h.ServingCount++
+	}
+	if len(ea.ZoneHints) > 0 {
+		h.HintedCount++
+	}
+	h.TotalEndpoints++
+}
+
+// ZoneDistribution returns ready and not-ready endpoint counts per zone, sorted by zone.
+// Endpoints without a zone are grouped under "<none>".
+func (h *EndpointHealth) ZoneDistribution() []ZoneEndpoints {
+	counts := make(map[string]*ZoneEndpoints)
+	add := func(ea EndpointAddress, ready bool) {
+		zone := ea.Zone
+		if zone == "" {
+			zone = "<none>"
+		}
+		z, ok := counts[zone]
+		if !ok {
+			z = &ZoneEndpoints{Zone: zone}
+			counts[zone] = z
+		}
+		if ready {
+			z.Ready++
+		} else {
+			z.NotReady++
+		}
+	}
+	for _, ea := range h.ReadyAddresses {
+		add(ea, true)
+	}
+	for _, ea := range h.NotReadyPods {
+		add(ea, false)
+	}
+	out := make([]ZoneEndpoints, 0, len(counts))
+	for _, z := range counts {
+		out = append(out, *z)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Zone < out[j].Zone })
+	return out
+}
+
+// CustomManagedSlices returns the slices not maintained by the built-in EndpointSlice controller.
+func (h *EndpointHealth) CustomManagedSlices() []EndpointSliceInfo {
+	var out []EndpointSliceInfo
+	for _, s := range h.Slices {
+		if s.CustomManaged() {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// DualStack reports whether the service has both IPv4 and IPv6 endpoints.
+func (h *EndpointHealth) DualStack() bool {
+	v4, v6 := false, false
+	for _, f := range h.AddressFamilies {
+		v4 = v4 || f == string(discoveryv1.AddressTypeIPv4)
+		v6 = v6 || f == string(discoveryv1.AddressTypeIPv6)
+	}
+	return v4 && v6
+}
+
+func endpointKey(ep discoveryv1.Endpoint) string {
+	if ep.TargetRef != nil {
+		return fmt.Sprintf("%s/%s/%s", ep.TargetRef.Kind, ep.TargetRef.Namespace, ep.TargetRef.Name)
+	}
+	if len(ep.Addresses) > 0 {
+		return ep.Addresses[0]
+	}
+	return ""
+}
+
+func coreEndpointAddress(addr corev1.EndpointAddress) EndpointAddress {
+	ea := EndpointAddress{IP: addr.IP, IPs: []string{addr.IP}}
+	if addr.TargetRef != nil {
+		ea.PodName = addr.TargetRef.Name
+	}
+	if addr.NodeName != nil {
+		ea.NodeName = *addr.NodeName
+	}
+	return ea
+}
+
+func formatSlicePort(p discoveryv1.EndpointPort) string {
+	port := "?"
+	if p.Port != nil {
+		port = fmt.Sprintf("%d", *p.Port)
+	}
+	proto := "TCP"
+	if p.Protocol != nil {
+		proto = string(*p.Protocol)
+	}
+	if p.Name != nil && *p.Name != "" {
+		return fmt.Sprintf("%s:%s/%s", *p.Name, port, proto)
+	}
+	return fmt.Sprintf("%s/%s", port, proto)
+}
+
+func addressFamily(ip string) string {
+	if strings.Contains(ip, ":") {
+		return string(discoveryv1.AddressTypeIPv6)
+	}
+	return string(discoveryv1.AddressTypeIPv4)
+}
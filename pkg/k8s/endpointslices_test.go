@@ -0,0 +1,113 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testSlice(name, addrType, managedBy string, endpoints ...discoveryv1.Endpoint) *discoveryv1.EndpointSlice {
+	port := int32(8080)
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{
+			discoveryv1.LabelServiceName: "web",
+			discoveryv1.LabelManagedBy:   managedBy,
+		}},
+		AddressType: discoveryv1.AddressType(addrType),
+		Endpoints:   endpoints,
+		Ports:       []discoveryv1.EndpointPort{{Port: &port}},
+	}
+}
+
+func testEndpoint(pod, addr, zone string, ready, serving, terminating bool, hints ...string) discoveryv1.Endpoint {
+	ep := discoveryv1.Endpoint{
+		Addresses: []string{addr},
+		Conditions: discoveryv1.EndpointConditions{
+			Ready: &ready, Serving: &serving, Terminating: &terminating,
+		},
+		TargetRef: &corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: pod},
+		Zone:      &zone,
+	}
+	if len(hints) > 0 {
+		ep.Hints = &discoveryv1.EndpointHints{}
+		for _, h := range hints {
+			ep.Hints.ForZones = append(ep.Hints.ForZones, discoveryv1.ForZone{Name: h})
+		}
+	}
+	return ep
+}
+
+func TestGetServiceEndpointHealthFromSlices(t *testing.T) {
+	client := NewClusterClientForTesting(fake.NewSimpleClientset(
+		testSlice("web-v4", "IPv4", EndpointSliceControllerName,
+			testEndpoint("web-1", "10.0.0.1", "zone-a", true, true, false, "zone-a"),
+			testEndpoint("web-2", "10.0.0.2", "zone-b", false, false, false),
+			testEndpoint("web-3", "10.0.0.3", "zone-b", false, true, true),
+		),
+		testSlice("web-v6", "IPv6", EndpointSliceControllerName,
+			testEndpoint("web-1", "fd00::1", "zone-a", true, true, false, "zone-a"),
+			testEndpoint("web-2", "fd00::2", "zone-b", false, false, false),
+		),
+		testSlice("web-custom", "IPv4", "mesh-controller"),
+		// A slice for another service must be ignored.
+		&discoveryv1.EndpointSlice{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default",
+			Labels: map[string]string{discoveryv1.LabelServiceName: "other"}}, AddressType: discoveryv1.AddressTypeIPv4},
+	), nil)
+
+	health, err := client.GetServiceEndpointHealth(context.Background(), "default", "web")
+	if err != nil {
+		t.Fatalf("GetServiceEndpointHealth() error = %v", err)
+	}
+	if health.Source != EndpointSourceSlices {
+		t.Errorf("expected EndpointSlice source, got %q", health.Source)
+	}
+	if health.ReadyCount != 1 || health.NotReadyCount != 1 || health.TerminatingCount != 1 || health.TotalEndpoints != 2 {
+		t.Errorf("unexpected counts: ready=%d notReady=%d terminating=%d total=%d",
+			health.ReadyCount, health.NotReadyCount, health.TerminatingCount, health.TotalEndpoints)
+	}
+	if health.ServingCount != 2 || health.HintedCount != 1 {
+		t.Errorf("unexpected serving=%d hinted=%d", health.ServingCount, health.HintedCount)
+	}
+	if !health.DualStack() || len(health.ReadyAddresses[0].IPs) != 2 {
+		t.Errorf("expected dual-stack endpoint merged across slices, got %+v", health.ReadyAddresses)
+	}
+	if custom := health.CustomManagedSlices(); len(custom) != 1 || custom[0].Name != "web-custom" {
+		t.Errorf("expected web-custom to be custom managed, got %+v", custom)
+	}
+	zones := health.ZoneDistribution()
+	if len(zones) != 2 || zones[0].Zone != "zone-a" || zones[0].Ready != 1 || zones[1].Ready != 0 || zones[1].NotReady != 1 {
+		t.Errorf("unexpected zone distribution: %+v", zones)
+	}
+}
+
+func TestGetServiceEndpointHealthFallsBackToEndpoints(t *testing.T) {
+	client := NewClusterClientForTesting(fake.NewSimpleClientset(
+		&corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Subsets: []corev1.EndpointSubset{{
+				Addresses:         []corev1.EndpointAddress{{IP: "10.0.0.1"}},
+				NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.0.0.2"}},
+				Ports:             []corev1.EndpointPort{{Name: "http", Port: 8080, Protocol: corev1.ProtocolTCP}},
+			}},
+		},
+	), nil)
+
+	health, err := client.GetServiceEndpointHealth(context.Background(), "default", "web")
+	if err != nil {
+		t.Fatalf("GetServiceEndpointHealth() error = %v", err)
+	}
+	if health.Source != EndpointSourceCore || health.ReadyCount != 1 || health.NotReadyCount != 1 {
+		t.Errorf("unexpected health: %+v", health)
+	}
+	if len(health.Ports) != 1 || health.Ports[0] != "http:8080/TCP" {
+		t.Errorf("unexpected ports: %v", health.Ports)
+	}
+
+	if _, err := client.GetServiceEndpointHealth(context.Background(), "default", "missing"); err == nil {
+		t.Error("expected an error for a service with neither slices nor endpoints")
+	}
+}
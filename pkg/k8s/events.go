@@ -3,6 +3,7 @@ package k8s
 import (
 	"context"
 	"sort"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -22,15 +23,7 @@ func (c *ClusterClient) ListEvents(ctx context.Context, namespace string, opts m
 
 	// Sort by last timestamp (most recent first)
 	sort.Slice(list.Items, func(i, j int) bool {
-		ti := list.Items[i].LastTimestamp.Time
-		tj := list.Items[j].LastTimestamp.Time
-		if ti.IsZero() {
-			ti = list.Items[i].CreationTimestamp.Time
-		}
-		if tj.IsZero() {
-			tj = list.Items[j].CreationTimestamp.Time
-		}
-		return ti.After(tj)
+		return eventTime(&list.Items[i]).After(eventTime(&list.Items[j]))
 	})
 
 	// Truncate to MaxEvents
@@ -47,3 +40,11 @@ func (c *ClusterClient) GetEventsForObject(ctx context.Context, namespace, objec
 	}
 	return c.ListEvents(ctx, namespace, opts)
 }
+
+// eventTime returns when an event last occurred, falling back to its creation time.
+func eventTime(e *corev1.Event) time.Time {
+	if !e.LastTimestamp.IsZero() {
+		return e.LastTimestamp.Time
+	}
+	return e.CreationTimestamp.Time
+}
@@ -0,0 +1,805 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Gateway API group and CRD names.
+const (
+	GatewayAPIGroup = "gateway.networking.k8s.io"
+
+	GatewayClassCRD   = "gatewayclasses.gateway.networking.k8s.io"
+	GatewayCRD        = "gateways.gateway.networking.k8s.io"
+	HTTPRouteCRD      = "httproutes.gateway.networking.k8s.io"
+	GRPCRouteCRD      = "grpcroutes.gateway.networking.k8s.io"
+	ReferenceGrantCRD = "referencegrants.gateway.networking.k8s.io"
+)
+
+// Route kinds handled by the Gateway API analysis.
+const (
+	KindHTTPRoute = "HTTPRoute"
+	KindGRPCRoute = "GRPCRoute"
+)
+
+// GatewayCondition is a status condition on a Gateway API object.
+type GatewayCondition struct {
+	Type    string
+	Status  string
+	Reason  string
+	Message string
+}
+
+// GatewayClassInfo is a parsed GatewayClass.
+type GatewayClassInfo struct {
+	Name           string
+	ControllerName string
+	Conditions     []GatewayCondition
+}
+
+// GatewayListener is a parsed Gateway listener merged with its status.
+type GatewayListener struct {
+	Name     string
+	Hostname string
+	Protocol string
+	Port     int64
+	// CertificateRefs are "namespace/name" references to TLS secrets.
+	CertificateRefs []string
+	// AllowedRoutesFrom is Same (default), All or Selector.
+	AllowedRoutesFrom string
+	// AllowedKinds lists route kinds the listener accepts (empty means the protocol default).
+	AllowedKinds   []string
+	AttachedRoutes int64
+	Conditions     []GatewayCondition
+}
+
+// GatewayInfo is a parsed Gateway.
+type GatewayInfo struct {
+	Namespace  string
+	Name       string
+	ClassName  string
+	Addresses  []string
+	Listeners  []GatewayListener
+	Conditions []GatewayCondition
+}
+
+// RouteParentRef identifies the Gateway (and optionally listener) a route attaches to.
+type RouteParentRef struct {
+	Namespace   string
+	Name        string
+	SectionName string
+	Port        int64
+}
+
+func (p RouteParentRef) String() string {
+	s := p.Namespace + "/" + p.Name
+	if p.SectionName != "" {
+		s += "#" + p.SectionName
+	}
+	return s
+}
+
+// RouteParentStatus is the status a controller reports for one parentRef of a route.
+type RouteParentStatus struct {
+	ParentRef      RouteParentRef
+	ControllerName string
+	Conditions     []GatewayCondition
+}
+
+// RouteMatch is one match of a route rule. HTTPRoutes use the path fields; GRPCRoutes use service/method.
+type RouteMatch struct {
+	PathType    string
+	PathValue   string
+	Method      string
+	Headers     []string
+	GRPCService string
+	GRPCMethod  string
+}
+
+// RouteBackendRef is a backendRef of a route rule.
+type RouteBackendRef struct {
+	Group     string
+	Kind      string
+	Namespace string
+	Name      string
+	Port      int64
+	Weight    int64
+}
+
+// IsService reports whether the backendRef targets a core Service.
+func (b RouteBackendRef) IsService() bool {
+	return b.Group == "" && b.Kind == "Service"
+}
+
+// RouteRule is one rule of an HTTPRoute or GRPCRoute.
+type RouteRule struct {
+	Matches     []RouteMatch
+	BackendRefs []RouteBackendRef
+}
+
+// GatewayRoute is a parsed HTTPRoute or GRPCRoute.
+type GatewayRoute struct {
+	Kind       string
+	Namespace  string
+	Name       string
+	Created    time.Time
+	Hostnames  []string
+	ParentRefs []RouteParentRef
+	Rules      []RouteRule
+	Parents    []RouteParentStatus
+}
+
+// ParentStatus returns the controller status for a parentRef, or nil if none was reported.
+func (r *GatewayRoute) ParentStatus(ref RouteParentRef) *RouteParentStatus {
+	for i := range r.Parents {
+		p := &r.Parents[i]
+		if p.ParentRef.Namespace == ref.Namespace && p.ParentRef.Name == ref.Name && p.ParentRef.SectionName == ref.SectionName {
+			return p
+		}
+	}
+	return nil
+}
+
+// ReferenceGrantFrom is a "from" entry of a ReferenceGrant.
+type ReferenceGrantFrom struct {
+	Group     string
+	Kind      string
+	Namespace string
+}
+
+// ReferenceGrantTo is a "to" entry of a ReferenceGrant. An empty Name allows every object of the kind.
+type ReferenceGrantTo struct {
+	Group string
+	Kind  string
+	Name  string
+}
+
+// ReferenceGrantInfo is a parsed ReferenceGrant.
+type ReferenceGrantInfo struct {
+	Namespace string
+	Name      string
+	From      []ReferenceGrantFrom
+	To        []ReferenceGrantTo
+}
+
+// GatewayAPIResources holds every Gateway API object relevant to routing analysis.
+type GatewayAPIResources struct {
+	// Installed maps a CRD name to whether it is present in the cluster.
+	Installed map[string]bool
+	Classes   []GatewayClassInfo
+	Gateways  []GatewayInfo
+	Routes    []GatewayRoute
+	Grants    []ReferenceGrantInfo
+	// Errors holds non-fatal listing errors per resource.
+	Errors []string
+}
+
+// AnyInstalled reports whether any Gateway API CRD is installed.
+func (r *GatewayAPIResources) AnyInstalled() bool {
+	for _, ok := range r.Installed {
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// FindGateway returns the Gateway with the given namespace and name, or nil.
+func (r *GatewayAPIResources) FindGateway(namespace, name string) *GatewayInfo {
+	for i := range r.Gateways {
+		if r.Gateways[i].Namespace == namespace && r.Gateways[i].Name == name {
+			return &r.Gateways[i]
+		}
+	}
+	return nil
+}
+
+// FindClass returns the GatewayClass with the given name, or nil.
+func (r *GatewayAPIResources) FindClass(name string) *GatewayClassInfo {
+	for i := range r.Classes {
+		if r.Classes[i].Name == name {
+			return &r.Classes[i]
+		}
+	}
+	return nil
+}
+
+// GetGatewayAPIResources discovers Gateway API CRDs and lists their objects. Routes are limited to the
+// namespace (empty for all); GatewayClasses, Gateways and ReferenceGrants are always listed cluster-wide
+// because routes attach to and reference objects in other namespaces.
+func (c *ClusterClient) GetGatewayAPIResources(ctx context.Context, namespace string) (*GatewayAPIResources, error) {
+	crds, err := c.ListCRDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing CRDs: %w", err)
+	}
+
+	res := &GatewayAPIResources{Installed: make(map[string]bool)}
+	list := func(crdName, ns string) []unstructured.Unstructured {
+		crd := FindCRD(crds, crdName)
+		res.Installed[crdName] = crd != nil
+		if crd == nil {
+			return nil
+		}
+		items, err := c.ListCustomResources(ctx, CRDGroupVersionResource(crd), ns)
+		if err != nil {
+			res.Errors = append(res.Errors, fmt.Sprintf("%s: %v", crdName, err))
+		}
+		return items
+	}
+
+	for _, item := range list(GatewayClassCRD, "") {
+		res.Classes = append(res.Classes, parseGatewayClass(&item))
+	}
+	for _, item := range list(GatewayCRD, "") {
+		res.Gateways = append(res.Gateways, parseGateway(&item))
+	}
+	for _, item := range list(HTTPRouteCRD, namespace) {
+		res.Routes = append(res.Routes, parseRoute(&item, KindHTTPRoute))
+	}
+	for _, item := range list(GRPCRouteCRD, namespace) {
+		res.Routes = append(res.Routes, parseRoute(&item, KindGRPCRoute))
+	}
+	for _, item := range list(ReferenceGrantCRD, "") {
+		res.Grants = append(res.Grants, parseReferenceGrant(&item))
+	}
+
+	sort.Slice(res.Gateways, func(i, j int) bool {
+		if res.Gateways[i].Namespace != res.Gateways[j].Namespace {
+			return res.Gateways[i].Namespace < res.Gateways[j].Namespace
+		}
+		return res.Gateways[i].Name < res.Gateways[j].Name
+	})
+	sort.Slice(res.Routes, func(i, j int) bool {
+		a, b := res.Routes[i], res.Routes[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	return res, nil
+}
+
+func parseConditions(obj map[string]interface{}, fields ...string) []GatewayCondition {
+	raw, _, _ := unstructured.NestedSlice(obj, fields...)
+	var conds []GatewayCondition
+	for _, r := range raw {
+		m, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var cond GatewayCondition
+		cond.Type, _, _ = unstructured.NestedString(m, "type")
+		cond.Status, _, _ = unstructured.NestedString(m, "status")
+		cond.Reason, _, _ = unstructured.NestedString(m, "reason")
+		cond.Message, _, _ = unstructured.NestedString(m, "message")
+		conds = append(conds, cond)
+	}
+	return conds
+}
+
+// FindGatewayCondition returns the condition of the given type, or nil.
+func FindGatewayCondition(conds []GatewayCondition, condType string) *GatewayCondition {
+	for i := range conds {
+		if conds[i].Type == condType {
+			return &conds[i]
+		}
+	}
+	return nil
+}
+
+func parseGatewayClass(item *unstructured.Unstructured) GatewayClassInfo {
+	gc := GatewayClassInfo{Name: item.GetName()}
+	gc.ControllerName, _, _ = unstructured.NestedString(item.Object, "spec", "controllerName")
+	gc.Conditions = parseConditions(item.Object, "status", "conditions")
+	return gc
+}
+
+func parseGateway(item *unstructured.Unstructured) GatewayInfo {
+	gw := GatewayInfo{Namespace: item.GetNamespace(), Name: item.GetName()}
+	gw.ClassName, _, _ = unstructured.NestedString(item.Object, "spec", "gatewayClassName")
+	gw.Conditions = parseConditions(item.Object, "status", "conditions")
+
+	addrs, _, _ := unstructured.NestedSlice(item.Object, "status", "addresses")
+	for _, a := range addrs {
+		if m, ok := a.(map[string]interface{}); ok {
+			if v, _, _ := unstructured.NestedString(m, "value"); v != "" {
+				gw.Addresses = append(gw.Addresses, v)
+			}
+		}
+	}
+
+	listenerStatus := make(map[string]map[string]interface{})
+	statuses, _, _ := unstructured.NestedSlice(item.Object, "status", "listeners")
+	for _, s := range statuses {
+		if m, ok := s.(map[string]interface{}); ok {
+			name, _, _ := unstructured.NestedString(m, "name")
+			listenerStatus[name] = m
+		}
+	}
+
+	listeners, _, _ := unstructured.NestedSlice(item.Object, "spec", "listeners")
+	for _, l := range listeners {
+		m, ok := l.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var ln GatewayListener
+		ln.Name, _, _ = unstructured.NestedString(m, "name")
+		ln.Hostname, _, _ = unstructured.NestedString(m, "hostname")
+		ln.Protocol, _, _ = unstructured.NestedString(m, "protocol")
+		ln.Port, _, _ = unstructured.NestedInt64(m, "port")
+		ln.AllowedRoutesFrom, _, _ = unstructured.NestedString(m, "allowedRoutes", "namespaces", "from")
+		if ln.AllowedRoutesFrom == "" {
+			ln.AllowedRoutesFrom = "Same"
+		}
+		kinds, _, _ := unstructured.NestedSlice(m, "allowedRoutes", "kinds")
+		for _, k := range kinds {
+			if km, ok := k.(map[string]interface{}); ok {
+				if kind, _, _ := unstructured.NestedString(km, "kind"); kind != "" {
+					ln.AllowedKinds = append(ln.AllowedKinds, kind)
+				}
+			}
+		}
+		certs, _, _ := unstructured.NestedSlice(m, "tls", "certificateRefs")
+		for _, cr := range certs {
+			if cm, ok := cr.(map[string]interface{}); ok {
+				name, _, _ := unstructured.NestedString(cm, "name")
+				ns, _, _ := unstructured.NestedString(cm, "namespace")
+				if ns == "" {
+					ns = gw.Namespace
+				}
+				ln.CertificateRefs = append(ln.CertificateRefs, ns+"/"+name)
+			}
+		}
+		if st, ok := listenerStatus[ln.Name]; ok {
+			ln.AttachedRoutes, _, _ = unstructured.NestedInt64(st, "attachedRoutes")
+			ln.Conditions = parseConditions(st, "conditions")
+		}
+		gw.Listeners = append(gw.Listeners, ln)
+	}
+	return gw
+}
+
+func parseParentRef(m map[string]interface{}, routeNamespace string) RouteParentRef {
+	var ref RouteParentRef
+	ref.Name, _, _ = unstructured.NestedString(m, "name")
+	ref.Namespace, _, _ = unstructured.NestedString(m, "namespace")
+	if ref.Namespace == "" {
+		ref.Namespace = routeNamespace
+	}
+	ref.SectionName, _, _ = unstructured.NestedString(m, "sectionName")
+	ref.Port, _, _ = unstructured.NestedInt64(m, "port")
+	return ref
+}
+
+func parseRoute(item *unstructured.Unstructured, kind string) GatewayRoute {
+	route := GatewayRoute{Kind: kind, Namespace: item.GetNamespace(), Name: item.GetName(), Created: item.GetCreationTimestamp().Time}
+	route.Hostnames, _, _ = unstructured.NestedStringSlice(item.Object, "spec", "hostnames")
+
+	parents, _, _ := unstructured.NestedSlice(item.Object, "spec", "parentRefs")
+	for _, p := range parents {
+		if m, ok := p.(map[string]interface{}); ok {
+			route.ParentRefs = append(route.ParentRefs, parseParentRef(m, route.Namespace))
+		}
+	}
+
+	rules, _, _ := unstructured.NestedSlice(item.Object, "spec", "rules")
+	for _, r := range rules {
+		rm, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var rule RouteRule
+		matches, _, _ := unstructured.NestedSlice(rm, "matches")
+		for _, mt := range matches {
+			mm, ok := mt.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			var match RouteMatch
+			if kind == KindGRPCRoute {
+				match.GRPCService, _, _ = unstructured.NestedString(mm, "method", "service")
+				match.GRPCMethod, _, _ = unstructured.NestedString(mm, "method", "method")
+			} else {
+				match.PathType, _, _ = unstructured.NestedString(mm, "path", "type")
+				match.PathValue, _, _ = unstructured.NestedString(mm, "path", "value")
+				match.Method, _, _ = unstructured.NestedString(mm, "method")
+			}
+			headers, _, _ := unstructured.NestedSlice(mm, "headers")
+			for _, h := range headers {
+				if hm, ok := h.(map[string]interface{}); ok {
+					name, _, _ := unstructured.NestedString(hm, "name")
+					value, _, _ := unstructured.NestedString(hm, "value")
+					match.Headers = append(match.Headers, name+"="+value)
+				}
+			}
+			rule.Matches = append(rule.Matches, match)
+		}
+		backends, _, _ := unstructured.NestedSlice(rm, "backendRefs")
+		for _, b := range backends {
+			bm, ok := b.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			ref := RouteBackendRef{Kind: "Service", Namespace: route.Namespace, Weight: 1}
+			if g, found, _ := unstructured.NestedString(bm, "group"); found {
+				ref.Group = g
+			}
+			if k, found, _ := unstructured.NestedString(bm, "kind"); found && k != "" {
+				ref.Kind = k
+			}
+			if ns, _, _ := unstructured.NestedString(bm, "namespace"); ns != "" {
+				ref.Namespace = ns
+			}
+			ref.Name, _, _ = unstructured.NestedString(bm, "name")
+			ref.Port, _, _ = unstructured.NestedInt64(bm, "port")
+			if w, found, _ := unstructured.NestedInt64(bm, "weight"); found {
+				ref.Weight = w
+			}
+			rule.BackendRefs = append(rule.BackendRefs, ref)
+		}
+		route.Rules = append(route.Rules, rule)
+	}
+
+	statuses, _, _ := unstructured.NestedSlice(item.Object, "status", "parents")
+	for _, s := range statuses {
+		sm, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var ps RouteParentStatus
+		if ref, ok := sm["parentRef"].(map[string]interface{}); ok {
+			ps.ParentRef = parseParentRef(ref, route.Namespace)
+		}
+		ps.ControllerName, _, _ = unstructured.NestedString(sm, "controllerName")
+		ps.Conditions = parseConditions(sm, "conditions")
+		route.Parents = append(route.Parents, ps)
+	}
+	return route
+}
+
+func parseReferenceGrant(item *unstructured.Unstructured) ReferenceGrantInfo {
+	g := ReferenceGrantInfo{Namespace: item.GetNamespace(), Name: item.GetName()}
+	from, _, _ := unstructured.NestedSlice(item.Object, "spec", "from")
+	for _, f := range from {
+		if m, ok := f.(map[string]interface{}); ok {
+			var rf ReferenceGrantFrom
+			rf.Group, _, _ = unstructured.NestedString(m, "group")
+			rf.Kind, _, _ = unstructured.NestedString(m, "kind")
+			rf.Namespace, _, _ = unstructured.NestedString(m, "namespace")
+			g.From = append(g.From, rf)
+		}
+	}
+	to, _, _ := unstructured.NestedSlice(item.Object, "spec", "to")
+	for _, t := range to {
+		if m, ok := t.(map[string]interface{}); ok {
+			var rt ReferenceGrantTo
+			rt.Group, _, _ = unstructured.NestedString(m, "group")
+			rt.Kind, _, _ = unstructured.NestedString(m, "kind")
+			rt.Name, _, _ = unstructured.NestedString(m, "name")
+			g.To = append(g.To, rt)
+		}
+	}
+	return g
+}
+
+// ReferencePermitted reports whether an object of fromKind in fromNamespace may reference the target
+// (toGroup/toKind/toName in toNamespace). Same-namespace references are always allowed; cross-namespace
+// references need a ReferenceGrant in the target namespace.
+func ReferencePermitted(grants []ReferenceGrantInfo, fromGroup, fromKind, fromNamespace, toGroup, toKind, toNamespace, toName string) bool {
+	if fromNamespace == toNamespace {
+		return true
+	}
+	for _, g := range grants {
+		if g.Namespace != toNamespace {
+			continue
+		}
+		fromOK := false
+		for _, f := range g.From {
+			if f.Group == fromGroup && f.Kind == fromKind && f.Namespace == fromNamespace {
+				fromOK = true
+				break
+			}
+		}
+		if !fromOK {
+			continue
+		}
+		for _, t := range g.To {
+			if t.Group == toGroup && t.Kind == toKind && (t.Name == "" || t.Name == toName) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// BackendRefPermitted reports whether a route may reference the backend under ReferenceGrant rules.
+func BackendRefPermitted(grants []ReferenceGrantInfo, route *GatewayRoute, ref RouteBackendRef) bool {
+	return ReferencePermitted(grants, GatewayAPIGroup, route.Kind, route.Namespace, ref.Group, ref.Kind, ref.Namespace, ref.Name)
+}
+
+// HostnameMatches reports whether host matches a Gateway API hostname pattern. A leading "*." wildcard
+// matches one or more labels; an empty pattern matches every host.
+func HostnameMatches(pattern, host string) bool {
+	pattern = strings.ToLower(pattern)
+	host = strings.ToLower(host)
+	if pattern == "" || pattern == host {
+		return true
+	}
+	if strings.HasPrefix(pattern, "*.") {
+		suffix := pattern[1:]
+		return strings.HasSuffix(host, suffix) && len(host) > len(suffix)
+	}
+	return false
+}
+
+// hostnamesIntersect reports whether two hostname patterns can match a common host.
+func hostnamesIntersect(a, b string) bool {
+	return HostnameMatches(a, b) || HostnameMatches(b, a)
+}
+
+// RouteHostMatches reports whether the route accepts host. Routes without hostnames accept every host.
+func RouteHostMatches(route *GatewayRoute, host string) bool {
+	if len(route.Hostnames) == 0 {
+		return true
+	}
+	for _, h := range route.Hostnames {
+		if HostnameMatches(h, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// RouteMatchesPath reports whether a request path satisfies a route match. HTTP matches default to
+// PathPrefix "/"; PathPrefix compares whole path segments. GRPCRoute matches compare "/service/method".
+func RouteMatchesPath(kind string, m RouteMatch, path string) bool {
+	if kind == KindGRPCRoute {
+		parts := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)
+		if m.GRPCService != "" && parts[0] != m.GRPCService {
+			return false
+		}
+		if m.GRPCMethod != "" && (len(parts) < 2 || parts[1] != m.GRPCMethod) {
+			return false
+		}
+		return true
+	}
+	value := m.PathValue
+	if value == "" {
+		value = "/"
+	}
+	switch m.PathType {
+	case "Exact":
+		return path == value
+	case "RegularExpression":
+		re, err := regexp.Compile("^(?:" + value + ")$")
+		return err == nil && re.MatchString(path)
+	default: // PathPrefix
+		prefix := strings.TrimSuffix(value, "/")
+		if prefix == "" {
+			return true
+		}
+		return path == prefix || strings.HasPrefix(path, prefix+"/")
+	}
+}
+
+// GatewayRouteMatch is the route rule selected for a host and path.
+type GatewayRouteMatch struct {
+	Route     *GatewayRoute
+	RuleIndex int
+	Rule      *RouteRule
+	// Match is the rule match that selected the request. A rule without matches is treated as a
+	// single PathPrefix "/" match (match-all for GRPCRoutes).
+	Match *RouteMatch
+	// Hostname is the route hostname that matched ("" when the route accepts all hosts).
+	Hostname string
+}
+
+// FindRouteForHostPath selects the route rule that serves host+path, following Gateway API precedence:
+// exact hostnames beat wildcards, Exact paths beat prefixes, longer prefixes beat shorter ones, and
+// ties between routes go to the oldest route, then to the first by namespace/name. Only
+// routes that a Gateway has accepted (status.parents Accepted=True) on a listener whose hostname
+// matches host are considered, since no other route receives traffic for the host.
+func (r *GatewayAPIResources) FindRouteForHostPath(host, path string) *GatewayRouteMatch {
+	var best *GatewayRouteMatch
+	bestScore := -1
+	for i := range r.Routes {
+		route := &r.Routes[i]
+		if !r.routeServesHost(route, host) {
+			continue
+		}
+		matchedHost, hostScore := "", 0
+		if len(route.Hostnames) > 0 {
+			found := false
+			for _, h := range route.Hostnames {
+				if !HostnameMatches(h, host) {
+					continue
+				}
+				score := 1
+				if !strings.HasPrefix(h, "*") {
+					score = 2
+				}
+				if !found || score > hostScore {
+					matchedHost, hostScore, found = h, score, true
+				}
+			}
+			if !found {
+				continue
+			}
+		}
+		for j := range route.Rules {
+			rule := &route.Rules[j]
+			matches := rule.Matches
+			if len(matches) == 0 {
+				matches = []RouteMatch{implicitRouteMatch(route.Kind)}
+			}
+			for k := range matches {
+				m := &matches[k]
+				if !RouteMatchesPath(route.Kind, *m, path) {
+					continue
+				}
+				score := hostScore*1_000_000 + matchSpecificity(route.Kind, *m)
+				if score > bestScore || (score == bestScore && best.Route != route && routeOlder(route, best.Route)) {
+					best = &GatewayRouteMatch{Route: route, RuleIndex: j, Rule: rule, Match: m, Hostname: matchedHost}
+					bestScore = score
+				}
+			}
+		}
+	}
+	return best
+}
+
+// routeOlder reports whether route a takes precedence over b when their matches tie: the older
+// creationTimestamp wins, then the alphabetically first namespace/name.
+func routeOlder(a, b *GatewayRoute) bool {
+	if !a.Created.Equal(b.Created) {
+		return a.Created.Before(b.Created)
+	}
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Name < b.Name
+}
+
+// routeServesHost reports whether a Gateway has accepted the route on at least one listener whose
+// hostname matches host.
+func (r *GatewayAPIResources) routeServesHost(route *GatewayRoute, host string) bool {
+	for _, att := range r.RouteAttachments(route) {
+		if att.Accepted() != "True" {
+			continue
+		}
+		for _, ln := range att.Listeners {
+			if HostnameMatches(ln.Hostname, host) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// implicitRouteMatch is the match a rule without matches behaves as.
+func implicitRouteMatch(kind string) RouteMatch {
+	if kind == KindGRPCRoute {
+		return RouteMatch{}
+	}
+	return RouteMatch{PathType: "PathPrefix", PathValue: "/"}
+}
+
+func matchSpecificity(kind string, m RouteMatch) int {
+	score := len(m.Headers)
+	if m.Method != "" {
+		score++
+	}
+	if kind == KindGRPCRoute {
+		if m.GRPCService != "" {
+			score += 1000
+		}
+		if m.GRPCMethod != "" {
+			score += 1000
+		}
+		return score
+	}
+	switch m.PathType {
+	case "Exact":
+		return 500_000 + score
+	case "RegularExpression":
+		return 250_000 + score
+	default:
+		return len(m.PathValue)*10 + score
+	}
+}
+
+// RouteAttachment describes how a route attaches to one of its parent Gateways.
+type RouteAttachment struct {
+	ParentRef RouteParentRef
+	// Gateway is nil when the referenced Gateway does not exist.
+	Gateway *GatewayInfo
+	// Listeners are the listeners the route can bind to.
+	Listeners []*GatewayListener
+	// Problem explains why no listener accepts the route (empty when it can attach).
+	Problem string
+	// Status is the controller-reported parent status, nil when the controller has not reported one.
+	Status *RouteParentStatus
+}
+
+// Accepted returns the controller's Accepted condition status ("True", "False" or "Unknown").
+func (a RouteAttachment) Accepted() string {
+	if a.Status == nil {
+		return "Unknown"
+	}
+	if c := FindGatewayCondition(a.Status.Conditions, "Accepted"); c != nil {
+		return c.Status
+	}
+	return "Unknown"
+}
+
+// RouteAttachments resolves every parentRef of a route against the Gateways and their listeners,
+// applying listener sectionName/port, protocol, allowedRoutes and hostname intersection rules.
+// Namespace selectors cannot be evaluated statically and are treated as allowing the route.
+func (r *GatewayAPIResources) RouteAttachments(route *GatewayRoute) []RouteAttachment {
+	var out []RouteAttachment
+	for _, ref := range route.ParentRefs {
+		att := RouteAttachment{ParentRef: ref, Status: route.ParentStatus(ref)}
+		att.Gateway = r.FindGateway(ref.Namespace, ref.Name)
+		if att.Gateway == nil {
+			att.Problem = fmt.Sprintf("Gateway %s/%s not found", ref.Namespace, ref.Name)
+			out = append(out, att)
+			continue
+		}
+		var reasons []string
+		for i := range att.Gateway.Listeners {
+			ln := &att.Gateway.Listeners[i]
+			if ref.SectionName != "" && ln.Name != ref.SectionName {
+				continue
+			}
+			if ref.Port != 0 && ln.Port != ref.Port {
+				continue
+			}
+			if reason := listenerRejects(ln, att.Gateway, route); reason != "" {
+				reasons = append(reasons, fmt.Sprintf("listener '%s' %s", ln.Name, reason))
+				continue
+			}
+			att.Listeners = append(att.Listeners, ln)
+		}
+		if len(att.Listeners) == 0 {
+			if len(reasons) == 0 {
+				att.Problem = fmt.Sprintf("no listener on Gateway %s/%s matches sectionName/port of the parentRef", ref.Namespace, ref.Name)
+			} else {
+				att.Problem = strings.Join(reasons, "; ")
+			}
+		}
+		out = append(out, att)
+	}
+	return out
+}
+
+func listenerRejects(ln *GatewayListener, gw *GatewayInfo, route *GatewayRoute) string {
+	if len(ln.AllowedKinds) > 0 {
+		if !containsValue(ln.AllowedKinds, route.Kind) {
+			return fmt.Sprintf("only allows kinds %s", strings.Join(ln.AllowedKinds, ", "))
+		}
+	} else if ln.Protocol != "HTTP" && ln.Protocol != "HTTPS" {
+		return fmt.Sprintf("uses protocol %s which does not accept %s", ln.Protocol, route.Kind)
+	}
+	if ln.AllowedRoutesFrom == "Same" && route.Namespace != gw.Namespace {
+		return fmt.Sprintf("only allows routes from namespace '%s'", gw.Namespace)
+	}
+	if ln.Hostname != "" && len(route.Hostnames) > 0 {
+		for _, h := range route.Hostnames {
+			if hostnamesIntersect(ln.Hostname, h) {
+				return ""
+			}
+		}
+		return fmt.Sprintf("hostname '%s' does not intersect route hostnames %s", ln.Hostname, strings.Join(route.Hostnames, ", "))
+	}
+	return ""
+}
@@ -0,0 +1,348 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apiextfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func gatewayObj(kind, namespace, name string, spec, status map[string]interface{}) *unstructured.Unstructured {
+	meta := map[string]interface{}{"name": name}
+	if namespace != "" {
+		meta["namespace"] = namespace
+	}
+	obj := map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       kind,
+		"metadata":   meta,
+		"spec":       spec,
+	}
+	if status != nil {
+		obj["status"] = status
+	}
+	return &unstructured.Unstructured{Object: obj}
+}
+
+func newGatewayAPIClient(t *testing.T, objs ...runtime.Object) *ClusterClient {
+	t.Helper()
+	gvr := func(resource string) schema.GroupVersionResource {
+		return schema.GroupVersionResource{Group: GatewayAPIGroup, Version: "v1", Resource: resource}
+	}
+	apiext := apiextfake.NewSimpleClientset(
+		reportCRD(GatewayAPIGroup, "gatewayclasses", "v1"),
+		reportCRD(GatewayAPIGroup, "gateways", "v1"),
+		reportCRD(GatewayAPIGroup, "httproutes", "v1"),
+		reportCRD(GatewayAPIGroup, "referencegrants", "v1"),
+	)
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			gvr("gatewayclasses"):  "GatewayClassList",
+			gvr("gateways"):        "GatewayList",
+			gvr("httproutes"):      "HTTPRouteList",
+			gvr("referencegrants"): "ReferenceGrantList",
+		}, objs...)
+	return NewClusterClientForTestingWithDynamic(fake.NewSimpleClientset(), apiext, dyn)
+}
+
+func TestGetGatewayAPIResources(t *testing.T) {
+	gw := gatewayObj("Gateway", "infra", "public", map[string]interface{}{
+		"gatewayClassName": "envoy",
+		"listeners": []interface{}{
+			map[string]interface{}{
+				"name": "https", "protocol": "HTTPS", "port": int64(443), "hostname": "*.example.com",
+				"allowedRoutes": map[string]interface{}{"namespaces": map[string]interface{}{"from": "All"}},
+				"tls": map[string]interface{}{"certificateRefs": []interface{}{
+					map[string]interface{}{"name": "wildcard-cert"},
+				}},
+			},
+			map[string]interface{}{"name": "internal", "protocol": "HTTP", "port": int64(80)},
+		},
+	}, map[string]interface{}{
+		"addresses": []interface{}{map[string]interface{}{"value": "20.1.2.3"}},
+		"conditions": []interface{}{
+			map[string]interface{}{"type": "Programmed", "status": "True"},
+		},
+		"listeners": []interface{}{
+			map[string]interface{}{"name": "https", "attachedRoutes": int64(1)},
+		},
+	})
+	route := gatewayObj("HTTPRoute", "shop", "store", map[string]interface{}{
+		"hostnames": []interface{}{"store.example.com"},
+		"parentRefs": []interface{}{
+			map[string]interface{}{"name": "public", "namespace": "infra"},
+		},
+		"rules": []interface{}{
+			map[string]interface{}{
+				"matches": []interface{}{
+					map[string]interface{}{"path": map[string]interface{}{"type": "PathPrefix", "value": "/api"}},
+				},
+				"backendRefs": []interface{}{
+					map[string]interface{}{"name": "api", "port": int64(8080)},
+					map[string]interface{}{"name": "legacy", "namespace": "legacy", "port": int64(80), "weight": int64(0)},
+				},
+			},
+			map[string]interface{}{
+				"backendRefs": []interface{}{map[string]interface{}{"name": "web", "port": int64(80)}},
+			},
+		},
+	}, map[string]interface{}{
+		"parents": []interface{}{
+			map[string]interface{}{
+				"parentRef":      map[string]interface{}{"name": "public", "namespace": "infra"},
+				"controllerName": "gateway.envoyproxy.io/gatewayclass-controller",
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Accepted", "status": "True"},
+					map[string]interface{}{"type": "ResolvedRefs", "status": "False", "reason": "RefNotPermitted"},
+				},
+			},
+		},
+	})
+	route.SetCreationTimestamp(metav1.NewTime(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)))
+	grant := gatewayObj("ReferenceGrant", "legacy", "allow-shop", map[string]interface{}{
+		"from": []interface{}{map[string]interface{}{"group": GatewayAPIGroup, "kind": "HTTPRoute", "namespace": "other"}},
+		"to":   []interface{}{map[string]interface{}{"group": "", "kind": "Service"}},
+	}, nil)
+
+	client := newGatewayAPIClient(t, route, grant)
+	// The fake tracker guesses "gatewaies" from the kind, so create the Gateway through its GVR.
+	gwGVR := schema.GroupVersionResource{Group: GatewayAPIGroup, Version: "v1", Resource: "gateways"}
+	if _, err := client.DynamicClient.Resource(gwGVR).Namespace("infra").Create(context.Background(), gw, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("creating gateway: %v", err)
+	}
+	res, err := client.GetGatewayAPIResources(context.Background(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.AnyInstalled() || res.Installed[GRPCRouteCRD] {
+		t.Errorf("unexpected installed map: %v", res.Installed)
+	}
+	if len(res.Gateways) != 1 || len(res.Routes) != 1 || len(res.Grants) != 1 {
+		t.Fatalf("got %d gateways, %d routes, %d grants", len(res.Gateways), len(res.Routes), len(res.Grants))
+	}
+
+	g := res.Gateways[0]
+	if g.ClassName != "envoy" || len(g.Addresses) != 1 || len(g.Listeners) != 2 {
+		t.Errorf("unexpected gateway: %+v", g)
+	}
+	if l := g.Listeners[0]; l.AttachedRoutes != 1 || l.AllowedRoutesFrom != "All" || l.CertificateRefs[0] != "infra/wildcard-cert" {
+		t.Errorf("unexpected https listener: %+v", l)
+	}
+	if l := g.Listeners[1]; l.AllowedRoutesFrom != "Same" {
+		t.Errorf("allowedRoutes should default to Same, got %q", l.AllowedRoutesFrom)
+	}
+
+	r := &res.Routes[0]
+	if want := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC); !r.Created.Equal(want) {
+		t.Errorf("Created = %v, want %v", r.Created, want)
+	}
+	if len(r.Rules) != 2 || len(r.Rules[0].BackendRefs) != 2 {
+		t.Fatalf("unexpected route rules: %+v", r.Rules)
+	}
+	if b := r.Rules[0].BackendRefs[1]; !b.IsService() || b.Namespace != "legacy" || b.Weight != 0 {
+		t.Errorf("unexpected backendRef: %+v", b)
+	}
+	if BackendRefPermitted(res.Grants, r, r.Rules[0].BackendRefs[1]) {
+		t.Error("cross-namespace backendRef should not be permitted by a grant for another namespace")
+	}
+	if !BackendRefPermitted(res.Grants, r, r.Rules[0].BackendRefs[0]) {
+		t.Error("same-namespace backendRef should always be permitted")
+	}
+
+	atts := res.RouteAttachments(r)
+	if len(atts) != 1 || atts[0].Gateway == nil || atts[0].Accepted() != "True" {
+		t.Fatalf("unexpected attachments: %+v", atts)
+	}
+	if len(atts[0].Listeners) != 1 || atts[0].Listeners[0].Name != "https" {
+		t.Errorf("route should only bind to the https listener (internal allows Same namespace only): %+v", atts[0])
+	}
+
+	m := res.FindRouteForHostPath("store.example.com", "/api/orders")
+	if m == nil || m.RuleIndex != 0 {
+		t.Fatalf("expected rule 1 to match, got %+v", m)
+	}
+	if m := res.FindRouteForHostPath("store.example.com", "/checkout"); m == nil || m.RuleIndex != 1 {
+		t.Errorf("expected catch-all rule 2 to match, got %+v", m)
+	}
+	if m := res.FindRouteForHostPath("other.example.com", "/api"); m != nil {
+		t.Errorf("expected no match for foreign host, got %+v", m)
+	}
+}
+
+func TestGetGatewayAPIResourcesNotInstalled(t *testing.T) {
+	client := NewClusterClientForTestingWithDynamic(fake.NewSimpleClientset(), apiextfake.NewSimpleClientset(),
+		dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()))
+	res, err := client.GetGatewayAPIResources(context.Background(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.AnyInstalled() {
+		t.Errorf("expected no Gateway API CRDs, got %v", res.Installed)
+	}
+}
+
+func TestHostnameMatches(t *testing.T) {
+	tests := []struct {
+		pattern, host string
+		want          bool
+	}{
+		{"", "a.example.com", true},
+		{"a.example.com", "A.example.com", true},
+		{"*.example.com", "a.example.com", true},
+		{"*.example.com", "a.b.example.com", true},
+		{"*.example.com", "example.com", false},
+		{"a.example.com", "b.example.com", false},
+	}
+	for _, tt := range tests {
+		if got := HostnameMatches(tt.pattern, tt.host); got != tt.want {
+			t.Errorf("HostnameMatches(%q, %q) = %v, want %v", tt.pattern, tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestRouteMatchesPath(t *testing.T) {
+	tests := []struct {
+		name  string
+		kind  string
+		match RouteMatch
+		path  string
+		want  bool
+	}{
+		{"default prefix", KindHTTPRoute, RouteMatch{}, "/anything", true},
+		{"prefix segment", KindHTTPRoute, RouteMatch{PathType: "PathPrefix", PathValue: "/api"}, "/api/v1", true},
+		{"prefix exact", KindHTTPRoute, RouteMatch{PathType: "PathPrefix", PathValue: "/api/"}, "/api", true},
+		{"prefix partial segment", KindHTTPRoute, RouteMatch{PathType: "PathPrefix", PathValue: "/api"}, "/apis", false},
+		{"exact", KindHTTPRoute, RouteMatch{PathType: "Exact", PathValue: "/login"}, "/login/", false},
+		{"regex", KindHTTPRoute, RouteMatch{PathType: "RegularExpression", PathValue: "/users/[0-9]+"}, "/users/42", true},
+		{"grpc service", KindGRPCRoute, RouteMatch{GRPCService: "shop.Cart"}, "/shop.Cart/Add", true},
+		{"grpc method", KindGRPCRoute, RouteMatch{GRPCService: "shop.Cart", GRPCMethod: "Remove"}, "/shop.Cart/Add", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RouteMatchesPath(tt.kind, tt.match, tt.path); got != tt.want {
+				t.Errorf("RouteMatchesPath = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// acceptedRoute returns an HTTPRoute attached to infra/public and accepted by its controller.
+func acceptedRoute(name string, hostnames []string, rules ...RouteRule) GatewayRoute {
+	ref := RouteParentRef{Namespace: "infra", Name: "public"}
+	return GatewayRoute{
+		Kind: KindHTTPRoute, Namespace: "a", Name: name, Hostnames: hostnames,
+		ParentRefs: []RouteParentRef{ref},
+		Rules:      rules,
+		Parents: []RouteParentStatus{{ParentRef: ref, Conditions: []GatewayCondition{
+			{Type: "Accepted", Status: "True"},
+		}}},
+	}
+}
+
+func publicGateway(listenerHostname string) GatewayInfo {
+	return GatewayInfo{Namespace: "infra", Name: "public", Listeners: []GatewayListener{{
+		Name: "https", Protocol: "HTTPS", Port: 443, Hostname: listenerHostname, AllowedRoutesFrom: "All",
+	}}}
+}
+
+func pathRule(pathType, value string) RouteRule {
+	return RouteRule{Matches: []RouteMatch{{PathType: pathType, PathValue: value}}}
+}
+
+func TestFindRouteForHostPathPrecedence(t *testing.T) {
+	res := &GatewayAPIResources{
+		Gateways: []GatewayInfo{publicGateway("*.example.com")},
+		Routes: []GatewayRoute{
+			acceptedRoute("wildcard", []string{"*.example.com"}, pathRule("PathPrefix", "/api/v1")),
+			acceptedRoute("exact-host", []string{"shop.example.com"}, pathRule("PathPrefix", "/"), pathRule("PathPrefix", "/api")),
+		},
+	}
+	m := res.FindRouteForHostPath("shop.example.com", "/api/v1/items")
+	if m == nil || m.Route.Name != "exact-host" || m.RuleIndex != 1 {
+		t.Errorf("expected exact-host rule 2, got %+v", m)
+	}
+	m = res.FindRouteForHostPath("blog.example.com", "/api/v1/items")
+	if m == nil || m.Route.Name != "wildcard" || m.Hostname != "*.example.com" {
+		t.Errorf("expected wildcard route, got %+v", m)
+	}
+}
+
+func TestFindRouteForHostPathTieBreak(t *testing.T) {
+	now := time.Now()
+	older := acceptedRoute("z-older", []string{"shop.example.com"}, pathRule("PathPrefix", "/api"))
+	older.Created = now.Add(-time.Hour)
+	newer := acceptedRoute("a-newer", []string{"shop.example.com"}, pathRule("PathPrefix", "/api"))
+	newer.Created = now
+
+	res := &GatewayAPIResources{
+		Gateways: []GatewayInfo{publicGateway("")},
+		Routes:   []GatewayRoute{newer, older},
+	}
+	if m := res.FindRouteForHostPath("shop.example.com", "/api/items"); m == nil || m.Route.Name != "z-older" {
+		t.Errorf("ties should go to the oldest route, got %+v", m)
+	}
+
+	sameAge := acceptedRoute("b-route", []string{"shop.example.com"}, pathRule("PathPrefix", "/api"))
+	sameAge.Created = older.Created
+	res.Routes = []GatewayRoute{older, sameAge}
+	if m := res.FindRouteForHostPath("shop.example.com", "/api/items"); m == nil || m.Route.Name != "b-route" {
+		t.Errorf("ties between routes of the same age should go to the first by namespace/name, got %+v", m)
+	}
+}
+
+func TestFindRouteForHostPathAttachment(t *testing.T) {
+	rejected := acceptedRoute("rejected", []string{"shop.example.com"}, pathRule("Exact", "/cart"))
+	rejected.Parents[0].Conditions[0] = GatewayCondition{Type: "Accepted", Status: "False", Reason: "NotAllowedByListeners"}
+	unreported := acceptedRoute("unreported", []string{"shop.example.com"}, pathRule("Exact", "/cart"))
+	unreported.Parents = nil
+
+	catchAll := acceptedRoute("catch-all", nil, RouteRule{})
+
+	res := &GatewayAPIResources{
+		Gateways: []GatewayInfo{publicGateway("")},
+		Routes:   []GatewayRoute{rejected, unreported, catchAll},
+	}
+	m := res.FindRouteForHostPath("shop.example.com", "/cart")
+	if m == nil || m.Route.Name != "catch-all" {
+		t.Fatalf("routes that are not accepted must not match, got %+v", m)
+	}
+	if m.Match == nil || m.Match.PathType != "PathPrefix" || m.Match.PathValue != "/" {
+		t.Errorf("a rule without matches should match as PathPrefix /, got %+v", m.Match)
+	}
+
+	// A route without hostnames serves only the hosts its listener accepts.
+	res = &GatewayAPIResources{
+		Gateways: []GatewayInfo{publicGateway("*.internal.example.com")},
+		Routes:   []GatewayRoute{catchAll},
+	}
+	if m := res.FindRouteForHostPath("api.internal.example.com", "/"); m == nil {
+		t.Error("expected the route to serve a host matching the listener hostname")
+	}
+	if m := res.FindRouteForHostPath("shop.example.com", "/"); m != nil {
+		t.Errorf("expected no match outside the listener hostname, got %+v", m)
+	}
+}
+
+func TestReferencePermitted(t *testing.T) {
+	grants := []ReferenceGrantInfo{{
+		Namespace: "backend",
+		From:      []ReferenceGrantFrom{{Group: GatewayAPIGroup, Kind: "HTTPRoute", Namespace: "frontend"}},
+		To:        []ReferenceGrantTo{{Group: "", Kind: "Service", Name: "api"}},
+	}}
+	if !ReferencePermitted(grants, GatewayAPIGroup, "HTTPRoute", "frontend", "", "Service", "backend", "api") {
+		t.Error("expected grant to permit frontend HTTPRoute -> backend/api")
+	}
+	if ReferencePermitted(grants, GatewayAPIGroup, "HTTPRoute", "frontend", "", "Service", "backend", "db") {
+		t.Error("grant is limited to Service 'api'")
+	}
+	if ReferencePermitted(grants, GatewayAPIGroup, "GRPCRoute", "frontend", "", "Service", "backend", "api") {
+		t.Error("grant only covers HTTPRoute")
+	}
+}
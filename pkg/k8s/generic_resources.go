@@ -0,0 +1,336 @@
+package k8s
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+// LastAppliedAnnotation holds the client-side apply snapshot that duplicates the whole object.
+const LastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// ResolvedResource is an API resource matched from a user-supplied kind, plural or short name.
+type ResolvedResource struct {
+	GVR        schema.GroupVersionResource
+	Kind       string
+	Namespaced bool
+}
+
+// String returns the resource as "plural.group/version" (or "plural/version" for the core group).
+func (r ResolvedResource) String() string {
+	if r.GVR.Group == "" {
+		return r.GVR.Resource + "/" + r.GVR.Version
+	}
+	return r.GVR.Resource + "." + r.GVR.Group + "/" + r.GVR.Version
+}
+
+// ResolveResource finds the resource a kind refers to, the way kubectl does: query may be a kind,
+// plural, singular or short name, optionally qualified by group ("certificates.cert-manager.io").
+// apiVersion ("group/version" or "v1") pins the group and version. When several groups serve the
+// same name, the core group wins; otherwise the query is ambiguous. Within a group the highest
+// version is chosen.
+func ResolveResource(lists []*metav1.APIResourceList, query, apiVersion string) (*ResolvedResource, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil, fmt.Errorf("kind is required")
+	}
+	name, group, qualified := query, "", false
+	if i := strings.Index(query, "."); i > 0 {
+		name, group, qualified = query[:i], query[i+1:], true
+	}
+	wantGV := schema.GroupVersion{}
+	if apiVersion != "" {
+		gv, err := schema.ParseGroupVersion(apiVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid api_version %q: %w", apiVersion, err)
+		}
+		wantGV = gv
+	}
+
+	var matches []ResolvedResource
+	for _, rl := range lists {
+		gv, err := schema.ParseGroupVersion(rl.GroupVersion)
+		if err != nil {
+			continue
+		}
+		if apiVersion != "" && gv != wantGV {
+			continue
+		}
+		if qualified && gv.Group != group {
+			continue
+		}
+		for _, r := range rl.APIResources {
+			if strings.Contains(r.Name, "/") || !hasVerb(r.Verbs, "get") {
+				continue
+			}
+			if !resourceNameMatches(r, name) {
+				continue
+			}
+			matches = append(matches, ResolvedResource{
+				GVR:        gv.WithResource(r.Name),
+				Kind:       r.Kind,
+				Namespaced: r.Namespaced,
+			})
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no API resource matches %q; use get_api_resources to list the available kinds", query)
+	}
+
+	groups := map[string]bool{}
+	for _, m := range matches {
+		groups[m.GVR.Group] = true
+	}
+	if len(groups) > 1 {
+		if !groups[""] {
+			var candidates []string
+			for g := range groups {
+				candidates = append(candidates, matches[0].GVR.Resource+"."+g)
+			}
+			sort.Strings(candidates)
+			return nil, fmt.Errorf("%q is ambiguous; qualify it with the group: %s", query, strings.Join(candidates, ", "))
+		}
+		core := matches[:0]
+		for _, m := range matches {
+			if m.GVR.Group == "" {
+				core = append(core, m)
+			}
+		}
+		matches = core
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return version.CompareKubeAwareVersionStrings(matches[i].GVR.Version, matches[j].GVR.Version) > 0
+	})
+	return &matches[0], nil
+}
+
+// resourceNameMatches reports whether name (lower case) is the resource's plural, singular, kind or a short name.
+func resourceNameMatches(r metav1.APIResource, name string) bool {
+	if r.Name == name || strings.ToLower(r.SingularName) == name || strings.ToLower(r.Kind) == name {
+		return true
+	}
+	for _, s := range r.ShortNames {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasVerb(verbs []string, verb string) bool {
+	for _, v := range verbs {
+		if v == verb {
+			return true
+		}
+	}
+	return false
+}
+
+// GetDynamicResource fetches one object of any resource with the dynamic client. namespace is
+// ignored for cluster-scoped resources.
+func (c *ClusterClient) GetDynamicResource(ctx context.Context, res *ResolvedResource, namespace, name string) (*unstructured.Unstructured, error) {
+	if c.DynamicClient == nil {
+		return nil, fmt.Errorf("dynamic client not available")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, util.DefaultTimeout)
+	defer cancel()
+
+	if !res.Namespaced {
+		return c.DynamicClient.Resource(res.GVR).Get(ctx, name, metav1.GetOptions{})
+	}
+	return c.DynamicClient.Resource(res.GVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// GetEventsForInvolvedObject returns events about one object, matched on kind and name. Events
+// for cluster-scoped objects are recorded in arbitrary namespaces, so pass "" to search them all.
+func (c *ClusterClient) GetEventsForInvolvedObject(ctx context.Context, namespace, kind, name string) ([]corev1.Event, error) {
+	events, err := c.ListEvents(ctx, namespace, metav1.ListOptions{
+		FieldSelector: fmt.Sprintf("involvedObject.kind=%s,involvedObject.name=%s", kind, name),
+	})
+	if err != nil {
+		return nil, err
+	}
+	matched := events[:0]
+	for _, e := range events {
+		if e.InvolvedObject.Kind == kind && e.InvolvedObject.Name == name {
+			matched = append(matched, e)
+		}
+	}
+	return matched, nil
+}
+
+// SanitizeForDisplay returns a copy of obj without managedFields and the last-applied annotation.
+// Secret values are replaced by their size so that displaying a Secret never leaks its contents.
+func SanitizeForDisplay(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	out := obj.DeepCopy()
+	unstructured.RemoveNestedField(out.Object, "metadata", "managedFields")
+	if ann := out.GetAnnotations(); ann != nil {
+		if _, ok := ann[LastAppliedAnnotation]; ok {
+			delete(ann, LastAppliedAnnotation)
+			if len(ann) == 0 {
+				ann = nil
+			}
+			out.SetAnnotations(ann)
+		}
+	}
+	if out.GetKind() == "Secret" && (out.GetAPIVersion() == "v1" || out.GetAPIVersion() == "") {
+		for _, field := range []string{"data", "stringData"} {
+			values, found, _ := unstructured.NestedMap(out.Object, field)
+			if !found {
+				continue
+			}
+			for k, v := range values {
+				s, _ := v.(string)
+				values[k] = fmt.Sprintf("<redacted, %d bytes>", secretValueSize(field, s))
+			}
+			_ = unstructured.SetNestedMap(out.Object, values, field)
+		}
+	}
+	return out
+}
+
+// secretValueSize returns the decoded size of a Secret value (data is base64-encoded).
+func secretValueSize(field, value string) int {
+	if field == "data" {
+		if decoded, err := base64.StdEncoding.DecodeString(value); err == nil {
+			return len(decoded)
+		}
+	}
+	return len(value)
+}
+
+// ObjectCondition is one entry of status.conditions on an arbitrary object.
+type ObjectCondition struct {
+	Type               string
+	Status             string
+	Reason             string
+	Message            string
+	LastTransitionTime time.Time
+}
+
+// ObjectStatus is a generic summary of an object's status block.
+type ObjectStatus struct {
+	Fields             [][2]string // scalar status fields such as phase or replicas, sorted by key
+	Conditions         []ObjectCondition
+	Generation         int64
+	ObservedGeneration int64 // -1 when the object does not report one
+	DeletionTimestamp  *time.Time
+	Finalizers         []string
+	Issues             []ResourceIssue
+}
+
+// ResourceIssue is a problem found in a generic object's status.
+type ResourceIssue struct {
+	Severity string
+	Problem  string
+}
+
+// negativeConditions are condition types that signal a problem when True.
+var negativeConditions = map[string]bool{
+	"Stalled": true, "Degraded": true, "Failed": true, "Failure": true, "ReplicaFailure": true,
+	"MemoryPressure": true, "DiskPressure": true, "PIDPressure": true, "NetworkUnavailable": true,
+	"Suspended": true, "FailureTarget": true,
+}
+
+// positiveConditionSuffixes identify condition types that signal a problem when False.
+var positiveConditionSuffixes = []string{
+	"Ready", "Available", "Healthy", "Synced", "Succeeded", "Established", "Accepted",
+	"Programmed", "ResolvedRefs", "Bound", "Complete", "Initialized", "Scheduled", "Valid",
+}
+
+// ConditionAbnormal reports whether a condition indicates a problem, using the usual polarity:
+// Ready-like conditions are bad when False, Stalled-like conditions are bad when True.
+func ConditionAbnormal(condType, status string) bool {
+	if negativeConditions[condType] || strings.HasSuffix(condType, "Pressure") {
+		return status == "True"
+	}
+	for _, suffix := range positiveConditionSuffixes {
+		if strings.HasSuffix(condType, suffix) {
+			return status == "False"
+		}
+	}
+	return false
+}
+
+// SummarizeObjectStatus extracts conditions, scalar status fields, generation tracking and
+// deletion state from any object and flags the obvious problems.
+func SummarizeObjectStatus(obj *unstructured.Unstructured, now time.Time) *ObjectStatus {
+	st := &ObjectStatus{Generation: obj.GetGeneration(), ObservedGeneration: -1, Finalizers: obj.GetFinalizers()}
+	if ts := obj.GetDeletionTimestamp(); ts != nil {
+		t := ts.Time
+		st.DeletionTimestamp = &t
+	}
+
+	status, _, _ := unstructured.NestedMap(obj.Object, "status")
+	for k, v := range status {
+		switch val := v.(type) {
+		case string, bool, int64, float64:
+			if k == "observedGeneration" {
+				continue
+			}
+			st.Fields = append(st.Fields, [2]string{k, fmt.Sprint(val)})
+		}
+	}
+	sort.Slice(st.Fields, func(i, j int) bool { return st.Fields[i][0] < st.Fields[j][0] })
+	if og, found, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration"); found {
+		st.ObservedGeneration = og
+	}
+
+	conds, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	condObserved := int64(-1)
+	for _, raw := range conds {
+		m, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		c := ObjectCondition{}
+		c.Type, _ = m["type"].(string)
+		c.Status, _ = m["status"].(string)
+		c.Reason, _ = m["reason"].(string)
+		c.Message, _ = m["message"].(string)
+		if ts, ok := m["lastTransitionTime"].(string); ok {
+			c.LastTransitionTime, _ = time.Parse(time.RFC3339, ts)
+		}
+		if og, ok := m["observedGeneration"].(int64); ok && og > condObserved {
+			condObserved = og
+		}
+		st.Conditions = append(st.Conditions, c)
+		if ConditionAbnormal(c.Type, c.Status) {
+			msg := fmt.Sprintf("Condition %s=%s", c.Type, c.Status)
+			if c.Reason != "" {
+				msg += " (" + c.Reason + ")"
+			}
+			if c.Message != "" {
+				msg += ": " + c.Message
+			}
+			st.Issues = append(st.Issues, ResourceIssue{Severity: "WARNING", Problem: msg})
+		}
+	}
+
+	if st.ObservedGeneration == -1 {
+		// Some controllers only report observedGeneration per condition.
+		st.ObservedGeneration = condObserved
+	}
+	if st.ObservedGeneration >= 0 && st.Generation > st.ObservedGeneration {
+		st.Issues = append(st.Issues, ResourceIssue{Severity: "INFO",
+			Problem: fmt.Sprintf("Controller has not observed the latest spec yet (generation %d, observed %d)", st.Generation, st.ObservedGeneration)})
+	}
+	if st.DeletionTimestamp != nil && len(st.Finalizers) > 0 && now.Sub(*st.DeletionTimestamp) > 5*time.Minute {
+		st.Issues = append(st.Issues, ResourceIssue{Severity: "WARNING",
+			Problem: fmt.Sprintf("Deletion requested %s ago but blocked by finalizers: %s",
+				now.Sub(*st.DeletionTimestamp).Round(time.Minute), strings.Join(st.Finalizers, ", "))})
+	}
+	return st
+}
@@ -0,0 +1,216 @@
+package k8s
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var testAPIResources = []*metav1.APIResourceList{
+	{GroupVersion: "v1", APIResources: []metav1.APIResource{
+		{Name: "pods", SingularName: "pod", Kind: "Pod", Namespaced: true, ShortNames: []string{"po"}, Verbs: []string{"get", "list"}},
+		{Name: "pods/log", Kind: "Pod", Namespaced: true, Verbs: []string{"get"}},
+		{Name: "events", SingularName: "event", Kind: "Event", Namespaced: true, ShortNames: []string{"ev"}, Verbs: []string{"get", "list"}},
+		{Name: "nodes", SingularName: "node", Kind: "Node", ShortNames: []string{"no"}, Verbs: []string{"get", "list"}},
+	}},
+	{GroupVersion: "events.k8s.io/v1", APIResources: []metav1.APIResource{
+		{Name: "events", SingularName: "event", Kind: "Event", Namespaced: true, ShortNames: []string{"ev"}, Verbs: []string{"get", "list"}},
+	}},
+	{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{
+		{Name: "deployments", SingularName: "deployment", Kind: "Deployment", Namespaced: true, ShortNames: []string{"deploy"}, Verbs: []string{"get", "list"}},
+	}},
+	{GroupVersion: "cert-manager.io/v1", APIResources: []metav1.APIResource{
+		{Name: "certificates", SingularName: "certificate", Kind: "Certificate", Namespaced: true, ShortNames: []string{"cert", "certs"}, Verbs: []string{"get", "list"}},
+	}},
+	{GroupVersion: "cert-manager.io/v1alpha2", APIResources: []metav1.APIResource{
+		{Name: "certificates", SingularName: "certificate", Kind: "Certificate", Namespaced: true, Verbs: []string{"get", "list"}},
+	}},
+	{GroupVersion: "networking.internal.knative.dev/v1alpha1", APIResources: []metav1.APIResource{
+		{Name: "certificates", SingularName: "certificate", Kind: "Certificate", Namespaced: true, ShortNames: []string{"kcert"}, Verbs: []string{"get", "list"}},
+	}},
+}
+
+func TestResolveResource(t *testing.T) {
+	tests := []struct {
+		query, apiVersion string
+		want              string
+		wantErr           string
+	}{
+		{query: "po", want: "pods/v1"},
+		{query: "Pod", want: "pods/v1"},
+		{query: "deploy", want: "deployments.apps/v1"},
+		{query: "deployments.apps", want: "deployments.apps/v1"},
+		{query: "nodes", want: "nodes/v1"},
+		{query: "events", want: "events/v1"},
+		{query: "ev", apiVersion: "events.k8s.io/v1", want: "events.events.k8s.io/v1"},
+		{query: "cert", want: "certificates.cert-manager.io/v1"},
+		{query: "certificates.cert-manager.io", want: "certificates.cert-manager.io/v1"},
+		{query: "certificate", apiVersion: "cert-manager.io/v1alpha2", want: "certificates.cert-manager.io/v1alpha2"},
+		{query: "certificates", wantErr: "ambiguous"},
+		{query: "widgets", wantErr: "no API resource matches"},
+		{query: "log", wantErr: "no API resource matches"},
+	}
+	for _, tt := range tests {
+		res, err := ResolveResource(testAPIResources, tt.query, tt.apiVersion)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ResolveResource(%q) error = %v, want %q", tt.query, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ResolveResource(%q) error = %v", tt.query, err)
+			continue
+		}
+		if res.String() != tt.want {
+			t.Errorf("ResolveResource(%q) = %s, want %s", tt.query, res.String(), tt.want)
+		}
+	}
+}
+
+func TestGetDynamicResourceAndEvents(t *testing.T) {
+	cert := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1", "kind": "Certificate",
+		"metadata": map[string]interface{}{"name": "web-tls", "namespace": "shop"},
+	}}
+	gvr := schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "CertificateList"}, cert)
+	cs := fake.NewSimpleClientset(
+		&corev1.Event{ObjectMeta: metav1.ObjectMeta{Name: "e1", Namespace: "shop"}, Type: "Warning", Reason: "Failed",
+			InvolvedObject: corev1.ObjectReference{Kind: "Certificate", Name: "web-tls"}},
+		&corev1.Event{ObjectMeta: metav1.ObjectMeta{Name: "e2", Namespace: "shop"}, Reason: "Created",
+			InvolvedObject: corev1.ObjectReference{Kind: "Secret", Name: "web-tls"}},
+	)
+	cs.Discovery().(*fakediscovery.FakeDiscovery).Resources = testAPIResources
+	client := NewClusterClientForTestingWithDynamic(cs, nil, dyn)
+
+	lists, err := client.GetAPIResources(context.Background())
+	if err != nil {
+		t.Fatalf("GetAPIResources() error = %v", err)
+	}
+	res, err := ResolveResource(lists, "cert", "")
+	if err != nil {
+		t.Fatalf("ResolveResource() error = %v", err)
+	}
+	obj, err := client.GetDynamicResource(context.Background(), res, "shop", "web-tls")
+	if err != nil || obj.GetName() != "web-tls" {
+		t.Fatalf("GetDynamicResource() = %v, %v", obj, err)
+	}
+
+	events, err := client.GetEventsForInvolvedObject(context.Background(), "shop", "Certificate", "web-tls")
+	if err != nil {
+		t.Fatalf("GetEventsForInvolvedObject() error = %v", err)
+	}
+	if len(events) != 1 || events[0].Reason != "Failed" {
+		t.Errorf("expected only the Certificate event, got %+v", events)
+	}
+}
+
+func TestSanitizeForDisplay(t *testing.T) {
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1", "kind": "Secret",
+		"metadata": map[string]interface{}{
+			"name": "db", "namespace": "shop",
+			"annotations":   map[string]interface{}{LastAppliedAnnotation: `{"data":{"password":"aHVudGVyMg=="}}`},
+			"managedFields": []interface{}{map[string]interface{}{"manager": "kubectl"}},
+		},
+		"data":       map[string]interface{}{"password": "aHVudGVyMg=="},
+		"stringData": map[string]interface{}{"user": "admin"},
+	}}
+	out := SanitizeForDisplay(secret)
+
+	if _, found, _ := unstructured.NestedSlice(out.Object, "metadata", "managedFields"); found {
+		t.Error("managedFields should be removed")
+	}
+	if out.GetAnnotations() != nil {
+		t.Errorf("last-applied annotation should be removed, got %v", out.GetAnnotations())
+	}
+	if v, _, _ := unstructured.NestedString(out.Object, "data", "password"); v != "<redacted, 7 bytes>" {
+		t.Errorf("data value not redacted: %q", v)
+	}
+	if v, _, _ := unstructured.NestedString(out.Object, "stringData", "user"); v != "<redacted, 5 bytes>" {
+		t.Errorf("stringData value not redacted: %q", v)
+	}
+	if v, _, _ := unstructured.NestedString(secret.Object, "data", "password"); v != "aHVudGVyMg==" {
+		t.Error("the original object must not be modified")
+	}
+}
+
+func TestSummarizeObjectStatus(t *testing.T) {
+	now := time.Now()
+	deleting := metav1.NewTime(now.Add(-time.Hour))
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1", "kind": "Certificate",
+		"metadata": map[string]interface{}{
+			"name": "web-tls", "namespace": "shop", "generation": int64(3),
+			"finalizers": []interface{}{"cert-manager.io/cleanup"},
+		},
+		"status": map[string]interface{}{
+			"notAfter": "2026-01-01T00:00:00Z",
+			"revision": int64(2),
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "False", "reason": "DoesNotExist", "message": "Issuing certificate", "observedGeneration": int64(2)},
+				map[string]interface{}{"type": "Issuing", "status": "True"},
+				map[string]interface{}{"type": "Stalled", "status": "False"},
+			},
+		},
+	}}
+	obj.SetDeletionTimestamp(&deleting)
+
+	st := SummarizeObjectStatus(obj, now)
+	if len(st.Fields) != 2 || st.Fields[0][0] != "notAfter" || st.Fields[1] != [2]string{"revision", "2"} {
+		t.Errorf("unexpected fields: %v", st.Fields)
+	}
+	if len(st.Conditions) != 3 || st.ObservedGeneration != 2 {
+		t.Errorf("unexpected conditions/observedGeneration: %+v", st)
+	}
+	var problems []string
+	for _, i := range st.Issues {
+		problems = append(problems, i.Severity+" "+i.Problem)
+	}
+	joined := strings.Join(problems, "\n")
+	for _, want := range []string{
+		"WARNING Condition Ready=False (DoesNotExist): Issuing certificate",
+		"INFO Controller has not observed the latest spec yet (generation 3, observed 2)",
+		"WARNING Deletion requested 1h0m0s ago but blocked by finalizers: cert-manager.io/cleanup",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("missing issue %q in:\n%s", want, joined)
+		}
+	}
+	if len(st.Issues) != 3 {
+		t.Errorf("expected 3 issues, got:\n%s", joined)
+	}
+}
+
+func TestConditionAbnormal(t *testing.T) {
+	tests := []struct {
+		condType, status string
+		want             bool
+	}{
+		{"Ready", "False", true},
+		{"Ready", "True", false},
+		{"ContainersReady", "False", true},
+		{"Stalled", "True", true},
+		{"Stalled", "False", false},
+		{"MemoryPressure", "True", true},
+		{"Progressing", "False", false},
+		{"Reconciling", "True", false},
+	}
+	for _, tt := range tests {
+		if got := ConditionAbnormal(tt.condType, tt.status); got != tt.want {
+			t.Errorf("ConditionAbnormal(%s, %s) = %v, want %v", tt.condType, tt.status, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,646 @@
+package k8s
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+// IngressControllerProfile describes how to locate and diagnose one ingress controller implementation.
+// Profiles are matched against IngressClass.spec.controller; new controllers are added with
+// RegisterIngressControllerProfile.
+type IngressControllerProfile struct {
+	// Name is a short identifier, e.g. "ingress-nginx".
+	Name string
+	// DisplayName is the human-readable controller name.
+	DisplayName string
+	// Controllers are IngressClass spec.controller values handled by this profile.
+	Controllers []string
+	// PodSelectors are label selectors tried in order to find controller pods.
+	PodSelectors []string
+	// AnnotationPrefix is the prefix of controller-specific Ingress annotations.
+	AnnotationPrefix string
+	// Annotations maps known annotation keys (without prefix) to value validators. A nil validator accepts any value.
+	// Keys ending in "." match every annotation with that prefix.
+	Annotations map[string]AnnotationValidator
+	// LogPatterns are controller-specific log signatures of data-plane or configuration problems.
+	LogPatterns []ControllerLogPattern
+}
+
+// AnnotationValidator returns a description of what is wrong with an annotation value, or "" if it is valid.
+type AnnotationValidator func(value string) string
+
+// ControllerLogPattern is a known controller log signature.
+type ControllerLogPattern struct {
+	Pattern  *regexp.Regexp
+	Severity string
+	// Problem describes what the log line means.
+	Problem string
+	// Hint is the suggested remediation.
+	Hint string
+}
+
+// ControllerLogMatch aggregates log lines matching one pattern.
+type ControllerLogMatch struct {
+	Pattern ControllerLogPattern
+	Count   int
+	// Sample is the most recent matching line.
+	Sample string
+}
+
+// IngressAnnotation is a controller-specific annotation with its prefix stripped.
+type IngressAnnotation struct {
+	Key   string
+	Value string
+}
+
+// AnnotationIssue is a problem with an Ingress annotation.
+type AnnotationIssue struct {
+	Annotation string
+	Value      string
+	Severity   string
+	Problem    string
+}
+
+var (
+	ingressProfilesMu sync.RWMutex
+	ingressProfiles   = []*IngressControllerProfile{
+		agicProfile(),
+		ingressNginxProfile(),
+		traefikProfile(),
+		haproxyProfile(),
+	}
+)
+
+// RegisterIngressControllerProfile adds a controller profile. Profiles registered later take precedence
+// when several claim the same controller value.
+func RegisterIngressControllerProfile(p *IngressControllerProfile) {
+	ingressProfilesMu.Lock()
+	defer ingressProfilesMu.Unlock()
+	ingressProfiles = append([]*IngressControllerProfile{p}, ingressProfiles...)
+}
+
+// IngressControllerProfiles returns all registered profiles.
+func IngressControllerProfiles() []*IngressControllerProfile {
+	ingressProfilesMu.RLock()
+	defer ingressProfilesMu.RUnlock()
+	return append([]*IngressControllerProfile(nil), ingressProfiles...)
+}
+
+// FindIngressControllerProfile returns the profile handling an IngressClass spec.controller value, or nil.
+// Controller values are matched exactly or as a prefix followed by "/" (e.g. "haproxy.org/ingress-controller/haproxy").
+func FindIngressControllerProfile(controller string) *IngressControllerProfile {
+	for _, p := range IngressControllerProfiles() {
+		for _, c := range p.Controllers {
+			if controller == c || strings.HasPrefix(controller, c+"/") {
+				return p
+			}
+		}
+	}
+	return nil
+}
+
+// IngressControllerProfileByName returns the profile with the given name, or nil.
+func IngressControllerProfileByName(name string) *IngressControllerProfile {
+	for _, p := range IngressControllerProfiles() {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// ParseIngressAnnotations extracts annotations with the given prefix from an Ingress, sorted by key.
+func ParseIngressAnnotations(ingress *networkingv1.Ingress, prefix string) []IngressAnnotation {
+	var annotations []IngressAnnotation
+	for k, v := range ingress.Annotations {
+		if strings.HasPrefix(k, prefix) {
+			annotations = append(annotations, IngressAnnotation{Key: strings.TrimPrefix(k, prefix), Value: v})
+		}
+	}
+	sort.Slice(annotations, func(i, j int) bool { return annotations[i].Key < annotations[j].Key })
+	return annotations
+}
+
+// ScanControllerLogs matches log output against the profile's log patterns.
+func ScanControllerLogs(p *IngressControllerProfile, logs string) []ControllerLogMatch {
+	var matches []ControllerLogMatch
+	lines := strings.Split(logs, "\n")
+	for _, pat := range p.LogPatterns {
+		m := ControllerLogMatch{Pattern: pat}
+		for _, line := range lines {
+			if pat.Pattern.MatchString(line) {
+				m.Count++
+				m.Sample = strings.TrimSpace(line)
+			}
+		}
+		if m.Count > 0 {
+			matches = append(matches, m)
+		}
+	}
+	return matches
+}
+
+// ValidateIngressAnnotations checks an Ingress's annotations against the controller profile: invalid values,
+// unknown keys under the controller's prefix (reported as INFO, since they may be typos or annotations newer
+// than the profile), and annotations meant for other controllers, which the serving controller ignores.
+func ValidateIngressAnnotations(p *IngressControllerProfile, ingress *networkingv1.Ingress) []AnnotationIssue {
+	var issues []AnnotationIssue
+	for _, a := range ParseIngressAnnotations(ingress, p.AnnotationPrefix) {
+		full := p.AnnotationPrefix + a.Key
+		validator, known := p.lookupAnnotation(a.Key)
+		if !known {
+			issues = append(issues, AnnotationIssue{Annotation: full, Value: a.Value, Severity: "INFO",
+				Problem: fmt.Sprintf("is not a known %s annotation — check the spelling against the controller documentation", p.DisplayName)})
+			continue
+		}
+		if validator != nil {
+			if problem := validator(a.Value); problem != "" {
+				issues = append(issues, AnnotationIssue{Annotation: full, Value: a.Value, Severity: "WARNING", Problem: problem})
+			}
+		}
+	}
+	for _, other := range IngressControllerProfiles() {
+		if other.Name == p.Name || other.AnnotationPrefix == "" {
+			continue
+		}
+		for _, a := range ParseIngressAnnotations(ingress, other.AnnotationPrefix) {
+			issues = append(issues, AnnotationIssue{Annotation: other.AnnotationPrefix + a.Key, Value: a.Value, Severity: "WARNING",
+				Problem: fmt.Sprintf("%s annotation has no effect on an Ingress served by %s", other.DisplayName, p.DisplayName)})
+		}
+	}
+	return issues
+}
+
+func (p *IngressControllerProfile) lookupAnnotation(key string) (AnnotationValidator, bool) {
+	if v, ok := p.Annotations[key]; ok {
+		return v, true
+	}
+	for k, v := range p.Annotations {
+		if strings.HasSuffix(k, ".") && strings.HasPrefix(key, k) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// --- Annotation validators ---
+
+func validateBool(value string) string {
+	if value != "true" && value != "false" {
+		return "must be \"true\" or \"false\""
+	}
+	return ""
+}
+
+func validateInt(min, max int) AnnotationValidator {
+	return func(value string) string {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < min || (max > 0 && n > max) {
+			if max > 0 {
+				return fmt.Sprintf("must be an integer between %d and %d", min, max)
+			}
+			return fmt.Sprintf("must be an integer >= %d", min)
+		}
+		return ""
+	}
+}
+
+func validateOneOf(values ...string) AnnotationValidator {
+	return func(value string) string {
+		for _, v := range values {
+			if strings.EqualFold(value, v) {
+				return ""
+			}
+		}
+		return "must be one of " + strings.Join(values, ", ")
+	}
+}
+
+func validatePattern(re *regexp.Regexp, want string) AnnotationValidator {
+	return func(value string) string {
+		if !re.MatchString(value) {
+			return "must be " + want
+		}
+		return ""
+	}
+}
+
+func validateCIDRList(value string) string {
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(item); err != nil && net.ParseIP(item) == nil {
+			return fmt.Sprintf("'%s' is not a valid IP or CIDR", item)
+		}
+	}
+	return ""
+}
+
+// validateStatusCodeList accepts a comma-separated list of HTTP status codes.
+func validateStatusCodeList(value string) string {
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if n, err := strconv.Atoi(item); err != nil || n < 100 || n > 599 {
+			return fmt.Sprintf("'%s' is not an HTTP status code", item)
+		}
+	}
+	return ""
+}
+
+// validateSnippet flags ingress-nginx snippet annotations, which are rejected unless the controller allows them.
+func validateSnippet(string) string {
+	return "snippet annotations are disabled by default since ingress-nginx 1.9 and rejected unless allow-snippet-annotations is enabled"
+}
+
+var (
+	sizePattern     = regexp.MustCompile(`^\d+[kKmMgG]?$`)
+	durationPattern = regexp.MustCompile(`^\d+(us|ms|s|m|h|d)?$`)
+)
+
+// --- Built-in profiles ---
+
+func agicProfile() *IngressControllerProfile {
+	probeInt := validateInt(1, 0)
+	return &IngressControllerProfile{
+		Name:             "agic",
+		DisplayName:      "Azure Application Gateway Ingress Controller",
+		Controllers:      []string{"azure/application-gateway"},
+		PodSelectors:     []string{"app=ingress-azure", "app.kubernetes.io/name=ingress-azure"},
+		AnnotationPrefix: AGICAnnotationPrefix,
+		Annotations: map[string]AnnotationValidator{
+			"backend-path-prefix":              nil,
+			"backend-hostname":                 nil,
+			"backend-protocol":                 validateOneOf("http", "https"),
+			"ssl-redirect":                     validateBool,
+			"connection-draining":              validateBool,
+			"connection-draining-timeout":      validateInt(1, 3600),
+			"cookie-based-affinity":            validateBool,
+			"request-timeout":                  validateInt(1, 86400),
+			"use-private-ip":                   validateBool,
+			"override-frontend-port":           validateInt(1, 65535),
+			"appgw-ssl-certificate":            nil,
+			"appgw-ssl-profile":                nil,
+			"appgw-trusted-root-certificate":   nil,
+			"health-probe-hostname":            nil,
+			"health-probe-port":                validateInt(1, 65535),
+			"health-probe-path":                nil,
+			"health-probe-status-codes":        nil,
+			"health-probe-interval":            probeInt,
+			"health-probe-timeout":             probeInt,
+			"health-probe-unhealthy-threshold": probeInt,
+			"waf-policy-for-path":              nil,
+			"rewrite-rule-set":                 nil,
+			"rewrite-rule-set-custom-resource": nil,
+			"hostname-extension":               nil,
+		},
+		LogPatterns: []ControllerLogPattern{
+			{regexp.MustCompile(`(?i)AuthorizationFailed|does not have authorization|StatusCode=403`), "CRITICAL",
+				"AGIC identity is not authorized to update the Application Gateway",
+				"Grant the AGIC identity Contributor on the Application Gateway and Reader on its resource group"},
+			{regexp.MustCompile(`(?i)ApplicationGatewayNotFound|ResourceNotFound|ResourceGroupNotFound`), "CRITICAL",
+				"The configured Application Gateway or resource group was not found",
+				"Check appgw.name / appgw.resourceGroup in the AGIC Helm values"},
+			{regexp.MustCompile(`(?i)BadRequest|InvalidResourceReference|ApplicationGatewayHttpListenersUsingSameFrontendPortAndFrontendIpConfiguration`), "CRITICAL",
+				"ARM rejected the generated Application Gateway configuration",
+				"Look for conflicting listeners/ports across Ingresses and fix the offending annotations"},
+			{regexp.MustCompile(`(?i)unable to get .*service|backend .*not found|Unable to find .*endpoints`), "WARNING",
+				"An Ingress backend Service or its endpoints could not be resolved",
+				"Check the backend Services referenced by Ingresses served by AGIC"},
+		},
+	}
+}
+
+func ingressNginxProfile() *IngressControllerProfile {
+	timeout := validateInt(1, 3600)
+	return &IngressControllerProfile{
+		Name:        "ingress-nginx",
+		DisplayName: "ingress-nginx",
+		Controllers: []string{"k8s.io/ingress-nginx"},
+		PodSelectors: []string{
+			"app.kubernetes.io/name=ingress-nginx,app.kubernetes.io/component=controller",
+			"app.kubernetes.io/name=ingress-nginx",
+			"app=ingress-nginx",
+		},
+		AnnotationPrefix: "nginx.ingress.kubernetes.io/",
+		// Every annotation documented for ingress-nginx, including deprecated aliases that are still read.
+		Annotations: map[string]AnnotationValidator{
+			// Rewrites and redirects
+			"rewrite-target":          nil,
+			"app-root":                nil,
+			"use-regex":               validateBool,
+			"enable-rewrite-log":      validateBool,
+			"x-forwarded-prefix":      nil,
+			"preserve-trailing-slash": validateBool,
+			"ssl-redirect":            validateBool,
+			"force-ssl-redirect":      validateBool,
+			"from-to-www-redirect":    validateBool,
+			"permanent-redirect":      nil,
+			"permanent-redirect-code": validateInt(300, 399),
+			"temporal-redirect":       nil,
+			"temporal-redirect-code":  validateInt(300, 399),
+			"server-alias":            nil,
+
+			// Backend selection and load balancing
+			"backend-protocol":               validateOneOf("HTTP", "HTTPS", "GRPC", "GRPCS", "AJP", "FCGI"),
+			"service-upstream":               validateBool,
+			"upstream-vhost":                 nil,
+			"upstream-hash-by":               nil,
+			"upstream-hash-by-subset":        validateBool,
+			"upstream-hash-by-subset-size":   validateInt(1, 0),
+			"load-balance":                   validateOneOf("round_robin", "ewma"),
+			"default-backend":                nil,
+			"custom-http-errors":             validateStatusCodeList,
+			"disable-proxy-intercept-errors": validateBool,
+
+			// Proxy behaviour
+			"proxy-connect-timeout":       timeout,
+			"proxy-send-timeout":          timeout,
+			"proxy-read-timeout":          timeout,
+			"proxy-next-upstream":         nil,
+			"proxy-next-upstream-timeout": validateInt(0, 0),
+			"proxy-next-upstream-tries":   validateInt(0, 0),
+			"proxy-request-buffering":     validateOneOf("on", "off"),
+			"proxy-body-size":             validatePattern(sizePattern, "a size such as 8m, 1g or 0"),
+			"proxy-buffer-size":           validatePattern(sizePattern, "a size such as 8k"),
+			"proxy-buffers-number":        validateInt(1, 0),
+			"proxy-buffering":             validateOneOf("on", "off"),
+			"proxy-max-temp-file-size":    validatePattern(sizePattern, "a size such as 1024m or 0"),
+			"proxy-http-version":          validateOneOf("1.0", "1.1"),
+			"proxy-cookie-domain":         nil,
+			"proxy-cookie-path":           nil,
+			"proxy-redirect-from":         nil,
+			"proxy-redirect-to":           nil,
+			"client-body-buffer-size":     validatePattern(sizePattern, "a size such as 16k"),
+			"connection-proxy-header":     nil,
+			"custom-headers":              nil,
+			"http2-push-preload":          validateBool,
+
+			// TLS
+			"ssl-passthrough":                       validateBool,
+			"ssl-ciphers":                           nil,
+			"ssl-prefer-server-ciphers":             validateBool,
+			"proxy-ssl-secret":                      nil,
+			"proxy-ssl-ciphers":                     nil,
+			"proxy-ssl-name":                        nil,
+			"proxy-ssl-protocols":                   nil,
+			"proxy-ssl-verify":                      validateOneOf("on", "off"),
+			"proxy-ssl-verify-depth":                validateInt(1, 0),
+			"proxy-ssl-server-name":                 validateOneOf("on", "off"),
+			"secure-verify-ca-secret":               nil,
+			"auth-tls-secret":                       nil,
+			"auth-tls-verify-client":                validateOneOf("on", "off", "optional", "optional_no_ca"),
+			"auth-tls-verify-depth":                 validateInt(1, 0),
+			"auth-tls-error-page":                   nil,
+			"auth-tls-pass-certificate-to-upstream": validateBool,
+			"auth-tls-match-cn":                     nil,
+
+			// CORS
+			"enable-cors":            validateBool,
+			"cors-allow-origin":      nil,
+			"cors-allow-methods":     nil,
+			"cors-allow-headers":     nil,
+			"cors-expose-headers":    nil,
+			"cors-allow-credentials": validateBool,
+			"cors-max-age":           validateInt(0, 0),
+
+			// Rate limiting and access control
+			"limit-rps":                       validateInt(1, 0),
+			"limit-rpm":                       validateInt(1, 0),
+			"limit-connections":               validateInt(1, 0),
+			"limit-burst-multiplier":          validateInt(1, 0),
+			"limit-rate":                      validateInt(0, 0),
+			"limit-rate-after":                validateInt(0, 0),
+			"limit-whitelist":                 validateCIDRList,
+			"limit-allowlist":                 validateCIDRList,
+			"global-rate-limit":               validateInt(1, 0),
+			"global-rate-limit-window":        validatePattern(durationPattern, "a duration such as 1m"),
+			"global-rate-limit-key":           nil,
+			"global-rate-limit-ignored-cidrs": validateCIDRList,
+			"whitelist-source-range":          validateCIDRList,
+			"allowlist-source-range":          validateCIDRList,
+			"denylist-source-range":           validateCIDRList,
+			"satisfy":                         validateOneOf("all", "any"),
+
+			// Session affinity
+			"affinity":                                 validateOneOf("cookie"),
+			"affinity-mode":                            validateOneOf("balanced", "persistent"),
+			"affinity-canary-behavior":                 validateOneOf("sticky", "legacy"),
+			"session-cookie-name":                      nil,
+			"session-cookie-path":                      nil,
+			"session-cookie-domain":                    nil,
+			"session-cookie-expires":                   validateInt(1, 0),
+			"session-cookie-max-age":                   validateInt(1, 0),
+			"session-cookie-secure":                    validateBool,
+			"session-cookie-samesite":                  validateOneOf("None", "Lax", "Strict"),
+			"session-cookie-conditional-samesite-none": validateBool,
+			"session-cookie-change-on-failure":         validateBool,
+
+			// Authentication
+			"auth-type":                  validateOneOf("basic", "digest"),
+			"auth-secret":                nil,
+			"auth-secret-type":           validateOneOf("auth-file", "auth-map"),
+			"auth-realm":                 nil,
+			"auth-url":                   validatePattern(regexp.MustCompile(`^https?://`), "an http(s) URL"),
+			"auth-method":                validateOneOf("GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"),
+			"auth-signin":                nil,
+			"auth-signin-redirect-param": nil,
+			"auth-response-headers":      nil,
+			"auth-proxy-set-headers":     nil,
+			"auth-request-redirect":      nil,
+			"auth-cache-key":             nil,
+			"auth-cache-duration":        nil,
+			"auth-keepalive":             validateInt(0, 0),
+			"auth-keepalive-share-vars":  validateBool,
+			"auth-keepalive-requests":    validateInt(1, 0),
+			"auth-keepalive-timeout":     validateInt(1, 0),
+			"auth-always-set-cookie":     validateBool,
+			"enable-global-auth":         validateBool,
+
+			// Canary releases
+			"canary":                   validateBool,
+			"canary-weight":            validateInt(0, 0),
+			"canary-weight-total":      validateInt(1, 0),
+			"canary-by-header":         nil,
+			"canary-by-header-value":   nil,
+			"canary-by-header-pattern": nil,
+			"canary-by-cookie":         nil,
+
+			// Mirroring
+			"mirror-target":       nil,
+			"mirror-host":         nil,
+			"mirror-request-body": validateOneOf("on", "off"),
+
+			// Observability and security modules
+			"enable-access-log":                 validateBool,
+			"enable-opentelemetry":              validateBool,
+			"opentelemetry-trust-incoming-span": validateBool,
+			"opentelemetry-operation-name":      nil,
+			"enable-opentracing":                validateBool,
+			"opentracing-trust-incoming-span":   validateBool,
+			"enable-modsecurity":                validateBool,
+			"enable-owasp-core-rules":           validateBool,
+			"modsecurity-transaction-id":        nil,
+
+			// Snippets
+			"configuration-snippet": validateSnippet,
+			"server-snippet":        validateSnippet,
+			"auth-snippet":          validateSnippet,
+			"stream-snippet":        validateSnippet,
+			"modsecurity-snippet":   validateSnippet,
+		},
+		LogPatterns: []ControllerLogPattern{
+			{regexp.MustCompile(`upstream timed out`), "CRITICAL",
+				"Upstream timed out — the backend did not respond within proxy-read-timeout (504)",
+				"Check backend latency, or raise nginx.ingress.kubernetes.io/proxy-read-timeout for slow endpoints"},
+			{regexp.MustCompile(`connect\(\) failed .*Connection refused`), "CRITICAL",
+				"Upstream refused the connection — pods are not listening on the target port (502)",
+				"Verify the Service targetPort matches the container port and that pods are ready"},
+			{regexp.MustCompile(`no live upstreams`), "CRITICAL",
+				"No live upstreams — every backend endpoint was marked failed (502/503)",
+				"Check backend pod readiness and endpoints with diagnose_service"},
+			{regexp.MustCompile(`upstream prematurely closed connection`), "WARNING",
+				"Upstream closed the connection before sending a complete response (502)",
+				"Check backend keep-alive timeouts and application crashes"},
+			{regexp.MustCompile(`(?i)error reloading nginx|Unexpected failure reloading the backend|nginx: \[emerg\]`), "CRITICAL",
+				"NGINX configuration reload failed — new Ingress changes are not being applied",
+				"Look for invalid snippet or annotation values in recently changed Ingresses"},
+			{regexp.MustCompile(`(?i)does not have any active Endpoint|Error obtaining Endpoints for Service`), "WARNING",
+				"An Ingress backend Service has no active endpoints",
+				"Run diagnose_service on the Service named in the log line"},
+			{regexp.MustCompile(`client intended to send too large body`), "WARNING",
+				"Requests rejected for exceeding the body size limit (413)",
+				"Raise nginx.ingress.kubernetes.io/proxy-body-size on the affected Ingress"},
+			{regexp.MustCompile(`(?i)Error getting SSL certificate|SSL_do_handshake\(\) failed`), "WARNING",
+				"TLS certificate or handshake problems",
+				"Check the TLS secret referenced by the Ingress with check_certificates"},
+		},
+	}
+}
+
+func traefikProfile() *IngressControllerProfile {
+	return &IngressControllerProfile{
+		Name:             "traefik",
+		DisplayName:      "Traefik",
+		Controllers:      []string{"traefik.io/ingress-controller"},
+		PodSelectors:     []string{"app.kubernetes.io/name=traefik", "app=traefik"},
+		AnnotationPrefix: "traefik.ingress.kubernetes.io/",
+		Annotations: map[string]AnnotationValidator{
+			"router.entrypoints":       nil,
+			"router.middlewares":       validateTraefikMiddlewares,
+			"router.priority":          validateInt(0, 0),
+			"router.pathmatcher":       validateOneOf("Path", "PathPrefix", "PathRegexp"),
+			"router.rulesyntax":        validateOneOf("v2", "v3"),
+			"router.tls":               validateBool,
+			"router.tls.certresolver":  nil,
+			"router.tls.options":       nil,
+			"router.tls.domains.":      nil,
+			"router.observability.":    nil,
+			"service.serversscheme":    validateOneOf("http", "https", "h2c"),
+			"service.serverstransport": nil,
+			"service.passhostheader":   validateBool,
+			"service.nativelb":         validateBool,
+			"service.nodeportlb":       validateBool,
+			"service.sticky.cookie":    validateBool,
+			"service.sticky.cookie.":   nil,
+		},
+		LogPatterns: []ControllerLogPattern{
+			{regexp.MustCompile(`(?i)middleware \\?"?[^ ]+\\?"? does not exist`), "CRITICAL",
+				"A router references a Middleware that does not exist — the router is disabled (404)",
+				"Create the Middleware or fix router.middlewares (format <namespace>-<name>@kubernetescrd)"},
+			{regexp.MustCompile(`(?i)error while (building|adding) (router|route|configuration)|invalid rule|error while parsing rule`), "CRITICAL",
+				"Traefik could not build a router from an Ingress rule",
+				"Check the Ingress paths and traefik.ingress.kubernetes.io/router.* annotations named in the log line"},
+			{regexp.MustCompile(`(?i)cannot create service|service .*not found|the service "[^"]+" does not exist`), "CRITICAL",
+				"A router's backend Service could not be resolved",
+				"Verify the backend Service name and port referenced by the Ingress"},
+			{regexp.MustCompile(`(?i)skipping service: no endpoints found|endpoints not found`), "WARNING",
+				"A backend Service has no ready endpoints",
+				"Run diagnose_service on the affected Service"},
+			{regexp.MustCompile(`(?i)non-existent resolver|unable to obtain ACME certificate|error renewing certificate`), "WARNING",
+				"TLS certificate resolution failed",
+				"Check the certresolver configuration and ACME challenge reachability"},
+		},
+	}
+}
+
+// validateTraefikMiddlewares checks the "<namespace>-<name>@<provider>" middleware reference format.
+func validateTraefikMiddlewares(value string) string {
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" && !strings.Contains(item, "@") {
+			return fmt.Sprintf("middleware '%s' lacks a provider suffix (expected <namespace>-<name>@kubernetescrd)", item)
+		}
+	}
+	return ""
+}
+
+func haproxyProfile() *IngressControllerProfile {
+	duration := validatePattern(durationPattern, "a duration such as 30s or 500ms")
+	return &IngressControllerProfile{
+		Name:        "haproxy",
+		DisplayName: "HAProxy Kubernetes Ingress",
+		Controllers: []string{"haproxy.org/ingress-controller", "haproxy-ingress.github.io/controller"},
+		PodSelectors: []string{
+			"app.kubernetes.io/name=kubernetes-ingress",
+			"app.kubernetes.io/name=haproxy-ingress",
+			"run=haproxy-ingress",
+		},
+		AnnotationPrefix: "haproxy.org/",
+		Annotations: map[string]AnnotationValidator{
+			"check":                  validateBool,
+			"check-interval":         duration,
+			"timeout-server":         duration,
+			"timeout-connect":        duration,
+			"timeout-client":         duration,
+			"timeout-queue":          duration,
+			"ssl-redirect":           validateBool,
+			"ssl-redirect-code":      validateOneOf("301", "302", "303", "307", "308"),
+			"ssl-passthrough":        validateBool,
+			"server-ssl":             validateBool,
+			"server-proto":           validateOneOf("h2"),
+			"load-balance":           nil,
+			"path-rewrite":           nil,
+			"set-host":               nil,
+			"allow-list":             validateCIDRList,
+			"deny-list":              validateCIDRList,
+			"whitelist":              validateCIDRList,
+			"blacklist":              validateCIDRList,
+			"rate-limit-requests":    validateInt(1, 0),
+			"rate-limit-period":      duration,
+			"cors-enable":            validateBool,
+			"cors-allow-origin":      nil,
+			"cors-allow-methods":     nil,
+			"cors-allow-headers":     nil,
+			"cookie-persistence":     nil,
+			"request-set-header":     nil,
+			"response-set-header":    nil,
+			"backend-config-snippet": nil,
+			"auth-type":              validateOneOf("basic-auth"),
+			"auth-secret":            nil,
+			"auth-realm":             nil,
+		},
+		LogPatterns: []ControllerLogPattern{
+			{regexp.MustCompile(`(?i)reload failed|failed to reload|haproxy.*(configuration|config) (file )?(is )?invalid`), "CRITICAL",
+				"HAProxy configuration reload failed — Ingress changes are not being applied",
+				"Check recently changed Ingress annotations and config snippets for invalid values"},
+			{regexp.MustCompile(`(?i)has no server available|no server is available`), "CRITICAL",
+				"A backend has no available servers (503)",
+				"Check the backend Service endpoints and health checks"},
+			{regexp.MustCompile(`(?i)server [^ ]+ is DOWN`), "WARNING",
+				"Backend servers are failing health checks",
+				"Check readiness of the backend pods and the haproxy.org/check settings"},
+			{regexp.MustCompile(`(?i)service .*not found|unable to find service`), "WARNING",
+				"An Ingress backend Service could not be found",
+				"Verify the backend Service referenced by the Ingress exists"},
+		},
+	}
+}
@@ -0,0 +1,130 @@
+package k8s
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFindIngressControllerProfile(t *testing.T) {
+	tests := []struct {
+		controller string
+		want       string
+	}{
+		{"k8s.io/ingress-nginx", "ingress-nginx"},
+		{"traefik.io/ingress-controller", "traefik"},
+		{"haproxy.org/ingress-controller/haproxy", "haproxy"},
+		{"azure/application-gateway", "agic"},
+		{"example.com/unknown", ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		if p := FindIngressControllerProfile(tt.controller); p != nil {
+			got = p.Name
+		}
+		if got != tt.want {
+			t.Errorf("FindIngressControllerProfile(%q) = %q, want %q", tt.controller, got, tt.want)
+		}
+	}
+}
+
+func TestScanControllerLogs(t *testing.T) {
+	logs := strings.Join([]string{
+		`2024/01/01 10:00:00 [error] 31#31: *1 upstream timed out (110: Operation timed out) while reading response header from upstream`,
+		`2024/01/01 10:00:05 [error] 31#31: *2 upstream timed out (110: Operation timed out) while connecting to upstream`,
+		`E0101 10:01:00.000000 7 controller.go:190] Unexpected failure reloading the backend: exit status 1`,
+		`I0101 10:02:00.000000 7 store.go:1] normal line`,
+	}, "\n")
+	matches := ScanControllerLogs(IngressControllerProfileByName("ingress-nginx"), logs)
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 pattern matches, got %d: %+v", len(matches), matches)
+	}
+	if matches[0].Count != 2 || !strings.Contains(matches[0].Sample, "while connecting") {
+		t.Errorf("unexpected upstream timeout match: %+v", matches[0])
+	}
+	if matches[1].Pattern.Severity != "CRITICAL" {
+		t.Errorf("reload failure should be CRITICAL, got %+v", matches[1])
+	}
+
+	traefik := ScanControllerLogs(IngressControllerProfileByName("traefik"),
+		`time="2024-01-01T10:00:00Z" level=error msg="middleware \"shop-auth@kubernetescrd\" does not exist" entryPointName=web routerName=shop-web@kubernetes`)
+	if len(traefik) != 1 {
+		t.Errorf("expected traefik missing middleware match, got %+v", traefik)
+	}
+}
+
+func TestValidateIngressAnnotations(t *testing.T) {
+	ing := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{
+		Name: "web", Namespace: "shop",
+		Annotations: map[string]string{
+			"nginx.ingress.kubernetes.io/proxy-read-timeout":     "60s",
+			"nginx.ingress.kubernetes.io/proxy-body-size":        "10m",
+			"nginx.ingress.kubernetes.io/ssl-redirect":           "yes",
+			"nginx.ingress.kubernetes.io/rewrite-targett":        "/",
+			"nginx.ingress.kubernetes.io/whitelist-source-range": "10.0.0.0/8, 300.1.1.1",
+			"nginx.ingress.kubernetes.io/proxy-http-version":     "1.1",
+			"nginx.ingress.kubernetes.io/custom-http-errors":     "404,503",
+			"nginx.ingress.kubernetes.io/upstream-hash-by":       "$request_uri",
+			"nginx.ingress.kubernetes.io/x-forwarded-prefix":     "/shop",
+			"nginx.ingress.kubernetes.io/default-backend":        "fallback",
+			"nginx.ingress.kubernetes.io/auth-method":            "GET",
+			"nginx.ingress.kubernetes.io/ssl-ciphers":            "ECDHE-RSA-AES128-GCM-SHA256",
+			"appgw.ingress.kubernetes.io/request-timeout":        "30",
+			"kubernetes.io/ingress.class":                        "nginx",
+		},
+	}}
+	issues := ValidateIngressAnnotations(IngressControllerProfileByName("ingress-nginx"), ing)
+	got := make(map[string]bool)
+	for _, i := range issues {
+		got[i.Annotation] = true
+		if i.Annotation == "nginx.ingress.kubernetes.io/rewrite-targett" && (i.Severity != "INFO" || !strings.Contains(i.Problem, "check the spelling")) {
+			t.Errorf("unknown annotation should be an INFO spelling hint, got %+v", i)
+		}
+	}
+	for _, want := range []string{
+		"nginx.ingress.kubernetes.io/proxy-read-timeout",
+		"nginx.ingress.kubernetes.io/ssl-redirect",
+		"nginx.ingress.kubernetes.io/rewrite-targett",
+		"nginx.ingress.kubernetes.io/whitelist-source-range",
+		"appgw.ingress.kubernetes.io/request-timeout",
+	} {
+		if !got[want] {
+			t.Errorf("expected an issue for %s, got %+v", want, issues)
+		}
+	}
+	if got["nginx.ingress.kubernetes.io/proxy-body-size"] || len(issues) != 5 {
+		t.Errorf("unexpected issues: %+v", issues)
+	}
+
+	traefikIng := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+		"traefik.ingress.kubernetes.io/router.middlewares":         "shop-auth",
+		"traefik.ingress.kubernetes.io/router.tls.domains.0.main":  "example.com",
+		"traefik.ingress.kubernetes.io/service.sticky.cookie.name": "sid",
+	}}}
+	issues = ValidateIngressAnnotations(IngressControllerProfileByName("traefik"), traefikIng)
+	if len(issues) != 1 || issues[0].Annotation != "traefik.ingress.kubernetes.io/router.middlewares" {
+		t.Errorf("expected only the middleware format issue, got %+v", issues)
+	}
+}
+
+func TestRegisterIngressControllerProfile(t *testing.T) {
+	saved := IngressControllerProfiles()
+	defer func() { ingressProfiles = saved }()
+
+	RegisterIngressControllerProfile(&IngressControllerProfile{
+		Name:        "contour",
+		DisplayName: "Contour",
+		Controllers: []string{"projectcontour.io/ingress-controller"},
+		LogPatterns: []ControllerLogPattern{{Pattern: regexp.MustCompile(`invalid`), Severity: "WARNING"}},
+	})
+	p := FindIngressControllerProfile("projectcontour.io/ingress-controller")
+	if p == nil || p.Name != "contour" {
+		t.Fatalf("registered profile not found, got %+v", p)
+	}
+	if m := ScanControllerLogs(p, "route invalid\nok"); len(m) != 1 || m[0].Count != 1 {
+		t.Errorf("unexpected matches: %+v", m)
+	}
+}
@@ -0,0 +1,175 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"k8s.io/client-go/rest"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+// NodeStatsSummary is the subset of the kubelet /stats/summary response used by the diagnostics.
+type NodeStatsSummary struct {
+	Node NodeStats  `json:"node"`
+	Pods []PodStats `json:"pods"`
+}
+
+// NodeStats holds node-level filesystem and process statistics.
+type NodeStats struct {
+	NodeName string        `json:"nodeName"`
+	Fs       *FsStats      `json:"fs,omitempty"`
+	Runtime  *RuntimeStats `json:"runtime,omitempty"`
+	Rlimit   *RlimitStats  `json:"rlimit,omitempty"`
+}
+
+// RuntimeStats holds container runtime filesystem statistics.
+type RuntimeStats struct {
+	ImageFs     *FsStats `json:"imageFs,omitempty"`
+	ContainerFs *FsStats `json:"containerFs,omitempty"`
+}
+
+// RlimitStats holds process ID limits for the node.
+type RlimitStats struct {
+	MaxPID  int64 `json:"maxpid"`
+	CurProc int64 `json:"curproc"`
+}
+
+// FsStats holds filesystem usage in bytes and inodes.
+type FsStats struct {
+	AvailableBytes uint64 `json:"availableBytes"`
+	CapacityBytes  uint64 `json:"capacityBytes"`
+	UsedBytes      uint64 `json:"usedBytes"`
+	InodesFree     uint64 `json:"inodesFree"`
+	Inodes         uint64 `json:"inodes"`
+	InodesUsed     uint64 `json:"inodesUsed"`
+}
+
+// PodReference identifies the pod a stats entry belongs to.
+type PodReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	UID       string `json:"uid"`
+}
+
+// PodStats holds per-pod ephemeral storage, volume, and process statistics.
+type PodStats struct {
+	PodRef           PodReference     `json:"podRef"`
+	Containers       []ContainerStats `json:"containers,omitempty"`
+	VolumeStats      []VolumeStats    `json:"volume,omitempty"`
+	EphemeralStorage *FsStats         `json:"ephemeral-storage,omitempty"`
+	ProcessStats     *ProcessStats    `json:"process_stats,omitempty"`
+}
+
+// ContainerStats holds per-container filesystem statistics.
+type ContainerStats struct {
+	Name   string   `json:"name"`
+	Rootfs *FsStats `json:"rootfs,omitempty"`
+	Logs   *FsStats `json:"logs,omitempty"`
+}
+
+// VolumeStats holds usage for a single pod volume.
+type VolumeStats struct {
+	FsStats
+	Name   string        `json:"name"`
+	PVCRef *PVCReference `json:"pvcRef,omitempty"`
+}
+
+// PVCReference identifies the PVC backing a volume.
+type PVCReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// ProcessStats holds the process count for a pod.
+type ProcessStats struct {
+	ProcessCount uint64 `json:"process_count"`
+}
+
+// UsedPercent returns used bytes as a percentage of capacity (0 if capacity is unknown).
+func (f *FsStats) UsedPercent() float64 {
+	if f == nil || f.CapacityBytes == 0 {
+		return 0
+	}
+	return float64(f.UsedBytes) / float64(f.CapacityBytes) * 100
+}
+
+// InodesUsedPercent returns used inodes as a percentage of total inodes (0 if unknown).
+func (f *FsStats) InodesUsedPercent() float64 {
+	if f == nil || f.Inodes == 0 {
+		return 0
+	}
+	return float64(f.InodesUsed) / float64(f.Inodes) * 100
+}
+
+// GetNodeStatsSummary fetches the kubelet stats summary for a node through the apiserver node proxy.
+func (c *ClusterClient) GetNodeStatsSummary(ctx context.Context, nodeName string) (*NodeStatsSummary, error) {
+	rc, err := c.coreRESTClient()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, util.DefaultTimeout)
+	defer cancel()
+
+	data, err := rc.Get().
+		Resource("nodes").
+		Name(nodeName).
+		SubResource("proxy").
+		Suffix("stats", "summary").
+		DoRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var summary NodeStatsSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil, fmt.Errorf("failed to parse kubelet stats summary: %w", err)
+	}
+	return &summary, nil
+}
+
+// GetNodeStatsSummaries fetches the kubelet stats summary for each node concurrently,
+// with at most util.MaxConcurrentNodeProxyCalls requests in flight. Nodes whose summary
+// could not be fetched are reported in the returned error map instead of the summaries.
+func (c *ClusterClient) GetNodeStatsSummaries(ctx context.Context, nodeNames []string) (map[string]*NodeStatsSummary, map[string]error) {
+	summaries := make(map[string]*NodeStatsSummary, len(nodeNames))
+	errs := make(map[string]error)
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, util.MaxConcurrentNodeProxyCalls)
+	)
+	for _, name := range nodeNames {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			summary, err := c.GetNodeStatsSummary(ctx, name)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs[name] = err
+				return
+			}
+			summaries[name] = summary
+		}(name)
+	}
+	wg.Wait()
+	return summaries, errs
+}
+
+// coreRESTClient returns the core/v1 REST client used for apiserver proxy subresources.
+// Fake clientsets return a nil client, which is reported as an error instead of panicking.
+func (c *ClusterClient) coreRESTClient() (rest.Interface, error) {
+	rc := c.Clientset.CoreV1().RESTClient()
+	if r, ok := rc.(*rest.RESTClient); rc == nil || (ok && r == nil) {
+		return nil, fmt.Errorf("apiserver proxy not available (REST client is nil)")
+	}
+	return rc, nil
+}
@@ -0,0 +1,117 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+const testStatsSummary = `{
+  "node": {
+    "nodeName": "node-1",
+    "fs": {"availableBytes": 20, "capacityBytes": 100, "usedBytes": 80, "inodesFree": 900, "inodes": 1000, "inodesUsed": 100},
+    "runtime": {"imageFs": {"availableBytes": 10, "capacityBytes": 100, "usedBytes": 90}},
+    "rlimit": {"maxpid": 4194304, "curproc": 512}
+  },
+  "pods": [
+    {
+      "podRef": {"name": "web-1", "namespace": "default", "uid": "abc"},
+      "ephemeral-storage": {"usedBytes": 4096, "inodesUsed": 12},
+      "process_stats": {"process_count": 7},
+      "volume": [{"name": "data", "usedBytes": 2048, "capacityBytes": 8192, "pvcRef": {"name": "data-pvc", "namespace": "default"}}]
+    }
+  ]
+}`
+
+func newProxyTestClient(t *testing.T, handler http.HandlerFunc) *ClusterClient {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	clientset, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("NewForConfig() error = %v", err)
+	}
+	return NewClusterClientForTesting(clientset, nil)
+}
+
+func TestGetNodeStatsSummary(t *testing.T) {
+	client := newProxyTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/nodes/node-1/proxy/stats/summary" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(testStatsSummary))
+	})
+
+	summary, err := client.GetNodeStatsSummary(context.Background(), "node-1")
+	if err != nil {
+		t.Fatalf("GetNodeStatsSummary() error = %v", err)
+	}
+	if summary.Node.NodeName != "node-1" {
+		t.Errorf("expected node name 'node-1', got %q", summary.Node.NodeName)
+	}
+	if got := summary.Node.Fs.UsedPercent(); got != 80 {
+		t.Errorf("expected node fs used 80%%, got %.1f", got)
+	}
+	if summary.Node.Rlimit == nil || summary.Node.Rlimit.CurProc != 512 {
+		t.Errorf("expected curproc 512, got %+v", summary.Node.Rlimit)
+	}
+	if len(summary.Pods) != 1 {
+		t.Fatalf("expected 1 pod, got %d", len(summary.Pods))
+	}
+	pod := summary.Pods[0]
+	if pod.EphemeralStorage == nil || pod.EphemeralStorage.UsedBytes != 4096 {
+		t.Errorf("expected ephemeral usage 4096, got %+v", pod.EphemeralStorage)
+	}
+	if pod.ProcessStats == nil || pod.ProcessStats.ProcessCount != 7 {
+		t.Errorf("expected process count 7, got %+v", pod.ProcessStats)
+	}
+	if len(pod.VolumeStats) != 1 || pod.VolumeStats[0].PVCRef == nil || pod.VolumeStats[0].UsedBytes != 2048 {
+		t.Errorf("unexpected volume stats: %+v", pod.VolumeStats)
+	}
+}
+
+func TestGetNodeStatsSummaryNotFound(t *testing.T) {
+	client := newProxyTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+
+	if _, err := client.GetNodeStatsSummary(context.Background(), "missing"); err == nil {
+		t.Fatal("expected error for missing node")
+	}
+}
+
+func TestGetNodeStatsSummaryFakeClient(t *testing.T) {
+	client := NewClusterClientForTesting(fake.NewSimpleClientset(), nil)
+
+	// Fake clientsets have no REST client; this must error rather than panic
+	if _, err := client.GetNodeStatsSummary(context.Background(), "node-1"); err == nil {
+		t.Fatal("expected error for fake clientset")
+	}
+}
+
+func TestGetNodeStatsSummaries(t *testing.T) {
+	client := newProxyTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/nodes/node-1/proxy/stats/summary" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(testStatsSummary))
+	})
+
+	summaries, errs := client.GetNodeStatsSummaries(context.Background(), []string{"node-1", "node-2", "node-3"})
+	if len(summaries) != 1 || summaries["node-1"] == nil {
+		t.Errorf("expected a summary for node-1 only, got %v", summaries)
+	}
+	if len(errs) != 2 || errs["node-2"] == nil || errs["node-3"] == nil {
+		t.Errorf("expected errors for node-2 and node-3, got %v", errs)
+	}
+}
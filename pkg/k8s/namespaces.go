@@ -20,3 +20,11 @@ func (c *ClusterClient) ListNamespaces(ctx context.Context) ([]corev1.Namespace,
 	}
 	return list.Items, nil
 }
+
+// GetNamespace returns a single namespace.
+func (c *ClusterClient) GetNamespace(ctx context.Context, name string) (*corev1.Namespace, error) {
+	ctx, cancel := context.WithTimeout(ctx, util.DefaultTimeout)
+	defer cancel()
+
+	return c.Clientset.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+}
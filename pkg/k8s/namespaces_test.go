@@ -43,3 +43,25 @@ func TestListNamespacesEmpty(t *testing.T) {
 		t.Errorf("expected 0 namespaces, got %d", len(namespaces))
 	}
 }
+
+func TestGetNamespace(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "apps",
+			Labels: map[string]string{"pod-security.kubernetes.io/enforce": "baseline"},
+		},
+	})
+	client := NewClusterClientForTesting(fakeClient, nil)
+
+	ns, err := client.GetNamespace(context.Background(), "apps")
+	if err != nil {
+		t.Fatalf("GetNamespace() error = %v", err)
+	}
+	if ns.Labels["pod-security.kubernetes.io/enforce"] != "baseline" {
+		t.Errorf("unexpected labels: %v", ns.Labels)
+	}
+
+	if _, err := client.GetNamespace(context.Background(), "missing"); err == nil {
+		t.Error("expected error for missing namespace")
+	}
+}
@@ -30,180 +30,64 @@ func (c *ClusterClient) GetIngress(ctx context.Context, namespace, name string)
 }
 
 // EndpointHealth summarizes the health of endpoints for a service.
+// TotalEndpoints counts ready and not-ready endpoints; terminating endpoints are tracked
+// separately so rolling updates do not read as degraded.
 type EndpointHealth struct {
-	ServiceName    string
-	ServiceNS      string
-	TotalEndpoints int
-	ReadyCount     int
-	NotReadyCount  int
-	ReadyAddresses []EndpointAddress
-	NotReadyPods   []EndpointAddress
+	ServiceName      string
+	ServiceNS        string
+	Source           string // EndpointSourceSlices or EndpointSourceCore
+	TotalEndpoints   int
+	ReadyCount       int
+	NotReadyCount    int
+	ServingCount     int
+	TerminatingCount int
+	HintedCount      int
+	ReadyAddresses   []EndpointAddress
+	NotReadyPods     []EndpointAddress
+	Terminating      []EndpointAddress
+	AddressFamilies  []string
+	Ports            []string
+	Slices           []EndpointSliceInfo
 }
 
 // EndpointAddress is an individual endpoint address with pod info.
 type EndpointAddress struct {
-	IP       string
-	PodName  string
-	NodeName string
+	IP          string
+	IPs         []string // every address of the endpoint across families
+	PodName     string
+	NodeName    string
+	Zone        string
+	ZoneHints   []string
+	Ready       bool
+	Serving     bool
+	Terminating bool
 }
 
-// GetServiceEndpointHealth returns endpoint health for a service.
+// GetServiceEndpointHealth returns endpoint health for a service. It reads discovery.k8s.io/v1
+// EndpointSlices and falls back to core/v1 Endpoints when the service has no slices or the
+// slice API is unavailable.
 func (c *ClusterClient) GetServiceEndpointHealth(ctx context.Context, namespace, serviceName string) (*EndpointHealth, error) {
-	ctx, cancel := context.WithTimeout(ctx, util.DefaultTimeout)
-	defer cancel()
-
-	ep, err := c.Clientset.CoreV1().Endpoints(namespace).Get(ctx, serviceName, metav1.GetOptions{})
-	if err != nil {
-		return nil, err
+	slices, err := c.ListServiceEndpointSlices(ctx, namespace, serviceName)
+	if err == nil && len(slices) > 0 {
+		return endpointHealthFromSlices(namespace, serviceName, slices), nil
 	}
 
-	health := &EndpointHealth{
-		ServiceName: serviceName,
-		ServiceNS:   namespace,
-	}
-
-	for _, subset := range ep.Subsets {
-		for _, addr := range subset.Addresses {
-			ea := EndpointAddress{IP: addr.IP}
-			if addr.TargetRef != nil {
-				ea.PodName = addr.TargetRef.Name
-			}
-			if addr.NodeName != nil {
-				ea.NodeName = *addr.NodeName
-			}
-			health.ReadyAddresses = append(health.ReadyAddresses, ea)
-			health.ReadyCount++
-		}
-		for _, addr := range subset.NotReadyAddresses {
-			ea := EndpointAddress{IP: addr.IP}
-			if addr.TargetRef != nil {
-				ea.PodName = addr.TargetRef.Name
-			}
-			if addr.NodeName != nil {
-				ea.NodeName = *addr.NodeName
-			}
-			health.NotReadyPods = append(health.NotReadyPods, ea)
-			health.NotReadyCount++
-		}
-	}
-	health.TotalEndpoints = health.ReadyCount + health.NotReadyCount
-	return health, nil
-}
-
-// ServiceDependency represents an inferred dependency from one service to another.
-type ServiceDependency struct {
-	FromService string
-	FromNS      string
-	ToService   string
-	ToNS        string
-	Confidence  string // "high", "medium", "low"
-	Source      string // "env", "configmap", "port"
-}
-
-// InferServiceDependencies infers inter-service dependencies from pod env vars.
-func (c *ClusterClient) InferServiceDependencies(ctx context.Context, namespace string) ([]ServiceDependency, error) {
-	ctx, cancel := context.WithTimeout(ctx, util.DefaultTimeout)
-	defer cancel()
-
-	// Get all services to build name lookup
-	services, err := c.Clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+	ep, err := c.GetEndpoints(ctx, namespace, serviceName)
 	if err != nil {
 		return nil, err
 	}
-	svcNames := make(map[string]bool)
-	for _, svc := range services.Items {
-		svcNames[svc.Name] = true
-	}
-
-	// Get all pods
-	pods, err := c.Clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
-	if err != nil {
-		return nil, err
-	}
-
-	// For each service, find its pods and check their env for references to other services
-	svcToPods := make(map[string][]corev1.Pod)
-	for i := range pods.Items {
-		pod := &pods.Items[i]
-		for _, svc := range services.Items {
-			if svc.Spec.Selector != nil && len(svc.Spec.Selector) > 0 {
-				sel := labels.SelectorFromSet(svc.Spec.Selector)
-				if sel.Matches(labels.Set(pod.Labels)) {
-					svcToPods[svc.Name] = append(svcToPods[svc.Name], *pod)
-					break
-				}
-			}
-		}
-	}
-
-	var deps []ServiceDependency
-	seen := make(map[string]bool)
-
-	for svcName, svcPods := range svcToPods {
-		for _, pod := range svcPods {
-			for _, container := range pod.Spec.Containers {
-				for _, env := range container.Env {
-					// Check for Kubernetes-injected service env vars (*_SERVICE_HOST)
-					if strings.HasSuffix(env.Name, "_SERVICE_HOST") {
-						targetName := strings.TrimSuffix(env.Name, "_SERVICE_HOST")
-						targetName = strings.ToLower(strings.ReplaceAll(targetName, "_", "-"))
-						if svcNames[targetName] && targetName != svcName {
-							key := svcName + "->" + targetName
-							if !seen[key] {
-								deps = append(deps, ServiceDependency{
-									FromService: svcName, FromNS: namespace,
-									ToService: targetName, ToNS: namespace,
-									Confidence: "high", Source: "env",
-								})
-								seen[key] = true
-							}
-						}
-					}
-					// Check env value for service DNS patterns
-					if env.Value != "" {
-						for candidateSvc := range svcNames {
-							if candidateSvc != svcName && strings.Contains(env.Value, candidateSvc+"."+namespace) {
-								key := svcName + "->" + candidateSvc
-								if !seen[key] {
-									deps = append(deps, ServiceDependency{
-										FromService: svcName, FromNS: namespace,
-										ToService: candidateSvc, ToNS: namespace,
-										Confidence: "medium", Source: "env",
-									})
-									seen[key] = true
-								}
-							}
-						}
-					}
-				}
-			}
-		}
-	}
-
-	return deps, nil
+	return endpointHealthFromEndpoints(namespace, serviceName, ep), nil
 }
 
 // AGICAnnotation represents a parsed AGIC annotation.
-type AGICAnnotation struct {
-	Key   string
-	Value string
-}
+type AGICAnnotation = IngressAnnotation
 
 // AGICAnnotationPrefix is the prefix for Azure Application Gateway Ingress Controller annotations.
 const AGICAnnotationPrefix = "appgw.ingress.kubernetes.io/"
 
 // ParseAGICAnnotations extracts AGIC annotations from an Ingress.
 func ParseAGICAnnotations(ingress *networkingv1.Ingress) []AGICAnnotation {
-	var annotations []AGICAnnotation
-	for k, v := range ingress.Annotations {
-		if strings.HasPrefix(k, AGICAnnotationPrefix) {
-			annotations = append(annotations, AGICAnnotation{
-				Key:   strings.TrimPrefix(k, AGICAnnotationPrefix),
-				Value: v,
-			})
-		}
-	}
-	return annotations
+	return ParseIngressAnnotations(ingress, AGICAnnotationPrefix)
 }
 
 // GetPodsForService returns pods matching a service's selector.
@@ -0,0 +1,262 @@
+package k8s
+
+import (
+	"fmt"
+	"net"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// PolicyEndpoint is one side of a connection evaluated against NetworkPolicies.
+// A pod endpoint has PodLabels set (possibly empty); an external endpoint has only IP.
+type PolicyEndpoint struct {
+	Namespace       string
+	NamespaceLabels map[string]string
+	PodLabels       map[string]string
+	IP              string
+	// Pod resolves named ports when this endpoint is the destination.
+	Pod *corev1.Pod
+}
+
+// IsPod reports whether the endpoint is a pod rather than an external address.
+func (e PolicyEndpoint) IsPod() bool {
+	return e.PodLabels != nil
+}
+
+// PolicyVerdict is the outcome of evaluating one traffic direction.
+type PolicyVerdict struct {
+	Allowed bool
+	// Isolated is true when at least one policy selects the pod for this direction (default deny applies).
+	Isolated bool
+	// Selecting lists the policies that select the pod for this direction.
+	Selecting []string
+	// Allowing lists the rules that admit the traffic, e.g. "allow-web (ingress rule 2)".
+	Allowing []string
+}
+
+// PolicyAppliesTo reports whether a policy selects the given pod for the direction.
+// Policies without policyTypes always cover Ingress, and Egress only if they have egress rules.
+func PolicyAppliesTo(np *networkingv1.NetworkPolicy, namespace string, podLabels map[string]string, direction networkingv1.PolicyType) bool {
+	if np.Namespace != namespace || !PolicyHasType(np, direction) {
+		return false
+	}
+	return SelectorMatches(&np.Spec.PodSelector, podLabels)
+}
+
+// PolicyHasType reports whether a policy governs the given direction.
+func PolicyHasType(np *networkingv1.NetworkPolicy, direction networkingv1.PolicyType) bool {
+	if len(np.Spec.PolicyTypes) == 0 {
+		return direction == networkingv1.PolicyTypeIngress ||
+			(direction == networkingv1.PolicyTypeEgress && len(np.Spec.Egress) > 0)
+	}
+	for _, t := range np.Spec.PolicyTypes {
+		if t == direction {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDefaultDeny reports whether a policy denies the given direction to every pod in its namespace:
+// an empty podSelector, the direction in policyTypes and no rules for it. A policy such as
+// podSelector: {}, ingress: [{}] selects every pod but allows all traffic.
+func IsDefaultDeny(np *networkingv1.NetworkPolicy, direction networkingv1.PolicyType) bool {
+	if len(np.Spec.PodSelector.MatchLabels) > 0 || len(np.Spec.PodSelector.MatchExpressions) > 0 || !PolicyHasType(np, direction) {
+		return false
+	}
+	if direction == networkingv1.PolicyTypeIngress {
+		return len(np.Spec.Ingress) == 0
+	}
+	return len(np.Spec.Egress) == 0
+}
+
+// SelectorMatches reports whether a label selector matches a label set. A nil selector matches nothing.
+func SelectorMatches(sel *metav1.LabelSelector, set map[string]string) bool {
+	if sel == nil {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(sel)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(set))
+}
+
+// EvaluateIngress decides whether dst admits traffic from src on port/protocol.
+func EvaluateIngress(policies []networkingv1.NetworkPolicy, src, dst PolicyEndpoint, port intstr.IntOrString, protocol corev1.Protocol) PolicyVerdict {
+	return evaluate(policies, src, dst, networkingv1.PolicyTypeIngress, func(ports []networkingv1.NetworkPolicyPort) bool {
+		return PortsMatch(ports, dst.Pod, port, protocol)
+	})
+}
+
+// EvaluateEgress decides whether src may send traffic to dst on port/protocol.
+func EvaluateEgress(policies []networkingv1.NetworkPolicy, src, dst PolicyEndpoint, port intstr.IntOrString, protocol corev1.Protocol) PolicyVerdict {
+	return evaluate(policies, src, dst, networkingv1.PolicyTypeEgress, func(ports []networkingv1.NetworkPolicyPort) bool {
+		return PortsMatch(ports, dst.Pod, port, protocol)
+	})
+}
+
+// EvaluateIngressAnyPort decides whether dst admits traffic from src on at least one port.
+func EvaluateIngressAnyPort(policies []networkingv1.NetworkPolicy, src, dst PolicyEndpoint) PolicyVerdict {
+	return evaluate(policies, src, dst, networkingv1.PolicyTypeIngress, func([]networkingv1.NetworkPolicyPort) bool { return true })
+}
+
+// EvaluateEgressAnyPort decides whether src may send traffic to dst on at least one port.
+func EvaluateEgressAnyPort(policies []networkingv1.NetworkPolicy, src, dst PolicyEndpoint) PolicyVerdict {
+	return evaluate(policies, src, dst, networkingv1.PolicyTypeEgress, func([]networkingv1.NetworkPolicyPort) bool { return true })
+}
+
+// evaluate applies every policy selecting the governed endpoint (dst for ingress, src for egress) and
+// collects the rules whose peers and ports admit the other endpoint.
+func evaluate(policies []networkingv1.NetworkPolicy, src, dst PolicyEndpoint, direction networkingv1.PolicyType, portsMatch func([]networkingv1.NetworkPolicyPort) bool) PolicyVerdict {
+	verdict := PolicyVerdict{Allowed: true}
+	subject, peer := dst, src
+	if direction == networkingv1.PolicyTypeEgress {
+		subject, peer = src, dst
+	}
+	if !subject.IsPod() {
+		return verdict
+	}
+	for i := range policies {
+		np := &policies[i]
+		if !PolicyAppliesTo(np, subject.Namespace, subject.PodLabels, direction) {
+			continue
+		}
+		verdict.Isolated = true
+		verdict.Selecting = append(verdict.Selecting, np.Name)
+		if direction == networkingv1.PolicyTypeIngress {
+			for r, rule := range np.Spec.Ingress {
+				if PeersMatch(rule.From, np.Namespace, peer) && portsMatch(rule.Ports) {
+					verdict.Allowing = append(verdict.Allowing, fmt.Sprintf("%s (ingress rule %d)", np.Name, r+1))
+				}
+			}
+		} else {
+			for r, rule := range np.Spec.Egress {
+				if PeersMatch(rule.To, np.Namespace, peer) && portsMatch(rule.Ports) {
+					verdict.Allowing = append(verdict.Allowing, fmt.Sprintf("%s (egress rule %d)", np.Name, r+1))
+				}
+			}
+		}
+	}
+	verdict.Allowed = !verdict.Isolated || len(verdict.Allowing) > 0
+	return verdict
+}
+
+// PeersMatch reports whether an endpoint matches any peer of a rule. An empty peer list matches everything.
+// podSelector alone selects pods in the policy's namespace; namespaceSelector widens it to matching namespaces.
+func PeersMatch(peers []networkingv1.NetworkPolicyPeer, policyNamespace string, ep PolicyEndpoint) bool {
+	if len(peers) == 0 {
+		return true
+	}
+	for _, peer := range peers {
+		if peer.IPBlock != nil {
+			if IPBlockContains(peer.IPBlock, ep.IP) {
+				return true
+			}
+			continue
+		}
+		if !ep.IsPod() {
+			continue
+		}
+		if peer.NamespaceSelector != nil {
+			if !SelectorMatches(peer.NamespaceSelector, ep.NamespaceLabels) {
+				continue
+			}
+		} else if ep.Namespace != policyNamespace {
+			continue
+		}
+		if peer.PodSelector != nil && !SelectorMatches(peer.PodSelector, ep.PodLabels) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// IPBlockContains reports whether ip is inside the block's CIDR and outside every except range.
+func IPBlockContains(block *networkingv1.IPBlock, ip string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	_, cidr, err := net.ParseCIDR(block.CIDR)
+	if err != nil || !cidr.Contains(addr) {
+		return false
+	}
+	for _, except := range block.Except {
+		if _, ex, err := net.ParseCIDR(except); err == nil && ex.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// PortsMatch reports whether port/protocol is admitted by a rule's ports. An empty list matches every port.
+// Named ports are resolved against the destination pod's container ports; endPort defines a range.
+func PortsMatch(ports []networkingv1.NetworkPolicyPort, dstPod *corev1.Pod, port intstr.IntOrString, protocol corev1.Protocol) bool {
+	if len(ports) == 0 {
+		return true
+	}
+	if protocol == "" {
+		protocol = corev1.ProtocolTCP
+	}
+	number, resolved := ResolvePodPort(dstPod, port, protocol)
+	for _, p := range ports {
+		proto := corev1.ProtocolTCP
+		if p.Protocol != nil {
+			proto = *p.Protocol
+		}
+		if proto != protocol {
+			continue
+		}
+		if p.Port == nil {
+			return true
+		}
+		if p.Port.Type == intstr.String {
+			if port.Type == intstr.String && port.StrVal == p.Port.StrVal {
+				return true
+			}
+			if rulePort, ok := ResolvePodPort(dstPod, *p.Port, proto); ok && resolved && rulePort == number {
+				return true
+			}
+			continue
+		}
+		if !resolved {
+			continue
+		}
+		end := p.Port.IntVal
+		if p.EndPort != nil {
+			end = *p.EndPort
+		}
+		if number >= p.Port.IntVal && number <= end {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolvePodPort returns the numeric port, looking up named ports in the pod's containers.
+func ResolvePodPort(pod *corev1.Pod, port intstr.IntOrString, protocol corev1.Protocol) (int32, bool) {
+	if port.Type == intstr.Int {
+		return port.IntVal, true
+	}
+	if pod == nil {
+		return 0, false
+	}
+	for _, c := range pod.Spec.Containers {
+		for _, cp := range c.Ports {
+			proto := cp.Protocol
+			if proto == "" {
+				proto = corev1.ProtocolTCP
+			}
+			if cp.Name == port.StrVal && proto == protocol {
+				return cp.ContainerPort, true
+			}
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,197 @@
+package k8s
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestEvaluateIngress(t *testing.T) {
+	tcp := corev1.ProtocolTCP
+	httpPort := intstr.FromString("http")
+	endPort := int32(9100)
+	policies := []networkingv1.NetworkPolicy{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "default-deny", Namespace: "shop"},
+			Spec: networkingv1.NetworkPolicySpec{
+				PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "allow-frontend", Namespace: "shop"},
+			Spec: networkingv1.NetworkPolicySpec{
+				PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+				Ingress: []networkingv1.NetworkPolicyIngressRule{{
+					From: []networkingv1.NetworkPolicyPeer{{
+						NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "web"}},
+						PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app": "frontend"}},
+					}},
+					Ports: []networkingv1.NetworkPolicyPort{{Protocol: &tcp, Port: &httpPort}},
+				}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "allow-metrics", Namespace: "shop"},
+			Spec: networkingv1.NetworkPolicySpec{
+				PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+				Ingress: []networkingv1.NetworkPolicyIngressRule{{
+					From: []networkingv1.NetworkPolicyPeer{{
+						IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/8", Except: []string{"10.1.0.0/16"}},
+					}},
+					Ports: []networkingv1.NetworkPolicyPort{{Port: &intstr.IntOrString{IntVal: 9000}, EndPort: &endPort}},
+				}},
+			},
+		},
+	}
+	api := PolicyEndpoint{
+		Namespace: "shop",
+		PodLabels: map[string]string{"app": "api"},
+		Pod: &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}},
+		}}}},
+	}
+	frontend := PolicyEndpoint{
+		Namespace:       "web",
+		NamespaceLabels: map[string]string{"team": "web"},
+		PodLabels:       map[string]string{"app": "frontend"},
+		IP:              "10.1.2.3",
+	}
+
+	tests := []struct {
+		name    string
+		src     PolicyEndpoint
+		dst     PolicyEndpoint
+		port    intstr.IntOrString
+		allowed bool
+	}{
+		{"named port resolved", frontend, api, intstr.FromInt32(8080), true},
+		{"wrong port", frontend, api, intstr.FromInt32(8443), false},
+		{"ip in except", frontend, api, intstr.FromInt32(9050), false},
+		{"ip in endPort range", PolicyEndpoint{IP: "10.2.0.1"}, api, intstr.FromInt32(9050), true},
+		{"ip beyond endPort", PolicyEndpoint{IP: "10.2.0.1"}, api, intstr.FromInt32(9200), false},
+		{"default deny for other pods", frontend, PolicyEndpoint{Namespace: "shop", PodLabels: map[string]string{"app": "db"}}, intstr.FromInt32(5432), false},
+		{"unselected namespace is open", frontend, PolicyEndpoint{Namespace: "other", PodLabels: map[string]string{}}, intstr.FromInt32(80), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := EvaluateIngress(policies, tt.src, tt.dst, tt.port, corev1.ProtocolTCP)
+			if v.Allowed != tt.allowed {
+				t.Errorf("Allowed = %v, want %v (verdict %+v)", v.Allowed, tt.allowed, v)
+			}
+		})
+	}
+
+	v := EvaluateIngress(policies, frontend, api, intstr.FromInt32(8080), corev1.ProtocolTCP)
+	if len(v.Selecting) != 3 || len(v.Allowing) != 1 || v.Allowing[0] != "allow-frontend (ingress rule 1)" {
+		t.Errorf("unexpected verdict: %+v", v)
+	}
+}
+
+func TestEvaluateEgress(t *testing.T) {
+	policies := []networkingv1.NetworkPolicy{{
+		ObjectMeta: metav1.ObjectMeta{Name: "egress-dns-only", Namespace: "shop"},
+		Spec: networkingv1.NetworkPolicySpec{
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+			Egress: []networkingv1.NetworkPolicyEgressRule{{
+				To: []networkingv1.NetworkPolicyPeer{{NamespaceSelector: &metav1.LabelSelector{}}},
+				Ports: []networkingv1.NetworkPolicyPort{{
+					Protocol: protocolPtr(corev1.ProtocolUDP),
+					Port:     &intstr.IntOrString{IntVal: 53},
+				}},
+			}},
+		},
+	}}
+	src := PolicyEndpoint{Namespace: "shop", PodLabels: map[string]string{"app": "api"}}
+	dns := PolicyEndpoint{Namespace: "kube-system", PodLabels: map[string]string{"k8s-app": "kube-dns"}}
+
+	if v := EvaluateEgress(policies, src, dns, intstr.FromInt32(53), corev1.ProtocolUDP); !v.Allowed {
+		t.Errorf("expected DNS egress allowed, got %+v", v)
+	}
+	if v := EvaluateEgress(policies, src, dns, intstr.FromInt32(53), corev1.ProtocolTCP); v.Allowed {
+		t.Errorf("expected TCP/53 denied, got %+v", v)
+	}
+	if v := EvaluateEgress(policies, src, PolicyEndpoint{IP: "1.1.1.1"}, intstr.FromInt32(53), corev1.ProtocolUDP); v.Allowed {
+		t.Errorf("expected external egress denied (namespaceSelector does not match IPs), got %+v", v)
+	}
+}
+
+func TestPolicyHasType(t *testing.T) {
+	np := &networkingv1.NetworkPolicy{Spec: networkingv1.NetworkPolicySpec{
+		Egress: []networkingv1.NetworkPolicyEgressRule{{}},
+	}}
+	if !PolicyHasType(np, networkingv1.PolicyTypeIngress) || !PolicyHasType(np, networkingv1.PolicyTypeEgress) {
+		t.Error("policy without policyTypes and with egress rules should cover both directions")
+	}
+	np.Spec.Egress = nil
+	if PolicyHasType(np, networkingv1.PolicyTypeEgress) {
+		t.Error("policy without policyTypes or egress rules should not cover egress")
+	}
+}
+
+func TestIsDefaultDeny(t *testing.T) {
+	ingress, egress := networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress
+	tests := []struct {
+		name                    string
+		spec                    networkingv1.NetworkPolicySpec
+		wantIngress, wantEgress bool
+	}{
+		{"deny all", networkingv1.NetworkPolicySpec{PolicyTypes: []networkingv1.PolicyType{ingress, egress}}, true, true},
+		{"ingress only by default", networkingv1.NetworkPolicySpec{}, true, false},
+		{"allow-all with an empty selector", networkingv1.NetworkPolicySpec{
+			PolicyTypes: []networkingv1.PolicyType{ingress, egress},
+			Ingress:     []networkingv1.NetworkPolicyIngressRule{{}},
+			Egress:      []networkingv1.NetworkPolicyEgressRule{{}},
+		}, false, false},
+		{"deny egress, allow ingress", networkingv1.NetworkPolicySpec{
+			PolicyTypes: []networkingv1.PolicyType{ingress, egress},
+			Ingress:     []networkingv1.NetworkPolicyIngressRule{{}},
+		}, false, true},
+		{"selects some pods", networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			PolicyTypes: []networkingv1.PolicyType{ingress, egress},
+		}, false, false},
+	}
+	for _, tt := range tests {
+		np := &networkingv1.NetworkPolicy{Spec: tt.spec}
+		if got := IsDefaultDeny(np, ingress); got != tt.wantIngress {
+			t.Errorf("%s: IsDefaultDeny(Ingress) = %v, want %v", tt.name, got, tt.wantIngress)
+		}
+		if got := IsDefaultDeny(np, egress); got != tt.wantEgress {
+			t.Errorf("%s: IsDefaultDeny(Egress) = %v, want %v", tt.name, got, tt.wantEgress)
+		}
+	}
+}
+
+func protocolPtr(p corev1.Protocol) *corev1.Protocol {
+	return &p
+}
+
+func TestEvaluateAnyPort(t *testing.T) {
+	port := intstr.FromInt32(5432)
+	policies := []networkingv1.NetworkPolicy{{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-from-api", Namespace: "shop"},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				From:  []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}}}},
+				Ports: []networkingv1.NetworkPolicyPort{{Port: &port}},
+			}},
+		},
+	}}
+	api := PolicyEndpoint{Namespace: "shop", PodLabels: map[string]string{"app": "api"}}
+	web := PolicyEndpoint{Namespace: "shop", PodLabels: map[string]string{"app": "web"}}
+	db := PolicyEndpoint{Namespace: "shop", PodLabels: map[string]string{"app": "db"}}
+
+	if v := EvaluateIngressAnyPort(policies, api, db); !v.Allowed {
+		t.Errorf("expected api -> db allowed on some port, got %+v", v)
+	}
+	if v := EvaluateIngressAnyPort(policies, web, db); v.Allowed {
+		t.Errorf("expected web -> db denied, got %+v", v)
+	}
+	if v := EvaluateEgressAnyPort(policies, api, db); !v.Allowed || v.Isolated {
+		t.Errorf("expected egress not isolated, got %+v", v)
+	}
+}
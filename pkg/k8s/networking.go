@@ -41,3 +41,15 @@ func (c *ClusterClient) GetEndpoints(ctx context.Context, namespace, name string
 
 	return c.Clientset.CoreV1().Endpoints(namespace).Get(ctx, name, metav1.GetOptions{})
 }
+
+// ListIngressClasses returns all IngressClasses in the cluster.
+func (c *ClusterClient) ListIngressClasses(ctx context.Context) ([]networkingv1.IngressClass, error) {
+	ctx, cancel := context.WithTimeout(ctx, util.DefaultTimeout)
+	defer cancel()
+
+	list, err := c.Clientset.NetworkingV1().IngressClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
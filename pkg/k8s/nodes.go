@@ -3,6 +3,7 @@ package k8s
 import (
 	"context"
 
+	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -28,3 +29,14 @@ func (c *ClusterClient) GetNode(ctx context.Context, name string) (*corev1.Node,
 
 	return c.Clientset.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
 }
+
+// NodeLeaseNamespace is the namespace holding kubelet heartbeat Leases.
+const NodeLeaseNamespace = "kube-node-lease"
+
+// GetNodeLease returns the kubelet heartbeat Lease for a node.
+func (c *ClusterClient) GetNodeLease(ctx context.Context, name string) (*coordinationv1.Lease, error) {
+	ctx, cancel := context.WithTimeout(ctx, util.DefaultTimeout)
+	defer cancel()
+
+	return c.Clientset.CoordinationV1().Leases(NodeLeaseNamespace).Get(ctx, name, metav1.GetOptions{})
+}
@@ -3,7 +3,9 @@ package k8s
 import (
 	"context"
 	"testing"
+	"time"
 
+	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -90,3 +92,33 @@ func TestGetNodeNotFound(t *testing.T) {
 		t.Fatal("expected error for nonexistent node")
 	}
 }
+
+func TestGetNodeLease(t *testing.T) {
+	holder := "node-1"
+	duration := int32(40)
+	renew := metav1.NewMicroTime(time.Now())
+	fakeClient := fake.NewSimpleClientset(
+		&coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1", Namespace: NodeLeaseNamespace},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &holder,
+				LeaseDurationSeconds: &duration,
+				RenewTime:            &renew,
+			},
+		},
+	)
+
+	client := NewClusterClientForTesting(fakeClient, nil)
+
+	lease, err := client.GetNodeLease(context.Background(), "node-1")
+	if err != nil {
+		t.Fatalf("GetNodeLease() error = %v", err)
+	}
+	if lease.Spec.LeaseDurationSeconds == nil || *lease.Spec.LeaseDurationSeconds != 40 {
+		t.Errorf("expected lease duration 40, got %v", lease.Spec.LeaseDurationSeconds)
+	}
+
+	if _, err := client.GetNodeLease(context.Background(), "node-2"); err == nil {
+		t.Error("expected error for missing lease")
+	}
+}
@@ -0,0 +1,148 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/health"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+// ObjectRef identifies an object by API group, version, kind, namespace and name,
+// as recorded in Flux inventories.
+type ObjectRef struct {
+	Group     string
+	Version   string
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// APIVersion returns the group/version string, or just the version for the core group.
+func (r ObjectRef) APIVersion() string {
+	return schema.GroupVersion{Group: r.Group, Version: r.Version}.String()
+}
+
+// String returns Kind/namespace/name, or Kind/name for cluster-scoped objects.
+func (r ObjectRef) String() string {
+	if r.Namespace == "" {
+		return r.Kind + "/" + r.Name
+	}
+	return r.Kind + "/" + r.Namespace + "/" + r.Name
+}
+
+// ObjectHealth is the live state of one referenced object.
+type ObjectHealth struct {
+	Ref     ObjectRef
+	Object  *unstructured.Unstructured // nil when missing or not fetched
+	Health  health.Result
+	Missing bool   // the object does not exist in the cluster
+	Error   string // the object could not be checked (kind not served, list forbidden)
+}
+
+// GetObjectsHealth fetches the referenced objects through the dynamic client and computes
+// their health. Objects are listed once per resource and namespace rather than fetched one
+// by one, which keeps large inventories within the client rate limits. Results are returned
+// in the order of refs; only a discovery failure is returned as an error. Namespaced refs
+// without a namespace are looked up in "default", where the apiserver would have created them.
+func (c *ClusterClient) GetObjectsHealth(ctx context.Context, refs []ObjectRef) ([]ObjectHealth, error) {
+	if c.DynamicClient == nil {
+		return nil, fmt.Errorf("dynamic client not available")
+	}
+	lists, err := c.GetAPIResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	type listKey struct {
+		gvr       schema.GroupVersionResource
+		namespace string
+	}
+	type listResult struct {
+		objects map[string]*unstructured.Unstructured
+		err     error
+	}
+	listed := map[listKey]*listResult{}
+
+	results := make([]ObjectHealth, len(refs))
+	for i, ref := range refs {
+		results[i].Ref = ref
+		res, err := resolveObjectRef(lists, ref)
+		if err != nil {
+			results[i].Error = err.Error()
+			results[i].Health = health.Result{Status: health.Unknown, Reason: "KindNotServed", Message: err.Error()}
+			continue
+		}
+
+		key := listKey{gvr: res.GVR}
+		if res.Namespaced {
+			key.namespace = ref.Namespace
+			if key.namespace == "" {
+				key.namespace = metav1.NamespaceDefault
+			}
+		}
+		lr, ok := listed[key]
+		if !ok {
+			lr = &listResult{}
+			lr.objects, lr.err = c.listDynamicByName(ctx, res, key.namespace)
+			listed[key] = lr
+		}
+		if lr.err != nil {
+			results[i].Error = lr.err.Error()
+			results[i].Health = health.Result{Status: health.Unknown, Reason: "ListFailed", Message: lr.err.Error()}
+			continue
+		}
+
+		obj, found := lr.objects[ref.Name]
+		if !found {
+			results[i].Missing = true
+			results[i].Health = health.Result{Status: health.Unknown, Reason: "NotFound", Message: "object does not exist in the cluster"}
+			continue
+		}
+		results[i].Object = obj
+		results[i].Health = health.Compute(obj)
+	}
+	return results, nil
+}
+
+// resolveObjectRef maps a ref to a served resource. If the recorded version is no longer
+// served, the group's preferred version is used instead.
+func resolveObjectRef(lists []*metav1.APIResourceList, ref ObjectRef) (*ResolvedResource, error) {
+	res, err := ResolveResource(lists, ref.Kind, ref.APIVersion())
+	if err == nil || ref.Group == "" {
+		return res, err
+	}
+	if fallback, ferr := ResolveResource(lists, ref.Kind+"."+ref.Group, ""); ferr == nil {
+		return fallback, nil
+	}
+	return nil, fmt.Errorf("%s %s is not served by the cluster (CRD removed?)", ref.APIVersion(), ref.Kind)
+}
+
+func (c *ClusterClient) listDynamicByName(ctx context.Context, res *ResolvedResource, namespace string) (map[string]*unstructured.Unstructured, error) {
+	ctx, cancel := context.WithTimeout(ctx, util.DefaultTimeout)
+	defer cancel()
+
+	var list *unstructured.UnstructuredList
+	var err error
+	if res.Namespaced {
+		list, err = c.DynamicClient.Resource(res.GVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	} else {
+		list, err = c.DynamicClient.Resource(res.GVR).List(ctx, metav1.ListOptions{})
+	}
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return map[string]*unstructured.Unstructured{}, nil
+		}
+		return nil, fmt.Errorf("listing %s: %w", res.String(), err)
+	}
+	byName := make(map[string]*unstructured.Unstructured, len(list.Items))
+	for i := range list.Items {
+		byName[list.Items[i].GetName()] = &list.Items[i]
+	}
+	return byName, nil
+}
@@ -0,0 +1,81 @@
+package k8s
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/health"
+)
+
+func TestGetObjectsHealth(t *testing.T) {
+	deploy := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1", "kind": "Deployment",
+		"metadata": map[string]interface{}{"name": "web", "namespace": "shop"},
+		"spec":     map[string]interface{}{"replicas": int64(2)},
+		"status":   map[string]interface{}{"replicas": int64(2), "updatedReplicas": int64(2), "readyReplicas": int64(2), "availableReplicas": int64(2)},
+	}}
+	failing := deploy.DeepCopy()
+	failing.SetName("api")
+	_ = unstructured.SetNestedField(failing.Object, int64(0), "status", "availableReplicas")
+	cert := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1", "kind": "Certificate",
+		"metadata": map[string]interface{}{"name": "web-tls", "namespace": "shop"},
+		"status": map[string]interface{}{"conditions": []interface{}{
+			map[string]interface{}{"type": "Ready", "status": "True"},
+		}},
+	}}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Group: "apps", Version: "v1", Resource: "deployments"}:                   "DeploymentList",
+		{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}:       "CertificateList",
+		{Group: "cert-manager.io", Version: "v1alpha2", Resource: "certificates"}: "CertificateList",
+		{Version: "v1", Resource: "pods"}:                                         "PodList",
+	}, deploy, failing, cert)
+	cs := fake.NewSimpleClientset()
+	cs.Discovery().(*fakediscovery.FakeDiscovery).Resources = testAPIResources
+	client := NewClusterClientForTestingWithDynamic(cs, nil, dyn)
+
+	refs := []ObjectRef{
+		{Group: "apps", Version: "v1", Kind: "Deployment", Namespace: "shop", Name: "web"},
+		{Group: "apps", Version: "v1", Kind: "Deployment", Namespace: "shop", Name: "api"},
+		{Group: "apps", Version: "v1", Kind: "Deployment", Namespace: "shop", Name: "gone"},
+		{Group: "cert-manager.io", Version: "v1beta1", Kind: "Certificate", Namespace: "shop", Name: "web-tls"},
+		{Group: "example.com", Version: "v1", Kind: "Widget", Namespace: "shop", Name: "w"},
+	}
+	results, err := client.GetObjectsHealth(context.Background(), refs)
+	if err != nil {
+		t.Fatalf("GetObjectsHealth() error = %v", err)
+	}
+	if len(results) != len(refs) {
+		t.Fatalf("expected %d results, got %d", len(refs), len(results))
+	}
+	if r := results[0]; r.Health.Status != health.Current || r.Object == nil {
+		t.Errorf("web: %+v", r)
+	}
+	if r := results[1]; r.Health.Status != health.InProgress || r.Health.Reason != "ReplicasUnavailable" {
+		t.Errorf("api: %+v", r.Health)
+	}
+	if r := results[2]; !r.Missing || r.Object != nil {
+		t.Errorf("gone: expected missing, got %+v", r)
+	}
+	if r := results[3]; r.Health.Status != health.Current || r.Error != "" {
+		t.Errorf("web-tls: an unserved version should fall back to a served one, got %+v", r)
+	}
+	if r := results[4]; r.Error == "" || !strings.Contains(r.Error, "not served") || r.Missing {
+		t.Errorf("widget: expected a not served error, got %+v", r)
+	}
+
+	if got := refs[4].String(); got != "Widget/shop/w" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := (ObjectRef{Version: "v1", Kind: "Namespace", Name: "shop"}); got.String() != "Namespace/shop" || got.APIVersion() != "v1" {
+		t.Errorf("unexpected cluster-scoped ref rendering: %s %s", got.String(), got.APIVersion())
+	}
+}
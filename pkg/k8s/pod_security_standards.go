@@ -0,0 +1,307 @@
+package k8s
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PSSLevel is a Pod Security Standards level.
+type PSSLevel string
+
+const (
+	PSSPrivileged PSSLevel = "privileged"
+	PSSBaseline   PSSLevel = "baseline"
+	PSSRestricted PSSLevel = "restricted"
+
+	// PSSLabelPrefix is the namespace label prefix used by Pod Security Admission.
+	PSSLabelPrefix = "pod-security.kubernetes.io/"
+)
+
+// PSSViolation is a single failed Pod Security Standards check.
+type PSSViolation struct {
+	// Level is the lowest level that forbids this configuration (baseline or restricted).
+	Level  PSSLevel
+	Check  string
+	Detail string
+}
+
+// NamespacePSSConfig holds the Pod Security Admission labels of a namespace.
+type NamespacePSSConfig struct {
+	Enforce        PSSLevel
+	EnforceVersion string
+	Audit          PSSLevel
+	AuditVersion   string
+	Warn           PSSLevel
+	WarnVersion    string
+}
+
+var baselineCapabilities = map[string]bool{
+	"AUDIT_WRITE": true, "CHOWN": true, "DAC_OVERRIDE": true, "FOWNER": true, "FSETID": true, "KILL": true,
+	"MKNOD": true, "NET_BIND_SERVICE": true, "SETFCAP": true, "SETGID": true, "SETPCAP": true, "SETUID": true, "SYS_CHROOT": true,
+}
+
+var safeSysctls = map[string]bool{
+	"kernel.shm_rmid_forced": true, "net.ipv4.ip_local_port_range": true, "net.ipv4.ip_unprivileged_port_start": true,
+	"net.ipv4.tcp_syncookies": true, "net.ipv4.ping_group_range": true, "net.ipv4.ip_local_reserved_ports": true,
+	"net.ipv4.tcp_keepalive_time": true, "net.ipv4.tcp_fin_timeout": true, "net.ipv4.tcp_keepalive_intvl": true,
+	"net.ipv4.tcp_keepalive_probes": true,
+}
+
+var allowedSELinuxTypes = map[string]bool{
+	"": true, "container_t": true, "container_init_t": true, "container_kvm_t": true, "container_engine_t": true,
+}
+
+// ParsePSSLabels reads pod-security.kubernetes.io labels; unset modes default to privileged.
+func ParsePSSLabels(labels map[string]string) NamespacePSSConfig {
+	level := func(mode string) PSSLevel {
+		if v, ok := labels[PSSLabelPrefix+mode]; ok {
+			return PSSLevel(v)
+		}
+		return PSSPrivileged
+	}
+	version := func(mode string) string {
+		if v, ok := labels[PSSLabelPrefix+mode+"-version"]; ok {
+			return v
+		}
+		return "latest"
+	}
+	return NamespacePSSConfig{
+		Enforce:        level("enforce"),
+		EnforceVersion: version("enforce"),
+		Audit:          level("audit"),
+		AuditVersion:   version("audit"),
+		Warn:           level("warn"),
+		WarnVersion:    version("warn"),
+	}
+}
+
+// NextPSSLevel returns the next stricter level ("" if already restricted).
+func NextPSSLevel(level PSSLevel) PSSLevel {
+	switch level {
+	case PSSPrivileged:
+		return PSSBaseline
+	case PSSBaseline:
+		return PSSRestricted
+	}
+	return ""
+}
+
+// HighestPSSLevel returns the strictest level a pod spec satisfies given its violations.
+func HighestPSSLevel(violations []PSSViolation) PSSLevel {
+	level := PSSRestricted
+	for _, v := range violations {
+		if v.Level == PSSBaseline {
+			return PSSPrivileged
+		}
+		level = PSSBaseline
+	}
+	return level
+}
+
+// ViolatesLevel reports whether any violation would be rejected at the given level.
+func ViolatesLevel(violations []PSSViolation, level PSSLevel) bool {
+	switch level {
+	case PSSPrivileged:
+		return false
+	case PSSBaseline:
+		for _, v := range violations {
+			if v.Level == PSSBaseline {
+				return true
+			}
+		}
+		return false
+	}
+	return len(violations) > 0
+}
+
+// EvaluatePodSecurity checks a pod spec (and its annotations) against the baseline and restricted Pod Security Standards.
+func EvaluatePodSecurity(annotations map[string]string, spec *corev1.PodSpec) []PSSViolation {
+	var v []PSSViolation
+	add := func(level PSSLevel, check, format string, args ...any) {
+		v = append(v, PSSViolation{Level: level, Check: check, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	type namedContainer struct {
+		kind string
+		name string
+		sc   *corev1.SecurityContext
+		c    corev1.Container
+	}
+	var containers []namedContainer
+	for _, c := range spec.InitContainers {
+		containers = append(containers, namedContainer{"initContainer", c.Name, c.SecurityContext, c})
+	}
+	for _, c := range spec.Containers {
+		containers = append(containers, namedContainer{"container", c.Name, c.SecurityContext, c})
+	}
+	for _, c := range spec.EphemeralContainers {
+		containers = append(containers, namedContainer{"ephemeralContainer", c.Name, c.SecurityContext, corev1.Container(c.EphemeralContainerCommon)})
+	}
+	psc := spec.SecurityContext
+	if psc == nil {
+		psc = &corev1.PodSecurityContext{}
+	}
+
+	// --- Baseline ---
+	if psc.WindowsOptions != nil && psc.WindowsOptions.HostProcess != nil && *psc.WindowsOptions.HostProcess {
+		add(PSSBaseline, "HostProcess", "spec.securityContext.windowsOptions.hostProcess=true")
+	}
+	if spec.HostNetwork {
+		add(PSSBaseline, "Host Namespaces", "spec.hostNetwork=true")
+	}
+	if spec.HostPID {
+		add(PSSBaseline, "Host Namespaces", "spec.hostPID=true")
+	}
+	if spec.HostIPC {
+		add(PSSBaseline, "Host Namespaces", "spec.hostIPC=true")
+	}
+	for _, vol := range spec.Volumes {
+		if vol.HostPath != nil {
+			add(PSSBaseline, "HostPath Volumes", "volume %q uses hostPath %s", vol.Name, vol.HostPath.Path)
+		}
+	}
+	checkSELinux := func(field string, opts *corev1.SELinuxOptions) {
+		if opts == nil {
+			return
+		}
+		if !allowedSELinuxTypes[opts.Type] {
+			add(PSSBaseline, "SELinux", "%s.seLinuxOptions.type=%s", field, opts.Type)
+		}
+		if opts.User != "" || opts.Role != "" {
+			add(PSSBaseline, "SELinux", "%s.seLinuxOptions sets user/role", field)
+		}
+	}
+	checkSELinux("spec.securityContext", psc.SELinuxOptions)
+	if psc.SeccompProfile != nil && psc.SeccompProfile.Type == corev1.SeccompProfileTypeUnconfined {
+		add(PSSBaseline, "Seccomp", "spec.securityContext.seccompProfile.type=Unconfined")
+	}
+	if psc.AppArmorProfile != nil && psc.AppArmorProfile.Type == corev1.AppArmorProfileTypeUnconfined {
+		add(PSSBaseline, "AppArmor", "spec.securityContext.appArmorProfile.type=Unconfined")
+	}
+	for _, s := range psc.Sysctls {
+		if !safeSysctls[s.Name] {
+			add(PSSBaseline, "Sysctls", "unsafe sysctl %s", s.Name)
+		}
+	}
+	for key, value := range annotations {
+		if strings.HasPrefix(key, "container.apparmor.security.beta.kubernetes.io/") &&
+			value != "runtime/default" && !strings.HasPrefix(value, "localhost/") {
+			add(PSSBaseline, "AppArmor", "annotation %s=%s", key, value)
+		}
+	}
+
+	for _, nc := range containers {
+		field := fmt.Sprintf("%s %q", nc.kind, nc.name)
+		for _, p := range nc.c.Ports {
+			if p.HostPort != 0 {
+				add(PSSBaseline, "Host Ports", "%s uses hostPort %d", field, p.HostPort)
+			}
+		}
+		sc := nc.sc
+		if sc == nil {
+			continue
+		}
+		if sc.Privileged != nil && *sc.Privileged {
+			add(PSSBaseline, "Privileged Containers", "%s is privileged", field)
+		}
+		if sc.WindowsOptions != nil && sc.WindowsOptions.HostProcess != nil && *sc.WindowsOptions.HostProcess {
+			add(PSSBaseline, "HostProcess", "%s sets windowsOptions.hostProcess=true", field)
+		}
+		if sc.Capabilities != nil {
+			for _, cap := range sc.Capabilities.Add {
+				if !baselineCapabilities[string(cap)] {
+					add(PSSBaseline, "Capabilities", "%s adds capability %s", field, cap)
+				}
+			}
+		}
+		checkSELinux(field+" securityContext", sc.SELinuxOptions)
+		if sc.ProcMount != nil && *sc.ProcMount != corev1.DefaultProcMount {
+			add(PSSBaseline, "/proc Mount Type", "%s sets procMount=%s", field, *sc.ProcMount)
+		}
+		if sc.SeccompProfile != nil && sc.SeccompProfile.Type == corev1.SeccompProfileTypeUnconfined {
+			add(PSSBaseline, "Seccomp", "%s seccompProfile.type=Unconfined", field)
+		}
+		if sc.AppArmorProfile != nil && sc.AppArmorProfile.Type == corev1.AppArmorProfileTypeUnconfined {
+			add(PSSBaseline, "AppArmor", "%s appArmorProfile.type=Unconfined", field)
+		}
+	}
+
+	// --- Restricted ---
+	for _, vol := range spec.Volumes {
+		if t := restrictedVolumeType(vol.VolumeSource); t != "" {
+			add(PSSRestricted, "Volume Types", "volume %q uses disallowed type %s", vol.Name, t)
+		}
+	}
+	podNonRoot := psc.RunAsNonRoot != nil && *psc.RunAsNonRoot
+	if psc.RunAsUser != nil && *psc.RunAsUser == 0 {
+		add(PSSRestricted, "Running as Non-root user", "spec.securityContext.runAsUser=0")
+	}
+	podSeccomp := psc.SeccompProfile != nil &&
+		(psc.SeccompProfile.Type == corev1.SeccompProfileTypeRuntimeDefault || psc.SeccompProfile.Type == corev1.SeccompProfileTypeLocalhost)
+
+	for _, nc := range containers {
+		field := fmt.Sprintf("%s %q", nc.kind, nc.name)
+		sc := nc.sc
+		if sc == nil {
+			sc = &corev1.SecurityContext{}
+		}
+		if sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+			add(PSSRestricted, "Privilege Escalation", "%s must set allowPrivilegeEscalation=false", field)
+		}
+		if sc.RunAsNonRoot != nil && !*sc.RunAsNonRoot {
+			add(PSSRestricted, "Running as Non-root", "%s sets runAsNonRoot=false", field)
+		} else if sc.RunAsNonRoot == nil && !podNonRoot {
+			add(PSSRestricted, "Running as Non-root", "%s must set runAsNonRoot=true (pod or container level)", field)
+		}
+		if sc.RunAsUser != nil && *sc.RunAsUser == 0 {
+			add(PSSRestricted, "Running as Non-root user", "%s sets runAsUser=0", field)
+		}
+		if sc.SeccompProfile == nil {
+			if !podSeccomp {
+				add(PSSRestricted, "Seccomp", "%s must set seccompProfile.type to RuntimeDefault or Localhost (pod or container level)", field)
+			}
+		} else if sc.SeccompProfile.Type != corev1.SeccompProfileTypeRuntimeDefault && sc.SeccompProfile.Type != corev1.SeccompProfileTypeLocalhost &&
+			sc.SeccompProfile.Type != corev1.SeccompProfileTypeUnconfined {
+			add(PSSRestricted, "Seccomp", "%s seccompProfile.type=%s", field, sc.SeccompProfile.Type)
+		}
+		dropsAll := false
+		if sc.Capabilities != nil {
+			for _, cap := range sc.Capabilities.Drop {
+				if cap == "ALL" {
+					dropsAll = true
+				}
+			}
+			for _, cap := range sc.Capabilities.Add {
+				if cap != "NET_BIND_SERVICE" && baselineCapabilities[string(cap)] {
+					add(PSSRestricted, "Capabilities", "%s adds capability %s (only NET_BIND_SERVICE allowed)", field, cap)
+				}
+			}
+		}
+		if !dropsAll {
+			add(PSSRestricted, "Capabilities", "%s must drop ALL capabilities", field)
+		}
+	}
+	return v
+}
+
+// restrictedVolumeType returns the volume type name if it is not allowed by the restricted profile.
+func restrictedVolumeType(src corev1.VolumeSource) string {
+	switch {
+	case src.ConfigMap != nil, src.CSI != nil, src.DownwardAPI != nil, src.EmptyDir != nil, src.Ephemeral != nil,
+		src.PersistentVolumeClaim != nil, src.Projected != nil, src.Secret != nil:
+		return ""
+	case src.HostPath != nil:
+		return "" // already reported by the baseline HostPath check
+	case src.NFS != nil:
+		return "nfs"
+	case src.ISCSI != nil:
+		return "iscsi"
+	case src.Image != nil:
+		return "image"
+	case src.GitRepo != nil:
+		return "gitRepo"
+	}
+	return "non-core volume type"
+}
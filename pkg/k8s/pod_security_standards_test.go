@@ -0,0 +1,106 @@
+package k8s
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func boolPtr(b bool) *bool { return &b }
+
+func restrictedPodSpec() *corev1.PodSpec {
+	return &corev1.PodSpec{
+		SecurityContext: &corev1.PodSecurityContext{
+			RunAsNonRoot:   boolPtr(true),
+			SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+		},
+		Containers: []corev1.Container{{
+			Name: "app",
+			SecurityContext: &corev1.SecurityContext{
+				AllowPrivilegeEscalation: boolPtr(false),
+				Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+			},
+		}},
+		Volumes: []corev1.Volume{{Name: "cfg", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{}}}},
+	}
+}
+
+func TestEvaluatePodSecurityRestricted(t *testing.T) {
+	violations := EvaluatePodSecurity(nil, restrictedPodSpec())
+	if len(violations) != 0 {
+		t.Fatalf("expected no violations, got %+v", violations)
+	}
+	if level := HighestPSSLevel(violations); level != PSSRestricted {
+		t.Errorf("HighestPSSLevel() = %s, want restricted", level)
+	}
+}
+
+func TestEvaluatePodSecurityBaselineOnly(t *testing.T) {
+	// A plain pod with no securityContext passes baseline but not restricted
+	spec := &corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}}
+	violations := EvaluatePodSecurity(nil, spec)
+
+	if level := HighestPSSLevel(violations); level != PSSBaseline {
+		t.Errorf("HighestPSSLevel() = %s, want baseline", level)
+	}
+	if ViolatesLevel(violations, PSSBaseline) {
+		t.Error("expected pod to pass baseline")
+	}
+	if !ViolatesLevel(violations, PSSRestricted) {
+		t.Error("expected pod to fail restricted")
+	}
+	checks := make(map[string]bool)
+	for _, v := range violations {
+		checks[v.Check] = true
+	}
+	for _, want := range []string{"Privilege Escalation", "Running as Non-root", "Seccomp", "Capabilities"} {
+		if !checks[want] {
+			t.Errorf("expected restricted check %q to fail", want)
+		}
+	}
+}
+
+func TestEvaluatePodSecurityPrivileged(t *testing.T) {
+	spec := restrictedPodSpec()
+	spec.HostNetwork = true
+	spec.Volumes = append(spec.Volumes, corev1.Volume{Name: "host", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/run"}}})
+	spec.Containers[0].SecurityContext.Privileged = boolPtr(true)
+	spec.Containers[0].SecurityContext.Capabilities.Add = []corev1.Capability{"SYS_ADMIN"}
+	spec.Containers[0].Ports = []corev1.ContainerPort{{ContainerPort: 80, HostPort: 80}}
+
+	violations := EvaluatePodSecurity(map[string]string{
+		"container.apparmor.security.beta.kubernetes.io/app": "unconfined",
+	}, spec)
+
+	if level := HighestPSSLevel(violations); level != PSSPrivileged {
+		t.Errorf("HighestPSSLevel() = %s, want privileged", level)
+	}
+	checks := make(map[string]bool)
+	for _, v := range violations {
+		if v.Level == PSSBaseline {
+			checks[v.Check] = true
+		}
+	}
+	for _, want := range []string{"Host Namespaces", "HostPath Volumes", "Privileged Containers", "Capabilities", "Host Ports", "AppArmor"} {
+		if !checks[want] {
+			t.Errorf("expected baseline check %q to fail, got %+v", want, violations)
+		}
+	}
+}
+
+func TestParsePSSLabels(t *testing.T) {
+	cfg := ParsePSSLabels(map[string]string{
+		"pod-security.kubernetes.io/enforce":         "baseline",
+		"pod-security.kubernetes.io/enforce-version": "v1.30",
+		"pod-security.kubernetes.io/warn":            "restricted",
+	})
+	if cfg.Enforce != PSSBaseline || cfg.EnforceVersion != "v1.30" {
+		t.Errorf("unexpected enforce: %+v", cfg)
+	}
+	if cfg.Warn != PSSRestricted || cfg.Audit != PSSPrivileged || cfg.AuditVersion != "latest" {
+		t.Errorf("unexpected audit/warn: %+v", cfg)
+	}
+	if NextPSSLevel(cfg.Enforce) != PSSRestricted || NextPSSLevel(PSSRestricted) != "" {
+		t.Error("unexpected NextPSSLevel")
+	}
+}
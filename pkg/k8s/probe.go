@@ -0,0 +1,323 @@
+package k8s
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+// Probe modes.
+const (
+	ProbeModeHTTP = "http"
+	ProbeModeTCP  = "tcp"
+)
+
+// ProbeOptions describes how a port is probed through the apiserver proxy.
+type ProbeOptions struct {
+	Mode    string // ProbeModeHTTP or ProbeModeTCP
+	Scheme  string // "http" or "https"
+	Path    string
+	Timeout time.Duration
+}
+
+// ProbeResult is the outcome of one probe. Connected is false when the apiserver could not open a
+// connection to the target; StatusCode is only set when the target answered with HTTP.
+type ProbeResult struct {
+	Connected  bool
+	StatusCode int
+	Latency    time.Duration
+	Body       string // start of the response body for 4xx/5xx answers
+	Error      string
+}
+
+// ProbeTLSInfo describes the TLS handshake with an endpoint.
+type ProbeTLSInfo struct {
+	Version     string
+	CipherSuite string
+	Subject     string
+	Issuer      string
+	DNSNames    []string
+	NotAfter    time.Time
+	SelfSigned  bool
+	NameMatches bool // whether the certificate is valid for one of the expected names
+	Problems    []string
+}
+
+// probeBodyLimit caps how much of an error response body is kept.
+const probeBodyLimit = 200
+
+// ProbeService sends a request to a service port through the services/proxy subresource, so the
+// apiserver picks one of the service's ready endpoints. port is the service port name or number.
+func (c *ClusterClient) ProbeService(ctx context.Context, namespace, name, port string, opts ProbeOptions) ProbeResult {
+	return c.proxyProbe(ctx, "services", namespace, fmt.Sprintf("%s:%s:%s", opts.Scheme, name, port), opts)
+}
+
+// ProbePod sends a request to a container port through the pods/proxy subresource.
+func (c *ClusterClient) ProbePod(ctx context.Context, pod *corev1.Pod, port int32, opts ProbeOptions) ProbeResult {
+	return c.proxyProbe(ctx, "pods", pod.Namespace, fmt.Sprintf("%s:%s:%d", opts.Scheme, pod.Name, port), opts)
+}
+
+// proxyProbe issues a GET through a proxy subresource and classifies the answer. The request is
+// sent with the REST client's HTTP client rather than Do(), which discards the status and body of
+// non-JSON error responses. The apiserver reports its own connection failures as 503 Status
+// objects ("error trying to reach service"); those are told apart from a 503 sent by the target.
+func (c *ClusterClient) proxyProbe(ctx context.Context, resource, namespace, name string, opts ProbeOptions) ProbeResult {
+	rc, err := c.coreRESTClient()
+	if err != nil {
+		return ProbeResult{Error: err.Error()}
+	}
+	restClient, ok := rc.(*rest.RESTClient)
+	if !ok || restClient.Client == nil {
+		return ProbeResult{Error: "apiserver proxy not available (no HTTP client)"}
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	u := rc.Get().
+		Namespace(namespace).
+		Resource(resource).
+		Name(name).
+		SubResource("proxy").
+		Suffix(strings.TrimPrefix(opts.Path, "/")).
+		URL()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return ProbeResult{Error: err.Error()}
+	}
+
+	start := time.Now()
+	resp, err := restClient.Client.Do(req)
+	res := ProbeResult{Latency: time.Since(start)}
+	if err != nil {
+		if ctx.Err() != nil {
+			res.Error = fmt.Sprintf("timed out after %s", timeout)
+		} else {
+			res.Error = err.Error()
+		}
+		return res
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	res.Latency = time.Since(start)
+
+	if msg, ok := proxyFailure(resp, body); ok {
+		switch {
+		case strings.Contains(msg, "no endpoints available"):
+			res.Error = msg
+		case isDialError(msg):
+			res.Error = strings.TrimPrefix(msg, "error trying to reach service: ")
+		default:
+			// The connection was accepted but the target did not answer with valid HTTP.
+			res.Connected = true
+			if opts.Mode == ProbeModeHTTP {
+				res.Error = strings.TrimPrefix(msg, "error trying to reach service: ")
+			}
+		}
+		return res
+	}
+
+	res.Connected = true
+	res.StatusCode = resp.StatusCode
+	if resp.StatusCode >= http.StatusBadRequest {
+		res.Body = util.TruncateString(strings.TrimSpace(string(body)), probeBodyLimit)
+	}
+	return res
+}
+
+// proxyFailure returns the message of a Status object the apiserver sent because it could not
+// reach the proxy target, as opposed to a response produced by the target itself.
+func proxyFailure(resp *http.Response, body []byte) (string, bool) {
+	if resp.StatusCode != http.StatusServiceUnavailable && resp.StatusCode != http.StatusBadGateway {
+		return "", false
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		return "", false
+	}
+	var status metav1.Status
+	if err := json.Unmarshal(body, &status); err != nil || status.Kind != "Status" {
+		return "", false
+	}
+	if strings.Contains(status.Message, "error trying to reach service") || strings.Contains(status.Message, "no endpoints available") {
+		return status.Message, true
+	}
+	return "", false
+}
+
+// isDialError reports whether an apiserver proxy error means the TCP connection itself failed.
+func isDialError(msg string) bool {
+	for _, s := range []string{"dial tcp", "connection refused", "no route to host", "i/o timeout", "connection timed out", "network is unreachable"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProbeTLS performs a TLS handshake directly with address (host:port) and describes the certificate
+// it presents. expectedNames are the DNS names clients use for the endpoint. This requires network
+// access to pod IPs, which the apiserver proxy does not provide, so it fails when the server runs
+// outside the cluster network.
+func ProbeTLS(ctx context.Context, address string, expectedNames []string, timeout time.Duration) (*ProbeTLSInfo, error) {
+	serverName := ""
+	if len(expectedNames) > 0 {
+		serverName = expectedNames[0]
+	}
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: timeout},
+		// The certificate is inspected below; verification would hide what is wrong with it.
+		Config: &tls.Config{InsecureSkipVerify: true, ServerName: serverName},
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	info := &ProbeTLSInfo{
+		Version:     tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+	}
+	if len(state.PeerCertificates) == 0 {
+		info.Problems = append(info.Problems, "endpoint presented no certificate")
+		return info, nil
+	}
+	leaf := state.PeerCertificates[0]
+	now := time.Now()
+	info.Subject = leaf.Subject.CommonName
+	info.Issuer = leaf.Issuer.CommonName
+	info.DNSNames = leaf.DNSNames
+	info.NotAfter = leaf.NotAfter
+	info.SelfSigned = util.IsSelfSigned(leaf)
+	if days := util.DaysUntilExpiry(leaf, now); now.After(leaf.NotAfter) {
+		info.Problems = append(info.Problems, fmt.Sprintf("certificate expired %s", leaf.NotAfter.Format(time.RFC3339)))
+	} else if days < 14 {
+		info.Problems = append(info.Problems, fmt.Sprintf("certificate expires in %d day(s)", days))
+	}
+	info.Problems = append(info.Problems, util.CertificateChainProblems(state.PeerCertificates, now)...)
+	for _, name := range expectedNames {
+		if leaf.VerifyHostname(name) == nil {
+			info.NameMatches = true
+			break
+		}
+	}
+	if !info.NameMatches && len(expectedNames) > 0 {
+		info.Problems = append(info.Problems, fmt.Sprintf("certificate is not valid for %s", strings.Join(expectedNames, ", ")))
+	}
+	return info, nil
+}
+
+// ServiceDNSNames returns the names a service is addressed by inside the cluster. The first is
+// the conventional TLS server name.
+func ServiceDNSNames(namespace, name string) []string {
+	return []string{
+		name + "." + namespace + ".svc",
+		name + "." + namespace + ".svc.cluster.local",
+		name + "." + namespace,
+		name,
+	}
+}
+
+// ProbeAddress joins a pod IP and port into a dialable address, bracketing IPv6 literals.
+func ProbeAddress(ip string, port int32) string {
+	return net.JoinHostPort(ip, strconv.Itoa(int(port)))
+}
+
+// ParseServiceURL splits a cluster-internal URL such as
+// http://source-controller.flux-system.svc.cluster.local./path into the service namespace,
+// name and port. ok is false for hosts that are not <name>.<namespace>.svc[.<domain>].
+func ParseServiceURL(rawURL string) (namespace, name, port string, ok bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", "", "", false
+	}
+	labels := strings.Split(strings.TrimSuffix(u.Hostname(), "."), ".")
+	if len(labels) < 3 || labels[2] != "svc" {
+		return "", "", "", false
+	}
+	port = u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return labels[1], labels[0], port, true
+}
+
+// GetThroughServiceProxy downloads a cluster-internal service URL through the services/proxy
+// subresource, for when the server runs outside the cluster network and cannot resolve the
+// service name itself. At most maxBytes are read; a larger body is an error. The request is
+// bounded by util.DefaultTimeout so an unresponsive service cannot hang the caller.
+func (c *ClusterClient) GetThroughServiceProxy(ctx context.Context, rawURL string, maxBytes int64) ([]byte, error) {
+	namespace, name, port, ok := ParseServiceURL(rawURL)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a cluster service URL", rawURL)
+	}
+	rc, err := c.coreRESTClient()
+	if err != nil {
+		return nil, err
+	}
+	restClient, isREST := rc.(*rest.RESTClient)
+	if !isREST || restClient.Client == nil {
+		return nil, fmt.Errorf("apiserver proxy not available (no HTTP client)")
+	}
+	u, _ := url.Parse(rawURL)
+	ctx, cancel := context.WithTimeout(ctx, util.DefaultTimeout)
+	defer cancel()
+
+	req := rc.Get().
+		Namespace(namespace).
+		Resource("services").
+		Name(fmt.Sprintf("%s:%s:%s", u.Scheme, name, port)).
+		SubResource("proxy").
+		Suffix(strings.TrimPrefix(u.Path, "/"))
+	for k, vs := range u.Query() {
+		for _, v := range vs {
+			req = req.Param(k, v)
+		}
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, req.URL().String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := restClient.Client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if msg, failed := proxyFailure(resp, body); failed {
+		return nil, fmt.Errorf("%s", msg)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s through the apiserver proxy: %s", rawURL, resp.Status)
+	}
+	if int64(len(body)) > maxBytes {
+		return nil, fmt.Errorf("response from %s exceeds %d bytes", rawURL, maxBytes)
+	}
+	return body, nil
+}
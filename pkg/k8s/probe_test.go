@@ -0,0 +1,183 @@
+package k8s
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const testProxyDialError = `{"kind":"Status","apiVersion":"v1","metadata":{},"status":"Failure",` +
+	`"message":"error trying to reach service: dial tcp 10.0.0.3:8080: connect: connection refused","reason":"ServiceUnavailable","code":503}`
+
+const testProxyMalformed = `{"kind":"Status","apiVersion":"v1","metadata":{},"status":"Failure",` +
+	`"message":"error trying to reach service: net/http: HTTP/1.x transport connection broken: malformed HTTP response","reason":"ServiceUnavailable","code":503}`
+
+func newProbeTestClient(t *testing.T) *ClusterClient {
+	return newProxyTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/namespaces/shop/services/http:web:80/proxy/healthz",
+			"/api/v1/namespaces/shop/pods/http:web-1:8080/proxy/healthz":
+			_, _ = w.Write([]byte("ok"))
+		case "/api/v1/namespaces/shop/pods/http:web-2:8080/proxy/healthz":
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("database connection pool exhausted"))
+		case "/api/v1/namespaces/shop/pods/http:web-3:8080/proxy/healthz":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(testProxyDialError))
+		case "/api/v1/namespaces/shop/pods/http:db-1:5432/proxy":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(testProxyMalformed))
+		case "/api/v1/namespaces/shop/pods/http:slow-1:8080/proxy/healthz":
+			time.Sleep(300 * time.Millisecond)
+			_, _ = w.Write([]byte("late"))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+func probePod(name string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop"}}
+}
+
+func TestProbeThroughProxy(t *testing.T) {
+	client := newProbeTestClient(t)
+	ctx := context.Background()
+	httpOpts := ProbeOptions{Mode: ProbeModeHTTP, Scheme: "http", Path: "/healthz", Timeout: 2 * time.Second}
+
+	if res := client.ProbeService(ctx, "shop", "web", "80", httpOpts); !res.Connected || res.StatusCode != 200 || res.Error != "" {
+		t.Errorf("service probe: unexpected result %+v", res)
+	}
+	if res := client.ProbePod(ctx, probePod("web-1"), 8080, httpOpts); !res.Connected || res.StatusCode != 200 {
+		t.Errorf("web-1: unexpected result %+v", res)
+	}
+
+	res := client.ProbePod(ctx, probePod("web-2"), 8080, httpOpts)
+	if !res.Connected || res.StatusCode != 503 || !strings.Contains(res.Body, "pool exhausted") {
+		t.Errorf("web-2: expected an application 503 with body, got %+v", res)
+	}
+
+	res = client.ProbePod(ctx, probePod("web-3"), 8080, httpOpts)
+	if res.Connected || res.StatusCode != 0 || !strings.Contains(res.Error, "connection refused") {
+		t.Errorf("web-3: expected a refused connection, got %+v", res)
+	}
+
+	tcpOpts := ProbeOptions{Mode: ProbeModeTCP, Scheme: "http", Timeout: 2 * time.Second}
+	if res := client.ProbePod(ctx, probePod("db-1"), 5432, tcpOpts); !res.Connected || res.Error != "" {
+		t.Errorf("db-1: a non-HTTP listener should count as open in TCP mode, got %+v", res)
+	}
+	httpOpts.Path = ""
+	if res := client.ProbePod(ctx, probePod("db-1"), 5432, httpOpts); !res.Connected || !strings.Contains(res.Error, "malformed HTTP response") {
+		t.Errorf("db-1: expected a malformed response error in HTTP mode, got %+v", res)
+	}
+
+	httpOpts.Path, httpOpts.Timeout = "/healthz", 50*time.Millisecond
+	if res := client.ProbePod(ctx, probePod("slow-1"), 8080, httpOpts); res.Connected || !strings.Contains(res.Error, "timed out") {
+		t.Errorf("slow-1: expected a timeout, got %+v", res)
+	}
+}
+
+func TestProbeWithoutRESTClient(t *testing.T) {
+	client := NewClusterClientForTesting(fake.NewSimpleClientset(), nil)
+	res := client.ProbePod(context.Background(), probePod("web-1"), 8080, ProbeOptions{Scheme: "http"})
+	if res.Connected || !strings.Contains(res.Error, "apiserver proxy not available") {
+		t.Errorf("expected proxy unavailable error, got %+v", res)
+	}
+}
+
+func TestProbeTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+
+	info, err := ProbeTLS(context.Background(), ProbeAddress(host, int32(p)), ServiceDNSNames("shop", "web"), 2*time.Second)
+	if err != nil {
+		t.Fatalf("ProbeTLS() error = %v", err)
+	}
+	if !strings.HasPrefix(info.Version, "TLS") || info.CipherSuite == "" {
+		t.Errorf("expected negotiated version and cipher, got %+v", info)
+	}
+	if info.NameMatches || len(info.Problems) == 0 || !strings.Contains(info.Problems[len(info.Problems)-1], "web.shop.svc") {
+		t.Errorf("expected a name mismatch for the service names, got %+v", info)
+	}
+
+	info, err = ProbeTLS(context.Background(), ProbeAddress(host, int32(p)), []string{"example.com"}, 2*time.Second)
+	if err != nil || !info.NameMatches {
+		t.Errorf("expected the test certificate to be valid for example.com, got %+v (%v)", info, err)
+	}
+
+	if _, err := ProbeTLS(context.Background(), "127.0.0.1:1", nil, time.Second); err == nil {
+		t.Error("expected an error for a closed port")
+	}
+}
+
+func TestServiceDNSNames(t *testing.T) {
+	names := ServiceDNSNames("shop", "web")
+	if names[0] != "web.shop.svc" || names[len(names)-1] != "web" {
+		t.Errorf("unexpected names: %v", names)
+	}
+	if got := ProbeAddress("fd00::1", 8443); got != "[fd00::1]:8443" {
+		t.Errorf("ProbeAddress() = %q", got)
+	}
+}
+
+func TestGetThroughServiceProxy(t *testing.T) {
+	client := newProxyTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/namespaces/flux-system/services/http:source-controller:80/proxy/gitrepository/flux-system/app/abc.tar.gz":
+			_, _ = w.Write([]byte("artifact"))
+		case "/api/v1/namespaces/flux-system/services/http:source-controller:80/proxy/big.tar.gz":
+			_, _ = w.Write([]byte(strings.Repeat("x", 64)))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	ctx := context.Background()
+
+	body, err := client.GetThroughServiceProxy(ctx, "http://source-controller.flux-system.svc.cluster.local./gitrepository/flux-system/app/abc.tar.gz", 1024)
+	if err != nil || string(body) != "artifact" {
+		t.Fatalf("GetThroughServiceProxy() = %q, %v", body, err)
+	}
+	if _, err := client.GetThroughServiceProxy(ctx, "http://source-controller.flux-system.svc/big.tar.gz", 16); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("expected a size error, got %v", err)
+	}
+	if _, err := client.GetThroughServiceProxy(ctx, "http://source-controller.flux-system.svc/missing", 16); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected a 404 error, got %v", err)
+	}
+	if _, err := client.GetThroughServiceProxy(ctx, "https://example.com/a.tar.gz", 16); err == nil {
+		t.Error("expected an error for a non-service URL")
+	}
+}
+
+func TestParseServiceURL(t *testing.T) {
+	tests := []struct {
+		url                   string
+		namespace, name, port string
+		ok                    bool
+	}{
+		{"http://source-controller.flux-system.svc.cluster.local./a.tar.gz", "flux-system", "source-controller", "80", true},
+		{"https://web.shop.svc:8443/x", "shop", "web", "8443", true},
+		{"https://web.shop.svc/x", "shop", "web", "443", true},
+		{"http://10.0.0.1/x", "", "", "", false},
+		{"ftp://web.shop.svc/x", "", "", "", false},
+	}
+	for _, tt := range tests {
+		ns, name, port, ok := ParseServiceURL(tt.url)
+		if ns != tt.namespace || name != tt.name || port != tt.port || ok != tt.ok {
+			t.Errorf("ParseServiceURL(%q) = %q, %q, %q, %v", tt.url, ns, name, port, ok)
+		}
+	}
+}
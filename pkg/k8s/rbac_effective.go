@@ -0,0 +1,289 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RBACSnapshot holds every RBAC object needed to resolve effective permissions.
+type RBACSnapshot struct {
+	Roles               []rbacv1.Role
+	ClusterRoles        []rbacv1.ClusterRole
+	RoleBindings        []rbacv1.RoleBinding
+	ClusterRoleBindings []rbacv1.ClusterRoleBinding
+}
+
+// EffectiveGrant is a single policy rule granted to a subject through a binding.
+type EffectiveGrant struct {
+	Subject          rbacv1.Subject
+	BindingKind      string // RoleBinding or ClusterRoleBinding
+	BindingName      string
+	BindingNamespace string
+	RoleKind         string // Role or ClusterRole
+	RoleName         string
+	// Namespace is where the rule applies; empty means cluster-wide.
+	Namespace string
+	Rule      rbacv1.PolicyRule
+}
+
+// ResourceRequest describes an action to check against RBAC rules.
+type ResourceRequest struct {
+	Verb        string
+	APIGroup    string
+	Resource    string
+	Subresource string
+	Name        string
+	Namespace   string // empty for a cluster-wide check
+}
+
+// GetRBACSnapshot fetches Roles, ClusterRoles and their bindings across all namespaces.
+func (c *ClusterClient) GetRBACSnapshot(ctx context.Context) (*RBACSnapshot, error) {
+	roles, err := c.ListRoles(ctx, "", metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing roles: %w", err)
+	}
+	clusterRoles, err := c.ListClusterRoles(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing cluster roles: %w", err)
+	}
+	roleBindings, err := c.ListRoleBindings(ctx, "", metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing role bindings: %w", err)
+	}
+	clusterRoleBindings, err := c.ListClusterRoleBindings(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing cluster role bindings: %w", err)
+	}
+	return &RBACSnapshot{
+		Roles:               roles,
+		ClusterRoles:        clusterRoles,
+		RoleBindings:        roleBindings,
+		ClusterRoleBindings: clusterRoleBindings,
+	}, nil
+}
+
+// ClusterRoleRules returns the rules of a ClusterRole. For aggregated ClusterRoles these already
+// include the rules of every selected ClusterRole: the aggregation controller copies them into
+// Rules, so the selected roles are not expanded again.
+func (s *RBACSnapshot) ClusterRoleRules(name string) []rbacv1.PolicyRule {
+	for i := range s.ClusterRoles {
+		if s.ClusterRoles[i].Name == name {
+			return s.ClusterRoles[i].Rules
+		}
+	}
+	return nil
+}
+
+// roleRules returns the rules of a namespaced Role.
+func (s *RBACSnapshot) roleRules(namespace, name string) []rbacv1.PolicyRule {
+	for i := range s.Roles {
+		if s.Roles[i].Namespace == namespace && s.Roles[i].Name == name {
+			return s.Roles[i].Rules
+		}
+	}
+	return nil
+}
+
+// Grants expands every binding into one EffectiveGrant per subject and rule.
+func (s *RBACSnapshot) Grants() []EffectiveGrant {
+	var grants []EffectiveGrant
+	for _, crb := range s.ClusterRoleBindings {
+		rules := s.ClusterRoleRules(crb.RoleRef.Name)
+		for _, subject := range crb.Subjects {
+			for _, rule := range rules {
+				grants = append(grants, EffectiveGrant{
+					Subject:     subject,
+					BindingKind: "ClusterRoleBinding",
+					BindingName: crb.Name,
+					RoleKind:    crb.RoleRef.Kind,
+					RoleName:    crb.RoleRef.Name,
+					Rule:        rule,
+				})
+			}
+		}
+	}
+	for _, rb := range s.RoleBindings {
+		var rules []rbacv1.PolicyRule
+		if rb.RoleRef.Kind == "ClusterRole" {
+			rules = s.ClusterRoleRules(rb.RoleRef.Name)
+		} else {
+			rules = s.roleRules(rb.Namespace, rb.RoleRef.Name)
+		}
+		for _, subject := range rb.Subjects {
+			// ServiceAccount subjects without a namespace default to the binding's namespace
+			if subject.Kind == rbacv1.ServiceAccountKind && subject.Namespace == "" {
+				subject.Namespace = rb.Namespace
+			}
+			for _, rule := range rules {
+				grants = append(grants, EffectiveGrant{
+					Subject:          subject,
+					BindingKind:      "RoleBinding",
+					BindingName:      rb.Name,
+					BindingNamespace: rb.Namespace,
+					RoleKind:         rb.RoleRef.Kind,
+					RoleName:         rb.RoleRef.Name,
+					Namespace:        rb.Namespace,
+					Rule:             rule,
+				})
+			}
+		}
+	}
+	return grants
+}
+
+// WhoCan returns the grants that allow the request.
+// For a cluster-wide request (empty Namespace) only ClusterRoleBindings are considered.
+func (s *RBACSnapshot) WhoCan(req ResourceRequest) []EffectiveGrant {
+	var result []EffectiveGrant
+	for _, g := range s.Grants() {
+		if g.Namespace != "" && g.Namespace != req.Namespace {
+			continue
+		}
+		if RuleAllows(g.Rule, req) {
+			result = append(result, g)
+		}
+	}
+	return result
+}
+
+// GrantsForSubject returns every grant that applies to the subject, including grants to groups
+// the subject implicitly belongs to (system:serviceaccounts, system:authenticated, ...).
+func (s *RBACSnapshot) GrantsForSubject(kind, name, namespace string, groups []string) []EffectiveGrant {
+	memberOf := make(map[string]bool)
+	for _, g := range groups {
+		memberOf[g] = true
+	}
+	if kind == rbacv1.ServiceAccountKind {
+		memberOf["system:serviceaccounts"] = true
+		memberOf["system:serviceaccounts:"+namespace] = true
+		memberOf["system:authenticated"] = true
+	}
+	if kind == rbacv1.UserKind && name != "system:anonymous" {
+		memberOf["system:authenticated"] = true
+	}
+
+	var result []EffectiveGrant
+	for _, g := range s.Grants() {
+		subj := g.Subject
+		switch {
+		case subj.Kind == kind && subj.Name == name && (kind != rbacv1.ServiceAccountKind || subj.Namespace == namespace):
+			result = append(result, g)
+		case subj.Kind == rbacv1.GroupKind && memberOf[subj.Name]:
+			result = append(result, g)
+		}
+	}
+	return result
+}
+
+// RuleAllows reports whether a policy rule permits the request.
+// Rules restricted by resourceNames only match requests that name one of them; like the
+// authorizer, they never grant unnamed requests such as list, watch or create.
+func RuleAllows(rule rbacv1.PolicyRule, req ResourceRequest) bool {
+	if len(rule.NonResourceURLs) > 0 && len(rule.Resources) == 0 {
+		return false
+	}
+	if !matchesAny(rule.Verbs, req.Verb) || !matchesAny(rule.APIGroups, req.APIGroup) {
+		return false
+	}
+	if !resourceMatches(rule.Resources, req.Resource, req.Subresource) {
+		return false
+	}
+	if len(rule.ResourceNames) > 0 && (req.Name == "" || !containsValue(rule.ResourceNames, req.Name)) {
+		return false
+	}
+	return true
+}
+
+// DangerousGrantReasons explains why a rule grants privilege-escalation or data-exposure capabilities.
+func DangerousGrantReasons(rule rbacv1.PolicyRule) []string {
+	var reasons []string
+	core := matchesAny(rule.APIGroups, "")
+	rbac := matchesAny(rule.APIGroups, rbacv1.GroupName)
+	check := func(ok bool, reason string) {
+		if ok {
+			reasons = append(reasons, reason)
+		}
+	}
+
+	if containsValue(rule.Verbs, "*") && containsValue(rule.Resources, "*") && containsValue(rule.APIGroups, "*") {
+		return []string{"full wildcard access (*/*/*) — equivalent to cluster-admin"}
+	}
+	check(core && resourceMatches(rule.Resources, "secrets", "") &&
+		(matchesAny(rule.Verbs, "get") || matchesAny(rule.Verbs, "list") || matchesAny(rule.Verbs, "watch")),
+		"can read secrets")
+	check(core && resourceMatches(rule.Resources, "pods", "exec") && (matchesAny(rule.Verbs, "create") || matchesAny(rule.Verbs, "get")),
+		"can exec into pods (pods/exec)")
+	check(core && resourceMatches(rule.Resources, "pods", "attach") && matchesAny(rule.Verbs, "create"),
+		"can attach to pods (pods/attach)")
+	check(core && resourceMatches(rule.Resources, "nodes", "proxy") && (matchesAny(rule.Verbs, "get") || matchesAny(rule.Verbs, "create")),
+		"can proxy to kubelets (nodes/proxy)")
+	check(core && resourceMatches(rule.Resources, "serviceaccounts", "token") && matchesAny(rule.Verbs, "create"),
+		"can mint service account tokens (serviceaccounts/token)")
+	check(rbac && matchesAny(rule.Verbs, "escalate"), "can escalate role permissions (escalate)")
+	check(rbac && matchesAny(rule.Verbs, "bind"), "can bind arbitrary roles (bind)")
+	check(matchesAny(rule.Verbs, "impersonate") &&
+		(resourceMatches(rule.Resources, "users", "") || resourceMatches(rule.Resources, "groups", "") ||
+			resourceMatches(rule.Resources, "serviceaccounts", "") || resourceMatches(rule.Resources, "uids", "")),
+		"can impersonate users, groups or service accounts")
+	check(core && resourceMatches(rule.Resources, "pods", "") && matchesAny(rule.Verbs, "create"),
+		"can create pods (may mount any secret or run privileged)")
+	return reasons
+}
+
+// FormatRule renders a policy rule in a compact "verbs on group/resources" form.
+func FormatRule(rule rbacv1.PolicyRule) string {
+	if len(rule.NonResourceURLs) > 0 {
+		return fmt.Sprintf("%s on %s", strings.Join(rule.Verbs, ","), strings.Join(rule.NonResourceURLs, ","))
+	}
+	groups := make([]string, 0, len(rule.APIGroups))
+	for _, g := range rule.APIGroups {
+		if g == "" {
+			g = "core"
+		}
+		groups = append(groups, g)
+	}
+	s := fmt.Sprintf("%s on %s/%s", strings.Join(rule.Verbs, ","), strings.Join(groups, ","), strings.Join(rule.Resources, ","))
+	if len(rule.ResourceNames) > 0 {
+		s += fmt.Sprintf(" (names: %s)", strings.Join(rule.ResourceNames, ","))
+	}
+	return s
+}
+
+func matchesAny(values []string, want string) bool {
+	for _, v := range values {
+		if v == "*" || v == want {
+			return true
+		}
+	}
+	return false
+}
+
+// resourceMatches mirrors the apiserver's RBAC resource matching, including "*" and "*/subresource".
+func resourceMatches(ruleResources []string, resource, subresource string) bool {
+	combined := resource
+	if subresource != "" {
+		combined = resource + "/" + subresource
+	}
+	for _, r := range ruleResources {
+		if r == "*" || r == combined {
+			return true
+		}
+		if subresource != "" && r == "*/"+subresource {
+			return true
+		}
+	}
+	return false
+}
+
+func containsValue(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package k8s
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -86,3 +87,164 @@ func TestListRoles(t *testing.T) {
 		t.Errorf("expected 1 role, got %d", len(roles))
 	}
 }
+
+func testRBACSnapshot() *RBACSnapshot {
+	return &RBACSnapshot{
+		ClusterRoles: []rbacv1.ClusterRole{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "monitoring"},
+				AggregationRule: &rbacv1.AggregationRule{
+					ClusterRoleSelectors: []metav1.LabelSelector{{MatchLabels: map[string]string{"rbac.example.com/aggregate-to-monitoring": "true"}}},
+				},
+				// Filled in by the aggregation controller from secret-reader.
+				Rules: []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get", "list"}}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "config-reader"},
+				Rules:      []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"app-config"}, Verbs: []string{"get", "list"}}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "secret-reader", Labels: map[string]string{"rbac.example.com/aggregate-to-monitoring": "true"}},
+				Rules:      []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get", "list"}}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "exec-all"},
+				Rules:      []rbacv1.PolicyRule{{APIGroups: []string{"*"}, Resources: []string{"*/exec"}, Verbs: []string{"create"}}},
+			},
+		},
+		Roles: []rbacv1.Role{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "deployer", Namespace: "apps"},
+				Rules:      []rbacv1.PolicyRule{{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"*"}}},
+			},
+		},
+		ClusterRoleBindings: []rbacv1.ClusterRoleBinding{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "prometheus"},
+				RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "monitoring"},
+				Subjects:   []rbacv1.Subject{{Kind: "ServiceAccount", Name: "prometheus", Namespace: "monitoring"}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "oncall-exec"},
+				RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "exec-all"},
+				Subjects:   []rbacv1.Subject{{Kind: "Group", Name: "oncall"}},
+			},
+		},
+		RoleBindings: []rbacv1.RoleBinding{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "ci-deployer", Namespace: "apps"},
+				RoleRef:    rbacv1.RoleRef{Kind: "Role", Name: "deployer"},
+				Subjects:   []rbacv1.Subject{{Kind: "ServiceAccount", Name: "ci"}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "app-config", Namespace: "apps"},
+				RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "config-reader"},
+				Subjects:   []rbacv1.Subject{{Kind: "ServiceAccount", Name: "app"}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "all-sa-read", Namespace: "apps"},
+				RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "secret-reader"},
+				Subjects:   []rbacv1.Subject{{Kind: "Group", Name: "system:serviceaccounts:apps"}},
+			},
+		},
+	}
+}
+
+func TestClusterRoleRulesAggregation(t *testing.T) {
+	s := testRBACSnapshot()
+	rules := s.ClusterRoleRules("monitoring")
+	if len(rules) != 1 || rules[0].Resources[0] != "secrets" {
+		t.Errorf("expected the aggregated secrets rule once, got %+v", rules)
+	}
+}
+
+func TestWhoCanResourceNames(t *testing.T) {
+	s := testRBACSnapshot()
+
+	if grants := s.WhoCan(ResourceRequest{Verb: "get", Resource: "configmaps", Name: "app-config", Namespace: "apps"}); len(grants) != 1 {
+		t.Errorf("expected the named rule to allow get app-config, got %+v", grants)
+	}
+	if grants := s.WhoCan(ResourceRequest{Verb: "get", Resource: "configmaps", Name: "other", Namespace: "apps"}); len(grants) != 0 {
+		t.Errorf("expected no grant for another name, got %+v", grants)
+	}
+	if grants := s.WhoCan(ResourceRequest{Verb: "list", Resource: "configmaps", Namespace: "apps"}); len(grants) != 0 {
+		t.Errorf("a resourceNames rule must not allow unnamed requests, got %+v", grants)
+	}
+}
+
+func TestWhoCan(t *testing.T) {
+	s := testRBACSnapshot()
+
+	grants := s.WhoCan(ResourceRequest{Verb: "list", Resource: "secrets", Namespace: "apps"})
+	subjects := make(map[string]bool)
+	for _, g := range grants {
+		subjects[g.Subject.Kind+"/"+g.Subject.Name] = true
+	}
+	if !subjects["ServiceAccount/prometheus"] || !subjects["Group/system:serviceaccounts:apps"] {
+		t.Errorf("unexpected who-can subjects: %v", subjects)
+	}
+
+	// Cluster-wide check excludes RoleBindings
+	grants = s.WhoCan(ResourceRequest{Verb: "list", Resource: "secrets"})
+	if len(grants) != 1 {
+		t.Errorf("expected 1 cluster-wide grant, got %d", len(grants))
+	}
+
+	// Wildcard subresource
+	grants = s.WhoCan(ResourceRequest{Verb: "create", Resource: "pods", Subresource: "exec", Namespace: "default"})
+	if len(grants) != 1 || grants[0].Subject.Name != "oncall" {
+		t.Errorf("expected oncall to exec via */exec, got %+v", grants)
+	}
+}
+
+func TestGrantsForSubject(t *testing.T) {
+	s := testRBACSnapshot()
+
+	grants := s.GrantsForSubject("ServiceAccount", "ci", "apps", nil)
+	if len(grants) != 2 {
+		t.Fatalf("expected direct + group grants for apps/ci, got %d", len(grants))
+	}
+
+	grants = s.GrantsForSubject("User", "alice", "", []string{"oncall"})
+	if len(grants) != 1 || grants[0].RoleName != "exec-all" {
+		t.Errorf("expected exec-all via oncall group, got %+v", grants)
+	}
+}
+
+func TestDangerousGrantReasons(t *testing.T) {
+	tests := []struct {
+		name string
+		rule rbacv1.PolicyRule
+		want string
+	}{
+		{"secrets", rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}}, "secrets"},
+		{"exec", rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods/exec"}, Verbs: []string{"create"}}, "exec"},
+		{"escalate", rbacv1.PolicyRule{APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"clusterroles"}, Verbs: []string{"escalate"}}, "escalate"},
+		{"impersonate", rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"users"}, Verbs: []string{"impersonate"}}, "impersonate"},
+		{"nodes/proxy", rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"nodes/proxy"}, Verbs: []string{"get"}}, "nodes/proxy"},
+		{"cluster-admin", rbacv1.PolicyRule{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}, "cluster-admin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reasons := DangerousGrantReasons(tt.rule)
+			found := false
+			for _, r := range reasons {
+				if strings.Contains(r, tt.want) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("DangerousGrantReasons() = %v, want reason containing %q", reasons, tt.want)
+			}
+		})
+	}
+
+	for _, safe := range []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"}},
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"*"}},
+	} {
+		if reasons := DangerousGrantReasons(safe); len(reasons) != 0 {
+			t.Errorf("expected no reasons for %s, got %v", FormatRule(safe), reasons)
+		}
+	}
+}
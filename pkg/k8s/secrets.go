@@ -0,0 +1,30 @@
+package k8s
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+// ListSecrets returns Secrets in the given namespace.
+func (c *ClusterClient) ListSecrets(ctx context.Context, namespace string, opts metav1.ListOptions) ([]corev1.Secret, error) {
+	ctx, cancel := context.WithTimeout(ctx, util.DefaultTimeout)
+	defer cancel()
+
+	list, err := c.Clientset.CoreV1().Secrets(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// GetSecret returns a single Secret.
+func (c *ClusterClient) GetSecret(ctx context.Context, namespace, name string) (*corev1.Secret, error) {
+	ctx, cancel := context.WithTimeout(ctx, util.DefaultTimeout)
+	defer cancel()
+
+	return c.Clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+}
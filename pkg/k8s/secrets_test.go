@@ -0,0 +1,57 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestListSecrets(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-tls", Namespace: "default"},
+			Type:       corev1.SecretTypeTLS,
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "db-creds", Namespace: "other"},
+			Type:       corev1.SecretTypeOpaque,
+		},
+	)
+
+	client := NewClusterClientForTesting(fakeClient, nil)
+
+	secrets, err := client.ListSecrets(context.Background(), "default", metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("ListSecrets() error = %v", err)
+	}
+	if len(secrets) != 1 {
+		t.Errorf("expected 1 secret, got %d", len(secrets))
+	}
+}
+
+func TestGetSecret(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-tls", Namespace: "default"},
+			Type:       corev1.SecretTypeTLS,
+			Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert")},
+		},
+	)
+
+	client := NewClusterClientForTesting(fakeClient, nil)
+
+	secret, err := client.GetSecret(context.Background(), "default", "web-tls")
+	if err != nil {
+		t.Fatalf("GetSecret() error = %v", err)
+	}
+	if secret.Type != corev1.SecretTypeTLS {
+		t.Errorf("expected TLS secret, got %s", secret.Type)
+	}
+
+	if _, err := client.GetSecret(context.Background(), "default", "missing"); err == nil {
+		t.Error("expected error for missing secret")
+	}
+}
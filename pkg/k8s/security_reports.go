@@ -0,0 +1,321 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// CRD names of the security report providers.
+const (
+	TrivyVulnerabilityReportCRD = "vulnerabilityreports.aquasecurity.github.io"
+	TrivyConfigAuditReportCRD   = "configauditreports.aquasecurity.github.io"
+	PolicyReportCRD             = "policyreports.wgpolicyk8s.io"
+	ClusterPolicyReportCRD      = "clusterpolicyreports.wgpolicyk8s.io"
+
+	// GatekeeperConstraintGroup is the API group of every Gatekeeper constraint CRD.
+	GatekeeperConstraintGroup = "constraints.gatekeeper.sh"
+)
+
+// SeverityCounts tallies findings by severity.
+type SeverityCounts struct {
+	Critical int
+	High     int
+	Medium   int
+	Low      int
+	Unknown  int
+}
+
+// Total returns the number of findings across all severities.
+func (s SeverityCounts) Total() int {
+	return s.Critical + s.High + s.Medium + s.Low + s.Unknown
+}
+
+func (s *SeverityCounts) add(o SeverityCounts) {
+	s.Critical += o.Critical
+	s.High += o.High
+	s.Medium += o.Medium
+	s.Low += o.Low
+	s.Unknown += o.Unknown
+}
+
+// WorkloadSecurityReport aggregates every report that targets one workload.
+type WorkloadSecurityReport struct {
+	Namespace string
+	Kind      string
+	Name      string
+	// Vulnerabilities are summed across containers from trivy VulnerabilityReports.
+	Vulnerabilities SeverityCounts
+	// ConfigAudit counts failed checks from trivy ConfigAuditReports.
+	ConfigAudit SeverityCounts
+	// PolicyFail counts PolicyReport results of fail or error; PolicyWarn counts warn results.
+	PolicyFail int
+	PolicyWarn int
+	// ConstraintViolations counts Gatekeeper audit violations.
+	ConstraintViolations int
+	// Rules lists the failing policy rules, config checks and constraints by name.
+	Rules []string
+}
+
+// SecurityReports is the aggregated output of the installed report providers.
+type SecurityReports struct {
+	// Providers maps a provider name to whether its CRDs are installed.
+	Providers map[string]bool
+	Workloads []WorkloadSecurityReport
+	// Errors holds non-fatal listing errors per provider.
+	Errors []string
+}
+
+// Totals sums all workload reports.
+func (r *SecurityReports) Totals() WorkloadSecurityReport {
+	var t WorkloadSecurityReport
+	for _, w := range r.Workloads {
+		t.Vulnerabilities.add(w.Vulnerabilities)
+		t.ConfigAudit.add(w.ConfigAudit)
+		t.PolicyFail += w.PolicyFail
+		t.PolicyWarn += w.PolicyWarn
+		t.ConstraintViolations += w.ConstraintViolations
+	}
+	return t
+}
+
+// AnyProvider reports whether at least one report provider is installed.
+func (r *SecurityReports) AnyProvider() bool {
+	for _, installed := range r.Providers {
+		if installed {
+			return true
+		}
+	}
+	return false
+}
+
+// Report provider names used as keys in SecurityReports.Providers.
+const (
+	ProviderTrivyVulnerabilities = "trivy-operator VulnerabilityReports"
+	ProviderTrivyConfigAudit     = "trivy-operator ConfigAuditReports"
+	ProviderPolicyReports        = "PolicyReports (Kyverno / wgpolicyk8s.io)"
+	ProviderGatekeeper           = "Gatekeeper constraints"
+)
+
+// GetSecurityReports discovers report CRDs and aggregates their results per workload in a namespace (empty for all).
+func (c *ClusterClient) GetSecurityReports(ctx context.Context, namespace string) (*SecurityReports, error) {
+	crds, err := c.ListCRDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing CRDs: %w", err)
+	}
+
+	reports := &SecurityReports{Providers: make(map[string]bool)}
+	byKey := make(map[string]*WorkloadSecurityReport)
+	workload := func(ns, kind, name string) *WorkloadSecurityReport {
+		key := ns + "/" + kind + "/" + name
+		if w, ok := byKey[key]; ok {
+			return w
+		}
+		w := &WorkloadSecurityReport{Namespace: ns, Kind: kind, Name: name}
+		byKey[key] = w
+		return w
+	}
+	list := func(provider string, crd *apiextensionsv1.CustomResourceDefinition, ns string) []unstructured.Unstructured {
+		items, err := c.ListCustomResources(ctx, CRDGroupVersionResource(crd), ns)
+		if err != nil {
+			reports.Errors = append(reports.Errors, fmt.Sprintf("%s: %v", provider, err))
+		}
+		return items
+	}
+
+	// trivy-operator vulnerability reports
+	if crd := FindCRD(crds, TrivyVulnerabilityReportCRD); crd != nil {
+		reports.Providers[ProviderTrivyVulnerabilities] = true
+		for _, item := range list(ProviderTrivyVulnerabilities, crd, namespace) {
+			ns, kind, name := trivyReportTarget(&item)
+			workload(ns, kind, name).Vulnerabilities.add(trivySummary(&item))
+		}
+	} else {
+		reports.Providers[ProviderTrivyVulnerabilities] = false
+	}
+
+	// trivy-operator config audit reports
+	if crd := FindCRD(crds, TrivyConfigAuditReportCRD); crd != nil {
+		reports.Providers[ProviderTrivyConfigAudit] = true
+		for _, item := range list(ProviderTrivyConfigAudit, crd, namespace) {
+			ns, kind, name := trivyReportTarget(&item)
+			w := workload(ns, kind, name)
+			w.ConfigAudit.add(trivySummary(&item))
+			checks, _, _ := unstructured.NestedSlice(item.Object, "report", "checks")
+			for _, ch := range checks {
+				check, ok := ch.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if success, _, _ := unstructured.NestedBool(check, "success"); success {
+					continue
+				}
+				id, _, _ := unstructured.NestedString(check, "checkID")
+				title, _, _ := unstructured.NestedString(check, "title")
+				w.Rules = appendUnique(w.Rules, strings.TrimSpace(id+" "+title))
+			}
+		}
+	} else {
+		reports.Providers[ProviderTrivyConfigAudit] = false
+	}
+
+	// wgpolicyk8s.io PolicyReports (Kyverno and other engines)
+	policyCRD := FindCRD(crds, PolicyReportCRD)
+	reports.Providers[ProviderPolicyReports] = policyCRD != nil
+	if policyCRD != nil {
+		for _, item := range list(ProviderPolicyReports, policyCRD, namespace) {
+			addPolicyReportResults(&item, workload)
+		}
+	}
+	if crd := FindCRD(crds, ClusterPolicyReportCRD); crd != nil {
+		reports.Providers[ProviderPolicyReports] = true
+		for _, item := range list(ProviderPolicyReports, crd, "") {
+			addPolicyReportResults(&item, func(ns, kind, name string) *WorkloadSecurityReport {
+				if namespace != "" && ns != namespace {
+					return &WorkloadSecurityReport{}
+				}
+				return workload(ns, kind, name)
+			})
+		}
+	}
+
+	// Gatekeeper constraints: one CRD per ConstraintTemplate, audit results in status.violations
+	reports.Providers[ProviderGatekeeper] = false
+	for i := range crds {
+		crd := &crds[i]
+		if crd.Spec.Group != GatekeeperConstraintGroup {
+			continue
+		}
+		reports.Providers[ProviderGatekeeper] = true
+		for _, item := range list(ProviderGatekeeper, crd, "") {
+			violations, _, _ := unstructured.NestedSlice(item.Object, "status", "violations")
+			for _, v := range violations {
+				viol, ok := v.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				ns, _, _ := unstructured.NestedString(viol, "namespace")
+				if namespace != "" && ns != namespace {
+					continue
+				}
+				kind, _, _ := unstructured.NestedString(viol, "kind")
+				name, _, _ := unstructured.NestedString(viol, "name")
+				w := workload(ns, kind, name)
+				w.ConstraintViolations++
+				w.Rules = appendUnique(w.Rules, item.GetKind()+"/"+item.GetName())
+			}
+		}
+	}
+
+	for _, w := range byKey {
+		sort.Strings(w.Rules)
+		reports.Workloads = append(reports.Workloads, *w)
+	}
+	sort.Slice(reports.Workloads, func(i, j int) bool {
+		a, b := reports.Workloads[i], reports.Workloads[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	return reports, nil
+}
+
+// trivyReportTarget reads the workload a trivy-operator report describes from its labels.
+func trivyReportTarget(item *unstructured.Unstructured) (namespace, kind, name string) {
+	labels := item.GetLabels()
+	namespace = labels["trivy-operator.resource.namespace"]
+	if namespace == "" {
+		namespace = item.GetNamespace()
+	}
+	kind = labels["trivy-operator.resource.kind"]
+	name = labels["trivy-operator.resource.name"]
+	if kind == "" || name == "" {
+		kind, name = item.GetKind(), item.GetName()
+	}
+	return namespace, kind, name
+}
+
+// trivySummary reads report.summary severity counts.
+func trivySummary(item *unstructured.Unstructured) SeverityCounts {
+	count := func(field string) int {
+		v, _, _ := unstructured.NestedInt64(item.Object, "report", "summary", field)
+		return int(v)
+	}
+	return SeverityCounts{
+		Critical: count("criticalCount"),
+		High:     count("highCount"),
+		Medium:   count("mediumCount"),
+		Low:      count("lowCount"),
+		Unknown:  count("unknownCount"),
+	}
+}
+
+// addPolicyReportResults attributes each failing PolicyReport result to its resources,
+// falling back to the report scope used by per-resource reports.
+func addPolicyReportResults(item *unstructured.Unstructured, workload func(ns, kind, name string) *WorkloadSecurityReport) {
+	scopeNS, _, _ := unstructured.NestedString(item.Object, "scope", "namespace")
+	if scopeNS == "" {
+		scopeNS = item.GetNamespace()
+	}
+	scopeKind, _, _ := unstructured.NestedString(item.Object, "scope", "kind")
+	scopeName, _, _ := unstructured.NestedString(item.Object, "scope", "name")
+
+	results, _, _ := unstructured.NestedSlice(item.Object, "results")
+	for _, r := range results {
+		res, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		outcome, _, _ := unstructured.NestedString(res, "result")
+		if outcome != "fail" && outcome != "error" && outcome != "warn" {
+			continue
+		}
+		policy, _, _ := unstructured.NestedString(res, "policy")
+		rule, _, _ := unstructured.NestedString(res, "rule")
+		ruleName := policy
+		if rule != "" {
+			ruleName += "/" + rule
+		}
+
+		type target struct{ ns, kind, name string }
+		var targets []target
+		resources, _, _ := unstructured.NestedSlice(res, "resources")
+		for _, rr := range resources {
+			ref, ok := rr.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			ns, _, _ := unstructured.NestedString(ref, "namespace")
+			kind, _, _ := unstructured.NestedString(ref, "kind")
+			name, _, _ := unstructured.NestedString(ref, "name")
+			targets = append(targets, target{ns, kind, name})
+		}
+		if len(targets) == 0 {
+			targets = append(targets, target{scopeNS, scopeKind, scopeName})
+		}
+		for _, t := range targets {
+			w := workload(t.ns, t.kind, t.name)
+			if outcome == "warn" {
+				w.PolicyWarn++
+			} else {
+				w.PolicyFail++
+			}
+			w.Rules = appendUnique(w.Rules, ruleName)
+		}
+	}
+}
+
+func appendUnique(items []string, s string) []string {
+	if s == "" || containsValue(items, s) {
+		return items
+	}
+	return append(items, s)
+}
@@ -0,0 +1,132 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func reportCRD(group, plural, version string) *apiextensionsv1.CustomResourceDefinition {
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: plural + "." + group},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group:    group,
+			Names:    apiextensionsv1.CustomResourceDefinitionNames{Plural: plural},
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: version, Served: true, Storage: true}},
+		},
+	}
+}
+
+func TestGetSecurityReports(t *testing.T) {
+	vulnGVR := schema.GroupVersionResource{Group: "aquasecurity.github.io", Version: "v1alpha1", Resource: "vulnerabilityreports"}
+	polGVR := schema.GroupVersionResource{Group: "wgpolicyk8s.io", Version: "v1alpha2", Resource: "policyreports"}
+	gkGVR := schema.GroupVersionResource{Group: "constraints.gatekeeper.sh", Version: "v1beta1", Resource: "k8sdisallowedtags"}
+
+	vuln := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "aquasecurity.github.io/v1alpha1",
+		"kind":       "VulnerabilityReport",
+		"metadata": map[string]interface{}{
+			"name": "replicaset-web-abc-app", "namespace": "shop",
+			"labels": map[string]interface{}{
+				"trivy-operator.resource.kind":      "ReplicaSet",
+				"trivy-operator.resource.name":      "web-abc",
+				"trivy-operator.resource.namespace": "shop",
+			},
+		},
+		"report": map[string]interface{}{
+			"summary": map[string]interface{}{"criticalCount": int64(2), "highCount": int64(5)},
+		},
+	}}
+	policy := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "wgpolicyk8s.io/v1alpha2",
+		"kind":       "PolicyReport",
+		"metadata":   map[string]interface{}{"name": "pol-web", "namespace": "shop"},
+		"scope":      map[string]interface{}{"kind": "Deployment", "name": "web", "namespace": "shop"},
+		"results": []interface{}{
+			map[string]interface{}{"policy": "require-limits", "rule": "check-limits", "result": "fail"},
+			map[string]interface{}{"policy": "disallow-latest", "result": "warn"},
+			map[string]interface{}{"policy": "require-labels", "result": "pass"},
+		},
+	}}
+	constraint := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "constraints.gatekeeper.sh/v1beta1",
+		"kind":       "K8sDisallowedTag",
+		"metadata":   map[string]interface{}{"name": "no-latest-tag"},
+		"status": map[string]interface{}{
+			"violations": []interface{}{
+				map[string]interface{}{"kind": "Deployment", "name": "web", "namespace": "shop"},
+				map[string]interface{}{"kind": "Deployment", "name": "api", "namespace": "other"},
+			},
+		},
+	}}
+
+	apiext := apiextfake.NewSimpleClientset(
+		reportCRD("aquasecurity.github.io", "vulnerabilityreports", "v1alpha1"),
+		reportCRD("wgpolicyk8s.io", "policyreports", "v1alpha2"),
+		reportCRD("constraints.gatekeeper.sh", "k8sdisallowedtags", "v1beta1"),
+	)
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			vulnGVR: "VulnerabilityReportList",
+			polGVR:  "PolicyReportList",
+			gkGVR:   "K8sDisallowedTagList",
+		}, vuln, policy, constraint)
+
+	client := NewClusterClientForTestingWithDynamic(fake.NewSimpleClientset(), apiext, dyn)
+
+	reports, err := client.GetSecurityReports(context.Background(), "shop")
+	if err != nil {
+		t.Fatalf("GetSecurityReports() error = %v", err)
+	}
+	if !reports.Providers[ProviderTrivyVulnerabilities] || reports.Providers[ProviderTrivyConfigAudit] {
+		t.Errorf("unexpected providers: %v", reports.Providers)
+	}
+	if !reports.Providers[ProviderPolicyReports] || !reports.Providers[ProviderGatekeeper] {
+		t.Errorf("unexpected providers: %v", reports.Providers)
+	}
+	if len(reports.Workloads) != 2 {
+		t.Fatalf("expected 2 workloads, got %+v", reports.Workloads)
+	}
+
+	deploy, rs := reports.Workloads[0], reports.Workloads[1]
+	if deploy.Kind != "Deployment" || deploy.Name != "web" {
+		t.Fatalf("unexpected first workload: %+v", deploy)
+	}
+	if deploy.PolicyFail != 1 || deploy.PolicyWarn != 1 || deploy.ConstraintViolations != 1 {
+		t.Errorf("unexpected policy counts: %+v", deploy)
+	}
+	if len(deploy.Rules) != 3 {
+		t.Errorf("expected 3 failing rules, got %v", deploy.Rules)
+	}
+	if rs.Vulnerabilities.Critical != 2 || rs.Vulnerabilities.High != 5 {
+		t.Errorf("unexpected vulnerabilities: %+v", rs.Vulnerabilities)
+	}
+
+	totals := reports.Totals()
+	if totals.Vulnerabilities.Total() != 7 || totals.ConstraintViolations != 1 {
+		t.Errorf("unexpected totals: %+v", totals)
+	}
+}
+
+func TestGetSecurityReportsNoProviders(t *testing.T) {
+	client := NewClusterClientForTestingWithDynamic(fake.NewSimpleClientset(), apiextfake.NewSimpleClientset(), nil)
+
+	reports, err := client.GetSecurityReports(context.Background(), "")
+	if err != nil {
+		t.Fatalf("GetSecurityReports() error = %v", err)
+	}
+	if reports.AnyProvider() {
+		t.Errorf("expected no providers, got %v", reports.Providers)
+	}
+	if len(reports.Workloads) != 0 || len(reports.Errors) != 0 {
+		t.Errorf("unexpected output: %+v", reports)
+	}
+}
@@ -0,0 +1,458 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+// Dependency confidence levels, strongest first.
+const (
+	ConfidenceHigh   = "high"
+	ConfidenceMedium = "medium"
+	ConfidenceLow    = "low"
+)
+
+// Dependency evidence sources.
+const (
+	DependencySourceEnv          = "env"
+	DependencySourceConfigMap    = "configmap"
+	DependencySourceSecret       = "secret"
+	DependencySourceArgs         = "args"
+	DependencySourceExternalName = "externalname"
+)
+
+// maxDependencyEvidence caps the evidence kept per edge.
+const maxDependencyEvidence = 3
+
+// ServiceDependency represents an inferred dependency from one service to another.
+// External dependencies (a connection string or ExternalName pointing outside the
+// cluster) have External set, ToService holding the host and ToNS empty.
+type ServiceDependency struct {
+	FromService string
+	FromNS      string
+	ToService   string
+	ToNS        string
+	External    bool
+	Confidence  string   // "high", "medium", "low"
+	Source      string   // "env", "configmap", "secret", "args", "externalname"
+	Evidence    []string // where the reference was found, strongest first
+}
+
+// connectionStringPattern matches URLs in well-known connection-string formats and captures
+// the scheme and host. Datastore and broker schemes are strong evidence of a dependency;
+// http, https and grpc URLs are common in docs links, callbacks and telemetry endpoints, so
+// they are only reported with low confidence (see connectionConfidence).
+var connectionStringPattern = regexp.MustCompile(
+	`\b(postgres|postgresql|mysql|redis|rediss|amqp|amqps|mongodb|mongodb\+srv|nats|kafka|http|https|grpc)://(?:[^@/\s"']*@)?([A-Za-z0-9][A-Za-z0-9.-]*)`)
+
+// hostTokenPattern splits free text into host-like tokens.
+var hostTokenPattern = regexp.MustCompile(`[A-Za-z0-9][A-Za-z0-9.-]*`)
+
+// InferServiceDependencies infers inter-service dependencies from the pods backing each
+// service in namespace (all namespaces when empty). It scans env values, envFrom and
+// volume-mounted ConfigMaps, Secret key names (never values), container command/args,
+// and ExternalName services, resolving short names, name.namespace and
+// name.namespace.svc[.cluster-domain] references against services in every namespace.
+func (c *ClusterClient) InferServiceDependencies(ctx context.Context, namespace string) ([]ServiceDependency, error) {
+	// Targets may live in any namespace; fall back to the scanned namespace when the
+	// caller cannot list cluster-wide.
+	allServices, err := c.ListServices(ctx, "", metav1.ListOptions{})
+	if err != nil {
+		if namespace == "" {
+			return nil, err
+		}
+		if allServices, err = c.ListServices(ctx, namespace, metav1.ListOptions{}); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(allServices, func(i, j int) bool {
+		if allServices[i].Namespace != allServices[j].Namespace {
+			return allServices[i].Namespace < allServices[j].Namespace
+		}
+		return allServices[i].Name < allServices[j].Name
+	})
+
+	pods, err := c.ListPods(ctx, namespace, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	inf := &dependencyInferrer{
+		client:     c,
+		services:   make(map[string]*corev1.Service, len(allServices)),
+		configMaps: make(map[string]*corev1.ConfigMap),
+		secretKeys: make(map[string][]string),
+		edges:      make(map[string]*ServiceDependency),
+	}
+	for i := range allServices {
+		svc := &allServices[i]
+		inf.services[svc.Namespace+"/"+svc.Name] = svc
+	}
+
+	for i := range allServices {
+		svc := &allServices[i]
+		if namespace != "" && svc.Namespace != namespace {
+			continue
+		}
+		if svc.Spec.Type == corev1.ServiceTypeExternalName && svc.Spec.ExternalName != "" {
+			inf.scanExternalName(svc)
+		}
+	}
+
+	// Attribute each pod to the first service (by name) selecting it. Replicas of the same
+	// controller share a pod template, so only the first pod per owner is scanned.
+	scanned := make(map[string]bool)
+	for i := range pods {
+		pod := &pods[i]
+		for j := range allServices {
+			svc := &allServices[j]
+			if svc.Namespace != pod.Namespace || len(svc.Spec.Selector) == 0 {
+				continue
+			}
+			if labels.SelectorFromSet(svc.Spec.Selector).Matches(labels.Set(pod.Labels)) {
+				if key := svc.Name + "/" + podOwnerKey(pod); !scanned[key] {
+					scanned[key] = true
+					inf.scanPod(ctx, svc, pod)
+				}
+				break
+			}
+		}
+	}
+
+	deps := make([]ServiceDependency, 0, len(inf.order))
+	for _, key := range inf.order {
+		deps = append(deps, *inf.edges[key])
+	}
+	return deps, nil
+}
+
+// dependencyInferrer accumulates dependency edges and caches referenced config objects.
+type dependencyInferrer struct {
+	client     *ClusterClient
+	services   map[string]*corev1.Service // keyed by namespace/name
+	configMaps map[string]*corev1.ConfigMap
+	secretKeys map[string][]string
+	edges      map[string]*ServiceDependency
+	order      []string
+}
+
+// scanExternalName links an ExternalName service to the in-cluster service or external
+// host it aliases.
+func (inf *dependencyInferrer) scanExternalName(svc *corev1.Service) {
+	evidence := fmt.Sprintf("ExternalName %s -> %s", svc.Name, svc.Spec.ExternalName)
+	if target := inf.resolveHost(svc.Namespace, svc.Spec.ExternalName, false); target != nil {
+		inf.addEdge(svc, target.Namespace, target.Name, false, ConfidenceHigh, DependencySourceExternalName, evidence)
+		return
+	}
+	inf.addEdge(svc, "", strings.TrimSuffix(svc.Spec.ExternalName, "."), true, ConfidenceHigh, DependencySourceExternalName, evidence)
+}
+
+func (inf *dependencyInferrer) scanPod(ctx context.Context, from *corev1.Service, pod *corev1.Pod) {
+	containers := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for _, ctr := range containers {
+		where := fmt.Sprintf("pod %s container %s", pod.Name, ctr.Name)
+		for _, env := range ctr.Env {
+			// Kubernetes-style service env vars (*_SERVICE_HOST) set explicitly in the spec
+			if strings.HasSuffix(env.Name, "_SERVICE_HOST") {
+				target := strings.ToLower(strings.ReplaceAll(strings.TrimSuffix(env.Name, "_SERVICE_HOST"), "_", "-"))
+				if svc := inf.services[pod.Namespace+"/"+target]; svc != nil {
+					inf.addEdge(from, svc.Namespace, svc.Name, false, ConfidenceHigh, DependencySourceEnv, fmt.Sprintf("%s env %s", where, env.Name))
+				}
+			}
+			if env.Value != "" {
+				inf.scanText(from, env.Value, DependencySourceEnv, fmt.Sprintf("%s env %s", where, env.Name))
+			}
+			if env.ValueFrom == nil {
+				continue
+			}
+			if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil {
+				if cm := inf.configMap(ctx, pod.Namespace, ref.Name); cm != nil {
+					inf.scanText(from, cm.Data[ref.Key], DependencySourceConfigMap,
+						fmt.Sprintf("%s env %s from configmap %s key %s", where, env.Name, ref.Name, ref.Key))
+				}
+			}
+			if ref := env.ValueFrom.SecretKeyRef; ref != nil {
+				inf.scanKeyName(from, ref.Key, fmt.Sprintf("%s env %s from secret %s key %s", where, env.Name, ref.Name, ref.Key))
+			}
+		}
+		for _, ef := range ctr.EnvFrom {
+			if ef.ConfigMapRef != nil {
+				inf.scanConfigMap(ctx, from, pod.Namespace, ef.ConfigMapRef.Name, fmt.Sprintf("%s envFrom configmap", where))
+			}
+			if ef.SecretRef != nil {
+				inf.scanSecret(ctx, from, pod.Namespace, ef.SecretRef.Name, fmt.Sprintf("%s envFrom secret", where))
+			}
+		}
+		// Arguments can carry credentials, so evidence names the position rather than the value.
+		for i, arg := range append(append([]string{}, ctr.Command...), ctr.Args...) {
+			inf.scanText(from, arg, DependencySourceArgs, fmt.Sprintf("%s args[%d]", where, i))
+		}
+	}
+
+	for _, vol := range pod.Spec.Volumes {
+		where := fmt.Sprintf("pod %s volume %s", pod.Name, vol.Name)
+		if vol.ConfigMap != nil {
+			inf.scanConfigMap(ctx, from, pod.Namespace, vol.ConfigMap.Name, where+" configmap")
+		}
+		if vol.Secret != nil {
+			inf.scanSecret(ctx, from, pod.Namespace, vol.Secret.SecretName, where+" secret")
+		}
+		if vol.Projected != nil {
+			for _, src := range vol.Projected.Sources {
+				if src.ConfigMap != nil {
+					inf.scanConfigMap(ctx, from, pod.Namespace, src.ConfigMap.Name, where+" configmap")
+				}
+				if src.Secret != nil {
+					inf.scanSecret(ctx, from, pod.Namespace, src.Secret.Name, where+" secret")
+				}
+			}
+		}
+	}
+}
+
+func (inf *dependencyInferrer) scanConfigMap(ctx context.Context, from *corev1.Service, namespace, name, where string) {
+	cm := inf.configMap(ctx, namespace, name)
+	if cm == nil {
+		return
+	}
+	keys := make([]string, 0, len(cm.Data))
+	for k := range cm.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		inf.scanText(from, cm.Data[k], DependencySourceConfigMap, fmt.Sprintf("%s %s key %s", where, name, k))
+	}
+}
+
+// scanSecret looks only at key names; secret values are never read into evidence.
+func (inf *dependencyInferrer) scanSecret(ctx context.Context, from *corev1.Service, namespace, name, where string) {
+	for _, k := range inf.secretKeyNames(ctx, namespace, name) {
+		inf.scanKeyName(from, k, fmt.Sprintf("%s %s key %s", where, name, k))
+	}
+}
+
+// scanKeyName matches key names such as REDIS_PASSWORD or postgres-url against same-namespace
+// service names. Key names are weak evidence, so edges are low confidence.
+func (inf *dependencyInferrer) scanKeyName(from *corev1.Service, key, evidence string) {
+	norm := strings.ToLower(strings.NewReplacer("_", "-", ".", "-").Replace(key))
+	best := ""
+	for id, svc := range inf.services {
+		if svc.Namespace != from.Namespace || svc.Name == from.Name {
+			continue
+		}
+		if strings.HasPrefix(norm, svc.Name+"-") && len(svc.Name) > len(best) {
+			best = id
+		}
+	}
+	if best != "" {
+		svc := inf.services[best]
+		inf.addEdge(from, svc.Namespace, svc.Name, false, ConfidenceLow, DependencySourceSecret, evidence)
+	}
+}
+
+// scanText finds service references in a free-form value: connection strings first, then
+// namespaced DNS names, then a bare "name" or "name:port" value.
+func (inf *dependencyInferrer) scanText(from *corev1.Service, text, source, evidence string) {
+	if text == "" {
+		return
+	}
+	for _, m := range connectionStringPattern.FindAllStringSubmatchIndex(text, -1) {
+		scheme, host := text[m[2]:m[3]], text[m[4]:m[5]]
+		ev := fmt.Sprintf("%s (%s://%s)", evidence, scheme, host)
+		conf := connectionConfidence(scheme)
+		if svc := inf.resolveHost(from.Namespace, host, true); svc != nil {
+			inf.addEdge(from, svc.Namespace, svc.Name, false, conf, source, ev)
+		} else if isExternalHost(host) {
+			inf.addEdge(from, "", host, true, conf, source, ev)
+		}
+	}
+	text = connectionStringPattern.ReplaceAllString(text, " ")
+
+	for _, tok := range hostTokenPattern.FindAllString(text, -1) {
+		if !strings.Contains(tok, ".") {
+			continue
+		}
+		if svc := inf.resolveHost(from.Namespace, tok, false); svc != nil {
+			conf := ConfidenceMedium
+			if strings.Contains(tok, ".svc") {
+				conf = ConfidenceHigh
+			}
+			inf.addEdge(from, svc.Namespace, svc.Name, false, conf, source, fmt.Sprintf("%s (%s)", evidence, tok))
+		}
+	}
+
+	if host, _, err := net.SplitHostPort(strings.TrimSpace(text)); err == nil {
+		text = host
+	}
+	if bare := strings.TrimSpace(text); bare != "" && !strings.ContainsAny(bare, ". ") {
+		if svc := inf.services[from.Namespace+"/"+bare]; svc != nil {
+			inf.addEdge(from, svc.Namespace, svc.Name, false, ConfidenceLow, source, fmt.Sprintf("%s (%s)", evidence, bare))
+		}
+	}
+}
+
+// resolveHost maps a DNS name to a known service. Accepted forms are name.namespace,
+// name.namespace.svc and name.namespace.svc.<cluster-domain>; a bare name resolves within
+// namespace only when allowShort is set.
+func (inf *dependencyInferrer) resolveHost(namespace, host string, allowShort bool) *corev1.Service {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	parts := strings.Split(host, ".")
+	switch {
+	case len(parts) == 1:
+		if allowShort {
+			return inf.services[namespace+"/"+parts[0]]
+		}
+	case len(parts) == 2:
+		return inf.services[parts[1]+"/"+parts[0]]
+	case parts[2] == "svc":
+		return inf.services[parts[1]+"/"+parts[0]]
+	}
+	return nil
+}
+
+func (inf *dependencyInferrer) addEdge(from *corev1.Service, toNS, toName string, external bool, confidence, source, evidence string) {
+	if !external && toNS == from.Namespace && toName == from.Name {
+		return
+	}
+	key := fmt.Sprintf("%s/%s->%s/%s", from.Namespace, from.Name, toNS, toName)
+	dep, ok := inf.edges[key]
+	if !ok {
+		dep = &ServiceDependency{
+			FromService: from.Name, FromNS: from.Namespace,
+			ToService: toName, ToNS: toNS, External: external,
+			Confidence: confidence, Source: source,
+		}
+		inf.edges[key] = dep
+		inf.order = append(inf.order, key)
+	} else if confidenceRank(confidence) > confidenceRank(dep.Confidence) {
+		dep.Confidence, dep.Source = confidence, source
+		dep.Evidence = append([]string{evidence}, dep.Evidence...)
+		if len(dep.Evidence) > maxDependencyEvidence {
+			dep.Evidence = dep.Evidence[:maxDependencyEvidence]
+		}
+		return
+	}
+	for _, e := range dep.Evidence {
+		if e == evidence {
+			return
+		}
+	}
+	if len(dep.Evidence) < maxDependencyEvidence {
+		dep.Evidence = append(dep.Evidence, evidence)
+	}
+}
+
+func (inf *dependencyInferrer) configMap(ctx context.Context, namespace, name string) *corev1.ConfigMap {
+	key := namespace + "/" + name
+	if cm, ok := inf.configMaps[key]; ok {
+		return cm
+	}
+	ctx, cancel := context.WithTimeout(ctx, util.DefaultTimeout)
+	defer cancel()
+	cm, err := inf.client.Clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		cm = nil
+	}
+	inf.configMaps[key] = cm
+	return cm
+}
+
+// secretKeyNames returns the data key names of a Secret without reading its values. Only the
+// object metadata is fetched, and key names are taken from the managedFields entries that
+// record which data/stringData keys each field manager set. Secrets never written through a
+// field-managed request, or clusters without a metadata client, yield no key names.
+func (inf *dependencyInferrer) secretKeyNames(ctx context.Context, namespace, name string) []string {
+	key := namespace + "/" + name
+	if keys, ok := inf.secretKeys[key]; ok {
+		return keys
+	}
+	var keys []string
+	if inf.client.MetadataClient != nil {
+		ctx, cancel := context.WithTimeout(ctx, util.DefaultTimeout)
+		defer cancel()
+		meta, err := inf.client.MetadataClient.Resource(corev1.SchemeGroupVersion.WithResource("secrets")).
+			Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err == nil {
+			keys = managedDataKeys(meta.ManagedFields)
+		}
+	}
+	inf.secretKeys[key] = keys
+	return keys
+}
+
+// managedDataKeys collects the data and stringData key names recorded in managedFields.
+func managedDataKeys(entries []metav1.ManagedFieldsEntry) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, mf := range entries {
+		if mf.FieldsV1 == nil {
+			continue
+		}
+		var tree map[string]map[string]interface{}
+		if err := json.Unmarshal(mf.FieldsV1.Raw, &tree); err != nil {
+			continue
+		}
+		for _, field := range []string{"f:data", "f:stringData"} {
+			for k := range tree[field] {
+				name, ok := strings.CutPrefix(k, "f:")
+				if ok && !seen[name] {
+					seen[name] = true
+					keys = append(keys, name)
+				}
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// connectionConfidence returns the confidence of a connection-string match by URL scheme.
+func connectionConfidence(scheme string) string {
+	switch scheme {
+	case "http", "https", "grpc":
+		return ConfidenceLow
+	}
+	return ConfidenceHigh
+}
+
+// confidenceRank orders confidence levels so stronger evidence wins.
+func confidenceRank(c string) int {
+	switch c {
+	case ConfidenceHigh:
+		return 3
+	case ConfidenceMedium:
+		return 2
+	case ConfidenceLow:
+		return 1
+	}
+	return 0
+}
+
+// podOwnerKey identifies the controller that owns a pod, or the pod itself when it has none.
+func podOwnerKey(pod *corev1.Pod) string {
+	if ref := metav1.GetControllerOf(pod); ref != nil {
+		return pod.Namespace + "/" + ref.Kind + "/" + ref.Name
+	}
+	return pod.Namespace + "/Pod/" + pod.Name
+}
+
+// isExternalHost reports whether host looks like an out-of-cluster DNS name rather than
+// localhost, an IP, or an unresolved in-cluster name.
+func isExternalHost(host string) bool {
+	if net.ParseIP(host) != nil || !strings.Contains(host, ".") {
+		return false
+	}
+	return !strings.Contains(host, ".svc") && !strings.HasSuffix(host, ".local")
+}
@@ -0,0 +1,574 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultNodePortMin and DefaultNodePortMax bound the default --service-node-port-range.
+	DefaultNodePortMin = 30000
+	DefaultNodePortMax = 32767
+
+	// LoadBalancerSourceRangesAnnotation is the legacy annotation form of spec.loadBalancerSourceRanges.
+	LoadBalancerSourceRangesAnnotation = "service.beta.kubernetes.io/load-balancer-source-ranges"
+
+	// excludeFromLBLabel removes a node from cloud load balancer backend pools.
+	excludeFromLBLabel = "node.kubernetes.io/exclude-from-external-load-balancers"
+)
+
+// loadBalancerEventReasons are the event reasons the service controller in
+// cloud-controller-manager records while reconciling a LoadBalancer service.
+var loadBalancerEventReasons = map[string]bool{
+	"EnsuringLoadBalancer":       true,
+	"EnsuredLoadBalancer":        true,
+	"SyncLoadBalancerFailed":     true,
+	"UpdatedLoadBalancer":        true,
+	"UpdateLoadBalancerFailed":   true,
+	"DeletingLoadBalancer":       true,
+	"DeletedLoadBalancer":        true,
+	"DeleteLoadBalancerFailed":   true,
+	"LoadBalancerUpdateFailed":   true,
+	"CreatingLoadBalancerFailed": true,
+	"UnAvailableLoadBalancer":    true,
+	"ExternalProvisioning":       true,
+	"AllocationFailed":           true, // MetalLB
+	"IPAllocated":                true, // MetalLB
+}
+
+// ExposureIssue is a problem that keeps a LoadBalancer or NodePort service from being reachable.
+type ExposureIssue struct {
+	Severity string
+	Problem  string
+	Action   string
+}
+
+// LoadBalancerProvider describes the Service annotations a cloud provider's load balancer
+// integration understands.
+type LoadBalancerProvider struct {
+	Name             string
+	ProviderIDPrefix string   // node spec.providerID prefix, e.g. "azure://"
+	Prefixes         []string // annotation key prefixes owned by the provider
+	Annotations      map[string]AnnotationValidator
+	// Internal reports whether the annotations request a private (VNet/VPC) load balancer.
+	Internal func(annotations map[string]string) bool
+}
+
+// ProviderAnnotation is a Service annotation recognised as belonging to a provider.
+type ProviderAnnotation struct {
+	Provider string
+	Key      string
+	Value    string
+	Problem  string // validation problem, empty when valid or not validated
+}
+
+// NodePortUsage is a node port claimed by the service together with any conflicting claims.
+type NodePortUsage struct {
+	Port      int32
+	Protocol  corev1.Protocol
+	Source    string   // "port <name>" or "healthCheckNodePort"
+	Conflicts []string // other services or hostPort pods claiming the same port
+}
+
+// ServiceExposure is the external reachability analysis of a LoadBalancer or NodePort service.
+type ServiceExposure struct {
+	Service             *corev1.Service
+	ExternalAddresses   []string
+	Pending             bool // LoadBalancer without any status.loadBalancer.ingress
+	LoadBalancerClass   string
+	Provider            string // provider detected from node providerIDs
+	Internal            bool
+	LocalTrafficPolicy  bool
+	HealthCheckNodePort int32
+	EligibleNodes       int      // ready nodes not excluded from load balancers
+	EndpointNodes       []string // nodes with ready endpoints
+	NodePorts           []NodePortUsage
+	SourceRanges        []string
+	SourceRangesFrom    string // "spec", "annotation" or ""
+	Annotations         []ProviderAnnotation
+	Events              []corev1.Event // load balancer events, oldest first
+	Issues              []ExposureIssue
+}
+
+func awsInternal(a map[string]string) bool {
+	return a["service.beta.kubernetes.io/aws-load-balancer-internal"] == "true" ||
+		a["service.beta.kubernetes.io/aws-load-balancer-scheme"] == "internal"
+}
+
+// loadBalancerProviders lists the cloud providers whose Service annotations are validated.
+var loadBalancerProviders = []*LoadBalancerProvider{
+	{
+		Name:             "azure",
+		ProviderIDPrefix: "azure://",
+		Prefixes:         []string{"service.beta.kubernetes.io/azure-"},
+		Annotations: map[string]AnnotationValidator{
+			"service.beta.kubernetes.io/azure-load-balancer-internal":                       validateBool,
+			"service.beta.kubernetes.io/azure-load-balancer-internal-subnet":                nil,
+			"service.beta.kubernetes.io/azure-load-balancer-resource-group":                 nil,
+			"service.beta.kubernetes.io/azure-pip-name":                                     nil,
+			"service.beta.kubernetes.io/azure-pip-prefix-id":                                nil,
+			"service.beta.kubernetes.io/azure-dns-label-name":                               nil,
+			"service.beta.kubernetes.io/azure-shared-securityrule":                          validateBool,
+			"service.beta.kubernetes.io/azure-load-balancer-mode":                           nil,
+			"service.beta.kubernetes.io/azure-load-balancer-tcp-idle-timeout":               validateInt(4, 100),
+			"service.beta.kubernetes.io/azure-load-balancer-disable-tcp-reset":              validateBool,
+			"service.beta.kubernetes.io/azure-load-balancer-health-probe-protocol":          validateOneOf("tcp", "http", "https"),
+			"service.beta.kubernetes.io/azure-load-balancer-health-probe-request-path":      nil,
+			"service.beta.kubernetes.io/azure-load-balancer-health-probe-interval":          validateInt(5, 0),
+			"service.beta.kubernetes.io/azure-load-balancer-health-probe-num-of-probe":      validateInt(1, 0),
+			"service.beta.kubernetes.io/azure-load-balancer-ipv4":                           nil,
+			"service.beta.kubernetes.io/azure-load-balancer-ipv6":                           nil,
+			"service.beta.kubernetes.io/azure-allowed-service-tags":                         nil,
+			"service.beta.kubernetes.io/azure-allowed-ip-ranges":                            validateCIDRList,
+			"service.beta.kubernetes.io/azure-pls-create":                                   validateBool,
+			"service.beta.kubernetes.io/azure-disable-load-balancer-floating-ip":            validateBool,
+			"service.beta.kubernetes.io/azure-additional-public-ips":                        nil,
+			"service.beta.kubernetes.io/azure-load-balancer-enable-high-availability-ports": validateBool,
+		},
+		Internal: func(a map[string]string) bool {
+			return a["service.beta.kubernetes.io/azure-load-balancer-internal"] == "true"
+		},
+	},
+	{
+		Name:             "aws",
+		ProviderIDPrefix: "aws://",
+		Prefixes:         []string{"service.beta.kubernetes.io/aws-load-balancer-"},
+		Annotations: map[string]AnnotationValidator{
+			"service.beta.kubernetes.io/aws-load-balancer-type":                              validateOneOf("nlb", "external", "nlb-ip"),
+			"service.beta.kubernetes.io/aws-load-balancer-internal":                          validateBool,
+			"service.beta.kubernetes.io/aws-load-balancer-scheme":                            validateOneOf("internal", "internet-facing"),
+			"service.beta.kubernetes.io/aws-load-balancer-nlb-target-type":                   validateOneOf("ip", "instance"),
+			"service.beta.kubernetes.io/aws-load-balancer-ssl-cert":                          nil,
+			"service.beta.kubernetes.io/aws-load-balancer-ssl-ports":                         nil,
+			"service.beta.kubernetes.io/aws-load-balancer-backend-protocol":                  validateOneOf("tcp", "ssl", "http", "https"),
+			"service.beta.kubernetes.io/aws-load-balancer-subnets":                           nil,
+			"service.beta.kubernetes.io/aws-load-balancer-eip-allocations":                   nil,
+			"service.beta.kubernetes.io/aws-load-balancer-security-groups":                   nil,
+			"service.beta.kubernetes.io/aws-load-balancer-extra-security-groups":             nil,
+			"service.beta.kubernetes.io/aws-load-balancer-cross-zone-load-balancing-enabled": validateBool,
+			"service.beta.kubernetes.io/aws-load-balancer-proxy-protocol":                    nil,
+			"service.beta.kubernetes.io/aws-load-balancer-connection-idle-timeout":           validateInt(1, 4000),
+			"service.beta.kubernetes.io/aws-load-balancer-healthcheck-protocol":              validateOneOf("tcp", "http", "https"),
+			"service.beta.kubernetes.io/aws-load-balancer-healthcheck-path":                  nil,
+			"service.beta.kubernetes.io/aws-load-balancer-healthcheck-port":                  nil,
+			"service.beta.kubernetes.io/aws-load-balancer-additional-resource-tags":          nil,
+			"service.beta.kubernetes.io/aws-load-balancer-ip-address-type":                   validateOneOf("ipv4", "dualstack"),
+			"service.beta.kubernetes.io/aws-load-balancer-target-group-attributes":           nil,
+			"service.beta.kubernetes.io/aws-load-balancer-name":                              nil,
+		},
+		Internal: awsInternal,
+	},
+	{
+		Name:             "gcp",
+		ProviderIDPrefix: "gce://",
+		Prefixes:         []string{"cloud.google.com/", "networking.gke.io/"},
+		Annotations: map[string]AnnotationValidator{
+			"networking.gke.io/load-balancer-type":                         validateOneOf("Internal", "External"),
+			"cloud.google.com/load-balancer-type":                          validateOneOf("Internal", "External"),
+			"networking.gke.io/internal-load-balancer-allow-global-access": validateBool,
+			"networking.gke.io/internal-load-balancer-subnet":              nil,
+			"networking.gke.io/load-balancer-ip-addresses":                 nil,
+			"cloud.google.com/l4-rbs":                                      validateOneOf("enabled"),
+			"cloud.google.com/network-tier":                                validateOneOf("Standard", "Premium"),
+			"cloud.google.com/neg":                                         nil,
+			"cloud.google.com/backend-config":                              nil,
+			"cloud.google.com/app-protocols":                               nil,
+		},
+		Internal: func(a map[string]string) bool {
+			return strings.EqualFold(a["networking.gke.io/load-balancer-type"], "Internal") ||
+				strings.EqualFold(a["cloud.google.com/load-balancer-type"], "Internal")
+		},
+	},
+}
+
+// LoadBalancerProviderByName returns the provider with the given name, or nil.
+func LoadBalancerProviderByName(name string) *LoadBalancerProvider {
+	for _, p := range loadBalancerProviders {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// DetectCloudProvider returns the provider named by the nodes' spec.providerID, or "".
+func DetectCloudProvider(nodes []corev1.Node) string {
+	for _, n := range nodes {
+		for _, p := range loadBalancerProviders {
+			if strings.HasPrefix(n.Spec.ProviderID, p.ProviderIDPrefix) {
+				return p.Name
+			}
+		}
+	}
+	return ""
+}
+
+// ParseProviderAnnotations returns the service's cloud load balancer annotations, validated
+// against the owning provider. Keys under a provider prefix that the provider does not
+// define are reported as unknown.
+func ParseProviderAnnotations(svc *corev1.Service) []ProviderAnnotation {
+	var out []ProviderAnnotation
+	for key, value := range svc.Annotations {
+		for _, p := range loadBalancerProviders {
+			if !hasAnyPrefix(key, p.Prefixes) {
+				continue
+			}
+			a := ProviderAnnotation{Provider: p.Name, Key: key, Value: value}
+			validate, known := p.Annotations[key]
+			switch {
+			case !known && p.Name != "gcp":
+				// GKE reuses its prefixes for non-load-balancer features; only flag Azure/AWS typos.
+				a.Problem = "unknown annotation — check the spelling; the cloud provider ignores it"
+			case validate != nil:
+				a.Problem = validate(value)
+			}
+			out = append(out, a)
+			break
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// AnalyzeServiceExposure inspects why a LoadBalancer or NodePort service is or is not reachable
+// from outside the cluster: load balancer status and cloud-controller events, the
+// externalTrafficPolicy/health check node port, node port collisions, source ranges and
+// provider annotations.
+func (c *ClusterClient) AnalyzeServiceExposure(ctx context.Context, svc *corev1.Service) (*ServiceExposure, error) {
+	nodes, err := c.ListNodes(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing nodes: %w", err)
+	}
+	services, err := c.ListServices(ctx, "", metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing services: %w", err)
+	}
+	// Pods are only needed to find hostPorts colliding with this service's node ports, so skip the
+	// cluster-wide list when it has none and leave out finished pods, which hold no ports.
+	var pods []corev1.Pod
+	var podsErr error
+	if serviceHasNodePorts(svc) {
+		pods, podsErr = c.ListPods(ctx, "", metav1.ListOptions{FieldSelector: "status.phase!=Succeeded,status.phase!=Failed"})
+	}
+	// Events are best-effort: controller history is an extra.
+	events, _ := c.GetEventsForObject(ctx, svc.Namespace, svc.Name)
+
+	var health *EndpointHealth
+	if len(svc.Spec.Selector) > 0 {
+		var err error
+		health, err = c.GetServiceEndpointHealth(ctx, svc.Namespace, svc.Name)
+		if apierrors.IsNotFound(err) {
+			// No Endpoints object at all means no ready endpoints.
+			health = &EndpointHealth{ServiceName: svc.Name, ServiceNS: svc.Namespace}
+		}
+	}
+	e := BuildServiceExposure(svc, nodes, services, pods, events, health)
+	if podsErr != nil {
+		e.add("INFO", fmt.Sprintf("Could not list pods, so hostPort collisions with node ports were not checked: %v", podsErr), "")
+	}
+	return e, nil
+}
+
+// serviceHasNodePorts reports whether a service has any node port or health check node port allocated.
+func serviceHasNodePorts(svc *corev1.Service) bool {
+	if svc.Spec.HealthCheckNodePort != 0 {
+		return true
+	}
+	for _, p := range svc.Spec.Ports {
+		if p.NodePort != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildServiceExposure runs the exposure analysis over already-fetched objects.
+func BuildServiceExposure(svc *corev1.Service, nodes []corev1.Node, services []corev1.Service, pods []corev1.Pod, events []corev1.Event, health *EndpointHealth) *ServiceExposure {
+	e := &ServiceExposure{
+		Service:            svc,
+		Provider:           DetectCloudProvider(nodes),
+		LocalTrafficPolicy: svc.Spec.ExternalTrafficPolicy == corev1.ServiceExternalTrafficPolicyLocal,
+		Annotations:        ParseProviderAnnotations(svc),
+	}
+	if svc.Spec.LoadBalancerClass != nil {
+		e.LoadBalancerClass = *svc.Spec.LoadBalancerClass
+	}
+	if p := LoadBalancerProviderByName(e.Provider); p != nil {
+		e.Internal = p.Internal(svc.Annotations)
+	}
+	for _, ev := range events {
+		if loadBalancerEventReasons[ev.Reason] {
+			e.Events = append(e.Events, ev)
+		}
+	}
+	sort.SliceStable(e.Events, func(i, j int) bool { return eventTime(&e.Events[i]).Before(eventTime(&e.Events[j])) })
+
+	for _, n := range nodes {
+		if _, excluded := n.Labels[excludeFromLBLabel]; excluded {
+			continue
+		}
+		if nodeIsReady(&n) {
+			e.EligibleNodes++
+		}
+	}
+
+	if svc.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		e.checkLoadBalancerStatus()
+		e.checkSourceRanges()
+		e.checkProviderAnnotations()
+	}
+	e.checkTrafficPolicy(health)
+	e.checkNodePorts(services, pods)
+	return e
+}
+
+func (e *ServiceExposure) add(severity, problem, action string) {
+	e.Issues = append(e.Issues, ExposureIssue{Severity: severity, Problem: problem, Action: action})
+}
+
+func (e *ServiceExposure) checkLoadBalancerStatus() {
+	svc := e.Service
+	for _, ing := range svc.Status.LoadBalancer.Ingress {
+		addr := ing.IP
+		if addr == "" {
+			addr = ing.Hostname
+		}
+		if addr != "" {
+			e.ExternalAddresses = append(e.ExternalAddresses, addr)
+		}
+		for _, p := range ing.Ports {
+			if p.Error != nil && *p.Error != "" {
+				e.add("WARNING", fmt.Sprintf("Load balancer reports an error for port %d/%s: %s", p.Port, p.Protocol, *p.Error), "")
+			}
+		}
+	}
+	for _, cond := range svc.Status.Conditions {
+		if cond.Status == metav1.ConditionFalse {
+			e.add("WARNING", fmt.Sprintf("Service condition %s is False: %s %s", cond.Type, cond.Reason, cond.Message), "")
+		}
+	}
+
+	var lastFailure, lastSuccess *corev1.Event
+	for i := range e.Events {
+		ev := &e.Events[i]
+		switch {
+		case ev.Type == corev1.EventTypeWarning:
+			lastFailure = ev
+		case ev.Reason == "EnsuredLoadBalancer" || ev.Reason == "UpdatedLoadBalancer" || ev.Reason == "IPAllocated":
+			lastSuccess = ev
+		}
+	}
+	failing := lastFailure != nil && (lastSuccess == nil || eventTime(lastFailure).After(eventTime(lastSuccess)))
+	if failing {
+		e.add("CRITICAL", fmt.Sprintf("Cloud controller failed to reconcile the load balancer (%s): %s", lastFailure.Reason, lastFailure.Message),
+			"Fix the cause in the controller error (quota, subnet, permissions, annotation values); the service controller retries automatically")
+	}
+
+	if len(svc.Status.LoadBalancer.Ingress) > 0 {
+		return
+	}
+	e.Pending = true
+	switch {
+	case failing:
+		// The failure above already explains the missing address.
+	case e.LoadBalancerClass != "":
+		e.add("CRITICAL", fmt.Sprintf("No external IP: loadBalancerClass '%s' is set, so the cloud provider ignores this service and that controller has not assigned an address", e.LoadBalancerClass),
+			fmt.Sprintf("Check that the controller implementing '%s' is installed and running", e.LoadBalancerClass))
+	case len(e.Events) == 0 && e.Provider == "":
+		e.add("CRITICAL", "No external IP and no load balancer events — no cloud controller or bare-metal load balancer (e.g. MetalLB) appears to handle LoadBalancer services",
+			"Install a load balancer implementation (MetalLB, kube-vip, cloud-controller-manager) or use a NodePort/Ingress instead")
+	case len(e.Events) == 0:
+		e.add("WARNING", fmt.Sprintf("No external IP and no load balancer events from the %s cloud controller yet", e.Provider),
+			"Check cloud-controller-manager logs and its cloud credentials")
+	default:
+		e.add("WARNING", "Load balancer is still being provisioned (no external IP yet)", "")
+	}
+}
+
+func (e *ServiceExposure) checkSourceRanges() {
+	svc := e.Service
+	annotation := svc.Annotations[LoadBalancerSourceRangesAnnotation]
+	switch {
+	case len(svc.Spec.LoadBalancerSourceRanges) > 0:
+		e.SourceRanges = svc.Spec.LoadBalancerSourceRanges
+		e.SourceRangesFrom = "spec"
+		if annotation != "" {
+			e.add("WARNING", "Both spec.loadBalancerSourceRanges and the "+LoadBalancerSourceRangesAnnotation+" annotation are set — the annotation is ignored",
+				"Remove the annotation to avoid confusion")
+		}
+	case annotation != "":
+		for _, r := range strings.Split(annotation, ",") {
+			if r = strings.TrimSpace(r); r != "" {
+				e.SourceRanges = append(e.SourceRanges, r)
+			}
+		}
+		e.SourceRangesFrom = "annotation"
+	}
+
+	open := len(e.SourceRanges) == 0
+	for _, r := range e.SourceRanges {
+		_, ipNet, err := net.ParseCIDR(r)
+		if err != nil {
+			e.add("CRITICAL", fmt.Sprintf("Source range '%s' is not a valid CIDR — the cloud controller will reject the load balancer", r),
+				"Use CIDR notation (e.g. 203.0.113.0/24) in loadBalancerSourceRanges")
+			continue
+		}
+		if ones, _ := ipNet.Mask.Size(); ones == 0 {
+			open = true
+		}
+	}
+	if open && !e.Internal && len(svc.Status.LoadBalancer.Ingress) > 0 {
+		e.add("INFO", "Public load balancer accepts traffic from any source address", "Set spec.loadBalancerSourceRanges to restrict access if the service is not meant to be public")
+	}
+}
+
+func (e *ServiceExposure) checkProviderAnnotations() {
+	for _, a := range e.Annotations {
+		if e.Provider != "" && a.Provider != e.Provider {
+			e.add("WARNING", fmt.Sprintf("Annotation %s is for %s but nodes run on %s — it is ignored", a.Key, a.Provider, e.Provider),
+				"Remove annotations copied from another cloud")
+			continue
+		}
+		if a.Problem != "" {
+			e.add("WARNING", fmt.Sprintf("Annotation %s=%q: %s", a.Key, a.Value, a.Problem), "Fix the annotation value")
+		}
+	}
+	if t := e.Service.Annotations["service.beta.kubernetes.io/aws-load-balancer-type"]; t == "external" && e.LoadBalancerClass == "" {
+		e.add("INFO", "aws-load-balancer-type=external is only honoured by the AWS Load Balancer Controller", "Ensure the AWS Load Balancer Controller is installed, or set loadBalancerClass: service.k8s.aws/nlb")
+	}
+}
+
+func (e *ServiceExposure) checkTrafficPolicy(health *EndpointHealth) {
+	svc := e.Service
+	if !e.LocalTrafficPolicy {
+		return
+	}
+	e.HealthCheckNodePort = svc.Spec.HealthCheckNodePort
+	if health == nil {
+		return
+	}
+	seen := make(map[string]bool)
+	for _, a := range health.ReadyAddresses {
+		if a.NodeName != "" && !seen[a.NodeName] {
+			seen[a.NodeName] = true
+			e.EndpointNodes = append(e.EndpointNodes, a.NodeName)
+		}
+	}
+	sort.Strings(e.EndpointNodes)
+
+	switch {
+	case len(e.EndpointNodes) == 0:
+		e.add("CRITICAL", "externalTrafficPolicy=Local but no node has a ready endpoint — every node fails the health check node port and external traffic is dropped",
+			"Fix the backing pods, or switch externalTrafficPolicy to Cluster")
+	case e.EligibleNodes > len(e.EndpointNodes):
+		e.add("INFO", fmt.Sprintf("externalTrafficPolicy=Local: only %d of %d eligible nodes host ready endpoints and pass the load balancer health check (port %d)",
+			len(e.EndpointNodes), e.EligibleNodes, e.HealthCheckNodePort),
+			"Spread replicas across nodes (topologySpreadConstraints) so traffic is balanced and survives a node loss")
+	}
+}
+
+func (e *ServiceExposure) checkNodePorts(services []corev1.Service, pods []corev1.Pod) {
+	svc := e.Service
+	for _, p := range svc.Spec.Ports {
+		if p.NodePort != 0 {
+			e.NodePorts = append(e.NodePorts, NodePortUsage{Port: p.NodePort, Protocol: p.Protocol, Source: "port " + servicePortLabel(p)})
+		}
+	}
+	if e.HealthCheckNodePort != 0 {
+		e.NodePorts = append(e.NodePorts, NodePortUsage{Port: e.HealthCheckNodePort, Protocol: corev1.ProtocolTCP, Source: "healthCheckNodePort"})
+	}
+	if len(e.NodePorts) == 0 {
+		if svc.Spec.Type == corev1.ServiceTypeNodePort || (svc.Spec.Type == corev1.ServiceTypeLoadBalancer && (svc.Spec.AllocateLoadBalancerNodePorts == nil || *svc.Spec.AllocateLoadBalancerNodePorts)) {
+			e.add("WARNING", "No node ports have been allocated to this service", "")
+		}
+		return
+	}
+
+	for i := range e.NodePorts {
+		u := &e.NodePorts[i]
+		if u.Port < DefaultNodePortMin || u.Port > DefaultNodePortMax {
+			e.add("INFO", fmt.Sprintf("Node port %d is outside the default range %d-%d — make sure node firewalls/security groups allow it",
+				u.Port, DefaultNodePortMin, DefaultNodePortMax), "")
+		}
+		for _, other := range services {
+			if other.Namespace == svc.Namespace && other.Name == svc.Name {
+				continue
+			}
+			for _, op := range other.Spec.Ports {
+				if op.NodePort == u.Port {
+					u.Conflicts = append(u.Conflicts, fmt.Sprintf("service %s/%s (%s)", other.Namespace, other.Name, servicePortLabel(op)))
+				}
+			}
+			if other.Spec.HealthCheckNodePort == u.Port {
+				u.Conflicts = append(u.Conflicts, fmt.Sprintf("service %s/%s (healthCheckNodePort)", other.Namespace, other.Name))
+			}
+		}
+		for _, pod := range pods {
+			if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+				continue
+			}
+			for _, c := range pod.Spec.Containers {
+				for _, cp := range c.Ports {
+					hostPort := cp.HostPort
+					if hostPort == 0 && pod.Spec.HostNetwork {
+						hostPort = cp.ContainerPort
+					}
+					if hostPort == u.Port && protocolOrTCP(cp.Protocol) == protocolOrTCP(u.Protocol) {
+						u.Conflicts = append(u.Conflicts, fmt.Sprintf("pod %s/%s (hostPort on %s)", pod.Namespace, pod.Name, valueOr(pod.Spec.NodeName, "unscheduled")))
+					}
+				}
+			}
+		}
+		if len(u.Conflicts) > 0 {
+			e.add("CRITICAL", fmt.Sprintf("Node port %d (%s) is also claimed by %s — traffic to that port may reach the wrong backend",
+				u.Port, u.Source, strings.Join(u.Conflicts, ", ")),
+				"Change one of the colliding ports (remove the fixed nodePort to let the apiserver allocate one)")
+		}
+	}
+}
+
+func servicePortLabel(p corev1.ServicePort) string {
+	if p.Name != "" {
+		return fmt.Sprintf("%s %d/%s", p.Name, p.Port, protocolOrTCP(p.Protocol))
+	}
+	return fmt.Sprintf("%d/%s", p.Port, protocolOrTCP(p.Protocol))
+}
+
+func protocolOrTCP(p corev1.Protocol) corev1.Protocol {
+	if p == "" {
+		return corev1.ProtocolTCP
+	}
+	return p
+}
+
+func valueOr(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+func nodeIsReady(n *corev1.Node) bool {
+	for _, c := range n.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func exposureNode(name string, ready bool) corev1.Node {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       corev1.NodeSpec{ProviderID: "azure:///subscriptions/x/" + name},
+		Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}}},
+	}
+}
+
+func lbService() *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeLoadBalancer,
+			Selector: map[string]string{"app": "web"},
+			Ports:    []corev1.ServicePort{{Name: "http", Port: 80, Protocol: corev1.ProtocolTCP, NodePort: 31080}},
+		},
+	}
+}
+
+func hasIssue(issues []ExposureIssue, severity, substr string) bool {
+	for _, i := range issues {
+		if i.Severity == severity && strings.Contains(i.Problem, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestBuildServiceExposurePendingWithFailure(t *testing.T) {
+	svc := lbService()
+	svc.Annotations = map[string]string{
+		"service.beta.kubernetes.io/azure-load-balancer-internal": "yes",
+		"service.beta.kubernetes.io/azure-load-balancer-interal":  "true",
+		"service.beta.kubernetes.io/aws-load-balancer-type":       "nlb",
+	}
+	svc.Spec.LoadBalancerSourceRanges = []string{"10.0.0.0/8", "not-a-cidr"}
+	now := time.Now()
+	events := []corev1.Event{
+		{Reason: "SyncLoadBalancerFailed", Type: corev1.EventTypeWarning, Message: "quota exceeded", LastTimestamp: metav1.NewTime(now)},
+		{Reason: "EnsuringLoadBalancer", Type: corev1.EventTypeNormal, LastTimestamp: metav1.NewTime(now.Add(-time.Minute))},
+		{Reason: "Scheduled", Type: corev1.EventTypeNormal},
+	}
+	other := corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "ops"},
+		Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeNodePort, Ports: []corev1.ServicePort{{Port: 8080, NodePort: 31080}}},
+	}
+
+	e := BuildServiceExposure(svc, []corev1.Node{exposureNode("n1", true)}, []corev1.Service{*svc, other}, nil, events, nil)
+
+	if !e.Pending || e.Provider != "azure" {
+		t.Errorf("expected pending azure load balancer, got pending=%v provider=%q", e.Pending, e.Provider)
+	}
+	if len(e.Events) != 2 || e.Events[0].Reason != "EnsuringLoadBalancer" {
+		t.Errorf("expected load balancer events oldest first, got %+v", e.Events)
+	}
+	for _, want := range []struct{ severity, substr string }{
+		{"CRITICAL", "quota exceeded"},
+		{"CRITICAL", "not-a-cidr"},
+		{"CRITICAL", "ops/legacy"},
+		{"WARNING", "azure-load-balancer-internal=\"yes\""},
+		{"WARNING", "azure-load-balancer-interal=\"true\": unknown annotation"},
+		{"WARNING", "aws-load-balancer-type is for aws"},
+	} {
+		if !hasIssue(e.Issues, want.severity, want.substr) {
+			t.Errorf("missing %s issue containing %q in %+v", want.severity, want.substr, e.Issues)
+		}
+	}
+}
+
+func TestBuildServiceExposureLocalTrafficPolicy(t *testing.T) {
+	svc := lbService()
+	svc.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyLocal
+	svc.Spec.HealthCheckNodePort = 32000
+	svc.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "20.1.2.3"}}
+	nodes := []corev1.Node{exposureNode("n1", true), exposureNode("n2", true), exposureNode("n3", false)}
+
+	e := BuildServiceExposure(svc, nodes, nil, nil, nil, &EndpointHealth{})
+	if !hasIssue(e.Issues, "CRITICAL", "no node has a ready endpoint") {
+		t.Errorf("expected critical Local policy issue, got %+v", e.Issues)
+	}
+	if len(e.NodePorts) != 2 || e.NodePorts[1].Source != "healthCheckNodePort" {
+		t.Errorf("expected service and health check node ports, got %+v", e.NodePorts)
+	}
+
+	health := &EndpointHealth{ReadyAddresses: []EndpointAddress{{IP: "10.0.0.1", NodeName: "n1"}, {IP: "10.0.0.2", NodeName: "n1"}}}
+	e = BuildServiceExposure(svc, nodes, nil, nil, nil, health)
+	if e.EligibleNodes != 2 || len(e.EndpointNodes) != 1 || !hasIssue(e.Issues, "INFO", "only 1 of 2") {
+		t.Errorf("expected 1 of 2 eligible nodes to pass, got %+v", e)
+	}
+	if !hasIssue(e.Issues, "INFO", "any source address") {
+		t.Errorf("expected open source range note, got %+v", e.Issues)
+	}
+}
+
+func TestAnalyzeServiceExposureHostPortCollision(t *testing.T) {
+	svc := lbService()
+	svc.Spec.Type = corev1.ServiceTypeNodePort
+	client := NewClusterClientForTesting(fake.NewSimpleClientset(
+		svc,
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "monitoring"},
+			Spec: corev1.PodSpec{HostNetwork: true, NodeName: "n1", Containers: []corev1.Container{{
+				Name: "agent", Ports: []corev1.ContainerPort{{ContainerPort: 31080}},
+			}}},
+		},
+	), nil)
+
+	e, err := client.AnalyzeServiceExposure(context.Background(), svc)
+	if err != nil {
+		t.Fatalf("AnalyzeServiceExposure() error = %v", err)
+	}
+	if len(e.NodePorts) != 1 || len(e.NodePorts[0].Conflicts) != 1 || !strings.Contains(e.NodePorts[0].Conflicts[0], "monitoring/agent") {
+		t.Errorf("expected hostNetwork pod collision, got %+v", e.NodePorts)
+	}
+}
+
+func TestAnalyzeServiceExposurePodListFailure(t *testing.T) {
+	svc := lbService()
+	svc.Spec.Type = corev1.ServiceTypeNodePort
+	clientset := fake.NewSimpleClientset(svc)
+	clientset.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", errors.New("RBAC"))
+	})
+	client := NewClusterClientForTesting(clientset, nil)
+
+	e, err := client.AnalyzeServiceExposure(context.Background(), svc)
+	if err != nil {
+		t.Fatalf("AnalyzeServiceExposure() error = %v", err)
+	}
+	if !hasIssue(e.Issues, "INFO", "hostPort collisions with node ports were not checked") {
+		t.Errorf("expected the skipped hostPort check to be reported, got %+v", e.Issues)
+	}
+
+	// Without node ports the pod list is not needed at all.
+	svc.Spec.Type = corev1.ServiceTypeLoadBalancer
+	svc.Spec.Ports[0].NodePort = 0
+	svc.Spec.AllocateLoadBalancerNodePorts = new(bool)
+	e, err = client.AnalyzeServiceExposure(context.Background(), svc)
+	if err != nil {
+		t.Fatalf("AnalyzeServiceExposure() error = %v", err)
+	}
+	if hasIssue(e.Issues, "INFO", "hostPort collisions") {
+		t.Errorf("pods should not be listed for a service without node ports, got %+v", e.Issues)
+	}
+}
@@ -0,0 +1,643 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Service meshes recognised by the mesh analysis.
+const (
+	MeshIstio   = "istio"
+	MeshLinkerd = "linkerd"
+)
+
+// Sidecar proxy container names.
+const (
+	IstioProxyContainer   = "istio-proxy"
+	LinkerdProxyContainer = "linkerd-proxy"
+)
+
+// Istio CRD names and the root namespace whose policies apply mesh-wide.
+const (
+	PeerAuthenticationCRD  = "peerauthentications.security.istio.io"
+	AuthorizationPolicyCRD = "authorizationpolicies.security.istio.io"
+	VirtualServiceCRD      = "virtualservices.networking.istio.io"
+	DestinationRuleCRD     = "destinationrules.networking.istio.io"
+
+	IstioRootNamespace = "istio-system"
+)
+
+// Injection states reported for a namespace or pod.
+const (
+	InjectionEnabled  = "enabled"
+	InjectionDisabled = "disabled"
+	InjectionAmbient  = "ambient" // Istio ambient mode: no sidecars, ztunnel handles mTLS
+)
+
+const (
+	istioInjectionLabel      = "istio-injection"
+	istioRevisionLabel       = "istio.io/rev"
+	istioDataplaneModeLabel  = "istio.io/dataplane-mode"
+	istioSidecarInjectKey    = "sidecar.istio.io/inject"
+	linkerdInjectAnnotation  = "linkerd.io/inject"
+	linkerdInboundPolicyAnno = "config.linkerd.io/default-inbound-policy"
+)
+
+// MeshIssue is a service mesh problem on the path to a service.
+type MeshIssue struct {
+	Severity string
+	Problem  string
+	Action   string
+}
+
+// MeshSidecar is the mesh proxy container of a pod.
+type MeshSidecar struct {
+	Mesh      string
+	Container string
+	Native    bool // injected as a restartPolicy: Always init container
+	Ready     bool
+	Restarts  int32
+}
+
+// PodMeshStatus is the mesh state of one backing pod.
+type PodMeshStatus struct {
+	Pod       string
+	Phase     corev1.PodPhase
+	Sidecar   *MeshSidecar
+	Injection string // effective injection state for the pod ("" when the namespace is not meshed)
+}
+
+// PeerAuthenticationInfo is a summary of an Istio PeerAuthentication.
+type PeerAuthenticationInfo struct {
+	Namespace string
+	Name      string
+	Selector  map[string]string
+	Mode      string            // STRICT, PERMISSIVE, DISABLE or UNSET
+	PortModes map[string]string // portLevelMtls
+}
+
+// MeshRouteDestination is a VirtualService route destination.
+type MeshRouteDestination struct {
+	Host   string
+	Subset string
+	Port   int64
+	Weight int64
+}
+
+// VirtualServiceInfo is a summary of an Istio VirtualService.
+type VirtualServiceInfo struct {
+	Namespace    string
+	Name         string
+	Hosts        []string
+	Gateways     []string
+	Destinations []MeshRouteDestination
+}
+
+// DestinationRuleInfo is a summary of an Istio DestinationRule.
+type DestinationRuleInfo struct {
+	Namespace string
+	Name      string
+	Host      string
+	TLSMode   string                       // trafficPolicy.tls.mode
+	Subsets   map[string]map[string]string // subset name -> labels
+}
+
+// AuthorizationPolicyInfo is a summary of an Istio AuthorizationPolicy.
+type AuthorizationPolicyInfo struct {
+	Namespace string
+	Name      string
+	Selector  map[string]string
+	Action    string // ALLOW, DENY, AUDIT or CUSTOM
+	Rules     int
+}
+
+// ServiceMeshResources holds the Istio configuration objects in the cluster.
+type ServiceMeshResources struct {
+	Installed             map[string]bool // CRD name -> installed
+	PeerAuthentications   []PeerAuthenticationInfo
+	VirtualServices       []VirtualServiceInfo
+	DestinationRules      []DestinationRuleInfo
+	AuthorizationPolicies []AuthorizationPolicyInfo
+	Errors                []string
+}
+
+// ServiceMeshAnalysis is the mesh view of a service: sidecars, mTLS, routing and authorization.
+type ServiceMeshAnalysis struct {
+	Mesh                  string // MeshIstio, MeshLinkerd or "" when the service is not meshed
+	NamespaceInjection    string
+	Pods                  []PodMeshStatus
+	MTLSMode              string
+	MTLSSource            string
+	VirtualServices       []VirtualServiceInfo
+	DestinationRules      []DestinationRuleInfo
+	AuthorizationPolicies []AuthorizationPolicyInfo
+	Issues                []MeshIssue
+}
+
+// Meshed reports whether the service takes part in a mesh.
+func (m *ServiceMeshAnalysis) Meshed() bool {
+	return m != nil && m.Mesh != ""
+}
+
+// SidecarContainer returns the proxy container name for the analysed mesh.
+func (m *ServiceMeshAnalysis) SidecarContainer() string {
+	switch m.Mesh {
+	case MeshIstio:
+		if m.NamespaceInjection == InjectionAmbient {
+			return "ztunnel"
+		}
+		return IstioProxyContainer
+	case MeshLinkerd:
+		return LinkerdProxyContainer
+	}
+	return ""
+}
+
+// DeniedByDefault reports whether an authorization policy rejects requests that match none of its rules.
+func (m *ServiceMeshAnalysis) DeniedByDefault() bool {
+	for _, p := range m.AuthorizationPolicies {
+		if p.Action == "ALLOW" {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *ServiceMeshAnalysis) add(severity, problem, action string) {
+	m.Issues = append(m.Issues, MeshIssue{Severity: severity, Problem: problem, Action: action})
+}
+
+// PodSidecar returns the mesh proxy of a pod, or nil when the pod has none.
+func PodSidecar(pod *corev1.Pod) *MeshSidecar {
+	meshOf := func(name string) string {
+		switch name {
+		case IstioProxyContainer:
+			return MeshIstio
+		case LinkerdProxyContainer:
+			return MeshLinkerd
+		}
+		return ""
+	}
+	var sc *MeshSidecar
+	for _, c := range pod.Spec.Containers {
+		if mesh := meshOf(c.Name); mesh != "" {
+			sc = &MeshSidecar{Mesh: mesh, Container: c.Name}
+		}
+	}
+	for _, c := range pod.Spec.InitContainers {
+		if mesh := meshOf(c.Name); mesh != "" && c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			sc = &MeshSidecar{Mesh: mesh, Container: c.Name, Native: true}
+		}
+	}
+	if sc == nil {
+		return nil
+	}
+	statuses := pod.Status.ContainerStatuses
+	if sc.Native {
+		statuses = pod.Status.InitContainerStatuses
+	}
+	for _, cs := range statuses {
+		if cs.Name == sc.Container {
+			sc.Ready = cs.Ready
+			sc.Restarts = cs.RestartCount
+		}
+	}
+	return sc
+}
+
+// NamespaceMeshInjection returns the mesh and injection state a namespace's labels and
+// annotations request, or empty strings when the namespace does not opt in or out.
+func NamespaceMeshInjection(ns *corev1.Namespace) (mesh, injection string) {
+	if ns == nil {
+		return "", ""
+	}
+	if ns.Labels[istioDataplaneModeLabel] == "ambient" {
+		return MeshIstio, InjectionAmbient
+	}
+	switch ns.Labels[istioInjectionLabel] {
+	case "enabled":
+		return MeshIstio, InjectionEnabled
+	case "disabled":
+		return MeshIstio, InjectionDisabled
+	}
+	if ns.Labels[istioRevisionLabel] != "" {
+		return MeshIstio, InjectionEnabled
+	}
+	switch ns.Annotations[linkerdInjectAnnotation] {
+	case "enabled", "ingress":
+		return MeshLinkerd, InjectionEnabled
+	case "disabled":
+		return MeshLinkerd, InjectionDisabled
+	}
+	return "", ""
+}
+
+// podInjection applies pod-level injection overrides on top of the namespace setting.
+func podInjection(pod *corev1.Pod, mesh, nsInjection string) string {
+	switch mesh {
+	case MeshIstio:
+		if nsInjection == InjectionAmbient {
+			return InjectionAmbient
+		}
+		v := pod.Labels[istioSidecarInjectKey]
+		if v == "" {
+			v = pod.Annotations[istioSidecarInjectKey]
+		}
+		switch v {
+		case "false":
+			return InjectionDisabled
+		case "true":
+			return InjectionEnabled
+		}
+	case MeshLinkerd:
+		switch pod.Annotations[linkerdInjectAnnotation] {
+		case "disabled":
+			return InjectionDisabled
+		case "enabled", "ingress":
+			return InjectionEnabled
+		}
+	}
+	return nsInjection
+}
+
+// GetServiceMeshResources lists Istio PeerAuthentications, VirtualServices, DestinationRules and
+// AuthorizationPolicies cluster-wide. CRDs that are not installed are skipped.
+func (c *ClusterClient) GetServiceMeshResources(ctx context.Context) (*ServiceMeshResources, error) {
+	crds, err := c.ListCRDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing CRDs: %w", err)
+	}
+
+	res := &ServiceMeshResources{Installed: make(map[string]bool)}
+	list := func(crdName string) []unstructured.Unstructured {
+		crd := FindCRD(crds, crdName)
+		res.Installed[crdName] = crd != nil
+		if crd == nil {
+			return nil
+		}
+		items, err := c.ListCustomResources(ctx, CRDGroupVersionResource(crd), "")
+		if err != nil {
+			res.Errors = append(res.Errors, fmt.Sprintf("%s: %v", crdName, err))
+		}
+		return items
+	}
+
+	for _, item := range list(PeerAuthenticationCRD) {
+		res.PeerAuthentications = append(res.PeerAuthentications, parsePeerAuthentication(&item))
+	}
+	for _, item := range list(VirtualServiceCRD) {
+		res.VirtualServices = append(res.VirtualServices, parseVirtualService(&item))
+	}
+	for _, item := range list(DestinationRuleCRD) {
+		res.DestinationRules = append(res.DestinationRules, parseDestinationRule(&item))
+	}
+	for _, item := range list(AuthorizationPolicyCRD) {
+		res.AuthorizationPolicies = append(res.AuthorizationPolicies, parseAuthorizationPolicy(&item))
+	}
+	return res, nil
+}
+
+func parsePeerAuthentication(item *unstructured.Unstructured) PeerAuthenticationInfo {
+	pa := PeerAuthenticationInfo{Namespace: item.GetNamespace(), Name: item.GetName(), Mode: "UNSET"}
+	pa.Selector, _, _ = unstructured.NestedStringMap(item.Object, "spec", "selector", "matchLabels")
+	if mode, _, _ := unstructured.NestedString(item.Object, "spec", "mtls", "mode"); mode != "" {
+		pa.Mode = mode
+	}
+	ports, _, _ := unstructured.NestedMap(item.Object, "spec", "portLevelMtls")
+	for port, raw := range ports {
+		if m, ok := raw.(map[string]interface{}); ok {
+			if mode, _, _ := unstructured.NestedString(m, "mode"); mode != "" {
+				if pa.PortModes == nil {
+					pa.PortModes = make(map[string]string)
+				}
+				pa.PortModes[port] = mode
+			}
+		}
+	}
+	return pa
+}
+
+func parseVirtualService(item *unstructured.Unstructured) VirtualServiceInfo {
+	vs := VirtualServiceInfo{Namespace: item.GetNamespace(), Name: item.GetName()}
+	vs.Hosts, _, _ = unstructured.NestedStringSlice(item.Object, "spec", "hosts")
+	vs.Gateways, _, _ = unstructured.NestedStringSlice(item.Object, "spec", "gateways")
+	for _, protocol := range []string{"http", "tcp", "tls"} {
+		routes, _, _ := unstructured.NestedSlice(item.Object, "spec", protocol)
+		for _, r := range routes {
+			rm, ok := r.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			dests, _, _ := unstructured.NestedSlice(rm, "route")
+			for _, d := range dests {
+				dm, ok := d.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				var dest MeshRouteDestination
+				dest.Host, _, _ = unstructured.NestedString(dm, "destination", "host")
+				dest.Subset, _, _ = unstructured.NestedString(dm, "destination", "subset")
+				dest.Port, _, _ = unstructured.NestedInt64(dm, "destination", "port", "number")
+				dest.Weight, _, _ = unstructured.NestedInt64(dm, "weight")
+				vs.Destinations = append(vs.Destinations, dest)
+			}
+		}
+	}
+	return vs
+}
+
+func parseDestinationRule(item *unstructured.Unstructured) DestinationRuleInfo {
+	dr := DestinationRuleInfo{Namespace: item.GetNamespace(), Name: item.GetName(), Subsets: make(map[string]map[string]string)}
+	dr.Host, _, _ = unstructured.NestedString(item.Object, "spec", "host")
+	dr.TLSMode, _, _ = unstructured.NestedString(item.Object, "spec", "trafficPolicy", "tls", "mode")
+	subsets, _, _ := unstructured.NestedSlice(item.Object, "spec", "subsets")
+	for _, s := range subsets {
+		sm, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(sm, "name")
+		lbls, _, _ := unstructured.NestedStringMap(sm, "labels")
+		dr.Subsets[name] = lbls
+	}
+	return dr
+}
+
+func parseAuthorizationPolicy(item *unstructured.Unstructured) AuthorizationPolicyInfo {
+	ap := AuthorizationPolicyInfo{Namespace: item.GetNamespace(), Name: item.GetName(), Action: "ALLOW"}
+	ap.Selector, _, _ = unstructured.NestedStringMap(item.Object, "spec", "selector", "matchLabels")
+	if action, _, _ := unstructured.NestedString(item.Object, "spec", "action"); action != "" {
+		ap.Action = action
+	}
+	rules, _, _ := unstructured.NestedSlice(item.Object, "spec", "rules")
+	ap.Rules = len(rules)
+	return ap
+}
+
+// MeshHostFQDN expands a mesh host name the way Istio does: short names are relative to
+// the namespace of the object that references them.
+func MeshHostFQDN(host, namespace string) string {
+	host = strings.TrimSuffix(host, ".")
+	switch strings.Count(host, ".") {
+	case 0:
+		return fmt.Sprintf("%s.%s.svc.%s", host, namespace, DefaultClusterDomain)
+	case 1:
+		if !strings.HasPrefix(host, "*") {
+			return host + ".svc." + DefaultClusterDomain
+		}
+	case 2:
+		if strings.HasSuffix(host, ".svc") {
+			return host + "." + DefaultClusterDomain
+		}
+	}
+	return host
+}
+
+func meshHostMatchesService(host, namespace string, svc *corev1.Service) bool {
+	fqdn := fmt.Sprintf("%s.%s.svc.%s", svc.Name, svc.Namespace, DefaultClusterDomain)
+	return HostnameMatches(MeshHostFQDN(host, namespace), fqdn)
+}
+
+func selectorMatches(selector, podLabels map[string]string) bool {
+	return labels.SelectorFromSet(selector).Matches(labels.Set(podLabels))
+}
+
+// AnalyzeServiceMesh inspects the mesh configuration in front of a service's pods: sidecar
+// injection and readiness, the effective PeerAuthentication mTLS mode, VirtualService and
+// DestinationRule subsets, and AuthorizationPolicies that can deny requests.
+func (c *ClusterClient) AnalyzeServiceMesh(ctx context.Context, svc *corev1.Service, pods []corev1.Pod) *ServiceMeshAnalysis {
+	ns, _ := c.GetNamespace(ctx, svc.Namespace)
+	res, err := c.GetServiceMeshResources(ctx)
+	if err != nil {
+		// Istio CRDs cannot be read; sidecar and injection checks still apply.
+		res = &ServiceMeshResources{Errors: []string{err.Error()}}
+	}
+	return BuildServiceMeshAnalysis(svc, ns, pods, res)
+}
+
+// BuildServiceMeshAnalysis runs the mesh analysis over already-fetched objects.
+func BuildServiceMeshAnalysis(svc *corev1.Service, ns *corev1.Namespace, pods []corev1.Pod, res *ServiceMeshResources) *ServiceMeshAnalysis {
+	m := &ServiceMeshAnalysis{}
+	m.Mesh, m.NamespaceInjection = NamespaceMeshInjection(ns)
+	if m.NamespaceInjection == InjectionDisabled {
+		// Opting out only matters if pods still carry a sidecar.
+		m.Mesh = ""
+	}
+	for i := range pods {
+		if sc := PodSidecar(&pods[i]); sc != nil {
+			m.Mesh = sc.Mesh
+			break
+		}
+	}
+	m.VirtualServices, m.DestinationRules = meshRoutingFor(svc, res)
+	if m.Mesh == "" && (len(m.VirtualServices) > 0 || len(m.DestinationRules) > 0) {
+		m.Mesh = MeshIstio
+	}
+	if m.Mesh == "" {
+		return m
+	}
+	m.checkSidecars(pods)
+	podLabels := svc.Spec.Selector
+	if len(pods) > 0 {
+		podLabels = pods[0].Labels
+	}
+	switch m.Mesh {
+	case MeshIstio:
+		m.checkIstioMTLS(svc, podLabels, res)
+		m.checkSubsets(svc, pods)
+		m.checkAuthorizationPolicies(svc, podLabels, res)
+	case MeshLinkerd:
+		m.MTLSMode, m.MTLSSource = "automatic", "linkerd, meshed-to-meshed traffic is always mTLS"
+		m.checkLinkerdPolicy(ns, pods)
+	}
+	return m
+}
+
+func (m *ServiceMeshAnalysis) checkSidecars(pods []corev1.Pod) {
+	meshed, expected := 0, 0
+	for i := range pods {
+		pod := &pods[i]
+		st := PodMeshStatus{Pod: pod.Name, Phase: pod.Status.Phase, Sidecar: PodSidecar(pod), Injection: podInjection(pod, m.Mesh, m.NamespaceInjection)}
+		m.Pods = append(m.Pods, st)
+		if st.Sidecar != nil {
+			meshed++
+			if !st.Sidecar.Ready && pod.Status.Phase == corev1.PodRunning {
+				m.add("CRITICAL", fmt.Sprintf("Sidecar %s in pod '%s' is not ready — the pod receives no mesh traffic", st.Sidecar.Container, pod.Name),
+					fmt.Sprintf("Check the proxy logs with get_pod_logs pod_name=%s container=%s and its connection to the control plane", pod.Name, st.Sidecar.Container))
+			}
+			continue
+		}
+		if st.Injection == InjectionEnabled {
+			expected++
+			m.add("WARNING", fmt.Sprintf("Pod '%s' has no %s sidecar although injection is enabled — it was created before injection was enabled or the injector webhook failed",
+				pod.Name, m.SidecarContainer()),
+				"Restart the workload (kubectl rollout restart) and check the sidecar injector webhook")
+		}
+	}
+	if meshed > 0 && meshed+expected < len(pods) && m.NamespaceInjection != InjectionAmbient {
+		m.add("WARNING", fmt.Sprintf("Only %d of %d backing pods run a sidecar — meshed and unmeshed replicas behave differently (mTLS, retries, policies)", meshed, len(pods)),
+			"Make injection consistent across the workload's pods")
+	}
+}
+
+// effectivePeerAuthentication returns the PeerAuthentication that decides the mTLS mode for
+// pods with the given labels: workload selector, then namespace-wide, then mesh-wide.
+func effectivePeerAuthentication(namespace string, podLabels map[string]string, pas []PeerAuthenticationInfo) (string, string) {
+	var workload, nsWide, meshWide *PeerAuthenticationInfo
+	for i := range pas {
+		pa := &pas[i]
+		switch {
+		case pa.Namespace == namespace && len(pa.Selector) > 0 && selectorMatches(pa.Selector, podLabels):
+			workload = pa
+		case pa.Namespace == namespace && len(pa.Selector) == 0:
+			nsWide = pa
+		case pa.Namespace == IstioRootNamespace && len(pa.Selector) == 0:
+			meshWide = pa
+		}
+	}
+	for _, pa := range []*PeerAuthenticationInfo{workload, nsWide, meshWide} {
+		if pa != nil && pa.Mode != "UNSET" {
+			return pa.Mode, fmt.Sprintf("PeerAuthentication %s/%s", pa.Namespace, pa.Name)
+		}
+	}
+	return "PERMISSIVE", "mesh default, no PeerAuthentication"
+}
+
+func (m *ServiceMeshAnalysis) checkIstioMTLS(svc *corev1.Service, podLabels map[string]string, res *ServiceMeshResources) {
+	m.MTLSMode, m.MTLSSource = effectivePeerAuthentication(svc.Namespace, podLabels, res.PeerAuthentications)
+	if m.MTLSMode != "STRICT" {
+		return
+	}
+	for _, dr := range m.DestinationRules {
+		if dr.TLSMode == "DISABLE" || dr.TLSMode == "SIMPLE" {
+			m.add("CRITICAL", fmt.Sprintf("DestinationRule %s/%s sets tls.mode=%s but the service requires STRICT mTLS (%s) — meshed clients are rejected",
+				dr.Namespace, dr.Name, dr.TLSMode, m.MTLSSource),
+				fmt.Sprintf("Set tls.mode ISTIO_MUTUAL in DestinationRule %s/%s or remove the tls override", dr.Namespace, dr.Name))
+		}
+	}
+}
+
+// meshRoutingFor returns the VirtualServices that route to the service and the DestinationRules for it.
+func meshRoutingFor(svc *corev1.Service, res *ServiceMeshResources) ([]VirtualServiceInfo, []DestinationRuleInfo) {
+	if res == nil {
+		return nil, nil
+	}
+	var vss []VirtualServiceInfo
+	for _, vs := range res.VirtualServices {
+		for _, d := range vs.Destinations {
+			if meshHostMatchesService(d.Host, vs.Namespace, svc) {
+				vss = append(vss, vs)
+				break
+			}
+		}
+	}
+	var drs []DestinationRuleInfo
+	for _, dr := range res.DestinationRules {
+		if meshHostMatchesService(dr.Host, dr.Namespace, svc) {
+			drs = append(drs, dr)
+		}
+	}
+	return vss, drs
+}
+
+func (m *ServiceMeshAnalysis) checkSubsets(svc *corev1.Service, pods []corev1.Pod) {
+	reported := make(map[string]bool)
+	for _, vs := range m.VirtualServices {
+		for _, d := range vs.Destinations {
+			if d.Subset == "" || !meshHostMatchesService(d.Host, vs.Namespace, svc) {
+				continue
+			}
+			key := vs.Namespace + "/" + vs.Name + "/" + d.Subset
+			if reported[key] {
+				continue
+			}
+			reported[key] = true
+
+			var subsetLabels map[string]string
+			var owner *DestinationRuleInfo
+			for i := range m.DestinationRules {
+				if l, ok := m.DestinationRules[i].Subsets[d.Subset]; ok {
+					subsetLabels, owner = l, &m.DestinationRules[i]
+					break
+				}
+			}
+			if owner == nil {
+				m.add("CRITICAL", fmt.Sprintf("VirtualService %s/%s routes to subset '%s' of %s but no DestinationRule defines it — those requests fail with 503 (no healthy upstream)",
+					vs.Namespace, vs.Name, d.Subset, d.Host),
+					fmt.Sprintf("Add subset '%s' to the DestinationRule for %s, or remove it from VirtualService %s", d.Subset, d.Host, vs.Name))
+				continue
+			}
+			matched := 0
+			for i := range pods {
+				if selectorMatches(subsetLabels, pods[i].Labels) {
+					matched++
+				}
+			}
+			if matched == 0 {
+				m.add("CRITICAL", fmt.Sprintf("Subset '%s' (%s) in DestinationRule %s/%s matches none of the service's pods — VirtualService %s sends traffic to an empty subset",
+					d.Subset, formatLabelMap(subsetLabels), owner.Namespace, owner.Name, vs.Name),
+					fmt.Sprintf("Fix the labels of subset '%s' or deploy pods carrying them", d.Subset))
+			}
+		}
+	}
+}
+
+func (m *ServiceMeshAnalysis) checkAuthorizationPolicies(svc *corev1.Service, podLabels map[string]string, res *ServiceMeshResources) {
+	for _, ap := range res.AuthorizationPolicies {
+		applies := (ap.Namespace == svc.Namespace && (len(ap.Selector) == 0 || selectorMatches(ap.Selector, podLabels))) ||
+			(ap.Namespace == IstioRootNamespace && len(ap.Selector) == 0)
+		if !applies {
+			continue
+		}
+		m.AuthorizationPolicies = append(m.AuthorizationPolicies, ap)
+		ref := ap.Namespace + "/" + ap.Name
+		switch {
+		case ap.Action == "ALLOW" && ap.Rules == 0:
+			m.add("CRITICAL", fmt.Sprintf("AuthorizationPolicy %s is an allow-nothing policy — every request is denied with 403 (RBAC: access denied)", ref),
+				fmt.Sprintf("Add ALLOW rules for the expected callers to AuthorizationPolicy %s", ref))
+		case ap.Action == "DENY":
+			m.add("WARNING", fmt.Sprintf("AuthorizationPolicy %s DENYs requests matching its %d rule(s) with 403 (RBAC: access denied)", ref, ap.Rules),
+				fmt.Sprintf("Review the rules of AuthorizationPolicy %s if callers receive 403", ref))
+		case ap.Action == "CUSTOM":
+			m.add("INFO", fmt.Sprintf("AuthorizationPolicy %s delegates to an external authorizer — its decision can deny requests", ref), "")
+		}
+	}
+	sort.Slice(m.AuthorizationPolicies, func(i, j int) bool {
+		return m.AuthorizationPolicies[i].Namespace+m.AuthorizationPolicies[i].Name < m.AuthorizationPolicies[j].Namespace+m.AuthorizationPolicies[j].Name
+	})
+	if m.DeniedByDefault() {
+		m.add("INFO", "ALLOW policies apply — requests matching none of their rules are denied with 403 (RBAC: access denied)", "")
+	}
+}
+
+func (m *ServiceMeshAnalysis) checkLinkerdPolicy(ns *corev1.Namespace, pods []corev1.Pod) {
+	policy := ""
+	if ns != nil {
+		policy = ns.Annotations[linkerdInboundPolicyAnno]
+	}
+	if len(pods) > 0 && pods[0].Annotations[linkerdInboundPolicyAnno] != "" {
+		policy = pods[0].Annotations[linkerdInboundPolicyAnno]
+	}
+	switch policy {
+	case "deny":
+		m.add("WARNING", "Linkerd default inbound policy is 'deny' — only traffic admitted by a Server and AuthorizationPolicy (policy.linkerd.io) reaches the pods",
+			"Check the Server/AuthorizationPolicy objects for this workload's ports")
+	case "all-authenticated", "cluster-authenticated":
+		m.add("INFO", fmt.Sprintf("Linkerd default inbound policy is '%s' — unmeshed clients (e.g. an ingress controller without a proxy) are rejected", policy), "")
+	}
+}
+
+func formatLabelMap(m map[string]string) string {
+	parts := make([]string, 0, len(m))
+	for k, v := range m {
+		parts = append(parts, k+"="+v)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,210 @@
+package k8s
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func meshPod(name string, lbls map[string]string, sidecar string, sidecarReady bool) corev1.Pod {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", Labels: lbls},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "app", Ready: true},
+		}},
+	}
+	if sidecar != "" {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: sidecar})
+		pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, corev1.ContainerStatus{Name: sidecar, Ready: sidecarReady})
+	}
+	return pod
+}
+
+func meshIssue(issues []MeshIssue, severity, substr string) bool {
+	for _, i := range issues {
+		if i.Severity == severity && strings.Contains(i.Problem, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPodSidecarNative(t *testing.T) {
+	always := corev1.ContainerRestartPolicyAlways
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{InitContainers: []corev1.Container{{Name: IstioProxyContainer, RestartPolicy: &always}}},
+		Status: corev1.PodStatus{InitContainerStatuses: []corev1.ContainerStatus{
+			{Name: IstioProxyContainer, Ready: true, RestartCount: 2},
+		}},
+	}
+	sc := PodSidecar(pod)
+	if sc == nil || !sc.Native || !sc.Ready || sc.Restarts != 2 || sc.Mesh != MeshIstio {
+		t.Errorf("unexpected native sidecar: %+v", sc)
+	}
+	if PodSidecar(&corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}}}) != nil {
+		t.Error("expected no sidecar for a plain pod")
+	}
+}
+
+func TestBuildServiceMeshAnalysisIstio(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "shop"},
+		Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "reviews"}},
+	}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop", Labels: map[string]string{"istio-injection": "enabled"}}}
+	pods := []corev1.Pod{
+		meshPod("reviews-v1", map[string]string{"app": "reviews", "version": "v1"}, IstioProxyContainer, true),
+		meshPod("reviews-v1-b", map[string]string{"app": "reviews", "version": "v1"}, IstioProxyContainer, false),
+		meshPod("reviews-old", map[string]string{"app": "reviews", "version": "v1"}, "", false),
+	}
+	res := &ServiceMeshResources{
+		PeerAuthentications: []PeerAuthenticationInfo{
+			{Namespace: IstioRootNamespace, Name: "default", Mode: "PERMISSIVE"},
+			{Namespace: "shop", Name: "shop", Mode: "STRICT"},
+			{Namespace: "shop", Name: "other", Selector: map[string]string{"app": "ratings"}, Mode: "DISABLE"},
+		},
+		VirtualServices: []VirtualServiceInfo{{Namespace: "shop", Name: "reviews", Destinations: []MeshRouteDestination{
+			{Host: "reviews", Subset: "v1", Weight: 50},
+			{Host: "reviews.shop.svc.cluster.local", Subset: "v2", Weight: 30},
+			{Host: "reviews.shop", Subset: "v3", Weight: 20},
+		}}},
+		DestinationRules: []DestinationRuleInfo{{Namespace: "shop", Name: "reviews", Host: "reviews", TLSMode: "DISABLE", Subsets: map[string]map[string]string{
+			"v1": {"version": "v1"},
+			"v2": {"version": "v2"},
+		}}},
+		AuthorizationPolicies: []AuthorizationPolicyInfo{
+			{Namespace: "shop", Name: "deny-all", Action: "ALLOW"},
+			{Namespace: "shop", Name: "ratings-only", Selector: map[string]string{"app": "ratings"}, Action: "DENY", Rules: 1},
+		},
+	}
+
+	m := BuildServiceMeshAnalysis(svc, ns, pods, res)
+	if !m.Meshed() || m.Mesh != MeshIstio || m.NamespaceInjection != InjectionEnabled {
+		t.Fatalf("expected istio mesh with injection, got %+v", m)
+	}
+	if m.MTLSMode != "STRICT" || !strings.Contains(m.MTLSSource, "shop/shop") {
+		t.Errorf("expected namespace-wide STRICT mTLS, got %s (%s)", m.MTLSMode, m.MTLSSource)
+	}
+	if len(m.AuthorizationPolicies) != 1 || !m.DeniedByDefault() {
+		t.Errorf("expected only the namespace-wide policy to apply, got %+v", m.AuthorizationPolicies)
+	}
+	for _, want := range []struct{ severity, substr string }{
+		{"CRITICAL", "istio-proxy in pod 'reviews-v1-b' is not ready"},
+		{"WARNING", "Pod 'reviews-old' has no istio-proxy sidecar"},
+		{"CRITICAL", "tls.mode=DISABLE"},
+		{"CRITICAL", "subset 'v3'"},
+		{"CRITICAL", "Subset 'v2'"},
+		{"CRITICAL", "allow-nothing"},
+	} {
+		if !meshIssue(m.Issues, want.severity, want.substr) {
+			t.Errorf("missing %s issue containing %q in %+v", want.severity, want.substr, m.Issues)
+		}
+	}
+	if meshIssue(m.Issues, "CRITICAL", "subset 'v1'") {
+		t.Error("subset v1 is defined and matches pods; it must not be reported")
+	}
+}
+
+func TestBuildServiceMeshAnalysisNotMeshed(t *testing.T) {
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"}}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop", Labels: map[string]string{"istio-injection": "disabled"}}}
+	m := BuildServiceMeshAnalysis(svc, ns, []corev1.Pod{meshPod("web-1", nil, "", false)}, &ServiceMeshResources{})
+	if m.Meshed() || len(m.Issues) != 0 {
+		t.Errorf("expected an unmeshed service, got %+v", m)
+	}
+
+	linkerdNS := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop", Annotations: map[string]string{
+		"linkerd.io/inject": "enabled", "config.linkerd.io/default-inbound-policy": "deny",
+	}}}
+	m = BuildServiceMeshAnalysis(svc, linkerdNS, []corev1.Pod{meshPod("web-1", nil, LinkerdProxyContainer, true)}, nil)
+	if m.Mesh != MeshLinkerd || m.MTLSMode != "automatic" || !meshIssue(m.Issues, "WARNING", "default inbound policy is 'deny'") {
+		t.Errorf("unexpected linkerd analysis: %+v", m)
+	}
+}
+
+func TestGetServiceMeshResources(t *testing.T) {
+	gvr := func(group, resource string) schema.GroupVersionResource {
+		return schema.GroupVersionResource{Group: group, Version: "v1", Resource: resource}
+	}
+	obj := func(group, kind, name string, spec map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": group + "/v1", "kind": kind,
+			"metadata": map[string]interface{}{"name": name, "namespace": "shop"},
+			"spec":     spec,
+		}}
+	}
+	apiext := apiextfake.NewSimpleClientset(
+		reportCRD("security.istio.io", "peerauthentications", "v1"),
+		reportCRD("networking.istio.io", "virtualservices", "v1"),
+		reportCRD("networking.istio.io", "destinationrules", "v1"),
+	)
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			gvr("security.istio.io", "peerauthentications"): "PeerAuthenticationList",
+			gvr("networking.istio.io", "virtualservices"):   "VirtualServiceList",
+			gvr("networking.istio.io", "destinationrules"):  "DestinationRuleList",
+		},
+		obj("security.istio.io", "PeerAuthentication", "strict", map[string]interface{}{
+			"mtls":          map[string]interface{}{"mode": "STRICT"},
+			"portLevelMtls": map[string]interface{}{"8080": map[string]interface{}{"mode": "PERMISSIVE"}},
+		}),
+		obj("networking.istio.io", "VirtualService", "reviews", map[string]interface{}{
+			"hosts": []interface{}{"reviews"},
+			"http": []interface{}{map[string]interface{}{"route": []interface{}{
+				map[string]interface{}{"destination": map[string]interface{}{"host": "reviews", "subset": "v2", "port": map[string]interface{}{"number": int64(9080)}}, "weight": int64(100)},
+			}}},
+		}),
+		obj("networking.istio.io", "DestinationRule", "reviews", map[string]interface{}{
+			"host":          "reviews",
+			"trafficPolicy": map[string]interface{}{"tls": map[string]interface{}{"mode": "ISTIO_MUTUAL"}},
+			"subsets":       []interface{}{map[string]interface{}{"name": "v2", "labels": map[string]interface{}{"version": "v2"}}},
+		}),
+	)
+	client := NewClusterClientForTestingWithDynamic(fake.NewSimpleClientset(), apiext, dyn)
+
+	res, err := client.GetServiceMeshResources(context.Background())
+	if err != nil {
+		t.Fatalf("GetServiceMeshResources() error = %v", err)
+	}
+	if res.Installed[AuthorizationPolicyCRD] || !res.Installed[VirtualServiceCRD] {
+		t.Errorf("unexpected installed CRDs: %v", res.Installed)
+	}
+	if len(res.PeerAuthentications) != 1 || res.PeerAuthentications[0].Mode != "STRICT" || res.PeerAuthentications[0].PortModes["8080"] != "PERMISSIVE" {
+		t.Errorf("unexpected PeerAuthentications: %+v", res.PeerAuthentications)
+	}
+	if len(res.VirtualServices) != 1 || len(res.VirtualServices[0].Destinations) != 1 {
+		t.Fatalf("unexpected VirtualServices: %+v", res.VirtualServices)
+	}
+	if d := res.VirtualServices[0].Destinations[0]; d.Subset != "v2" || d.Port != 9080 || d.Weight != 100 {
+		t.Errorf("unexpected destination: %+v", d)
+	}
+	if len(res.DestinationRules) != 1 || res.DestinationRules[0].TLSMode != "ISTIO_MUTUAL" || res.DestinationRules[0].Subsets["v2"]["version"] != "v2" {
+		t.Errorf("unexpected DestinationRules: %+v", res.DestinationRules)
+	}
+}
+
+func TestMeshHostFQDN(t *testing.T) {
+	tests := map[string]string{
+		"reviews":                          "reviews.shop.svc.cluster.local",
+		"reviews.other":                    "reviews.other.svc.cluster.local",
+		"reviews.other.svc":                "reviews.other.svc.cluster.local",
+		"reviews.other.svc.cluster.local.": "reviews.other.svc.cluster.local",
+		"*.shop.svc.cluster.local":         "*.shop.svc.cluster.local",
+		"api.example.com":                  "api.example.com",
+	}
+	for host, want := range tests {
+		if got := MeshHostFQDN(host, "shop"); got != want {
+			t.Errorf("MeshHostFQDN(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestListServiceAccounts(t *testing.T) {
+	automount := false
+	fakeClient := fake.NewSimpleClientset(
+		&corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "default"},
+		},
+		&corev1.ServiceAccount{
+			ObjectMeta:                   metav1.ObjectMeta{Name: "builder", Namespace: "default"},
+			AutomountServiceAccountToken: &automount,
+		},
+		&corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "other"},
+		},
+	)
+
+	client := NewClusterClientForTesting(fakeClient, nil)
+
+	accounts, err := client.ListServiceAccounts(context.Background(), "default", metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("ListServiceAccounts() error = %v", err)
+	}
+	if len(accounts) != 2 {
+		t.Errorf("expected 2 service accounts, got %d", len(accounts))
+	}
+
+	all, err := client.ListServiceAccounts(context.Background(), "", metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("ListServiceAccounts() error = %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("expected 3 service accounts across namespaces, got %d", len(all))
+	}
+}
+
+func TestGetServiceAccount(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(&corev1.ServiceAccount{
+		ObjectMeta:       metav1.ObjectMeta{Name: "puller", Namespace: "default"},
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "regcred"}},
+	})
+	client := NewClusterClientForTesting(fakeClient, nil)
+
+	sa, err := client.GetServiceAccount(context.Background(), "default", "puller")
+	if err != nil {
+		t.Fatalf("GetServiceAccount() error = %v", err)
+	}
+	if len(sa.ImagePullSecrets) != 1 || sa.ImagePullSecrets[0].Name != "regcred" {
+		t.Errorf("unexpected imagePullSecrets: %v", sa.ImagePullSecrets)
+	}
+
+	if _, err := client.GetServiceAccount(context.Background(), "default", "missing"); err == nil {
+		t.Error("expected error for missing service account")
+	}
+}
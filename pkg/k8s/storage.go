@@ -4,6 +4,7 @@ import (
 	"context"
 
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
@@ -32,3 +33,59 @@ func (c *ClusterClient) ListPVs(ctx context.Context) ([]corev1.PersistentVolume,
 	}
 	return list.Items, nil
 }
+
+// GetPVC returns a single PersistentVolumeClaim.
+func (c *ClusterClient) GetPVC(ctx context.Context, namespace, name string) (*corev1.PersistentVolumeClaim, error) {
+	ctx, cancel := context.WithTimeout(ctx, util.DefaultTimeout)
+	defer cancel()
+
+	return c.Clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// GetPV returns a single PersistentVolume.
+func (c *ClusterClient) GetPV(ctx context.Context, name string) (*corev1.PersistentVolume, error) {
+	ctx, cancel := context.WithTimeout(ctx, util.DefaultTimeout)
+	defer cancel()
+
+	return c.Clientset.CoreV1().PersistentVolumes().Get(ctx, name, metav1.GetOptions{})
+}
+
+// ListStorageClasses returns all StorageClasses.
+func (c *ClusterClient) ListStorageClasses(ctx context.Context) ([]storagev1.StorageClass, error) {
+	ctx, cancel := context.WithTimeout(ctx, util.DefaultTimeout)
+	defer cancel()
+
+	list, err := c.Clientset.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// ListVolumeAttachments returns all VolumeAttachments.
+func (c *ClusterClient) ListVolumeAttachments(ctx context.Context) ([]storagev1.VolumeAttachment, error) {
+	ctx, cancel := context.WithTimeout(ctx, util.DefaultTimeout)
+	defer cancel()
+
+	list, err := c.Clientset.StorageV1().VolumeAttachments().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// GetCSIDriver returns a single CSIDriver.
+func (c *ClusterClient) GetCSIDriver(ctx context.Context, name string) (*storagev1.CSIDriver, error) {
+	ctx, cancel := context.WithTimeout(ctx, util.DefaultTimeout)
+	defer cancel()
+
+	return c.Clientset.StorageV1().CSIDrivers().Get(ctx, name, metav1.GetOptions{})
+}
+
+// GetCSINode returns the CSINode object for a node.
+func (c *ClusterClient) GetCSINode(ctx context.Context, name string) (*storagev1.CSINode, error) {
+	ctx, cancel := context.WithTimeout(ctx, util.DefaultTimeout)
+	defer cancel()
+
+	return c.Clientset.StorageV1().CSINodes().Get(ctx, name, metav1.GetOptions{})
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
@@ -69,3 +70,87 @@ func TestListPVs(t *testing.T) {
 		t.Errorf("expected 1 PV, got %d", len(pvs))
 	}
 }
+
+func TestGetPVCAndPV(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "default"},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-1"},
+		},
+		&corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv-1"}},
+	)
+
+	client := NewClusterClientForTesting(fakeClient, nil)
+
+	pvc, err := client.GetPVC(context.Background(), "default", "data")
+	if err != nil {
+		t.Fatalf("GetPVC() error = %v", err)
+	}
+	pv, err := client.GetPV(context.Background(), pvc.Spec.VolumeName)
+	if err != nil {
+		t.Fatalf("GetPV() error = %v", err)
+	}
+	if pv.Name != "pv-1" {
+		t.Errorf("expected pv-1, got %s", pv.Name)
+	}
+
+	if _, err := client.GetPVC(context.Background(), "default", "missing"); err == nil {
+		t.Error("expected error for missing PVC")
+	}
+}
+
+func TestListStorageClassesAndAttachments(t *testing.T) {
+	pvName := "pv-1"
+	fakeClient := fake.NewSimpleClientset(
+		&storagev1.StorageClass{
+			ObjectMeta:  metav1.ObjectMeta{Name: "fast"},
+			Provisioner: "disk.csi.azure.com",
+		},
+		&storagev1.VolumeAttachment{
+			ObjectMeta: metav1.ObjectMeta{Name: "csi-abc"},
+			Spec: storagev1.VolumeAttachmentSpec{
+				Attacher: "disk.csi.azure.com",
+				NodeName: "node-1",
+				Source:   storagev1.VolumeAttachmentSource{PersistentVolumeName: &pvName},
+			},
+			Status: storagev1.VolumeAttachmentStatus{Attached: true},
+		},
+		&storagev1.CSIDriver{ObjectMeta: metav1.ObjectMeta{Name: "disk.csi.azure.com"}},
+		&storagev1.CSINode{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Spec: storagev1.CSINodeSpec{
+				Drivers: []storagev1.CSINodeDriver{{Name: "disk.csi.azure.com", NodeID: "node-1"}},
+			},
+		},
+	)
+
+	client := NewClusterClientForTesting(fakeClient, nil)
+	ctx := context.Background()
+
+	classes, err := client.ListStorageClasses(ctx)
+	if err != nil {
+		t.Fatalf("ListStorageClasses() error = %v", err)
+	}
+	if len(classes) != 1 {
+		t.Errorf("expected 1 StorageClass, got %d", len(classes))
+	}
+
+	attachments, err := client.ListVolumeAttachments(ctx)
+	if err != nil {
+		t.Fatalf("ListVolumeAttachments() error = %v", err)
+	}
+	if len(attachments) != 1 || *attachments[0].Spec.Source.PersistentVolumeName != "pv-1" {
+		t.Errorf("unexpected attachments: %+v", attachments)
+	}
+
+	if _, err := client.GetCSIDriver(ctx, "disk.csi.azure.com"); err != nil {
+		t.Errorf("GetCSIDriver() error = %v", err)
+	}
+	csiNode, err := client.GetCSINode(ctx, "node-1")
+	if err != nil {
+		t.Fatalf("GetCSINode() error = %v", err)
+	}
+	if len(csiNode.Spec.Drivers) != 1 {
+		t.Errorf("expected 1 CSI driver on node, got %d", len(csiNode.Spec.Drivers))
+	}
+}
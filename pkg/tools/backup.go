@@ -0,0 +1,413 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+type storageBackupCoverageInput struct {
+	Namespace   string `json:"namespace,omitempty" jsonschema:"Namespace to analyze (empty or 'all' for all namespaces)"`
+	MaxAgeHours int    `json:"max_age_hours,omitempty" jsonschema:"Snapshots/backups older than this are reported as stale (default 24)"`
+}
+
+const (
+	volumeSnapshotCRD = "volumesnapshots.snapshot.storage.k8s.io"
+	veleroBackupCRD   = "backups.velero.io"
+	veleroScheduleCRD = "schedules.velero.io"
+
+	defaultBackupMaxAgeHours = 24
+)
+
+// pvcProtection records the most recent snapshot and backup covering a PVC.
+type pvcProtection struct {
+	SnapshotName string
+	SnapshotTime time.Time
+	BackupName   string
+	BackupTime   time.Time
+	// ManifestsOnly is set when the latest covering backup does not include volume data.
+	ManifestsOnly bool
+}
+
+// latest returns the most recent protection timestamp (zero if unprotected).
+func (p pvcProtection) latest() time.Time {
+	t := p.SnapshotTime
+	if !p.ManifestsOnly && p.BackupTime.After(t) {
+		t = p.BackupTime
+	}
+	return t
+}
+
+// veleroBackup is the subset of a Velero Backup used for coverage mapping.
+type veleroBackup struct {
+	Name               string
+	Phase              string
+	Completed          time.Time
+	IncludedNamespaces []string
+	ExcludedNamespaces []string
+	IncludesPVCs       bool
+	VolumeData         bool
+}
+
+func registerBackupTools(server *mcp.Server, client *k8s.ClusterClient) {
+	// storage_backup_coverage
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "storage_backup_coverage",
+		Description: "Report which stateful data is protected. Maps every PVC to its latest VolumeSnapshot (snapshot.storage.k8s.io) " +
+			"and Velero Backup with age, lists Velero Schedules, and flags StatefulSet PVCs with no protection or stale snapshots. " +
+			"Works without the snapshot or Velero CRDs installed (reports everything as unprotected).",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input storageBackupCoverageInput) (*mcp.CallToolResult, any, error) {
+		ns := util.NamespaceOrAll(input.Namespace)
+		maxAgeHours := input.MaxAgeHours
+		if maxAgeHours <= 0 {
+			maxAgeHours = defaultBackupMaxAgeHours
+		}
+		maxAge := time.Duration(maxAgeHours) * time.Hour
+
+		pvcs, err := client.ListPVCs(ctx, ns, metav1.ListOptions{})
+		if err != nil {
+			return util.HandleK8sError("listing PVCs", err), nil, nil
+		}
+		statefulSets, err := client.ListStatefulSets(ctx, ns, metav1.ListOptions{})
+		if err != nil {
+			return util.HandleK8sError("listing statefulsets", err), nil, nil
+		}
+
+		var sb strings.Builder
+		sb.WriteString(util.FormatHeader(fmt.Sprintf("Storage Backup Coverage (namespace: %s)", displayNS(input.Namespace))))
+		sb.WriteString("\n\n")
+		findings := 0
+		var actions []string
+
+		// 1. Discover providers
+		sb.WriteString(util.FormatSubHeader("Data Protection Providers"))
+		sb.WriteString("\n")
+		crds, crdErr := client.ListCRDs(ctx)
+		absent := "not installed"
+		if crdErr != nil {
+			absent = "unknown"
+			sb.WriteString(fmt.Sprintf("  (CRD discovery unavailable: %v)\n", crdErr))
+		}
+		snapshotCRD := k8s.FindCRD(crds, volumeSnapshotCRD)
+		backupCRD := k8s.FindCRD(crds, veleroBackupCRD)
+		scheduleCRD := k8s.FindCRD(crds, veleroScheduleCRD)
+
+		protection := make(map[string]*pvcProtection)
+		protectionFor := func(key string) *pvcProtection {
+			if p, ok := protection[key]; ok {
+				return p
+			}
+			p := &pvcProtection{}
+			protection[key] = p
+			return p
+		}
+
+		if snapshotCRD == nil {
+			sb.WriteString(util.FormatKeyValue("VolumeSnapshots", absent))
+			sb.WriteString("\n")
+		} else {
+			gvr := k8s.CRDGroupVersionResource(snapshotCRD)
+			sb.WriteString(util.FormatKeyValue("VolumeSnapshots", "installed ("+gvr.Version+")"))
+			sb.WriteString("\n")
+			snapshots, err := client.ListCustomResources(ctx, gvr, ns)
+			if err != nil {
+				sb.WriteString(fmt.Sprintf("  (unable to list VolumeSnapshots: %v)\n", err))
+			}
+			var failed []string
+			for i := range snapshots {
+				snap := &snapshots[i]
+				pvcName, _, _ := unstructured.NestedString(snap.Object, "spec", "source", "persistentVolumeClaimName")
+				if pvcName == "" {
+					continue
+				}
+				if msg, _, _ := unstructured.NestedString(snap.Object, "status", "error", "message"); msg != "" {
+					failed = append(failed, fmt.Sprintf("%s/%s: %s", snap.GetNamespace(), snap.GetName(), msg))
+					continue
+				}
+				if ready, _, _ := unstructured.NestedBool(snap.Object, "status", "readyToUse"); !ready {
+					continue
+				}
+				created := snap.GetCreationTimestamp().Time
+				if ts, _, _ := unstructured.NestedString(snap.Object, "status", "creationTime"); ts != "" {
+					if t, err := time.Parse(time.RFC3339, ts); err == nil {
+						created = t
+					}
+				}
+				p := protectionFor(snap.GetNamespace() + "/" + pvcName)
+				if created.After(p.SnapshotTime) {
+					p.SnapshotName = snap.GetName()
+					p.SnapshotTime = created
+				}
+			}
+			if len(failed) > 0 {
+				sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("%d VolumeSnapshot(s) failed", len(failed))))
+				sb.WriteString("\n")
+				for _, f := range truncateList(failed, 10) {
+					sb.WriteString(fmt.Sprintf("  - %s\n", f))
+				}
+				findings++
+				actions = append(actions, "Check the CSI snapshotter and VolumeSnapshotClass for the failed snapshots")
+			}
+		}
+
+		var backups []veleroBackup
+		if backupCRD == nil {
+			sb.WriteString(util.FormatKeyValue("Velero", absent))
+			sb.WriteString("\n")
+		} else {
+			gvr := k8s.CRDGroupVersionResource(backupCRD)
+			sb.WriteString(util.FormatKeyValue("Velero", "installed ("+gvr.Version+")"))
+			sb.WriteString("\n")
+			items, err := client.ListCustomResources(ctx, gvr, "")
+			if err != nil {
+				sb.WriteString(fmt.Sprintf("  (unable to list Velero Backups: %v)\n", err))
+			}
+			failedBackups := 0
+			for i := range items {
+				b := parseVeleroBackup(&items[i])
+				if b.Phase == "Failed" || b.Phase == "PartiallyFailed" {
+					failedBackups++
+				}
+				backups = append(backups, b)
+			}
+			if failedBackups > 0 {
+				sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("%d Velero Backup(s) Failed or PartiallyFailed", failedBackups)))
+				sb.WriteString("\n")
+				findings++
+				actions = append(actions, "Inspect failed backups with 'velero backup describe <name> --details' and 'velero backup logs <name>'")
+			}
+		}
+
+		// 2. Velero schedules
+		if scheduleCRD != nil {
+			schedules, err := client.ListCustomResources(ctx, k8s.CRDGroupVersionResource(scheduleCRD), "")
+			if err == nil && len(schedules) > 0 {
+				sb.WriteString("\n")
+				sb.WriteString(util.FormatSubHeader("Velero Schedules"))
+				sb.WriteString("\n")
+				headers := []string{"NAME", "SCHEDULE", "NAMESPACES", "PHASE", "LAST BACKUP", "PAUSED"}
+				rows := make([][]string, 0, len(schedules))
+				for i := range schedules {
+					s := &schedules[i]
+					cron, _, _ := unstructured.NestedString(s.Object, "spec", "schedule")
+					included, _, _ := unstructured.NestedStringSlice(s.Object, "spec", "template", "includedNamespaces")
+					phase, _, _ := unstructured.NestedString(s.Object, "status", "phase")
+					lastBackup, _, _ := unstructured.NestedString(s.Object, "status", "lastBackup")
+					paused, _, _ := unstructured.NestedBool(s.Object, "spec", "paused")
+					namespaces := "*"
+					if len(included) > 0 {
+						namespaces = strings.Join(included, ",")
+					}
+					last := "<never>"
+					if t, err := time.Parse(time.RFC3339, lastBackup); err == nil {
+						last = util.FormatAge(t) + " ago"
+					}
+					rows = append(rows, []string{s.GetName(), cron, namespaces, valueOrNone(phase), last, strconv.FormatBool(paused)})
+					if paused {
+						sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("Velero Schedule '%s' is paused", s.GetName())))
+						sb.WriteString("\n")
+						findings++
+					}
+				}
+				sb.WriteString(util.FormatTable(headers, rows))
+			}
+		}
+
+		// Map backups to PVC namespaces
+		for _, b := range backups {
+			if (b.Phase != "Completed" && b.Phase != "PartiallyFailed") || !b.IncludesPVCs {
+				continue
+			}
+			for _, pvc := range pvcs {
+				if !b.coversNamespace(pvc.Namespace) {
+					continue
+				}
+				p := protectionFor(pvc.Namespace + "/" + pvc.Name)
+				if b.Completed.After(p.BackupTime) {
+					p.BackupName = b.Name
+					p.BackupTime = b.Completed
+					p.ManifestsOnly = !b.VolumeData
+				}
+			}
+		}
+
+		// 3. Per-PVC coverage
+		owners := statefulSetPVCOwners(statefulSets)
+		sort.Slice(pvcs, func(i, j int) bool {
+			if pvcs[i].Namespace != pvcs[j].Namespace {
+				return pvcs[i].Namespace < pvcs[j].Namespace
+			}
+			return pvcs[i].Name < pvcs[j].Name
+		})
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("PVC Coverage"))
+		sb.WriteString("\n")
+		headers := []string{"PVC", "NAMESPACE", "STATEFULSET", "LATEST SNAPSHOT", "LATEST BACKUP", "STATUS"}
+		rows := make([][]string, 0, len(pvcs))
+		var unprotectedSTS, staleSTS []string
+		unprotectedOther := 0
+		for i := range pvcs {
+			pvc := &pvcs[i]
+			key := pvc.Namespace + "/" + pvc.Name
+			owner := statefulSetOwnerOf(owners, pvc)
+			p := protection[key]
+			if p == nil {
+				p = &pvcProtection{}
+			}
+
+			snapshot := "-"
+			if p.SnapshotName != "" {
+				snapshot = fmt.Sprintf("%s (%s ago)", p.SnapshotName, util.FormatAge(p.SnapshotTime))
+			}
+			backup := "-"
+			if p.BackupName != "" {
+				backup = fmt.Sprintf("%s (%s ago)", p.BackupName, util.FormatAge(p.BackupTime))
+				if p.ManifestsOnly {
+					backup += " [manifests only]"
+				}
+			}
+
+			status := "PROTECTED"
+			latest := p.latest()
+			switch {
+			case latest.IsZero():
+				status = "UNPROTECTED"
+				if owner != "" {
+					unprotectedSTS = append(unprotectedSTS, key)
+				} else {
+					unprotectedOther++
+				}
+			case time.Since(latest) > maxAge:
+				status = "STALE"
+				if owner != "" {
+					staleSTS = append(staleSTS, fmt.Sprintf("%s (last protected %s ago)", key, util.FormatAge(latest)))
+				}
+			}
+			if owner == "" {
+				owner = "-"
+			}
+			rows = append(rows, []string{pvc.Name, pvc.Namespace, owner, snapshot, backup, status})
+		}
+		if len(rows) == 0 {
+			sb.WriteString("  No PVCs found.\n")
+		} else {
+			sb.WriteString(util.FormatTable(headers, rows))
+		}
+
+		if len(unprotectedSTS) > 0 {
+			sb.WriteString(fmt.Sprintf("\n%s\n", util.FormatFinding("CRITICAL", fmt.Sprintf("%d StatefulSet PVC(s) have no snapshot or backup", len(unprotectedSTS)))))
+			for _, k := range truncateList(unprotectedSTS, 20) {
+				sb.WriteString(fmt.Sprintf("  - %s\n", k))
+			}
+			findings++
+			if snapshotCRD == nil && backupCRD == nil {
+				actions = append(actions, "Install the CSI snapshot CRDs/controller or Velero to protect stateful data")
+			} else {
+				actions = append(actions, "Add a VolumeSnapshot schedule or Velero Schedule covering the unprotected StatefulSet namespaces")
+			}
+		}
+		if len(staleSTS) > 0 {
+			sb.WriteString(fmt.Sprintf("\n%s\n", util.FormatFinding("WARNING", fmt.Sprintf("%d StatefulSet PVC(s) have no snapshot/backup newer than %dh", len(staleSTS), maxAgeHours))))
+			for _, k := range truncateList(staleSTS, 20) {
+				sb.WriteString(fmt.Sprintf("  - %s\n", k))
+			}
+			findings++
+			actions = append(actions, "Check that snapshot/backup schedules are running and not paused or failing")
+		}
+		if unprotectedOther > 0 {
+			sb.WriteString(fmt.Sprintf("\n%s\n", util.FormatFinding("INFO", fmt.Sprintf("%d non-StatefulSet PVC(s) are unprotected", unprotectedOther))))
+		}
+
+		// Summary
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Summary"))
+		sb.WriteString("\n")
+		protected := len(pvcs) - len(unprotectedSTS) - unprotectedOther
+		sb.WriteString(fmt.Sprintf("  %d/%d PVC(s) have a snapshot or backup with volume data.\n", protected, len(pvcs)))
+		if findings == 0 {
+			sb.WriteString("  No backup coverage issues found.\n")
+		} else {
+			sb.WriteString(fmt.Sprintf("  %d finding(s) identified. Review details above.\n", findings))
+		}
+		if len(actions) > 0 {
+			sb.WriteString("\nSUGGESTED ACTIONS:\n")
+			for i, a := range dedupe(actions) {
+				sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, a))
+			}
+		}
+
+		return util.SuccessResult(sb.String()), nil, nil
+	})
+}
+
+// parseVeleroBackup extracts the coverage-relevant fields of a Velero Backup.
+func parseVeleroBackup(obj *unstructured.Unstructured) veleroBackup {
+	b := veleroBackup{Name: obj.GetName()}
+	b.Phase, _, _ = unstructured.NestedString(obj.Object, "status", "phase")
+	b.IncludedNamespaces, _, _ = unstructured.NestedStringSlice(obj.Object, "spec", "includedNamespaces")
+	b.ExcludedNamespaces, _, _ = unstructured.NestedStringSlice(obj.Object, "spec", "excludedNamespaces")
+
+	b.Completed = obj.GetCreationTimestamp().Time
+	if ts, _, _ := unstructured.NestedString(obj.Object, "status", "completionTimestamp"); ts != "" {
+		if t, err := time.Parse(time.RFC3339, ts); err == nil {
+			b.Completed = t
+		}
+	}
+
+	included, _, _ := unstructured.NestedStringSlice(obj.Object, "spec", "includedResources")
+	excluded, _, _ := unstructured.NestedStringSlice(obj.Object, "spec", "excludedResources")
+	b.IncludesPVCs = (len(included) == 0 || containsString(included, "*") ||
+		containsString(included, "persistentvolumeclaims") || containsString(included, "pvc")) &&
+		!containsString(excluded, "persistentvolumeclaims") && !containsString(excluded, "pvc")
+
+	// Velero snapshots volumes unless snapshotVolumes is explicitly false; fs-backup also captures data
+	snapshotVolumes, found, _ := unstructured.NestedBool(obj.Object, "spec", "snapshotVolumes")
+	fsBackup, _, _ := unstructured.NestedBool(obj.Object, "spec", "defaultVolumesToFsBackup")
+	b.VolumeData = !found || snapshotVolumes || fsBackup
+	return b
+}
+
+// coversNamespace reports whether the backup includes the given namespace.
+func (b veleroBackup) coversNamespace(ns string) bool {
+	if containsString(b.ExcludedNamespaces, ns) {
+		return false
+	}
+	return len(b.IncludedNamespaces) == 0 || containsString(b.IncludedNamespaces, "*") || containsString(b.IncludedNamespaces, ns)
+}
+
+// statefulSetPVCOwners maps "<namespace>/<template>-<statefulset>-" PVC name prefixes to StatefulSet names.
+func statefulSetPVCOwners(statefulSets []appsv1.StatefulSet) map[string]string {
+	owners := make(map[string]string)
+	for _, sts := range statefulSets {
+		for _, tmpl := range sts.Spec.VolumeClaimTemplates {
+			owners[fmt.Sprintf("%s/%s-%s-", sts.Namespace, tmpl.Name, sts.Name)] = sts.Name
+		}
+	}
+	return owners
+}
+
+// statefulSetOwnerOf returns the StatefulSet that created the PVC from a volumeClaimTemplate, or "".
+func statefulSetOwnerOf(owners map[string]string, pvc *corev1.PersistentVolumeClaim) string {
+	key := pvc.Namespace + "/" + pvc.Name
+	for prefix, sts := range owners {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if _, err := strconv.Atoi(strings.TrimPrefix(key, prefix)); err == nil {
+			return sts
+		}
+	}
+	return ""
+}
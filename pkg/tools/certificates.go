@@ -0,0 +1,400 @@
+package tools
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+type checkCertificatesInput struct {
+	Namespace string `json:"namespace,omitempty" jsonschema:"Namespace for Ingress TLS secrets and cert-manager resources (empty or 'all' for all namespaces)"`
+}
+
+const (
+	certManagerCertificateCRD        = "certificates.cert-manager.io"
+	certManagerCertificateRequestCRD = "certificaterequests.cert-manager.io"
+	certManagerIssuerCRD             = "issuers.cert-manager.io"
+	certManagerClusterIssuerCRD      = "clusterissuers.cert-manager.io"
+
+	certManagerInjectAnnotation = "cert-manager.io/inject-ca-from"
+)
+
+// certReport accumulates certificate table rows, findings and actions for check_certificates.
+type certReport struct {
+	sb       *strings.Builder
+	rows     [][]string
+	findings int
+	actions  []string
+	now      time.Time
+}
+
+func (r *certReport) finding(severity, msg string) {
+	r.sb.WriteString(util.FormatFinding(severity, msg))
+	r.sb.WriteString("\n")
+	r.findings++
+}
+
+// addCert records a table row for the certificate and reports expiry findings.
+func (r *certReport) addCert(source string, cert *x509.Certificate) {
+	days := util.DaysUntilExpiry(cert, r.now)
+	status := "OK"
+	switch {
+	case r.now.After(cert.NotAfter):
+		status = "EXPIRED"
+		r.finding("CRITICAL", fmt.Sprintf("%s: certificate '%s' expired %s ago", source, cert.Subject.CommonName, util.FormatAge(cert.NotAfter)))
+	case days <= util.CertExpiryCriticalDays:
+		status = "CRITICAL"
+		r.finding("CRITICAL", fmt.Sprintf("%s: certificate '%s' expires in %d day(s)", source, cert.Subject.CommonName, days))
+	case days <= util.CertExpiryWarningDays:
+		status = "WARNING"
+		r.finding("WARNING", fmt.Sprintf("%s: certificate '%s' expires in %d day(s)", source, cert.Subject.CommonName, days))
+	}
+	r.rows = append(r.rows, []string{
+		source,
+		valueOrNone(cert.Subject.CommonName),
+		valueOrNone(cert.Issuer.CommonName),
+		cert.NotAfter.UTC().Format("2006-01-02"),
+		fmt.Sprintf("%d", days),
+		status,
+	})
+	if status != "OK" {
+		r.actions = append(r.actions, fmt.Sprintf("Renew certificates expiring within %d days and restart workloads that do not reload certificates automatically", util.CertExpiryWarningDays))
+	}
+}
+
+// flush writes the accumulated table (if any) and resets the rows.
+func (r *certReport) flush(empty string) {
+	if len(r.rows) == 0 {
+		r.sb.WriteString("  " + empty + "\n")
+		return
+	}
+	r.sb.WriteString(util.FormatTable([]string{"SOURCE", "SUBJECT", "ISSUER", "NOT AFTER", "DAYS LEFT", "STATUS"}, r.rows))
+	r.rows = nil
+}
+
+func registerCertificateTools(server *mcp.Server, client *k8s.ClusterClient) {
+	// check_certificates
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "check_certificates",
+		Description: "Scan certificates for expiry and misconfiguration: kubernetes.io/tls Secrets referenced by Ingresses " +
+			"(expiry, SAN mismatch with Ingress hosts, key mismatch, chain problems), admission webhook caBundles, " +
+			"and APIService caBundles. Includes cert-manager Certificate/CertificateRequest/Issuer Ready status and renewal times when installed.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input checkCertificatesInput) (*mcp.CallToolResult, any, error) {
+		ns := util.NamespaceOrAll(input.Namespace)
+
+		ingresses, err := client.ListIngresses(ctx, ns, metav1.ListOptions{})
+		if err != nil {
+			return util.HandleK8sError("listing ingresses", err), nil, nil
+		}
+
+		var sb strings.Builder
+		sb.WriteString(util.FormatHeader(fmt.Sprintf("Certificate Check (namespace: %s)", displayNS(input.Namespace))))
+		sb.WriteString("\n\n")
+		r := &certReport{sb: &sb, now: time.Now()}
+
+		// 1. Ingress TLS secrets
+		sb.WriteString(util.FormatSubHeader("Ingress TLS Secrets"))
+		sb.WriteString("\n")
+		hostsBySecret := make(map[string][]string)
+		ingressesBySecret := make(map[string][]string)
+		for _, ing := range ingresses {
+			for _, t := range ing.Spec.TLS {
+				if t.SecretName == "" {
+					sb.WriteString(fmt.Sprintf("  Ingress %s/%s has a TLS entry without secretName (controller default certificate)\n", ing.Namespace, ing.Name))
+					continue
+				}
+				key := ing.Namespace + "/" + t.SecretName
+				hostsBySecret[key] = append(hostsBySecret[key], t.Hosts...)
+				ingressesBySecret[key] = append(ingressesBySecret[key], ing.Name)
+			}
+		}
+		secretKeys := make([]string, 0, len(hostsBySecret))
+		for key := range hostsBySecret {
+			secretKeys = append(secretKeys, key)
+		}
+		sort.Strings(secretKeys)
+		for _, key := range secretKeys {
+			parts := strings.SplitN(key, "/", 2)
+			source := "secret/" + key
+			secret, err := client.GetSecret(ctx, parts[0], parts[1])
+			if err != nil {
+				r.finding("CRITICAL", fmt.Sprintf("%s referenced by Ingress %s could not be read: %v", source, strings.Join(dedupe(ingressesBySecret[key]), ","), err))
+				r.actions = append(r.actions, fmt.Sprintf("Create TLS secret %s (or fix the Ingress secretName)", key))
+				continue
+			}
+			if secret.Type != corev1.SecretTypeTLS {
+				r.finding("WARNING", fmt.Sprintf("%s has type %s, expected %s", source, secret.Type, corev1.SecretTypeTLS))
+			}
+			certs, err := util.ParsePEMCertificates(secret.Data[corev1.TLSCertKey])
+			if err != nil {
+				r.finding("CRITICAL", fmt.Sprintf("%s: %s is not a valid certificate: %v", source, corev1.TLSCertKey, err))
+				continue
+			}
+			leaf := certs[0]
+			r.addCert(source, leaf)
+
+			if _, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey]); err != nil {
+				r.finding("CRITICAL", fmt.Sprintf("%s: private key does not match the certificate: %v", source, err))
+			}
+			for _, host := range dedupe(hostsBySecret[key]) {
+				if err := leaf.VerifyHostname(host); err != nil {
+					r.finding("CRITICAL", fmt.Sprintf("%s: SAN mismatch — host '%s' not covered (SANs: %s)", source, host, strings.Join(leaf.DNSNames, ", ")))
+					r.actions = append(r.actions, fmt.Sprintf("Reissue %s with SANs covering all Ingress TLS hosts", key))
+				}
+			}
+			for _, problem := range util.CertificateChainProblems(certs, r.now) {
+				r.finding("WARNING", fmt.Sprintf("%s: chain problem: %s", source, problem))
+			}
+			if len(certs) == 1 && util.IsSelfSigned(leaf) {
+				r.finding("WARNING", fmt.Sprintf("%s: certificate is self-signed — clients will not trust it", source))
+			}
+			if caData := secret.Data["ca.crt"]; len(caData) > 0 {
+				if err := verifyAgainstCA(certs, caData, r.now); err != nil {
+					r.finding("WARNING", fmt.Sprintf("%s: certificate does not verify against ca.crt: %v", source, err))
+				}
+			}
+		}
+		r.flush("No Ingress TLS secrets found.")
+
+		// 2. Admission webhook caBundles
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Admission Webhook CA Bundles"))
+		sb.WriteString("\n")
+		if mutating, err := client.ListMutatingWebhookConfigurations(ctx); err != nil {
+			sb.WriteString(fmt.Sprintf("  (unable to list mutating webhooks: %v)\n", err))
+		} else {
+			for _, cfg := range mutating {
+				for _, wh := range cfg.Webhooks {
+					r.checkWebhookBundle("mutating/"+cfg.Name+"/"+wh.Name, wh.ClientConfig, cfg.Annotations)
+				}
+			}
+		}
+		if validating, err := client.ListValidatingWebhookConfigurations(ctx); err != nil {
+			sb.WriteString(fmt.Sprintf("  (unable to list validating webhooks: %v)\n", err))
+		} else {
+			for _, cfg := range validating {
+				for _, wh := range cfg.Webhooks {
+					r.checkWebhookBundle("validating/"+cfg.Name+"/"+wh.Name, wh.ClientConfig, cfg.Annotations)
+				}
+			}
+		}
+		r.flush("No webhook CA bundles found.")
+
+		// 3. APIService caBundles
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("APIService CA Bundles"))
+		sb.WriteString("\n")
+		apiServices, err := client.ListAPIServices(ctx)
+		if err != nil {
+			sb.WriteString(fmt.Sprintf("  (unable to list APIServices: %v)\n", err))
+		} else {
+			for i := range apiServices {
+				svc := &apiServices[i]
+				if _, found, _ := unstructured.NestedMap(svc.Object, "spec", "service"); !found {
+					continue // served locally by kube-apiserver
+				}
+				source := "apiservice/" + svc.GetName()
+				if skip, _, _ := unstructured.NestedBool(svc.Object, "spec", "insecureSkipTLSVerify"); skip {
+					r.finding("WARNING", fmt.Sprintf("%s: insecureSkipTLSVerify is enabled — the API server does not verify the backend certificate", source))
+					continue
+				}
+				bundle, _, _ := unstructured.NestedString(svc.Object, "spec", "caBundle")
+				if bundle == "" {
+					r.finding("WARNING", fmt.Sprintf("%s: no caBundle configured", source))
+					continue
+				}
+				data, err := base64.StdEncoding.DecodeString(bundle)
+				if err != nil {
+					r.finding("WARNING", fmt.Sprintf("%s: caBundle is not valid base64: %v", source, err))
+					continue
+				}
+				certs, err := util.ParsePEMCertificates(data)
+				if err != nil {
+					r.finding("WARNING", fmt.Sprintf("%s: caBundle is not valid PEM: %v", source, err))
+					continue
+				}
+				for _, cert := range certs {
+					r.addCert(source, cert)
+				}
+			}
+			r.flush("No aggregated APIServices with CA bundles found.")
+		}
+
+		// 4. cert-manager
+		crds, _ := client.ListCRDs(ctx)
+		if certCRD := k8s.FindCRD(crds, certManagerCertificateCRD); certCRD != nil {
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("cert-manager"))
+			sb.WriteString("\n")
+			r.checkCertManager(ctx, client, crds, certCRD, ns)
+		}
+
+		// Summary
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Summary"))
+		sb.WriteString("\n")
+		if r.findings == 0 {
+			sb.WriteString("  No certificate issues found.\n")
+		} else {
+			sb.WriteString(fmt.Sprintf("  %d finding(s) identified. Review details above.\n", r.findings))
+		}
+		if len(r.actions) > 0 {
+			sb.WriteString("\nSUGGESTED ACTIONS:\n")
+			for i, a := range dedupe(r.actions) {
+				sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, a))
+			}
+		}
+
+		return util.SuccessResult(sb.String()), nil, nil
+	})
+}
+
+// checkWebhookBundle parses a webhook caBundle and reports expiry or missing bundles.
+func (r *certReport) checkWebhookBundle(source string, cc admissionregistrationv1.WebhookClientConfig, annotations map[string]string) {
+	if len(cc.CABundle) == 0 {
+		if cc.Service == nil {
+			return // URL webhooks may rely on publicly trusted certificates
+		}
+		if from := annotations[certManagerInjectAnnotation]; from != "" {
+			r.finding("WARNING", fmt.Sprintf("%s: caBundle is empty — cert-manager cainjector has not injected CA from %s", source, from))
+		} else {
+			r.finding("WARNING", fmt.Sprintf("%s: caBundle is empty for a service webhook — API server calls will fail TLS verification", source))
+		}
+		return
+	}
+	certs, err := util.ParsePEMCertificates(cc.CABundle)
+	if err != nil {
+		r.finding("WARNING", fmt.Sprintf("%s: caBundle is not valid PEM: %v", source, err))
+		return
+	}
+	for _, cert := range certs {
+		r.addCert(source, cert)
+	}
+}
+
+// checkCertManager reports cert-manager Certificate, CertificateRequest and Issuer readiness.
+func (r *certReport) checkCertManager(ctx context.Context, client *k8s.ClusterClient, crds []apiextensionsv1.CustomResourceDefinition, certCRD *apiextensionsv1.CustomResourceDefinition, ns string) {
+	sb := r.sb
+
+	certs, err := client.ListCustomResources(ctx, k8s.CRDGroupVersionResource(certCRD), ns)
+	if err != nil {
+		sb.WriteString(fmt.Sprintf("  (unable to list Certificates: %v)\n", err))
+	} else {
+		headers := []string{"CERTIFICATE", "NAMESPACE", "SECRET", "READY", "NOT AFTER", "RENEWAL"}
+		rows := make([][]string, 0, len(certs))
+		for i := range certs {
+			c := &certs[i]
+			secretName, _, _ := unstructured.NestedString(c.Object, "spec", "secretName")
+			notAfter, _, _ := unstructured.NestedString(c.Object, "status", "notAfter")
+			renewal, _, _ := unstructured.NestedString(c.Object, "status", "renewalTime")
+			status, reason, message := unstructuredCondition(c, "Ready")
+			ready := status
+			if reason != "" {
+				ready += " (" + reason + ")"
+			}
+			rows = append(rows, []string{c.GetName(), c.GetNamespace(), secretName, ready, valueOrNone(notAfter), valueOrNone(renewal)})
+
+			id := fmt.Sprintf("certificate %s/%s", c.GetNamespace(), c.GetName())
+			if status != "True" {
+				r.finding("WARNING", fmt.Sprintf("%s is not Ready: %s", id, valueOrNone(message)))
+				r.actions = append(r.actions, fmt.Sprintf("Inspect %s with 'kubectl describe certificate -n %s %s' and its latest CertificateRequest", id, c.GetNamespace(), c.GetName()))
+			}
+			if t, err := time.Parse(time.RFC3339, renewal); err == nil && r.now.After(t.Add(time.Hour)) {
+				r.finding("WARNING", fmt.Sprintf("%s renewal is overdue (renewalTime %s)", id, renewal))
+			}
+		}
+		if len(rows) == 0 {
+			sb.WriteString("  No cert-manager Certificates found.\n")
+		} else {
+			sb.WriteString(util.FormatTable(headers, rows))
+		}
+	}
+
+	if crd := k8s.FindCRD(crds, certManagerCertificateRequestCRD); crd != nil {
+		requests, err := client.ListCustomResources(ctx, k8s.CRDGroupVersionResource(crd), ns)
+		if err == nil {
+			for i := range requests {
+				cr := &requests[i]
+				status, reason, message := unstructuredCondition(cr, "Ready")
+				if status == "False" && (reason == "Failed" || reason == "Denied") {
+					r.finding("WARNING", fmt.Sprintf("CertificateRequest %s/%s %s: %s", cr.GetNamespace(), cr.GetName(), reason, message))
+				}
+			}
+		}
+	}
+
+	for _, name := range []string{certManagerIssuerCRD, certManagerClusterIssuerCRD} {
+		crd := k8s.FindCRD(crds, name)
+		if crd == nil {
+			continue
+		}
+		issuerNS := ns
+		if crd.Spec.Names.Kind == "ClusterIssuer" {
+			issuerNS = ""
+		}
+		issuers, err := client.ListCustomResources(ctx, k8s.CRDGroupVersionResource(crd), issuerNS)
+		if err != nil {
+			continue
+		}
+		for i := range issuers {
+			is := &issuers[i]
+			if status, _, message := unstructuredCondition(is, "Ready"); status != "True" {
+				id := crd.Spec.Names.Kind + " " + is.GetName()
+				if is.GetNamespace() != "" {
+					id = crd.Spec.Names.Kind + " " + is.GetNamespace() + "/" + is.GetName()
+				}
+				r.finding("CRITICAL", fmt.Sprintf("%s is not Ready: %s", id, valueOrNone(message)))
+				r.actions = append(r.actions, fmt.Sprintf("Fix %s — no certificates can be issued or renewed from it", id))
+			}
+		}
+	}
+}
+
+// verifyAgainstCA verifies the leaf certificate against the CA bundle, using any further chain entries as intermediates.
+func verifyAgainstCA(chain []*x509.Certificate, caData []byte, now time.Time) error {
+	cas, err := util.ParsePEMCertificates(caData)
+	if err != nil {
+		return err
+	}
+	roots := x509.NewCertPool()
+	for _, ca := range cas {
+		roots.AddCert(ca)
+	}
+	intermediates := x509.NewCertPool()
+	for _, c := range chain[1:] {
+		intermediates.AddCert(c)
+	}
+	_, err = chain[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates, CurrentTime: now, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}})
+	return err
+}
+
+// unstructuredCondition returns the status, reason and message of a status condition ("Unknown" if absent).
+func unstructuredCondition(obj *unstructured.Unstructured, condType string) (status, reason, message string) {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok || cond["type"] != condType {
+			continue
+		}
+		status, _ = cond["status"].(string)
+		reason, _ = cond["reason"].(string)
+		message, _ = cond["message"].(string)
+		return status, reason, message
+	}
+	return "Unknown", "", ""
+}
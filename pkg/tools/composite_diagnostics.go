@@ -12,6 +12,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/health"
 	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
 	"github.com/pat-nel87/kube-doctor-mcp/pkg/mermaid"
 	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
@@ -20,7 +21,7 @@ import (
 type diagnoseRequestPathInput struct {
 	Hostname  string `json:"hostname" jsonschema:"required,Hostname to trace (e.g. api.example.com)"`
 	Path      string `json:"path,omitempty" jsonschema:"URL path to trace (e.g. /payments/v1/charge). Default: /"`
-	Namespace string `json:"namespace,omitempty" jsonschema:"Namespace to search for Ingress (empty = all)"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"Namespace to search for Ingress or route (empty = all)"`
 }
 
 type diagnoseServiceInput struct {
@@ -42,7 +43,9 @@ func registerCompositeDiagnosticTools(server *mcp.Server, client *k8s.ClusterCli
 	mcp.AddTool(server, &mcp.Tool{
 		Name: "diagnose_request_path",
 		Description: "Trace and diagnose the full request path from a hostname through Ingress → Service → Endpoints → Pods. " +
+			"Falls back to Gateway API (Gateway → HTTPRoute/GRPCRoute → Service) when no Ingress matches. " +
 			"Checks health at every layer, validates AGIC/Ingress annotations, analyzes resource usage, " +
+			"checks Istio/Linkerd sidecars, mTLS, VirtualService/DestinationRule subsets and AuthorizationPolicies, " +
 			"and generates Mermaid topology + sequence diagrams. THE PRIMARY tool for debugging why a URL is not working.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input diagnoseRequestPathInput) (*mcp.CallToolResult, any, error) {
 		path := input.Path
@@ -63,12 +66,17 @@ func registerCompositeDiagnosticTools(server *mcp.Server, client *k8s.ClusterCli
 		// --- [1] FIND INGRESS ---
 		ing, rule, matchedPath, err := client.FindIngressForHostPath(ctx, ns, input.Hostname, path)
 		if err != nil {
-			sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("No Ingress found for %s%s", input.Hostname, path)))
+			// Fall back to Gateway API routes
+			if gwRes, gwMatch := findGatewayRoute(ctx, client, ns, input.Hostname, path); gwMatch != nil {
+				writeGatewayRouteFallback(ctx, client, &sb, gwRes, gwMatch, input.Hostname, path)
+				return util.SuccessResult(sb.String()), nil, nil
+			}
+			sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("No Ingress or Gateway API route found for %s%s", input.Hostname, path)))
 			sb.WriteString("\n")
-			sb.WriteString("  Searched all namespaces for matching Ingress host+path rules.\n")
+			sb.WriteString("  Searched Ingress host+path rules and accepted HTTPRoute/GRPCRoute hostnames and matches.\n")
 			sb.WriteString("\nSUGGESTED ACTIONS:\n")
-			sb.WriteString("1. Create an Ingress resource with host: " + input.Hostname + " and path: " + path + "\n")
-			sb.WriteString("2. Use list_ingresses to see existing Ingress resources\n")
+			sb.WriteString("1. Create an Ingress resource or HTTPRoute with host: " + input.Hostname + " and path: " + path + "\n")
+			sb.WriteString("2. Use list_ingresses or analyze_gateway_api to see existing routes\n")
 			return util.SuccessResult(sb.String()), nil, nil
 		}
 
@@ -167,6 +175,12 @@ func registerCompositeDiagnosticTools(server *mcp.Server, client *k8s.ClusterCli
 		sb.WriteString(fmt.Sprintf("    ClusterIP: %s\n", svc.Spec.ClusterIP))
 		sb.WriteString(fmt.Sprintf("    Port: %s → targetPort: %s\n", backendSvcPort, formatServicePorts(svc)))
 		sb.WriteString(fmt.Sprintf("    Selector: %s\n", util.FormatLabels(svc.Spec.Selector)))
+		svcHealth := health.Service(svc)
+		sb.WriteString(fmt.Sprintf("    Health: %s\n", svcHealth))
+		if !svcHealth.Healthy() {
+			sb.WriteString(fmt.Sprintf("    %s\n", util.FormatFinding(healthSeverity(svcHealth), "Service is not healthy: "+svcHealth.String())))
+			findings++
+		}
 
 		// Check service events
 		svcEvents, _ := client.GetEventsForObject(ctx, ing.Namespace, backendSvcName)
@@ -208,7 +222,7 @@ func registerCompositeDiagnosticTools(server *mcp.Server, client *k8s.ClusterCli
 		pods, err := client.GetPodsForService(ctx, svc)
 		if err == nil && len(pods) > 0 {
 			sb.WriteString("\n    PODS:\n")
-			headers := []string{"POD", "NODE", "READY", "RESTARTS", "STATUS", "AGE"}
+			headers := []string{"POD", "NODE", "READY", "RESTARTS", "STATUS", "HEALTH", "AGE"}
 			rows := make([][]string, 0, len(pods))
 			for i := range pods {
 				p := &pods[i]
@@ -218,6 +232,7 @@ func registerCompositeDiagnosticTools(server *mcp.Server, client *k8s.ClusterCli
 					fmt.Sprintf("%d/%d", ready, total),
 					fmt.Sprintf("%d", restarts),
 					podPhaseReason(p),
+					string(health.Pod(p).Status),
 					util.FormatAge(p.CreationTimestamp.Time),
 				})
 			}
@@ -228,8 +243,8 @@ func registerCompositeDiagnosticTools(server *mcp.Server, client *k8s.ClusterCli
 			// Check each pod's health
 			for i := range pods {
 				p := &pods[i]
-				if !isPodHealthy(p) {
-					sb.WriteString(fmt.Sprintf("    %s\n", util.FormatFinding("CRITICAL", fmt.Sprintf("Pod '%s' is unhealthy: %s", p.Name, podPhaseReason(p)))))
+				if podHealth := health.Pod(p); !podHealth.Healthy() {
+					sb.WriteString(fmt.Sprintf("    %s\n", util.FormatFinding(healthSeverity(podHealth), fmt.Sprintf("Pod '%s' is unhealthy: %s", p.Name, podHealth))))
 					findings++
 					actions = append(actions, fmt.Sprintf("Diagnose pod '%s' with diagnose_pod tool", p.Name))
 				}
@@ -329,6 +344,16 @@ func registerCompositeDiagnosticTools(server *mcp.Server, client *k8s.ClusterCli
 			}
 		}
 
+		// --- [5] SERVICE MESH ---
+		mesh := client.AnalyzeServiceMesh(ctx, svc, pods)
+		if mesh.Meshed() {
+			checkIngressControllerMesh(ctx, client, ing, mesh)
+			sb.WriteString("\n[5] SERVICE MESH\n")
+			n, meshActions := writeServiceMeshSection(&sb, "    ", mesh)
+			findings += n
+			actions = append(actions, meshActions...)
+		}
+
 		// --- SUMMARY ---
 		sb.WriteString("\n")
 		sb.WriteString(util.FormatSubHeader("Summary"))
@@ -372,8 +397,12 @@ func registerCompositeDiagnosticTools(server *mcp.Server, client *k8s.ClusterCli
 				label = label[:30] + "..."
 			}
 			healthy := isPodHealthy(p)
-			fc.AddNode(podID, label, mermaid.ShapeRound)
-			fc.AddEdge("svc", podID, "", mermaid.EdgeSolid)
+			fc.AddNode(podID, label+podSidecarSuffix(p), mermaid.ShapeRound)
+			edgeLabel := ""
+			if mesh.Meshed() && k8s.PodSidecar(p) != nil {
+				edgeLabel = "mTLS"
+			}
+			fc.AddEdge("svc", podID, edgeLabel, mermaid.EdgeSolid)
 			if !healthy {
 				fc.AddStyle(podID, mermaid.SeverityCritical)
 			} else {
@@ -395,6 +424,9 @@ func registerCompositeDiagnosticTools(server *mcp.Server, client *k8s.ClusterCli
 			if len(podLabel) > 25 {
 				podLabel = podLabel[:25] + "..."
 			}
+			if mesh.Meshed() {
+				seq.AddParticipant("proxy", fmt.Sprintf("Sidecar: %s", mesh.SidecarContainer()))
+			}
 			seq.AddParticipant("pod", fmt.Sprintf("Pod: %s", podLabel))
 
 			seq.AddMessage("client", "ing", fmt.Sprintf("HTTPS %s %s", "GET", path), mermaid.MsgSolid)
@@ -421,8 +453,13 @@ func registerCompositeDiagnosticTools(server *mcp.Server, client *k8s.ClusterCli
 			}
 			seq.AddNote("svc", svcNote, mermaid.NoteOver)
 
-			seq.AddMessage("svc", "pod", "Forward to pod", mermaid.MsgSolid)
-			seq.AddMessage("pod", "svc", "Response", mermaid.MsgDotted)
+			if addMeshSequenceHop(seq, mesh, "svc", "proxy", "pod") {
+				seq.AddMessage("pod", "proxy", "Response", mermaid.MsgDotted)
+				seq.AddMessage("proxy", "svc", "Response", mermaid.MsgDotted)
+			} else {
+				seq.AddMessage("svc", "pod", "Forward to pod", mermaid.MsgSolid)
+				seq.AddMessage("pod", "svc", "Response", mermaid.MsgDotted)
+			}
 			seq.AddMessage("svc", "ing", "Response", mermaid.MsgDotted)
 			seq.AddMessage("ing", "client", "Response", mermaid.MsgDotted)
 		}
@@ -436,7 +473,7 @@ func registerCompositeDiagnosticTools(server *mcp.Server, client *k8s.ClusterCli
 	mcp.AddTool(server, &mcp.Tool{
 		Name: "diagnose_service",
 		Description: "Everything about a single Kubernetes service: endpoint health, backing pod status, resource usage, " +
-			"Ingress exposure, network policies, events, and Mermaid dependency diagram. " +
+			"Ingress exposure, LoadBalancer/NodePort exposure, network policies, events, and Mermaid dependency diagram. " +
 			"Use this as the primary tool for investigating service-level issues.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input diagnoseServiceInput) (*mcp.CallToolResult, any, error) {
 		svc, err := client.GetService(ctx, input.Namespace, input.ServiceName)
@@ -458,6 +495,12 @@ func registerCompositeDiagnosticTools(server *mcp.Server, client *k8s.ClusterCli
 		sb.WriteString(fmt.Sprintf("  Selector: %s\n", util.FormatLabels(svc.Spec.Selector)))
 		sb.WriteString(fmt.Sprintf("  Session Affinity: %s\n", svc.Spec.SessionAffinity))
 		sb.WriteString(fmt.Sprintf("  Age: %s\n", util.FormatAge(svc.CreationTimestamp.Time)))
+		svcHealth := health.Service(svc)
+		sb.WriteString(fmt.Sprintf("  Health: %s\n", svcHealth))
+		if !svcHealth.Healthy() {
+			sb.WriteString(fmt.Sprintf("  %s\n", util.FormatFinding(healthSeverity(svcHealth), "Service is not healthy: "+svcHealth.String())))
+			findings++
+		}
 
 		// 2. Endpoint health
 		sb.WriteString("\n")
@@ -487,30 +530,28 @@ func registerCompositeDiagnosticTools(server *mcp.Server, client *k8s.ClusterCli
 			sb.WriteString(util.FormatSubHeader("Backing Pods"))
 			sb.WriteString("\n")
 
-			headers := []string{"POD", "STATUS", "READY", "RESTARTS", "NODE", "AGE"}
+			headers := []string{"POD", "STATUS", "HEALTH", "READY", "RESTARTS", "NODE", "AGE"}
 			rows := make([][]string, 0, len(pods))
+			unhealthyPods := 0
 			for i := range pods {
 				p := &pods[i]
 				ready, total, restarts := podContainerSummary(p)
+				podHealth := health.Pod(p)
 				rows = append(rows, []string{
 					p.Name, podPhaseReason(p),
+					string(podHealth.Status),
 					fmt.Sprintf("%d/%d", ready, total),
 					fmt.Sprintf("%d", restarts),
 					p.Spec.NodeName,
 					util.FormatAge(p.CreationTimestamp.Time),
 				})
-				if !isPodHealthy(p) {
+				if !podHealth.Healthy() {
+					unhealthyPods++
 					findings++
 				}
 			}
 			sb.WriteString(util.FormatTable(headers, rows))
 
-			unhealthyPods := 0
-			for i := range pods {
-				if !isPodHealthy(&pods[i]) {
-					unhealthyPods++
-				}
-			}
 			if unhealthyPods > 0 {
 				sb.WriteString(fmt.Sprintf("\n  %s\n", util.FormatFinding("CRITICAL", fmt.Sprintf("%d/%d pods are unhealthy", unhealthyPods, len(pods)))))
 			}
@@ -582,6 +623,24 @@ func registerCompositeDiagnosticTools(server *mcp.Server, client *k8s.ClusterCli
 			}
 		}
 
+		// 5b. LoadBalancer / NodePort exposure
+		if svc.Spec.Type == corev1.ServiceTypeLoadBalancer || svc.Spec.Type == corev1.ServiceTypeNodePort {
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("External Exposure"))
+			sb.WriteString("\n")
+			exposure, err := client.AnalyzeServiceExposure(ctx, svc)
+			if err != nil {
+				sb.WriteString(fmt.Sprintf("  Could not analyze exposure: %v\n", err))
+			} else {
+				sb.WriteString(fmt.Sprintf("  External Address: %s\n", valueOrNone(strings.Join(exposure.ExternalAddresses, ", "))))
+				n, _ := writeExposureIssues(&sb, "  ", exposure.Issues)
+				findings += n
+				if len(exposure.Issues) > 0 {
+					sb.WriteString("  Run diagnose_service_exposure for node ports, annotations and load balancer events.\n")
+				}
+			}
+		}
+
 		// 6. Network policies
 		netPols, err := client.ListNetworkPolicies(ctx, input.Namespace, metav1.ListOptions{})
 		if err == nil {
@@ -10,6 +10,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/health"
 	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
 	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
 )
@@ -50,8 +51,11 @@ func registerDiagnosticTools(server *mcp.Server, client *k8s.ClusterClient) {
 
 		// Status summary
 		phase := podPhaseReason(pod)
+		podHealth := health.Pod(pod)
 		sb.WriteString(util.FormatKeyValue("STATUS", phase))
 		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("HEALTH", podHealth.String()))
+		sb.WriteString("\n")
 		_, _, restarts := podContainerSummary(pod)
 		sb.WriteString(util.FormatKeyValue("RESTARTS", fmt.Sprintf("%d", restarts)))
 		sb.WriteString("\n")
@@ -132,7 +136,13 @@ func registerDiagnosticTools(server *mcp.Server, client *k8s.ClusterClient) {
 		}
 
 		if findings == 0 {
-			sb.WriteString("  No issues found - pod appears healthy.\n")
+			if podHealth.Healthy() {
+				sb.WriteString("  No issues found - pod appears healthy.\n")
+			} else {
+				sb.WriteString(util.FormatFinding(healthSeverity(podHealth), "Pod is not healthy: "+podHealth.String()))
+				sb.WriteString("\n")
+				findings++
+			}
 		}
 
 		// Warning events
@@ -190,7 +200,7 @@ func registerDiagnosticTools(server *mcp.Server, client *k8s.ClusterClient) {
 			if cs.State.Waiting != nil {
 				switch cs.State.Waiting.Reason {
 				case "ImagePullBackOff", "ErrImagePull":
-					sb.WriteString(fmt.Sprintf("%d. Check image name and registry credentials for container '%s'\n", actionNum, cs.Name))
+					sb.WriteString(fmt.Sprintf("%d. Run diagnose_image_pull to find why container '%s' cannot pull its image\n", actionNum, cs.Name))
 					actionNum++
 				case "CrashLoopBackOff":
 					sb.WriteString(fmt.Sprintf("%d. Check application logs for container '%s' (use get_pod_logs with previous=true)\n", actionNum, cs.Name))
@@ -203,7 +213,11 @@ func registerDiagnosticTools(server *mcp.Server, client *k8s.ClusterClient) {
 			actionNum++
 		}
 		if actionNum == 1 {
-			sb.WriteString("  No specific actions needed - pod is healthy.\n")
+			if podHealth.Healthy() {
+				sb.WriteString("  No specific actions needed - pod is healthy.\n")
+			} else {
+				sb.WriteString(fmt.Sprintf("%d. Review the findings and events above — pod health is %s\n", actionNum, podHealth.Status))
+			}
 		}
 
 		return util.SuccessResult(sb.String()), nil, nil
@@ -212,7 +226,7 @@ func registerDiagnosticTools(server *mcp.Server, client *k8s.ClusterClient) {
 	// diagnose_namespace
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "diagnose_namespace",
-		Description: "Health check an entire namespace. Finds unhealthy pods, failing deployments, pending PVCs, warning events, and pods with high restart counts. Use this to quickly assess namespace health.",
+		Description: "Health check an entire namespace. Finds unhealthy pods, workloads that are not Current (deployments, statefulsets, daemonsets, failed jobs), unbound PVCs, warning events, and pods with high restart counts. Use this to quickly assess namespace health.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input diagnoseNamespaceInput) (*mcp.CallToolResult, any, error) {
 		var sb strings.Builder
 		sb.WriteString(util.FormatHeader(fmt.Sprintf("Namespace Diagnosis: %s", input.Namespace)))
@@ -267,33 +281,47 @@ func registerDiagnosticTools(server *mcp.Server, client *k8s.ClusterClient) {
 			findings++
 		}
 
-		// 2. Check deployments
-		deployments, err := client.ListDeployments(ctx, input.Namespace, metav1.ListOptions{})
-		if err == nil {
-			failingDeploys := 0
-			for _, d := range deployments {
-				desired := int32(0)
-				if d.Spec.Replicas != nil {
-					desired = *d.Spec.Replicas
+		// 2. Check workloads
+		type unhealthyWorkload struct {
+			ref    string
+			result health.Result
+		}
+		var unhealthy []unhealthyWorkload
+		if deployments, err := client.ListDeployments(ctx, input.Namespace, metav1.ListOptions{}); err == nil {
+			for i := range deployments {
+				if r := health.Deployment(&deployments[i]); !r.Healthy() {
+					unhealthy = append(unhealthy, unhealthyWorkload{"Deployment/" + deployments[i].Name, r})
 				}
-				if d.Status.AvailableReplicas < desired {
-					failingDeploys++
+			}
+		}
+		if statefulSets, err := client.ListStatefulSets(ctx, input.Namespace, metav1.ListOptions{}); err == nil {
+			for i := range statefulSets {
+				if r := health.StatefulSet(&statefulSets[i]); !r.Healthy() {
+					unhealthy = append(unhealthy, unhealthyWorkload{"StatefulSet/" + statefulSets[i].Name, r})
 				}
 			}
-			if failingDeploys > 0 {
-				sb.WriteString(fmt.Sprintf("\n%s\n", util.FormatFinding("WARNING", fmt.Sprintf("%d deployments with unavailable replicas", failingDeploys))))
-				for _, d := range deployments {
-					desired := int32(0)
-					if d.Spec.Replicas != nil {
-						desired = *d.Spec.Replicas
-					}
-					if d.Status.AvailableReplicas < desired {
-						sb.WriteString(fmt.Sprintf("  - %s: %d/%d available\n", d.Name, d.Status.AvailableReplicas, desired))
-					}
+		}
+		if daemonSets, err := client.ListDaemonSets(ctx, input.Namespace, metav1.ListOptions{}); err == nil {
+			for i := range daemonSets {
+				if r := health.DaemonSet(&daemonSets[i]); !r.Healthy() {
+					unhealthy = append(unhealthy, unhealthyWorkload{"DaemonSet/" + daemonSets[i].Name, r})
 				}
-				findings++
 			}
 		}
+		if jobs, err := client.ListJobs(ctx, input.Namespace, metav1.ListOptions{}); err == nil {
+			for i := range jobs {
+				if r := health.Job(&jobs[i]); r.Status == health.Failed {
+					unhealthy = append(unhealthy, unhealthyWorkload{"Job/" + jobs[i].Name, r})
+				}
+			}
+		}
+		if len(unhealthy) > 0 {
+			sb.WriteString(fmt.Sprintf("\n%s\n", util.FormatFinding("WARNING", fmt.Sprintf("%d workloads not healthy", len(unhealthy)))))
+			for _, w := range unhealthy {
+				sb.WriteString(fmt.Sprintf("  - %s: %s\n", w.ref, w.result))
+			}
+			findings++
+		}
 
 		// 3. Warning events in last hour
 		events, err := client.ListEvents(ctx, input.Namespace, metav1.ListOptions{})
@@ -315,22 +343,18 @@ func registerDiagnosticTools(server *mcp.Server, client *k8s.ClusterClient) {
 			}
 		}
 
-		// 4. Pending PVCs
+		// 4. Unbound PVCs
 		pvcs, err := client.ListPVCs(ctx, input.Namespace, metav1.ListOptions{})
 		if err == nil {
-			pendingPVCs := 0
-			for _, pvc := range pvcs {
-				if pvc.Status.Phase != corev1.ClaimBound {
-					pendingPVCs++
+			var notBound []string
+			for i := range pvcs {
+				if r := health.PVC(&pvcs[i]); !r.Healthy() {
+					notBound = append(notBound, fmt.Sprintf("  - %s: %s\n", pvcs[i].Name, r))
 				}
 			}
-			if pendingPVCs > 0 {
-				sb.WriteString(fmt.Sprintf("\n%s\n", util.FormatFinding("WARNING", fmt.Sprintf("%d PVCs not bound", pendingPVCs))))
-				for _, pvc := range pvcs {
-					if pvc.Status.Phase != corev1.ClaimBound {
-						sb.WriteString(fmt.Sprintf("  - %s: %s\n", pvc.Name, pvc.Status.Phase))
-					}
-				}
+			if len(notBound) > 0 {
+				sb.WriteString(fmt.Sprintf("\n%s\n", util.FormatFinding("WARNING", fmt.Sprintf("%d PVCs not bound", len(notBound)))))
+				sb.WriteString(strings.Join(notBound, ""))
 				findings++
 			}
 		}
@@ -614,23 +638,17 @@ func registerDiagnosticTools(server *mcp.Server, client *k8s.ClusterClient) {
 	})
 }
 
-// isPodHealthy returns true if the pod is in a healthy state.
-func isPodHealthy(p *corev1.Pod) bool {
-	// Running and all containers ready
-	if p.Status.Phase == corev1.PodRunning {
-		for _, cs := range p.Status.ContainerStatuses {
-			if !cs.Ready {
-				return false
-			}
-			if cs.State.Waiting != nil {
-				return false
-			}
-		}
-		return true
+// healthSeverity is the finding severity for an object that is not Current:
+// CRITICAL when it has Failed, WARNING while it is in progress, terminating or unknown.
+func healthSeverity(r health.Result) string {
+	if r.Status == health.Failed {
+		return "CRITICAL"
 	}
-	// Succeeded (completed jobs) are healthy
-	if p.Status.Phase == corev1.PodSucceeded {
-		return true
-	}
-	return false
+	return "WARNING"
+}
+
+// isPodHealthy returns true if the pod is Current according to health.Pod:
+// Running with every container ready, or Succeeded.
+func isPodHealthy(p *corev1.Pod) bool {
+	return health.Pod(p).Healthy()
 }
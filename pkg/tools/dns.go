@@ -0,0 +1,265 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+// maxDNSPodFindings caps the per-pod DNS settings findings listed by check_dns_health.
+const maxDNSPodFindings = 20
+
+// dnsSection accumulates the findings and actions of one check_dns_health section.
+type dnsSection struct {
+	sb       *strings.Builder
+	findings int
+	actions  []string
+}
+
+func (d *dnsSection) finding(severity, msg string) {
+	d.sb.WriteString(util.FormatFinding(severity, msg))
+	d.sb.WriteString("\n")
+	d.findings++
+}
+
+// writeDNSServiceEndpoints checks that the kube-dns Service has ready endpoints on 53/UDP and 53/TCP.
+func writeDNSServiceEndpoints(ctx context.Context, client *k8s.ClusterClient, d *dnsSection, svc *corev1.Service) {
+	d.sb.WriteString(util.FormatSubHeader("kube-dns Service Endpoints"))
+	d.sb.WriteString("\n")
+	if svc == nil {
+		d.sb.WriteString("  Skipped: kube-dns Service not found.\n")
+		return
+	}
+
+	protocols := make(map[corev1.Protocol]bool)
+	for _, p := range svc.Spec.Ports {
+		if p.Port == 53 {
+			protocols[p.Protocol] = true
+		}
+	}
+	if !protocols[corev1.ProtocolUDP] {
+		d.finding("CRITICAL", "kube-dns Service does not expose 53/UDP — ordinary DNS queries will fail")
+	}
+	if !protocols[corev1.ProtocolTCP] {
+		d.finding("WARNING", "kube-dns Service does not expose 53/TCP — truncated (large) answers cannot be retried over TCP")
+	}
+
+	health, err := client.GetServiceEndpointHealth(ctx, svc.Namespace, svc.Name)
+	if err != nil {
+		d.finding("CRITICAL", fmt.Sprintf("Could not read kube-dns endpoints: %v", err))
+		return
+	}
+	d.sb.WriteString(util.FormatKeyValue("Endpoints", fmt.Sprintf("%d ready, %d not ready, %d terminating (source: %s)",
+		health.ReadyCount, health.NotReadyCount, health.TerminatingCount, health.Source)))
+	d.sb.WriteString("\n")
+	switch {
+	case health.ReadyCount == 0:
+		d.finding("CRITICAL", "kube-dns Service has no ready endpoints — every in-cluster DNS query will time out")
+		d.actions = append(d.actions, "Restore CoreDNS readiness: check the coredns Deployment, its readiness probe (ready plugin) and events in kube-system")
+	case health.NotReadyCount > 0:
+		d.finding("WARNING", fmt.Sprintf("%d kube-dns endpoint(s) not ready — a share of queries may time out", health.NotReadyCount))
+	}
+}
+
+// writeCorefileSection summarises forwarders, stub domains, rewrites and cache settings and
+// reports Corefile problems. It returns the parsed Corefile, or nil when unavailable.
+func writeCorefileSection(ctx context.Context, client *k8s.ClusterClient, d *dnsSection) *k8s.Corefile {
+	d.sb.WriteString(util.FormatSubHeader("CoreDNS Configuration (Corefile)"))
+	d.sb.WriteString("\n")
+	cf, err := client.GetCorefile(ctx)
+	if err != nil {
+		d.finding("INFO", fmt.Sprintf("Corefile not available: %v", err))
+		return nil
+	}
+
+	d.sb.WriteString(util.FormatKeyValue("Source", cf.Source))
+	d.sb.WriteString("\n")
+	d.sb.WriteString(util.FormatKeyValue("Cluster Domain", cf.ClusterDomain()))
+	d.sb.WriteString("\n")
+
+	rows := make([][]string, 0, len(cf.Servers))
+	for _, s := range cf.Servers {
+		forward, cache := "<none>", "<none>"
+		if p := s.Plugin("forward"); p != nil {
+			forward = strings.Join(p.Args[1:], " ")
+			for _, opt := range p.Block {
+				if strings.HasPrefix(opt, "policy") || strings.HasPrefix(opt, "max_concurrent") {
+					forward += " (" + opt + ")"
+				}
+			}
+		}
+		if p := s.Plugin("cache"); p != nil {
+			cache = valueOrNone(strings.Join(p.Args, " "))
+			if len(p.Args) == 0 {
+				cache = "default"
+			}
+		}
+		plugins := make([]string, 0, len(s.Plugins))
+		for _, p := range s.Plugins {
+			plugins = append(plugins, p.Name)
+		}
+		rows = append(rows, []string{strings.Join(s.Zones, " ") + ":" + s.Port, forward, cache, strings.Join(plugins, ",")})
+	}
+	d.sb.WriteString(util.FormatTable([]string{"ZONES", "FORWARD-TO", "CACHE", "PLUGINS"}, rows))
+
+	if stubs := cf.StubDomains(); len(stubs) > 0 {
+		d.sb.WriteString("\n  Stub domains:\n")
+		for _, s := range stubs {
+			d.sb.WriteString(fmt.Sprintf("    %s -> %s\n", strings.Join(s.Zones, " "), strings.Join(s.Plugin.Args[1:], " ")))
+		}
+	}
+	if rewrites := cf.Directives("rewrite"); len(rewrites) > 0 {
+		d.sb.WriteString("\n  Rewrite rules:\n")
+		for _, r := range rewrites {
+			d.sb.WriteString(fmt.Sprintf("    %s\n", r.Plugin.Line()))
+		}
+	}
+
+	d.sb.WriteString("\n")
+	for _, issue := range k8s.CheckCorefile(cf) {
+		if issue.Severity == "INFO" && strings.HasPrefix(issue.Problem, "Rewrite rule") {
+			// Already listed above; note without counting as a finding.
+			continue
+		}
+		d.finding(issue.Severity, issue.Problem)
+		if issue.Severity != "INFO" {
+			d.actions = append(d.actions, "Fix the Corefile in "+cf.Source+" (kubectl -n kube-system edit configmap coredns) and let the reload plugin pick it up")
+		}
+	}
+	return cf
+}
+
+// writeNodeLocalDNSSection reports whether NodeLocal DNSCache is installed and fully scheduled.
+func writeNodeLocalDNSSection(ctx context.Context, client *k8s.ClusterClient, d *dnsSection) {
+	d.sb.WriteString(util.FormatSubHeader("NodeLocal DNSCache"))
+	d.sb.WriteString("\n")
+	info, err := client.GetNodeLocalDNS(ctx)
+	switch {
+	case err != nil:
+		d.sb.WriteString(fmt.Sprintf("  Could not check for NodeLocal DNSCache: %v\n", err))
+	case info == nil:
+		d.sb.WriteString("  Not installed — pods query CoreDNS directly through the kube-dns Service.\n")
+	default:
+		d.sb.WriteString(util.FormatKeyValue("DaemonSet", fmt.Sprintf("kube-system/%s (%d/%d ready)", info.Name, info.Ready, info.Desired)))
+		d.sb.WriteString("\n")
+		d.sb.WriteString(util.FormatKeyValue("Local IP", valueOrNone(info.LocalIP)))
+		d.sb.WriteString("\n")
+		if info.Ready < info.Desired {
+			d.finding("WARNING", fmt.Sprintf("NodeLocal DNSCache is ready on %d of %d nodes — pods on the remaining nodes may fail DNS lookups", info.Ready, info.Desired))
+			d.actions = append(d.actions, fmt.Sprintf("Check the %s DaemonSet pods with list_pods namespace=kube-system", info.Name))
+		}
+	}
+}
+
+// writePodDNSSection checks dnsPolicy, dnsConfig and ndots across pods in namespace ("" for all).
+func writePodDNSSection(ctx context.Context, client *k8s.ClusterClient, d *dnsSection, namespace, clusterDomain string) {
+	d.sb.WriteString(util.FormatSubHeader("Pod DNS Settings"))
+	d.sb.WriteString("\n")
+	pods, err := client.ListPods(ctx, namespace, metav1.ListOptions{})
+	if err != nil {
+		d.sb.WriteString(fmt.Sprintf("  Could not list pods: %v\n", err))
+		return
+	}
+
+	policies := make(map[string]int)
+	affected, listed := 0, 0
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		settings, issues := k8s.AnalyzePodDNS(pod, clusterDomain)
+		policies[settings.Policy]++
+		if len(issues) == 0 {
+			continue
+		}
+		affected++
+		for _, issue := range issues {
+			if listed < maxDNSPodFindings {
+				d.finding(issue.Severity, fmt.Sprintf("Pod '%s/%s': %s", pod.Namespace, pod.Name, issue.Problem))
+			}
+			listed++
+		}
+	}
+
+	names := make([]string, 0, len(policies))
+	for p := range policies {
+		names = append(names, p)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, p := range names {
+		parts = append(parts, fmt.Sprintf("%s=%d", p, policies[p]))
+	}
+	d.sb.WriteString(util.FormatKeyValue("Effective Policies", valueOrNone(strings.Join(parts, ", "))))
+	d.sb.WriteString("\n")
+	if listed > maxDNSPodFindings {
+		d.sb.WriteString(fmt.Sprintf("  ... and %d more pod DNS finding(s)\n", listed-maxDNSPodFindings))
+	}
+	if affected == 0 {
+		d.sb.WriteString(fmt.Sprintf("  All %d active pod(s) use sane DNS settings.\n", len(pods)))
+	} else {
+		d.actions = append(d.actions, "Use dnsPolicy ClusterFirstWithHostNet for hostNetwork pods, keep ndots at or below 5, and use fully-qualified names (trailing dot) for hot external lookups")
+	}
+}
+
+// writeDNSResolutionSection resolves name against server the way a pod in namespace would,
+// listing every query the search path produces.
+func writeDNSResolutionSection(ctx context.Context, d *dnsSection, server, name, namespace, clusterDomain string) {
+	d.sb.WriteString(util.FormatSubHeader(fmt.Sprintf("Resolution Test: %s", name)))
+	d.sb.WriteString("\n")
+	if server == "" {
+		d.finding("WARNING", "No DNS server to query: kube-dns has no ClusterIP and dns_server was not given")
+		return
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+	searches := []string{namespace + ".svc." + clusterDomain, "svc." + clusterDomain, clusterDomain}
+	d.sb.WriteString(util.FormatKeyValue("Server", server))
+	d.sb.WriteString("\n")
+	d.sb.WriteString(util.FormatKeyValue("Search Path", fmt.Sprintf("%s (ndots:%d, as a pod in '%s')", strings.Join(searches, " "), k8s.DefaultNdots, namespace)))
+	d.sb.WriteString("\n")
+
+	results := k8s.ResolveWithSearch(ctx, server, name, searches, k8s.DefaultNdots)
+	rows := make([][]string, 0, len(results))
+	for _, r := range results {
+		status := r.RCode
+		if r.Err != nil {
+			status = "ERROR: " + r.Err.Error()
+		}
+		rows = append(rows, []string{r.Name, status, valueOrNone(strings.Join(r.Answers, ", ")), r.Duration.Round(time.Millisecond).String()})
+	}
+	d.sb.WriteString(util.FormatTable([]string{"QUERY", "RCODE", "ANSWERS", "TIME"}, rows))
+
+	last := results[len(results)-1]
+	switch {
+	case last.Resolved():
+		if len(results) > 1 {
+			d.finding("INFO", fmt.Sprintf("'%s' resolved after %d queries (x2 with AAAA) — use '%s.' to skip the search path", name, len(results), strings.TrimSuffix(last.Name, ".")))
+		}
+	case last.Err != nil:
+		var netErr net.Error
+		if errors.As(last.Err, &netErr) && netErr.Timeout() {
+			d.finding("WARNING", fmt.Sprintf("Queries to %s timed out — the cluster DNS IP is usually only reachable from inside the cluster; pass dns_server (e.g. a port-forwarded CoreDNS) when running outside it", server))
+		} else {
+			d.finding("WARNING", fmt.Sprintf("Could not query %s: %v", server, last.Err))
+		}
+	case last.RCode == "SERVFAIL":
+		d.finding("CRITICAL", fmt.Sprintf("'%s' returned SERVFAIL — CoreDNS could not reach an upstream or the zone is broken", name))
+		d.actions = append(d.actions, "Check CoreDNS forwarders and upstream reachability for "+name)
+	default:
+		d.finding("WARNING", fmt.Sprintf("'%s' did not resolve (%s) on any of %d candidate names", name, last.RCode, len(results)))
+		d.actions = append(d.actions, fmt.Sprintf("Verify that '%s' exists (Service name and namespace, or the external record)", name))
+	}
+}
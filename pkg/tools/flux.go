@@ -14,6 +14,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/pat-nel87/kube-doctor-mcp/pkg/flux"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/health"
 	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
 	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
 )
@@ -55,7 +56,7 @@ type getFluxResourceTreeInput struct {
 	ResourceKind string `json:"resource_kind,omitempty" jsonschema:"Resource kind: Kustomization or HelmRelease (default: Kustomization)"`
 }
 
-// registerFluxTools registers all 8 FluxCD diagnostic tools.
+// registerFluxTools registers all 9 FluxCD diagnostic tools.
 func registerFluxTools(server *mcp.Server, fluxClient *flux.FluxClient, k8sClient *k8s.ClusterClient) {
 	registerListFluxKustomizations(server, fluxClient)
 	registerListFluxHelmReleases(server, fluxClient)
@@ -65,6 +66,7 @@ func registerFluxTools(server *mcp.Server, fluxClient *flux.FluxClient, k8sClien
 	registerDiagnoseFluxHelmRelease(server, fluxClient, k8sClient)
 	registerDiagnoseFluxSystem(server, fluxClient, k8sClient)
 	registerGetFluxResourceTree(server, fluxClient)
+	registerDetectFluxDrift(server, fluxClient, k8sClient)
 }
 
 // --- Tool 1: list_flux_kustomizations ---
@@ -344,7 +346,7 @@ func registerListFluxImagePolicies(server *mcp.Server, fluxClient *flux.FluxClie
 func registerDiagnoseFluxKustomization(server *mcp.Server, fluxClient *flux.FluxClient, k8sClient *k8s.ClusterClient) {
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "diagnose_flux_kustomization",
-		Description: "Deep diagnosis of a FluxCD Kustomization. Checks reconciliation status, source health, dependency chain, managed resources from inventory, and recent events. Use this when a Kustomization is failing or stuck.",
+		Description: "Deep diagnosis of a FluxCD Kustomization. Checks reconciliation status, source health, dependency chain, the live health of every object in the inventory (flagging objects deleted out of band), and recent events. Use this when a Kustomization is failing or stuck.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input diagnoseFluxKustomizationInput) (*mcp.CallToolResult, any, error) {
 		ks, err := fluxClient.GetKustomization(ctx, input.Namespace, input.Name)
 		if err != nil {
@@ -356,8 +358,11 @@ func registerDiagnoseFluxKustomization(server *mcp.Server, fluxClient *flux.Flux
 		sb.WriteString("\n\n")
 
 		// Basic info
-		health := flux.KustomizationHealth(ks)
-		sb.WriteString(util.FormatKeyValue("STATUS", string(health)))
+		fluxStatus := flux.KustomizationHealth(ks)
+		verdict := health.FromConditions(ks.Status.Conditions, ks.Generation, ks.Status.ObservedGeneration)
+		sb.WriteString(util.FormatKeyValue("STATUS", string(fluxStatus)))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("HEALTH", verdict.String()))
 		sb.WriteString("\n")
 		sb.WriteString(util.FormatKeyValue("SOURCE", fmt.Sprintf("%s/%s", ks.Spec.SourceRef.Kind, ks.Spec.SourceRef.Name)))
 		sb.WriteString("\n")
@@ -396,20 +401,26 @@ func registerDiagnoseFluxKustomization(server *mcp.Server, fluxClient *flux.Flux
 			findings++
 		}
 
-		if health == flux.HealthFailed {
+		if fluxStatus == flux.HealthFailed {
 			msg := flux.GetConditionMessage(ks.Status.Conditions, fluxmeta.ReadyCondition)
 			sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("Reconciliation failed: %s", msg)))
 			sb.WriteString("\n")
 			findings++
 		}
 
-		if health == flux.HealthStalled {
+		if fluxStatus == flux.HealthStalled {
 			msg := flux.GetConditionMessage(ks.Status.Conditions, fluxmeta.StalledCondition)
 			sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("Reconciliation stalled: %s", msg)))
 			sb.WriteString("\n")
 			findings++
 		}
 
+		if !ks.Spec.Suspend && (verdict.Status == health.InProgress || verdict.Status == health.Unknown) {
+			sb.WriteString(util.FormatFinding("WARNING", "Kustomization is not ready: "+verdict.String()))
+			sb.WriteString("\n")
+			findings++
+		}
+
 		if ks.Status.LastAppliedRevision != ks.Status.LastAttemptedRevision && ks.Status.LastAttemptedRevision != "" {
 			sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("Applied revision (%s) differs from attempted revision (%s)",
 				truncateRevision(ks.Status.LastAppliedRevision), truncateRevision(ks.Status.LastAttemptedRevision))))
@@ -449,18 +460,30 @@ func registerDiagnoseFluxKustomization(server *mcp.Server, fluxClient *flux.Flux
 			}
 		}
 
-		// Inventory
+		// Inventory: live health of every applied object
+		var inventoryActions []string
+		missingObjects := 0
 		if ks.Status.Inventory != nil && len(ks.Status.Inventory.Entries) > 0 {
 			sb.WriteString("\n")
 			sb.WriteString(util.FormatSubHeader(fmt.Sprintf("Managed Resources (%d)", len(ks.Status.Inventory.Entries))))
 			sb.WriteString("\n")
-			limit := 20
-			for i, entry := range ks.Status.Inventory.Entries {
-				if i >= limit {
-					sb.WriteString(fmt.Sprintf("  ... and %d more\n", len(ks.Status.Inventory.Entries)-limit))
-					break
+			refs, invalid := flux.KustomizationInventory(ks)
+			report, err := checkInventories(ctx, k8sClient, []inventorySource{{Owner: ks.Namespace + "/" + ks.Name, Refs: refs, Invalid: invalid}})
+			if err != nil {
+				sb.WriteString(fmt.Sprintf("  (could not check live objects: %v)\n", err))
+				limit := 20
+				for i, entry := range ks.Status.Inventory.Entries {
+					if i >= limit {
+						sb.WriteString(fmt.Sprintf("  ... and %d more\n", len(ks.Status.Inventory.Entries)-limit))
+						break
+					}
+					sb.WriteString(fmt.Sprintf("  %s (v%s)\n", entry.ID, entry.Version))
 				}
-				sb.WriteString(fmt.Sprintf("  %s (v%s)\n", entry.ID, entry.Version))
+			} else {
+				n, actions := writeInventoryReport(&sb, report, false)
+				findings += n
+				inventoryActions = actions
+				missingObjects = report.Missing
 			}
 		}
 
@@ -488,7 +511,7 @@ func registerDiagnoseFluxKustomization(server *mcp.Server, fluxClient *flux.Flux
 		// Suggested actions
 		sb.WriteString("\nSUGGESTED ACTIONS:\n")
 		actionNum := 1
-		if health == flux.HealthFailed {
+		if fluxStatus == flux.HealthFailed {
 			reason := flux.GetConditionReason(ks.Status.Conditions, fluxmeta.ReadyCondition)
 			switch reason {
 			case "BuildFailed":
@@ -509,6 +532,14 @@ func registerDiagnoseFluxKustomization(server *mcp.Server, fluxClient *flux.Flux
 			sb.WriteString(fmt.Sprintf("%d. Resume reconciliation: flux resume kustomization %s -n %s\n", actionNum, ks.Name, ks.Namespace))
 			actionNum++
 		}
+		if missingObjects > 0 && !ks.Spec.Suspend {
+			sb.WriteString(fmt.Sprintf("%d. Recreate the missing objects: flux reconcile kustomization %s -n %s\n", actionNum, ks.Name, ks.Namespace))
+			actionNum++
+		}
+		for _, a := range inventoryActions {
+			sb.WriteString(fmt.Sprintf("%d. %s\n", actionNum, a))
+			actionNum++
+		}
 		if actionNum == 1 {
 			sb.WriteString("  No specific actions needed — Kustomization is healthy.\n")
 		}
@@ -533,10 +564,13 @@ func registerDiagnoseFluxHelmRelease(server *mcp.Server, fluxClient *flux.FluxCl
 		sb.WriteString(util.FormatHeader(fmt.Sprintf("Flux HelmRelease Diagnosis: %s (namespace: %s)", hr.Name, hr.Namespace)))
 		sb.WriteString("\n\n")
 
-		health := flux.HelmReleaseHealth(hr)
+		fluxStatus := flux.HelmReleaseHealth(hr)
+		verdict := health.FromConditions(hr.Status.Conditions, hr.Generation, hr.Status.ObservedGeneration)
 		chart, version := helmChartInfo(hr)
 
-		sb.WriteString(util.FormatKeyValue("STATUS", string(health)))
+		sb.WriteString(util.FormatKeyValue("STATUS", string(fluxStatus)))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("HEALTH", verdict.String()))
 		sb.WriteString("\n")
 		sb.WriteString(util.FormatKeyValue("CHART", chart))
 		sb.WriteString("\n")
@@ -587,20 +621,26 @@ func registerDiagnoseFluxHelmRelease(server *mcp.Server, fluxClient *flux.FluxCl
 			findings++
 		}
 
-		if health == flux.HealthFailed {
+		if fluxStatus == flux.HealthFailed {
 			msg := flux.GetConditionMessage(hr.Status.Conditions, fluxmeta.ReadyCondition)
 			sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("Reconciliation failed: %s", msg)))
 			sb.WriteString("\n")
 			findings++
 		}
 
-		if health == flux.HealthStalled {
+		if fluxStatus == flux.HealthStalled {
 			msg := flux.GetConditionMessage(hr.Status.Conditions, fluxmeta.StalledCondition)
 			sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("Reconciliation stalled: %s", msg)))
 			sb.WriteString("\n")
 			findings++
 		}
 
+		if !hr.Spec.Suspend && (verdict.Status == health.InProgress || verdict.Status == health.Unknown) {
+			sb.WriteString(util.FormatFinding("WARNING", "HelmRelease is not ready: "+verdict.String()))
+			sb.WriteString("\n")
+			findings++
+		}
+
 		// Check Released condition
 		releasedMsg := flux.GetConditionMessage(hr.Status.Conditions, "Released")
 		releasedReason := flux.GetConditionReason(hr.Status.Conditions, "Released")
@@ -673,7 +713,7 @@ func registerDiagnoseFluxHelmRelease(server *mcp.Server, fluxClient *flux.FluxCl
 		// Suggested actions
 		sb.WriteString("\nSUGGESTED ACTIONS:\n")
 		actionNum := 1
-		if health == flux.HealthFailed {
+		if fluxStatus == flux.HealthFailed {
 			reason := flux.GetConditionReason(hr.Status.Conditions, fluxmeta.ReadyCondition)
 			switch {
 			case strings.Contains(reason, "Install"):
@@ -704,7 +744,7 @@ func registerDiagnoseFluxHelmRelease(server *mcp.Server, fluxClient *flux.FluxCl
 func registerDiagnoseFluxSystem(server *mcp.Server, fluxClient *flux.FluxClient, k8sClient *k8s.ClusterClient) {
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "diagnose_flux_system",
-		Description: "Comprehensive FluxCD system health check. Checks flux-system pods, tallies Kustomization/HelmRelease/Source health across the cluster, lists warning events, and generates a Mermaid topology diagram. Use this for a broad Flux health overview.",
+		Description: "Comprehensive FluxCD system health check. Checks flux-system pods, tallies Kustomization/HelmRelease/Source health across the cluster, counts healthy/unhealthy/missing objects across all Kustomization inventories, lists warning events, and generates a Mermaid topology diagram. Use this for a broad Flux health overview.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input diagnoseFluxSystemInput) (*mcp.CallToolResult, any, error) {
 		var sb strings.Builder
 		sb.WriteString(util.FormatHeader("FluxCD System Health Report"))
@@ -724,21 +764,17 @@ func registerDiagnoseFluxSystem(server *mcp.Server, fluxClient *flux.FluxClient,
 				sb.WriteString("\n")
 				findings++
 			} else {
-				healthy := 0
+				var unhealthy []string
 				for i := range pods {
-					if isPodHealthy(&pods[i]) {
-						healthy++
+					p := &pods[i]
+					if r := health.Pod(p); !r.Healthy() {
+						unhealthy = append(unhealthy, fmt.Sprintf("  %s\n", util.FormatFinding(healthSeverity(r), fmt.Sprintf("Controller pod '%s' is unhealthy: %s", p.Name, r))))
 					}
 				}
-				sb.WriteString(fmt.Sprintf("  Pods: %d/%d healthy\n", healthy, len(pods)))
-				if healthy < len(pods) {
-					for i := range pods {
-						p := &pods[i]
-						if !isPodHealthy(p) {
-							sb.WriteString(fmt.Sprintf("  %s\n", util.FormatFinding("CRITICAL", fmt.Sprintf("Controller pod '%s' is unhealthy: %s", p.Name, podPhaseReason(p)))))
-							findings++
-						}
-					}
+				sb.WriteString(fmt.Sprintf("  Pods: %d/%d healthy\n", len(pods)-len(unhealthy), len(pods)))
+				for _, line := range unhealthy {
+					sb.WriteString(line)
+					findings++
 				}
 			}
 		}
@@ -765,13 +801,35 @@ func registerDiagnoseFluxSystem(server *mcp.Server, fluxClient *flux.FluxClient,
 				for i := range ksList {
 					h := flux.KustomizationHealth(&ksList[i])
 					if h == flux.HealthFailed || h == flux.HealthStalled {
-						sb.WriteString(fmt.Sprintf("    - %s/%s: %s\n", ksList[i].Namespace, ksList[i].Name, h))
+						r := health.FromConditions(ksList[i].Status.Conditions, ksList[i].Generation, ksList[i].Status.ObservedGeneration)
+						sb.WriteString(fmt.Sprintf("    - %s/%s: %s — %s\n", ksList[i].Namespace, ksList[i].Name, h, r))
 					}
 				}
 				findings++
 			}
 		}
 
+		// Inventory health across all Kustomizations
+		var inventoryActions []string
+		if len(ksList) > 0 && k8sClient != nil {
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("Kustomization Inventory Health"))
+			sb.WriteString("\n")
+			sources := make([]inventorySource, 0, len(ksList))
+			for i := range ksList {
+				refs, invalid := flux.KustomizationInventory(&ksList[i])
+				sources = append(sources, inventorySource{Owner: ksList[i].Namespace + "/" + ksList[i].Name, Refs: refs, Invalid: invalid})
+			}
+			report, err := checkInventories(ctx, k8sClient, sources)
+			if err != nil {
+				sb.WriteString(fmt.Sprintf("  (could not check live objects: %v)\n", err))
+			} else {
+				n, actions := writeInventoryReport(&sb, report, true)
+				findings += n
+				inventoryActions = actions
+			}
+		}
+
 		// 3. HelmRelease health tally
 		sb.WriteString("\n")
 		sb.WriteString(util.FormatSubHeader("HelmRelease Health"))
@@ -794,7 +852,8 @@ func registerDiagnoseFluxSystem(server *mcp.Server, fluxClient *flux.FluxClient,
 				for i := range hrList {
 					h := flux.HelmReleaseHealth(&hrList[i])
 					if h == flux.HealthFailed || h == flux.HealthStalled {
-						sb.WriteString(fmt.Sprintf("    - %s/%s: %s\n", hrList[i].Namespace, hrList[i].Name, h))
+						r := health.FromConditions(hrList[i].Status.Conditions, hrList[i].Generation, hrList[i].Status.ObservedGeneration)
+						sb.WriteString(fmt.Sprintf("    - %s/%s: %s — %s\n", hrList[i].Namespace, hrList[i].Name, h, r))
 					}
 				}
 				findings++
@@ -876,6 +935,12 @@ func registerDiagnoseFluxSystem(server *mcp.Server, fluxClient *flux.FluxClient,
 		} else {
 			sb.WriteString(fmt.Sprintf("  %d issue(s) found. Review findings above.\n", findings))
 		}
+		if len(inventoryActions) > 0 {
+			sb.WriteString("\nSUGGESTED ACTIONS:\n")
+			for i, a := range inventoryActions {
+				sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, a))
+			}
+		}
 
 		// Mermaid diagram
 		sb.WriteString("\n")
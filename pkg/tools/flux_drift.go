@@ -0,0 +1,342 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/flux"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+// maxDriftRows caps the drifted-field rows printed per report.
+const maxDriftRows = 100
+
+// artifactHTTPClient downloads source-controller artifacts.
+var artifactHTTPClient = &http.Client{Timeout: 60 * time.Second}
+
+type detectFluxDriftInput struct {
+	Namespace string `json:"namespace,omitempty" jsonschema:"Kustomization namespace (default: flux-system)"`
+	Name      string `json:"name" jsonschema:"required,Kustomization name"`
+}
+
+// objectDrift is the comparison of one object rendered from the source with the cluster.
+type objectDrift struct {
+	Ref     k8s.ObjectRef
+	Drifts  []k8s.FieldDrift
+	Ignored []string
+}
+
+// --- Tool 9: detect_flux_drift ---
+
+func registerDetectFluxDrift(server *mcp.Server, fluxClient *flux.FluxClient, k8sClient *k8s.ClusterClient) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "detect_flux_drift",
+		Description: "Detect configuration drift for a FluxCD Kustomization. Downloads the source artifact from source-controller, runs the kustomize build for spec.path with postBuild substitutions, and compares every rendered object field by field with the live object. managedFields attribute each difference: hand edits (kubectl, k9s, dashboards) are reported as drift, while fields owned by other controllers such as an HPA are ignored. Use this when live objects no longer match Git.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input detectFluxDriftInput) (*mcp.CallToolResult, any, error) {
+		if input.Name == "" {
+			return util.ErrorResult("name is required"), nil, nil
+		}
+		if k8sClient == nil {
+			return util.ErrorResult("kubernetes client not available"), nil, nil
+		}
+		namespace := input.Namespace
+		if namespace == "" {
+			namespace = "flux-system"
+		}
+		ks, err := fluxClient.GetKustomization(ctx, namespace, input.Name)
+		if err != nil {
+			return handleFluxError(fmt.Sprintf("getting Kustomization %s/%s", namespace, input.Name), err), nil, nil
+		}
+
+		artifact, err := fluxClient.SourceArtifact(ctx, ks)
+		if err != nil {
+			return util.ErrorResult("getting the source artifact of Kustomization %s/%s: %v", ks.Namespace, ks.Name, err), nil, nil
+		}
+		archive, err := downloadSourceArtifact(ctx, k8sClient, artifact.URL, artifact.Digest)
+		if err != nil {
+			return util.ErrorResult("downloading the source artifact: %v", err), nil, nil
+		}
+		vars, err := flux.PostBuildVariables(ks, postBuildLookup(ctx, k8sClient, ks.Namespace))
+		if err != nil {
+			return util.ErrorResult("resolving postBuild variables: %v", err), nil, nil
+		}
+		desired, err := flux.BuildKustomization(archive, ks, vars)
+		if err != nil {
+			return util.ErrorResult("building %s at revision %s: %v", valueOrNone(ks.Spec.Path), artifact.Revision, err), nil, nil
+		}
+
+		var sb strings.Builder
+		sb.WriteString(util.FormatHeader(fmt.Sprintf("Flux Drift Detection: %s (namespace: %s)", ks.Name, ks.Namespace)))
+		sb.WriteString("\n\n")
+		sb.WriteString(util.FormatKeyValue("SOURCE", fmt.Sprintf("%s/%s", ks.Spec.SourceRef.Kind, ks.Spec.SourceRef.Name)))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("PATH", valueOrNone(ks.Spec.Path)))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("SOURCE REVISION", truncateRevision(artifact.Revision)))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("APPLIED REVISION", truncateRevision(ks.Status.LastAppliedRevision)))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("SUSPENDED", fmt.Sprintf("%v", ks.Spec.Suspend)))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("RENDERED OBJECTS", fmt.Sprintf("%d", len(desired))))
+		sb.WriteString("\n")
+
+		// Split the rendered objects into those compared and those kustomize-controller leaves alone.
+		var compare []*unstructured.Unstructured
+		var refs []k8s.ObjectRef
+		var skipped []string
+		for _, obj := range desired {
+			if reason := driftSkipReason(ks, obj); reason != "" {
+				skipped = append(skipped, fmt.Sprintf("%s (%s)", refForObject(obj), reason))
+				continue
+			}
+			compare = append(compare, obj)
+			refs = append(refs, refForObject(obj))
+		}
+		live, err := k8sClient.GetObjectsHealth(ctx, refs)
+		if err != nil {
+			return util.HandleK8sError("fetching live objects", err), nil, nil
+		}
+
+		var drifted []objectDrift
+		var missing, unchecked []string
+		totalFields, interactiveFields, ignoredFields, addedFields := 0, 0, 0, 0
+		for i, obj := range compare {
+			switch {
+			case live[i].Missing:
+				missing = append(missing, refs[i].String())
+				continue
+			case live[i].Error != "":
+				unchecked = append(unchecked, fmt.Sprintf("%s: %s", refs[i], live[i].Error))
+				continue
+			}
+			report := k8s.DiffLiveObject(obj, live[i].Object, flux.FieldManager)
+			ignoredFields += len(report.Ignored)
+			if len(report.Drifts) == 0 && len(report.Ignored) == 0 {
+				continue
+			}
+			for _, d := range report.Drifts {
+				totalFields++
+				if k8s.IsInteractiveFieldManager(d.Manager) {
+					interactiveFields++
+				}
+				if d.Type == k8s.DriftAdded {
+					addedFields++
+				}
+			}
+			drifted = append(drifted, objectDrift{Ref: refs[i], Drifts: report.Drifts, Ignored: report.Ignored})
+		}
+
+		driftedObjects := 0
+		var rows [][]string
+		for _, od := range drifted {
+			if len(od.Drifts) == 0 {
+				continue
+			}
+			driftedObjects++
+			for _, d := range od.Drifts {
+				if len(rows) >= maxDriftRows {
+					break
+				}
+				rows = append(rows, []string{od.Ref.String(), d.Path, d.Type, d.Desired, d.Live, valueOrNone(d.Manager)})
+			}
+		}
+
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader(fmt.Sprintf("Drifted Fields (%d)", totalFields)))
+		sb.WriteString("\n")
+		if len(rows) == 0 {
+			sb.WriteString("  No drifted fields — live objects match the source.\n")
+		} else {
+			sb.WriteString(util.FormatTable([]string{"OBJECT", "FIELD", "CHANGE", "SOURCE VALUE", "LIVE VALUE", "MANAGER"}, rows))
+			if totalFields > len(rows) {
+				sb.WriteString(fmt.Sprintf("  ... and %d more\n", totalFields-len(rows)))
+			}
+		}
+
+		if len(missing) > 0 {
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader(fmt.Sprintf("Missing Objects (%d)", len(missing))))
+			sb.WriteString("\n")
+			for _, m := range limitStrings(missing, maxInventoryRows) {
+				sb.WriteString(fmt.Sprintf("  %s\n", m))
+			}
+		}
+
+		if ignoredFields > 0 {
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader(fmt.Sprintf("Ignored Fields (%d)", ignoredFields)))
+			sb.WriteString("\n")
+			var ignored []string
+			for _, od := range drifted {
+				for _, f := range od.Ignored {
+					ignored = append(ignored, fmt.Sprintf("%s %s", od.Ref, f))
+				}
+			}
+			for _, f := range limitStrings(ignored, maxInventoryRows) {
+				sb.WriteString(fmt.Sprintf("  %s\n", f))
+			}
+		}
+
+		// Findings
+		sb.WriteString("\nFINDINGS:\n")
+		findings := 0
+		if ks.Spec.Suspend {
+			sb.WriteString(util.FormatFinding("INFO", "Kustomization is suspended — drift is not corrected until it is resumed"))
+			sb.WriteString("\n")
+		}
+		if artifact.Revision != ks.Status.LastAppliedRevision {
+			sb.WriteString(util.FormatFinding("INFO", fmt.Sprintf("Source revision %s has not been applied yet (applied: %s) — some differences may be pending changes rather than drift",
+				truncateRevision(artifact.Revision), truncateRevision(ks.Status.LastAppliedRevision))))
+			sb.WriteString("\n")
+		}
+		if totalFields > 0 {
+			msg := fmt.Sprintf("%d field(s) on %d object(s) differ from the source", totalFields, driftedObjects)
+			if interactiveFields > 0 {
+				msg += fmt.Sprintf(" (%d changed by hand with kubectl, k9s or a dashboard)", interactiveFields)
+			}
+			sb.WriteString(util.FormatFinding("WARNING", msg))
+			sb.WriteString("\n")
+			findings++
+		}
+		if len(missing) > 0 {
+			sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("%d object(s) in the source are missing from the cluster", len(missing))))
+			sb.WriteString("\n")
+			findings++
+		}
+		if ignoredFields > 0 {
+			sb.WriteString(util.FormatFinding("INFO", fmt.Sprintf("%d differing field(s) are owned by other controllers and were ignored", ignoredFields)))
+			sb.WriteString("\n")
+		}
+		if len(skipped) > 0 {
+			sb.WriteString(util.FormatFinding("INFO", fmt.Sprintf("%d object(s) not compared: %s", len(skipped), strings.Join(limitStrings(skipped, 5), ", "))))
+			sb.WriteString("\n")
+		}
+		if len(unchecked) > 0 {
+			sb.WriteString(util.FormatFinding("INFO", fmt.Sprintf("%d object(s) could not be checked: %s", len(unchecked), unchecked[0])))
+			sb.WriteString("\n")
+		}
+		if findings == 0 {
+			sb.WriteString("  No drift found — the cluster matches the source.\n")
+		}
+
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Summary"))
+		sb.WriteString("\n")
+		sb.WriteString(fmt.Sprintf("  %d object(s) compared, %d drifted, %d missing, %d field(s) ignored.\n",
+			len(compare)-len(unchecked)-len(missing), driftedObjects, len(missing), ignoredFields))
+
+		// Suggested actions
+		sb.WriteString("\nSUGGESTED ACTIONS:\n")
+		actionNum := 1
+		if ks.Spec.Suspend && (totalFields > 0 || len(missing) > 0) {
+			sb.WriteString(fmt.Sprintf("%d. Resume reconciliation to correct the drift: flux resume kustomization %s -n %s\n", actionNum, ks.Name, ks.Namespace))
+			actionNum++
+		} else if totalFields > 0 || len(missing) > 0 {
+			sb.WriteString(fmt.Sprintf("%d. Revert the drift and recreate missing objects: flux reconcile kustomization %s -n %s\n", actionNum, ks.Name, ks.Namespace))
+			actionNum++
+		}
+		if interactiveFields > 0 {
+			sb.WriteString(fmt.Sprintf("%d. If a hand edit should be kept, commit it to %s at path '%s' — otherwise the next reconcile overwrites it\n", actionNum, ks.Spec.SourceRef.Name, ks.Spec.Path))
+			actionNum++
+		}
+		if addedFields > 0 {
+			sb.WriteString(fmt.Sprintf("%d. Review the %d field(s) added by hand that the source does not set; they are removed when kustomize-controller takes over their ownership\n", actionNum, addedFields))
+			actionNum++
+		}
+		if actionNum == 1 {
+			sb.WriteString("  No specific actions needed — no drift detected.\n")
+		}
+
+		return util.SuccessResult(sb.String()), nil, nil
+	})
+}
+
+// downloadSourceArtifact fetches an artifact directly, falling back to the apiserver service
+// proxy when the source-controller URL only resolves inside the cluster.
+func downloadSourceArtifact(ctx context.Context, client *k8s.ClusterClient, url, digest string) ([]byte, error) {
+	data, err := flux.DownloadArtifact(ctx, artifactHTTPClient, url, digest)
+	if err == nil {
+		return data, nil
+	}
+	if _, _, _, ok := k8s.ParseServiceURL(url); !ok {
+		return nil, err
+	}
+	data, proxyErr := client.GetThroughServiceProxy(ctx, url, flux.MaxArtifactSize)
+	if proxyErr != nil {
+		return nil, fmt.Errorf("%v; through the apiserver proxy: %v", err, proxyErr)
+	}
+	if err := flux.VerifyDigest(data, digest); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// postBuildLookup reads substituteFrom ConfigMaps and Secrets from the Kustomization's namespace.
+func postBuildLookup(ctx context.Context, client *k8s.ClusterClient, namespace string) func(kind, name string) (map[string]string, bool, error) {
+	return func(kind, name string) (map[string]string, bool, error) {
+		switch kind {
+		case "ConfigMap":
+			cm, err := client.GetConfigMap(ctx, namespace, name)
+			if apierrors.IsNotFound(err) {
+				return nil, false, nil
+			}
+			if err != nil {
+				return nil, false, err
+			}
+			return cm.Data, true, nil
+		case "Secret":
+			secret, err := client.GetSecret(ctx, namespace, name)
+			if apierrors.IsNotFound(err) {
+				return nil, false, nil
+			}
+			if err != nil {
+				return nil, false, err
+			}
+			data := make(map[string]string, len(secret.Data))
+			for k, v := range secret.Data {
+				data[k] = string(v)
+			}
+			return data, true, nil
+		}
+		return nil, false, fmt.Errorf("unsupported kind %s", kind)
+	}
+}
+
+// driftSkipReason returns why kustomize-controller would not correct drift on obj, or "".
+func driftSkipReason(ks *kustomizev1.Kustomization, obj *unstructured.Unstructured) string {
+	annotations, labels := obj.GetAnnotations(), obj.GetLabels()
+	if annotations[flux.ReconcileAnnotation] == "disabled" || labels[flux.ReconcileAnnotation] == "disabled" {
+		return "reconcile disabled"
+	}
+	switch ssa := annotations[flux.SSAAnnotation]; ssa {
+	case "IfNotPresent", "Ignore":
+		return "ssa " + ssa
+	}
+	if ks.Spec.Decryption != nil && obj.GetAPIVersion() == "v1" && obj.GetKind() == "Secret" {
+		return "encrypted in the source"
+	}
+	return ""
+}
+
+func refForObject(obj *unstructured.Unstructured) k8s.ObjectRef {
+	gvk := obj.GroupVersionKind()
+	return k8s.ObjectRef{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind, Namespace: obj.GetNamespace(), Name: obj.GetName()}
+}
+
+// limitStrings returns at most n items, with a final "... and N more" entry when truncated.
+func limitStrings(items []string, n int) []string {
+	if len(items) <= n {
+		return items
+	}
+	return append(items[:n:n], fmt.Sprintf("... and %d more", len(items)-n))
+}
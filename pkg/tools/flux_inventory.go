@@ -0,0 +1,234 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/health"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+// maxInventoryRows caps the managed-object rows printed per report.
+const maxInventoryRows = 20
+
+// inventoryObject is one inventory entry with its live health and the Kustomization that applied it.
+type inventoryObject struct {
+	k8s.ObjectHealth
+	Owner string // namespace/name of the Kustomization
+}
+
+// inventoryReport is the live health of one or more Kustomization inventories.
+type inventoryReport struct {
+	Objects   []inventoryObject
+	Invalid   []string // entry IDs that could not be parsed
+	Healthy   int
+	Unhealthy int // present but not Current
+	Missing   int // in the inventory but deleted from the cluster
+	Unchecked int // kind no longer served, or the list call failed
+}
+
+// inventorySource is the parsed inventory of one Kustomization.
+type inventorySource struct {
+	Owner   string
+	Refs    []k8s.ObjectRef
+	Invalid []string
+}
+
+// checkInventories fetches every object of the given inventories in one pass, so objects of the same
+// kind and namespace are listed once across all Kustomizations.
+func checkInventories(ctx context.Context, client *k8s.ClusterClient, sources []inventorySource) (*inventoryReport, error) {
+	if client == nil {
+		return nil, fmt.Errorf("kubernetes client not available")
+	}
+	var refs []k8s.ObjectRef
+	var owners []string
+	report := &inventoryReport{}
+	for _, src := range sources {
+		refs = append(refs, src.Refs...)
+		for range src.Refs {
+			owners = append(owners, src.Owner)
+		}
+		report.Invalid = append(report.Invalid, src.Invalid...)
+	}
+	if len(refs) == 0 {
+		return report, nil
+	}
+
+	results, err := client.GetObjectsHealth(ctx, refs)
+	if err != nil {
+		return nil, err
+	}
+	for i, r := range results {
+		switch {
+		case r.Missing:
+			report.Missing++
+		case r.Error != "":
+			report.Unchecked++
+		case r.Health.Healthy():
+			report.Healthy++
+		default:
+			report.Unhealthy++
+		}
+		report.Objects = append(report.Objects, inventoryObject{ObjectHealth: r, Owner: owners[i]})
+	}
+	sort.SliceStable(report.Objects, func(i, j int) bool {
+		return inventoryRank(report.Objects[i].ObjectHealth) < inventoryRank(report.Objects[j].ObjectHealth)
+	})
+	return report, nil
+}
+
+// inventoryRank orders objects so the most urgent problems are listed first.
+func inventoryRank(o k8s.ObjectHealth) int {
+	switch {
+	case o.Missing:
+		return 0
+	case o.Error != "":
+		return 5
+	}
+	switch o.Health.Status {
+	case health.Failed:
+		return 1
+	case health.Terminating:
+		return 2
+	case health.InProgress:
+		return 3
+	case health.Unknown:
+		return 4
+	}
+	return 6
+}
+
+// inventoryHealthLabel is the HEALTH column value for an object.
+func inventoryHealthLabel(o k8s.ObjectHealth) string {
+	switch {
+	case o.Missing:
+		return "MISSING"
+	case o.Error != "":
+		return "UNCHECKED"
+	}
+	return string(o.Health.Status)
+}
+
+// inventoryActionFor returns the tool to run for an unhealthy managed object.
+func inventoryActionFor(ref k8s.ObjectRef) string {
+	if tool := diagnoseToolFor(ref.Kind, ref.Namespace, ref.Name); tool != "" {
+		return tool
+	}
+	if ref.Namespace == "" {
+		return fmt.Sprintf("describe_resource kind=%s name=%s", qualifiedKind(ref), ref.Name)
+	}
+	return fmt.Sprintf("describe_resource kind=%s namespace=%s name=%s", qualifiedKind(ref), ref.Namespace, ref.Name)
+}
+
+func qualifiedKind(ref k8s.ObjectRef) string {
+	if ref.Group == "" {
+		return ref.Kind
+	}
+	return ref.Kind + "." + ref.Group
+}
+
+// writeInventoryReport renders the counts, a table of the most urgent objects and the findings.
+// It returns the number of findings and the suggested actions for unhealthy objects.
+func writeInventoryReport(sb *strings.Builder, report *inventoryReport, showOwner bool) (int, []string) {
+	total := len(report.Objects)
+	sb.WriteString(fmt.Sprintf("  Objects: %d, Healthy: %d, Unhealthy: %d, Missing: %d", total, report.Healthy, report.Unhealthy, report.Missing))
+	if report.Unchecked > 0 {
+		sb.WriteString(fmt.Sprintf(", Unchecked: %d", report.Unchecked))
+	}
+	sb.WriteString("\n")
+	if total == 0 {
+		return 0, nil
+	}
+
+	headers := []string{"KIND", "NAMESPACE", "NAME", "HEALTH", "DETAILS"}
+	if showOwner {
+		headers = append(headers, "KUSTOMIZATION")
+	}
+	rows := make([][]string, 0, maxInventoryRows)
+	for _, o := range report.Objects {
+		if len(rows) >= maxInventoryRows {
+			break
+		}
+		if showOwner && o.Health.Healthy() && !o.Missing && o.Error == "" {
+			break // cluster-wide report lists problems only
+		}
+		details := o.Health.Message
+		if o.Health.Reason != "" && !o.Missing {
+			details = o.Health.Reason + ": " + details
+		}
+		if o.Health.Healthy() {
+			details = "-"
+		}
+		row := []string{o.Ref.Kind, valueOrNone(o.Ref.Namespace), o.Ref.Name, inventoryHealthLabel(o.ObjectHealth), util.TruncateString(details, 80)}
+		if showOwner {
+			row = append(row, o.Owner)
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatTable(headers, rows))
+		if shown := len(rows); shown < total && !showOwner {
+			sb.WriteString(fmt.Sprintf("  ... and %d more\n", total-shown))
+		}
+	}
+
+	findings := 0
+	var actions []string
+	if report.Missing > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("%d object(s) in the inventory are missing from the cluster (deleted out of band)", report.Missing)))
+		sb.WriteString("\n")
+		findings++
+	}
+	failed := 0
+	for _, o := range report.Objects {
+		if !o.Missing && o.Error == "" && o.Health.Status == health.Failed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("%d managed object(s) Failed", failed)))
+		sb.WriteString("\n")
+		findings++
+	}
+	if pending := report.Unhealthy - failed; pending > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("%d managed object(s) not yet Current (in progress, terminating or unknown)", pending)))
+		sb.WriteString("\n")
+		findings++
+	}
+	if report.Unchecked > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatFinding("INFO", fmt.Sprintf("%d object(s) could not be checked: %s", report.Unchecked, firstUncheckedError(report))))
+		sb.WriteString("\n")
+	}
+	if len(report.Invalid) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatFinding("INFO", fmt.Sprintf("%d inventory entries could not be parsed: %s", len(report.Invalid), strings.Join(report.Invalid, ", "))))
+		sb.WriteString("\n")
+	}
+
+	for _, o := range report.Objects {
+		if len(actions) >= 5 {
+			break
+		}
+		if !o.Missing && o.Error == "" && !o.Health.Healthy() {
+			actions = append(actions, fmt.Sprintf("Investigate %s (%s): %s", o.Ref, o.Health.Status, inventoryActionFor(o.Ref)))
+		}
+	}
+	return findings, actions
+}
+
+func firstUncheckedError(report *inventoryReport) string {
+	for _, o := range report.Objects {
+		if o.Error != "" {
+			return o.Error
+		}
+	}
+	return ""
+}
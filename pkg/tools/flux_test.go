@@ -1,7 +1,14 @@
 package tools
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
@@ -13,6 +20,11 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes/fake"
 
 	"github.com/pat-nel87/kube-doctor-mcp/pkg/flux"
@@ -32,6 +44,13 @@ func callFluxTool(t *testing.T, fluxObjs []client.Object, k8sObjs []corev1.Pod,
 	}
 	k8sClient := k8s.NewClusterClientForTesting(fakeK8s, nil)
 
+	return callFluxToolWithClients(t, fluxClient, k8sClient, toolName, args)
+}
+
+// callFluxToolWithClients calls the named Flux tool against the given clients.
+func callFluxToolWithClients(t *testing.T, fluxClient *flux.FluxClient, k8sClient *k8s.ClusterClient, toolName string, args map[string]any) (string, bool) {
+	t.Helper()
+
 	server := mcp.NewServer(&mcp.Implementation{
 		Name:    "kube-doctor-test",
 		Version: "test",
@@ -586,6 +605,140 @@ func TestGetFluxResourceTree_HelmRelease(t *testing.T) {
 	}
 }
 
+// --- detect_flux_drift tests ---
+
+const driftTestDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: shop
+spec:
+  replicas: ${replicas}
+  template:
+    spec:
+      containers:
+      - name: app
+        image: web:1.0
+`
+
+const driftTestService = `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: shop
+spec:
+  ports:
+  - port: 80
+`
+
+func driftTestArtifact(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDetectFluxDrift(t *testing.T) {
+	archive := driftTestArtifact(t, map[string]string{
+		"apps/shop/deployment.yaml": driftTestDeployment,
+		"apps/shop/service.yaml":    driftTestService,
+	})
+	sum := sha256.Sum256(archive)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(archive)
+	}))
+	defer server.Close()
+
+	fluxClient := flux.NewFluxClientForTesting(
+		&kustomizev1.Kustomization{
+			ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "flux-system"},
+			Spec: kustomizev1.KustomizationSpec{
+				Path:      "./apps/shop",
+				SourceRef: kustomizev1.CrossNamespaceSourceReference{Kind: "GitRepository", Name: "flux-system"},
+				PostBuild: &kustomizev1.PostBuild{SubstituteFrom: []kustomizev1.SubstituteReference{{Kind: "ConfigMap", Name: "cluster-vars"}}},
+			},
+			Status: kustomizev1.KustomizationStatus{LastAppliedRevision: "main@sha1:abc123"},
+		},
+		&sourcev1.GitRepository{
+			ObjectMeta: metav1.ObjectMeta{Name: "flux-system", Namespace: "flux-system"},
+			Status: sourcev1.GitRepositoryStatus{Artifact: &fluxmeta.Artifact{
+				URL:      server.URL + "/gitrepository/flux-system/flux-system/abc123.tar.gz",
+				Revision: "main@sha1:abc123",
+				Digest:   "sha256:" + hex.EncodeToString(sum[:]),
+			}},
+		},
+	)
+
+	live := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1", "kind": "Deployment",
+		"metadata": map[string]interface{}{"name": "web", "namespace": "shop"},
+		"spec": map[string]interface{}{
+			"replicas": int64(4),
+			"template": map[string]interface{}{"spec": map[string]interface{}{"containers": []interface{}{
+				map[string]interface{}{"name": "app", "image": "web:hotfix"},
+			}}},
+		},
+	}}
+	live.SetManagedFields([]metav1.ManagedFieldsEntry{
+		{Manager: "kustomize-controller", Operation: metav1.ManagedFieldsOperationApply, FieldsType: "FieldsV1",
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:template":{"f:spec":{"f:containers":{"k:{\"name\":\"app\"}":{".":{},"f:name":{}}}}}}}`)}},
+		{Manager: "kubectl-set", Operation: metav1.ManagedFieldsOperationUpdate, FieldsType: "FieldsV1",
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:template":{"f:spec":{"f:containers":{"k:{\"name\":\"app\"}":{"f:image":{}}}}}}}`)}},
+		{Manager: "kube-controller-manager", Operation: metav1.ManagedFieldsOperationUpdate, FieldsType: "FieldsV1",
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:replicas":{}}}`)}},
+	})
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		{Group: "apps", Version: "v1", Resource: "deployments"}: "DeploymentList",
+		{Version: "v1", Resource: "services"}:                   "ServiceList",
+	}, live)
+	cs := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-vars", Namespace: "flux-system"},
+		Data:       map[string]string{"replicas": "2"},
+	})
+	cs.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{
+			{Name: "services", Kind: "Service", Namespaced: true, Verbs: []string{"get", "list"}},
+		}},
+		{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{
+			{Name: "deployments", Kind: "Deployment", Namespaced: true, Verbs: []string{"get", "list"}},
+		}},
+	}
+	k8sClient := k8s.NewClusterClientForTestingWithDynamic(cs, nil, dyn)
+
+	text, isErr := callFluxToolWithClients(t, fluxClient, k8sClient, "detect_flux_drift", map[string]any{"name": "shop"})
+	if isErr {
+		t.Fatalf("expected success, got error: %s", text)
+	}
+	for _, want := range []string{
+		"spec.template.spec.containers[name=app].image",
+		"web:hotfix",
+		"kubectl-set",
+		"Service/shop/web",
+		"spec.replicas (owned by kube-controller-manager)",
+		"1 field(s) on 1 object(s) differ from the source (1 changed by hand",
+		"flux reconcile kustomization shop -n flux-system",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in output:\n%s", want, text)
+		}
+	}
+}
+
 // --- Helper tests ---
 
 func TestTruncateRevision(t *testing.T) {
@@ -0,0 +1,743 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/mermaid"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+type analyzeGatewayAPIInput struct {
+	Namespace string `json:"namespace,omitempty" jsonschema:"Kubernetes namespace of the routes to analyze (empty for all namespaces)"`
+}
+
+func registerGatewayAPITools(server *mcp.Server, client *k8s.ClusterClient) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "analyze_gateway_api",
+		Description: "Audit Gateway API routing: GatewayClasses, Gateways and their listeners, HTTPRoutes and GRPCRoutes, and ReferenceGrants. " +
+			"Checks controller acceptance, listener programming and attached routes, route attachment (sectionName, allowedRoutes, hostname intersection), " +
+			"backendRef resolution to Services and endpoints, and cross-namespace references without a ReferenceGrant. Includes a Mermaid diagram of Gateway -> Route -> Service.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input analyzeGatewayAPIInput) (*mcp.CallToolResult, any, error) {
+		ns := util.NamespaceOrAll(input.Namespace)
+
+		res, err := client.GetGatewayAPIResources(ctx, ns)
+		if err != nil {
+			return util.HandleK8sError("reading Gateway API resources", err), nil, nil
+		}
+
+		var sb strings.Builder
+		sb.WriteString(util.FormatHeader(fmt.Sprintf("Gateway API Analysis (namespace: %s)", displayNS(ns))))
+		sb.WriteString("\n\n")
+		for _, e := range res.Errors {
+			sb.WriteString(fmt.Sprintf("  (could not list %s)\n", e))
+		}
+		if !res.AnyInstalled() {
+			sb.WriteString("  Gateway API CRDs (gateway.networking.k8s.io) are not installed in this cluster.\n")
+			sb.WriteString("  Use list_ingresses and analyze_all_ingresses for Ingress-based routing.\n")
+			return util.SuccessResult(sb.String()), nil, nil
+		}
+
+		findings := 0
+		var actions []string
+		finding := func(severity, msg string) {
+			sb.WriteString(util.FormatFinding(severity, msg))
+			sb.WriteString("\n")
+			findings++
+		}
+		backends := newGatewayBackendResolver(ctx, client)
+
+		// Gateways in scope: those in the namespace plus those the namespace's routes attach to
+		inScope := make(map[string]bool)
+		for _, gw := range res.Gateways {
+			if ns == "" || gw.Namespace == ns {
+				inScope[gw.Namespace+"/"+gw.Name] = true
+			}
+		}
+		for _, r := range res.Routes {
+			for _, p := range r.ParentRefs {
+				inScope[p.Namespace+"/"+p.Name] = true
+			}
+		}
+
+		// GatewayClasses
+		sb.WriteString(util.FormatSubHeader("GatewayClasses"))
+		sb.WriteString("\n")
+		if len(res.Classes) == 0 {
+			sb.WriteString("  No GatewayClasses found.\n")
+		} else {
+			var rows [][]string
+			for _, gc := range res.Classes {
+				rows = append(rows, []string{gc.Name, gc.ControllerName, gatewayConditionStatus(gc.Conditions, "Accepted")})
+			}
+			sb.WriteString(util.FormatTable([]string{"NAME", "CONTROLLER", "ACCEPTED"}, rows))
+		}
+
+		// Gateways and listeners
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Gateways"))
+		sb.WriteString("\n")
+		var gwRows, listenerRows [][]string
+		for _, gw := range res.Gateways {
+			if !inScope[gw.Namespace+"/"+gw.Name] {
+				continue
+			}
+			gwRows = append(gwRows, []string{
+				gw.Namespace, gw.Name, gw.ClassName,
+				valueOrNone(strings.Join(gw.Addresses, ", ")),
+				gatewayConditionStatus(gw.Conditions, "Programmed"),
+				fmt.Sprintf("%d", len(gw.Listeners)),
+			})
+			for _, ln := range gw.Listeners {
+				listenerRows = append(listenerRows, []string{
+					gw.Namespace + "/" + gw.Name, ln.Name, ln.Protocol, fmt.Sprintf("%d", ln.Port),
+					valueOrAny(ln.Hostname), ln.AllowedRoutesFrom,
+					fmt.Sprintf("%d", ln.AttachedRoutes),
+					gatewayConditionStatus(ln.Conditions, "Programmed"),
+				})
+			}
+		}
+		if len(gwRows) == 0 {
+			sb.WriteString("  No Gateways found.\n")
+		} else {
+			sb.WriteString(util.FormatTable([]string{"NAMESPACE", "NAME", "CLASS", "ADDRESSES", "PROGRAMMED", "LISTENERS"}, gwRows))
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("Listeners"))
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatTable([]string{"GATEWAY", "LISTENER", "PROTOCOL", "PORT", "HOSTNAME", "ALLOWED FROM", "ROUTES", "PROGRAMMED"}, listenerRows))
+		}
+
+		// Routes
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Routes"))
+		sb.WriteString("\n")
+		if len(res.Routes) == 0 {
+			sb.WriteString("  No HTTPRoutes or GRPCRoutes found.\n")
+		} else {
+			var rows [][]string
+			for i := range res.Routes {
+				r := &res.Routes[i]
+				var parents []string
+				for _, p := range r.ParentRefs {
+					parents = append(parents, p.String())
+				}
+				accepted, resolved := routeStatusSummary(r)
+				rows = append(rows, []string{
+					r.Namespace, r.Kind + "/" + r.Name,
+					valueOrAny(strings.Join(r.Hostnames, ", ")),
+					valueOrNone(strings.Join(parents, ", ")),
+					fmt.Sprintf("%d", len(r.Rules)),
+					accepted, resolved,
+				})
+			}
+			sb.WriteString(util.FormatTable([]string{"NAMESPACE", "ROUTE", "HOSTNAMES", "PARENTS", "RULES", "ACCEPTED", "RESOLVED REFS"}, rows))
+		}
+
+		if len(res.Grants) > 0 {
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("ReferenceGrants"))
+			sb.WriteString("\n")
+			var rows [][]string
+			for _, g := range res.Grants {
+				var from, to []string
+				for _, f := range g.From {
+					from = append(from, fmt.Sprintf("%s from %s", f.Kind, f.Namespace))
+				}
+				for _, t := range g.To {
+					target := t.Kind
+					if t.Name != "" {
+						target += "/" + t.Name
+					}
+					to = append(to, target)
+				}
+				rows = append(rows, []string{g.Namespace, g.Name, strings.Join(from, ", "), strings.Join(to, ", ")})
+			}
+			sb.WriteString(util.FormatTable([]string{"NAMESPACE", "NAME", "FROM", "TO"}, rows))
+		}
+
+		// Findings
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Findings"))
+		sb.WriteString("\n")
+
+		for _, gc := range res.Classes {
+			if c := k8s.FindGatewayCondition(gc.Conditions, "Accepted"); c != nil && c.Status == "False" {
+				finding("CRITICAL", fmt.Sprintf("GatewayClass '%s' is not accepted by controller %s: %s", gc.Name, gc.ControllerName, conditionDetail(c)))
+				actions = append(actions, fmt.Sprintf("Check that the controller '%s' is installed and running", gc.ControllerName))
+			}
+		}
+
+		for i := range res.Gateways {
+			gw := &res.Gateways[i]
+			if !inScope[gw.Namespace+"/"+gw.Name] {
+				continue
+			}
+			ref := fmt.Sprintf("Gateway '%s/%s'", gw.Namespace, gw.Name)
+			if res.FindClass(gw.ClassName) == nil && len(res.Classes) > 0 {
+				finding("CRITICAL", fmt.Sprintf("%s references GatewayClass '%s' which does not exist", ref, gw.ClassName))
+				actions = append(actions, fmt.Sprintf("Create GatewayClass '%s' or fix spec.gatewayClassName of %s", gw.ClassName, ref))
+			}
+			for _, t := range []string{"Accepted", "Programmed"} {
+				if c := k8s.FindGatewayCondition(gw.Conditions, t); c != nil && c.Status == "False" {
+					finding("CRITICAL", fmt.Sprintf("%s is not %s: %s", ref, strings.ToLower(t), conditionDetail(c)))
+					actions = append(actions, fmt.Sprintf("Inspect the gateway controller logs and events for %s", ref))
+				}
+			}
+			if len(gw.Conditions) == 0 {
+				finding("WARNING", fmt.Sprintf("%s has no status — no controller has reconciled it", ref))
+				actions = append(actions, fmt.Sprintf("Verify the controller for GatewayClass '%s' is running", gw.ClassName))
+			} else if len(gw.Addresses) == 0 {
+				finding("WARNING", fmt.Sprintf("%s has no addresses assigned", ref))
+			}
+			for _, ln := range gw.Listeners {
+				lref := fmt.Sprintf("%s listener '%s'", ref, ln.Name)
+				if c := k8s.FindGatewayCondition(ln.Conditions, "ResolvedRefs"); c != nil && c.Status == "False" {
+					finding("CRITICAL", fmt.Sprintf("%s has unresolved references: %s", lref, conditionDetail(c)))
+				}
+				if c := k8s.FindGatewayCondition(ln.Conditions, "Conflicted"); c != nil && c.Status == "True" {
+					finding("WARNING", fmt.Sprintf("%s conflicts with another listener: %s", lref, conditionDetail(c)))
+				}
+				if c := k8s.FindGatewayCondition(ln.Conditions, "Programmed"); c != nil && c.Status == "False" {
+					finding("WARNING", fmt.Sprintf("%s is not programmed: %s", lref, conditionDetail(c)))
+				}
+				if ln.Protocol == "HTTPS" && len(ln.CertificateRefs) == 0 {
+					finding("WARNING", fmt.Sprintf("%s uses HTTPS but has no certificateRefs", lref))
+				}
+				for _, cert := range ln.CertificateRefs {
+					certNS, certName, _ := strings.Cut(cert, "/")
+					if !k8s.ReferencePermitted(res.Grants, k8s.GatewayAPIGroup, "Gateway", gw.Namespace, "", "Secret", certNS, certName) {
+						finding("CRITICAL", fmt.Sprintf("%s references Secret '%s' in another namespace without a ReferenceGrant", lref, cert))
+						actions = append(actions, fmt.Sprintf("Create a ReferenceGrant in namespace '%s' allowing Gateways from '%s' to reference Secret '%s'", certNS, gw.Namespace, certName))
+					}
+				}
+				if len(ln.Conditions) > 0 && ln.AttachedRoutes == 0 {
+					finding("INFO", fmt.Sprintf("%s has no attached routes", lref))
+				}
+			}
+		}
+
+		type routeKey struct{ gateway, host, match string }
+		claimed := make(map[routeKey]string)
+		for i := range res.Routes {
+			r := &res.Routes[i]
+			rref := fmt.Sprintf("%s '%s/%s'", r.Kind, r.Namespace, r.Name)
+			if len(r.ParentRefs) == 0 {
+				finding("WARNING", fmt.Sprintf("%s has no parentRefs and is not attached to any Gateway", rref))
+			}
+			for _, att := range res.RouteAttachments(r) {
+				switch {
+				case att.Problem != "":
+					finding("CRITICAL", fmt.Sprintf("%s cannot attach to %s: %s", rref, att.ParentRef, att.Problem))
+					actions = append(actions, fmt.Sprintf("Fix parentRefs/allowedRoutes/hostnames so %s can attach to %s", rref, att.ParentRef))
+				case att.Status == nil:
+					finding("WARNING", fmt.Sprintf("%s has no status for parent %s — the gateway controller has not processed it", rref, att.ParentRef))
+				case att.Accepted() == "False":
+					c := k8s.FindGatewayCondition(att.Status.Conditions, "Accepted")
+					finding("CRITICAL", fmt.Sprintf("%s is not accepted by %s: %s", rref, att.ParentRef, conditionDetail(c)))
+				}
+				if att.Status != nil {
+					if c := k8s.FindGatewayCondition(att.Status.Conditions, "ResolvedRefs"); c != nil && c.Status == "False" {
+						finding("CRITICAL", fmt.Sprintf("%s has unresolved backend references on %s: %s", rref, att.ParentRef, conditionDetail(c)))
+					}
+				}
+				// Overlapping host+match on the same gateway
+				hosts := r.Hostnames
+				if len(hosts) == 0 {
+					hosts = []string{"*"}
+				}
+				for _, h := range hosts {
+					for _, rule := range r.Rules {
+						for _, m := range rule.Matches {
+							key := routeKey{att.ParentRef.Namespace + "/" + att.ParentRef.Name, h, describeRouteMatch(r.Kind, &m)}
+							if other, ok := claimed[key]; ok && other != rref {
+								finding("WARNING", fmt.Sprintf("%s and %s both route %s %s on %s", other, rref, h, key.match, key.gateway))
+								actions = append(actions, "Remove overlapping route matches so routing does not depend on precedence tie-breaking")
+							} else {
+								claimed[key] = rref
+							}
+						}
+					}
+				}
+			}
+			for ri, rule := range r.Rules {
+				if len(rule.BackendRefs) == 0 {
+					finding("WARNING", fmt.Sprintf("%s rule %d has no backendRefs — matching requests get an error response", rref, ri+1))
+					continue
+				}
+				for _, b := range rule.BackendRefs {
+					check := backends.check(res, r, b)
+					if check.Severity != "" {
+						finding(check.Severity, fmt.Sprintf("%s rule %d: %s", rref, ri+1, check.Problem))
+						if check.Action != "" {
+							actions = append(actions, check.Action)
+						}
+					}
+				}
+			}
+		}
+		if findings == 0 {
+			sb.WriteString("  No issues found.\n")
+		}
+
+		// Diagram
+		if len(res.Routes) > 0 {
+			sb.WriteString("\nGATEWAY ROUTING DIAGRAM:\n")
+			fc := mermaid.NewFlowchart(mermaid.DirectionLR)
+			addGatewayRoutesToFlowchart(fc, res, res.Routes, backends, nil)
+			sb.WriteString(fc.RenderBlock())
+			sb.WriteString("\n")
+		}
+
+		// Summary
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Summary"))
+		sb.WriteString("\n")
+		sb.WriteString(fmt.Sprintf("  %d GatewayClass(es), %d Gateway(s) in scope, %d route(s), %d ReferenceGrant(s)\n",
+			len(res.Classes), len(gwRows), len(res.Routes), len(res.Grants)))
+		if findings == 0 {
+			sb.WriteString("  Gateway API configuration looks healthy.\n")
+		} else {
+			sb.WriteString(fmt.Sprintf("  %d finding(s) identified. Review details above.\n", findings))
+			actions = append(actions, "Use trace_ingress_to_backend with a hostname and path to trace a single request through the Gateway")
+		}
+		if len(actions) > 0 {
+			sb.WriteString("\nSUGGESTED ACTIONS:\n")
+			for i, a := range dedupe(actions) {
+				sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, a))
+			}
+		}
+
+		return util.SuccessResult(sb.String()), nil, nil
+	})
+}
+
+// gatewayBackendCheck is the resolution result of one route backendRef.
+type gatewayBackendCheck struct {
+	Service *corev1.Service
+	Health  *k8s.EndpointHealth
+	// Severity is empty when the backend resolves to a Service with ready endpoints.
+	Severity string
+	Problem  string
+	Action   string
+}
+
+// gatewayBackendResolver resolves backendRefs to Services and endpoint health, caching lookups.
+type gatewayBackendResolver struct {
+	ctx     context.Context
+	client  *k8s.ClusterClient
+	results map[string]gatewayBackendCheck
+}
+
+func newGatewayBackendResolver(ctx context.Context, client *k8s.ClusterClient) *gatewayBackendResolver {
+	return &gatewayBackendResolver{ctx: ctx, client: client, results: make(map[string]gatewayBackendCheck)}
+}
+
+// check validates a backendRef: kind, ReferenceGrant, Service existence, port and endpoints.
+func (r *gatewayBackendResolver) check(res *k8s.GatewayAPIResources, route *k8s.GatewayRoute, b k8s.RouteBackendRef) gatewayBackendCheck {
+	target := fmt.Sprintf("%s/%s", b.Namespace, b.Name)
+	if !b.IsService() {
+		return gatewayBackendCheck{Severity: "INFO", Problem: fmt.Sprintf("backendRef %s %s is not a Service and was not resolved", b.Kind, target)}
+	}
+	if !k8s.BackendRefPermitted(res.Grants, route, b) {
+		return gatewayBackendCheck{
+			Severity: "CRITICAL",
+			Problem:  fmt.Sprintf("backendRef Service '%s' is in another namespace and no ReferenceGrant permits it", target),
+			Action: fmt.Sprintf("Create a ReferenceGrant in namespace '%s' allowing %s from '%s' to reference Service '%s'",
+				b.Namespace, route.Kind, route.Namespace, b.Name),
+		}
+	}
+
+	key := fmt.Sprintf("%s:%d", target, b.Port)
+	if cached, ok := r.results[key]; ok {
+		return cached
+	}
+	check := gatewayBackendCheck{}
+	svc, err := r.client.GetService(r.ctx, b.Namespace, b.Name)
+	switch {
+	case err != nil:
+		check.Severity = "CRITICAL"
+		check.Problem = fmt.Sprintf("backend Service '%s' not found", target)
+		check.Action = fmt.Sprintf("Create Service '%s' or fix the backendRef", target)
+	default:
+		check.Service = svc
+		portFound := false
+		for _, p := range svc.Spec.Ports {
+			if int64(p.Port) == b.Port {
+				portFound = true
+				break
+			}
+		}
+		if b.Port == 0 {
+			check.Severity = "WARNING"
+			check.Problem = fmt.Sprintf("backendRef Service '%s' does not set a port (required for Services)", target)
+		} else if !portFound {
+			check.Severity = "WARNING"
+			check.Problem = fmt.Sprintf("port %d is not exposed by Service '%s' (ports: %s)", b.Port, target, formatServicePorts(svc))
+			check.Action = fmt.Sprintf("Fix the backendRef port for Service '%s'", target)
+		}
+		if health, err := r.client.GetServiceEndpointHealth(r.ctx, b.Namespace, b.Name); err == nil {
+			check.Health = health
+			if check.Severity == "" && health.ReadyCount == 0 {
+				check.Severity = "CRITICAL"
+				check.Problem = fmt.Sprintf("backend Service '%s' has no ready endpoints", target)
+				check.Action = fmt.Sprintf("Use diagnose_service on '%s' to find why no pods are ready", target)
+			}
+		}
+	}
+	r.results[key] = check
+	return check
+}
+
+// addGatewayRoutesToFlowchart draws Gateway -> Route -> Service edges for the given routes and returns the
+// Gateway node IDs. Service nodes listed in existing are assumed to be drawn by the caller already.
+func addGatewayRoutesToFlowchart(fc *mermaid.Flowchart, res *k8s.GatewayAPIResources, routes []k8s.GatewayRoute, backends *gatewayBackendResolver, existing map[string]bool) []string {
+	var gatewayIDs []string
+	seenGW := make(map[string]bool)
+	seenSvc := make(map[string]bool)
+	for id := range existing {
+		seenSvc[id] = true
+	}
+	for i := range routes {
+		r := &routes[i]
+		routeID := mermaid.SafeID("route_" + r.Kind + "_" + r.Namespace + "_" + r.Name)
+		fc.AddNode(routeID, diagramLabel(fmt.Sprintf("%s: %s%s%s", r.Kind, r.Name, mermaid.BR(), valueOrAny(strings.Join(r.Hostnames, ", ")))), mermaid.ShapeRound)
+		routeOK := true
+		for _, att := range res.RouteAttachments(r) {
+			gwID := mermaid.SafeID("gw_" + att.ParentRef.Namespace + "_" + att.ParentRef.Name)
+			if !seenGW[gwID] {
+				seenGW[gwID] = true
+				gatewayIDs = append(gatewayIDs, gwID)
+				fc.AddNode(gwID, diagramLabel(fmt.Sprintf("Gateway: %s%sns: %s", att.ParentRef.Name, mermaid.BR(), att.ParentRef.Namespace)), mermaid.ShapeTrapAlt)
+				if att.Gateway == nil || gatewayConditionStatus(att.Gateway.Conditions, "Programmed") == "False" {
+					fc.AddStyle(gwID, mermaid.SeverityCritical)
+				} else {
+					fc.AddStyle(gwID, mermaid.SeverityInfo)
+				}
+			}
+			if att.Problem != "" || att.Accepted() == "False" {
+				routeOK = false
+				fc.AddEdge(gwID, routeID, "not attached", mermaid.EdgeDotted)
+			} else {
+				fc.AddEdge(gwID, routeID, "", mermaid.EdgeSolid)
+			}
+		}
+		if routeOK {
+			fc.AddStyle(routeID, mermaid.SeverityHealthy)
+		} else {
+			fc.AddStyle(routeID, mermaid.SeverityCritical)
+		}
+		for _, rule := range r.Rules {
+			label := "*"
+			if len(rule.Matches) > 0 {
+				label = describeRouteMatch(r.Kind, &rule.Matches[0])
+			}
+			for _, b := range rule.BackendRefs {
+				svcID := mermaid.SafeID("svc_" + b.Name)
+				if b.Namespace != r.Namespace {
+					svcID = mermaid.SafeID("svc_" + b.Namespace + "_" + b.Name)
+				}
+				check := backends.check(res, r, b)
+				if !seenSvc[svcID] {
+					seenSvc[svcID] = true
+					fc.AddNode(svcID, diagramLabel(fmt.Sprintf("%s: %s/%s", b.Kind, b.Namespace, b.Name)), mermaid.ShapeRect)
+					switch check.Severity {
+					case "CRITICAL":
+						fc.AddStyle(svcID, mermaid.SeverityCritical)
+					case "WARNING":
+						fc.AddStyle(svcID, mermaid.SeverityWarning)
+					default:
+						fc.AddStyle(svcID, mermaid.SeverityHealthy)
+					}
+				}
+				style := mermaid.EdgeSolid
+				if check.Severity == "CRITICAL" {
+					style = mermaid.EdgeDotted
+				}
+				fc.AddEdge(routeID, svcID, diagramLabel(label), style)
+			}
+		}
+	}
+	return gatewayIDs
+}
+
+// writeGatewayRouteTrace renders the Gateway -> Route -> Service -> Endpoints layers for a matched route.
+// It returns the number of findings and the suggested actions.
+func writeGatewayRouteTrace(ctx context.Context, client *k8s.ClusterClient, sb *strings.Builder, res *k8s.GatewayAPIResources, m *k8s.GatewayRouteMatch, hostname, path string) (int, []string) {
+	findings := 0
+	var actions []string
+	finding := func(severity, msg string) {
+		sb.WriteString(util.FormatFinding(severity, msg))
+		sb.WriteString("\n")
+		findings++
+	}
+	route := m.Route
+	rref := fmt.Sprintf("%s '%s/%s'", route.Kind, route.Namespace, route.Name)
+
+	// [1] GATEWAY
+	sb.WriteString(util.FormatSubHeader("[1] GATEWAY"))
+	sb.WriteString("\n")
+	attachments := res.RouteAttachments(route)
+	if len(attachments) == 0 {
+		finding("CRITICAL", fmt.Sprintf("%s has no parentRefs — it is not attached to any Gateway", rref))
+		actions = append(actions, fmt.Sprintf("Add a parentRef to %s", rref))
+	}
+	var attached *k8s.RouteAttachment
+	for i := range attachments {
+		att := &attachments[i]
+		sb.WriteString(util.FormatKeyValue("Gateway", att.ParentRef.String()))
+		sb.WriteString("\n")
+		if att.Gateway != nil {
+			sb.WriteString(util.FormatKeyValue("Gateway Class", att.Gateway.ClassName))
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatKeyValue("Addresses", valueOrNone(strings.Join(att.Gateway.Addresses, ", "))))
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatKeyValue("Programmed", gatewayConditionStatus(att.Gateway.Conditions, "Programmed")))
+			sb.WriteString("\n")
+			if c := k8s.FindGatewayCondition(att.Gateway.Conditions, "Programmed"); c != nil && c.Status == "False" {
+				finding("CRITICAL", fmt.Sprintf("Gateway '%s' is not programmed: %s", att.ParentRef, conditionDetail(c)))
+			}
+		}
+		for _, ln := range att.Listeners {
+			tls := "none"
+			if len(ln.CertificateRefs) > 0 {
+				tls = strings.Join(ln.CertificateRefs, ", ")
+			}
+			sb.WriteString(util.FormatKeyValue("Listener", fmt.Sprintf("%s (%s:%d, host %s, TLS %s)", ln.Name, ln.Protocol, ln.Port, valueOrAny(ln.Hostname), tls)))
+			sb.WriteString("\n")
+			if c := k8s.FindGatewayCondition(ln.Conditions, "ResolvedRefs"); c != nil && c.Status == "False" {
+				finding("CRITICAL", fmt.Sprintf("Listener '%s' has unresolved references: %s", ln.Name, conditionDetail(c)))
+			}
+		}
+		sb.WriteString(util.FormatKeyValue("Route Accepted", att.Accepted()))
+		sb.WriteString("\n")
+		switch {
+		case att.Problem != "":
+			finding("CRITICAL", fmt.Sprintf("%s cannot attach to %s: %s", rref, att.ParentRef, att.Problem))
+			actions = append(actions, "Run analyze_gateway_api to review listener allowedRoutes and hostnames")
+		case att.Accepted() == "False":
+			finding("CRITICAL", fmt.Sprintf("%s is not accepted by %s: %s", rref, att.ParentRef,
+				conditionDetail(k8s.FindGatewayCondition(att.Status.Conditions, "Accepted"))))
+		case att.Status == nil:
+			finding("WARNING", fmt.Sprintf("No controller has reported status for %s on %s", rref, att.ParentRef))
+		default:
+			if attached == nil {
+				attached = att
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	// [2] ROUTE
+	sb.WriteString(util.FormatSubHeader("[2] ROUTE"))
+	sb.WriteString("\n")
+	sb.WriteString(util.FormatKeyValue("Route", fmt.Sprintf("%s %s/%s", route.Kind, route.Namespace, route.Name)))
+	sb.WriteString("\n")
+	sb.WriteString(util.FormatKeyValue("Matched Host", valueOrAny(m.Hostname)))
+	sb.WriteString("\n")
+	matchDesc := describeRouteMatch(route.Kind, m.Match)
+	if len(m.Rule.Matches) == 0 {
+		matchDesc += " (rule has no matches)"
+	}
+	sb.WriteString(util.FormatKeyValue("Matched Rule", fmt.Sprintf("%d: %s", m.RuleIndex+1, matchDesc)))
+	sb.WriteString("\n")
+	if m.Match.Method != "" || len(m.Match.Headers) > 0 {
+		sb.WriteString(util.FormatFinding("INFO", fmt.Sprintf("Rule also requires method/headers: %s %s", m.Match.Method, strings.Join(m.Match.Headers, ", "))))
+		sb.WriteString("\n")
+	}
+	for _, p := range route.Parents {
+		if c := k8s.FindGatewayCondition(p.Conditions, "ResolvedRefs"); c != nil && c.Status == "False" {
+			finding("CRITICAL", fmt.Sprintf("Controller reports unresolved backend references on %s: %s", p.ParentRef, conditionDetail(c)))
+		}
+	}
+
+	// [3] BACKENDS
+	sb.WriteString("\n")
+	sb.WriteString(util.FormatSubHeader("[3] BACKENDS"))
+	sb.WriteString("\n")
+	backends := newGatewayBackendResolver(ctx, client)
+	var readyTotal, endpointTotal int
+	healthKnown := false
+	if len(m.Rule.BackendRefs) == 0 {
+		finding("CRITICAL", "Matched rule has no backendRefs — requests receive an error response")
+		actions = append(actions, fmt.Sprintf("Add backendRefs to rule %d of %s", m.RuleIndex+1, rref))
+	} else {
+		var rows [][]string
+		for _, b := range m.Rule.BackendRefs {
+			check := backends.check(res, route, b)
+			endpoints := "-"
+			if check.Health != nil {
+				endpoints = fmt.Sprintf("%d/%d", check.Health.ReadyCount, check.Health.TotalEndpoints)
+				healthKnown = true
+				if b.Weight > 0 {
+					readyTotal += check.Health.ReadyCount
+					endpointTotal += check.Health.TotalEndpoints
+				}
+			}
+			status := "OK"
+			if check.Severity != "" {
+				status = check.Severity
+			}
+			rows = append(rows, []string{b.Kind + "/" + b.Namespace + "/" + b.Name, fmt.Sprintf("%d", b.Port), fmt.Sprintf("%d", b.Weight), endpoints, status})
+		}
+		sb.WriteString(util.FormatTable([]string{"BACKEND", "PORT", "WEIGHT", "READY", "STATUS"}, rows))
+		for _, b := range m.Rule.BackendRefs {
+			check := backends.check(res, route, b)
+			if check.Severity != "" {
+				finding(check.Severity, check.Problem)
+				if check.Action != "" {
+					actions = append(actions, check.Action)
+				}
+			} else if check.Health != nil && check.Health.NotReadyCount > 0 {
+				finding("WARNING", fmt.Sprintf("Service '%s/%s' has %d not-ready endpoint(s)", b.Namespace, b.Name, check.Health.NotReadyCount))
+			}
+			if b.Weight == 0 {
+				sb.WriteString(util.FormatFinding("INFO", fmt.Sprintf("Backend '%s/%s' has weight 0 and receives no traffic", b.Namespace, b.Name)))
+				sb.WriteString("\n")
+			}
+			if check.Service != nil {
+				pods, err := client.GetPodsForService(ctx, check.Service)
+				if err == nil {
+					for i := range pods {
+						if !isPodHealthy(&pods[i]) {
+							finding("WARNING", fmt.Sprintf("Backend pod '%s' is unhealthy: %s", pods[i].Name, podPhaseReason(&pods[i])))
+							actions = append(actions, fmt.Sprintf("Diagnose pod '%s' with diagnose_pod tool", pods[i].Name))
+						}
+					}
+				}
+			}
+		}
+	}
+
+	// Request flow
+	sb.WriteString("\nREQUEST FLOW DIAGRAM:\n")
+	seq := mermaid.NewSequence()
+	seq.AddParticipant("CLIENT", "Client")
+	gwLabel := "Gateway"
+	if attached != nil {
+		gwLabel = "Gateway: " + attached.ParentRef.Name
+	} else if len(attachments) > 0 {
+		gwLabel = "Gateway: " + attachments[0].ParentRef.Name
+	}
+	seq.AddParticipant("GW", gwLabel)
+	seq.AddParticipant("ROUTE", fmt.Sprintf("%s: %s", route.Kind, route.Name))
+	seq.AddParticipant("SVC", "Backends")
+	seq.AddMessage("CLIENT", "GW", hostname+path, mermaid.MsgSolid)
+	if attached == nil {
+		seq.AddMessage("GW", "CLIENT", "404 - route not attached", mermaid.MsgDotted)
+	} else {
+		seq.AddMessage("GW", "ROUTE", "match "+matchDesc, mermaid.MsgSolid)
+		switch {
+		case len(m.Rule.BackendRefs) == 0:
+			seq.AddMessage("ROUTE", "CLIENT", "500 - no backendRefs", mermaid.MsgDotted)
+		case !healthKnown:
+			seq.AddMessage("ROUTE", "SVC", "endpoints unknown", mermaid.MsgDotted)
+		case readyTotal == 0:
+			seq.AddMessage("ROUTE", "SVC", "NO READY ENDPOINTS", mermaid.MsgDotted)
+			seq.AddNote("SVC", "500/503 - no backends", mermaid.NoteRight)
+		default:
+			seq.AddMessage("ROUTE", "SVC", fmt.Sprintf("%d/%d ready endpoint(s)", readyTotal, endpointTotal), mermaid.MsgSolid)
+		}
+	}
+	sb.WriteString(seq.RenderBlock())
+	sb.WriteString("\n")
+
+	return findings, actions
+}
+
+// writeGatewayRouteFallback renders the Gateway API trace used when no Ingress serves host+path,
+// followed by the summary and suggested actions.
+func writeGatewayRouteFallback(ctx context.Context, client *k8s.ClusterClient, sb *strings.Builder, res *k8s.GatewayAPIResources, m *k8s.GatewayRouteMatch, hostname, path string) {
+	sb.WriteString("  No Ingress matches this host/path; tracing the Gateway API route instead.\n\n")
+	findings, actions := writeGatewayRouteTrace(ctx, client, sb, res, m, hostname, path)
+	sb.WriteString("\n")
+	sb.WriteString(util.FormatSubHeader("Summary"))
+	sb.WriteString("\n")
+	if findings == 0 {
+		sb.WriteString("  Request path appears healthy. Requests to " + hostname + path + " should be routed correctly.\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("  %d finding(s) across the request path. Review details above.\n", findings))
+	}
+	if len(actions) > 0 {
+		sb.WriteString("\nSUGGESTED ACTIONS:\n")
+		for i, a := range dedupe(actions) {
+			sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, a))
+		}
+	}
+}
+
+// findGatewayRoute looks up a Gateway API route serving host+path. It returns nil when Gateway API is not
+// installed or nothing matches.
+func findGatewayRoute(ctx context.Context, client *k8s.ClusterClient, namespace, hostname, path string) (*k8s.GatewayAPIResources, *k8s.GatewayRouteMatch) {
+	res, err := client.GetGatewayAPIResources(ctx, namespace)
+	if err != nil || !res.AnyInstalled() {
+		return nil, nil
+	}
+	return res, res.FindRouteForHostPath(hostname, path)
+}
+
+func describeRouteMatch(kind string, m *k8s.RouteMatch) string {
+	if kind == k8s.KindGRPCRoute {
+		service, method := m.GRPCService, m.GRPCMethod
+		if service == "" {
+			service = "*"
+		}
+		if method == "" {
+			method = "*"
+		}
+		return fmt.Sprintf("grpc %s/%s", service, method)
+	}
+	pathType, value := m.PathType, m.PathValue
+	if pathType == "" {
+		pathType = "PathPrefix"
+	}
+	if value == "" {
+		value = "/"
+	}
+	return fmt.Sprintf("%s %s", pathType, value)
+}
+
+// routeStatusSummary condenses Accepted and ResolvedRefs across a route's parents.
+func routeStatusSummary(r *k8s.GatewayRoute) (string, string) {
+	if len(r.Parents) == 0 {
+		return "Unknown", "Unknown"
+	}
+	accepted, resolved := "True", "True"
+	for _, p := range r.Parents {
+		if s := gatewayConditionStatus(p.Conditions, "Accepted"); s != "True" {
+			accepted = s
+		}
+		if s := gatewayConditionStatus(p.Conditions, "ResolvedRefs"); s != "True" {
+			resolved = s
+		}
+	}
+	return accepted, resolved
+}
+
+func gatewayConditionStatus(conds []k8s.GatewayCondition, condType string) string {
+	if c := k8s.FindGatewayCondition(conds, condType); c != nil {
+		return c.Status
+	}
+	return "Unknown"
+}
+
+func conditionDetail(c *k8s.GatewayCondition) string {
+	if c == nil {
+		return "no details"
+	}
+	if c.Message != "" {
+		return fmt.Sprintf("%s — %s", c.Reason, c.Message)
+	}
+	return c.Reason
+}
+
+func valueOrAny(s string) string {
+	if s == "" {
+		return "*"
+	}
+	return s
+}
@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
+)
+
+func TestWriteGatewayRouteFallback(t *testing.T) {
+	ref := k8s.RouteParentRef{Namespace: "infra", Name: "public"}
+	res := &k8s.GatewayAPIResources{
+		Gateways: []k8s.GatewayInfo{{Namespace: "infra", Name: "public", Listeners: []k8s.GatewayListener{{
+			Name: "http", Protocol: "HTTP", Port: 80, AllowedRoutesFrom: "All",
+		}}}},
+		Routes: []k8s.GatewayRoute{{
+			Kind: k8s.KindHTTPRoute, Namespace: "shop", Name: "store",
+			ParentRefs: []k8s.RouteParentRef{ref},
+			Rules:      []k8s.RouteRule{{}},
+			Parents: []k8s.RouteParentStatus{{ParentRef: ref, Conditions: []k8s.GatewayCondition{
+				{Type: "Accepted", Status: "True"},
+			}}},
+		}},
+	}
+	m := res.FindRouteForHostPath("store.example.com", "/checkout")
+	if m == nil {
+		t.Fatal("expected the accepted route to match")
+	}
+
+	var sb strings.Builder
+	client := k8s.NewClusterClientForTesting(fake.NewSimpleClientset(), nil)
+	writeGatewayRouteFallback(context.Background(), client, &sb, res, m, "store.example.com", "/checkout")
+	out := sb.String()
+
+	for _, want := range []string{
+		"tracing the Gateway API route instead",
+		"PathPrefix / (rule has no matches)",
+		"Matched rule has no backendRefs",
+		"1 finding(s) across the request path",
+		"SUGGESTED ACTIONS:\n1. Add backendRefs to rule 1 of HTTPRoute 'shop/store'",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/health"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+type getResourceInput struct {
+	Kind       string `json:"kind" jsonschema:"required,Resource kind, plural or short name, optionally with group (e.g. pod, deploy, certificates.cert-manager.io)"`
+	Name       string `json:"name" jsonschema:"required,Object name"`
+	Namespace  string `json:"namespace,omitempty" jsonschema:"Namespace for namespaced kinds (default: default)"`
+	APIVersion string `json:"api_version,omitempty" jsonschema:"Pin the group/version, e.g. cert-manager.io/v1 (default: highest served version)"`
+	Output     string `json:"output,omitempty" jsonschema:"yaml (default) or json"`
+}
+
+type describeResourceInput struct {
+	Kind       string `json:"kind" jsonschema:"required,Resource kind, plural or short name, optionally with group (e.g. pod, deploy, certificates.cert-manager.io)"`
+	Name       string `json:"name" jsonschema:"required,Object name"`
+	Namespace  string `json:"namespace,omitempty" jsonschema:"Namespace for namespaced kinds (default: default)"`
+	APIVersion string `json:"api_version,omitempty" jsonschema:"Pin the group/version, e.g. cert-manager.io/v1 (default: highest served version)"`
+}
+
+const (
+	// maxResourceLines caps rendered YAML/JSON so a huge object cannot flood the response.
+	maxResourceLines = 500
+	// maxSpecLines caps the spec excerpt in describe_resource.
+	maxSpecLines = 80
+)
+
+func registerGenericResourceTools(server *mcp.Server, client *k8s.ClusterClient) {
+	// get_resource
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "get_resource",
+		Description: "Get any object by kind and name — built-in or CRD — resolved through API discovery (kinds, plurals and short names " +
+			"like deploy, pvc, certificates.cert-manager.io) and fetched with the dynamic client. Returns YAML or JSON with " +
+			"managedFields and the last-applied annotation stripped; Secret values are redacted.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input getResourceInput) (*mcp.CallToolResult, any, error) {
+		output := strings.ToLower(input.Output)
+		if output == "" {
+			output = "yaml"
+		}
+		if output != "yaml" && output != "json" {
+			return util.ErrorResult("unsupported output %q (use yaml or json)", input.Output), nil, nil
+		}
+		res, obj, errResult := fetchGenericResource(ctx, client, input.Kind, input.APIVersion, input.Namespace, input.Name)
+		if errResult != nil {
+			return errResult, nil, nil
+		}
+
+		rendered, err := renderObject(k8s.SanitizeForDisplay(obj).Object, output)
+		if err != nil {
+			return util.ErrorResult("rendering %s %s: %v", res.Kind, obj.GetName(), err), nil, nil
+		}
+
+		var sb strings.Builder
+		sb.WriteString(util.FormatHeader(fmt.Sprintf("%s: %s", res.Kind, objectRef(obj))))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("Resource", res.String()))
+		sb.WriteString("\n\n")
+		sb.WriteString("```" + output + "\n")
+		sb.WriteString(truncateLines(rendered, maxResourceLines))
+		sb.WriteString("```\n")
+
+		return util.SuccessResult(sb.String()), nil, nil
+	})
+
+	// describe_resource
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "describe_resource",
+		Description: "Describe any object by kind and name — built-in or CRD — like kubectl describe: metadata, owners, finalizers, " +
+			"a generic status summary (status fields, status.conditions with polarity-aware problem detection, observedGeneration " +
+			"lag, stuck deletion), a spec excerpt and related events. Use it for kinds the typed tools do not cover.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input describeResourceInput) (*mcp.CallToolResult, any, error) {
+		res, obj, errResult := fetchGenericResource(ctx, client, input.Kind, input.APIVersion, input.Namespace, input.Name)
+		if errResult != nil {
+			return errResult, nil, nil
+		}
+		clean := k8s.SanitizeForDisplay(obj)
+		status := k8s.SummarizeObjectStatus(obj, time.Now())
+		objHealth := health.Compute(obj)
+
+		var sb strings.Builder
+		sb.WriteString(util.FormatHeader(fmt.Sprintf("%s: %s", res.Kind, objectRef(obj))))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("Resource", res.String()))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("Age", util.FormatAge(obj.GetCreationTimestamp().Time)))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("Labels", valueOrNone(util.FormatLabels(obj.GetLabels()))))
+		sb.WriteString("\n")
+		if ann := clean.GetAnnotations(); len(ann) > 0 {
+			keys := make([]string, 0, len(ann))
+			for k := range ann {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			sb.WriteString(util.FormatKeyValue("Annotations", ""))
+			sb.WriteString("\n")
+			for _, k := range keys {
+				sb.WriteString(fmt.Sprintf("  %s: %s\n", k, util.TruncateString(ann[k], 100)))
+			}
+		}
+		if owners := obj.GetOwnerReferences(); len(owners) > 0 {
+			refs := make([]string, 0, len(owners))
+			for _, o := range owners {
+				refs = append(refs, o.Kind+"/"+o.Name)
+			}
+			sb.WriteString(util.FormatKeyValue("Owned By", strings.Join(refs, ", ")))
+			sb.WriteString("\n")
+		}
+		if len(status.Finalizers) > 0 {
+			sb.WriteString(util.FormatKeyValue("Finalizers", strings.Join(status.Finalizers, ", ")))
+			sb.WriteString("\n")
+		}
+		if status.DeletionTimestamp != nil {
+			sb.WriteString(util.FormatKeyValue("Deleting Since", util.FormatAge(*status.DeletionTimestamp)+" ago"))
+			sb.WriteString("\n")
+		}
+
+		// Status
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Status"))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("Health", objHealth.String()))
+		sb.WriteString("\n")
+		if status.ObservedGeneration >= 0 {
+			sb.WriteString(util.FormatKeyValue("Generation", fmt.Sprintf("%d (observed %d)", status.Generation, status.ObservedGeneration)))
+			sb.WriteString("\n")
+		}
+		for _, f := range status.Fields {
+			sb.WriteString(util.FormatKeyValue(f[0], util.TruncateString(f[1], 120)))
+			sb.WriteString("\n")
+		}
+		if len(status.Fields) == 0 && status.ObservedGeneration < 0 && len(status.Conditions) == 0 {
+			sb.WriteString("  No status fields reported.\n")
+		}
+		if len(status.Conditions) > 0 {
+			rows := make([][]string, 0, len(status.Conditions))
+			for _, c := range status.Conditions {
+				age := "-"
+				if !c.LastTransitionTime.IsZero() {
+					age = util.FormatAge(c.LastTransitionTime)
+				}
+				rows = append(rows, []string{c.Type, c.Status, valueOrNone(c.Reason), age, util.TruncateString(c.Message, 90)})
+			}
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatTable([]string{"CONDITION", "STATUS", "REASON", "AGE", "MESSAGE"}, rows))
+		}
+
+		// Spec excerpt
+		if spec, found, _ := unstructured.NestedFieldNoCopy(clean.Object, "spec"); found {
+			if rendered, err := renderObject(spec, "yaml"); err == nil {
+				sb.WriteString("\n")
+				sb.WriteString(util.FormatSubHeader("Spec"))
+				sb.WriteString("\n```yaml\n")
+				sb.WriteString(truncateLines(rendered, maxSpecLines))
+				sb.WriteString("```\n")
+			}
+		}
+
+		// Events
+		eventNS := obj.GetNamespace()
+		events, err := client.GetEventsForInvolvedObject(ctx, eventNS, res.Kind, obj.GetName())
+		warnings := 0
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Events"))
+		sb.WriteString("\n")
+		switch {
+		case err != nil:
+			sb.WriteString(fmt.Sprintf("  Could not list events: %v\n", err))
+		case len(events) == 0:
+			sb.WriteString("  No events recorded (events expire after about an hour).\n")
+		default:
+			rows := make([][]string, 0, len(events))
+			for _, e := range events {
+				if e.Type == "Warning" {
+					warnings++
+				}
+				rows = append(rows, []string{e.Type, e.Reason, util.FormatAge(e.LastTimestamp.Time), fmt.Sprintf("%d", e.Count), util.TruncateString(e.Message, 100)})
+			}
+			sb.WriteString(util.FormatTable([]string{"TYPE", "REASON", "AGE", "COUNT", "MESSAGE"}, rows))
+		}
+
+		// Findings
+		findings := 0
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Findings"))
+		sb.WriteString("\n")
+		for _, issue := range status.Issues {
+			sb.WriteString(util.FormatFinding(issue.Severity, issue.Problem))
+			sb.WriteString("\n")
+			if issue.Severity != "INFO" {
+				findings++
+			}
+		}
+		if warnings > 0 {
+			sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("%d Warning event(s) recorded for this object", warnings)))
+			sb.WriteString("\n")
+			findings++
+		}
+		if findings == 0 && len(status.Issues) == 0 {
+			sb.WriteString(util.FormatFinding("OK", "No problems detected in status or events"))
+			sb.WriteString("\n")
+		}
+
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Summary"))
+		sb.WriteString("\n")
+		sb.WriteString(fmt.Sprintf("  Health %s, %d finding(s).\n", objHealth.Status, findings))
+		if tool := diagnoseToolFor(res.Kind, obj.GetNamespace(), obj.GetName()); tool != "" && (findings > 0 || !objHealth.Healthy()) {
+			sb.WriteString("\nSUGGESTED ACTIONS:\n")
+			sb.WriteString(fmt.Sprintf("1. Run %s for a kind-specific diagnosis\n", tool))
+		}
+
+		return util.SuccessResult(sb.String()), nil, nil
+	})
+}
+
+// fetchGenericResource resolves kind through discovery and fetches the object. Namespaced kinds
+// default to the "default" namespace, as with kubectl. On failure it returns the tool error result.
+func fetchGenericResource(ctx context.Context, client *k8s.ClusterClient, kind, apiVersion, namespace, name string) (*k8s.ResolvedResource, *unstructured.Unstructured, *mcp.CallToolResult) {
+	if name == "" {
+		return nil, nil, util.ErrorResult("name is required")
+	}
+	lists, err := client.GetAPIResources(ctx)
+	if err != nil {
+		return nil, nil, util.HandleK8sError("getting API resources", err)
+	}
+	res, err := k8s.ResolveResource(lists, kind, apiVersion)
+	if err != nil {
+		return nil, nil, util.ErrorResult("%v", err)
+	}
+	if res.Namespaced && namespace == "" {
+		namespace = "default"
+	}
+	obj, err := client.GetDynamicResource(ctx, res, namespace, name)
+	if err != nil {
+		ref := name
+		if res.Namespaced {
+			ref = namespace + "/" + name
+		}
+		return nil, nil, util.HandleK8sError(fmt.Sprintf("getting %s %s", res.Kind, ref), err)
+	}
+	return res, obj, nil
+}
+
+// renderObject marshals an object (or any part of one) as YAML or indented JSON.
+func renderObject(v interface{}, output string) (string, error) {
+	if output == "json" {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data) + "\n", nil
+	}
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// truncateLines keeps the first max lines of s and notes how many were dropped.
+func truncateLines(s string, max int) string {
+	lines := strings.SplitAfter(s, "\n")
+	if len(lines) <= max {
+		return s
+	}
+	return strings.Join(lines[:max], "") + fmt.Sprintf("# ... %d more line(s) truncated\n", len(lines)-max)
+}
+
+// objectRef formats an object as namespace/name, or just name when cluster-scoped.
+func objectRef(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" {
+		return obj.GetName()
+	}
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
+
+// diagnoseToolFor returns the invocation of the kind-specific diagnosis tool for an object, or ""
+// when only the generic tools apply.
+func diagnoseToolFor(kind, namespace, name string) string {
+	switch kind {
+	case "Pod":
+		return fmt.Sprintf("diagnose_pod namespace=%s name=%s", namespace, name)
+	case "Deployment":
+		return fmt.Sprintf("get_deployment_detail namespace=%s name=%s", namespace, name)
+	case "Service":
+		return fmt.Sprintf("diagnose_service namespace=%s service_name=%s", namespace, name)
+	case "PersistentVolumeClaim":
+		return fmt.Sprintf("diagnose_pvc namespace=%s name=%s", namespace, name)
+	case "Node":
+		return fmt.Sprintf("diagnose_node name=%s", name)
+	case "Namespace":
+		return fmt.Sprintf("diagnose_namespace namespace=%s", name)
+	case "Kustomization":
+		return fmt.Sprintf("diagnose_flux_kustomization namespace=%s name=%s", namespace, name)
+	case "HelmRelease":
+		return fmt.Sprintf("diagnose_flux_helm_release namespace=%s name=%s", namespace, name)
+	}
+	return ""
+}
@@ -0,0 +1,388 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/health"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+type diagnoseImagePullInput struct {
+	Namespace string `json:"namespace" jsonschema:"required,Kubernetes namespace"`
+	Name      string `json:"name" jsonschema:"required,Pod name"`
+}
+
+// imagePullWaitingReasons are container waiting reasons caused by image pulls.
+var imagePullWaitingReasons = map[string]bool{"ErrImagePull": true, "ImagePullBackOff": true, "InvalidImageName": true, "ErrImageNeverPull": true}
+
+// pullSecretRef is an imagePullSecret and where it was configured.
+type pullSecretRef struct {
+	name   string
+	source string
+}
+
+func registerImagePullTools(server *mcp.Server, client *k8s.ClusterClient) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "diagnose_image_pull",
+		Description: "Find the root cause of ErrImagePull/ImagePullBackOff for a pod. Classifies pull errors from events " +
+			"(not found, unauthorized, x509, network timeout, rate limiting), verifies the pod's and ServiceAccount's " +
+			"imagePullSecrets exist and contain an entry for the image's registry host (credentials are never printed), " +
+			"and checks whether other pods on the same node pull from that registry successfully.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input diagnoseImagePullInput) (*mcp.CallToolResult, any, error) {
+		pod, err := client.GetPod(ctx, input.Namespace, input.Name)
+		if err != nil {
+			return util.HandleK8sError(fmt.Sprintf("getting pod %s/%s", input.Namespace, input.Name), err), nil, nil
+		}
+
+		var sb strings.Builder
+		sb.WriteString(util.FormatHeader(fmt.Sprintf("Image Pull Diagnosis: %s (namespace: %s)", pod.Name, pod.Namespace)))
+		sb.WriteString("\n\n")
+		saName := pod.Spec.ServiceAccountName
+		if saName == "" {
+			saName = "default"
+		}
+		sb.WriteString(util.FormatKeyValue("Node", valueOrNone(pod.Spec.NodeName)))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("ServiceAccount", saName))
+		sb.WriteString("\n")
+		podHealth := health.Pod(pod)
+		sb.WriteString(util.FormatKeyValue("Health", podHealth.String()))
+		sb.WriteString("\n")
+		findings := 0
+		var actions []string
+
+		// Pull failure messages from events, keyed by container name
+		eventMessages := make(map[string][]string)
+		if events, err := client.GetEventsForObject(ctx, pod.Namespace, pod.Name); err == nil {
+			for _, e := range events {
+				if e.InvolvedObject.Name != pod.Name || e.Type != corev1.EventTypeWarning {
+					continue
+				}
+				if e.Reason != "Failed" && e.Reason != "InspectFailed" && e.Reason != "ErrImageNeverPull" {
+					continue
+				}
+				container := containerFromFieldPath(e.InvolvedObject.FieldPath)
+				eventMessages[container] = append(eventMessages[container], e.Message)
+			}
+		}
+
+		// 1. Failing containers
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Pull Failures"))
+		sb.WriteString("\n")
+		statuses := make(map[string]corev1.ContainerStatus)
+		for _, cs := range append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+			statuses[cs.Name] = cs
+		}
+		registries := make(map[string]bool)
+		publicRegistry := make(map[string]bool) // every image pulled from the registry is public
+		var checkRegistries []string
+		causes := make(map[string]bool)
+		failing := 0
+		for _, c := range append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+			ref := util.ParseImageReference(c.Image)
+			if seen, ok := publicRegistry[ref.Registry]; ok {
+				publicRegistry[ref.Registry] = seen && isPublicImage(ref)
+			} else {
+				publicRegistry[ref.Registry] = isPublicImage(ref)
+			}
+			registries[ref.Registry] = true
+			cs := statuses[c.Name]
+			if cs.State.Waiting == nil || !imagePullWaitingReasons[cs.State.Waiting.Reason] {
+				continue
+			}
+			failing++
+			if !containsString(checkRegistries, ref.Registry) {
+				checkRegistries = append(checkRegistries, ref.Registry)
+			}
+			sb.WriteString(fmt.Sprintf("  Container %q\n", c.Name))
+			sb.WriteString(fmt.Sprintf("    Image:      %s\n", c.Image))
+			sb.WriteString(fmt.Sprintf("    Registry:   %s\n", ref.Registry))
+			sb.WriteString(fmt.Sprintf("    Repository: %s\n", ref.Repository))
+			sb.WriteString(fmt.Sprintf("    Tag:        %s\n", valueOrNone(ref.Tag)))
+			if ref.Digest != "" {
+				sb.WriteString(fmt.Sprintf("    Digest:     %s\n", ref.Digest))
+			}
+			sb.WriteString(fmt.Sprintf("    State:      %s\n", cs.State.Waiting.Reason))
+
+			// Prefer the most specific message: events carry the runtime error, the waiting message often only says "Back-off"
+			messages := append(append([]string{}, eventMessages[c.Name]...), cs.State.Waiting.Message)
+			cause := util.ImagePullCause{Category: "Unknown"}
+			causeMessage := ""
+			for _, m := range messages {
+				if m == "" {
+					continue
+				}
+				if cc := util.ClassifyImagePullError(m); cc.Category != "Unknown" {
+					cause, causeMessage = cc, m
+					break
+				}
+				if causeMessage == "" {
+					causeMessage = m
+				}
+			}
+			if cs.State.Waiting.Reason == "InvalidImageName" {
+				cause = util.ClassifyImagePullError("invalid reference format")
+			}
+			if cs.State.Waiting.Reason == "ErrImageNeverPull" {
+				cause = util.ImagePullCause{Category: "NeverPull", Explanation: "imagePullPolicy is Never and the image is not present on the node"}
+			}
+			if causeMessage != "" {
+				sb.WriteString(fmt.Sprintf("    Message:    %s\n", causeMessage))
+			}
+			if cause.Category == "Unknown" {
+				sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("Pull of %s is failing; no recognizable error in events (events may have expired)", c.Image)))
+			} else {
+				sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("%s: %s", cause.Category, cause.Explanation)))
+			}
+			sb.WriteString("\n")
+			findings++
+			causes[cause.Category] = true
+			actions = append(actions, imagePullActions(cause.Category, c.Image, ref)...)
+		}
+		if failing == 0 {
+			sb.WriteString(util.FormatFinding("OK", "No containers are currently failing to pull their image"))
+			sb.WriteString("\n")
+			if !podHealth.Healthy() {
+				sb.WriteString(util.FormatFinding("INFO", "Pod is not healthy for reasons other than image pulls: "+podHealth.String()))
+				sb.WriteString("\n")
+				actions = append(actions, fmt.Sprintf("Run diagnose_pod on %s/%s to investigate the pod's health", pod.Namespace, pod.Name))
+			}
+			checkRegistries = make([]string, 0, len(registries))
+			for r := range registries {
+				checkRegistries = append(checkRegistries, r)
+			}
+		}
+		sort.Strings(checkRegistries)
+
+		// 2. Pull secrets
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Pull Credentials"))
+		sb.WriteString("\n")
+		var refs []pullSecretRef
+		for _, s := range pod.Spec.ImagePullSecrets {
+			refs = append(refs, pullSecretRef{name: s.Name, source: "pod"})
+		}
+		sa, err := client.GetServiceAccount(ctx, pod.Namespace, saName)
+		switch {
+		case apierrors.IsNotFound(err):
+			sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("ServiceAccount %s does not exist", saName)))
+			sb.WriteString("\n")
+			findings++
+		case err != nil:
+			sb.WriteString(util.FormatFinding("INFO", fmt.Sprintf("Unable to read ServiceAccount %s: %v", saName, err)))
+			sb.WriteString("\n")
+		default:
+			for _, s := range sa.ImagePullSecrets {
+				refs = append(refs, pullSecretRef{name: s.Name, source: "serviceaccount/" + saName})
+			}
+		}
+
+		covered := make(map[string]bool)
+		if len(refs) == 0 {
+			sb.WriteString("  No imagePullSecrets on the pod or its ServiceAccount.\n")
+		} else {
+			headers := []string{"SECRET", "SOURCE", "STATUS", "REGISTRY ENTRIES"}
+			var rows [][]string
+			var problems []string
+			for _, r := range refs {
+				status, hosts := checkPullSecret(ctx, client, pod.Namespace, r.name, checkRegistries, covered)
+				rows = append(rows, []string{r.name, r.source, status, hosts})
+				if status != "OK" {
+					problems = append(problems, fmt.Sprintf("imagePullSecret %s (%s): %s", r.name, r.source, status))
+					actions = append(actions, fmt.Sprintf("Fix imagePullSecret %s/%s (%s)", pod.Namespace, r.name, status))
+				}
+			}
+			sb.WriteString(util.FormatTable(headers, rows))
+			for _, p := range problems {
+				sb.WriteString(util.FormatFinding("CRITICAL", p))
+				sb.WriteString("\n")
+				findings++
+			}
+		}
+		for _, registry := range checkRegistries {
+			switch {
+			case covered[registry]:
+				sb.WriteString(util.FormatFinding("OK", fmt.Sprintf("A pull secret has credentials for %s", registry)))
+			case publicRegistry[registry] && !causes["Unauthorized"] && !causes["NotFoundOrUnauthorized"]:
+				sb.WriteString(util.FormatFinding("INFO", fmt.Sprintf("No pull secret for %s (public images; anonymous pulls may be rate limited)", registry)))
+			case failing == 0:
+				sb.WriteString(util.FormatFinding("INFO", fmt.Sprintf("No pull secret has credentials for %s — pulls rely on node-level credentials", registry)))
+			default:
+				sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("No pull secret has credentials for %s — the pull relies on node-level credentials", registry)))
+				findings++
+				actions = append(actions, fmt.Sprintf("Create a docker-registry secret for %s and add it to the pod's or ServiceAccount %s's imagePullSecrets", registry, saName))
+			}
+			sb.WriteString("\n")
+		}
+
+		// 3. Other pods on the same node
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Same-Node Comparison"))
+		sb.WriteString("\n")
+		if failing == 0 {
+			sb.WriteString("  Skipped — no pull failures to compare.\n")
+		} else if pod.Spec.NodeName == "" {
+			sb.WriteString("  Pod is not scheduled to a node.\n")
+		} else if nodePods, err := client.ListPods(ctx, "", metav1.ListOptions{FieldSelector: "spec.nodeName=" + pod.Spec.NodeName}); err != nil {
+			sb.WriteString(fmt.Sprintf("  Unable to list pods on node %s: %v\n", pod.Spec.NodeName, err))
+		} else {
+			for _, registry := range checkRegistries {
+				ok, failed := sameNodeRegistryPulls(nodePods, pod, registry)
+				switch {
+				case len(ok) > 0:
+					msg := fmt.Sprintf("%d other pod(s) on %s run images from %s (e.g. %s)", len(ok), pod.Spec.NodeName, registry, strings.Join(truncateList(ok, 2), ", "))
+					if causes["Network"] || causes["TLS"] {
+						msg += " — the node can reach the registry; check proxy/egress rules for this repository"
+					} else {
+						msg += " — connectivity is fine; the problem is specific to this image or its credentials"
+					}
+					sb.WriteString(util.FormatFinding("INFO", msg))
+				case len(failed) > 0:
+					sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("%d other pod(s) on %s also fail to pull from %s (%s) — likely a node or registry-wide problem", len(failed), pod.Spec.NodeName, registry, strings.Join(truncateList(failed, 2), ", "))))
+					findings++
+					actions = append(actions, fmt.Sprintf("Check DNS, egress and registry availability from node %s", pod.Spec.NodeName))
+				default:
+					sb.WriteString(util.FormatFinding("INFO", fmt.Sprintf("No other pods on %s use %s", pod.Spec.NodeName, registry)))
+				}
+				sb.WriteString("\n")
+			}
+		}
+
+		// Summary
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Summary"))
+		sb.WriteString("\n")
+		if findings == 0 {
+			sb.WriteString("  No image pull issues found.\n")
+		} else {
+			sb.WriteString(fmt.Sprintf("  %d finding(s) identified. Review details above.\n", findings))
+		}
+		if len(actions) > 0 {
+			sb.WriteString("\nSUGGESTED ACTIONS:\n")
+			for i, a := range dedupe(actions) {
+				sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, a))
+			}
+		}
+
+		return util.SuccessResult(sb.String()), nil, nil
+	})
+}
+
+// checkPullSecret validates an imagePullSecret and records which registries it covers.
+// It returns a status ("OK" or a problem) and the registry hosts it has entries for; credentials are never read out.
+func checkPullSecret(ctx context.Context, client *k8s.ClusterClient, namespace, name string, registries []string, covered map[string]bool) (string, string) {
+	secret, err := client.GetSecret(ctx, namespace, name)
+	if apierrors.IsNotFound(err) {
+		return "secret does not exist", "-"
+	}
+	if err != nil {
+		return fmt.Sprintf("unable to read secret: %v", err), "-"
+	}
+
+	var data []byte
+	switch secret.Type {
+	case corev1.SecretTypeDockerConfigJson:
+		data = secret.Data[corev1.DockerConfigJsonKey]
+	case corev1.SecretTypeDockercfg:
+		data = secret.Data[corev1.DockerConfigKey]
+	default:
+		return fmt.Sprintf("wrong type %s (need %s)", secret.Type, corev1.SecretTypeDockerConfigJson), "-"
+	}
+	entries, err := util.ParseDockerConfig(data)
+	if err != nil {
+		return "malformed docker config", "-"
+	}
+
+	var hosts []string
+	for _, e := range entries {
+		host := e.Host
+		if !e.HasCredentials {
+			host += " (no credentials)"
+		}
+		hosts = append(hosts, host)
+		for _, r := range registries {
+			if e.HasCredentials && util.RegistryHostMatches(e.Host, r) {
+				covered[r] = true
+			}
+		}
+	}
+	if len(hosts) == 0 {
+		return "no registry entries", "-"
+	}
+	return "OK", strings.Join(hosts, ", ")
+}
+
+// sameNodeRegistryPulls splits other pods on the node into those running an image from the registry and those failing to pull one.
+func sameNodeRegistryPulls(nodePods []corev1.Pod, target *corev1.Pod, registry string) (ok, failed []string) {
+	for i := range nodePods {
+		p := &nodePods[i]
+		if p.Spec.NodeName != target.Spec.NodeName || (p.Namespace == target.Namespace && p.Name == target.Name) {
+			continue
+		}
+		images := make(map[string]string)
+		for _, c := range append(append([]corev1.Container{}, p.Spec.InitContainers...), p.Spec.Containers...) {
+			images[c.Name] = c.Image
+		}
+		name := p.Namespace + "/" + p.Name
+		for _, cs := range append(append([]corev1.ContainerStatus{}, p.Status.InitContainerStatuses...), p.Status.ContainerStatuses...) {
+			if util.ParseImageReference(images[cs.Name]).Registry != registry {
+				continue
+			}
+			if cs.State.Waiting != nil && imagePullWaitingReasons[cs.State.Waiting.Reason] {
+				failed = append(failed, name)
+				break
+			}
+			if cs.ImageID != "" {
+				ok = append(ok, name)
+				break
+			}
+		}
+	}
+	return ok, failed
+}
+
+// containerFromFieldPath extracts the container name from an event fieldPath such as "spec.containers{app}".
+func containerFromFieldPath(fieldPath string) string {
+	start := strings.Index(fieldPath, "{")
+	end := strings.LastIndex(fieldPath, "}")
+	if start < 0 || end <= start {
+		return ""
+	}
+	return fieldPath[start+1 : end]
+}
+
+// imagePullActions suggests fixes for a classified pull failure.
+func imagePullActions(category, image string, ref util.ImageReference) []string {
+	switch category {
+	case "ImageNotFound":
+		return []string{fmt.Sprintf("Verify %s exists: check the tag spelling and that it was pushed to %s", image, ref.Registry)}
+	case "Unauthorized", "NotFoundOrUnauthorized":
+		return []string{
+			fmt.Sprintf("Verify %s exists and the pull secret for %s has valid, unexpired credentials", image, ref.Registry),
+			fmt.Sprintf("Test the credentials locally: docker login %s && docker pull %s", ref.Registry, image),
+		}
+	case "TLS":
+		return []string{fmt.Sprintf("Install the CA for %s in the node trust store / container runtime (e.g. containerd certs.d)", ref.Registry)}
+	case "Network":
+		return []string{fmt.Sprintf("Check DNS resolution, firewall/egress rules and proxy settings from the node to %s", ref.Registry)}
+	case "RateLimited":
+		return []string{fmt.Sprintf("Authenticate pulls from %s or use a pull-through cache/mirror to avoid rate limits", ref.Registry)}
+	case "InvalidName":
+		return []string{fmt.Sprintf("Fix the image reference %q (lowercase repository, valid tag characters)", image)}
+	case "PlatformMismatch":
+		return []string{fmt.Sprintf("Publish a multi-arch image for %s or constrain the pod to matching nodes (kubernetes.io/arch)", ref.Name())}
+	case "NeverPull":
+		return []string{fmt.Sprintf("Set imagePullPolicy to IfNotPresent or pre-load %s on the node", image)}
+	}
+	return []string{fmt.Sprintf("Inspect kubelet/container runtime logs on the node for the pull of %s", image)}
+}
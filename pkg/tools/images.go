@@ -0,0 +1,384 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+type imageInventoryInput struct {
+	Namespace         string   `json:"namespace,omitempty" jsonschema:"Kubernetes namespace (empty for all namespaces)"`
+	AllowedRegistries []string `json:"allowed_registries,omitempty" jsonschema:"Allowed registries or registry/org prefixes (e.g. ghcr.io/myorg, *.dkr.ecr.us-east-1.amazonaws.com); empty disables the allowlist check"`
+}
+
+// publicOnlyRegistries serve only public images, so pulls from them need no credentials.
+// Registries that also host private repositories (ghcr.io, quay.io, gcr.io) are not listed.
+var publicOnlyRegistries = map[string]bool{
+	"registry.k8s.io": true, "k8s.gcr.io": true, "mcr.microsoft.com": true, "public.ecr.aws": true,
+}
+
+// isPublicImage reports whether an image is known to be pullable without credentials: official
+// Docker Hub images (library/) or images from a public-only registry.
+func isPublicImage(ref util.ImageReference) bool {
+	if ref.Registry == "docker.io" {
+		return strings.HasPrefix(ref.Repository, "library/")
+	}
+	return publicOnlyRegistries[ref.Registry]
+}
+
+// imageEntry aggregates every container running one image reference.
+type imageEntry struct {
+	ref        util.ImageReference
+	display    string
+	digests    map[string]bool
+	workloads  []string
+	namespaces []string
+	pods       int
+}
+
+func registerImageTools(server *mcp.Server, client *k8s.ClusterClient) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "image_inventory",
+		Description: "List every container image in use with registry, tag, resolved digest (from containerStatuses imageID) and the workloads running it. " +
+			"Flags :latest or untagged images, one tag resolving to different digests across pods, registries outside an allowlist, " +
+			"imagePullPolicy mismatches, private-registry images without imagePullSecrets, and version drift of one image across namespaces.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input imageInventoryInput) (*mcp.CallToolResult, any, error) {
+		ns := util.NamespaceOrAll(input.Namespace)
+
+		pods, err := client.ListPods(ctx, ns, metav1.ListOptions{})
+		if err != nil {
+			return util.HandleK8sError("listing pods", err), nil, nil
+		}
+		serviceAccounts, err := client.ListServiceAccounts(ctx, ns, metav1.ListOptions{})
+		if err != nil {
+			return util.HandleK8sError("listing service accounts", err), nil, nil
+		}
+		saPullSecrets := make(map[string]bool)
+		for _, sa := range serviceAccounts {
+			if len(sa.ImagePullSecrets) > 0 {
+				saPullSecrets[sa.Namespace+"/"+sa.Name] = true
+			}
+		}
+
+		var sb strings.Builder
+		sb.WriteString(util.FormatHeader(fmt.Sprintf("Image Inventory (scope: %s)", displayNS(input.Namespace))))
+		sb.WriteString("\n\n")
+		findings := 0
+		var actions []string
+		var policyFindings, pullSecretFindings [][2]string // severity, message
+		seenFinding := make(map[string]bool)
+		addFinding := func(list *[][2]string, severity, msg string) {
+			if !seenFinding[msg] {
+				seenFinding[msg] = true
+				*list = append(*list, [2]string{severity, msg})
+			}
+		}
+
+		entries := make(map[string]*imageEntry)
+		for i := range pods {
+			pod := &pods[i]
+			workload := pod.Namespace + "/" + podWorkloadName(pod)
+			statuses := make(map[string]corev1.ContainerStatus)
+			for _, cs := range append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+				statuses[cs.Name] = cs
+			}
+			saName := pod.Spec.ServiceAccountName
+			if saName == "" {
+				saName = "default"
+			}
+			hasPullSecrets := len(pod.Spec.ImagePullSecrets) > 0 || saPullSecrets[pod.Namespace+"/"+saName]
+
+			for _, c := range append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+				ref := util.ParseImageReference(c.Image)
+				key := ref.Name() + ":" + ref.Tag
+				display := ref.Name()
+				if ref.Tag != "" {
+					display += ":" + ref.Tag
+				} else if ref.Digest != "" {
+					key = ref.Name() + "@" + ref.Digest
+					display = key
+				}
+				e, ok := entries[key]
+				if !ok {
+					e = &imageEntry{ref: ref, display: display, digests: make(map[string]bool)}
+					entries[key] = e
+				}
+				e.pods++
+				if !containsString(e.workloads, workload) {
+					e.workloads = append(e.workloads, workload)
+				}
+				if !containsString(e.namespaces, pod.Namespace) {
+					e.namespaces = append(e.namespaces, pod.Namespace)
+				}
+				cs, hasStatus := statuses[c.Name]
+				if digest := util.ImageIDDigest(cs.ImageID); digest != "" {
+					e.digests[digest] = true
+				}
+
+				// imagePullPolicy defaults to Always for :latest/untagged images, IfNotPresent otherwise
+				mutable := ref.Digest == "" && (ref.Tag == "" || ref.Tag == "latest")
+				policy := c.ImagePullPolicy
+				if policy == "" {
+					policy = corev1.PullIfNotPresent
+					if mutable {
+						policy = corev1.PullAlways
+					}
+				}
+				switch {
+				case mutable && policy != corev1.PullAlways:
+					addFinding(&policyFindings, "WARNING", fmt.Sprintf("%s container %q runs mutable image %s with imagePullPolicy=%s — nodes may run stale, divergent versions", workload, c.Name, display, policy))
+				case ref.Digest != "" && policy == corev1.PullAlways:
+					addFinding(&policyFindings, "INFO", fmt.Sprintf("%s container %q pins a digest but uses imagePullPolicy=Always — every start depends on the registry", workload, c.Name))
+				}
+
+				if !isPublicImage(ref) && !hasPullSecrets {
+					pulling := hasStatus && cs.State.Waiting != nil &&
+						(cs.State.Waiting.Reason == "ErrImagePull" || cs.State.Waiting.Reason == "ImagePullBackOff")
+					if pulling {
+						addFinding(&pullSecretFindings, "WARNING", fmt.Sprintf("%s cannot pull %s from private registry %s and has no imagePullSecrets (pod or ServiceAccount)", workload, display, ref.Registry))
+						actions = append(actions, fmt.Sprintf("Add an imagePullSecret for %s to %s or its ServiceAccount", ref.Registry, workload))
+					} else {
+						addFinding(&pullSecretFindings, "INFO", fmt.Sprintf("%s pulls from %s without imagePullSecrets — relies on node-level registry credentials", workload, ref.Registry))
+					}
+				}
+			}
+		}
+
+		keys := make([]string, 0, len(entries))
+		for key := range entries {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		// 1. Inventory
+		sb.WriteString(util.FormatSubHeader("Images"))
+		sb.WriteString("\n")
+		if len(keys) == 0 {
+			sb.WriteString("  No pods found.\n")
+		} else {
+			headers := []string{"IMAGE", "REGISTRY", "DIGEST", "PODS", "WORKLOADS"}
+			var rows [][]string
+			for _, key := range keys {
+				e := entries[key]
+				sort.Strings(e.workloads)
+				digest := "-"
+				switch len(e.digests) {
+				case 0:
+				case 1:
+					for d := range e.digests {
+						digest = shortDigest(d)
+					}
+				default:
+					digest = fmt.Sprintf("%d digests", len(e.digests))
+				}
+				rows = append(rows, []string{e.display, e.ref.Registry, digest, fmt.Sprintf("%d", e.pods), strings.Join(truncateList(e.workloads, 3), ", ")})
+			}
+			sb.WriteString(util.FormatTable(headers, rows))
+			sb.WriteString(fmt.Sprintf("\n%s\n", util.FormatCount("images", len(rows))))
+		}
+
+		// 2. Tag hygiene
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Tag Hygiene"))
+		sb.WriteString("\n")
+		hygiene := 0
+		for _, key := range keys {
+			e := entries[key]
+			if e.ref.Digest == "" && (e.ref.Tag == "" || e.ref.Tag == "latest") {
+				sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("%s uses a mutable tag (%s) — used by %s", e.display, mutableTagDisplay(e.ref.Tag), strings.Join(truncateList(e.workloads, 3), ", "))))
+				sb.WriteString("\n")
+				findings++
+				hygiene++
+				actions = append(actions, fmt.Sprintf("Pin %s to an immutable version tag or digest", e.ref.Name()))
+			}
+			if len(e.digests) > 1 {
+				digests := make([]string, 0, len(e.digests))
+				for d := range e.digests {
+					digests = append(digests, shortDigest(d))
+				}
+				sort.Strings(digests)
+				sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("%s resolves to %d different digests across pods (%s) — the tag was overwritten", e.display, len(digests), strings.Join(digests, ", "))))
+				sb.WriteString("\n")
+				findings++
+				hygiene++
+				actions = append(actions, fmt.Sprintf("Restart workloads using %s to converge on one digest, and stop re-pushing that tag", e.display))
+			}
+		}
+		for _, f := range policyFindings {
+			sb.WriteString(util.FormatFinding(f[0], f[1]))
+			sb.WriteString("\n")
+			if f[0] != "INFO" {
+				findings++
+			}
+			hygiene++
+		}
+		if hygiene == 0 {
+			sb.WriteString(util.FormatFinding("OK", "All images use immutable tags with consistent digests and pull policies"))
+			sb.WriteString("\n")
+		}
+
+		// 3. Registries
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Registries"))
+		sb.WriteString("\n")
+		registryCounts := make(map[string]int)
+		for _, e := range entries {
+			registryCounts[e.ref.Registry]++
+		}
+		registries := make([]string, 0, len(registryCounts))
+		for r := range registryCounts {
+			registries = append(registries, r)
+		}
+		sort.Strings(registries)
+		if len(registries) > 0 {
+			var rows [][]string
+			for _, r := range registries {
+				rows = append(rows, []string{r, fmt.Sprintf("%d", registryCounts[r])})
+			}
+			sb.WriteString(util.FormatTable([]string{"REGISTRY", "IMAGES"}, rows))
+		}
+		if len(input.AllowedRegistries) > 0 {
+			for _, key := range keys {
+				e := entries[key]
+				if !registryAllowed(e.ref, input.AllowedRegistries) {
+					sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("%s comes from a registry outside the allowlist — used by %s", e.display, strings.Join(truncateList(e.workloads, 3), ", "))))
+					sb.WriteString("\n")
+					findings++
+					actions = append(actions, fmt.Sprintf("Mirror %s into an allowed registry or add %s to the allowlist", e.ref.Name(), e.ref.Registry))
+				}
+			}
+		}
+		sort.Slice(pullSecretFindings, func(i, j int) bool { return pullSecretFindings[i][1] < pullSecretFindings[j][1] })
+		for _, f := range pullSecretFindings {
+			sb.WriteString(util.FormatFinding(f[0], f[1]))
+			sb.WriteString("\n")
+			if f[0] != "INFO" {
+				findings++
+			}
+		}
+
+		// 4. Version drift
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Version Drift"))
+		sb.WriteString("\n")
+		tagsByRepo := make(map[string]map[string][]string) // repository -> tag -> namespaces
+		for _, e := range entries {
+			if e.ref.Tag == "" {
+				continue
+			}
+			if tagsByRepo[e.ref.Name()] == nil {
+				tagsByRepo[e.ref.Name()] = make(map[string][]string)
+			}
+			tagsByRepo[e.ref.Name()][e.ref.Tag] = e.namespaces
+		}
+		repos := make([]string, 0, len(tagsByRepo))
+		for repo, tags := range tagsByRepo {
+			if len(tags) > 1 {
+				repos = append(repos, repo)
+			}
+		}
+		sort.Strings(repos)
+		if len(repos) == 0 {
+			sb.WriteString(util.FormatFinding("OK", "Each image runs a single version"))
+			sb.WriteString("\n")
+		}
+		for _, repo := range repos {
+			tags := tagsByRepo[repo]
+			var parts []string
+			namespaces := make(map[string]bool)
+			for tag, nss := range tags {
+				sort.Strings(nss)
+				parts = append(parts, fmt.Sprintf("%s (%s)", tag, strings.Join(nss, ", ")))
+				for _, n := range nss {
+					namespaces[n] = true
+				}
+			}
+			sort.Strings(parts)
+			severity := "INFO"
+			if len(namespaces) > 1 {
+				severity = "WARNING"
+				findings++
+				actions = append(actions, fmt.Sprintf("Align %s on one version across namespaces", repo))
+			}
+			sb.WriteString(util.FormatFinding(severity, fmt.Sprintf("%s runs %d versions: %s", repo, len(tags), strings.Join(parts, "; "))))
+			sb.WriteString("\n")
+		}
+
+		// Summary
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Summary"))
+		sb.WriteString("\n")
+		if findings == 0 {
+			sb.WriteString("  No image hygiene issues found.\n")
+		} else {
+			sb.WriteString(fmt.Sprintf("  %d finding(s) identified. Review details above.\n", findings))
+		}
+		if len(actions) > 0 {
+			sb.WriteString("\nSUGGESTED ACTIONS:\n")
+			for i, a := range dedupe(actions) {
+				sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, a))
+			}
+		}
+
+		return util.SuccessResult(sb.String()), nil, nil
+	})
+}
+
+// podWorkloadName returns "Kind/name" of the workload that owns a pod, resolving ReplicaSets to their Deployment.
+func podWorkloadName(pod *corev1.Pod) string {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return "Pod/" + pod.Name
+	}
+	if owner.Kind == "ReplicaSet" {
+		if hash := pod.Labels["pod-template-hash"]; hash != "" && strings.HasSuffix(owner.Name, "-"+hash) {
+			return "Deployment/" + strings.TrimSuffix(owner.Name, "-"+hash)
+		}
+	}
+	return owner.Kind + "/" + owner.Name
+}
+
+// registryAllowed reports whether an image matches an allowlist entry: a registry host, a registry/org prefix,
+// or a "*.domain" wildcard.
+func registryAllowed(ref util.ImageReference, allowlist []string) bool {
+	name := ref.Name()
+	for _, entry := range allowlist {
+		entry = strings.TrimSuffix(strings.TrimSpace(entry), "/")
+		switch {
+		case entry == "":
+			continue
+		case strings.HasPrefix(entry, "*."):
+			if strings.HasSuffix(ref.Registry, entry[1:]) {
+				return true
+			}
+		case entry == ref.Registry, name == entry, strings.HasPrefix(name, entry+"/"):
+			return true
+		}
+	}
+	return false
+}
+
+// mutableTagDisplay describes a tag that does not pin an image version.
+func mutableTagDisplay(tag string) string {
+	if tag == "" {
+		return "no tag, implies latest"
+	}
+	return tag
+}
+
+// shortDigest abbreviates a sha256 digest for display.
+func shortDigest(digest string) string {
+	algo, hex, found := strings.Cut(digest, ":")
+	if !found || len(hex) <= 12 {
+		return digest
+	}
+	return algo + ":" + hex[:12]
+}
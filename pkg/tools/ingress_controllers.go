@@ -0,0 +1,349 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+type checkIngressControllerHealthInput struct {
+	IngressClass string `json:"ingress_class,omitempty" jsonschema:"IngressClass name to check (empty for every class in the cluster)"`
+}
+
+const (
+	// defaultIngressClassAnnotation marks the cluster's default IngressClass.
+	defaultIngressClassAnnotation = "ingressclass.kubernetes.io/is-default-class"
+	// controllerLogTailLines and controllerLogSince bound the log scan per controller pod.
+	controllerLogTailLines = 500
+	controllerLogSince     = "30m"
+	// controllerLogMaxPods limits how many replicas' logs are scanned per controller.
+	controllerLogMaxPods = 3
+)
+
+func registerIngressControllerTools(server *mcp.Server, client *k8s.ClusterClient) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "check_ingress_controller_health",
+		Description: "Check the health of the ingress controllers serving each IngressClass (ingress-nginx, Traefik, HAProxy, AGIC). " +
+			"Detects the implementation from IngressClass.spec.controller, locates its pods, scans their logs for controller-specific errors " +
+			"(upstream timeouts, config reload failures, router errors), and validates controller-specific Ingress annotations.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input checkIngressControllerHealthInput) (*mcp.CallToolResult, any, error) {
+		classes, err := client.ListIngressClasses(ctx)
+		if err != nil {
+			return util.HandleK8sError("listing ingress classes", err), nil, nil
+		}
+		ingresses, err := client.ListIngresses(ctx, "", metav1.ListOptions{})
+		if err != nil {
+			return util.HandleK8sError("listing ingresses", err), nil, nil
+		}
+
+		var sb strings.Builder
+		title := "Ingress Controller Health"
+		if input.IngressClass != "" {
+			title += fmt.Sprintf(" (class: %s)", input.IngressClass)
+		}
+		sb.WriteString(util.FormatHeader(title))
+		sb.WriteString("\n\n")
+
+		findings := 0
+		var actions []string
+		finding := func(severity, msg string) {
+			sb.WriteString(util.FormatFinding(severity, msg))
+			sb.WriteString("\n")
+			findings++
+		}
+
+		// Map ingresses to classes
+		classByName := make(map[string]*networkingv1.IngressClass, len(classes))
+		var defaults []string
+		for i := range classes {
+			ic := &classes[i]
+			classByName[ic.Name] = ic
+			if ic.Annotations[defaultIngressClassAnnotation] == "true" {
+				defaults = append(defaults, ic.Name)
+			}
+		}
+		if input.IngressClass != "" && classByName[input.IngressClass] == nil {
+			return util.ErrorResult("IngressClass '%s' not found", input.IngressClass), nil, nil
+		}
+		served := make(map[string][]*networkingv1.Ingress)
+		var unclassed, dangling []*networkingv1.Ingress
+		for i := range ingresses {
+			ing := &ingresses[i]
+			name := ingressClassName(ing)
+			switch {
+			case name == "<none>" && len(defaults) == 1:
+				served[defaults[0]] = append(served[defaults[0]], ing)
+			case name == "<none>":
+				unclassed = append(unclassed, ing)
+			case classByName[name] == nil:
+				dangling = append(dangling, ing)
+			default:
+				served[name] = append(served[name], ing)
+			}
+		}
+
+		// Ingress classes
+		sb.WriteString(util.FormatSubHeader("Ingress Classes"))
+		sb.WriteString("\n")
+		if len(classes) == 0 {
+			sb.WriteString("  No IngressClasses found.\n")
+		} else {
+			var rows [][]string
+			for _, ic := range classes {
+				impl := "unknown"
+				if p := k8s.FindIngressControllerProfile(ic.Spec.Controller); p != nil {
+					impl = p.DisplayName
+				}
+				isDefault := ""
+				if ic.Annotations[defaultIngressClassAnnotation] == "true" {
+					isDefault = "yes"
+				}
+				rows = append(rows, []string{ic.Name, ic.Spec.Controller, impl, isDefault, fmt.Sprintf("%d", len(served[ic.Name]))})
+			}
+			sb.WriteString(util.FormatTable([]string{"CLASS", "CONTROLLER", "IMPLEMENTATION", "DEFAULT", "INGRESSES"}, rows))
+		}
+
+		if input.IngressClass == "" {
+			sb.WriteString("\n")
+			if len(defaults) > 1 {
+				finding("WARNING", fmt.Sprintf("%d IngressClasses are marked default (%s) — Ingresses without a class are rejected",
+					len(defaults), strings.Join(defaults, ", ")))
+				actions = append(actions, "Keep exactly one IngressClass annotated "+defaultIngressClassAnnotation+"=true")
+			}
+			for _, ing := range unclassed {
+				finding("WARNING", fmt.Sprintf("Ingress '%s/%s' has no ingressClassName and there is no single default IngressClass — no controller serves it",
+					ing.Namespace, ing.Name))
+				actions = append(actions, fmt.Sprintf("Set spec.ingressClassName on Ingress '%s/%s'", ing.Namespace, ing.Name))
+			}
+			for _, ing := range dangling {
+				finding("WARNING", fmt.Sprintf("Ingress '%s/%s' references IngressClass '%s' which does not exist", ing.Namespace, ing.Name, ingressClassName(ing)))
+				actions = append(actions, fmt.Sprintf("Create IngressClass '%s' or fix the class of Ingress '%s/%s'", ingressClassName(ing), ing.Namespace, ing.Name))
+			}
+		}
+
+		// Group classes by controller profile; several classes can share one controller deployment
+		type controllerGroup struct {
+			profile *k8s.IngressControllerProfile
+			classes []string
+		}
+		var groups []*controllerGroup
+		byProfile := make(map[string]*controllerGroup)
+		for _, ic := range classes {
+			if input.IngressClass != "" && ic.Name != input.IngressClass {
+				continue
+			}
+			p := k8s.FindIngressControllerProfile(ic.Spec.Controller)
+			if p == nil {
+				sb.WriteString("\n")
+				finding("INFO", fmt.Sprintf("IngressClass '%s' uses controller '%s' which has no health profile; pods and logs were not checked",
+					ic.Name, ic.Spec.Controller))
+				continue
+			}
+			g, ok := byProfile[p.Name]
+			if !ok {
+				g = &controllerGroup{profile: p}
+				byProfile[p.Name] = g
+				groups = append(groups, g)
+			}
+			g.classes = append(g.classes, ic.Name)
+		}
+
+		for _, g := range groups {
+			p := g.profile
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader(fmt.Sprintf("%s (classes: %s)", p.DisplayName, strings.Join(g.classes, ", "))))
+			sb.WriteString("\n")
+
+			// Pods
+			pods, selector, err := findIngressControllerPods(ctx, client, p)
+			if err != nil {
+				return util.HandleK8sError("searching for ingress controller pods", err), nil, nil
+			}
+			if len(pods) == 0 {
+				finding("CRITICAL", fmt.Sprintf("No %s pods found (tried selectors: %s)", p.DisplayName, strings.Join(p.PodSelectors, "; ")))
+				actions = append(actions, fmt.Sprintf("Verify %s is installed, or locate its pods with list_pods and a label_selector", p.DisplayName))
+			} else {
+				sb.WriteString(util.FormatKeyValue("Pod Selector", selector))
+				sb.WriteString("\n")
+				var rows [][]string
+				for i := range pods {
+					pod := &pods[i]
+					ready, total, restarts := podContainerSummary(pod)
+					rows = append(rows, []string{pod.Namespace, pod.Name, podPhaseReason(pod), fmt.Sprintf("%d/%d", ready, total),
+						fmt.Sprintf("%d", restarts), pod.Spec.NodeName})
+				}
+				sb.WriteString(util.FormatTable([]string{"NAMESPACE", "POD", "STATUS", "READY", "RESTARTS", "NODE"}, rows))
+				for i := range pods {
+					n, a := writeControllerPodHealth(&sb, p, &pods[i])
+					findings += n
+					actions = append(actions, a...)
+				}
+			}
+
+			// Logs
+			if len(pods) > 0 {
+				sb.WriteString("\n  Controller log scan:\n")
+				type logHit struct {
+					match k8s.ControllerLogMatch
+					pods  []string
+				}
+				hits := make(map[string]*logHit)
+				var order []string
+				scanned := 0
+				for i := range pods {
+					if scanned == controllerLogMaxPods {
+						break
+					}
+					pod := &pods[i]
+					if pod.Status.Phase != corev1.PodRunning {
+						continue
+					}
+					logs, err := client.GetPodLogs(ctx, pod.Namespace, pod.Name, controllerContainer(pod), controllerLogTailLines, false, controllerLogSince)
+					if err != nil {
+						sb.WriteString(fmt.Sprintf("    Could not fetch logs for %s: %v\n", pod.Name, err))
+						continue
+					}
+					scanned++
+					for _, m := range k8s.ScanControllerLogs(p, logs) {
+						key := m.Pattern.Problem
+						h, ok := hits[key]
+						if !ok {
+							h = &logHit{match: m}
+							hits[key] = h
+							order = append(order, key)
+						} else {
+							h.match.Count += m.Count
+							h.match.Sample = m.Sample
+						}
+						h.pods = append(h.pods, pod.Name)
+					}
+				}
+				if scanned == 0 {
+					sb.WriteString("    No running controller pods to scan.\n")
+				} else if len(hits) == 0 {
+					sb.WriteString(fmt.Sprintf("    No known %s error signatures in the last %s (%d pod(s), up to %d lines each).\n",
+						p.DisplayName, controllerLogSince, scanned, controllerLogTailLines))
+				}
+				for _, key := range order {
+					h := hits[key]
+					finding(h.match.Pattern.Severity, fmt.Sprintf("%s — %d log line(s) in %s", h.match.Pattern.Problem, h.match.Count, strings.Join(h.pods, ", ")))
+					sb.WriteString(fmt.Sprintf("    e.g. %s\n", truncateName(h.match.Sample, 200)))
+					if h.match.Pattern.Hint != "" {
+						actions = append(actions, h.match.Pattern.Hint)
+					}
+				}
+			}
+
+			// Annotations on the Ingresses this controller serves
+			var ings []*networkingv1.Ingress
+			for _, class := range g.classes {
+				ings = append(ings, served[class]...)
+			}
+			sb.WriteString(fmt.Sprintf("\n  Annotation validation (%d Ingress(es)):\n", len(ings)))
+			issueCount, unknownCount := 0, 0
+			for _, ing := range ings {
+				for _, issue := range k8s.ValidateIngressAnnotations(p, ing) {
+					finding(issue.Severity, fmt.Sprintf("Ingress '%s/%s': %s=%q %s", ing.Namespace, ing.Name, issue.Annotation, issue.Value, issue.Problem))
+					if issue.Severity == "INFO" {
+						unknownCount++
+					} else {
+						issueCount++
+					}
+				}
+			}
+			if issueCount == 0 && unknownCount == 0 {
+				sb.WriteString("    All controller annotations are recognised and valid.\n")
+			}
+			if issueCount > 0 {
+				actions = append(actions, fmt.Sprintf("Fix or remove the invalid annotations listed above — %s ignores or rejects them", p.DisplayName))
+			}
+			if unknownCount > 0 {
+				actions = append(actions, fmt.Sprintf("Check the spelling of the unrecognised annotations against the %s documentation", p.DisplayName))
+			}
+		}
+
+		// Summary
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Summary"))
+		sb.WriteString("\n")
+		if findings == 0 {
+			sb.WriteString("  Ingress controllers appear healthy. No issues found.\n")
+		} else {
+			sb.WriteString(fmt.Sprintf("  %d finding(s) identified. Review details above.\n", findings))
+		}
+		if len(actions) > 0 {
+			sb.WriteString("\nSUGGESTED ACTIONS:\n")
+			for i, a := range dedupe(actions) {
+				sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, a))
+			}
+		}
+
+		return util.SuccessResult(sb.String()), nil, nil
+	})
+}
+
+// findIngressControllerPods returns the pods matched by the first of the profile's selectors that finds any,
+// along with that selector.
+func findIngressControllerPods(ctx context.Context, client *k8s.ClusterClient, p *k8s.IngressControllerProfile) ([]corev1.Pod, string, error) {
+	for _, sel := range p.PodSelectors {
+		pods, err := client.ListPods(ctx, "", metav1.ListOptions{LabelSelector: sel})
+		if err != nil {
+			return nil, "", err
+		}
+		if len(pods) > 0 {
+			sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+			return pods, sel, nil
+		}
+	}
+	return nil, "", nil
+}
+
+// writeControllerPodHealth reports unhealthy, restarting, OOMKilled and crash-looping controller pods.
+func writeControllerPodHealth(sb *strings.Builder, p *k8s.IngressControllerProfile, pod *corev1.Pod) (int, []string) {
+	findings := 0
+	var actions []string
+	if !isPodHealthy(pod) {
+		sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("%s pod '%s' is not healthy: %s", p.DisplayName, pod.Name, podPhaseReason(pod))))
+		sb.WriteString("\n")
+		findings++
+		actions = append(actions, fmt.Sprintf("Investigate %s pod '%s' with diagnose_pod", p.DisplayName, pod.Name))
+	}
+	if _, _, restarts := podContainerSummary(pod); restarts > util.HighRestartThreshold {
+		sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("%s pod '%s' has high restart count: %d", p.DisplayName, pod.Name, restarts)))
+		sb.WriteString("\n")
+		findings++
+		actions = append(actions, fmt.Sprintf("Check the crash cause of '%s' with get_pod_logs previous=true", pod.Name))
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		if t := cs.LastTerminationState.Terminated; t != nil && t.Reason == "OOMKilled" {
+			sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("Container '%s' of '%s' was OOMKilled — consider increasing memory limits", cs.Name, pod.Name)))
+			sb.WriteString("\n")
+			findings++
+		}
+		if cs.State.Waiting != nil && cs.State.Waiting.Reason == "CrashLoopBackOff" {
+			sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("Container '%s' of '%s' is in CrashLoopBackOff", cs.Name, pod.Name)))
+			sb.WriteString("\n")
+			findings++
+		}
+	}
+	return findings, actions
+}
+
+// controllerContainer picks the controller container: the first one, skipping common sidecars.
+func controllerContainer(pod *corev1.Pod) string {
+	for _, c := range pod.Spec.Containers {
+		if c.Name != "istio-proxy" && c.Name != "linkerd-proxy" {
+			return c.Name
+		}
+	}
+	return ""
+}
@@ -3,6 +3,7 @@ package tools
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -19,7 +20,7 @@ import (
 // --- Input structs ---
 
 type mapServiceTopologyInput struct {
-	Namespace string `json:"namespace" jsonschema:"required,Kubernetes namespace to map (use a specific namespace, not 'all')"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"Kubernetes namespace to map (empty or 'all' for a cluster-wide service dependency graph)"`
 }
 
 type traceIngressToBackendInput struct {
@@ -48,12 +49,13 @@ func registerNetworkAnalysisTools(server *mcp.Server, client *k8s.ClusterClient)
 	// 1. map_service_topology
 	// =========================================================================
 	mcp.AddTool(server, &mcp.Tool{
-		Name:        "map_service_topology",
-		Description: "Map the full network topology for a namespace: services, their backing pods, ingresses exposing them, and inferred inter-service dependencies from pod environment variables. Produces structured text plus a Mermaid flowchart showing Internet -> Ingresses -> Services -> Pods with dependency edges.",
+		Name: "map_service_topology",
+		Description: "Map the full network topology for a namespace: services, their backing pods, ingresses and Gateway API routes exposing them, and inferred inter-service dependencies (env vars, referenced ConfigMaps, Secret key names, container args, ExternalName services and connection strings, across namespaces) with confidence and evidence. " +
+			"Produces structured text plus a Mermaid flowchart showing Internet -> Ingresses/Gateways -> Routes -> Services -> Pods with dependency edges. Without a namespace, renders a cluster-wide service dependency graph.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input mapServiceTopologyInput) (*mcp.CallToolResult, any, error) {
 		ns := input.Namespace
 		if ns == "" || ns == "all" || ns == "*" {
-			return util.ErrorResult("map_service_topology requires a specific namespace, not 'all'"), nil, nil
+			return clusterDependencyGraph(ctx, client)
 		}
 
 		var sb strings.Builder
@@ -155,18 +157,48 @@ func registerNetworkAnalysisTools(server *mcp.Server, client *k8s.ClusterClient)
 			sb.WriteString(fmt.Sprintf("\n%s\n", util.FormatCount("ingresses", len(ingresses))))
 		}
 
+		// --- Gather Gateway API routes (best-effort) ---
+		var gwRes *k8s.GatewayAPIResources
+		if res, gwErr := client.GetGatewayAPIResources(ctx, ns); gwErr == nil && len(res.Routes) > 0 {
+			gwRes = res
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("Gateway API Routes"))
+			sb.WriteString("\n")
+			routeHeaders := []string{"ROUTE", "HOSTNAMES", "GATEWAYS", "BACKEND-SERVICES"}
+			routeRows := make([][]string, 0, len(res.Routes))
+			for i := range res.Routes {
+				r := &res.Routes[i]
+				var parents, backends []string
+				for _, p := range r.ParentRefs {
+					parents = append(parents, p.String())
+				}
+				for _, rule := range r.Rules {
+					for _, b := range rule.BackendRefs {
+						name := b.Name
+						if b.Namespace != ns {
+							name = b.Namespace + "/" + b.Name
+						}
+						backends = append(backends, name)
+					}
+				}
+				routeRows = append(routeRows, []string{
+					r.Kind + "/" + r.Name,
+					valueOrAny(strings.Join(r.Hostnames, ", ")),
+					strings.Join(parents, ", "),
+					strings.Join(dedupe(backends), ", "),
+				})
+			}
+			sb.WriteString(util.FormatTable(routeHeaders, routeRows))
+			sb.WriteString(fmt.Sprintf("\n%s\n", util.FormatCount("routes", len(res.Routes))))
+		}
+
 		// --- Infer dependencies ---
 		deps, depErr := client.InferServiceDependencies(ctx, ns)
 		if depErr == nil && len(deps) > 0 {
 			sb.WriteString("\n")
 			sb.WriteString(util.FormatSubHeader("Inferred Service Dependencies"))
 			sb.WriteString("\n")
-			depHeaders := []string{"FROM", "TO", "CONFIDENCE", "SOURCE"}
-			depRows := make([][]string, 0, len(deps))
-			for _, d := range deps {
-				depRows = append(depRows, []string{d.FromService, d.ToService, d.Confidence, d.Source})
-			}
-			sb.WriteString(util.FormatTable(depHeaders, depRows))
+			sb.WriteString(formatDependencyTable(deps, ns))
 		}
 
 		// --- Findings ---
@@ -201,7 +233,7 @@ func registerNetworkAnalysisTools(server *mcp.Server, client *k8s.ClusterClient)
 
 		// Internet node
 		hasIngress := len(ingresses) > 0
-		if hasIngress {
+		if hasIngress || gwRes != nil {
 			fc.AddNode("INTERNET", "Internet", mermaid.ShapeCircle)
 			fc.AddStyle("INTERNET", mermaid.SeverityInfo)
 		}
@@ -261,6 +293,17 @@ func registerNetworkAnalysisTools(server *mcp.Server, client *k8s.ClusterClient)
 			}
 		}
 
+		// Gateway -> Route -> Service edges
+		if gwRes != nil {
+			existing := make(map[string]bool, len(svcMap))
+			for name := range svcMap {
+				existing[mermaid.SafeID("svc_"+name)] = true
+			}
+			for _, gwID := range addGatewayRoutesToFlowchart(fc, gwRes, gwRes.Routes, newGatewayBackendResolver(ctx, client), existing) {
+				fc.AddEdge("INTERNET", gwID, "", mermaid.EdgeSolid)
+			}
+		}
+
 		// Pods subgraph
 		fc.AddSubgraph("pods_sub", "Pods", func(sg *mermaid.Subgraph) {
 			for _, info := range svcMap {
@@ -294,12 +337,11 @@ func registerNetworkAnalysisTools(server *mcp.Server, client *k8s.ClusterClient)
 			}
 		}
 
-		// Dependency edges (dotted)
+		// Dependency edges (dotted); targets outside the namespace get their own nodes
 		if depErr == nil {
+			addDependencyTargetNodes(fc, deps, ns)
 			for _, dep := range deps {
-				fromID := mermaid.SafeID("svc_" + dep.FromService)
-				toID := mermaid.SafeID("svc_" + dep.ToService)
-				fc.AddEdge(fromID, toID, dep.Confidence, mermaid.EdgeDotted)
+				fc.AddEdge(dependencyNodeID(dep.FromNS, dep.FromService, ns), dependencyTargetID(dep, ns), dep.Confidence, mermaid.EdgeDotted)
 			}
 		}
 
@@ -314,7 +356,7 @@ func registerNetworkAnalysisTools(server *mcp.Server, client *k8s.ClusterClient)
 	// =========================================================================
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "trace_ingress_to_backend",
-		Description: "Trace the full request path from a hostname+path through Ingress -> Service -> Endpoints -> Pods. Checks AGIC annotations, backend service health, pod status, and available metrics. Produces a layered trace report plus a Mermaid sequence diagram of the request flow. Use this to debug 502/503/504 errors or routing issues.",
+		Description: "Trace the full request path from a hostname+path through Ingress -> Service -> Endpoints -> Pods. Falls back to Gateway API HTTPRoutes/GRPCRoutes (Gateway -> Route -> Service) when no Ingress matches. Checks AGIC annotations, backend service health, pod status, available metrics, and Istio/Linkerd sidecars, mTLS mode, VirtualService/DestinationRule subsets and AuthorizationPolicies. Produces a layered trace report plus a Mermaid sequence diagram of the request flow. Use this to debug 502/503/504 errors or routing issues.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input traceIngressToBackendInput) (*mcp.CallToolResult, any, error) {
 		hostname := input.Hostname
 		path := input.Path
@@ -337,12 +379,20 @@ func registerNetworkAnalysisTools(server *mcp.Server, client *k8s.ClusterClient)
 
 		ing, matchedRule, matchedPath, err := client.FindIngressForHostPath(ctx, "", hostname, path)
 		if err != nil {
-			sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("No ingress found for %s%s: %v", hostname, path, err)))
+			// Fall back to Gateway API routes
+			if gwRes, gwMatch := findGatewayRoute(ctx, client, "", hostname, path); gwMatch != nil {
+				sb.Reset()
+				sb.WriteString(util.FormatHeader(fmt.Sprintf("Gateway-to-Backend Trace: %s%s", hostname, path)))
+				sb.WriteString("\n\n")
+				writeGatewayRouteFallback(ctx, client, &sb, gwRes, gwMatch, hostname, path)
+				return util.SuccessResult(sb.String()), nil, nil
+			}
+			sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("No ingress or Gateway API route found for %s%s: %v", hostname, path, err)))
 			sb.WriteString("\n")
 			sb.WriteString("\nSUGGESTED ACTIONS:\n")
 			sb.WriteString("1. Verify the hostname and path are correct\n")
-			sb.WriteString("2. Check that an Ingress resource exists with this host/path\n")
-			sb.WriteString("3. Use list_ingresses to see available ingresses\n")
+			sb.WriteString("2. Check that an Ingress resource or HTTPRoute exists with this host/path\n")
+			sb.WriteString("3. Use list_ingresses or analyze_gateway_api to see available routes\n")
 			return util.SuccessResult(sb.String()), nil, nil
 		}
 
@@ -500,8 +550,12 @@ func registerNetworkAnalysisTools(server *mcp.Server, client *k8s.ClusterClient)
 		}
 
 		// Pod health detail
+		var mesh *k8s.ServiceMeshAnalysis
 		if svcErr == nil {
 			pods, podsErr := client.GetPodsForService(ctx, svc)
+			if podsErr == nil {
+				mesh = client.AnalyzeServiceMesh(ctx, svc, pods)
+			}
 			if podsErr == nil && len(pods) > 0 {
 				sb.WriteString("\n  Pod Health:\n")
 				podHeaders := []string{"POD", "STATUS", "READY", "RESTARTS", "AGE"}
@@ -549,6 +603,18 @@ func registerNetworkAnalysisTools(server *mcp.Server, client *k8s.ClusterClient)
 			}
 		}
 
+		// --- [4] SERVICE MESH layer ---
+		var meshActions []string
+		if mesh.Meshed() {
+			checkIngressControllerMesh(ctx, client, ing, mesh)
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("[4] SERVICE MESH"))
+			sb.WriteString("\n")
+			var n int
+			n, meshActions = writeServiceMeshSection(&sb, "", mesh)
+			findings += n
+		}
+
 		// --- Overall assessment ---
 		sb.WriteString("\n")
 		sb.WriteString(util.FormatSubHeader("Assessment"))
@@ -558,6 +624,12 @@ func registerNetworkAnalysisTools(server *mcp.Server, client *k8s.ClusterClient)
 		} else {
 			sb.WriteString(fmt.Sprintf("  %d issue(s) found along the request path. Review findings above.\n", findings))
 		}
+		if len(meshActions) > 0 {
+			sb.WriteString("\nSUGGESTED ACTIONS:\n")
+			for i, a := range dedupe(meshActions) {
+				sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, a))
+			}
+		}
 
 		// --- Mermaid Sequence Diagram ---
 		sb.WriteString("\nREQUEST FLOW DIAGRAM:\n")
@@ -568,6 +640,10 @@ func registerNetworkAnalysisTools(server *mcp.Server, client *k8s.ClusterClient)
 		}
 		seq.AddParticipant("SVC", fmt.Sprintf("Service: %s", backendSvcName))
 		seq.AddParticipant("EP", "Endpoints")
+		if mesh.Meshed() {
+			seq.AddParticipant("PROXY", fmt.Sprintf("Sidecar: %s", mesh.SidecarContainer()))
+			seq.AddParticipant("APP", "App container")
+		}
 
 		// Request flow
 		seq.AddMessage("CLIENT", "INGRESS", fmt.Sprintf("%s%s", hostname, path), mermaid.MsgSolid)
@@ -595,6 +671,7 @@ func registerNetworkAnalysisTools(server *mcp.Server, client *k8s.ClusterClient)
 				if health.NotReadyCount > 0 {
 					seq.AddNote("EP", fmt.Sprintf("%d not-ready", health.NotReadyCount), mermaid.NoteRight)
 				}
+				addMeshSequenceHop(seq, mesh, "EP", "PROXY", "APP")
 			}
 		} else {
 			seq.AddMessage("SVC", "EP", "endpoints unknown", mermaid.MsgDotted)
@@ -611,7 +688,7 @@ func registerNetworkAnalysisTools(server *mcp.Server, client *k8s.ClusterClient)
 	// =========================================================================
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "list_endpoint_health",
-		Description: "Check endpoint health for every service in a namespace. Flags services with 0 ready endpoints as DEAD and services with partial readiness as DEGRADED. Use this to quickly find services that can't serve traffic.",
+		Description: "Check endpoint health for every service in a namespace. Flags services with 0 ready endpoints as DEAD and services with partial readiness as DEGRADED; terminating endpoints are counted separately. Use this to quickly find services that can't serve traffic.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input listEndpointHealthInput) (*mcp.CallToolResult, any, error) {
 		ns := input.Namespace
 		if ns == "" {
@@ -632,7 +709,7 @@ func registerNetworkAnalysisTools(server *mcp.Server, client *k8s.ClusterClient)
 			return util.SuccessResult(sb.String()), nil, nil
 		}
 
-		headers := []string{"SERVICE", "TYPE", "TOTAL-EP", "READY", "NOT-READY", "STATUS"}
+		headers := []string{"SERVICE", "TYPE", "TOTAL-EP", "READY", "NOT-READY", "TERMINATING", "STATUS"}
 		rows := make([][]string, 0, len(services))
 
 		deadServices := 0
@@ -647,7 +724,7 @@ func registerNetworkAnalysisTools(server *mcp.Server, client *k8s.ClusterClient)
 				rows = append(rows, []string{
 					svc.Name,
 					string(svc.Spec.Type),
-					"?", "?", "?",
+					"?", "?", "?", "?",
 					"ERROR",
 				})
 				continue
@@ -676,6 +753,7 @@ func registerNetworkAnalysisTools(server *mcp.Server, client *k8s.ClusterClient)
 				fmt.Sprintf("%d", health.TotalEndpoints),
 				fmt.Sprintf("%d", health.ReadyCount),
 				fmt.Sprintf("%d", health.NotReadyCount),
+				fmt.Sprintf("%d", health.TerminatingCount),
 				status,
 			})
 		}
@@ -688,7 +766,7 @@ func registerNetworkAnalysisTools(server *mcp.Server, client *k8s.ClusterClient)
 		// Findings
 		sb.WriteString("\nFINDINGS:\n")
 		for _, row := range rows {
-			switch row[5] {
+			switch row[6] {
 			case "DEAD":
 				sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("Service '%s' has 0 ready endpoints — all traffic will fail", row[0])))
 				sb.WriteString("\n")
@@ -715,7 +793,7 @@ func registerNetworkAnalysisTools(server *mcp.Server, client *k8s.ClusterClient)
 	// =========================================================================
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "analyze_service_connectivity",
-		Description: "Run a comprehensive connectivity analysis for a specific service. Checks: service exists, selector matches pods, endpoints are ready, port mappings are valid, NetworkPolicies that affect it, and Ingress exposure. Produces a full connectivity report with a Mermaid flowchart. Use this to debug why a service is unreachable.",
+		Description: "Run a comprehensive connectivity analysis for a specific service. Checks: service exists, selector matches pods, endpoints are ready, port mappings are valid, NetworkPolicies that affect it, Ingress exposure, and service mesh state (Istio/Linkerd sidecars, mTLS, subsets, AuthorizationPolicies). Produces a full connectivity report with a Mermaid flowchart. Use this to debug why a service is unreachable.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input analyzeServiceConnectivityInput) (*mcp.CallToolResult, any, error) {
 		ns := input.Namespace
 		svcName := input.ServiceName
@@ -939,6 +1017,18 @@ func registerNetworkAnalysisTools(server *mcp.Server, client *k8s.ClusterClient)
 			}
 		}
 
+		// --- Check 7: Service mesh ---
+		mesh := client.AnalyzeServiceMesh(ctx, svc, matchedPods)
+		var actions []string
+		if mesh.Meshed() {
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("Check 7: Service Mesh"))
+			sb.WriteString("\n")
+			n, meshActions := writeServiceMeshSection(&sb, "", mesh)
+			findings += n
+			actions = append(actions, meshActions...)
+		}
+
 		// --- Overall assessment ---
 		sb.WriteString("\n")
 		sb.WriteString(util.FormatSubHeader("Overall Assessment"))
@@ -948,6 +1038,12 @@ func registerNetworkAnalysisTools(server *mcp.Server, client *k8s.ClusterClient)
 		} else {
 			sb.WriteString(fmt.Sprintf("  %d issue(s) found. Review findings above.\n", findings))
 		}
+		if len(actions) > 0 {
+			sb.WriteString("\nSUGGESTED ACTIONS:\n")
+			for i, a := range dedupe(actions) {
+				sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, a))
+			}
+		}
 
 		// --- Mermaid Flowchart ---
 		sb.WriteString("\nCONNECTIVITY DIAGRAM:\n")
@@ -993,9 +1089,13 @@ func registerNetworkAnalysisTools(server *mcp.Server, client *k8s.ClusterClient)
 		for i := range matchedPods {
 			pod := &matchedPods[i]
 			podID := mermaid.SafeID("pod_" + pod.Name)
-			podLabel := fmt.Sprintf("%s%s%s", pod.Name, mermaid.BR(), podPhaseReason(pod))
+			podLabel := fmt.Sprintf("%s%s%s%s", pod.Name, mermaid.BR(), podPhaseReason(pod), podSidecarSuffix(pod))
 			fc.AddNode(podID, podLabel, mermaid.ShapeRect)
-			fc.AddEdge(svcID, podID, "", mermaid.EdgeSolid)
+			edgeLabel := ""
+			if mesh.Meshed() && k8s.PodSidecar(pod) != nil {
+				edgeLabel = "mTLS"
+			}
+			fc.AddEdge(svcID, podID, edgeLabel, mermaid.EdgeSolid)
 
 			if isPodHealthy(pod) {
 				fc.AddStyle(podID, mermaid.SeverityHealthy)
@@ -1027,6 +1127,18 @@ func registerNetworkAnalysisTools(server *mcp.Server, client *k8s.ClusterClient)
 			}
 		}
 
+		// AuthorizationPolicy nodes
+		for _, ap := range mesh.AuthorizationPolicies {
+			apID := mermaid.SafeID("authz_" + ap.Namespace + "_" + ap.Name)
+			fc.AddNode(apID, fmt.Sprintf("AuthZ: %s%s%s", ap.Name, mermaid.BR(), ap.Action), mermaid.ShapeDiamond)
+			if ap.Action == "DENY" || (ap.Action == "ALLOW" && ap.Rules == 0) {
+				fc.AddStyle(apID, mermaid.SeverityCritical)
+			} else {
+				fc.AddStyle(apID, mermaid.SeverityWarning)
+			}
+			fc.AddEdge(apID, svcID, strings.ToLower(ap.Action), mermaid.EdgeDotted)
+		}
+
 		sb.WriteString(fc.RenderBlock())
 		sb.WriteString("\n")
 
@@ -1245,7 +1357,7 @@ func registerNetworkAnalysisTools(server *mcp.Server, client *k8s.ClusterClient)
 	// =========================================================================
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "check_agic_health",
-		Description: "Check the health of Azure Application Gateway Ingress Controller (AGIC). Finds the AGIC pod (label app=ingress-azure), checks its status, restarts, recent logs for errors, and AGIC ConfigMap. Use this when ingress routing through Azure Application Gateway is failing.",
+		Description: "Check the health of Azure Application Gateway Ingress Controller (AGIC). Finds the AGIC pod (label app=ingress-azure or app.kubernetes.io/name=ingress-azure), checks its status, restarts, recent logs for errors and known AGIC failure signatures, and AGIC ConfigMap. Use this when ingress routing through Azure Application Gateway is failing; for other controllers use check_ingress_controller_health.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input checkAGICHealthInput) (*mcp.CallToolResult, any, error) {
 		var sb strings.Builder
 		sb.WriteString(util.FormatHeader("AGIC Health Check"))
@@ -1258,20 +1370,19 @@ func registerNetworkAnalysisTools(server *mcp.Server, client *k8s.ClusterClient)
 		sb.WriteString("\n")
 
 		// Search across all namespaces for AGIC pods
-		agicPods, err := client.ListPods(ctx, "", metav1.ListOptions{
-			LabelSelector: "app=ingress-azure",
-		})
+		agicProfile := k8s.IngressControllerProfileByName("agic")
+		agicPods, _, err := findIngressControllerPods(ctx, client, agicProfile)
 		if err != nil {
 			return util.HandleK8sError("searching for AGIC pods", err), nil, nil
 		}
 
 		if len(agicPods) == 0 {
-			sb.WriteString(util.FormatFinding("CRITICAL", "No AGIC pods found with label 'app=ingress-azure'"))
+			sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("No AGIC pods found (tried selectors: %s)", strings.Join(agicProfile.PodSelectors, "; "))))
 			sb.WriteString("\n")
 			sb.WriteString("\nSUGGESTED ACTIONS:\n")
 			sb.WriteString("1. Verify AGIC is installed in the cluster\n")
-			sb.WriteString("2. Check if AGIC uses a different label selector\n")
-			sb.WriteString("3. Try: list_pods with label_selector='app.kubernetes.io/name=ingress-azure'\n")
+			sb.WriteString("2. Check if AGIC uses a different label selector (use list_pods with a label_selector)\n")
+			sb.WriteString("3. If the cluster uses another ingress controller, run check_ingress_controller_health\n")
 			return util.SuccessResult(sb.String()), nil, nil
 		}
 
@@ -1394,6 +1505,14 @@ func registerNetworkAnalysisTools(server *mcp.Server, client *k8s.ClusterClient)
 				} else {
 					sb.WriteString("  No errors or warnings in recent logs.\n")
 				}
+				for _, m := range k8s.ScanControllerLogs(agicProfile, logs) {
+					sb.WriteString(util.FormatFinding(m.Pattern.Severity, fmt.Sprintf("%s (%d line(s))", m.Pattern.Problem, m.Count)))
+					sb.WriteString("\n")
+					if m.Pattern.Hint != "" {
+						sb.WriteString(fmt.Sprintf("    Hint: %s\n", m.Pattern.Hint))
+					}
+					findings++
+				}
 			}
 		}
 
@@ -1463,6 +1582,187 @@ func registerNetworkAnalysisTools(server *mcp.Server, client *k8s.ClusterClient)
 // --- Helper functions ---
 // NOTE: formatServicePorts is defined in composite_diagnostics.go and shared across tools.
 
+// formatDependencyTable renders inferred dependencies with their strongest evidence.
+// Services in ns are shown by name; others as namespace/name.
+func formatDependencyTable(deps []k8s.ServiceDependency, ns string) string {
+	rows := make([][]string, 0, len(deps))
+	for _, d := range deps {
+		evidence := ""
+		if len(d.Evidence) > 0 {
+			evidence = d.Evidence[0]
+			if more := len(d.Evidence) - 1; more > 0 {
+				evidence += fmt.Sprintf(" (+%d more)", more)
+			}
+		}
+		rows = append(rows, []string{dependencyName(d.FromNS, d.FromService, ns), dependencyTargetName(d, ns), d.Confidence, d.Source, evidence})
+	}
+	return util.FormatTable([]string{"FROM", "TO", "CONFIDENCE", "SOURCE", "EVIDENCE"}, rows)
+}
+
+func dependencyName(svcNS, name, ns string) string {
+	if svcNS == ns {
+		return name
+	}
+	return svcNS + "/" + name
+}
+
+func dependencyTargetName(d k8s.ServiceDependency, ns string) string {
+	if d.External {
+		return d.ToService + " (external)"
+	}
+	return dependencyName(d.ToNS, d.ToService, ns)
+}
+
+// dependencyNodeID keeps the svc_<name> IDs for services in ns so dependency edges attach
+// to the nodes already drawn for them.
+func dependencyNodeID(svcNS, name, ns string) string {
+	if svcNS == ns {
+		return mermaid.SafeID("svc_" + name)
+	}
+	return mermaid.SafeID("svc_" + svcNS + "_" + name)
+}
+
+func dependencyTargetID(d k8s.ServiceDependency, ns string) string {
+	if d.External {
+		return mermaid.SafeID("ext_" + d.ToService)
+	}
+	return dependencyNodeID(d.ToNS, d.ToService, ns)
+}
+
+// addDependencyTargetNodes draws dependency targets outside ns: other namespaces' services
+// grouped by namespace, and external hosts in their own subgraph.
+func addDependencyTargetNodes(fc *mermaid.Flowchart, deps []k8s.ServiceDependency, ns string) {
+	byNS := make(map[string][]string)
+	var namespaces, externals []string
+	seen := make(map[string]bool)
+	for _, d := range deps {
+		id := dependencyTargetID(d, ns)
+		if seen[id] || (!d.External && d.ToNS == ns) {
+			continue
+		}
+		seen[id] = true
+		if d.External {
+			externals = append(externals, d.ToService)
+			continue
+		}
+		if _, ok := byNS[d.ToNS]; !ok {
+			namespaces = append(namespaces, d.ToNS)
+		}
+		byNS[d.ToNS] = append(byNS[d.ToNS], d.ToService)
+	}
+	sort.Strings(namespaces)
+	for _, other := range namespaces {
+		names := byNS[other]
+		fc.AddSubgraph(mermaid.SafeID("ns_"+other), fmt.Sprintf("Namespace %s", other), func(sg *mermaid.Subgraph) {
+			for _, name := range names {
+				sg.AddNode(dependencyNodeID(other, name, ns), name, mermaid.ShapeRound)
+			}
+		})
+	}
+	if len(externals) > 0 {
+		fc.AddSubgraph("external_deps", "External", func(sg *mermaid.Subgraph) {
+			for _, host := range externals {
+				sg.AddNode(mermaid.SafeID("ext_"+host), host, mermaid.ShapeRect)
+			}
+		})
+		for _, host := range externals {
+			fc.AddStyle(mermaid.SafeID("ext_"+host), mermaid.SeverityInfo)
+		}
+	}
+}
+
+// clusterDependencyGraph renders map_service_topology's cluster-wide view: every inferred
+// service dependency grouped by namespace, without pod or ingress detail.
+func clusterDependencyGraph(ctx context.Context, client *k8s.ClusterClient) (*mcp.CallToolResult, any, error) {
+	deps, err := client.InferServiceDependencies(ctx, "")
+	if err != nil {
+		return util.HandleK8sError("inferring service dependencies", err), nil, nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(util.FormatHeader("Service Dependency Graph (all namespaces)"))
+	sb.WriteString("\n\n")
+	if len(deps) == 0 {
+		sb.WriteString("  No service dependencies could be inferred from pod specs, ConfigMaps, Secret keys or ExternalName services.\n")
+		return util.SuccessResult(sb.String()), nil, nil
+	}
+
+	sb.WriteString(util.FormatSubHeader("Inferred Service Dependencies"))
+	sb.WriteString("\n")
+	sb.WriteString(formatDependencyTable(deps, ""))
+
+	crossNS, external := 0, 0
+	confidence := make(map[string]int)
+	for _, d := range deps {
+		switch {
+		case d.External:
+			external++
+		case d.ToNS != d.FromNS:
+			crossNS++
+		}
+		confidence[d.Confidence]++
+	}
+
+	// Every service is drawn inside its namespace's subgraph; passing an empty ns makes
+	// dependencyNodeID namespace-qualify all node IDs.
+	sb.WriteString("\nDEPENDENCY DIAGRAM:\n")
+	fc := mermaid.NewFlowchart(mermaid.DirectionLR)
+	byNS := make(map[string][]string)
+	seen := make(map[string]bool)
+	var namespaces []string
+	addSvc := func(svcNS, name string) {
+		if seen[svcNS+"/"+name] {
+			return
+		}
+		seen[svcNS+"/"+name] = true
+		if _, ok := byNS[svcNS]; !ok {
+			namespaces = append(namespaces, svcNS)
+		}
+		byNS[svcNS] = append(byNS[svcNS], name)
+	}
+	for _, d := range deps {
+		addSvc(d.FromNS, d.FromService)
+		if !d.External {
+			addSvc(d.ToNS, d.ToService)
+		}
+	}
+	sort.Strings(namespaces)
+	for _, svcNS := range namespaces {
+		names := byNS[svcNS]
+		fc.AddSubgraph(mermaid.SafeID("ns_"+svcNS), fmt.Sprintf("Namespace %s", svcNS), func(sg *mermaid.Subgraph) {
+			for _, name := range names {
+				sg.AddNode(dependencyNodeID(svcNS, name, ""), name, mermaid.ShapeRound)
+			}
+		})
+	}
+	var externals []k8s.ServiceDependency
+	for _, d := range deps {
+		if d.External {
+			externals = append(externals, d)
+		}
+	}
+	addDependencyTargetNodes(fc, externals, "")
+	for _, d := range deps {
+		style := mermaid.EdgeDotted
+		if d.Confidence == k8s.ConfidenceHigh {
+			style = mermaid.EdgeSolid
+		}
+		fc.AddEdge(dependencyNodeID(d.FromNS, d.FromService, ""), dependencyTargetID(d, ""), d.Confidence, style)
+	}
+	sb.WriteString(fc.RenderBlock())
+	sb.WriteString("\n")
+
+	sb.WriteString("\n")
+	sb.WriteString(util.FormatSubHeader("Summary"))
+	sb.WriteString("\n")
+	sb.WriteString(fmt.Sprintf("  %d dependencies across %d namespace(s): %d cross-namespace, %d external\n", len(deps), len(namespaces), crossNS, external))
+	sb.WriteString(fmt.Sprintf("  Confidence: %d high, %d medium, %d low\n",
+		confidence[k8s.ConfidenceHigh], confidence[k8s.ConfidenceMedium], confidence[k8s.ConfidenceLow]))
+	sb.WriteString("  Solid edges are high-confidence references; dotted edges are weaker name matches.\n")
+
+	return util.SuccessResult(sb.String()), nil, nil
+}
+
 // extractIngressDetails returns hosts, paths, and backend service names from an Ingress.
 func extractIngressDetails(ing *networkingv1.Ingress) (hosts []string, paths []string, backends []string) {
 	backendSet := make(map[string]bool)
@@ -0,0 +1,348 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+type networkPolicyMatrixInput struct {
+	Namespace string `json:"namespace,omitempty" jsonschema:"Namespace to compute a workload-to-workload matrix for (empty for a namespace-to-namespace matrix across the cluster)"`
+}
+
+// matrixMaxColumns bounds the rendered matrix; larger scopes get a per-unit summary only.
+const matrixMaxColumns = 15
+
+// matrixUnit is a row/column of the traffic matrix: a namespace or a workload, represented by one pod per workload.
+type matrixUnit struct {
+	name      string
+	endpoints []k8s.PolicyEndpoint
+}
+
+func registerNetworkPolicyMatrixTools(server *mcp.Server, client *k8s.ClusterClient) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "network_policy_matrix",
+		Description: "Compute the allowed-traffic matrix between all namespaces (or between workloads inside one namespace) from every " +
+			"NetworkPolicy, evaluating egress and ingress together. Highlights namespaces without default-deny, policies that " +
+			"select no pods, rules that can never match (selectors with no matching namespaces or pods), and overly broad " +
+			"0.0.0.0/0 egress.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input networkPolicyMatrixInput) (*mcp.CallToolResult, any, error) {
+		namespaces, err := client.ListNamespaces(ctx)
+		if err != nil {
+			return util.HandleK8sError("listing namespaces", err), nil, nil
+		}
+		pods, err := client.ListPods(ctx, "", metav1.ListOptions{})
+		if err != nil {
+			return util.HandleK8sError("listing pods", err), nil, nil
+		}
+		policies, err := client.ListNetworkPolicies(ctx, input.Namespace, metav1.ListOptions{})
+		if err != nil {
+			return util.HandleK8sError("listing network policies", err), nil, nil
+		}
+
+		nsLabels := make(map[string]map[string]string, len(namespaces))
+		for _, ns := range namespaces {
+			set := map[string]string{"kubernetes.io/metadata.name": ns.Name}
+			for k, v := range ns.Labels {
+				set[k] = v
+			}
+			nsLabels[ns.Name] = set
+		}
+
+		// One representative pod per workload; replicas share labels.
+		activePods := make(map[string][]*corev1.Pod)
+		workloads := make(map[string]map[string]k8s.PolicyEndpoint)
+		for i := range pods {
+			pod := &pods[i]
+			if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+				continue
+			}
+			activePods[pod.Namespace] = append(activePods[pod.Namespace], pod)
+			if workloads[pod.Namespace] == nil {
+				workloads[pod.Namespace] = make(map[string]k8s.PolicyEndpoint)
+			}
+			name := podWorkloadName(pod)
+			if _, ok := workloads[pod.Namespace][name]; !ok {
+				workloads[pod.Namespace][name] = k8s.PolicyEndpoint{
+					Namespace:       pod.Namespace,
+					NamespaceLabels: nsLabels[pod.Namespace],
+					PodLabels:       podLabelSet(pod),
+					IP:              pod.Status.PodIP,
+					Pod:             pod,
+				}
+			}
+		}
+
+		var units []matrixUnit
+		title := "Network Policy Matrix (namespaces)"
+		if input.Namespace == "" {
+			for ns, byWorkload := range workloads {
+				unit := matrixUnit{name: ns}
+				for _, ep := range byWorkload {
+					unit.endpoints = append(unit.endpoints, ep)
+				}
+				units = append(units, unit)
+			}
+		} else {
+			title = fmt.Sprintf("Network Policy Matrix (namespace: %s)", input.Namespace)
+			for name, ep := range workloads[input.Namespace] {
+				units = append(units, matrixUnit{name: name, endpoints: []k8s.PolicyEndpoint{ep}})
+			}
+		}
+		sort.Slice(units, func(i, j int) bool { return units[i].name < units[j].name })
+
+		var sb strings.Builder
+		sb.WriteString(util.FormatHeader(title))
+		sb.WriteString("\n\n")
+		sb.WriteString(util.FormatKeyValue("POLICIES", fmt.Sprintf("%d", len(policies))))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("UNITS", fmt.Sprintf("%d", len(units))))
+		sb.WriteString("\n")
+
+		findings := 0
+		var actions []string
+
+		if len(units) == 0 {
+			sb.WriteString("\n  No running pods in scope.\n")
+			return util.SuccessResult(sb.String()), nil, nil
+		}
+
+		// Matrix: a cell is allowed when egress of the source and ingress of the destination both admit
+		// traffic on at least one port, for every pair of representative pods.
+		type cell struct{ allowed, total int }
+		cells := make([][]cell, len(units))
+		inbound := make([]int, len(units))
+		outbound := make([]int, len(units))
+		for i, src := range units {
+			cells[i] = make([]cell, len(units))
+			for j, dst := range units {
+				c := cell{}
+				for _, a := range src.endpoints {
+					for _, b := range dst.endpoints {
+						c.total++
+						if k8s.EvaluateEgressAnyPort(policies, a, b).Allowed && k8s.EvaluateIngressAnyPort(policies, a, b).Allowed {
+							c.allowed++
+						}
+					}
+				}
+				cells[i][j] = c
+				if c.allowed > 0 && i != j {
+					outbound[i]++
+					inbound[j]++
+				}
+			}
+		}
+		cellText := func(c cell) string {
+			switch {
+			case c.allowed == c.total:
+				return "allow"
+			case c.allowed == 0:
+				return "DENY"
+			default:
+				return fmt.Sprintf("partial %d/%d", c.allowed, c.total)
+			}
+		}
+
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Allowed Traffic (rows = source, columns = destination)"))
+		sb.WriteString("\n")
+		if len(units) <= matrixMaxColumns {
+			headers := []string{"FROM \\ TO"}
+			for i := range units {
+				headers = append(headers, fmt.Sprintf("[%d]", i+1))
+			}
+			var rows [][]string
+			for i, u := range units {
+				row := []string{fmt.Sprintf("[%d] %s", i+1, u.name)}
+				for j := range units {
+					row = append(row, cellText(cells[i][j]))
+				}
+				rows = append(rows, row)
+			}
+			sb.WriteString(util.FormatTable(headers, rows))
+		} else {
+			sb.WriteString(fmt.Sprintf("  %d units — too many to render as a grid; showing per-unit reachability.\n", len(units)))
+		}
+
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Isolation Summary"))
+		sb.WriteString("\n")
+		others := len(units) - 1
+		var summaryRows [][]string
+		for i, u := range units {
+			summaryRows = append(summaryRows, []string{
+				u.name,
+				fmt.Sprintf("%d/%d", inbound[i], others),
+				fmt.Sprintf("%d/%d", outbound[i], others),
+			})
+		}
+		sb.WriteString(util.FormatTable([]string{"UNIT", "REACHABLE FROM", "CAN REACH"}, summaryRows))
+
+		// Findings
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Findings"))
+		sb.WriteString("\n")
+
+		// Namespaces without default-deny
+		scope := make([]string, 0, len(activePods))
+		for ns := range activePods {
+			if input.Namespace == "" || ns == input.Namespace {
+				scope = append(scope, ns)
+			}
+		}
+		sort.Strings(scope)
+		for _, ns := range scope {
+			denyIngress, denyEgress := false, false
+			for i := range policies {
+				np := &policies[i]
+				if np.Namespace != ns {
+					continue
+				}
+				if k8s.IsDefaultDeny(np, networkingv1.PolicyTypeIngress) {
+					denyIngress = true
+				}
+				if k8s.IsDefaultDeny(np, networkingv1.PolicyTypeEgress) {
+					denyEgress = true
+				}
+			}
+			if !denyIngress {
+				sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("Namespace '%s' has no default-deny ingress policy (empty podSelector)", ns)))
+				sb.WriteString("\n")
+				findings++
+				actions = append(actions, fmt.Sprintf("Add a default-deny NetworkPolicy (podSelector: {}, policyTypes: [Ingress, Egress]) to '%s' and allow required flows explicitly", ns))
+			} else if !denyEgress {
+				sb.WriteString(util.FormatFinding("INFO", fmt.Sprintf("Namespace '%s' denies ingress by default but not egress", ns)))
+				sb.WriteString("\n")
+			}
+		}
+
+		for i := range policies {
+			np := &policies[i]
+			ref := fmt.Sprintf("%s/%s", np.Namespace, np.Name)
+
+			// Policies that select no pods
+			selected := 0
+			for _, pod := range activePods[np.Namespace] {
+				if k8s.SelectorMatches(&np.Spec.PodSelector, pod.Labels) {
+					selected++
+				}
+			}
+			if selected == 0 {
+				sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("Policy '%s' selects no running pods (podSelector: %s)",
+					ref, formatLabelSelector(&np.Spec.PodSelector))))
+				sb.WriteString("\n")
+				findings++
+				actions = append(actions, fmt.Sprintf("Fix the podSelector of '%s' or delete the policy if the workload is gone", ref))
+			}
+
+			// Rules that can never match
+			for r, rule := range np.Spec.Ingress {
+				if reason := deadPeerReason(rule.From, np.Namespace, nsLabels, activePods); reason != "" {
+					sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("Policy '%s' ingress rule %d (%s) never matches: %s",
+						ref, r+1, strings.Join(describeIngressRule(rule), "; "), reason)))
+					sb.WriteString("\n")
+					findings++
+				}
+			}
+			for r, rule := range np.Spec.Egress {
+				if reason := deadPeerReason(rule.To, np.Namespace, nsLabels, activePods); reason != "" {
+					sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("Policy '%s' egress rule %d (%s) never matches: %s",
+						ref, r+1, strings.Join(describeEgressRule(rule), "; "), reason)))
+					sb.WriteString("\n")
+					findings++
+				}
+
+				// Overly broad egress
+				if len(rule.To) == 0 && len(rule.Ports) == 0 {
+					sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("Policy '%s' egress rule %d allows all destinations on all ports", ref, r+1)))
+					sb.WriteString("\n")
+					findings++
+					actions = append(actions, fmt.Sprintf("Restrict egress in '%s' to the destinations and ports the workload needs", ref))
+				}
+				for _, to := range rule.To {
+					if to.IPBlock != nil && (to.IPBlock.CIDR == "0.0.0.0/0" || to.IPBlock.CIDR == "::/0") {
+						msg := fmt.Sprintf("Policy '%s' egress rule %d allows %s", ref, r+1, to.IPBlock.CIDR)
+						if len(to.IPBlock.Except) > 0 {
+							msg += fmt.Sprintf(" (except %s)", strings.Join(to.IPBlock.Except, ","))
+						}
+						if len(rule.Ports) == 0 {
+							msg += " on all ports"
+						}
+						sb.WriteString(util.FormatFinding("WARNING", msg+" — effectively unrestricted internet egress"))
+						sb.WriteString("\n")
+						findings++
+						actions = append(actions, fmt.Sprintf("Restrict egress in '%s' to the destinations and ports the workload needs", ref))
+					}
+				}
+			}
+		}
+		if findings == 0 {
+			sb.WriteString("  No isolation gaps found.\n")
+		}
+
+		// Summary
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Summary"))
+		sb.WriteString("\n")
+		if findings == 0 {
+			sb.WriteString("  No network policy issues found.\n")
+		} else {
+			sb.WriteString(fmt.Sprintf("  %d finding(s) identified. Review details above.\n", findings))
+		}
+		actions = append(actions, "Run can_reach to see which rules decide a specific source, destination and port")
+		sb.WriteString("\nSUGGESTED ACTIONS:\n")
+		for i, a := range dedupe(actions) {
+			sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, a))
+		}
+
+		return util.SuccessResult(sb.String()), nil, nil
+	})
+}
+
+// deadPeerReason explains why a rule's peer list can never match, or returns "" if at least one peer can.
+// ipBlock peers always count as matchable.
+func deadPeerReason(peers []networkingv1.NetworkPolicyPeer, policyNamespace string, nsLabels map[string]map[string]string, pods map[string][]*corev1.Pod) string {
+	if len(peers) == 0 {
+		return ""
+	}
+	var reasons []string
+	for _, peer := range peers {
+		if peer.IPBlock != nil {
+			return ""
+		}
+		namespaces := []string{policyNamespace}
+		if peer.NamespaceSelector != nil {
+			namespaces = nil
+			for ns, set := range nsLabels {
+				if k8s.SelectorMatches(peer.NamespaceSelector, set) {
+					namespaces = append(namespaces, ns)
+				}
+			}
+			if len(namespaces) == 0 {
+				reasons = append(reasons, fmt.Sprintf("no namespace matches %s", formatLabelSelector(peer.NamespaceSelector)))
+				continue
+			}
+		}
+		if peer.PodSelector == nil {
+			return ""
+		}
+		for _, ns := range namespaces {
+			for _, pod := range pods[ns] {
+				if k8s.SelectorMatches(peer.PodSelector, pod.Labels) {
+					return ""
+				}
+			}
+		}
+		reasons = append(reasons, fmt.Sprintf("no running pod matches %s", formatLabelSelector(peer.PodSelector)))
+	}
+	return strings.Join(reasons, "; ")
+}
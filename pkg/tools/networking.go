@@ -6,6 +6,8 @@ import (
 	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 
 	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
 	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
@@ -140,54 +142,167 @@ func registerNetworkingTools(server *mcp.Server, client *k8s.ClusterClient) {
 
 	// get_endpoints
 	mcp.AddTool(server, &mcp.Tool{
-		Name:        "get_endpoints",
-		Description: "Get endpoints for a service showing which pods back it and their ready status. Useful for debugging services with no endpoints or connectivity issues.",
+		Name: "get_endpoints",
+		Description: "Get endpoints for a service showing which pods back it and their ready status. Reads EndpointSlices (falling back to core Endpoints) and reports ready/serving/terminating conditions, " +
+			"per-zone distribution and topology hints, dual-stack address families, and slices managed by custom controllers. Useful for debugging services with no endpoints or connectivity issues.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input getEndpointsInput) (*mcp.CallToolResult, any, error) {
-		endpoints, err := client.GetEndpoints(ctx, input.Namespace, input.Name)
+		health, err := client.GetServiceEndpointHealth(ctx, input.Namespace, input.Name)
 		if err != nil {
 			return util.HandleK8sError(fmt.Sprintf("getting endpoints %s/%s", input.Namespace, input.Name), err), nil, nil
 		}
+		// The service is only needed for its topology and IP family settings; endpoints
+		// can outlive it, so a lookup failure is not fatal.
+		svc, _ := client.GetService(ctx, input.Namespace, input.Name)
 
 		var sb strings.Builder
-		sb.WriteString(util.FormatHeader(fmt.Sprintf("Endpoints: %s (namespace: %s)", endpoints.Name, endpoints.Namespace)))
+		sb.WriteString(util.FormatHeader(fmt.Sprintf("Endpoints: %s (namespace: %s)", input.Name, input.Namespace)))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("Source", health.Source))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("Ports", valueOrNone(strings.Join(health.Ports, ", "))))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("Address Families", valueOrNone(strings.Join(health.AddressFamilies, ", "))))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("Endpoints", fmt.Sprintf("%d ready, %d not ready, %d terminating (%d serving)",
+			health.ReadyCount, health.NotReadyCount, health.TerminatingCount, health.ServingCount)))
 		sb.WriteString("\n")
 
-		totalAddresses := 0
-		for _, subset := range endpoints.Subsets {
-			// Ready addresses
-			if len(subset.Addresses) > 0 {
-				sb.WriteString("\nReady Addresses:\n")
-				for _, addr := range subset.Addresses {
-					ref := ""
-					if addr.TargetRef != nil {
-						ref = fmt.Sprintf(" (%s/%s)", addr.TargetRef.Kind, addr.TargetRef.Name)
-					}
-					for _, port := range subset.Ports {
-						sb.WriteString(fmt.Sprintf("  %s:%d%s\n", addr.IP, port.Port, ref))
-					}
-					totalAddresses++
-				}
+		if len(health.Slices) > 0 {
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("EndpointSlices"))
+			sb.WriteString("\n")
+			rows := make([][]string, 0, len(health.Slices))
+			for _, s := range health.Slices {
+				rows = append(rows, []string{s.Name, s.AddressType, fmt.Sprintf("%d", s.Endpoints), valueOrNone(strings.Join(s.Ports, ",")), valueOrNone(s.ManagedBy)})
+			}
+			sb.WriteString(util.FormatTable([]string{"SLICE", "ADDRESS-TYPE", "ENDPOINTS", "PORTS", "MANAGED-BY"}, rows))
+		}
+
+		all := make([]k8s.EndpointAddress, 0, health.TotalEndpoints+health.TerminatingCount)
+		all = append(all, health.ReadyAddresses...)
+		all = append(all, health.NotReadyPods...)
+		all = append(all, health.Terminating...)
+		if len(all) > 0 {
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("Endpoints"))
+			sb.WriteString("\n")
+			rows := make([][]string, 0, len(all))
+			for _, ea := range all {
+				rows = append(rows, []string{
+					strings.Join(ea.IPs, ","),
+					valueOrNone(ea.PodName),
+					valueOrNone(ea.NodeName),
+					valueOrNone(ea.Zone),
+					fmt.Sprintf("%t", ea.Ready),
+					fmt.Sprintf("%t", ea.Serving),
+					fmt.Sprintf("%t", ea.Terminating),
+					valueOrNone(strings.Join(ea.ZoneHints, ",")),
+				})
 			}
+			sb.WriteString(util.FormatTable([]string{"ADDRESSES", "POD", "NODE", "ZONE", "READY", "SERVING", "TERMINATING", "ZONE-HINTS"}, rows))
+		}
 
-			// Not-ready addresses
-			if len(subset.NotReadyAddresses) > 0 {
-				sb.WriteString("\nNot Ready Addresses:\n")
-				for _, addr := range subset.NotReadyAddresses {
-					ref := ""
-					if addr.TargetRef != nil {
-						ref = fmt.Sprintf(" (%s/%s)", addr.TargetRef.Kind, addr.TargetRef.Name)
-					}
-					for _, port := range subset.Ports {
-						sb.WriteString(fmt.Sprintf("  %s:%d%s\n", addr.IP, port.Port, ref))
-					}
-				}
+		zones := health.ZoneDistribution()
+		if len(zones) > 1 || (len(zones) == 1 && zones[0].Zone != "<none>") {
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("Zone Distribution"))
+			sb.WriteString("\n")
+			rows := make([][]string, 0, len(zones))
+			for _, z := range zones {
+				rows = append(rows, []string{z.Zone, fmt.Sprintf("%d", z.Ready), fmt.Sprintf("%d", z.NotReady)})
 			}
+			sb.WriteString(util.FormatTable([]string{"ZONE", "READY", "NOT-READY"}, rows))
 		}
 
-		if totalAddresses == 0 && len(endpoints.Subsets) == 0 {
-			sb.WriteString("\n(no endpoints - check service selector matches pod labels)\n")
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Findings"))
+		sb.WriteString("\n")
+		findings := writeEndpointFindings(&sb, health, svc)
+		if findings == 0 {
+			sb.WriteString("  No endpoint issues found.\n")
 		}
 
 		return util.SuccessResult(sb.String()), nil, nil
 	})
 }
+
+// writeEndpointFindings reports readiness, slice ownership, dual-stack and topology hint
+// problems for a service's endpoints. svc may be nil when the service no longer exists.
+func writeEndpointFindings(sb *strings.Builder, health *k8s.EndpointHealth, svc *corev1.Service) int {
+	findings := 0
+	finding := func(severity, msg string) {
+		sb.WriteString(util.FormatFinding(severity, msg))
+		sb.WriteString("\n")
+		findings++
+	}
+
+	if health.Source == k8s.EndpointSourceCore {
+		finding("INFO", "No EndpointSlices found; showing core/v1 Endpoints, which are deprecated and capped at 1000 addresses")
+	}
+	switch {
+	case health.TotalEndpoints == 0 && health.TerminatingCount == 0:
+		finding("CRITICAL", "Service has no endpoints — check the service selector matches pod labels")
+	case health.ReadyCount == 0:
+		finding("CRITICAL", fmt.Sprintf("Service has 0 ready endpoints (%d not ready, %d terminating) — traffic will fail", health.NotReadyCount, health.TerminatingCount))
+	case health.NotReadyCount > 0:
+		finding("WARNING", fmt.Sprintf("%d of %d endpoints not ready — partial availability", health.NotReadyCount, health.TotalEndpoints))
+	}
+	if health.TerminatingCount > 0 {
+		serving := 0
+		for _, ea := range health.Terminating {
+			if ea.Serving {
+				serving++
+			}
+		}
+		finding("INFO", fmt.Sprintf("%d endpoint(s) terminating, %d still serving — expected during rollouts; persistent terminating endpoints point at slow shutdown", health.TerminatingCount, serving))
+	}
+
+	for _, s := range health.CustomManagedSlices() {
+		switch s.ManagedBy {
+		case k8s.EndpointSliceMirroringControllerName:
+			finding("INFO", fmt.Sprintf("EndpointSlice '%s' is mirrored from a manually maintained Endpoints object — it does not follow the service selector", s.Name))
+		case "":
+			finding("WARNING", fmt.Sprintf("EndpointSlice '%s' has no %s label — it is maintained by hand and never updated automatically", s.Name, discoveryv1.LabelManagedBy))
+		default:
+			finding("WARNING", fmt.Sprintf("EndpointSlice '%s' is managed by '%s', not the built-in EndpointSlice controller — check that controller if endpoints look stale", s.Name, s.ManagedBy))
+		}
+	}
+
+	if svc != nil && len(svc.Spec.IPFamilies) > 1 && !health.DualStack() && health.TotalEndpoints > 0 {
+		finding("WARNING", fmt.Sprintf("Service is dual-stack (%s) but endpoints only have %s addresses — clients on the other family cannot connect",
+			joinIPFamilies(svc.Spec.IPFamilies), strings.Join(health.AddressFamilies, ", ")))
+	}
+
+	if svc != nil && topologyRoutingRequested(svc) && health.ReadyCount > 0 {
+		if health.HintedCount == 0 {
+			finding("WARNING", "Topology-aware routing is requested but no endpoints carry zone hints — the controller falls back to cluster-wide routing (too few endpoints, unbalanced zones, or nodes missing zone labels)")
+		} else if health.HintedCount < health.TotalEndpoints {
+			finding("INFO", fmt.Sprintf("Only %d of %d endpoints carry zone hints", health.HintedCount, health.TotalEndpoints))
+		}
+	}
+	for _, z := range health.ZoneDistribution() {
+		if z.Ready == 0 && z.NotReady > 0 {
+			finding("WARNING", fmt.Sprintf("Zone '%s' has no ready endpoints (%d not ready)", z.Zone, z.NotReady))
+		}
+	}
+	return findings
+}
+
+// topologyRoutingRequested reports whether the service opts into topology-aware routing.
+func topologyRoutingRequested(svc *corev1.Service) bool {
+	if mode := svc.Annotations["service.kubernetes.io/topology-mode"]; mode != "" && !strings.EqualFold(mode, "disabled") {
+		return true
+	}
+	if hints := svc.Annotations["service.kubernetes.io/topology-aware-hints"]; strings.EqualFold(hints, "auto") {
+		return true
+	}
+	return svc.Spec.TrafficDistribution != nil && *svc.Spec.TrafficDistribution != ""
+}
+
+func joinIPFamilies(families []corev1.IPFamily) string {
+	out := make([]string, len(families))
+	for i, f := range families {
+		out[i] = string(f)
+	}
+	return strings.Join(out, ", ")
+}
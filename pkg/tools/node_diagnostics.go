@@ -0,0 +1,416 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/health"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+type diagnoseNodeInput struct {
+	Name string `json:"name" jsonschema:"required,Node name"`
+}
+
+// standardNodeConditions are the conditions set by the kubelet itself.
+// Any other condition type is reported by node-problem-detector or a similar agent.
+var standardNodeConditions = map[corev1.NodeConditionType]bool{
+	corev1.NodeReady:              true,
+	corev1.NodeMemoryPressure:     true,
+	corev1.NodeDiskPressure:       true,
+	corev1.NodePIDPressure:        true,
+	corev1.NodeNetworkUnavailable: true,
+}
+
+// criticalProblemConditions are node-problem-detector conditions that usually require draining the node.
+var criticalProblemConditions = map[string]bool{
+	"KernelDeadlock":              true,
+	"ReadonlyFilesystem":          true,
+	"FilesystemCorruptionProblem": true,
+	"CorruptDockerOverlay2":       true,
+}
+
+// imageGCEventReasons are kubelet event reasons that indicate image garbage collection trouble.
+var imageGCEventReasons = map[string]bool{
+	"ImageGCFailed":       true,
+	"FreeDiskSpaceFailed": true,
+	"InvalidDiskCapacity": true,
+}
+
+func registerNodeDiagnosticTools(server *mcp.Server, client *k8s.ClusterClient) {
+	// diagnose_node
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "diagnose_node",
+		Description: "Deep node diagnosis: conditions including node-problem-detector signals (KernelDeadlock, ReadonlyFilesystem), " +
+			"kubelet heartbeat Lease and NotReady flapping history, pods on the node with eviction events, image GC pressure, " +
+			"and per-pod ephemeral storage, inode, and PID usage from the kubelet /stats/summary API via the apiserver node proxy.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input diagnoseNodeInput) (*mcp.CallToolResult, any, error) {
+		node, err := client.GetNode(ctx, input.Name)
+		if err != nil {
+			return util.HandleK8sError(fmt.Sprintf("getting node %s", input.Name), err), nil, nil
+		}
+
+		var sb strings.Builder
+		sb.WriteString(util.FormatHeader(fmt.Sprintf("Node Diagnosis: %s", node.Name)))
+		sb.WriteString("\n\n")
+		findings := 0
+		var actions []string
+
+		nodeHealth := health.Node(node)
+		sb.WriteString(util.FormatKeyValue("STATUS", nodeStatus(node)))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("HEALTH", nodeHealth.String()))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("ROLES", nodeRoles(node)))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("KUBELET", node.Status.NodeInfo.KubeletVersion))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("AGE", util.FormatAge(node.CreationTimestamp.Time)))
+		sb.WriteString("\n")
+		if node.Spec.Unschedulable {
+			sb.WriteString(util.FormatFinding("INFO", "Node is cordoned (unschedulable)"))
+			sb.WriteString("\n")
+		}
+
+		// 1. Conditions
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Conditions"))
+		sb.WriteString("\n")
+		var readyCond *corev1.NodeCondition
+		for i := range node.Status.Conditions {
+			cond := &node.Status.Conditions[i]
+			if cond.Type == corev1.NodeReady {
+				readyCond = cond
+			}
+			source := "kubelet"
+			if !standardNodeConditions[cond.Type] {
+				source = "node-problem-detector"
+			}
+			sb.WriteString(fmt.Sprintf("  %-28s %-8s %-22s %s\n", string(cond.Type), string(cond.Status), source, cond.Message))
+		}
+		switch nodeHealth.Status {
+		case health.Failed, health.Unknown:
+			// A node whose kubelet stopped reporting is as unusable as one
+			// reporting NotReady, so both are critical here.
+			sb.WriteString(util.FormatFinding("CRITICAL", "Node is not healthy: "+nodeHealth.String()))
+			sb.WriteString("\n")
+			findings++
+			actions = append(actions, "Check kubelet and container runtime status on the node (systemctl status kubelet)")
+		case health.Terminating:
+			sb.WriteString(util.FormatFinding("WARNING", "Node is not healthy: "+nodeHealth.String()))
+			sb.WriteString("\n")
+			findings++
+		}
+		for _, cond := range node.Status.Conditions {
+			switch {
+			case standardNodeConditions[cond.Type] && cond.Type != corev1.NodeReady && cond.Status == corev1.ConditionTrue:
+				sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("Node has %s: %s", cond.Type, cond.Message)))
+				sb.WriteString("\n")
+				findings++
+			case !standardNodeConditions[cond.Type] && cond.Status == corev1.ConditionTrue:
+				severity := "WARNING"
+				if criticalProblemConditions[string(cond.Type)] {
+					severity = "CRITICAL"
+					actions = append(actions, fmt.Sprintf("Cordon and drain the node — %s usually requires a reboot or replacement", cond.Type))
+				}
+				sb.WriteString(util.FormatFinding(severity, fmt.Sprintf("node-problem-detector reports %s (%s): %s", cond.Type, cond.Reason, cond.Message)))
+				sb.WriteString("\n")
+				findings++
+			}
+		}
+
+		// 2. Node events (NPD, eviction, image GC, readiness transitions)
+		nodeEvents, _ := client.ListEvents(ctx, "", metav1.ListOptions{
+			FieldSelector: "involvedObject.kind=Node,involvedObject.name=" + node.Name,
+		})
+
+		// 3. Heartbeat lease and readiness flapping
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Heartbeat & Readiness History"))
+		sb.WriteString("\n")
+		lease, leaseErr := client.GetNodeLease(ctx, node.Name)
+		if leaseErr != nil {
+			sb.WriteString(fmt.Sprintf("  (could not get lease %s/%s: %v)\n", k8s.NodeLeaseNamespace, node.Name, leaseErr))
+		} else {
+			duration := time.Duration(0)
+			if lease.Spec.LeaseDurationSeconds != nil {
+				duration = time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+			}
+			if lease.Spec.RenewTime != nil {
+				sinceRenew := time.Since(lease.Spec.RenewTime.Time)
+				sb.WriteString(fmt.Sprintf("  Lease last renewed: %s ago (duration %s)\n", util.FormatAge(lease.Spec.RenewTime.Time), duration))
+				if duration > 0 && sinceRenew > duration {
+					sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("Kubelet heartbeat is stale — lease not renewed for %s (lease duration %s)", sinceRenew.Round(time.Second), duration)))
+					sb.WriteString("\n")
+					findings++
+					actions = append(actions, "Kubelet is not renewing its lease — check kubelet logs and node network connectivity to the apiserver")
+				}
+			} else {
+				sb.WriteString("  Lease has never been renewed\n")
+			}
+		}
+		if readyCond != nil {
+			sb.WriteString(fmt.Sprintf("  Ready condition last changed: %s ago\n", util.FormatAge(readyCond.LastTransitionTime.Time)))
+		}
+		notReadyTransitions := 0
+		for _, e := range nodeEvents {
+			if e.Reason == "NodeNotReady" {
+				notReadyTransitions += eventCount(e)
+			}
+		}
+		if notReadyTransitions > 0 {
+			sb.WriteString(fmt.Sprintf("  NotReady transitions in recent events: %d\n", notReadyTransitions))
+		}
+		recentlyChanged := readyCond != nil && time.Since(readyCond.LastTransitionTime.Time) < time.Hour
+		if notReadyTransitions >= 2 || (notReadyTransitions >= 1 && recentlyChanged && readyCond.Status == corev1.ConditionTrue) {
+			sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("Node readiness is flapping (%d NotReady transitions in recent events)", notReadyTransitions)))
+			sb.WriteString("\n")
+			findings++
+			actions = append(actions, "Investigate intermittent kubelet heartbeats — check node CPU/memory saturation, network, and kubelet restarts")
+		}
+
+		// 4. Pods on the node
+		pods, err := client.ListPods(ctx, "", metav1.ListOptions{FieldSelector: "spec.nodeName=" + node.Name})
+		if err != nil {
+			sb.WriteString(fmt.Sprintf("\n  (could not list pods on node: %v)\n", err))
+		}
+		podsOnNode := make(map[string]bool, len(pods))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader(fmt.Sprintf("Pods on Node (%d)", len(pods))))
+		sb.WriteString("\n")
+		headers := []string{"POD", "NAMESPACE", "STATUS", "READY", "RESTARTS", "AGE"}
+		rows := make([][]string, 0, len(pods))
+		var evicted []corev1.Pod
+		unhealthy := 0
+		for i := range pods {
+			p := &pods[i]
+			podsOnNode[p.Namespace+"/"+p.Name] = true
+			if p.Status.Reason == "Evicted" {
+				evicted = append(evicted, *p)
+			}
+			if !isPodHealthy(p) {
+				unhealthy++
+			}
+			ready, total, restarts := podContainerSummary(p)
+			rows = append(rows, []string{
+				p.Name,
+				p.Namespace,
+				podPhaseReason(p),
+				fmt.Sprintf("%d/%d", ready, total),
+				fmt.Sprintf("%d", restarts),
+				util.FormatAge(p.CreationTimestamp.Time),
+			})
+		}
+		sb.WriteString(util.FormatTable(headers, rows))
+		if unhealthy > 0 {
+			sb.WriteString(fmt.Sprintf("\n%s\n", util.FormatFinding("WARNING", fmt.Sprintf("%d unhealthy pod(s) on this node", unhealthy))))
+			findings++
+		}
+
+		// 5. Evictions
+		evictionEvents, _ := client.ListEvents(ctx, "", metav1.ListOptions{FieldSelector: "reason=Evicted"})
+		var nodeEvictionEvents []corev1.Event
+		for _, e := range evictionEvents {
+			if e.Reason == "Evicted" && podsOnNode[e.InvolvedObject.Namespace+"/"+e.InvolvedObject.Name] {
+				nodeEvictionEvents = append(nodeEvictionEvents, e)
+			}
+		}
+		for _, e := range nodeEvents {
+			if e.Reason == "EvictionThresholdMet" {
+				nodeEvictionEvents = append(nodeEvictionEvents, e)
+			}
+		}
+		if len(evicted) > 0 || len(nodeEvictionEvents) > 0 {
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("Evictions"))
+			sb.WriteString("\n")
+			if len(evicted) > 0 {
+				sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("%d evicted pod(s) on this node", len(evicted))))
+				sb.WriteString("\n")
+				for _, p := range evicted {
+					sb.WriteString(fmt.Sprintf("  - %s/%s: %s\n", p.Namespace, p.Name, p.Status.Message))
+				}
+				findings++
+				actions = append(actions, "Clean up evicted pods and address the resource pressure that caused them")
+			}
+			for _, e := range nodeEvictionEvents {
+				sb.WriteString(fmt.Sprintf("  [%s] %s/%s: %s", e.Reason, e.InvolvedObject.Namespace, e.InvolvedObject.Name, e.Message))
+				if e.Count > 1 {
+					sb.WriteString(fmt.Sprintf(" (x%d)", e.Count))
+				}
+				sb.WriteString("\n")
+			}
+		}
+
+		// 6. Node warning events, grouped by origin
+		var npdEvents, gcEvents, otherEvents []corev1.Event
+		for _, e := range nodeEvents {
+			switch {
+			case imageGCEventReasons[e.Reason]:
+				gcEvents = append(gcEvents, e)
+			case strings.HasSuffix(e.Source.Component, "-monitor"):
+				npdEvents = append(npdEvents, e)
+			case e.Type == "Warning" && e.Reason != "EvictionThresholdMet":
+				otherEvents = append(otherEvents, e)
+			}
+		}
+		if len(npdEvents) > 0 {
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("node-problem-detector Events"))
+			sb.WriteString("\n")
+			for _, e := range npdEvents {
+				sb.WriteString(fmt.Sprintf("  [%s] %s: %s", e.Source.Component, e.Reason, e.Message))
+				if e.Count > 1 {
+					sb.WriteString(fmt.Sprintf(" (x%d)", e.Count))
+				}
+				sb.WriteString("\n")
+			}
+			sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("%d node-problem-detector event(s) (kernel/runtime problems)", len(npdEvents))))
+			sb.WriteString("\n")
+			findings++
+		}
+		if len(otherEvents) > 0 {
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("Node Warning Events"))
+			sb.WriteString("\n")
+			for _, e := range otherEvents {
+				sb.WriteString(fmt.Sprintf("  - %s: %s", e.Reason, e.Message))
+				if e.Count > 1 {
+					sb.WriteString(fmt.Sprintf(" (x%d)", e.Count))
+				}
+				sb.WriteString("\n")
+			}
+		}
+
+		// 7. Kubelet stats summary
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Kubelet Stats (/stats/summary)"))
+		sb.WriteString("\n")
+		summary, statsErr := client.GetNodeStatsSummary(ctx, node.Name)
+		var imageFs *k8s.FsStats
+		if statsErr != nil {
+			sb.WriteString(fmt.Sprintf("  (kubelet stats not available: %v)\n", statsErr))
+		} else {
+			if fs := summary.Node.Fs; fs != nil {
+				sb.WriteString(fmt.Sprintf("  Node FS:   %s / %s (%.1f%%), inodes %d/%d (%.1f%%)\n",
+					formatBytes(int64(fs.UsedBytes)), formatBytes(int64(fs.CapacityBytes)), fs.UsedPercent(),
+					fs.InodesUsed, fs.Inodes, fs.InodesUsedPercent()))
+				if fs.InodesUsedPercent() >= float64(util.ResourceUsageWarningPercent) {
+					sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("Node filesystem inode usage at %.1f%%", fs.InodesUsedPercent())))
+					sb.WriteString("\n")
+					findings++
+				}
+			}
+			if summary.Node.Runtime != nil && summary.Node.Runtime.ImageFs != nil {
+				imageFs = summary.Node.Runtime.ImageFs
+				sb.WriteString(fmt.Sprintf("  Image FS:  %s / %s (%.1f%%)\n",
+					formatBytes(int64(imageFs.UsedBytes)), formatBytes(int64(imageFs.CapacityBytes)), imageFs.UsedPercent()))
+			}
+			if rl := summary.Node.Rlimit; rl != nil && rl.MaxPID > 0 {
+				pidPct := float64(rl.CurProc) / float64(rl.MaxPID) * 100
+				sb.WriteString(fmt.Sprintf("  PIDs:      %d / %d (%.1f%%)\n", rl.CurProc, rl.MaxPID, pidPct))
+				if pidPct >= float64(util.ResourceUsageWarningPercent) {
+					sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("Node PID usage at %.1f%% — risk of PIDPressure", pidPct)))
+					sb.WriteString("\n")
+					findings++
+				}
+			}
+
+			podStats := make([]k8s.PodStats, len(summary.Pods))
+			copy(podStats, summary.Pods)
+			sort.Slice(podStats, func(i, j int) bool {
+				return podEphemeralUsed(podStats[i]) > podEphemeralUsed(podStats[j])
+			})
+			if len(podStats) > util.DefaultTopLimit {
+				podStats = podStats[:util.DefaultTopLimit]
+			}
+			if len(podStats) > 0 {
+				sb.WriteString(fmt.Sprintf("\n  Top %d pods by ephemeral storage:\n", len(podStats)))
+				statHeaders := []string{"POD", "NAMESPACE", "EPHEMERAL", "INODES", "PIDS"}
+				statRows := make([][]string, 0, len(podStats))
+				for _, ps := range podStats {
+					inodes := "-"
+					if ps.EphemeralStorage != nil {
+						inodes = fmt.Sprintf("%d", ps.EphemeralStorage.InodesUsed)
+					}
+					pids := "-"
+					if ps.ProcessStats != nil {
+						pids = fmt.Sprintf("%d", ps.ProcessStats.ProcessCount)
+					}
+					statRows = append(statRows, []string{
+						ps.PodRef.Name,
+						ps.PodRef.Namespace,
+						formatBytes(int64(podEphemeralUsed(ps))),
+						inodes,
+						pids,
+					})
+				}
+				sb.WriteString("  ")
+				sb.WriteString(strings.ReplaceAll(util.FormatTable(statHeaders, statRows), "\n", "\n  "))
+				sb.WriteString("\n")
+			}
+		}
+
+		// 8. Image GC pressure
+		imageGCPressure := imageFs != nil && imageFs.UsedPercent() >= float64(util.ImageGCHighThresholdPercent)
+		if imageGCPressure || len(gcEvents) > 0 {
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("Image GC"))
+			sb.WriteString("\n")
+			if imageGCPressure {
+				sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("Image filesystem at %.1f%% — above the kubelet image GC threshold (%d%%)", imageFs.UsedPercent(), util.ImageGCHighThresholdPercent)))
+				sb.WriteString("\n")
+				findings++
+			}
+			for _, e := range gcEvents {
+				sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("%s: %s", e.Reason, e.Message)))
+				sb.WriteString("\n")
+				findings++
+			}
+			actions = append(actions, "Free image filesystem space (prune unused images, check for large images) or enlarge the node disk")
+		}
+
+		// Summary
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Assessment"))
+		sb.WriteString("\n")
+		if findings == 0 {
+			sb.WriteString("  Node appears healthy. No issues found.\n")
+		} else {
+			sb.WriteString(fmt.Sprintf("  %d finding(s) identified. Review details above.\n", findings))
+		}
+
+		if len(actions) > 0 {
+			sb.WriteString("\nSUGGESTED ACTIONS:\n")
+			for i, a := range dedupe(actions) {
+				sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, a))
+			}
+		}
+
+		return util.SuccessResult(sb.String()), nil, nil
+	})
+}
+
+// podEphemeralUsed returns the ephemeral storage bytes used by a pod according to kubelet stats.
+func podEphemeralUsed(ps k8s.PodStats) uint64 {
+	if ps.EphemeralStorage == nil {
+		return 0
+	}
+	return ps.EphemeralStorage.UsedBytes
+}
+
+// eventCount returns the number of occurrences recorded by an event (at least 1).
+func eventCount(e corev1.Event) int {
+	if e.Count > 1 {
+		return int(e.Count)
+	}
+	return 1
+}
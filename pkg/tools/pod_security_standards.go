@@ -0,0 +1,209 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+type checkPodSecurityStandardsInput struct {
+	Namespace string `json:"namespace" jsonschema:"required,Namespace to evaluate"`
+}
+
+// pssWorkload is a pod or pod template evaluated against the Pod Security Standards.
+type pssWorkload struct {
+	kind       string
+	name       string
+	violations []k8s.PSSViolation
+}
+
+// pssRank orders levels from least to most strict.
+var pssRank = map[k8s.PSSLevel]int{k8s.PSSPrivileged: 0, k8s.PSSBaseline: 1, k8s.PSSRestricted: 2}
+
+func registerPodSecurityStandardsTools(server *mcp.Server, client *k8s.ClusterClient) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "check_pod_security_standards",
+		Description: "Evaluate every pod and pod template (Deployments, StatefulSets, DaemonSets, Jobs, CronJobs) in a namespace " +
+			"against the Pod Security Standards (privileged/baseline/restricted), field by field. Reads the namespace's " +
+			"pod-security.kubernetes.io enforce/audit/warn labels and produces a migration plan listing the workloads " +
+			"that would be rejected if the namespace were raised to the next level.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input checkPodSecurityStandardsInput) (*mcp.CallToolResult, any, error) {
+		ns, err := client.GetNamespace(ctx, input.Namespace)
+		if err != nil {
+			return util.HandleK8sError(fmt.Sprintf("getting namespace %s", input.Namespace), err), nil, nil
+		}
+		specs, err := collectWorkloadPodSpecs(ctx, client, input.Namespace)
+		if err != nil {
+			return util.HandleK8sError(fmt.Sprintf("listing workloads in %s", input.Namespace), err), nil, nil
+		}
+		workloads := make([]pssWorkload, 0, len(specs))
+		for _, w := range specs {
+			workloads = append(workloads, pssWorkload{kind: w.kind, name: w.name, violations: k8s.EvaluatePodSecurity(w.annotations, w.spec)})
+		}
+		cfg := k8s.ParsePSSLabels(ns.Labels)
+
+		var sb strings.Builder
+		sb.WriteString(util.FormatHeader(fmt.Sprintf("Pod Security Standards: %s", input.Namespace)))
+		sb.WriteString("\n\n")
+		findings := 0
+		var actions []string
+
+		// 1. Namespace labels
+		sb.WriteString(util.FormatSubHeader("Pod Security Admission Labels"))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("Enforce", fmt.Sprintf("%s (version %s)", cfg.Enforce, cfg.EnforceVersion)))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("Audit", fmt.Sprintf("%s (version %s)", cfg.Audit, cfg.AuditVersion)))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("Warn", fmt.Sprintf("%s (version %s)", cfg.Warn, cfg.WarnVersion)))
+		sb.WriteString("\n")
+		hasLabels := false
+		for key := range ns.Labels {
+			if strings.HasPrefix(key, k8s.PSSLabelPrefix) {
+				hasLabels = true
+				break
+			}
+		}
+		if !hasLabels {
+			sb.WriteString(util.FormatFinding("WARNING", "No pod-security.kubernetes.io labels set — namespace uses the cluster default (usually privileged)"))
+			sb.WriteString("\n")
+			findings++
+		}
+		for _, mode := range []struct {
+			name  string
+			level k8s.PSSLevel
+		}{{"enforce", cfg.Enforce}, {"audit", cfg.Audit}, {"warn", cfg.Warn}} {
+			if _, ok := pssRank[mode.level]; !ok {
+				sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("Unrecognized %s level %q", mode.name, mode.level)))
+				sb.WriteString("\n")
+				findings++
+				actions = append(actions, fmt.Sprintf("Fix the %s%s label on namespace %s (must be privileged, baseline or restricted)", k8s.PSSLabelPrefix, mode.name, input.Namespace))
+			}
+		}
+		if pssRank[cfg.Audit] > pssRank[cfg.Enforce] || pssRank[cfg.Warn] > pssRank[cfg.Enforce] {
+			sb.WriteString(util.FormatFinding("INFO", "audit/warn are stricter than enforce — a migration to a stricter level is already staged"))
+			sb.WriteString("\n")
+		}
+
+		// 2. Workload evaluation
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Workload Evaluation"))
+		sb.WriteString("\n")
+		if len(workloads) == 0 {
+			sb.WriteString("  No pods or pod templates found.\n")
+		} else {
+			headers := []string{"WORKLOAD", "KIND", "HIGHEST LEVEL", "BASELINE", "RESTRICTED"}
+			var rows [][]string
+			for _, w := range workloads {
+				baseline, restricted := 0, 0
+				for _, v := range w.violations {
+					if v.Level == k8s.PSSBaseline {
+						baseline++
+					} else {
+						restricted++
+					}
+				}
+				rows = append(rows, []string{w.name, w.kind, string(k8s.HighestPSSLevel(w.violations)), fmt.Sprintf("%d", baseline), fmt.Sprintf("%d", restricted)})
+			}
+			sb.WriteString(util.FormatTable(headers, rows))
+			sb.WriteString(fmt.Sprintf("\n%s\n", util.FormatCount("workloads", len(workloads))))
+		}
+
+		// Workloads already violating the enforced level keep running, but new pods would be rejected
+		for _, w := range workloads {
+			if k8s.ViolatesLevel(w.violations, cfg.Enforce) {
+				sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("%s/%s violates the enforced %s level — new pods will be rejected", w.kind, w.name, cfg.Enforce)))
+				sb.WriteString("\n")
+				findings++
+				actions = append(actions, fmt.Sprintf("Fix %s/%s before its next rollout; its pods violate the enforced %s level", w.kind, w.name, cfg.Enforce))
+			}
+		}
+
+		// 3. Field-by-field violations
+		var detailed []pssWorkload
+		for _, w := range workloads {
+			if len(w.violations) > 0 {
+				detailed = append(detailed, w)
+			}
+		}
+		if len(detailed) > 0 {
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("Violations"))
+			sb.WriteString("\n")
+			for _, w := range detailed {
+				sb.WriteString(fmt.Sprintf("  %s/%s:\n", w.kind, w.name))
+				for _, v := range w.violations {
+					sb.WriteString(fmt.Sprintf("    [%s] %s: %s\n", v.Level, v.Check, v.Detail))
+				}
+			}
+		}
+
+		// 4. Migration plan
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Migration Plan"))
+		sb.WriteString("\n")
+		next := k8s.NextPSSLevel(cfg.Enforce)
+		if next == "" {
+			sb.WriteString("  Namespace already enforces restricted — no further hardening level available.\n")
+		}
+		for level := next; level != ""; level = k8s.NextPSSLevel(level) {
+			var blocked []string
+			for _, w := range workloads {
+				if k8s.ViolatesLevel(w.violations, level) {
+					blocked = append(blocked, w.kind+"/"+w.name)
+				}
+			}
+			if len(blocked) == 0 {
+				sb.WriteString(util.FormatFinding("OK", fmt.Sprintf("Raising enforce to %s would reject no workloads", level)))
+				sb.WriteString("\n")
+				if level == next {
+					actions = append(actions, fmt.Sprintf("Raise enforcement: kubectl label ns %s %senforce=%s --overwrite", input.Namespace, k8s.PSSLabelPrefix, level))
+				}
+				continue
+			}
+			severity := "INFO"
+			if level == next {
+				severity = "WARNING"
+				findings++
+			}
+			sb.WriteString(util.FormatFinding(severity, fmt.Sprintf("Raising enforce to %s would reject %d workload(s):", level, len(blocked))))
+			sb.WriteString("\n")
+			for _, b := range blocked {
+				sb.WriteString(fmt.Sprintf("    - %s\n", b))
+			}
+			if level == next {
+				actions = append(actions,
+					fmt.Sprintf("Stage the change first: kubectl label ns %s %swarn=%s %saudit=%s --overwrite", input.Namespace, k8s.PSSLabelPrefix, level, k8s.PSSLabelPrefix, level),
+					fmt.Sprintf("Fix the %d workload(s) listed for %s using the violation details above", len(blocked), level),
+					fmt.Sprintf("Once no warnings remain, raise enforcement: kubectl label ns %s %senforce=%s --overwrite", input.Namespace, k8s.PSSLabelPrefix, level))
+			}
+		}
+		if cfg.EnforceVersion == "latest" && hasLabels {
+			sb.WriteString(util.FormatFinding("INFO", "enforce-version is latest — pin it to a Kubernetes minor version to avoid surprises on upgrade"))
+			sb.WriteString("\n")
+		}
+
+		// Summary
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Summary"))
+		sb.WriteString("\n")
+		if findings == 0 {
+			sb.WriteString("  No Pod Security Standards issues found.\n")
+		} else {
+			sb.WriteString(fmt.Sprintf("  %d finding(s) identified. Review details above.\n", findings))
+		}
+		if len(actions) > 0 {
+			sb.WriteString("\nSUGGESTED ACTIONS:\n")
+			for i, a := range dedupe(actions) {
+				sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, a))
+			}
+		}
+
+		return util.SuccessResult(sb.String()), nil, nil
+	})
+}
@@ -0,0 +1,397 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+type probeServiceInput struct {
+	Namespace      string `json:"namespace" jsonschema:"required,Kubernetes namespace"`
+	ServiceName    string `json:"service_name" jsonschema:"required,Service to probe"`
+	Port           string `json:"port,omitempty" jsonschema:"Service port number or name (default: the first TCP port)"`
+	Mode           string `json:"mode,omitempty" jsonschema:"http (send a GET request, default) or tcp (only check that the port accepts connections)"`
+	Scheme         string `json:"scheme,omitempty" jsonschema:"http or https (default: https when the port is named https, has appProtocol https or is 443/8443)"`
+	Path           string `json:"path,omitempty" jsonschema:"URL path for HTTP probes (default /)"`
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty" jsonschema:"Per-request timeout in seconds (default 5, max 30)"`
+}
+
+const (
+	// maxProbedEndpoints caps how many backing pods are probed in one call.
+	maxProbedEndpoints = 20
+	// slowProbeThreshold is the latency above which a probe is reported as slow.
+	slowProbeThreshold = time.Second
+)
+
+// endpointProbe is the probe result for one backing pod.
+type endpointProbe struct {
+	pod      *corev1.Pod
+	state    string // endpoint state: ready, not-ready, terminating or none
+	port     int32
+	result   k8s.ProbeResult
+	tls      *k8s.ProbeTLSInfo
+	portNote string // set when the target port could not be resolved
+}
+
+func registerProbeTools(server *mcp.Server, client *k8s.ClusterClient) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "probe_service",
+		Description: "Actively probe a Service: sends an HTTP GET (or a TCP connect) to the service and to each backing pod through the " +
+			"apiserver's services/proxy and pods/proxy subresources, and reports the status code, latency and, for https, the TLS " +
+			"version, cipher and certificate of each endpoint. Makes 'endpoints are Ready but the app returns 503' and " +
+			"'Ready pod refuses connections' visible. Sends live requests — only use it when active probing is acceptable.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input probeServiceInput) (*mcp.CallToolResult, any, error) {
+		mode := strings.ToLower(input.Mode)
+		if mode == "" {
+			mode = k8s.ProbeModeHTTP
+		}
+		if mode != k8s.ProbeModeHTTP && mode != k8s.ProbeModeTCP {
+			return util.ErrorResult("unsupported mode %q (use http or tcp)", input.Mode), nil, nil
+		}
+		scheme := strings.ToLower(input.Scheme)
+		if scheme != "" && scheme != "http" && scheme != "https" {
+			return util.ErrorResult("unsupported scheme %q (use http or https)", input.Scheme), nil, nil
+		}
+		timeout := 5 * time.Second
+		if input.TimeoutSeconds > 0 {
+			timeout = time.Duration(min(input.TimeoutSeconds, 30)) * time.Second
+		}
+		path := input.Path
+		if path == "" {
+			path = "/"
+		}
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+
+		svc, err := client.GetService(ctx, input.Namespace, input.ServiceName)
+		if err != nil {
+			return util.HandleK8sError(fmt.Sprintf("getting service %s/%s", input.Namespace, input.ServiceName), err), nil, nil
+		}
+		if svc.Spec.Type == corev1.ServiceTypeExternalName {
+			return util.ErrorResult("service %s/%s is an ExternalName service for %s and has no endpoints to probe", svc.Namespace, svc.Name, svc.Spec.ExternalName), nil, nil
+		}
+		sp := defaultProbePort(svc)
+		if input.Port != "" {
+			sp = findServicePort(svc, intstr.Parse(input.Port), corev1.ProtocolTCP)
+		}
+		if sp == nil {
+			return util.ErrorResult("service %s/%s has no TCP port %s", svc.Namespace, svc.Name, valueOrNone(input.Port)), nil, nil
+		}
+		if scheme == "" {
+			scheme = probeScheme(sp)
+		}
+		targetPort := sp.TargetPort
+		if targetPort.Type == intstr.Int && targetPort.IntVal == 0 {
+			targetPort = intstr.FromInt32(sp.Port)
+		}
+		opts := k8s.ProbeOptions{Mode: mode, Scheme: scheme, Path: path, Timeout: timeout}
+
+		// Endpoint state by pod; a missing Endpoints object just means nothing is ready.
+		states := map[string]string{}
+		health, err := client.GetServiceEndpointHealth(ctx, svc.Namespace, svc.Name)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return util.HandleK8sError(fmt.Sprintf("getting endpoints for %s/%s", svc.Namespace, svc.Name), err), nil, nil
+		}
+		if health != nil {
+			for _, a := range health.ReadyAddresses {
+				states[a.PodName] = "ready"
+			}
+			for _, a := range health.NotReadyPods {
+				states[a.PodName] = "not-ready"
+			}
+			for _, a := range health.Terminating {
+				states[a.PodName] = "terminating"
+			}
+		}
+		pods, err := client.GetPodsForService(ctx, svc)
+		if err != nil {
+			return util.HandleK8sError(fmt.Sprintf("listing pods for service %s", svc.Name), err), nil, nil
+		}
+
+		// Service-level probe: the apiserver picks a ready endpoint like a client would.
+		svcPortRef := sp.Name
+		if svcPortRef == "" {
+			svcPortRef = strconv.Itoa(int(sp.Port))
+		}
+		svcResult := client.ProbeService(ctx, svc.Namespace, svc.Name, svcPortRef, opts)
+
+		// Endpoint-level probes
+		var probes []*endpointProbe
+		skipped := 0
+		tlsNote := ""
+		for i := range pods {
+			pod := &pods[i]
+			if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed || pod.Status.PodIP == "" {
+				continue
+			}
+			if len(probes) == maxProbedEndpoints {
+				skipped++
+				continue
+			}
+			state := states[pod.Name]
+			if state == "" {
+				state = "none"
+			}
+			ep := &endpointProbe{pod: pod, state: state}
+			probes = append(probes, ep)
+			port, ok := k8s.ResolvePodPort(pod, targetPort, corev1.ProtocolTCP)
+			if !ok {
+				ep.portNote = fmt.Sprintf("pod has no container port named '%s'", targetPort.StrVal)
+				continue
+			}
+			ep.port = port
+			ep.result = client.ProbePod(ctx, pod, port, opts)
+			if scheme == "https" && ep.result.Connected && tlsNote == "" {
+				info, err := k8s.ProbeTLS(ctx, k8s.ProbeAddress(pod.Status.PodIP, port), k8s.ServiceDNSNames(svc.Namespace, svc.Name), min(timeout, 3*time.Second))
+				if err != nil {
+					// Usually the server runs outside the cluster network; don't retry for every pod.
+					tlsNote = fmt.Sprintf("TLS details unavailable: direct connection to pod %s failed (%v). The apiserver proxy does not expose the backend handshake; run kube-doctor inside the cluster to inspect certificates.", pod.Name, err)
+					continue
+				}
+				ep.tls = info
+			}
+		}
+
+		var sb strings.Builder
+		method := "HTTP GET"
+		if mode == k8s.ProbeModeTCP {
+			method = "TCP connect"
+		}
+		sb.WriteString(util.FormatHeader(fmt.Sprintf("Service Probe: %s/%s port %s (%s)", svc.Namespace, svc.Name, svcPortRef, method)))
+		sb.WriteString("\n\n")
+		target := fmt.Sprintf("%s://%s.%s.svc:%d%s -> targetPort %s", scheme, svc.Name, svc.Namespace, sp.Port, path, targetPort.String())
+		if mode == k8s.ProbeModeTCP {
+			target = fmt.Sprintf("tcp://%s.%s.svc:%d -> targetPort %s", svc.Name, svc.Namespace, sp.Port, targetPort.String())
+		}
+		sb.WriteString(util.FormatKeyValue("Target", target))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("Via", "apiserver services/proxy and pods/proxy (latency includes the apiserver hop)"))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("Timeout", timeout.String()))
+		sb.WriteString("\n")
+
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Service"))
+		sb.WriteString("\n")
+		sb.WriteString(fmt.Sprintf("  %s in %s\n", probeResultLabel(mode, svcResult), formatProbeLatency(svcResult)))
+		if svcResult.Body != "" {
+			sb.WriteString(fmt.Sprintf("  Body: %s\n", svcResult.Body))
+		}
+
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Endpoints"))
+		sb.WriteString("\n")
+		if len(probes) == 0 {
+			sb.WriteString("  No running pods with an IP match the service selector.\n")
+		} else {
+			rows := make([][]string, 0, len(probes))
+			for _, ep := range probes {
+				result, latency, address := ep.portNote, "-", ep.pod.Status.PodIP
+				if ep.portNote == "" {
+					result = probeResultLabel(mode, ep.result)
+					latency = formatProbeLatency(ep.result)
+					address = k8s.ProbeAddress(ep.pod.Status.PodIP, ep.port)
+				}
+				rows = append(rows, []string{ep.pod.Name, ep.state, address, util.TruncateString(result, 70), latency})
+			}
+			sb.WriteString(util.FormatTable([]string{"POD", "ENDPOINT", "ADDRESS", "RESULT", "LATENCY"}, rows))
+			for _, ep := range probes {
+				if ep.result.Body != "" {
+					sb.WriteString(fmt.Sprintf("  %s body: %s\n", ep.pod.Name, ep.result.Body))
+				}
+			}
+			if skipped > 0 {
+				sb.WriteString(fmt.Sprintf("  ... %d more pod(s) not probed (limit %d)\n", skipped, maxProbedEndpoints))
+			}
+		}
+
+		if scheme == "https" {
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("TLS"))
+			sb.WriteString("\n")
+			var rows [][]string
+			for _, ep := range probes {
+				if ep.tls == nil {
+					continue
+				}
+				expires := "-"
+				if !ep.tls.NotAfter.IsZero() {
+					expires = ep.tls.NotAfter.Format("2006-01-02")
+				}
+				rows = append(rows, []string{ep.pod.Name, ep.tls.Version, ep.tls.CipherSuite, valueOrNone(ep.tls.Subject),
+					valueOrNone(ep.tls.Issuer), expires, fmt.Sprintf("%t", ep.tls.NameMatches)})
+			}
+			if len(rows) > 0 {
+				sb.WriteString(util.FormatTable([]string{"POD", "VERSION", "CIPHER", "SUBJECT", "ISSUER", "EXPIRES", "NAME-MATCH"}, rows))
+			}
+			if tlsNote != "" {
+				sb.WriteString(util.FormatFinding("INFO", tlsNote))
+				sb.WriteString("\n")
+			}
+		}
+
+		// Findings
+		findings := 0
+		var actions []string
+		var lines []string
+		add := func(severity, msg, action string) {
+			lines = append(lines, util.FormatFinding(severity, msg))
+			if severity != "INFO" {
+				findings++
+			}
+			if action != "" {
+				actions = append(actions, action)
+			}
+		}
+
+		succeeded, failingReady := 0, 0
+		for _, ep := range probes {
+			name := ep.pod.Name
+			res := ep.result
+			switch {
+			case ep.portNote != "":
+				add("CRITICAL", fmt.Sprintf("Pod '%s': %s — the service cannot route to it", name, ep.portNote),
+					fmt.Sprintf("Name the container port '%s' or point the service targetPort at a numeric port", targetPort.StrVal))
+			case !res.Connected:
+				if ep.state == "ready" {
+					failingReady++
+					add("CRITICAL", fmt.Sprintf("Pod '%s' is a Ready endpoint but port %d is unreachable: %s", name, ep.port, res.Error),
+						fmt.Sprintf("Check that the container in '%s' listens on port %d on all interfaces (0.0.0.0, not 127.0.0.1) and that the service targetPort matches the containerPort", name, ep.port))
+				} else {
+					add("WARNING", fmt.Sprintf("Pod '%s' (%s) is unreachable on port %d: %s", name, ep.state, ep.port, res.Error), "")
+				}
+			case res.Error != "":
+				add("WARNING", fmt.Sprintf("Pod '%s' accepted the connection but did not answer %s: %s", name, strings.ToUpper(scheme), res.Error),
+					probeSchemeAction(scheme, res.Error))
+			case mode == k8s.ProbeModeTCP || res.StatusCode < http.StatusBadRequest:
+				succeeded++
+				if ep.state == "not-ready" {
+					add("INFO", fmt.Sprintf("Pod '%s' is not Ready but answers %s — its readiness probe checks something else or it is still warming up", name, probeResultLabel(mode, res)), "")
+				}
+			case res.StatusCode >= http.StatusInternalServerError:
+				if ep.state == "ready" {
+					failingReady++
+					add("CRITICAL", fmt.Sprintf("Pod '%s' is a Ready endpoint but returns %s", name, probeResultLabel(mode, res)),
+						fmt.Sprintf("Check the application logs with get_pod_logs pod_name=%s; its readiness probe does not cover the failing dependency", name))
+				} else {
+					add("WARNING", fmt.Sprintf("Pod '%s' (%s) returns %s", name, ep.state, probeResultLabel(mode, res)), "")
+				}
+			default:
+				add("WARNING", fmt.Sprintf("Pod '%s' returns %s for %s", name, probeResultLabel(mode, res), path),
+					"Check the probe path; 401/403 mean the endpoint needs credentials and 404 that the path does not exist")
+			}
+			if res.Connected && res.Latency > slowProbeThreshold {
+				add("WARNING", fmt.Sprintf("Pod '%s' took %s to answer", name, formatProbeLatency(res)), "")
+			}
+			if ep.tls != nil {
+				for _, p := range ep.tls.Problems {
+					add("WARNING", fmt.Sprintf("Pod '%s' TLS: %s", name, p), "Renew or reissue the serving certificate with the service DNS names as SANs")
+				}
+				if ep.tls.SelfSigned {
+					add("INFO", fmt.Sprintf("Pod '%s' presents a self-signed certificate", name), "")
+				}
+			}
+		}
+
+		switch {
+		case len(probes) == 0:
+			add("CRITICAL", "No running pods back this service", "Check the service selector with analyze_service_connectivity")
+		case !svcResult.Connected && succeeded > 0:
+			add("WARNING", fmt.Sprintf("Service probe failed (%s) although %d pod(s) answered directly", svcResult.Error, succeeded),
+				"Check the service port/targetPort mapping and that the answering pods are Ready endpoints")
+		case !svcResult.Connected:
+			add("CRITICAL", fmt.Sprintf("Service probe failed: %s", svcResult.Error), "")
+		}
+		if failingReady > 0 && health != nil && failingReady == health.ReadyCount {
+			add("CRITICAL", "Every Ready endpoint fails the probe — clients see errors even though the service looks healthy", "")
+		}
+
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Findings"))
+		sb.WriteString("\n")
+		if len(lines) == 0 {
+			sb.WriteString(util.FormatFinding("OK", "Service and all probed endpoints answered successfully"))
+			sb.WriteString("\n")
+		}
+		for _, l := range lines {
+			sb.WriteString(l)
+			sb.WriteString("\n")
+		}
+
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Summary"))
+		sb.WriteString("\n")
+		sb.WriteString(fmt.Sprintf("  %d/%d endpoint(s) answered successfully, %d finding(s).\n", succeeded, len(probes), findings))
+		if len(actions) > 0 {
+			sb.WriteString("\nSUGGESTED ACTIONS:\n")
+			for i, a := range dedupe(actions) {
+				sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, a))
+			}
+		}
+
+		return util.SuccessResult(sb.String()), nil, nil
+	})
+}
+
+// defaultProbePort returns the first TCP port of a service.
+func defaultProbePort(svc *corev1.Service) *corev1.ServicePort {
+	for i := range svc.Spec.Ports {
+		if svc.Spec.Ports[i].Protocol == "" || svc.Spec.Ports[i].Protocol == corev1.ProtocolTCP {
+			return &svc.Spec.Ports[i]
+		}
+	}
+	return nil
+}
+
+// probeScheme guesses whether a service port serves TLS from its name, appProtocol and number.
+func probeScheme(sp *corev1.ServicePort) string {
+	if sp.AppProtocol != nil && strings.EqualFold(*sp.AppProtocol, "https") {
+		return "https"
+	}
+	if strings.HasPrefix(strings.ToLower(sp.Name), "https") || sp.Port == 443 || sp.Port == 8443 {
+		return "https"
+	}
+	return "http"
+}
+
+// probeResultLabel summarizes a probe result, e.g. "503 Service Unavailable" or "open".
+func probeResultLabel(mode string, res k8s.ProbeResult) string {
+	switch {
+	case !res.Connected:
+		return "FAILED: " + res.Error
+	case res.Error != "":
+		return "connected, " + res.Error
+	case mode == k8s.ProbeModeTCP:
+		return "open"
+	default:
+		return fmt.Sprintf("%d %s", res.StatusCode, http.StatusText(res.StatusCode))
+	}
+}
+
+// formatProbeLatency formats probe latency in milliseconds.
+func formatProbeLatency(res k8s.ProbeResult) string {
+	return fmt.Sprintf("%dms", res.Latency.Milliseconds())
+}
+
+// probeSchemeAction suggests switching scheme when the answer looks like the other protocol.
+func probeSchemeAction(scheme, errMsg string) string {
+	switch {
+	case scheme == "http" && strings.Contains(errMsg, "malformed HTTP response"):
+		return "The port may not speak plain HTTP; retry with scheme=https, or mode=tcp for non-HTTP protocols"
+	case scheme == "https" && (strings.Contains(errMsg, "first record does not look like a TLS handshake") || strings.Contains(errMsg, "server gave HTTP response")):
+		return "The port serves plain HTTP; retry with scheme=http"
+	}
+	return "Retry with mode=tcp if the port serves a non-HTTP protocol"
+}
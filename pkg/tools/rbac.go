@@ -0,0 +1,355 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+type whoCanInput struct {
+	Verb      string `json:"verb" jsonschema:"required,Verb to check (get, list, watch, create, update, patch, delete, escalate, bind, impersonate, ...)"`
+	Resource  string `json:"resource" jsonschema:"required,Resource to check, optionally with group and subresource (e.g. pods, pods/exec, deployments.apps, nodes/proxy)"`
+	APIGroup  string `json:"api_group,omitempty" jsonschema:"API group of the resource (auto-detected via discovery when omitted; use core for the core group)"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"Namespace to check (empty for cluster-wide permissions only)"`
+	Name      string `json:"name,omitempty" jsonschema:"Specific resource name (matches rules restricted by resourceNames)"`
+}
+
+type whatCanInput struct {
+	Kind      string   `json:"kind" jsonschema:"required,Subject kind: ServiceAccount, User, or Group"`
+	Name      string   `json:"name" jsonschema:"required,Subject name"`
+	Namespace string   `json:"namespace,omitempty" jsonschema:"ServiceAccount namespace (required for ServiceAccount)"`
+	Groups    []string `json:"groups,omitempty" jsonschema:"Additional groups the user belongs to (e.g. from the identity provider)"`
+}
+
+// broadGroups are groups whose grants apply to large populations of identities.
+var broadGroups = map[string]string{
+	"system:authenticated":   "every authenticated identity",
+	"system:unauthenticated": "anonymous requests",
+	"system:serviceaccounts": "every service account in the cluster",
+}
+
+func registerRBACTools(server *mcp.Server, client *k8s.ClusterClient) {
+	// who_can
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "who_can",
+		Description: "Find every subject (user, group, service account) allowed to perform a verb on a resource. " +
+			"Resolves Roles and ClusterRoles (including aggregated ClusterRoles and wildcards) through RoleBindings and ClusterRoleBindings. " +
+			"Flags grants to broad groups such as system:authenticated.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input whoCanInput) (*mcp.CallToolResult, any, error) {
+		resource, subresource, group := parseResourceArg(input.Resource)
+		if input.APIGroup != "" {
+			group = normalizeAPIGroupArg(input.APIGroup)
+		} else if group == "" {
+			group = resolveAPIGroup(ctx, client, resource)
+		}
+
+		snapshot, err := client.GetRBACSnapshot(ctx)
+		if err != nil {
+			return util.HandleK8sError("reading RBAC configuration", err), nil, nil
+		}
+
+		request := k8s.ResourceRequest{
+			Verb:        input.Verb,
+			APIGroup:    group,
+			Resource:    resource,
+			Subresource: subresource,
+			Name:        input.Name,
+			Namespace:   input.Namespace,
+		}
+		grants := snapshot.WhoCan(request)
+
+		var sb strings.Builder
+		scope := "cluster-wide"
+		if input.Namespace != "" {
+			scope = "namespace: " + input.Namespace
+		}
+		sb.WriteString(util.FormatHeader(fmt.Sprintf("Who Can %s %s (%s)", input.Verb, input.Resource, scope)))
+		sb.WriteString("\n\n")
+		sb.WriteString(util.FormatKeyValue("API Group", groupDisplay(group)))
+		sb.WriteString("\n")
+		if input.Name != "" {
+			sb.WriteString(util.FormatKeyValue("Resource Name", input.Name))
+			sb.WriteString("\n")
+		}
+		findings := 0
+		var actions []string
+
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Subjects"))
+		sb.WriteString("\n")
+		headers := []string{"KIND", "SUBJECT", "VIA BINDING", "ROLE", "SCOPE"}
+		seen := make(map[string]bool)
+		var rows [][]string
+		for _, g := range grants {
+			key := fmt.Sprintf("%s|%s|%s|%s/%s|%s", subjectString(g.Subject), g.BindingKind, g.BindingName, g.RoleKind, g.RoleName, g.Namespace)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			rows = append(rows, []string{
+				g.Subject.Kind,
+				subjectString(g.Subject),
+				fmt.Sprintf("%s/%s", g.BindingKind, g.BindingName),
+				fmt.Sprintf("%s/%s", g.RoleKind, g.RoleName),
+				scopeDisplay(g.Namespace),
+			})
+			if g.Subject.Kind == rbacv1.GroupKind {
+				if who, ok := broadGroups[g.Subject.Name]; ok {
+					sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("%s/%s grants this to group %s (%s)", g.BindingKind, g.BindingName, g.Subject.Name, who)))
+					sb.WriteString("\n")
+					findings++
+					actions = append(actions, fmt.Sprintf("Restrict %s/%s to specific subjects instead of %s", g.BindingKind, g.BindingName, g.Subject.Name))
+				}
+			}
+		}
+		sort.Slice(rows, func(i, j int) bool { return rows[i][1] < rows[j][1] })
+		if len(rows) == 0 {
+			sb.WriteString("  No RBAC bindings grant this permission.\n")
+		} else {
+			sb.WriteString(util.FormatTable(headers, rows))
+			sb.WriteString(fmt.Sprintf("\n%s\n", util.FormatCount("grants", len(rows))))
+		}
+		sb.WriteString("\n  Note: members of system:masters bypass RBAC and are not listed.\n")
+
+		if reasons := k8s.DangerousGrantReasons(rbacv1.PolicyRule{
+			Verbs:     []string{input.Verb},
+			APIGroups: []string{group},
+			Resources: []string{strings.TrimSuffix(resource+"/"+subresource, "/")},
+		}); len(reasons) > 0 && len(rows) > 0 {
+			sb.WriteString(fmt.Sprintf("\n%s\n", util.FormatFinding("WARNING", fmt.Sprintf("This is a sensitive permission (%s) — review every subject above", strings.Join(reasons, "; ")))))
+			findings++
+		}
+
+		if len(actions) > 0 {
+			sb.WriteString("\nSUGGESTED ACTIONS:\n")
+			for i, a := range dedupe(actions) {
+				sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, a))
+			}
+		}
+
+		return util.SuccessResult(sb.String()), nil, nil
+	})
+
+	// what_can
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "what_can",
+		Description: "Expand the full effective RBAC permission set for a ServiceAccount, user, or group, including grants via " +
+			"implicit groups (system:serviceaccounts, system:authenticated) and aggregated ClusterRoles. " +
+			"Flags dangerous grants: secrets read, pods/exec, nodes/proxy, escalate, bind, impersonate, wildcard access.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input whatCanInput) (*mcp.CallToolResult, any, error) {
+		kind := normalizeSubjectKind(input.Kind)
+		if kind == "" {
+			return util.ErrorResult("invalid kind %q: must be ServiceAccount, User, or Group", input.Kind), nil, nil
+		}
+		if kind == rbacv1.ServiceAccountKind && input.Namespace == "" {
+			return util.ErrorResult("namespace is required for ServiceAccount subjects"), nil, nil
+		}
+
+		snapshot, err := client.GetRBACSnapshot(ctx)
+		if err != nil {
+			return util.HandleK8sError("reading RBAC configuration", err), nil, nil
+		}
+		grants := snapshot.GrantsForSubject(kind, input.Name, input.Namespace, input.Groups)
+
+		var sb strings.Builder
+		subjectName := input.Name
+		if kind == rbacv1.ServiceAccountKind {
+			subjectName = input.Namespace + "/" + input.Name
+		}
+		sb.WriteString(util.FormatHeader(fmt.Sprintf("What Can %s %s Do", kind, subjectName)))
+		sb.WriteString("\n\n")
+		findings := 0
+		var actions []string
+
+		// 1. Bindings
+		sb.WriteString(util.FormatSubHeader("Bindings"))
+		sb.WriteString("\n")
+		headers := []string{"BINDING", "ROLE", "SCOPE", "VIA"}
+		seenBinding := make(map[string]bool)
+		var rows [][]string
+		for _, g := range grants {
+			key := g.BindingKind + "/" + g.BindingNamespace + "/" + g.BindingName + "/" + subjectString(g.Subject)
+			if seenBinding[key] {
+				continue
+			}
+			seenBinding[key] = true
+			via := "direct"
+			if g.Subject.Kind == rbacv1.GroupKind && !(kind == rbacv1.GroupKind && g.Subject.Name == input.Name) {
+				via = "group " + g.Subject.Name
+			}
+			binding := g.BindingKind + "/" + g.BindingName
+			if g.BindingNamespace != "" {
+				binding = g.BindingKind + "/" + g.BindingNamespace + "/" + g.BindingName
+			}
+			rows = append(rows, []string{binding, g.RoleKind + "/" + g.RoleName, scopeDisplay(g.Namespace), via})
+		}
+		if len(rows) == 0 {
+			sb.WriteString("  No RBAC bindings apply to this subject.\n")
+		} else {
+			sb.WriteString(util.FormatTable(headers, rows))
+		}
+
+		// 2. Effective permissions grouped by scope
+		byScope := make(map[string][]string)
+		for _, g := range grants {
+			byScope[g.Namespace] = append(byScope[g.Namespace], k8s.FormatRule(g.Rule))
+		}
+		scopes := make([]string, 0, len(byScope))
+		for s := range byScope {
+			scopes = append(scopes, s)
+		}
+		sort.Strings(scopes)
+		if len(scopes) > 0 {
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("Effective Permissions"))
+			sb.WriteString("\n")
+		}
+		for _, s := range scopes {
+			sb.WriteString(fmt.Sprintf("  %s:\n", scopeDisplay(s)))
+			rules := dedupe(byScope[s])
+			sort.Strings(rules)
+			for _, r := range rules {
+				sb.WriteString(fmt.Sprintf("    - %s\n", r))
+			}
+		}
+
+		// 3. Dangerous grants
+		// Cluster-wide dangerous grants are CRITICAL, namespaced ones WARNING.
+		type dangerousGrant struct {
+			message     string
+			clusterWide bool
+		}
+		var dangerous []dangerousGrant
+		seen := make(map[string]bool)
+		for _, g := range grants {
+			for _, reason := range k8s.DangerousGrantReasons(g.Rule) {
+				msg := fmt.Sprintf("%s (%s/%s, %s)", reason, g.RoleKind, g.RoleName, scopeDisplay(g.Namespace))
+				if seen[msg] {
+					continue
+				}
+				seen[msg] = true
+				dangerous = append(dangerous, dangerousGrant{message: msg, clusterWide: g.Namespace == ""})
+			}
+		}
+		if len(dangerous) > 0 {
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("Dangerous Grants"))
+			sb.WriteString("\n")
+			for _, d := range dangerous {
+				severity := "WARNING"
+				if d.clusterWide {
+					severity = "CRITICAL"
+				}
+				sb.WriteString(util.FormatFinding(severity, d.message))
+				sb.WriteString("\n")
+				findings++
+			}
+			actions = append(actions, "Apply least privilege: replace wildcard or sensitive rules with narrowly scoped Roles")
+			if kind == rbacv1.ServiceAccountKind {
+				actions = append(actions, fmt.Sprintf("Check which pods run as %s (automountServiceAccountToken exposes these permissions to the pod)", subjectName))
+			}
+		}
+
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Summary"))
+		sb.WriteString("\n")
+		sb.WriteString(fmt.Sprintf("  %d binding(s), %d rule(s), %d dangerous grant(s).\n", len(rows), len(grants), findings))
+		if len(actions) > 0 {
+			sb.WriteString("\nSUGGESTED ACTIONS:\n")
+			for i, a := range dedupe(actions) {
+				sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, a))
+			}
+		}
+
+		return util.SuccessResult(sb.String()), nil, nil
+	})
+}
+
+// parseResourceArg splits "resource[.group][/subresource]" into its parts.
+func parseResourceArg(arg string) (resource, subresource, group string) {
+	resource = arg
+	if i := strings.Index(resource, "/"); i >= 0 {
+		resource, subresource = resource[:i], resource[i+1:]
+	}
+	if i := strings.Index(resource, "."); i >= 0 {
+		resource, group = resource[:i], resource[i+1:]
+	}
+	return resource, subresource, group
+}
+
+// normalizeAPIGroupArg maps the spellings of the core API group ("core", `""`, "v1") to "".
+func normalizeAPIGroupArg(group string) string {
+	switch strings.TrimSpace(group) {
+	case "core", `""`, "''", "v1":
+		return ""
+	}
+	return group
+}
+
+// resolveAPIGroup finds the API group serving a resource via discovery, preferring the core group.
+func resolveAPIGroup(ctx context.Context, client *k8s.ClusterClient, resource string) string {
+	lists, err := client.GetAPIResources(ctx)
+	if err != nil {
+		return ""
+	}
+	var found []string
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, r := range list.APIResources {
+			if r.Name == resource {
+				if gv.Group == "" {
+					return ""
+				}
+				found = append(found, gv.Group)
+			}
+		}
+	}
+	if len(found) > 0 {
+		return found[0]
+	}
+	return ""
+}
+
+func normalizeSubjectKind(kind string) string {
+	switch strings.ToLower(kind) {
+	case "serviceaccount", "sa":
+		return rbacv1.ServiceAccountKind
+	case "user":
+		return rbacv1.UserKind
+	case "group":
+		return rbacv1.GroupKind
+	}
+	return ""
+}
+
+func subjectString(s rbacv1.Subject) string {
+	if s.Kind == rbacv1.ServiceAccountKind {
+		return s.Namespace + "/" + s.Name
+	}
+	return s.Name
+}
+
+func scopeDisplay(namespace string) string {
+	if namespace == "" {
+		return "cluster-wide"
+	}
+	return "namespace " + namespace
+}
+
+func groupDisplay(group string) string {
+	if group == "" {
+		return "core"
+	}
+	return group
+}
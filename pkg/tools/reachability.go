@@ -0,0 +1,475 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/mermaid"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+type canReachInput struct {
+	SourceNamespace      string `json:"source_namespace" jsonschema:"required,Namespace of the source pod"`
+	SourcePod            string `json:"source_pod,omitempty" jsonschema:"Source pod name"`
+	SourceLabels         string `json:"source_labels,omitempty" jsonschema:"Labels of a hypothetical source pod, e.g. 'app=web,tier=frontend' (used when source_pod is empty)"`
+	DestinationNamespace string `json:"destination_namespace,omitempty" jsonschema:"Namespace of the destination (defaults to source_namespace)"`
+	DestinationPod       string `json:"destination_pod,omitempty" jsonschema:"Destination pod name"`
+	DestinationService   string `json:"destination_service,omitempty" jsonschema:"Destination service name; traffic is evaluated against each backing pod"`
+	DestinationIP        string `json:"destination_ip,omitempty" jsonschema:"External destination IP (only the source's egress policies apply)"`
+	Port                 string `json:"port" jsonschema:"required,Destination port number or name (a service port when destination_service is set)"`
+	Protocol             string `json:"protocol,omitempty" jsonschema:"TCP, UDP or SCTP (default TCP)"`
+}
+
+// reachTarget is one destination endpoint with the port traffic arrives on.
+type reachTarget struct {
+	name     string
+	endpoint k8s.PolicyEndpoint
+	port     intstr.IntOrString
+	egress   k8s.PolicyVerdict
+	ingress  k8s.PolicyVerdict
+}
+
+func registerReachabilityTools(server *mcp.Server, client *k8s.ClusterClient) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "can_reach",
+		Description: "Simulate NetworkPolicy evaluation: can a source pod (or a label set) reach a destination pod, service or IP " +
+			"on a port? Evaluates the source's egress policies and the destination's ingress policies across namespaces, " +
+			"including namespaceSelector, ipBlock/except, named ports, endPort and default-deny semantics. Reports the verdict, " +
+			"the exact policies and rules that decided it, and a Mermaid path diagram.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input canReachInput) (*mcp.CallToolResult, any, error) {
+		dstNS := input.DestinationNamespace
+		if dstNS == "" {
+			dstNS = input.SourceNamespace
+		}
+		protocol := corev1.Protocol(strings.ToUpper(input.Protocol))
+		if protocol == "" {
+			protocol = corev1.ProtocolTCP
+		}
+		if protocol != corev1.ProtocolTCP && protocol != corev1.ProtocolUDP && protocol != corev1.ProtocolSCTP {
+			return util.ErrorResult("unsupported protocol %q (use TCP, UDP or SCTP)", input.Protocol), nil, nil
+		}
+		if input.Port == "" {
+			return util.ErrorResult("port is required"), nil, nil
+		}
+		port := intstr.Parse(input.Port)
+		destinations := 0
+		for _, d := range []string{input.DestinationPod, input.DestinationService, input.DestinationIP} {
+			if d != "" {
+				destinations++
+			}
+		}
+		if destinations != 1 {
+			return util.ErrorResult("specify exactly one of destination_pod, destination_service or destination_ip"), nil, nil
+		}
+
+		// Source
+		src := k8s.PolicyEndpoint{Namespace: input.SourceNamespace}
+		srcName := ""
+		switch {
+		case input.SourcePod != "":
+			pod, err := client.GetPod(ctx, input.SourceNamespace, input.SourcePod)
+			if err != nil {
+				return util.HandleK8sError(fmt.Sprintf("getting pod %s/%s", input.SourceNamespace, input.SourcePod), err), nil, nil
+			}
+			src.PodLabels = podLabelSet(pod)
+			src.IP = pod.Status.PodIP
+			srcName = fmt.Sprintf("Pod %s/%s", pod.Namespace, pod.Name)
+		case input.SourceLabels != "":
+			set, err := labels.ConvertSelectorToLabelsMap(input.SourceLabels)
+			if err != nil {
+				return util.ErrorResult("invalid source_labels %q: %v", input.SourceLabels, err), nil, nil
+			}
+			src.PodLabels = set
+			srcName = fmt.Sprintf("Pods {%s} in %s", input.SourceLabels, input.SourceNamespace)
+		default:
+			return util.ErrorResult("specify source_pod or source_labels"), nil, nil
+		}
+		nsLabels, err := namespaceLabels(ctx, client, input.SourceNamespace)
+		if err != nil {
+			return util.HandleK8sError(fmt.Sprintf("getting namespace %s", input.SourceNamespace), err), nil, nil
+		}
+		src.NamespaceLabels = nsLabels
+
+		// Destination(s)
+		var targets []*reachTarget
+		dstName := ""
+		switch {
+		case input.DestinationIP != "":
+			targets = append(targets, &reachTarget{name: input.DestinationIP, endpoint: k8s.PolicyEndpoint{IP: input.DestinationIP}, port: port})
+			dstName = fmt.Sprintf("IP %s", input.DestinationIP)
+		case input.DestinationPod != "":
+			pod, err := client.GetPod(ctx, dstNS, input.DestinationPod)
+			if err != nil {
+				return util.HandleK8sError(fmt.Sprintf("getting pod %s/%s", dstNS, input.DestinationPod), err), nil, nil
+			}
+			ep, err := podPolicyEndpoint(ctx, client, pod)
+			if err != nil {
+				return util.HandleK8sError(fmt.Sprintf("getting namespace %s", dstNS), err), nil, nil
+			}
+			targets = append(targets, &reachTarget{name: pod.Name, endpoint: ep, port: port})
+			dstName = fmt.Sprintf("Pod %s/%s", pod.Namespace, pod.Name)
+		default:
+			svc, err := client.GetService(ctx, dstNS, input.DestinationService)
+			if err != nil {
+				return util.HandleK8sError(fmt.Sprintf("getting service %s/%s", dstNS, input.DestinationService), err), nil, nil
+			}
+			svcPort := findServicePort(svc, port, protocol)
+			if svcPort == nil {
+				return util.ErrorResult("service %s/%s has no %s port %s", dstNS, svc.Name, protocol, input.Port), nil, nil
+			}
+			pods, err := client.GetPodsForService(ctx, svc)
+			if err != nil {
+				return util.HandleK8sError(fmt.Sprintf("listing pods for service %s", svc.Name), err), nil, nil
+			}
+			targetPort := svcPort.TargetPort
+			if targetPort.Type == intstr.Int && targetPort.IntVal == 0 {
+				targetPort = intstr.FromInt32(svcPort.Port)
+			}
+			for i := range pods {
+				if pods[i].Status.Phase == corev1.PodSucceeded || pods[i].Status.Phase == corev1.PodFailed {
+					continue
+				}
+				ep, err := podPolicyEndpoint(ctx, client, &pods[i])
+				if err != nil {
+					return util.HandleK8sError(fmt.Sprintf("getting namespace %s", dstNS), err), nil, nil
+				}
+				targets = append(targets, &reachTarget{name: pods[i].Name, endpoint: ep, port: targetPort})
+			}
+			dstName = fmt.Sprintf("Service %s/%s port %d (targetPort %s)", svc.Namespace, svc.Name, svcPort.Port, targetPort.String())
+			port = targetPort
+		}
+
+		// Policies on both sides
+		srcPolicies, err := client.ListNetworkPolicies(ctx, input.SourceNamespace, metav1.ListOptions{})
+		if err != nil {
+			return util.HandleK8sError("listing network policies", err), nil, nil
+		}
+		dstPolicies := srcPolicies
+		if dstNS != input.SourceNamespace {
+			dstPolicies, err = client.ListNetworkPolicies(ctx, dstNS, metav1.ListOptions{})
+			if err != nil {
+				return util.HandleK8sError("listing network policies", err), nil, nil
+			}
+		}
+
+		allowed := 0
+		for _, t := range targets {
+			t.egress = k8s.EvaluateEgress(srcPolicies, src, t.endpoint, t.port, protocol)
+			t.ingress = k8s.EvaluateIngress(dstPolicies, src, t.endpoint, t.port, protocol)
+			if t.egress.Allowed && t.ingress.Allowed {
+				allowed++
+			}
+		}
+
+		var sb strings.Builder
+		sb.WriteString(util.FormatHeader(fmt.Sprintf("Reachability: %s → %s", srcName, dstName)))
+		sb.WriteString("\n\n")
+		sb.WriteString(util.FormatKeyValue("SOURCE", srcName))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("SOURCE LABELS", util.FormatLabels(src.PodLabels)))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("DESTINATION", dstName))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("PORT", fmt.Sprintf("%s/%s", input.Port, protocol)))
+		sb.WriteString("\n")
+
+		findings := 0
+		var actions []string
+
+		if len(targets) == 0 {
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatFinding("CRITICAL", "Service has no running backing pods — nothing to reach regardless of policy"))
+			sb.WriteString("\n")
+			sb.WriteString("\nSUGGESTED ACTIONS:\n1. Run analyze_service_connectivity to check the service selector and endpoints\n")
+			return util.SuccessResult(sb.String()), nil, nil
+		}
+
+		verdict := "ALLOWED"
+		switch {
+		case allowed == 0:
+			verdict = "DENIED"
+		case allowed < len(targets):
+			verdict = fmt.Sprintf("PARTIAL (%d of %d pods reachable)", allowed, len(targets))
+		}
+		sb.WriteString(util.FormatKeyValue("VERDICT", verdict))
+		sb.WriteString("\n")
+
+		// Egress side
+		first := targets[0]
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader(fmt.Sprintf("Egress (source namespace: %s)", input.SourceNamespace)))
+		sb.WriteString("\n")
+		writeVerdictDetail(&sb, first.egress, "egress", "source")
+
+		// Ingress side
+		sb.WriteString("\n")
+		if input.DestinationIP != "" {
+			sb.WriteString(util.FormatSubHeader("Ingress"))
+		} else {
+			sb.WriteString(util.FormatSubHeader(fmt.Sprintf("Ingress (destination namespace: %s)", dstNS)))
+		}
+		sb.WriteString("\n")
+		if input.DestinationIP != "" {
+			sb.WriteString("  Destination is outside the cluster — ingress policies do not apply\n")
+		} else if len(targets) == 1 {
+			writeVerdictDetail(&sb, first.ingress, "ingress", "destination")
+		} else {
+			headers := []string{"POD", "IP", "PORT", "EGRESS", "INGRESS", "DECIDED BY"}
+			var rows [][]string
+			for _, t := range targets {
+				rows = append(rows, []string{
+					t.name,
+					valueOrNone(t.endpoint.IP),
+					t.port.String(),
+					verdictWord(t.egress.Allowed),
+					verdictWord(t.ingress.Allowed),
+					decidedBy(t.ingress),
+				})
+			}
+			sb.WriteString(util.FormatTable(headers, rows))
+		}
+
+		// Findings
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Findings"))
+		sb.WriteString("\n")
+		egressDenied, ingressDenied := false, false
+		for _, t := range targets {
+			if !t.egress.Allowed {
+				egressDenied = true
+			}
+			if !t.ingress.Allowed {
+				ingressDenied = true
+			}
+		}
+		if egressDenied {
+			sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("Egress denied: %s is selected by %s and no egress rule allows %s %s/%s",
+				srcName, strings.Join(first.egress.Selecting, ", "), dstName, input.Port, protocol)))
+			sb.WriteString("\n")
+			findings++
+			actions = append(actions, fmt.Sprintf("Add an egress rule to one of [%s] in '%s' allowing the destination on %s/%s",
+				strings.Join(first.egress.Selecting, ", "), input.SourceNamespace, port.String(), protocol))
+		}
+		if ingressDenied {
+			for _, t := range targets {
+				if t.ingress.Allowed {
+					continue
+				}
+				sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("Ingress denied: pod '%s' is selected by %s and no ingress rule admits %s on %s/%s",
+					t.name, strings.Join(t.ingress.Selecting, ", "), srcName, t.port.String(), protocol)))
+				sb.WriteString("\n")
+				findings++
+				actions = append(actions, fmt.Sprintf("Add an ingress rule to one of [%s] in '%s' admitting the source (podSelector/namespaceSelector) on %s/%s",
+					strings.Join(t.ingress.Selecting, ", "), dstNS, t.port.String(), protocol))
+			}
+		}
+		if port.Type == intstr.String {
+			for _, t := range targets {
+				if _, ok := k8s.ResolvePodPort(t.endpoint.Pod, port, protocol); !ok && t.endpoint.IsPod() {
+					sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("Named port '%s' is not declared by pod '%s' — rules using numeric ports cannot match it", port.StrVal, t.name)))
+					sb.WriteString("\n")
+					findings++
+				}
+			}
+		}
+		if input.DestinationService != "" && first.egress.Isolated {
+			dns := k8s.PolicyEndpoint{Namespace: "kube-system", PodLabels: map[string]string{"k8s-app": "kube-dns"}}
+			if dnsLabels, err := namespaceLabels(ctx, client, "kube-system"); err == nil {
+				dns.NamespaceLabels = dnsLabels
+			}
+			if v := k8s.EvaluateEgress(srcPolicies, src, dns, intstr.FromInt32(53), corev1.ProtocolUDP); !v.Allowed {
+				sb.WriteString(util.FormatFinding("WARNING", "Source egress is isolated and UDP/53 to kube-dns is not allowed — DNS names will not resolve"))
+				sb.WriteString("\n")
+				findings++
+				actions = append(actions, "Allow egress to kube-system pods labelled k8s-app=kube-dns on UDP/TCP 53")
+			}
+		}
+		if src.IP == "" && input.SourcePod == "" {
+			sb.WriteString(util.FormatFinding("INFO", "Source is a label set without an IP — ipBlock peers in the destination's ingress rules were not considered"))
+			sb.WriteString("\n")
+		}
+		if findings == 0 {
+			sb.WriteString("  No policy blocks this path.\n")
+		}
+
+		// Diagram
+		sb.WriteString("\nPATH DIAGRAM:\n")
+		sb.WriteString(reachabilityDiagram(srcName, dstName, targets).RenderBlock())
+		sb.WriteString("\n")
+
+		// Summary
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Summary"))
+		sb.WriteString("\n")
+		if findings == 0 {
+			sb.WriteString(fmt.Sprintf("  Traffic is %s. No reachability issues found.\n", strings.ToLower(verdict)))
+		} else {
+			sb.WriteString(fmt.Sprintf("  Traffic is %s. %d finding(s) identified. Review details above.\n", strings.ToLower(verdict), findings))
+		}
+		if len(actions) > 0 {
+			sb.WriteString("\nSUGGESTED ACTIONS:\n")
+			for i, a := range dedupe(actions) {
+				sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, a))
+			}
+		}
+
+		return util.SuccessResult(sb.String()), nil, nil
+	})
+}
+
+// namespaceLabels returns a namespace's labels, including kubernetes.io/metadata.name which the API server sets on every namespace.
+func namespaceLabels(ctx context.Context, client *k8s.ClusterClient, name string) (map[string]string, error) {
+	ns, err := client.GetNamespace(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	set := map[string]string{"kubernetes.io/metadata.name": name}
+	for k, v := range ns.Labels {
+		set[k] = v
+	}
+	return set, nil
+}
+
+// podLabelSet returns the pod's labels as a non-nil map, so the endpoint is treated as a pod.
+func podLabelSet(pod *corev1.Pod) map[string]string {
+	set := make(map[string]string, len(pod.Labels))
+	for k, v := range pod.Labels {
+		set[k] = v
+	}
+	return set
+}
+
+// podPolicyEndpoint builds a NetworkPolicy endpoint for a pod, including its namespace labels.
+func podPolicyEndpoint(ctx context.Context, client *k8s.ClusterClient, pod *corev1.Pod) (k8s.PolicyEndpoint, error) {
+	nsLabels, err := namespaceLabels(ctx, client, pod.Namespace)
+	if err != nil {
+		return k8s.PolicyEndpoint{}, err
+	}
+	return k8s.PolicyEndpoint{
+		Namespace:       pod.Namespace,
+		NamespaceLabels: nsLabels,
+		PodLabels:       podLabelSet(pod),
+		IP:              pod.Status.PodIP,
+		Pod:             pod,
+	}, nil
+}
+
+// findServicePort returns the service port matching a number or name and protocol.
+func findServicePort(svc *corev1.Service, port intstr.IntOrString, protocol corev1.Protocol) *corev1.ServicePort {
+	for i := range svc.Spec.Ports {
+		sp := &svc.Spec.Ports[i]
+		proto := sp.Protocol
+		if proto == "" {
+			proto = corev1.ProtocolTCP
+		}
+		if proto != protocol {
+			continue
+		}
+		if (port.Type == intstr.Int && sp.Port == port.IntVal) || (port.Type == intstr.String && sp.Name == port.StrVal) {
+			return sp
+		}
+	}
+	return nil
+}
+
+// writeVerdictDetail prints which policies selected an endpoint and which rules allowed the traffic.
+func writeVerdictDetail(sb *strings.Builder, v k8s.PolicyVerdict, direction, side string) {
+	if !v.Isolated {
+		sb.WriteString(fmt.Sprintf("  No policy selects the %s for %s — not isolated, allowed by default\n", side, direction))
+		return
+	}
+	sb.WriteString(fmt.Sprintf("  Selected by: %s (default deny for %s)\n", strings.Join(v.Selecting, ", "), direction))
+	if v.Allowed {
+		sb.WriteString(fmt.Sprintf("  ALLOWED by: %s\n", strings.Join(v.Allowing, ", ")))
+	} else {
+		sb.WriteString("  DENIED: no rule in the selecting policies matches this peer and port\n")
+	}
+}
+
+func verdictWord(allowed bool) string {
+	if allowed {
+		return "allowed"
+	}
+	return "DENIED"
+}
+
+// decidedBy names the rules that allowed traffic, the policies that isolated it, or the default.
+func decidedBy(v k8s.PolicyVerdict) string {
+	switch {
+	case !v.Isolated:
+		return "default allow"
+	case v.Allowed:
+		return strings.Join(v.Allowing, ", ")
+	default:
+		return "default deny: " + strings.Join(v.Selecting, ", ")
+	}
+}
+
+// reachabilityDiagram draws source → egress gate → ingress gate → destination pods.
+func reachabilityDiagram(srcName, dstName string, targets []*reachTarget) *mermaid.Flowchart {
+	fc := mermaid.NewFlowchart(mermaid.DirectionLR)
+	fc.AddNode("SRC", diagramLabel(srcName), mermaid.ShapeStadium)
+
+	egress := targets[0].egress
+	egressLabel := "Egress: default allow"
+	if egress.Isolated {
+		egressLabel = "Egress: " + strings.Join(egress.Selecting, ", ")
+	}
+	fc.AddNode("EGRESS", diagramLabel(egressLabel), mermaid.ShapeDiamond)
+	fc.AddEdge("SRC", "EGRESS", "", mermaid.EdgeSolid)
+	edge := func(allowed bool) (string, mermaid.EdgeStyle) {
+		if allowed {
+			return "allowed", mermaid.EdgeSolid
+		}
+		return "denied", mermaid.EdgeDotted
+	}
+
+	if !targets[0].endpoint.IsPod() {
+		fc.AddNode("DST", diagramLabel(dstName), mermaid.ShapeStadium)
+		label, style := edge(egress.Allowed)
+		fc.AddEdge("EGRESS", "DST", label, style)
+		if egress.Allowed {
+			fc.AddStyle("DST", mermaid.SeverityHealthy)
+		} else {
+			fc.AddStyle("DST", mermaid.SeverityCritical)
+		}
+		return fc
+	}
+
+	for i, t := range targets {
+		ingressID := fmt.Sprintf("INGRESS%d", i)
+		dstID := fmt.Sprintf("DST%d", i)
+		fc.AddNode(ingressID, diagramLabel("Ingress: "+decidedBy(t.ingress)), mermaid.ShapeDiamond)
+		fc.AddNode(dstID, fmt.Sprintf("%s:%s", t.name, t.port.String()), mermaid.ShapeRect)
+		label, style := edge(t.egress.Allowed)
+		fc.AddEdge("EGRESS", ingressID, label, style)
+		if t.ingress.Allowed {
+			fc.AddEdge(ingressID, dstID, "allowed", mermaid.EdgeSolid)
+			fc.AddStyle(ingressID, mermaid.SeverityHealthy)
+		} else {
+			fc.AddEdge(ingressID, dstID, "denied", mermaid.EdgeDotted)
+			fc.AddStyle(ingressID, mermaid.SeverityCritical)
+		}
+		if t.egress.Allowed && t.ingress.Allowed {
+			fc.AddStyle(dstID, mermaid.SeverityHealthy)
+		} else {
+			fc.AddStyle(dstID, mermaid.SeverityCritical)
+		}
+	}
+	return fc
+}
+
+// diagramLabelReplacer drops brackets that Mermaid would parse as node shape delimiters.
+var diagramLabelReplacer = strings.NewReplacer("(", "", ")", "", "{", "", "}", "", "[", "", "]", "")
+
+func diagramLabel(s string) string {
+	return diagramLabelReplacer.Replace(s)
+}
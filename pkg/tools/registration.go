@@ -14,15 +14,32 @@ func RegisterAll(server *mcp.Server, client *k8s.ClusterClient, fluxClient *flux
 	registerEventTools(server, client)
 	registerWorkloadTools(server, client)
 	registerNodeTools(server, client)
+	registerNodeDiagnosticTools(server, client)
 	registerNetworkingTools(server, client)
 	registerStorageTools(server, client)
+	registerStorageAnalysisTools(server, client)
+	registerBackupTools(server, client)
 	registerMetricsTools(server, client)
 	registerDiagnosticTools(server, client)
 	registerPolicyTools(server, client)
+	registerReachabilityTools(server, client)
+	registerNetworkPolicyMatrixTools(server, client)
 	registerSecurityTools(server, client)
+	registerSecurityReportTools(server, client)
+	registerRBACTools(server, client)
+	registerPodSecurityStandardsTools(server, client)
+	registerSecretAuditTools(server, client)
+	registerImageTools(server, client)
+	registerImagePullTools(server, client)
+	registerCertificateTools(server, client)
 	registerResourceTools(server, client)
 	registerDiscoveryTools(server, client)
+	registerGenericResourceTools(server, client)
 	registerNetworkAnalysisTools(server, client)
+	registerServiceExposureTools(server, client)
+	registerProbeTools(server, client)
+	registerGatewayAPITools(server, client)
+	registerIngressControllerTools(server, client)
 	registerResourceAnalysisTools(server, client)
 	registerCompositeDiagnosticTools(server, client)
 	if fluxClient != nil {
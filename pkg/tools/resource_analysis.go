@@ -33,7 +33,11 @@ type analyzeNetworkPoliciesInput struct {
 	Namespace string `json:"namespace" jsonschema:"required,Kubernetes namespace to analyze network policies in"`
 }
 
-type checkDNSHealthInput struct{}
+type checkDNSHealthInput struct {
+	Namespace   string `json:"namespace,omitempty" jsonschema:"Namespace whose pods' dnsPolicy/dnsConfig are checked and whose search path is used for resolve_name (default: all namespaces)"`
+	ResolveName string `json:"resolve_name,omitempty" jsonschema:"Optional name to resolve against cluster DNS the way a pod would (e.g. my-svc or api.example.com)"`
+	DNSServer   string `json:"dns_server,omitempty" jsonschema:"DNS server (host or host:port) for resolve_name; defaults to the kube-dns ClusterIP"`
+}
 
 func registerResourceAnalysisTools(server *mcp.Server, client *k8s.ClusterClient) {
 	// -------------------------------------------------------------------------
@@ -1321,7 +1325,9 @@ func registerResourceAnalysisTools(server *mcp.Server, client *k8s.ClusterClient
 		Name: "check_dns_health",
 		Description: "Check CoreDNS health in the cluster. Finds CoreDNS pods in kube-system, checks phase, " +
 			"restart counts, readiness conditions. Retrieves CoreDNS logs and scans for SERVFAIL, NXDOMAIN, " +
-			"and ERROR patterns. Returns a health report with findings.",
+			"and ERROR patterns. Parses the Corefile (forwarders, stub domains, rewrites, cache), checks kube-dns Service endpoints, " +
+			"NodeLocal DNSCache, and pod dnsPolicy/dnsConfig/ndots settings that cause search-path amplification. " +
+			"Optionally resolves a name against the cluster DNS IP (or dns_server) and shows every query the search path produces.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input checkDNSHealthInput) (*mcp.CallToolResult, any, error) {
 		var sb strings.Builder
 		sb.WriteString(util.FormatHeader("DNS Health Check"))
@@ -1370,6 +1376,7 @@ func registerResourceAnalysisTools(server *mcp.Server, client *k8s.ClusterClient
 		if svcErr != nil {
 			sb.WriteString(util.FormatFinding("WARNING", "CoreDNS service 'kube-dns' not found in kube-system"))
 			sb.WriteString("\n\n")
+			coreDNSSvc = nil
 		} else {
 			sb.WriteString(util.FormatKeyValue("Service", fmt.Sprintf("%s (ClusterIP: %s)", coreDNSSvc.Name, coreDNSSvc.Spec.ClusterIP)))
 			sb.WriteString("\n")
@@ -1549,6 +1556,29 @@ func registerResourceAnalysisTools(server *mcp.Server, client *k8s.ClusterClient
 			}
 		}
 
+		// Service endpoints, Corefile, NodeLocal DNSCache, pod settings and resolution
+		dns := &dnsSection{sb: &sb}
+		sb.WriteString("\n")
+		writeDNSServiceEndpoints(ctx, client, dns, coreDNSSvc)
+		sb.WriteString("\n")
+		clusterDomain := k8s.DefaultClusterDomain
+		if cf := writeCorefileSection(ctx, client, dns); cf != nil {
+			clusterDomain = cf.ClusterDomain()
+		}
+		sb.WriteString("\n")
+		writeNodeLocalDNSSection(ctx, client, dns)
+		sb.WriteString("\n")
+		writePodDNSSection(ctx, client, dns, input.Namespace, clusterDomain)
+		if input.ResolveName != "" {
+			server := input.DNSServer
+			if server == "" && coreDNSSvc != nil && coreDNSSvc.Spec.ClusterIP != corev1.ClusterIPNone {
+				server = coreDNSSvc.Spec.ClusterIP
+			}
+			sb.WriteString("\n")
+			writeDNSResolutionSection(ctx, dns, server, input.ResolveName, input.Namespace, clusterDomain)
+		}
+		findingsCount += dns.findings
+
 		// Overall assessment
 		sb.WriteString("\n")
 		sb.WriteString(util.FormatSubHeader("Overall DNS Assessment"))
@@ -1588,6 +1618,10 @@ func registerResourceAnalysisTools(server *mcp.Server, client *k8s.ClusterClient
 				break
 			}
 		}
+		for _, a := range dedupe(dns.actions) {
+			sb.WriteString(fmt.Sprintf("%d. %s\n", actionNum, a))
+			actionNum++
+		}
 		if actionNum == 1 {
 			sb.WriteString("  No specific actions needed — DNS appears healthy.\n")
 		}
@@ -3,6 +3,7 @@ package tools
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -254,57 +255,12 @@ func registerResourceTools(server *mcp.Server, client *k8s.ClusterClient) {
 		sb.WriteString("\n\n")
 
 		// Extract dependencies
-		configMaps := make(map[string]bool)
-		secrets := make(map[string]bool)
-		pvcs := make(map[string]bool)
+		refs := podSpecReferences(podSpec)
+		configMaps := refs.configMaps
+		secrets := refs.secrets
+		pvcs := refs.pvcs
 		serviceAccountName := podSpec.ServiceAccountName
 
-		// From volumes
-		for _, vol := range podSpec.Volumes {
-			if vol.ConfigMap != nil {
-				configMaps[vol.ConfigMap.Name] = true
-			}
-			if vol.Secret != nil {
-				secrets[vol.Secret.SecretName] = true
-			}
-			if vol.PersistentVolumeClaim != nil {
-				pvcs[vol.PersistentVolumeClaim.ClaimName] = true
-			}
-			if vol.Projected != nil {
-				for _, src := range vol.Projected.Sources {
-					if src.ConfigMap != nil {
-						configMaps[src.ConfigMap.Name] = true
-					}
-					if src.Secret != nil {
-						secrets[src.Secret.Name] = true
-					}
-				}
-			}
-		}
-
-		// From containers env/envFrom
-		allContainers := append(podSpec.InitContainers, podSpec.Containers...)
-		for _, c := range allContainers {
-			for _, envFrom := range c.EnvFrom {
-				if envFrom.ConfigMapRef != nil {
-					configMaps[envFrom.ConfigMapRef.Name] = true
-				}
-				if envFrom.SecretRef != nil {
-					secrets[envFrom.SecretRef.Name] = true
-				}
-			}
-			for _, env := range c.Env {
-				if env.ValueFrom != nil {
-					if env.ValueFrom.ConfigMapKeyRef != nil {
-						configMaps[env.ValueFrom.ConfigMapKeyRef.Name] = true
-					}
-					if env.ValueFrom.SecretKeyRef != nil {
-						secrets[env.ValueFrom.SecretKeyRef.Name] = true
-					}
-				}
-			}
-		}
-
 		// List dependencies
 		if serviceAccountName != "" {
 			sb.WriteString(util.FormatKeyValue("ServiceAccount", serviceAccountName))
@@ -434,3 +390,181 @@ func quantityStr(list corev1.ResourceList, name corev1.ResourceName) string {
 	}
 	return q.String()
 }
+
+// podReferences holds the names of objects a pod spec references.
+type podReferences struct {
+	configMaps map[string]bool
+	// secrets holds every Secret referenced from volumes, env or envFrom.
+	secrets       map[string]bool
+	envSecrets    map[string]bool
+	volumeSecrets map[string]bool
+	// requiredSecrets are referenced without optional=true; the pod cannot start if they are missing.
+	requiredSecrets  map[string]bool
+	imagePullSecrets map[string]bool
+	pvcs             map[string]bool
+}
+
+// podSpecReferences walks volumes, projected sources, env, envFrom and imagePullSecrets of a pod spec.
+func podSpecReferences(spec *corev1.PodSpec) podReferences {
+	refs := podReferences{
+		configMaps:       make(map[string]bool),
+		secrets:          make(map[string]bool),
+		envSecrets:       make(map[string]bool),
+		volumeSecrets:    make(map[string]bool),
+		requiredSecrets:  make(map[string]bool),
+		imagePullSecrets: make(map[string]bool),
+		pvcs:             make(map[string]bool),
+	}
+	addSecret := func(name string, viaEnv bool, optional *bool) {
+		refs.secrets[name] = true
+		if viaEnv {
+			refs.envSecrets[name] = true
+		} else {
+			refs.volumeSecrets[name] = true
+		}
+		if optional == nil || !*optional {
+			refs.requiredSecrets[name] = true
+		}
+	}
+
+	// From volumes
+	for _, vol := range spec.Volumes {
+		if vol.ConfigMap != nil {
+			refs.configMaps[vol.ConfigMap.Name] = true
+		}
+		if vol.Secret != nil {
+			addSecret(vol.Secret.SecretName, false, vol.Secret.Optional)
+		}
+		if vol.PersistentVolumeClaim != nil {
+			refs.pvcs[vol.PersistentVolumeClaim.ClaimName] = true
+		}
+		if vol.Projected != nil {
+			for _, src := range vol.Projected.Sources {
+				if src.ConfigMap != nil {
+					refs.configMaps[src.ConfigMap.Name] = true
+				}
+				if src.Secret != nil {
+					addSecret(src.Secret.Name, false, src.Secret.Optional)
+				}
+			}
+		}
+	}
+
+	// From containers env/envFrom
+	allContainers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, c := range allContainers {
+		for _, envFrom := range c.EnvFrom {
+			if envFrom.ConfigMapRef != nil {
+				refs.configMaps[envFrom.ConfigMapRef.Name] = true
+			}
+			if envFrom.SecretRef != nil {
+				addSecret(envFrom.SecretRef.Name, true, envFrom.SecretRef.Optional)
+			}
+		}
+		for _, env := range c.Env {
+			if env.ValueFrom != nil {
+				if env.ValueFrom.ConfigMapKeyRef != nil {
+					refs.configMaps[env.ValueFrom.ConfigMapKeyRef.Name] = true
+				}
+				if env.ValueFrom.SecretKeyRef != nil {
+					addSecret(env.ValueFrom.SecretKeyRef.Name, true, env.ValueFrom.SecretKeyRef.Optional)
+				}
+			}
+		}
+	}
+
+	for _, ref := range spec.ImagePullSecrets {
+		refs.imagePullSecrets[ref.Name] = true
+	}
+	return refs
+}
+
+// workloadPodSpec is a pod spec together with the workload that owns it.
+type workloadPodSpec struct {
+	kind        string
+	namespace   string
+	name        string
+	annotations map[string]string
+	spec        *corev1.PodSpec
+}
+
+// templateControllerKinds are owners whose pod templates are collected directly, so their pods are skipped.
+var templateControllerKinds = map[string]bool{"ReplicaSet": true, "StatefulSet": true, "DaemonSet": true, "Job": true}
+
+// collectWorkloadPodSpecs returns the pod templates of Deployments, StatefulSets, DaemonSets, CronJobs and Jobs,
+// plus standalone pods, so each pod spec in a namespace is seen once.
+func collectWorkloadPodSpecs(ctx context.Context, client *k8s.ClusterClient, namespace string) ([]workloadPodSpec, error) {
+	var workloads []workloadPodSpec
+	add := func(kind string, meta metav1.ObjectMeta, annotations map[string]string, spec *corev1.PodSpec) {
+		workloads = append(workloads, workloadPodSpec{kind: kind, namespace: meta.Namespace, name: meta.Name, annotations: annotations, spec: spec})
+	}
+
+	deployments, err := client.ListDeployments(ctx, namespace, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range deployments {
+		d := &deployments[i]
+		add("Deployment", d.ObjectMeta, d.Spec.Template.Annotations, &d.Spec.Template.Spec)
+	}
+	statefulSets, err := client.ListStatefulSets(ctx, namespace, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range statefulSets {
+		s := &statefulSets[i]
+		add("StatefulSet", s.ObjectMeta, s.Spec.Template.Annotations, &s.Spec.Template.Spec)
+	}
+	daemonSets, err := client.ListDaemonSets(ctx, namespace, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range daemonSets {
+		d := &daemonSets[i]
+		add("DaemonSet", d.ObjectMeta, d.Spec.Template.Annotations, &d.Spec.Template.Spec)
+	}
+	cronJobs, err := client.ListCronJobs(ctx, namespace, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range cronJobs {
+		cj := &cronJobs[i]
+		tmpl := &cj.Spec.JobTemplate.Spec.Template
+		add("CronJob", cj.ObjectMeta, tmpl.Annotations, &tmpl.Spec)
+	}
+	jobs, err := client.ListJobs(ctx, namespace, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range jobs {
+		j := &jobs[i]
+		// Jobs created by a CronJob are covered by the CronJob template
+		if owner := metav1.GetControllerOf(j); owner != nil && owner.Kind == "CronJob" {
+			continue
+		}
+		add("Job", j.ObjectMeta, j.Spec.Template.Annotations, &j.Spec.Template.Spec)
+	}
+	pods, err := client.ListPods(ctx, namespace, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range pods {
+		p := &pods[i]
+		if owner := metav1.GetControllerOf(p); owner != nil && templateControllerKinds[owner.Kind] {
+			continue
+		}
+		add("Pod", p.ObjectMeta, p.Annotations, &p.Spec)
+	}
+
+	sort.SliceStable(workloads, func(i, j int) bool {
+		a, b := workloads[i], workloads[j]
+		if a.namespace != b.namespace {
+			return a.namespace < b.namespace
+		}
+		if a.kind != b.kind {
+			return a.kind < b.kind
+		}
+		return a.name < b.name
+	})
+	return workloads, nil
+}
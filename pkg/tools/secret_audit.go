@@ -0,0 +1,391 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+type auditSecretsInput struct {
+	Namespace string `json:"namespace,omitempty" jsonschema:"Kubernetes namespace (empty for all namespaces)"`
+}
+
+// controllerManagedSecretTypes are consumed by controllers rather than pods, so they are never reported as unused.
+var controllerManagedSecretTypes = map[corev1.SecretType]bool{
+	corev1.SecretTypeServiceAccountToken: true,
+	corev1.SecretTypeBootstrapToken:      true,
+	"helm.sh/release.v1":                 true,
+}
+
+// secretUsage records how a Secret is consumed.
+type secretUsage struct {
+	consumers []string
+	viaEnv    bool
+	viaVolume bool
+	viaPull   bool
+	viaSA     bool
+	ingresses []string
+}
+
+func (u *secretUsage) addConsumer(name string) {
+	if !containsString(u.consumers, name) {
+		u.consumers = append(u.consumers, name)
+	}
+}
+
+func (u *secretUsage) via() string {
+	var parts []string
+	if u.viaEnv {
+		parts = append(parts, "env")
+	}
+	if u.viaVolume {
+		parts = append(parts, "volume")
+	}
+	if u.viaPull {
+		parts = append(parts, "imagePull")
+	}
+	if u.viaSA {
+		parts = append(parts, "serviceAccount")
+	}
+	if len(u.ingresses) > 0 {
+		parts = append(parts, "ingressTLS")
+	}
+	return strings.Join(parts, ",")
+}
+
+func registerSecretAuditTools(server *mcp.Server, client *k8s.ClusterClient) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "audit_secrets",
+		Description: "Audit how Secrets are consumed: secrets exposed via environment variables (more leak-prone than volumes), " +
+			"env literals that look like credentials (known token prefixes, JWTs, high entropy; values are redacted), " +
+			"ServiceAccount tokens automounted for accounts with no RBAC bindings, legacy long-lived ServiceAccount token " +
+			"secrets, Secrets no pod, ServiceAccount or Ingress references, and references to Secrets that do not exist.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input auditSecretsInput) (*mcp.CallToolResult, any, error) {
+		ns := util.NamespaceOrAll(input.Namespace)
+
+		secrets, err := client.ListSecrets(ctx, ns, metav1.ListOptions{})
+		if err != nil {
+			return util.HandleK8sError("listing secrets", err), nil, nil
+		}
+		workloads, err := collectWorkloadPodSpecs(ctx, client, ns)
+		if err != nil {
+			return util.HandleK8sError("listing workloads", err), nil, nil
+		}
+		serviceAccounts, err := client.ListServiceAccounts(ctx, ns, metav1.ListOptions{})
+		if err != nil {
+			return util.HandleK8sError("listing service accounts", err), nil, nil
+		}
+		ingresses, err := client.ListIngresses(ctx, ns, metav1.ListOptions{})
+		if err != nil {
+			return util.HandleK8sError("listing ingresses", err), nil, nil
+		}
+
+		secretByKey := make(map[string]*corev1.Secret, len(secrets))
+		usage := make(map[string]*secretUsage, len(secrets))
+		for i := range secrets {
+			key := secrets[i].Namespace + "/" + secrets[i].Name
+			secretByKey[key] = &secrets[i]
+			usage[key] = &secretUsage{}
+		}
+		saByKey := make(map[string]*corev1.ServiceAccount, len(serviceAccounts))
+		for i := range serviceAccounts {
+			saByKey[serviceAccounts[i].Namespace+"/"+serviceAccounts[i].Name] = &serviceAccounts[i]
+		}
+
+		var sb strings.Builder
+		sb.WriteString(util.FormatHeader(fmt.Sprintf("Secret Audit (scope: %s)", displayNS(input.Namespace))))
+		sb.WriteString("\n\n")
+		findings := 0
+		var actions []string
+		var literals []string
+		var missing [][2]string // severity, message
+
+		// Walk every pod spec once, recording consumption, missing references and env literals
+		for _, w := range workloads {
+			workload := w.kind + "/" + w.name
+			refs := podSpecReferences(w.spec)
+			for name := range refs.secrets {
+				key := w.namespace + "/" + name
+				u, ok := usage[key]
+				if !ok {
+					if refs.requiredSecrets[name] {
+						missing = append(missing, [2]string{"CRITICAL", fmt.Sprintf("%s/%s references missing Secret %s — pods fail with CreateContainerConfigError", w.namespace, workload, key)})
+						actions = append(actions, fmt.Sprintf("Create Secret %s or remove the reference from %s", key, workload))
+					}
+					continue
+				}
+				u.addConsumer(workload)
+				u.viaEnv = u.viaEnv || refs.envSecrets[name]
+				u.viaVolume = u.viaVolume || refs.volumeSecrets[name]
+			}
+			for name := range refs.imagePullSecrets {
+				key := w.namespace + "/" + name
+				u, ok := usage[key]
+				if !ok {
+					missing = append(missing, [2]string{"WARNING", fmt.Sprintf("%s/%s lists missing imagePullSecret %s — private image pulls will fail", w.namespace, workload, key)})
+					actions = append(actions, fmt.Sprintf("Create Secret %s or remove it from imagePullSecrets of %s", key, workload))
+					continue
+				}
+				u.addConsumer(workload)
+				u.viaPull = true
+			}
+
+			allContainers := append(append([]corev1.Container{}, w.spec.InitContainers...), w.spec.Containers...)
+			for _, c := range allContainers {
+				for _, env := range c.Env {
+					if reason := util.CredentialReason(env.Name, env.Value); reason != "" {
+						literals = append(literals, fmt.Sprintf("%s/%s container %q: %s=%s (%s)", w.namespace, workload, c.Name, env.Name, util.RedactValue(env.Value), reason))
+						actions = append(actions, fmt.Sprintf("Move %s in %s/%s into a Secret and reference it with secretKeyRef", env.Name, w.namespace, workload))
+					}
+				}
+			}
+		}
+		for _, sa := range serviceAccounts {
+			refs := make([]string, 0, len(sa.Secrets)+len(sa.ImagePullSecrets))
+			for _, ref := range sa.Secrets {
+				refs = append(refs, ref.Name)
+			}
+			for _, ref := range sa.ImagePullSecrets {
+				refs = append(refs, ref.Name)
+			}
+			for _, name := range refs {
+				if u, ok := usage[sa.Namespace+"/"+name]; ok {
+					u.addConsumer("ServiceAccount/" + sa.Name)
+					u.viaSA = true
+				}
+			}
+		}
+		for _, ing := range ingresses {
+			for _, tls := range ing.Spec.TLS {
+				if tls.SecretName == "" {
+					continue
+				}
+				key := ing.Namespace + "/" + tls.SecretName
+				u, ok := usage[key]
+				if !ok {
+					missing = append(missing, [2]string{"WARNING", fmt.Sprintf("%s/Ingress/%s references missing TLS Secret %s — the controller serves a default certificate", ing.Namespace, ing.Name, key)})
+					actions = append(actions, fmt.Sprintf("Create TLS Secret %s for Ingress %s/%s", key, ing.Namespace, ing.Name))
+					continue
+				}
+				u.ingresses = append(u.ingresses, ing.Name)
+				u.addConsumer("Ingress/" + ing.Name)
+			}
+		}
+
+		keys := make([]string, 0, len(secretByKey))
+		for key := range secretByKey {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		// 1. Consumption
+		sb.WriteString(util.FormatSubHeader("Secret Consumption"))
+		sb.WriteString("\n")
+		headers := []string{"SECRET", "TYPE", "VIA", "CONSUMERS"}
+		var rows [][]string
+		var envExposed []string
+		for _, key := range keys {
+			u := usage[key]
+			if len(u.consumers) == 0 {
+				continue
+			}
+			rows = append(rows, []string{key, string(secretByKey[key].Type), u.via(), strings.Join(truncateList(u.consumers, 3), ", ")})
+			if u.viaEnv {
+				envExposed = append(envExposed, key)
+			}
+		}
+		if len(rows) == 0 {
+			sb.WriteString("  No Secrets are referenced by workloads, ServiceAccounts or Ingresses.\n")
+		} else {
+			sb.WriteString(util.FormatTable(headers, rows))
+			sb.WriteString(fmt.Sprintf("\n%s\n", util.FormatCount("referenced secrets", len(rows))))
+		}
+		for _, key := range envExposed {
+			sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("Secret %s is exposed via environment variables (visible in process listings, crash dumps and child processes)", key)))
+			sb.WriteString("\n")
+			findings++
+			actions = append(actions, fmt.Sprintf("Mount Secret %s as a volume instead of injecting it via env/envFrom", key))
+		}
+
+		// 2. Missing references
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Missing Secrets"))
+		sb.WriteString("\n")
+		sort.Slice(missing, func(i, j int) bool { return missing[i][1] < missing[j][1] })
+		if len(missing) == 0 {
+			sb.WriteString(util.FormatFinding("OK", "All referenced Secrets exist"))
+			sb.WriteString("\n")
+		}
+		for _, m := range missing {
+			sb.WriteString(util.FormatFinding(m[0], m[1]))
+			sb.WriteString("\n")
+			findings++
+		}
+
+		// 3. Credential-like env literals
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Credential-like Env Literals"))
+		sb.WriteString("\n")
+		if len(literals) == 0 {
+			sb.WriteString(util.FormatFinding("OK", "No credential-like literal env values found"))
+			sb.WriteString("\n")
+		}
+		for _, l := range literals {
+			sb.WriteString(util.FormatFinding("CRITICAL", l))
+			sb.WriteString("\n")
+			findings++
+		}
+
+		// 4. ServiceAccount token automount
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("ServiceAccount Token Automount"))
+		sb.WriteString("\n")
+		snapshot, err := client.GetRBACSnapshot(ctx)
+		if err != nil {
+			sb.WriteString(util.FormatFinding("INFO", fmt.Sprintf("Unable to read RBAC bindings, skipping automount check: %v", err)))
+			sb.WriteString("\n")
+		} else {
+			bound := boundServiceAccounts(snapshot)
+			unneeded := 0
+			for _, w := range workloads {
+				saName := w.spec.ServiceAccountName
+				if saName == "" {
+					saName = "default"
+				}
+				saKey := w.namespace + "/" + saName
+				if !tokenAutomounted(w.spec, saByKey[saKey]) || saBound(bound, w.namespace, saName) {
+					continue
+				}
+				sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("%s/%s/%s mounts a token for ServiceAccount %s, which has no RBAC bindings", w.namespace, w.kind, w.name, saName)))
+				sb.WriteString("\n")
+				findings++
+				unneeded++
+				actions = append(actions, fmt.Sprintf("Set automountServiceAccountToken: false on ServiceAccount %s (or on %s/%s if it does not call the API)", saKey, w.kind, w.name))
+			}
+			if unneeded == 0 {
+				sb.WriteString(util.FormatFinding("OK", "Every automounted token belongs to a ServiceAccount with RBAC bindings"))
+				sb.WriteString("\n")
+			}
+		}
+
+		// 5. Legacy long-lived token secrets
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Legacy ServiceAccount Token Secrets"))
+		sb.WriteString("\n")
+		legacy := 0
+		for _, key := range keys {
+			s := secretByKey[key]
+			if s.Type != corev1.SecretTypeServiceAccountToken {
+				continue
+			}
+			legacy++
+			saName := s.Annotations[corev1.ServiceAccountNameKey]
+			msg := fmt.Sprintf("%s is a long-lived token for ServiceAccount %s (age %s)", key, valueOrNone(saName), util.FormatAge(s.CreationTimestamp.Time))
+			if _, ok := saByKey[s.Namespace+"/"+saName]; !ok && saName != "" {
+				msg += " — the ServiceAccount no longer exists"
+			}
+			sb.WriteString(util.FormatFinding("WARNING", msg))
+			sb.WriteString("\n")
+			findings++
+			actions = append(actions, fmt.Sprintf("Replace legacy token %s with short-lived tokens (projected volumes or 'kubectl create token') and delete it", key))
+		}
+		if legacy == 0 {
+			sb.WriteString(util.FormatFinding("OK", "No legacy ServiceAccount token secrets"))
+			sb.WriteString("\n")
+		}
+
+		// 6. Unused secrets
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Unused Secrets"))
+		sb.WriteString("\n")
+		var unused []string
+		for _, key := range keys {
+			if len(usage[key].consumers) == 0 && !controllerManagedSecretTypes[secretByKey[key].Type] {
+				unused = append(unused, key)
+			}
+		}
+		if len(unused) == 0 {
+			sb.WriteString(util.FormatFinding("OK", "Every Secret is referenced"))
+			sb.WriteString("\n")
+		} else {
+			sb.WriteString(util.FormatFinding("INFO", fmt.Sprintf("%d Secret(s) are not referenced by any pod, ServiceAccount or Ingress:", len(unused))))
+			sb.WriteString("\n")
+			for _, key := range truncateList(unused, 20) {
+				sb.WriteString(fmt.Sprintf("    - %s\n", key))
+			}
+			sb.WriteString("  Note: Secrets read through the API (operators, cert-manager issuers, external tools) also appear here.\n")
+			actions = append(actions, "Review unused Secrets and delete the ones no longer needed")
+		}
+
+		// Summary
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Summary"))
+		sb.WriteString("\n")
+		if findings == 0 {
+			sb.WriteString("  No secret exposure or hygiene issues found.\n")
+		} else {
+			sb.WriteString(fmt.Sprintf("  %d finding(s) identified. Review details above.\n", findings))
+		}
+		if len(actions) > 0 {
+			sb.WriteString("\nSUGGESTED ACTIONS:\n")
+			for i, a := range dedupe(actions) {
+				sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, a))
+			}
+		}
+
+		return util.SuccessResult(sb.String()), nil, nil
+	})
+}
+
+// boundServiceAccounts returns the "namespace/name" of every ServiceAccount that is a subject of a
+// binding. Bindings to the system:serviceaccounts group cover every ServiceAccount and are recorded
+// as "*"; bindings to system:serviceaccounts:<ns> are recorded as "<ns>/*". Group bindings count only
+// when they grant access to resources: every cluster binds system:serviceaccounts to
+// system:service-account-issuer-discovery, which grants nothing but the OIDC discovery URLs.
+// Use saBound to look up.
+func boundServiceAccounts(snapshot *k8s.RBACSnapshot) map[string]bool {
+	bound := make(map[string]bool)
+	for _, g := range snapshot.Grants() {
+		s := g.Subject
+		switch {
+		case s.Kind == rbacv1.ServiceAccountKind:
+			bound[s.Namespace+"/"+s.Name] = true
+		case s.Kind != rbacv1.GroupKind || len(g.Rule.Resources) == 0:
+			// users, and group rules limited to non-resource URLs
+		case s.Name == serviceAccountsGroup:
+			bound["*"] = true
+		case strings.HasPrefix(s.Name, serviceAccountsGroup+":"):
+			bound[strings.TrimPrefix(s.Name, serviceAccountsGroup+":")+"/*"] = true
+		}
+	}
+	return bound
+}
+
+// serviceAccountsGroup is the group every ServiceAccount belongs to; system:serviceaccounts:<ns>
+// holds the ServiceAccounts of one namespace.
+const serviceAccountsGroup = "system:serviceaccounts"
+
+// saBound reports whether a ServiceAccount is bound directly or through its groups.
+func saBound(bound map[string]bool, namespace, name string) bool {
+	return bound[namespace+"/"+name] || bound[namespace+"/*"] || bound["*"]
+}
+
+// tokenAutomounted resolves automountServiceAccountToken: the pod spec wins over the ServiceAccount, default true.
+func tokenAutomounted(spec *corev1.PodSpec, sa *corev1.ServiceAccount) bool {
+	if spec.AutomountServiceAccountToken != nil {
+		return *spec.AutomountServiceAccountToken
+	}
+	if sa != nil && sa.AutomountServiceAccountToken != nil {
+		return *sa.AutomountServiceAccountToken
+	}
+	return true
+}
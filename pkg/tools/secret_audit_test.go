@@ -0,0 +1,61 @@
+package tools
+
+import (
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
+)
+
+func TestBoundServiceAccounts(t *testing.T) {
+	group := func(name string) rbacv1.Subject {
+		return rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: name}
+	}
+	clusterRole := func(name string, rules ...rbacv1.PolicyRule) rbacv1.ClusterRole {
+		return rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: name}, Rules: rules}
+	}
+	clusterBinding := func(name, role string, subjects ...rbacv1.Subject) rbacv1.ClusterRoleBinding {
+		return rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: role},
+			Subjects:   subjects,
+		}
+	}
+	// The default bindings every conformant cluster ships.
+	defaults := &k8s.RBACSnapshot{
+		ClusterRoles: []rbacv1.ClusterRole{
+			clusterRole("system:service-account-issuer-discovery",
+				rbacv1.PolicyRule{Verbs: []string{"get"}, NonResourceURLs: []string{"/.well-known/openid-configuration", "/openid/v1/jwks"}}),
+			clusterRole("view",
+				rbacv1.PolicyRule{Verbs: []string{"get", "list"}, APIGroups: []string{""}, Resources: []string{"pods"}}),
+		},
+		ClusterRoleBindings: []rbacv1.ClusterRoleBinding{
+			clusterBinding("system:service-account-issuer-discovery", "system:service-account-issuer-discovery", group(serviceAccountsGroup)),
+		},
+		RoleBindings: []rbacv1.RoleBinding{{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-view", Namespace: "shop"},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "view"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "api"}},
+		}},
+	}
+
+	bound := boundServiceAccounts(defaults)
+	if saBound(bound, "shop", "worker") {
+		t.Error("the issuer-discovery binding should not count as an RBAC binding; the automount warning would never fire")
+	}
+	if !saBound(bound, "shop", "api") {
+		t.Error("shop/api is bound through a RoleBinding without a subject namespace")
+	}
+
+	defaults.ClusterRoleBindings = append(defaults.ClusterRoleBindings,
+		clusterBinding("shop-sa-view", "view", group(serviceAccountsGroup+":shop")))
+	bound = boundServiceAccounts(defaults)
+	if !saBound(bound, "shop", "worker") {
+		t.Error("a resource grant to system:serviceaccounts:shop binds every ServiceAccount in shop")
+	}
+	if saBound(bound, "billing", "worker") {
+		t.Error("system:serviceaccounts:shop does not cover billing")
+	}
+}
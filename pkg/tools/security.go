@@ -244,7 +244,7 @@ func registerSecurityTools(server *mcp.Server, client *k8s.ClusterClient) {
 	// audit_namespace_security
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "audit_namespace_security",
-		Description: "Comprehensive security audit for a namespace. Checks network policies, pod disruption budgets, pod security contexts, RBAC bindings, and resource quotas. Returns an overall security score and a Mermaid policy coverage diagram.",
+		Description: "Comprehensive security audit for a namespace. Checks network policies, pod disruption budgets, pod security contexts, RBAC bindings, resource quotas, and results from installed scanners and policy engines (trivy-operator, PolicyReports, Gatekeeper). Returns an overall security score and a Mermaid policy coverage diagram.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input auditNamespaceSecurityInput) (*mcp.CallToolResult, any, error) {
 		var sb strings.Builder
 		sb.WriteString(util.FormatHeader(fmt.Sprintf("Namespace Security Audit: %s", input.Namespace)))
@@ -385,6 +385,81 @@ func registerSecurityTools(server *mcp.Server, client *k8s.ClusterClient) {
 			hasQuota = true
 		}
 
+		// 6. Security Reports (trivy-operator, PolicyReports, Gatekeeper)
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Security Reports"))
+		sb.WriteString("\n")
+		reportStatus := "not installed"
+		reportStyle := ":::info"
+		reports, err := client.GetSecurityReports(ctx, input.Namespace)
+		if err != nil {
+			reportStatus = "unknown"
+			sb.WriteString(fmt.Sprintf("  (could not read security reports: %v)\n", err))
+		} else if !reports.AnyProvider() {
+			sb.WriteString("  No scanners or policy engines installed (trivy-operator, Kyverno, Gatekeeper) — not scored\n")
+		} else {
+			totals := reports.Totals()
+			reportIssues := 0
+			sb.WriteString(fmt.Sprintf("  %d workload(s) with reports\n", len(reports.Workloads)))
+			for _, e := range reports.Errors {
+				sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("Could not list %s — its results are missing from this audit", e)))
+				sb.WriteString("\n")
+			}
+			if len(reports.Errors) > 0 {
+				score -= 5
+				findings++
+			}
+			if totals.Vulnerabilities.Critical > 0 {
+				sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("%d critical vulnerabilities in running images", totals.Vulnerabilities.Critical)))
+				sb.WriteString("\n")
+				score -= 15
+				findings++
+				reportIssues++
+			}
+			if totals.Vulnerabilities.High > 0 {
+				sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("%d high vulnerabilities in running images", totals.Vulnerabilities.High)))
+				sb.WriteString("\n")
+				score -= 10
+				findings++
+				reportIssues++
+			}
+			if n := totals.ConfigAudit.Critical + totals.ConfigAudit.High; n > 0 {
+				sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("%d critical/high configuration audit failures", n)))
+				sb.WriteString("\n")
+				score -= 5
+				findings++
+				reportIssues++
+			}
+			if totals.PolicyFail > 0 {
+				sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("%d policy rule failures", totals.PolicyFail)))
+				sb.WriteString("\n")
+				score -= 10
+				findings++
+				reportIssues++
+			}
+			if totals.ConstraintViolations > 0 {
+				sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("%d Gatekeeper constraint violations", totals.ConstraintViolations)))
+				sb.WriteString("\n")
+				score -= 10
+				findings++
+				reportIssues++
+			}
+			reportStatus = "clean"
+			reportStyle = ":::success"
+			if reportIssues > 0 {
+				reportStatus = fmt.Sprintf("%d issues", reportIssues)
+				reportStyle = ":::warning"
+				sb.WriteString("  Run get_security_reports for per-workload details\n")
+			}
+			if len(reports.Errors) > 0 {
+				reportStatus = "partial"
+				if reportIssues > 0 {
+					reportStatus = fmt.Sprintf("%d issues, partial", reportIssues)
+				}
+				reportStyle = ":::warning"
+			}
+		}
+
 		// Clamp score
 		if score < 0 {
 			score = 0
@@ -459,6 +534,7 @@ func registerSecurityTools(server *mcp.Server, client *k8s.ClusterClient) {
 		mermaidLines = append(mermaidLines, fmt.Sprintf("    NS --> SEC[Pod Security: %s]%s", secStatus, secStyle))
 		mermaidLines = append(mermaidLines, fmt.Sprintf("    NS --> RBAC[RBAC: %s]", rbacStatus))
 		mermaidLines = append(mermaidLines, fmt.Sprintf("    NS --> QUOTA[Quotas: %s]", quotaStatus))
+		mermaidLines = append(mermaidLines, fmt.Sprintf("    NS --> REP[Security Reports: %s]%s", reportStatus, reportStyle))
 
 		sb.WriteString(util.FormatMermaidBlock(strings.Join(mermaidLines, "\n")))
 		sb.WriteString("\n")
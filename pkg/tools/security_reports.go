@@ -0,0 +1,197 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+type getSecurityReportsInput struct {
+	Namespace string `json:"namespace,omitempty" jsonschema:"Kubernetes namespace (empty for all namespaces)"`
+}
+
+// securityReportProviders is the display order of report providers.
+var securityReportProviders = []string{
+	k8s.ProviderTrivyVulnerabilities,
+	k8s.ProviderTrivyConfigAudit,
+	k8s.ProviderPolicyReports,
+	k8s.ProviderGatekeeper,
+}
+
+func registerSecurityReportTools(server *mcp.Server, client *k8s.ClusterClient) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "get_security_reports",
+		Description: "Aggregate results from in-cluster security scanners and policy engines per namespace and workload: " +
+			"trivy-operator VulnerabilityReports and ConfigAuditReports, wgpolicyk8s.io PolicyReports (Kyverno) and " +
+			"Gatekeeper constraint audit violations. Providers are discovered via CRDs; missing providers are reported as not installed.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input getSecurityReportsInput) (*mcp.CallToolResult, any, error) {
+		ns := util.NamespaceOrAll(input.Namespace)
+
+		reports, err := client.GetSecurityReports(ctx, ns)
+		if err != nil {
+			return util.HandleK8sError("reading security reports", err), nil, nil
+		}
+
+		var sb strings.Builder
+		sb.WriteString(util.FormatHeader(fmt.Sprintf("Security Reports (namespace: %s)", displayNS(ns))))
+		sb.WriteString("\n\n")
+
+		findings := 0
+		var actions []string
+
+		// Providers
+		sb.WriteString(util.FormatSubHeader("Report Providers"))
+		sb.WriteString("\n")
+		var providerRows [][]string
+		for _, p := range securityReportProviders {
+			status := "not installed"
+			if reports.Providers[p] {
+				status = "installed"
+			}
+			providerRows = append(providerRows, []string{p, status})
+		}
+		sb.WriteString(util.FormatTable([]string{"PROVIDER", "STATUS"}, providerRows))
+		for _, e := range reports.Errors {
+			sb.WriteString(fmt.Sprintf("  (could not list %s)\n", e))
+		}
+		if !reports.AnyProvider() {
+			sb.WriteString("\n  No security report providers are installed. Install trivy-operator, Kyverno or Gatekeeper\n")
+			sb.WriteString("  to get vulnerability, configuration and policy results here.\n")
+			return util.SuccessResult(sb.String()), nil, nil
+		}
+
+		// Per-workload table
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Workloads"))
+		sb.WriteString("\n")
+		if len(reports.Workloads) == 0 {
+			sb.WriteString("  No reports found for this scope.\n")
+		} else {
+			headers := []string{"NAMESPACE", "WORKLOAD", "VULNS C/H/M/L", "CONFIG C/H", "POLICY FAIL/WARN", "CONSTRAINTS"}
+			var rows [][]string
+			for _, w := range reports.Workloads {
+				rows = append(rows, []string{
+					valueOrNone(w.Namespace),
+					w.Kind + "/" + w.Name,
+					fmt.Sprintf("%d/%d/%d/%d", w.Vulnerabilities.Critical, w.Vulnerabilities.High, w.Vulnerabilities.Medium, w.Vulnerabilities.Low),
+					fmt.Sprintf("%d/%d", w.ConfigAudit.Critical, w.ConfigAudit.High),
+					fmt.Sprintf("%d/%d", w.PolicyFail, w.PolicyWarn),
+					fmt.Sprintf("%d", w.ConstraintViolations),
+				})
+			}
+			sb.WriteString(util.FormatTable(headers, rows))
+		}
+
+		// Findings
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Findings"))
+		sb.WriteString("\n")
+		ruleCounts := make(map[string]int)
+		for _, w := range reports.Workloads {
+			ref := fmt.Sprintf("%s '%s'", w.Kind, w.Name)
+			if w.Namespace != "" {
+				ref += fmt.Sprintf(" in '%s'", w.Namespace)
+			}
+			if w.Vulnerabilities.Critical > 0 {
+				sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("%s has %d critical and %d high vulnerabilities",
+					ref, w.Vulnerabilities.Critical, w.Vulnerabilities.High)))
+				sb.WriteString("\n")
+				findings++
+				actions = append(actions, fmt.Sprintf("Rebuild or upgrade the images of %s to patch critical vulnerabilities", ref))
+			} else if w.Vulnerabilities.High > 0 {
+				sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("%s has %d high vulnerabilities", ref, w.Vulnerabilities.High)))
+				sb.WriteString("\n")
+				findings++
+			}
+			if n := w.ConfigAudit.Critical + w.ConfigAudit.High; n > 0 {
+				sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("%s fails %d critical/high configuration check(s)", ref, n)))
+				sb.WriteString("\n")
+				findings++
+			}
+			if w.PolicyFail > 0 {
+				sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("%s fails %d policy rule(s)", ref, w.PolicyFail)))
+				sb.WriteString("\n")
+				findings++
+			}
+			if w.ConstraintViolations > 0 {
+				sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("%s violates %d Gatekeeper constraint(s)", ref, w.ConstraintViolations)))
+				sb.WriteString("\n")
+				findings++
+			}
+			for _, r := range w.Rules {
+				ruleCounts[r]++
+			}
+		}
+		if findings == 0 {
+			sb.WriteString("  No critical or high severity results.\n")
+		}
+
+		// Most common failing rules
+		if len(ruleCounts) > 0 {
+			type ruleCount struct {
+				name  string
+				count int
+			}
+			var rules []ruleCount
+			for name, count := range ruleCounts {
+				rules = append(rules, ruleCount{name, count})
+			}
+			sort.Slice(rules, func(i, j int) bool {
+				if rules[i].count != rules[j].count {
+					return rules[i].count > rules[j].count
+				}
+				return rules[i].name < rules[j].name
+			})
+			if len(rules) > 10 {
+				rules = rules[:10]
+			}
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("Top Failing Rules"))
+			sb.WriteString("\n")
+			var rows [][]string
+			for _, r := range rules {
+				rows = append(rows, []string{r.name, fmt.Sprintf("%d", r.count)})
+			}
+			sb.WriteString(util.FormatTable([]string{"RULE / CHECK / CONSTRAINT", "WORKLOADS"}, rows))
+		}
+
+		totals := reports.Totals()
+		if totals.PolicyFail > 0 {
+			actions = append(actions, "Fix the failing policy rules listed above, or add policy exceptions where the workload is intentionally non-compliant")
+		}
+		if totals.ConstraintViolations > 0 {
+			actions = append(actions, "Resolve Gatekeeper violations before switching the constraints' enforcementAction to deny")
+		}
+		if totals.ConfigAudit.Critical+totals.ConfigAudit.High > 0 {
+			actions = append(actions, "Run check_pod_security_standards or analyze_pod_security to fix failing configuration checks")
+		}
+
+		// Summary
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Summary"))
+		sb.WriteString("\n")
+		sb.WriteString(fmt.Sprintf("  Vulnerabilities: %d critical, %d high, %d medium, %d low\n",
+			totals.Vulnerabilities.Critical, totals.Vulnerabilities.High, totals.Vulnerabilities.Medium, totals.Vulnerabilities.Low))
+		sb.WriteString(fmt.Sprintf("  Policy results: %d fail, %d warn; Gatekeeper violations: %d\n",
+			totals.PolicyFail, totals.PolicyWarn, totals.ConstraintViolations))
+		if findings == 0 {
+			sb.WriteString("  No security report issues found.\n")
+		} else {
+			sb.WriteString(fmt.Sprintf("  %d finding(s) identified. Review details above.\n", findings))
+		}
+		if len(actions) > 0 {
+			sb.WriteString("\nSUGGESTED ACTIONS:\n")
+			for i, a := range dedupe(actions) {
+				sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, a))
+			}
+		}
+
+		return util.SuccessResult(sb.String()), nil, nil
+	})
+}
@@ -0,0 +1,177 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/health"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+type diagnoseServiceExposureInput struct {
+	Namespace   string `json:"namespace" jsonschema:"required,Kubernetes namespace"`
+	ServiceName string `json:"service_name" jsonschema:"required,LoadBalancer or NodePort service name"`
+}
+
+func registerServiceExposureTools(server *mcp.Server, client *k8s.ClusterClient) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "diagnose_service_exposure",
+		Description: "Explain why a LoadBalancer or NodePort service is or is not reachable from outside the cluster. " +
+			"Checks status.loadBalancer and cloud-controller events (EnsuringLoadBalancer, SyncLoadBalancerFailed), loadBalancerClass, " +
+			"externalTrafficPolicy=Local against the nodes that host ready endpoints (health check node port), NodePort collisions with " +
+			"other services and hostPort pods, loadBalancerSourceRanges, and Azure/AWS/GCP load balancer annotations.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input diagnoseServiceExposureInput) (*mcp.CallToolResult, any, error) {
+		svc, err := client.GetService(ctx, input.Namespace, input.ServiceName)
+		if err != nil {
+			return util.HandleK8sError(fmt.Sprintf("getting service %s/%s", input.Namespace, input.ServiceName), err), nil, nil
+		}
+		if svc.Spec.Type != corev1.ServiceTypeLoadBalancer && svc.Spec.Type != corev1.ServiceTypeNodePort {
+			return util.ErrorResult("service %s/%s is type %s; diagnose_service_exposure only applies to LoadBalancer and NodePort services (use diagnose_service)",
+				svc.Namespace, svc.Name, svc.Spec.Type), nil, nil
+		}
+		exposure, err := client.AnalyzeServiceExposure(ctx, svc)
+		if err != nil {
+			return util.HandleK8sError("analyzing service exposure", err), nil, nil
+		}
+
+		var sb strings.Builder
+		sb.WriteString(util.FormatHeader(fmt.Sprintf("Service Exposure: %s/%s (%s)", svc.Namespace, svc.Name, svc.Spec.Type)))
+		sb.WriteString("\n\n")
+
+		svcHealth := health.Service(svc)
+		sb.WriteString(util.FormatKeyValue("Health", svcHealth.String()))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("External Address", valueOrNone(strings.Join(exposure.ExternalAddresses, ", "))))
+		sb.WriteString("\n")
+		if svc.Spec.Type == corev1.ServiceTypeLoadBalancer {
+			scope := "public"
+			if exposure.Internal {
+				scope = "internal"
+			}
+			sb.WriteString(util.FormatKeyValue("Load Balancer", fmt.Sprintf("%s (provider: %s)", scope, valueOrNone(exposure.Provider))))
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatKeyValue("LoadBalancerClass", valueOrNone(exposure.LoadBalancerClass)))
+			sb.WriteString("\n")
+			ranges := valueOrNone(strings.Join(exposure.SourceRanges, ", "))
+			if exposure.SourceRangesFrom != "" {
+				ranges += " (from " + exposure.SourceRangesFrom + ")"
+			}
+			sb.WriteString(util.FormatKeyValue("Source Ranges", ranges))
+			sb.WriteString("\n")
+		}
+		policy := string(svc.Spec.ExternalTrafficPolicy)
+		if exposure.LocalTrafficPolicy {
+			policy += fmt.Sprintf(" (health check node port %d, endpoints on %d/%d eligible nodes)",
+				exposure.HealthCheckNodePort, len(exposure.EndpointNodes), exposure.EligibleNodes)
+		}
+		sb.WriteString(util.FormatKeyValue("External Traffic Policy", valueOrNone(policy)))
+		sb.WriteString("\n")
+
+		// Node ports
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Node Ports"))
+		sb.WriteString("\n")
+		if len(exposure.NodePorts) == 0 {
+			sb.WriteString("  No node ports allocated.\n")
+		} else {
+			rows := make([][]string, 0, len(exposure.NodePorts))
+			for _, u := range exposure.NodePorts {
+				rows = append(rows, []string{fmt.Sprintf("%d/%s", u.Port, u.Protocol), u.Source, valueOrNone(strings.Join(u.Conflicts, ", "))})
+			}
+			sb.WriteString(util.FormatTable([]string{"NODE-PORT", "USED-FOR", "CONFLICTS"}, rows))
+		}
+
+		// Endpoint nodes for externalTrafficPolicy=Local
+		if exposure.LocalTrafficPolicy && len(exposure.EndpointNodes) > 0 {
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("Nodes Passing the Health Check"))
+			sb.WriteString("\n")
+			for _, n := range exposure.EndpointNodes {
+				sb.WriteString(fmt.Sprintf("  %s\n", n))
+			}
+		}
+
+		// Provider annotations
+		if len(exposure.Annotations) > 0 {
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("Load Balancer Annotations"))
+			sb.WriteString("\n")
+			rows := make([][]string, 0, len(exposure.Annotations))
+			for _, a := range exposure.Annotations {
+				status := "OK"
+				if a.Problem != "" {
+					status = a.Problem
+				}
+				rows = append(rows, []string{a.Provider, a.Key, a.Value, status})
+			}
+			sb.WriteString(util.FormatTable([]string{"PROVIDER", "ANNOTATION", "VALUE", "STATUS"}, rows))
+		}
+
+		// Cloud controller events
+		if svc.Spec.Type == corev1.ServiceTypeLoadBalancer {
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("Load Balancer Events"))
+			sb.WriteString("\n")
+			if len(exposure.Events) == 0 {
+				sb.WriteString("  No load balancer events recorded (events expire after about an hour).\n")
+			} else {
+				rows := make([][]string, 0, len(exposure.Events))
+				for _, e := range exposure.Events {
+					rows = append(rows, []string{e.Type, e.Reason, util.FormatAge(e.LastTimestamp.Time), util.TruncateString(e.Message, 100)})
+				}
+				sb.WriteString(util.FormatTable([]string{"TYPE", "REASON", "AGE", "MESSAGE"}, rows))
+			}
+		}
+
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Findings"))
+		sb.WriteString("\n")
+		findings, actions := writeExposureIssues(&sb, "", exposure.Issues)
+		switch {
+		case len(exposure.Issues) == 0 && !svcHealth.Healthy():
+			sb.WriteString(util.FormatFinding(healthSeverity(svcHealth), "Service is not healthy: "+svcHealth.String()))
+			sb.WriteString("\n")
+			findings++
+		case len(exposure.Issues) == 0:
+			sb.WriteString(util.FormatFinding("OK", "No exposure problems detected"))
+			sb.WriteString("\n")
+		}
+
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Summary"))
+		sb.WriteString("\n")
+		sb.WriteString(fmt.Sprintf("  %d finding(s).\n", findings))
+		if len(actions) > 0 {
+			sb.WriteString("\nSUGGESTED ACTIONS:\n")
+			for i, a := range dedupe(actions) {
+				sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, a))
+			}
+		}
+
+		return util.SuccessResult(sb.String()), nil, nil
+	})
+}
+
+// writeExposureIssues writes exposure issues as findings, each line starting with indent, and
+// returns how many were not informational along with their suggested actions.
+func writeExposureIssues(sb *strings.Builder, indent string, issues []k8s.ExposureIssue) (int, []string) {
+	findings := 0
+	var actions []string
+	for _, issue := range issues {
+		sb.WriteString(indent)
+		sb.WriteString(util.FormatFinding(issue.Severity, issue.Problem))
+		sb.WriteString("\n")
+		if issue.Severity != "INFO" {
+			findings++
+		}
+		if issue.Action != "" {
+			actions = append(actions, issue.Action)
+		}
+	}
+	return findings, actions
+}
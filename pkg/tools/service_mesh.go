@@ -0,0 +1,168 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/mermaid"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+// writeServiceMeshSection writes the mesh view of a service — injection, sidecars, mTLS,
+// routing and authorization — prefixing every line with indent. It returns the number of
+// non-informational findings and the suggested actions.
+func writeServiceMeshSection(sb *strings.Builder, indent string, m *k8s.ServiceMeshAnalysis) (int, []string) {
+	line := func(s string) {
+		sb.WriteString(indent)
+		sb.WriteString(s)
+		sb.WriteString("\n")
+	}
+	line(util.FormatKeyValue("Mesh", m.Mesh))
+	line(util.FormatKeyValue("Namespace Injection", valueOrNone(m.NamespaceInjection)))
+	if m.MTLSMode != "" {
+		line(util.FormatKeyValue("mTLS", fmt.Sprintf("%s (%s)", m.MTLSMode, m.MTLSSource)))
+	}
+
+	if len(m.Pods) > 0 {
+		rows := make([][]string, 0, len(m.Pods))
+		for _, p := range m.Pods {
+			sidecar, ready, restarts := "<none>", "-", "-"
+			if p.Sidecar != nil {
+				sidecar = p.Sidecar.Container
+				if p.Sidecar.Native {
+					sidecar += " (native)"
+				}
+				ready = fmt.Sprintf("%t", p.Sidecar.Ready)
+				restarts = fmt.Sprintf("%d", p.Sidecar.Restarts)
+			}
+			rows = append(rows, []string{p.Pod, valueOrNone(p.Injection), sidecar, ready, restarts})
+		}
+		table := util.FormatTable([]string{"POD", "INJECTION", "SIDECAR", "READY", "RESTARTS"}, rows)
+		sb.WriteString(indent)
+		sb.WriteString(strings.ReplaceAll(strings.TrimSuffix(table, "\n"), "\n", "\n"+indent))
+		sb.WriteString("\n")
+	}
+
+	for _, vs := range m.VirtualServices {
+		dests := make([]string, 0, len(vs.Destinations))
+		for _, d := range vs.Destinations {
+			dest := d.Host
+			if d.Subset != "" {
+				dest += " subset=" + d.Subset
+			}
+			if d.Weight > 0 {
+				dest += fmt.Sprintf(" weight=%d", d.Weight)
+			}
+			dests = append(dests, dest)
+		}
+		line(fmt.Sprintf("VirtualService %s/%s -> %s", vs.Namespace, vs.Name, strings.Join(dests, ", ")))
+	}
+	for _, dr := range m.DestinationRules {
+		subsets := make([]string, 0, len(dr.Subsets))
+		for name := range dr.Subsets {
+			subsets = append(subsets, name)
+		}
+		sort.Strings(subsets)
+		line(fmt.Sprintf("DestinationRule %s/%s host=%s tls=%s subsets=%s", dr.Namespace, dr.Name, dr.Host,
+			valueOrNone(dr.TLSMode), valueOrNone(strings.Join(subsets, ","))))
+	}
+	for _, ap := range m.AuthorizationPolicies {
+		line(fmt.Sprintf("AuthorizationPolicy %s/%s action=%s rules=%d", ap.Namespace, ap.Name, ap.Action, ap.Rules))
+	}
+
+	findings := 0
+	var actions []string
+	for _, issue := range m.Issues {
+		line(util.FormatFinding(issue.Severity, issue.Problem))
+		if issue.Severity != "INFO" {
+			findings++
+		}
+		if issue.Action != "" {
+			actions = append(actions, issue.Action)
+		}
+	}
+	return findings, actions
+}
+
+// checkIngressControllerMesh adds an issue when the backend requires Istio STRICT mTLS but the
+// Ingress's controller runs without a sidecar, so its plain-text requests are rejected.
+func checkIngressControllerMesh(ctx context.Context, client *k8s.ClusterClient, ing *networkingv1.Ingress, m *k8s.ServiceMeshAnalysis) {
+	if m.MTLSMode != "STRICT" {
+		return
+	}
+	classes, err := client.ListIngressClasses(ctx)
+	if err != nil {
+		return
+	}
+	var profile *k8s.IngressControllerProfile
+	for _, ic := range classes {
+		if ic.Name == ingressClassName(ing) {
+			profile = k8s.FindIngressControllerProfile(ic.Spec.Controller)
+		}
+	}
+	if profile == nil {
+		return
+	}
+	if profile.Name == "agic" {
+		// Application Gateway sits outside the cluster network and is never meshed.
+		m.Issues = append(m.Issues, k8s.MeshIssue{Severity: "CRITICAL",
+			Problem: "Backend requires STRICT mTLS but Application Gateway sends plain-text requests — the sidecar resets them (502)",
+			Action:  "Use a PERMISSIVE PeerAuthentication for the backend port, or front the service with an Istio ingress gateway"})
+		return
+	}
+	pods, _, err := findIngressControllerPods(ctx, client, profile)
+	if err != nil || len(pods) == 0 {
+		return
+	}
+	if k8s.PodSidecar(&pods[0]) == nil {
+		m.Issues = append(m.Issues, k8s.MeshIssue{Severity: "CRITICAL",
+			Problem: fmt.Sprintf("Backend requires STRICT mTLS but %s pod '%s/%s' has no sidecar — its plain-text requests are rejected (502/503)",
+				profile.DisplayName, pods[0].Namespace, pods[0].Name),
+			Action: fmt.Sprintf("Inject a sidecar into the %s pods or set the backend PeerAuthentication to PERMISSIVE", profile.DisplayName)})
+	}
+}
+
+// addMeshSequenceHop draws the hop through the sidecar in a request-flow sequence diagram: from
+// sends to the proxy, which forwards to the application container (to). It returns false when the
+// service is not meshed and the caller should draw the direct hop instead.
+func addMeshSequenceHop(seq *mermaid.Sequence, m *k8s.ServiceMeshAnalysis, from, proxy, to string) bool {
+	if !m.Meshed() {
+		return false
+	}
+	msg := "mTLS"
+	if m.MTLSMode != "" && m.MTLSMode != "automatic" {
+		msg = "mTLS " + m.MTLSMode
+	}
+	seq.AddMessage(from, proxy, msg, mermaid.MsgSolid)
+	var notes []string
+	for _, issue := range m.Issues {
+		if issue.Severity == "CRITICAL" {
+			notes = append(notes, util.TruncateString(issue.Problem, 60))
+		}
+	}
+	if m.DeniedByDefault() {
+		notes = append(notes, "AuthZ: 403 unless an ALLOW rule matches")
+	}
+	if len(notes) > 0 {
+		seq.AddNote(proxy, strings.Join(notes, mermaid.BR()), mermaid.NoteRight)
+	}
+	seq.AddMessage(proxy, to, "localhost", mermaid.MsgSolid)
+	return true
+}
+
+// podSidecarSuffix returns a short sidecar marker for pod diagram labels.
+func podSidecarSuffix(pod *corev1.Pod) string {
+	if sc := k8s.PodSidecar(pod); sc != nil {
+		if !sc.Ready {
+			return mermaid.BR() + "+" + sc.Container + " NOT READY"
+		}
+		return mermaid.BR() + "+" + sc.Container
+	}
+	return ""
+}
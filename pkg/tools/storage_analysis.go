@@ -0,0 +1,989 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/health"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
+)
+
+type analyzeEphemeralStorageInput struct {
+	Namespace string `json:"namespace,omitempty" jsonschema:"Namespace to analyze (empty or 'all' for all namespaces)"`
+	Node      string `json:"node,omitempty" jsonschema:"Restrict the analysis to a single node"`
+}
+
+type diagnosePVCInput struct {
+	Namespace string `json:"namespace" jsonschema:"required,Kubernetes namespace of the PVC"`
+	Name      string `json:"name" jsonschema:"required,PersistentVolumeClaim name"`
+}
+
+const (
+	defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+	selectedNodeAnnotation        = "volume.kubernetes.io/selected-node"
+
+	// stuckDetachThreshold matches the attach/detach controller's maximum wait for unmount.
+	stuckDetachThreshold = 6 * time.Minute
+)
+
+// ephemeralPodUsage captures the ephemeral-storage configuration and usage of a single pod.
+type ephemeralPodUsage struct {
+	Pod     *corev1.Pod
+	Request int64
+	Limit   int64
+	Used    int64
+	HasStat bool
+}
+
+func registerStorageAnalysisTools(server *mcp.Server, client *k8s.ClusterClient) {
+	// analyze_ephemeral_storage
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "analyze_ephemeral_storage",
+		Description: "Analyze ephemeral-storage and disk pressure: ephemeral-storage requests/limits, emptyDir sizeLimits, " +
+			"per-pod usage from the kubelet summary API (via the apiserver node proxy), and pods evicted by the kubelet. " +
+			"Ranks the worst ephemeral-storage consumers per node. Use this when nodes report DiskPressure or pods are Evicted.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input analyzeEphemeralStorageInput) (*mcp.CallToolResult, any, error) {
+		ns := util.NamespaceOrAll(input.Namespace)
+		fieldSelector := ""
+		if input.Node != "" {
+			fieldSelector = "spec.nodeName=" + input.Node
+		}
+
+		pods, err := client.ListPods(ctx, ns, util.ListOptions("", fieldSelector))
+		if err != nil {
+			return util.HandleK8sError("listing pods", err), nil, nil
+		}
+
+		var nodes []corev1.Node
+		if input.Node != "" {
+			node, err := client.GetNode(ctx, input.Node)
+			if err != nil {
+				return util.HandleK8sError(fmt.Sprintf("getting node %s", input.Node), err), nil, nil
+			}
+			nodes = []corev1.Node{*node}
+		} else {
+			nodes, err = client.ListNodes(ctx, metav1.ListOptions{})
+			if err != nil {
+				return util.HandleK8sError("listing nodes", err), nil, nil
+			}
+		}
+
+		var sb strings.Builder
+		scope := displayNS(input.Namespace)
+		if input.Node != "" {
+			scope += ", node: " + input.Node
+		}
+		sb.WriteString(util.FormatHeader(fmt.Sprintf("Ephemeral Storage Analysis (namespace: %s)", scope)))
+		sb.WriteString("\n\n")
+		findings := 0
+		var actions []string
+
+		// Only query kubelets for nodes that host pods in scope
+		nodesWithPods := make(map[string]bool)
+		for i := range pods {
+			if pods[i].Spec.NodeName != "" {
+				nodesWithPods[pods[i].Spec.NodeName] = true
+			}
+		}
+
+		// 1. Node disk pressure and kubelet stats
+		sb.WriteString(util.FormatSubHeader("Node Disk Status"))
+		sb.WriteString("\n")
+		podStats := make(map[string]k8s.PodStats)
+		volumeUsage := make(map[string]map[string]uint64) // ns/pod -> volume -> used bytes
+		queried := make([]string, 0, len(nodes))
+		for i := range nodes {
+			if nodesWithPods[nodes[i].Name] || input.Node != "" {
+				queried = append(queried, nodes[i].Name)
+			}
+		}
+		summaries, statsErrs := client.GetNodeStatsSummaries(ctx, queried)
+		nodeHeaders := []string{"NODE", "DISK-PRESSURE", "NODE-FS", "IMAGE-FS", "EPHEMERAL-ALLOCATABLE"}
+		nodeRows := make([][]string, 0, len(queried))
+		for i := range nodes {
+			n := &nodes[i]
+			if !nodesWithPods[n.Name] && input.Node == "" {
+				continue
+			}
+			pressure := "False"
+			for _, cond := range n.Status.Conditions {
+				if cond.Type == corev1.NodeDiskPressure && cond.Status == corev1.ConditionTrue {
+					pressure = "True"
+					sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("Node '%s' has DiskPressure: %s", n.Name, cond.Message)))
+					sb.WriteString("\n")
+					findings++
+					actions = append(actions, fmt.Sprintf("Use diagnose_node on '%s' to investigate disk pressure and image GC", n.Name))
+				}
+			}
+			nodeFs, imageFs := "?", "?"
+			if summary, ok := summaries[n.Name]; ok {
+				if summary.Node.Fs != nil {
+					nodeFs = fmt.Sprintf("%.1f%%", summary.Node.Fs.UsedPercent())
+				}
+				if summary.Node.Runtime != nil && summary.Node.Runtime.ImageFs != nil {
+					imageFs = fmt.Sprintf("%.1f%%", summary.Node.Runtime.ImageFs.UsedPercent())
+				}
+				for _, ps := range summary.Pods {
+					key := ps.PodRef.Namespace + "/" + ps.PodRef.Name
+					podStats[key] = ps
+					vols := make(map[string]uint64, len(ps.VolumeStats))
+					for _, vs := range ps.VolumeStats {
+						vols[vs.Name] = vs.UsedBytes
+					}
+					volumeUsage[key] = vols
+				}
+			}
+			allocatable := "<none>"
+			if q, ok := n.Status.Allocatable[corev1.ResourceEphemeralStorage]; ok {
+				allocatable = formatBytes(q.Value())
+			}
+			nodeRows = append(nodeRows, []string{n.Name, pressure, nodeFs, imageFs, allocatable})
+		}
+		sb.WriteString(util.FormatTable(nodeHeaders, nodeRows))
+		if len(statsErrs) > 0 {
+			sb.WriteString(fmt.Sprintf("\n  (kubelet stats unavailable for %d node(s) — usage columns will be incomplete)\n", len(statsErrs)))
+		}
+
+		// 2. Per-pod configuration and usage
+		byNode := make(map[string][]ephemeralPodUsage)
+		var noLimit []string
+		var unboundedEmptyDirs []string
+		var overSizeLimit []string
+		var evicted []*corev1.Pod
+		for i := range pods {
+			p := &pods[i]
+			if p.Status.Reason == "Evicted" {
+				evicted = append(evicted, p)
+				continue
+			}
+			if p.Status.Phase == corev1.PodSucceeded || p.Status.Phase == corev1.PodFailed {
+				continue
+			}
+			key := p.Namespace + "/" + p.Name
+
+			usage, missing := newEphemeralPodUsage(p, podStats)
+			noLimit = append(noLimit, missing...)
+			unbounded, over := classifyEmptyDirs(p, volumeUsage[key])
+			unboundedEmptyDirs = append(unboundedEmptyDirs, unbounded...)
+			overSizeLimit = append(overSizeLimit, over...)
+
+			byNode[p.Spec.NodeName] = append(byNode[p.Spec.NodeName], usage)
+		}
+
+		// 3. Worst offenders per node
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Top Ephemeral Storage Consumers by Node"))
+		sb.WriteString("\n")
+		nodeNames := make([]string, 0, len(byNode))
+		for name := range byNode {
+			nodeNames = append(nodeNames, name)
+		}
+		sort.Strings(nodeNames)
+		var nearLimit []string
+		for _, nodeName := range nodeNames {
+			usages := byNode[nodeName]
+			sort.Slice(usages, func(i, j int) bool { return usages[i].Used > usages[j].Used })
+			// Count every pod near its limit, not only the top consumers shown below.
+			for _, u := range usages {
+				if u.nearLimit() {
+					nearLimit = append(nearLimit, u.Pod.Namespace+"/"+u.Pod.Name)
+				}
+			}
+			if len(usages) > util.DefaultTopLimit {
+				usages = usages[:util.DefaultTopLimit]
+			}
+			label := nodeName
+			if label == "" {
+				label = "<unscheduled>"
+			}
+			sb.WriteString(fmt.Sprintf("\n  Node: %s\n", label))
+			headers := []string{"POD", "NAMESPACE", "USED", "REQUEST", "LIMIT", "% OF LIMIT"}
+			rows := make([][]string, 0, len(usages))
+			for _, u := range usages {
+				used := "?"
+				if u.HasStat {
+					used = formatBytes(u.Used)
+				}
+				pct := "-"
+				if ratio, ok := u.limitPercent(); ok {
+					pct = fmt.Sprintf("%.0f%%", ratio)
+					if u.nearLimit() {
+						pct += " [WARNING]"
+					}
+				}
+				rows = append(rows, []string{
+					u.Pod.Name,
+					u.Pod.Namespace,
+					used,
+					formatOptionalBytes(u.Request),
+					formatOptionalBytes(u.Limit),
+					pct,
+				})
+			}
+			sb.WriteString("  ")
+			sb.WriteString(strings.ReplaceAll(strings.TrimRight(util.FormatTable(headers, rows), "\n"), "\n", "\n  "))
+			sb.WriteString("\n")
+		}
+		if len(nearLimit) > 0 {
+			sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("%d pod(s) using >=%d%% of their ephemeral-storage limit — the kubelet evicts pods that exceed it: %s",
+				len(nearLimit), util.ResourceUsageWarningPercent, strings.Join(truncateList(nearLimit, 5), ", "))))
+			sb.WriteString("\n")
+			findings++
+		}
+
+		// 4. emptyDir volumes
+		if len(unboundedEmptyDirs) > 0 || len(overSizeLimit) > 0 {
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("emptyDir Volumes"))
+			sb.WriteString("\n")
+			if len(overSizeLimit) > 0 {
+				sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("%d emptyDir volume(s) over their sizeLimit — pods will be evicted", len(overSizeLimit))))
+				sb.WriteString("\n")
+				for _, v := range overSizeLimit {
+					sb.WriteString(fmt.Sprintf("  - %s\n", v))
+				}
+				findings++
+			}
+			if len(unboundedEmptyDirs) > 0 {
+				sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("%d disk-backed emptyDir volume(s) without sizeLimit", len(unboundedEmptyDirs))))
+				sb.WriteString("\n")
+				for _, v := range truncateList(unboundedEmptyDirs, 20) {
+					sb.WriteString(fmt.Sprintf("  - %s\n", v))
+				}
+				findings++
+				actions = append(actions, "Set emptyDir.sizeLimit on disk-backed emptyDir volumes so one pod cannot fill the node disk")
+			}
+		}
+
+		// 5. Missing limits
+		if len(noLimit) > 0 {
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("Containers Without ephemeral-storage Limits"))
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatFinding("INFO", fmt.Sprintf("%d container(s) have no ephemeral-storage limit", len(noLimit))))
+			sb.WriteString("\n")
+			for _, c := range truncateList(noLimit, 20) {
+				sb.WriteString(fmt.Sprintf("  - %s\n", c))
+			}
+			findings++
+			actions = append(actions, "Add ephemeral-storage requests/limits (or a LimitRange default) so the scheduler accounts for local disk")
+		}
+
+		// 6. Evicted pods
+		if len(evicted) > 0 {
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("Evicted Pods"))
+			sb.WriteString("\n")
+			headers := []string{"POD", "NAMESPACE", "NODE", "AGE", "MESSAGE"}
+			rows := make([][]string, 0, len(evicted))
+			diskEvictions := 0
+			for _, p := range evicted {
+				if strings.Contains(p.Status.Message, "ephemeral-storage") || strings.Contains(p.Status.Message, "DiskPressure") {
+					diskEvictions++
+				}
+				rows = append(rows, []string{p.Name, p.Namespace, p.Spec.NodeName, util.FormatAge(p.CreationTimestamp.Time), p.Status.Message})
+			}
+			sb.WriteString(util.FormatTable(headers, rows))
+			sb.WriteString(fmt.Sprintf("\n%s\n", util.FormatFinding("WARNING", fmt.Sprintf("%d evicted pod(s), %d due to disk/ephemeral-storage", len(evicted), diskEvictions))))
+			findings++
+			actions = append(actions, "Delete evicted pods once investigated (kubectl delete pod --field-selector=status.phase=Failed)")
+		}
+
+		// Summary
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Summary"))
+		sb.WriteString("\n")
+		if findings == 0 {
+			sb.WriteString("  No ephemeral storage issues found.\n")
+		} else {
+			sb.WriteString(fmt.Sprintf("  %d finding(s) identified. Review details above.\n", findings))
+		}
+		if len(actions) > 0 {
+			sb.WriteString("\nSUGGESTED ACTIONS:\n")
+			for i, a := range dedupe(actions) {
+				sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, a))
+			}
+		}
+
+		return util.SuccessResult(sb.String()), nil, nil
+	})
+
+	// diagnose_pvc
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "diagnose_pvc",
+		Description: "Diagnose a PersistentVolumeClaim end to end: PVC → PV → StorageClass → VolumeAttachment → CSIDriver/CSINode. " +
+			"Explains Pending PVCs (no default StorageClass, WaitForFirstConsumer, zone/topology mismatch), Multi-Attach errors, " +
+			"stuck detaches, and capacity usage from kubelet volume stats. Also flags Released PVs with the Retain policy.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input diagnosePVCInput) (*mcp.CallToolResult, any, error) {
+		pvc, err := client.GetPVC(ctx, input.Namespace, input.Name)
+		if err != nil {
+			return util.HandleK8sError(fmt.Sprintf("getting PVC %s/%s", input.Namespace, input.Name), err), nil, nil
+		}
+
+		var sb strings.Builder
+		sb.WriteString(util.FormatHeader(fmt.Sprintf("PVC Diagnosis: %s/%s", pvc.Namespace, pvc.Name)))
+		sb.WriteString("\n\n")
+		findings := 0
+		var actions []string
+
+		// 1. PVC status
+		sb.WriteString(util.FormatSubHeader("PersistentVolumeClaim"))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("Phase", string(pvc.Status.Phase)))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("Health", health.PVC(pvc).String()))
+		sb.WriteString("\n")
+		requested := "<none>"
+		if q, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+			requested = q.String()
+		}
+		sb.WriteString(util.FormatKeyValue("Requested", requested))
+		sb.WriteString("\n")
+		if q, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+			sb.WriteString(util.FormatKeyValue("Capacity", q.String()))
+			sb.WriteString("\n")
+		}
+		accessModes := make([]string, 0, len(pvc.Spec.AccessModes))
+		for _, am := range pvc.Spec.AccessModes {
+			accessModes = append(accessModes, string(am))
+		}
+		sb.WriteString(util.FormatKeyValue("Access Modes", strings.Join(accessModes, ",")))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("Volume", valueOrNone(pvc.Spec.VolumeName)))
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("Age", util.FormatAge(pvc.CreationTimestamp.Time)))
+		sb.WriteString("\n")
+		if selected := pvc.Annotations[selectedNodeAnnotation]; selected != "" {
+			sb.WriteString(util.FormatKeyValue("Selected Node", selected))
+			sb.WriteString("\n")
+		}
+
+		// 2. StorageClass resolution
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("StorageClass"))
+		sb.WriteString("\n")
+		classes, err := client.ListStorageClasses(ctx)
+		if err != nil {
+			return util.HandleK8sError("listing storage classes", err), nil, nil
+		}
+		var sc *storagev1.StorageClass
+		var defaults []string
+		for i := range classes {
+			if classes[i].Annotations[defaultStorageClassAnnotation] == "true" {
+				defaults = append(defaults, classes[i].Name)
+			}
+		}
+		switch {
+		case pvc.Spec.StorageClassName == nil:
+			if len(defaults) == 0 {
+				sb.WriteString(util.FormatFinding("CRITICAL", "PVC does not set storageClassName and the cluster has no default StorageClass"))
+				sb.WriteString("\n")
+				findings++
+				actions = append(actions, "Set storageClassName on the PVC or mark a StorageClass as default (storageclass.kubernetes.io/is-default-class=true)")
+			} else {
+				if len(defaults) > 1 {
+					// The DefaultStorageClass admission plugin picks the most recently created default.
+					newest := findStorageClass(classes, defaults[0])
+					for _, name := range defaults[1:] {
+						if c := findStorageClass(classes, name); c.CreationTimestamp.After(newest.CreationTimestamp.Time) {
+							newest = c
+						}
+					}
+					sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("%d StorageClasses are marked default (%s) — new PVCs without storageClassName get the most recently created one (%s), which may not be intended",
+						len(defaults), strings.Join(defaults, ", "), newest.Name)))
+					sb.WriteString("\n")
+					findings++
+					actions = append(actions, "Remove the storageclass.kubernetes.io/is-default-class annotation from all but one StorageClass")
+					sc = newest
+				} else {
+					sc = findStorageClass(classes, defaults[0])
+				}
+				sb.WriteString(fmt.Sprintf("  Using default StorageClass '%s'\n", sc.Name))
+			}
+		case *pvc.Spec.StorageClassName == "":
+			sb.WriteString("  storageClassName is \"\" — dynamic provisioning disabled, only pre-created PVs can bind\n")
+		default:
+			sc = findStorageClass(classes, *pvc.Spec.StorageClassName)
+			if sc == nil {
+				sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("StorageClass '%s' does not exist", *pvc.Spec.StorageClassName)))
+				sb.WriteString("\n")
+				findings++
+				actions = append(actions, fmt.Sprintf("Create StorageClass '%s' or update the PVC to an existing class", *pvc.Spec.StorageClassName))
+			}
+		}
+		bindingMode := storagev1.VolumeBindingImmediate
+		if sc != nil {
+			if sc.VolumeBindingMode != nil {
+				bindingMode = *sc.VolumeBindingMode
+			}
+			reclaim := corev1.PersistentVolumeReclaimDelete
+			if sc.ReclaimPolicy != nil {
+				reclaim = *sc.ReclaimPolicy
+			}
+			expansion := false
+			if sc.AllowVolumeExpansion != nil {
+				expansion = *sc.AllowVolumeExpansion
+			}
+			sb.WriteString(util.FormatKeyValue("Name", sc.Name))
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatKeyValue("Provisioner", sc.Provisioner))
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatKeyValue("Binding Mode", string(bindingMode)))
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatKeyValue("Reclaim Policy", string(reclaim)))
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatKeyValue("Volume Expansion", fmt.Sprintf("%t", expansion)))
+			sb.WriteString("\n")
+			if len(sc.AllowedTopologies) > 0 {
+				sb.WriteString(util.FormatKeyValue("Allowed Topologies", formatTopologyTerms(sc.AllowedTopologies)))
+				sb.WriteString("\n")
+			}
+		}
+
+		// Consumer pods
+		pods, err := client.ListPods(ctx, pvc.Namespace, metav1.ListOptions{})
+		if err != nil {
+			return util.HandleK8sError("listing pods", err), nil, nil
+		}
+		var consumers []*corev1.Pod
+		for i := range pods {
+			for _, vol := range pods[i].Spec.Volumes {
+				if vol.PersistentVolumeClaim != nil && vol.PersistentVolumeClaim.ClaimName == pvc.Name {
+					consumers = append(consumers, &pods[i])
+					break
+				}
+			}
+		}
+
+		// 3. Pending explanation
+		if pvc.Status.Phase == corev1.ClaimPending {
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("Why Is It Pending?"))
+			sb.WriteString("\n")
+			if sc != nil && bindingMode == storagev1.VolumeBindingWaitForFirstConsumer {
+				if len(consumers) == 0 {
+					sb.WriteString(util.FormatFinding("INFO", "StorageClass uses WaitForFirstConsumer and no pod references this PVC yet — this is expected"))
+					sb.WriteString("\n")
+				} else {
+					sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("WaitForFirstConsumer: %d consumer pod(s) exist but the volume is not provisioned yet — check pod scheduling", len(consumers))))
+					sb.WriteString("\n")
+					findings++
+				}
+			}
+			if sc != nil && len(sc.AllowedTopologies) > 0 {
+				nodes, err := client.ListNodes(ctx, metav1.ListOptions{})
+				if err == nil {
+					eligible := 0
+					for i := range nodes {
+						if topologyTermsMatch(sc.AllowedTopologies, nodes[i].Labels) {
+							eligible++
+						}
+					}
+					if eligible == 0 {
+						sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("No node matches the StorageClass allowedTopologies (%s) — zone mismatch", formatTopologyTerms(sc.AllowedTopologies))))
+						sb.WriteString("\n")
+						findings++
+						actions = append(actions, "Align StorageClass allowedTopologies with the zones your nodes run in")
+					}
+				}
+			}
+			events, err := client.GetEventsForObject(ctx, pvc.Namespace, pvc.Name)
+			if err == nil {
+				for _, e := range events {
+					if e.Type != corev1.EventTypeWarning && e.Reason != "ExternalProvisioning" {
+						continue
+					}
+					sb.WriteString(fmt.Sprintf("  [%s] %s (x%d): %s\n", e.Type, e.Reason, eventCount(e), e.Message))
+					if e.Reason == "ProvisioningFailed" {
+						findings++
+						actions = append(actions, "Check the CSI provisioner controller logs for the ProvisioningFailed error")
+					}
+				}
+			}
+		}
+
+		// 4. PersistentVolume
+		var pv *corev1.PersistentVolume
+		if pvc.Spec.VolumeName != "" {
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("PersistentVolume"))
+			sb.WriteString("\n")
+			pv, err = client.GetPV(ctx, pvc.Spec.VolumeName)
+			if err != nil {
+				sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("Bound PV '%s' could not be fetched: %v", pvc.Spec.VolumeName, err)))
+				sb.WriteString("\n")
+				findings++
+				pv = nil
+			} else {
+				sb.WriteString(util.FormatKeyValue("Name", pv.Name))
+				sb.WriteString("\n")
+				sb.WriteString(util.FormatKeyValue("Phase", string(pv.Status.Phase)))
+				sb.WriteString("\n")
+				sb.WriteString(util.FormatKeyValue("Reclaim Policy", string(pv.Spec.PersistentVolumeReclaimPolicy)))
+				sb.WriteString("\n")
+				if pv.Spec.CSI != nil {
+					sb.WriteString(util.FormatKeyValue("CSI Driver", pv.Spec.CSI.Driver))
+					sb.WriteString("\n")
+					sb.WriteString(util.FormatKeyValue("Volume Handle", pv.Spec.CSI.VolumeHandle))
+					sb.WriteString("\n")
+				}
+				if pv.Spec.NodeAffinity != nil && pv.Spec.NodeAffinity.Required != nil {
+					sb.WriteString(util.FormatKeyValue("Node Affinity", formatNodeSelectorTerms(pv.Spec.NodeAffinity.Required.NodeSelectorTerms)))
+					sb.WriteString("\n")
+				}
+			}
+		}
+
+		// 5. Consumer pods and attach/mount events
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Consumer Pods"))
+		sb.WriteString("\n")
+		if len(consumers) == 0 {
+			sb.WriteString("  No pods reference this PVC.\n")
+		}
+		for _, p := range consumers {
+			node := valueOrNone(p.Spec.NodeName)
+			sb.WriteString(fmt.Sprintf("  - %s (phase: %s, node: %s)\n", p.Name, podPhaseReason(p), node))
+
+			if pv != nil && p.Spec.NodeName != "" && pv.Spec.NodeAffinity != nil && pv.Spec.NodeAffinity.Required != nil {
+				if n, err := client.GetNode(ctx, p.Spec.NodeName); err == nil &&
+					!nodeSelectorTermsMatch(pv.Spec.NodeAffinity.Required.NodeSelectorTerms, n) {
+					sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("Node '%s' does not satisfy the PV node affinity — zone mismatch", n.Name)))
+					sb.WriteString("\n")
+					findings++
+				}
+			}
+
+			events, err := client.GetEventsForObject(ctx, p.Namespace, p.Name)
+			if err != nil {
+				continue
+			}
+			for _, e := range events {
+				switch {
+				case e.Reason == "FailedAttachVolume" && strings.Contains(e.Message, "Multi-Attach"):
+					sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("Multi-Attach error (x%d): %s", eventCount(e), e.Message)))
+					sb.WriteString("\n")
+					findings++
+					actions = append(actions, "A ReadWriteOnce volume is still attached to another node — ensure the old pod is gone and check VolumeAttachments for a stuck detach")
+				case e.Reason == "FailedAttachVolume" || e.Reason == "FailedMount":
+					sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("%s (x%d): %s", e.Reason, eventCount(e), e.Message)))
+					sb.WriteString("\n")
+					findings++
+				case e.Reason == "FailedScheduling" && strings.Contains(e.Message, "volume node affinity conflict"):
+					sb.WriteString(util.FormatFinding("CRITICAL", "Pod cannot schedule: volume node affinity conflict (PV is in a zone with no schedulable nodes)"))
+					sb.WriteString("\n")
+					findings++
+					actions = append(actions, "Add nodes in the PV's zone or restore the data into a volume in a schedulable zone")
+				}
+			}
+		}
+
+		// 6. VolumeAttachments and CSI
+		if pv != nil {
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("VolumeAttachments"))
+			sb.WriteString("\n")
+			attachments, err := client.ListVolumeAttachments(ctx)
+			if err != nil {
+				sb.WriteString(fmt.Sprintf("  (unable to list VolumeAttachments: %v)\n", err))
+			} else {
+				headers := []string{"NAME", "NODE", "ATTACHED", "AGE", "ERROR"}
+				var rows [][]string
+				attachedNodes := 0
+				for _, va := range attachments {
+					if va.Spec.Source.PersistentVolumeName == nil || *va.Spec.Source.PersistentVolumeName != pv.Name {
+						continue
+					}
+					errMsg := ""
+					if va.Status.AttachError != nil {
+						errMsg = "attach: " + va.Status.AttachError.Message
+					}
+					if va.Status.DetachError != nil {
+						errMsg = "detach: " + va.Status.DetachError.Message
+					}
+					if va.Status.Attached {
+						attachedNodes++
+					}
+					rows = append(rows, []string{va.Name, va.Spec.NodeName, fmt.Sprintf("%t", va.Status.Attached), util.FormatAge(va.CreationTimestamp.Time), errMsg})
+					if va.DeletionTimestamp != nil && time.Since(va.DeletionTimestamp.Time) > stuckDetachThreshold {
+						sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("VolumeAttachment '%s' on node '%s' has been detaching for %s — stuck detach", va.Name, va.Spec.NodeName, util.FormatAge(va.DeletionTimestamp.Time))))
+						sb.WriteString("\n")
+						findings++
+						actions = append(actions, fmt.Sprintf("Check the CSI controller and node '%s'; if the node is gone, remove the VolumeAttachment finalizer after confirming the disk is detached", va.Spec.NodeName))
+					} else if va.Status.DetachError != nil {
+						sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("VolumeAttachment '%s' detach error: %s", va.Name, va.Status.DetachError.Message)))
+						sb.WriteString("\n")
+						findings++
+					}
+					if va.Status.AttachError != nil {
+						sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("VolumeAttachment '%s' attach error: %s", va.Name, va.Status.AttachError.Message)))
+						sb.WriteString("\n")
+						findings++
+					}
+				}
+				if len(rows) == 0 {
+					sb.WriteString("  No VolumeAttachments for this PV.\n")
+				} else {
+					sb.WriteString(util.FormatTable(headers, rows))
+				}
+				if attachedNodes > 1 && !hasAccessMode(pv.Spec.AccessModes, corev1.ReadWriteMany) && !hasAccessMode(pv.Spec.AccessModes, corev1.ReadOnlyMany) {
+					sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("Volume is attached to %d nodes but is not ReadWriteMany/ReadOnlyMany", attachedNodes)))
+					sb.WriteString("\n")
+					findings++
+				}
+			}
+
+			if pv.Spec.CSI != nil {
+				sb.WriteString("\n")
+				sb.WriteString(util.FormatSubHeader("CSI Driver"))
+				sb.WriteString("\n")
+				driver, err := client.GetCSIDriver(ctx, pv.Spec.CSI.Driver)
+				if err != nil {
+					sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("CSIDriver '%s' not found: %v", pv.Spec.CSI.Driver, err)))
+					sb.WriteString("\n")
+					findings++
+				} else {
+					attachRequired := true
+					if driver.Spec.AttachRequired != nil {
+						attachRequired = *driver.Spec.AttachRequired
+					}
+					sb.WriteString(util.FormatKeyValue("Attach Required", fmt.Sprintf("%t", attachRequired)))
+					sb.WriteString("\n")
+				}
+				checked := make(map[string]bool)
+				for _, p := range consumers {
+					if p.Spec.NodeName == "" || checked[p.Spec.NodeName] {
+						continue
+					}
+					checked[p.Spec.NodeName] = true
+					csiNode, err := client.GetCSINode(ctx, p.Spec.NodeName)
+					registered := false
+					if err == nil {
+						for _, d := range csiNode.Spec.Drivers {
+							if d.Name == pv.Spec.CSI.Driver {
+								registered = true
+							}
+						}
+					}
+					if registered {
+						sb.WriteString(fmt.Sprintf("  Driver registered on node %s\n", p.Spec.NodeName))
+					} else {
+						sb.WriteString(util.FormatFinding("CRITICAL", fmt.Sprintf("CSI driver '%s' is not registered on node '%s'", pv.Spec.CSI.Driver, p.Spec.NodeName)))
+						sb.WriteString("\n")
+						findings++
+						actions = append(actions, fmt.Sprintf("Check the CSI node plugin DaemonSet pod on node '%s'", p.Spec.NodeName))
+					}
+				}
+			}
+		}
+
+		// 7. Capacity usage from kubelet volume stats
+		statsChecked := make(map[string]bool)
+		for _, p := range consumers {
+			if p.Spec.NodeName == "" || statsChecked[p.Spec.NodeName] {
+				continue
+			}
+			statsChecked[p.Spec.NodeName] = true
+			summary, err := client.GetNodeStatsSummary(ctx, p.Spec.NodeName)
+			if err != nil {
+				continue
+			}
+			vs := findPVCVolumeStats(summary, pvc.Namespace, pvc.Name)
+			if vs == nil {
+				continue
+			}
+			sb.WriteString("\n")
+			sb.WriteString(util.FormatSubHeader("Capacity Usage"))
+			sb.WriteString("\n")
+			used := vs.UsedPercent()
+			inodes := vs.InodesUsedPercent()
+			sb.WriteString(fmt.Sprintf("  Used: %s / %s (%.1f%%), inodes %.1f%%\n",
+				formatBytes(int64(vs.UsedBytes)), formatBytes(int64(vs.CapacityBytes)), used, inodes))
+			worst := used
+			if inodes > worst {
+				worst = inodes
+			}
+			if worst >= 95 {
+				sb.WriteString(util.FormatFinding("CRITICAL", "Volume is nearly full"))
+				sb.WriteString("\n")
+				findings++
+				actions = append(actions, "Expand the PVC (if the StorageClass allows expansion) or clean up data")
+			} else if worst >= float64(util.ResourceUsageWarningPercent) {
+				sb.WriteString(util.FormatFinding("WARNING", fmt.Sprintf("Volume usage above %d%%", util.ResourceUsageWarningPercent)))
+				sb.WriteString("\n")
+				findings++
+				actions = append(actions, "Plan a PVC expansion before the volume fills up")
+			}
+			break
+		}
+
+		// 8. Released PVs with Retain (cluster-wide)
+		pvs, err := client.ListPVs(ctx)
+		if err == nil {
+			headers := []string{"NAME", "CAPACITY", "STORAGE CLASS", "FORMER CLAIM", "AGE"}
+			var rows [][]string
+			for _, v := range pvs {
+				if v.Status.Phase != corev1.VolumeReleased || v.Spec.PersistentVolumeReclaimPolicy != corev1.PersistentVolumeReclaimRetain {
+					continue
+				}
+				capacity := ""
+				if q, ok := v.Spec.Capacity[corev1.ResourceStorage]; ok {
+					capacity = q.String()
+				}
+				claim := "<none>"
+				if v.Spec.ClaimRef != nil {
+					claim = v.Spec.ClaimRef.Namespace + "/" + v.Spec.ClaimRef.Name
+				}
+				rows = append(rows, []string{v.Name, capacity, v.Spec.StorageClassName, claim, util.FormatAge(v.CreationTimestamp.Time)})
+			}
+			if len(rows) > 0 {
+				sb.WriteString("\n")
+				sb.WriteString(util.FormatSubHeader("Released PVs (Retain)"))
+				sb.WriteString("\n")
+				sb.WriteString(util.FormatTable(headers, rows))
+				// Cluster-wide context, not a problem with this PVC: not counted as a finding.
+				sb.WriteString(fmt.Sprintf("\n%s\n", util.FormatFinding("INFO", fmt.Sprintf("%d Released PV(s) with reclaim policy Retain elsewhere in the cluster — their disks are kept (and billed) but cannot be re-bound; back up and delete the ones no longer needed, or clear claimRef to re-bind them", len(rows)))))
+			}
+		}
+
+		// Summary
+		sb.WriteString("\n")
+		sb.WriteString(util.FormatSubHeader("Summary"))
+		sb.WriteString("\n")
+		if findings == 0 {
+			sb.WriteString("  No issues found — PVC is healthy.\n")
+		} else {
+			sb.WriteString(fmt.Sprintf("  %d finding(s) identified. Review details above.\n", findings))
+		}
+		if len(actions) > 0 {
+			sb.WriteString("\nSUGGESTED ACTIONS:\n")
+			for i, a := range dedupe(actions) {
+				sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, a))
+			}
+		}
+
+		return util.SuccessResult(sb.String()), nil, nil
+	})
+}
+
+// newEphemeralPodUsage sums a pod's container ephemeral-storage requests and limits and attaches
+// the kubelet-reported usage when available. It also returns the containers without a limit.
+func newEphemeralPodUsage(p *corev1.Pod, podStats map[string]k8s.PodStats) (ephemeralPodUsage, []string) {
+	key := p.Namespace + "/" + p.Name
+	usage := ephemeralPodUsage{Pod: p}
+	var noLimit []string
+	for _, c := range p.Spec.Containers {
+		if q, ok := c.Resources.Requests[corev1.ResourceEphemeralStorage]; ok {
+			usage.Request += q.Value()
+		}
+		if q, ok := c.Resources.Limits[corev1.ResourceEphemeralStorage]; ok {
+			usage.Limit += q.Value()
+		} else {
+			noLimit = append(noLimit, fmt.Sprintf("%s/%s", key, c.Name))
+		}
+	}
+	// A pod-level limit only applies when every container sets one
+	if len(noLimit) > 0 {
+		usage.Limit = 0
+	}
+	if ps, ok := podStats[key]; ok {
+		usage.Used = int64(podEphemeralUsed(ps))
+		usage.HasStat = true
+	}
+	return usage, noLimit
+}
+
+// limitPercent returns usage as a percentage of the pod's ephemeral-storage limit.
+// ok is false when the pod has no effective limit or no kubelet stats.
+func (u ephemeralPodUsage) limitPercent() (float64, bool) {
+	if u.Limit <= 0 || !u.HasStat {
+		return 0, false
+	}
+	return float64(u.Used) / float64(u.Limit) * 100, true
+}
+
+// nearLimit reports whether usage is at or above util.ResourceUsageWarningPercent of the limit.
+func (u ephemeralPodUsage) nearLimit() bool {
+	ratio, ok := u.limitPercent()
+	return ok && ratio >= float64(util.ResourceUsageWarningPercent)
+}
+
+// classifyEmptyDirs returns the disk-backed emptyDir volumes of a pod that have no sizeLimit,
+// and those whose kubelet-reported usage exceeds their sizeLimit.
+func classifyEmptyDirs(p *corev1.Pod, volumeUsage map[string]uint64) (unbounded, overLimit []string) {
+	key := p.Namespace + "/" + p.Name
+	for _, vol := range p.Spec.Volumes {
+		if vol.EmptyDir == nil || vol.EmptyDir.Medium == corev1.StorageMediumMemory {
+			continue
+		}
+		if vol.EmptyDir.SizeLimit == nil {
+			unbounded = append(unbounded, fmt.Sprintf("%s (volume '%s')", key, vol.Name))
+			continue
+		}
+		used, ok := volumeUsage[vol.Name]
+		if ok && int64(used) > vol.EmptyDir.SizeLimit.Value() {
+			overLimit = append(overLimit, fmt.Sprintf("%s volume '%s': %s used, sizeLimit %s",
+				key, vol.Name, formatBytes(int64(used)), vol.EmptyDir.SizeLimit.String()))
+		}
+	}
+	return unbounded, overLimit
+}
+
+// formatOptionalBytes formats a byte count, returning "-" when unset.
+func formatOptionalBytes(b int64) string {
+	if b <= 0 {
+		return "-"
+	}
+	return formatBytes(b)
+}
+
+// truncateList returns at most max items, appending a summary entry for the remainder.
+func truncateList(items []string, max int) []string {
+	if len(items) <= max {
+		return items
+	}
+	result := append([]string{}, items[:max]...)
+	return append(result, fmt.Sprintf("... and %d more", len(items)-max))
+}
+
+// findStorageClass returns the StorageClass with the given name, or nil.
+func findStorageClass(classes []storagev1.StorageClass, name string) *storagev1.StorageClass {
+	for i := range classes {
+		if classes[i].Name == name {
+			return &classes[i]
+		}
+	}
+	return nil
+}
+
+// findPVCVolumeStats returns the kubelet volume stats backed by the given PVC, or nil.
+func findPVCVolumeStats(summary *k8s.NodeStatsSummary, namespace, name string) *k8s.VolumeStats {
+	for _, ps := range summary.Pods {
+		for i := range ps.VolumeStats {
+			ref := ps.VolumeStats[i].PVCRef
+			if ref != nil && ref.Namespace == namespace && ref.Name == name {
+				return &ps.VolumeStats[i]
+			}
+		}
+	}
+	return nil
+}
+
+func hasAccessMode(modes []corev1.PersistentVolumeAccessMode, mode corev1.PersistentVolumeAccessMode) bool {
+	for _, m := range modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// topologyTermsMatch reports whether node labels satisfy any StorageClass allowedTopologies term.
+func topologyTermsMatch(terms []corev1.TopologySelectorTerm, labels map[string]string) bool {
+	for _, term := range terms {
+		matched := true
+		for _, expr := range term.MatchLabelExpressions {
+			value, ok := labels[expr.Key]
+			if !ok || !containsString(expr.Values, value) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeSelectorTermsMatch reports whether a node satisfies any of the node selector terms (ORed).
+// Within a term, matchExpressions are evaluated against the node labels and matchFields against
+// metadata.name; a term with neither matches nothing, as in the scheduler.
+func nodeSelectorTermsMatch(terms []corev1.NodeSelectorTerm, node *corev1.Node) bool {
+	fields := map[string]string{"metadata.name": node.Name}
+	for _, term := range terms {
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			continue
+		}
+		if nodeSelectorRequirementsMatch(term.MatchExpressions, node.Labels) && nodeSelectorRequirementsMatch(term.MatchFields, fields) {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeSelectorRequirementsMatch reports whether values satisfy every requirement (ANDed). Gt and Lt
+// compare the value as an integer, as the scheduler does.
+func nodeSelectorRequirementsMatch(reqs []corev1.NodeSelectorRequirement, values map[string]string) bool {
+	for _, expr := range reqs {
+		value, ok := values[expr.Key]
+		matched := false
+		switch expr.Operator {
+		case corev1.NodeSelectorOpIn:
+			matched = ok && containsString(expr.Values, value)
+		case corev1.NodeSelectorOpNotIn:
+			matched = !ok || !containsString(expr.Values, value)
+		case corev1.NodeSelectorOpExists:
+			matched = ok
+		case corev1.NodeSelectorOpDoesNotExist:
+			matched = !ok
+		case corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt:
+			if !ok || len(expr.Values) != 1 {
+				break
+			}
+			have, err1 := strconv.ParseInt(value, 10, 64)
+			want, err2 := strconv.ParseInt(expr.Values[0], 10, 64)
+			if err1 != nil || err2 != nil {
+				break
+			}
+			matched = (expr.Operator == corev1.NodeSelectorOpGt && have > want) || (expr.Operator == corev1.NodeSelectorOpLt && have < want)
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func formatTopologyTerms(terms []corev1.TopologySelectorTerm) string {
+	parts := make([]string, 0, len(terms))
+	for _, term := range terms {
+		exprs := make([]string, 0, len(term.MatchLabelExpressions))
+		for _, expr := range term.MatchLabelExpressions {
+			exprs = append(exprs, fmt.Sprintf("%s in (%s)", expr.Key, strings.Join(expr.Values, ",")))
+		}
+		parts = append(parts, strings.Join(exprs, " && "))
+	}
+	return strings.Join(parts, " || ")
+}
+
+func formatNodeSelectorTerms(terms []corev1.NodeSelectorTerm) string {
+	parts := make([]string, 0, len(terms))
+	for _, term := range terms {
+		exprs := make([]string, 0, len(term.MatchExpressions))
+		for _, expr := range term.MatchExpressions {
+			exprs = append(exprs, fmt.Sprintf("%s %s (%s)", expr.Key, expr.Operator, strings.Join(expr.Values, ",")))
+		}
+		parts = append(parts, strings.Join(exprs, " && "))
+	}
+	return strings.Join(parts, " || ")
+}
+
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+package tools
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
+)
+
+func TestNodeSelectorTermsMatch(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name:   "node-a",
+		Labels: map[string]string{"topology.kubernetes.io/zone": "eu-west-1a", "example.com/generation": "12"},
+	}}
+	zone := func(op corev1.NodeSelectorOperator, values ...string) corev1.NodeSelectorRequirement {
+		return corev1.NodeSelectorRequirement{Key: "topology.kubernetes.io/zone", Operator: op, Values: values}
+	}
+	generation := func(op corev1.NodeSelectorOperator, value string) corev1.NodeSelectorRequirement {
+		return corev1.NodeSelectorRequirement{Key: "example.com/generation", Operator: op, Values: []string{value}}
+	}
+	hostname := func(op corev1.NodeSelectorOperator, values ...string) corev1.NodeSelectorRequirement {
+		return corev1.NodeSelectorRequirement{Key: "metadata.name", Operator: op, Values: values}
+	}
+
+	tests := []struct {
+		name  string
+		terms []corev1.NodeSelectorTerm
+		want  bool
+	}{
+		{"zone in", []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{zone(corev1.NodeSelectorOpIn, "eu-west-1a")}}}, true},
+		{"zone mismatch", []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{zone(corev1.NodeSelectorOpIn, "eu-west-1b")}}}, false},
+		{"terms are ORed", []corev1.NodeSelectorTerm{
+			{MatchExpressions: []corev1.NodeSelectorRequirement{zone(corev1.NodeSelectorOpIn, "eu-west-1b")}},
+			{MatchExpressions: []corev1.NodeSelectorRequirement{zone(corev1.NodeSelectorOpExists)}},
+		}, true},
+		// "12" > "9" numerically but not as strings.
+		{"gt is numeric", []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{generation(corev1.NodeSelectorOpGt, "9")}}}, true},
+		{"lt is numeric", []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{generation(corev1.NodeSelectorOpLt, "9")}}}, false},
+		{"gt with a non-integer", []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{generation(corev1.NodeSelectorOpGt, "x")}}}, false},
+		{"matchFields on the node name", []corev1.NodeSelectorTerm{{MatchFields: []corev1.NodeSelectorRequirement{hostname(corev1.NodeSelectorOpIn, "node-a")}}}, true},
+		{"matchFields mismatch", []corev1.NodeSelectorTerm{{
+			MatchExpressions: []corev1.NodeSelectorRequirement{zone(corev1.NodeSelectorOpIn, "eu-west-1a")},
+			MatchFields:      []corev1.NodeSelectorRequirement{hostname(corev1.NodeSelectorOpIn, "node-b")},
+		}}, false},
+		{"empty term matches nothing", []corev1.NodeSelectorTerm{{}}, false},
+	}
+	for _, tt := range tests {
+		if got := nodeSelectorTermsMatch(tt.terms, node); got != tt.want {
+			t.Errorf("%s: nodeSelectorTermsMatch() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func ephemeralContainer(name, request, limit string) corev1.Container {
+	c := corev1.Container{Name: name, Resources: corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{},
+		Limits:   corev1.ResourceList{},
+	}}
+	if request != "" {
+		c.Resources.Requests[corev1.ResourceEphemeralStorage] = resource.MustParse(request)
+	}
+	if limit != "" {
+		c.Resources.Limits[corev1.ResourceEphemeralStorage] = resource.MustParse(limit)
+	}
+	return c
+}
+
+func TestNewEphemeralPodUsage(t *testing.T) {
+	stats := map[string]k8s.PodStats{
+		"default/web": {EphemeralStorage: &k8s.FsStats{UsedBytes: 850}},
+		"default/api": {EphemeralStorage: &k8s.FsStats{UsedBytes: 500}},
+	}
+	tests := []struct {
+		name        string
+		pod         *corev1.Pod
+		wantLimit   int64
+		wantUsed    int64
+		wantNoLimit int
+		wantPercent float64
+		wantHasPct  bool
+		wantNear    bool
+	}{
+		{
+			name: "limits summed across containers, near limit",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{ephemeralContainer("app", "100", "600"), ephemeralContainer("sidecar", "", "400")}},
+			},
+			wantLimit: 1000, wantUsed: 850, wantPercent: 85, wantHasPct: true, wantNear: true,
+		},
+		{
+			name: "below the warning threshold",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{ephemeralContainer("app", "", "1000")}},
+			},
+			wantLimit: 1000, wantUsed: 500, wantPercent: 50, wantHasPct: true,
+		},
+		{
+			name: "one container without a limit leaves the pod unbounded",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{ephemeralContainer("app", "", "600"), ephemeralContainer("sidecar", "", "")}},
+			},
+			wantUsed: 850, wantNoLimit: 1,
+		},
+		{
+			name: "no kubelet stats",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "batch", Namespace: "default"},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{ephemeralContainer("app", "", "1000")}},
+			},
+			wantLimit: 1000,
+		},
+	}
+	for _, tt := range tests {
+		usage, noLimit := newEphemeralPodUsage(tt.pod, stats)
+		if usage.Limit != tt.wantLimit || usage.Used != tt.wantUsed || len(noLimit) != tt.wantNoLimit {
+			t.Errorf("%s: got limit=%d used=%d noLimit=%v, want limit=%d used=%d noLimit=%d",
+				tt.name, usage.Limit, usage.Used, noLimit, tt.wantLimit, tt.wantUsed, tt.wantNoLimit)
+		}
+		pct, ok := usage.limitPercent()
+		if ok != tt.wantHasPct || pct != tt.wantPercent {
+			t.Errorf("%s: limitPercent() = %.1f, %v, want %.1f, %v", tt.name, pct, ok, tt.wantPercent, tt.wantHasPct)
+		}
+		if got := usage.nearLimit(); got != tt.wantNear {
+			t.Errorf("%s: nearLimit() = %v, want %v", tt.name, got, tt.wantNear)
+		}
+	}
+}
+
+func TestClassifyEmptyDirs(t *testing.T) {
+	limit := resource.MustParse("1Ki")
+	emptyDir := func(name string, medium corev1.StorageMedium, sizeLimit *resource.Quantity) corev1.Volume {
+		return corev1.Volume{Name: name, VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{Medium: medium, SizeLimit: sizeLimit},
+		}}
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: corev1.PodSpec{Volumes: []corev1.Volume{
+			emptyDir("scratch", "", nil),
+			emptyDir("shm", corev1.StorageMediumMemory, nil),
+			emptyDir("cache", "", &limit),
+			emptyDir("tmp", "", &limit),
+			{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{}}},
+		}},
+	}
+	usage := map[string]uint64{"cache": 2048, "tmp": 512}
+
+	unbounded, over := classifyEmptyDirs(pod, usage)
+	if len(unbounded) != 1 || unbounded[0] != "default/web (volume 'scratch')" {
+		t.Errorf("unexpected unbounded emptyDirs: %v", unbounded)
+	}
+	if len(over) != 1 || over[0] != "default/web volume 'cache': 2.0Ki used, sizeLimit 1Ki" {
+		t.Errorf("unexpected over-limit emptyDirs: %v", over)
+	}
+}
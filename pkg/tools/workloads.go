@@ -8,6 +8,7 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/pat-nel87/kube-doctor-mcp/pkg/health"
 	"github.com/pat-nel87/kube-doctor-mcp/pkg/k8s"
 	"github.com/pat-nel87/kube-doctor-mcp/pkg/util"
 )
@@ -51,9 +52,10 @@ func registerWorkloadTools(server *mcp.Server, client *k8s.ClusterClient) {
 			return util.HandleK8sError("listing deployments", err), nil, nil
 		}
 
-		headers := []string{"NAME", "NAMESPACE", "READY", "UP-TO-DATE", "AVAILABLE", "AGE", "STRATEGY"}
+		headers := []string{"NAME", "NAMESPACE", "READY", "UP-TO-DATE", "AVAILABLE", "HEALTH", "AGE", "STRATEGY"}
 		rows := make([][]string, 0, len(deployments))
-		for _, d := range deployments {
+		for i := range deployments {
+			d := &deployments[i]
 			strategy := "RollingUpdate"
 			if d.Spec.Strategy.Type != "" {
 				strategy = string(d.Spec.Strategy.Type)
@@ -68,6 +70,7 @@ func registerWorkloadTools(server *mcp.Server, client *k8s.ClusterClient) {
 				fmt.Sprintf("%d/%d", d.Status.ReadyReplicas, desired),
 				fmt.Sprintf("%d", d.Status.UpdatedReplicas),
 				fmt.Sprintf("%d", d.Status.AvailableReplicas),
+				string(health.Deployment(d).Status),
 				util.FormatAge(d.CreationTimestamp.Time),
 				strategy,
 			})
@@ -103,6 +106,8 @@ func registerWorkloadTools(server *mcp.Server, client *k8s.ClusterClient) {
 		sb.WriteString(util.FormatKeyValue("Replicas", fmt.Sprintf("%d desired, %d ready, %d available, %d updated",
 			desired, deploy.Status.ReadyReplicas, deploy.Status.AvailableReplicas, deploy.Status.UpdatedReplicas)))
 		sb.WriteString("\n")
+		sb.WriteString(util.FormatKeyValue("Health", health.Deployment(deploy).String()))
+		sb.WriteString("\n")
 		sb.WriteString(util.FormatKeyValue("Strategy", string(deploy.Spec.Strategy.Type)))
 		sb.WriteString("\n")
 		sb.WriteString(util.FormatKeyValue("Age", util.FormatAge(deploy.CreationTimestamp.Time)))
@@ -0,0 +1,65 @@
+package util
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+)
+
+// ParsePEMCertificates decodes every CERTIFICATE block in PEM data, in order.
+func ParsePEMCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no PEM certificates found")
+	}
+	return certs, nil
+}
+
+// DaysUntilExpiry returns the whole days until the certificate expires (negative if expired).
+func DaysUntilExpiry(cert *x509.Certificate, now time.Time) int {
+	return int(cert.NotAfter.Sub(now).Hours() / 24)
+}
+
+// CertificateChainProblems checks an ordered chain (leaf first) and returns a description of each problem found.
+func CertificateChainProblems(chain []*x509.Certificate, now time.Time) []string {
+	var problems []string
+	for i, cert := range chain {
+		if now.Before(cert.NotBefore) {
+			problems = append(problems, fmt.Sprintf("certificate %d (%s) is not valid until %s", i, cert.Subject.CommonName, cert.NotBefore.Format(time.RFC3339)))
+		}
+		if i > 0 && now.After(cert.NotAfter) {
+			problems = append(problems, fmt.Sprintf("intermediate %d (%s) expired %s", i, cert.Subject.CommonName, cert.NotAfter.Format(time.RFC3339)))
+		}
+		if i+1 < len(chain) {
+			if err := cert.CheckSignatureFrom(chain[i+1]); err != nil {
+				problems = append(problems, fmt.Sprintf("certificate %d (%s) is not signed by the next certificate in the chain (%s)", i, cert.Subject.CommonName, chain[i+1].Subject.CommonName))
+			}
+		}
+	}
+	return problems
+}
+
+// IsSelfSigned reports whether the certificate's issuer is itself.
+func IsSelfSigned(cert *x509.Certificate) bool {
+	if cert.Subject.String() != cert.Issuer.String() {
+		return false
+	}
+	// CheckSignatureFrom would reject non-CA leaves, so verify the signature directly
+	return cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature) == nil
+}
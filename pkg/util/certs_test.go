@@ -0,0 +1,96 @@
+package util
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func testCert(t *testing.T, cn string, notAfter time.Time, isCA bool, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestParsePEMCertificates(t *testing.T) {
+	_, _, pemA := testCert(t, "a", time.Now().Add(24*time.Hour), true, nil, nil)
+	_, _, pemB := testCert(t, "b", time.Now().Add(24*time.Hour), true, nil, nil)
+
+	certs, err := ParsePEMCertificates(append(pemA, pemB...))
+	if err != nil {
+		t.Fatalf("ParsePEMCertificates() error = %v", err)
+	}
+	if len(certs) != 2 || certs[0].Subject.CommonName != "a" || certs[1].Subject.CommonName != "b" {
+		t.Errorf("unexpected certificates: %d", len(certs))
+	}
+
+	if _, err := ParsePEMCertificates([]byte("not a cert")); err == nil {
+		t.Error("expected error for non-PEM input")
+	}
+}
+
+func TestDaysUntilExpiry(t *testing.T) {
+	now := time.Now()
+	cert, _, _ := testCert(t, "x", now.Add(10*24*time.Hour+time.Hour), false, nil, nil)
+	if d := DaysUntilExpiry(cert, now); d != 10 {
+		t.Errorf("DaysUntilExpiry() = %d, want 10", d)
+	}
+	expired, _, _ := testCert(t, "x", now.Add(-48*time.Hour), false, nil, nil)
+	if d := DaysUntilExpiry(expired, now); d >= 0 {
+		t.Errorf("DaysUntilExpiry() = %d, want negative", d)
+	}
+}
+
+func TestCertificateChainProblems(t *testing.T) {
+	now := time.Now()
+	ca, caKey, _ := testCert(t, "ca", now.Add(365*24*time.Hour), true, nil, nil)
+	leaf, _, _ := testCert(t, "leaf", now.Add(30*24*time.Hour), false, ca, caKey)
+	other, _, _ := testCert(t, "other-ca", now.Add(365*24*time.Hour), true, nil, nil)
+
+	if problems := CertificateChainProblems([]*x509.Certificate{leaf, ca}, now); len(problems) != 0 {
+		t.Errorf("expected valid chain, got %v", problems)
+	}
+	if problems := CertificateChainProblems([]*x509.Certificate{leaf, other}, now); len(problems) != 1 {
+		t.Errorf("expected 1 problem for wrong issuer, got %v", problems)
+	}
+
+	if !IsSelfSigned(ca) {
+		t.Error("expected CA to be self-signed")
+	}
+	if IsSelfSigned(leaf) {
+		t.Error("expected leaf not to be self-signed")
+	}
+	selfSignedLeaf, _, _ := testCert(t, "self", now.Add(24*time.Hour), false, nil, nil)
+	if !IsSelfSigned(selfSignedLeaf) {
+		t.Error("expected self-signed non-CA certificate to be detected")
+	}
+}
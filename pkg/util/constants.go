@@ -33,6 +33,18 @@ const (
 	// MaxRBACBindings is the maximum number of RBAC bindings to return.
 	MaxRBACBindings = 200
 
+	// ImageGCHighThresholdPercent is the kubelet's default image filesystem usage that triggers image GC.
+	ImageGCHighThresholdPercent = 85
+
+	// CertExpiryWarningDays is the number of days before expiry that a certificate is reported as a warning.
+	CertExpiryWarningDays = 30
+
+	// CertExpiryCriticalDays is the number of days before expiry that a certificate is reported as critical.
+	CertExpiryCriticalDays = 7
+
 	// MaxFluxResources is the maximum number of Flux resources to return in a list.
 	MaxFluxResources = 200
+
+	// MaxConcurrentNodeProxyCalls bounds the number of kubelet proxy requests in flight at once.
+	MaxConcurrentNodeProxyCalls = 10
 )
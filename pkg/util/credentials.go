@@ -0,0 +1,102 @@
+package util
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// credentialPrefixes maps well-known token prefixes to the credential type they identify.
+var credentialPrefixes = []struct {
+	prefix string
+	kind   string
+}{
+	{"AKIA", "AWS access key"},
+	{"ASIA", "AWS temporary access key"},
+	{"ghp_", "GitHub personal access token"},
+	{"gho_", "GitHub OAuth token"},
+	{"ghs_", "GitHub app token"},
+	{"github_pat_", "GitHub fine-grained token"},
+	{"glpat-", "GitLab personal access token"},
+	{"xoxb-", "Slack bot token"},
+	{"xoxp-", "Slack user token"},
+	{"sk_live_", "Stripe live secret key"},
+	{"AIza", "Google API key"},
+	{"-----BEGIN", "PEM private key or certificate"},
+}
+
+var (
+	credentialNamePattern = regexp.MustCompile(`(?i)(PASSWORD|PASSWD|SECRET|TOKEN|API_?KEY|PRIVATE_?KEY|CREDENTIAL|ACCESS_?KEY)`)
+	jwtPattern            = regexp.MustCompile(`^eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*$`)
+	// referenceNamePattern matches variables that name or locate a credential rather than hold it.
+	referenceNamePattern = regexp.MustCompile(`(?i)_(FILE|PATH|URL|URI|NAME)$`)
+	// dnsNamePattern matches DNS-style object names such as my-db-secret or vault.example.com.
+	dnsNamePattern    = regexp.MustCompile(`^[a-z0-9]+([-.][a-z0-9]+)+$`)
+	placeholderValues = map[string]bool{"changeme": true, "password": true, "secret": true, "true": true, "false": true, "none": true, "null": true}
+)
+
+const (
+	// credentialEntropyThreshold is the Shannon entropy (bits/char) above which a long token looks random.
+	credentialEntropyThreshold = 4.0
+	credentialMinLength        = 20
+)
+
+// ShannonEntropy returns the Shannon entropy of s in bits per character.
+func ShannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+	var entropy float64
+	for _, c := range counts {
+		p := float64(c) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// CredentialReason explains why an environment variable literal looks like a credential, or returns "".
+// It checks known token prefixes, JWTs, credential-like variable names and high-entropy strings.
+func CredentialReason(name, value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.HasPrefix(value, "$(") {
+		return ""
+	}
+	for _, p := range credentialPrefixes {
+		if strings.HasPrefix(value, p.prefix) && len(value) > len(p.prefix)+8 {
+			return fmt.Sprintf("value matches the %s format", p.kind)
+		}
+	}
+	if jwtPattern.MatchString(value) {
+		return "value looks like a JWT"
+	}
+	if credentialNamePattern.MatchString(name) && len(value) >= 8 && !placeholderValues[strings.ToLower(value)] &&
+		!referenceNamePattern.MatchString(name) && !isReferenceValue(value) {
+		return fmt.Sprintf("variable name %s suggests a credential", name)
+	}
+	if len(value) >= credentialMinLength && !strings.ContainsAny(value, " /:") && ShannonEntropy(value) >= credentialEntropyThreshold {
+		return fmt.Sprintf("high-entropy value (%.1f bits/char)", ShannonEntropy(value))
+	}
+	return ""
+}
+
+// isReferenceValue reports whether a value is a path, URL or DNS-style name: it points at a
+// credential (a mounted file, a Secret, a vault endpoint) rather than containing one.
+func isReferenceValue(value string) bool {
+	return strings.HasPrefix(value, "/") || strings.HasPrefix(value, "./") || strings.HasPrefix(value, "~/") ||
+		strings.Contains(value, "://") || dnsNamePattern.MatchString(value)
+}
+
+// RedactValue hides a sensitive value, keeping at most a short prefix for identification.
+func RedactValue(value string) string {
+	if len(value) < 12 {
+		return "****"
+	}
+	return fmt.Sprintf("%s**** (%d chars)", value[:4], len(value))
+}
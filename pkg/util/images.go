@@ -0,0 +1,73 @@
+package util
+
+import "strings"
+
+// DefaultRegistry is the registry implied by image references without a registry host.
+const DefaultRegistry = "docker.io"
+
+// ImageReference is a parsed container image reference.
+type ImageReference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseImageReference splits an image reference into registry, repository, tag and digest,
+// applying Docker Hub defaults (docker.io, library/) the way container runtimes do.
+func ParseImageReference(image string) ImageReference {
+	var ref ImageReference
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		ref.Digest = name[i+1:]
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		ref.Tag = name[i+1:]
+		name = name[:i]
+	}
+
+	first, rest, found := strings.Cut(name, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		ref.Registry = first
+		ref.Repository = rest
+	} else {
+		ref.Registry = DefaultRegistry
+		ref.Repository = name
+		if !found {
+			ref.Repository = "library/" + name
+		}
+	}
+	if ref.Registry == "index.docker.io" {
+		ref.Registry = DefaultRegistry
+	}
+	return ref
+}
+
+// Name returns the fully qualified repository name (registry/repository).
+func (r ImageReference) Name() string {
+	return r.Registry + "/" + r.Repository
+}
+
+// String returns the normalized reference, including tag and digest when present.
+func (r ImageReference) String() string {
+	s := r.Name()
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// ImageIDDigest extracts the registry digest from a containerStatus imageID
+// (e.g. "docker-pullable://nginx@sha256:abc"). A bare image ID such as "sha256:abc" or
+// "docker://sha256:abc" is the local config digest of a side-loaded or locally built image,
+// which differs across nodes for the same tag, so it yields "".
+func ImageIDDigest(imageID string) string {
+	if i := strings.LastIndex(imageID, "@sha256:"); i >= 0 {
+		return imageID[i+1:]
+	}
+	return ""
+}
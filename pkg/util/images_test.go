@@ -0,0 +1,50 @@
+package util
+
+import "testing"
+
+func TestParseImageReference(t *testing.T) {
+	tests := []struct {
+		image string
+		want  ImageReference
+	}{
+		{"nginx", ImageReference{Registry: "docker.io", Repository: "library/nginx"}},
+		{"nginx:1.25", ImageReference{Registry: "docker.io", Repository: "library/nginx", Tag: "1.25"}},
+		{"bitnami/redis:7.2", ImageReference{Registry: "docker.io", Repository: "bitnami/redis", Tag: "7.2"}},
+		{"ghcr.io/org/app:v1.0.0", ImageReference{Registry: "ghcr.io", Repository: "org/app", Tag: "v1.0.0"}},
+		{"localhost:5000/app", ImageReference{Registry: "localhost:5000", Repository: "app"}},
+		{"registry.k8s.io/pause:3.9@sha256:abc", ImageReference{Registry: "registry.k8s.io", Repository: "pause", Tag: "3.9", Digest: "sha256:abc"}},
+		{"quay.io/prometheus/node-exporter@sha256:def", ImageReference{Registry: "quay.io", Repository: "prometheus/node-exporter", Digest: "sha256:def"}},
+		{"index.docker.io/library/busybox:latest", ImageReference{Registry: "docker.io", Repository: "library/busybox", Tag: "latest"}},
+	}
+	for _, tt := range tests {
+		if got := ParseImageReference(tt.image); got != tt.want {
+			t.Errorf("ParseImageReference(%q) = %+v, want %+v", tt.image, got, tt.want)
+		}
+	}
+}
+
+func TestImageReferenceString(t *testing.T) {
+	ref := ParseImageReference("nginx:1.25")
+	if ref.Name() != "docker.io/library/nginx" {
+		t.Errorf("Name() = %q", ref.Name())
+	}
+	if ref.String() != "docker.io/library/nginx:1.25" {
+		t.Errorf("String() = %q", ref.String())
+	}
+}
+
+func TestImageIDDigest(t *testing.T) {
+	tests := map[string]string{
+		"docker-pullable://nginx@sha256:abc": "sha256:abc",
+		"docker.io/library/nginx@sha256:abc": "sha256:abc",
+		"sha256:def":                         "",
+		"docker://sha256:def":                "",
+		"containerd://sha256:def":            "",
+		"":                                   "",
+	}
+	for id, want := range tests {
+		if got := ImageIDDigest(id); got != want {
+			t.Errorf("ImageIDDigest(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
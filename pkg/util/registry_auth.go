@@ -0,0 +1,106 @@
+package util
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ImagePullCause classifies why an image pull failed.
+type ImagePullCause struct {
+	Category    string
+	Explanation string
+}
+
+// imagePullPatterns are matched in order against lowercased kubelet/runtime pull error messages.
+// Needles are whole phrases: bare status codes such as "401" also occur inside digests and sizes.
+var imagePullPatterns = []struct {
+	needles []string
+	cause   ImagePullCause
+}{
+	{[]string{"toomanyrequests", "rate limit", "429 too many requests"},
+		ImagePullCause{"RateLimited", "the registry is throttling pulls (e.g. Docker Hub anonymous pull limits)"}},
+	{[]string{"x509", "certificate signed by unknown authority", "tls: failed to verify"},
+		ImagePullCause{"TLS", "the node does not trust the registry's TLS certificate"}},
+	{[]string{"repository does not exist or may require authorization"},
+		ImagePullCause{"NotFoundOrUnauthorized", "the repository does not exist, or it is private and no valid credentials were sent"}},
+	{[]string{"manifest unknown", "not found", "manifest for"},
+		ImagePullCause{"ImageNotFound", "the repository or tag does not exist in the registry"}},
+	{[]string{"unauthorized", "authentication required", "403 forbidden", "denied:", "access denied"},
+		ImagePullCause{"Unauthorized", "the registry rejected the credentials (missing, wrong or expired pull secret)"}},
+	{[]string{"i/o timeout", "no such host", "connection refused", "dial tcp", "context deadline exceeded", "network is unreachable", "tls handshake timeout"},
+		ImagePullCause{"Network", "the node cannot reach the registry (DNS, firewall, proxy or egress)"}},
+	{[]string{"invalid reference format", "invalidimagename"},
+		ImagePullCause{"InvalidName", "the image reference is malformed"}},
+	{[]string{"no match for platform", "exec format error"},
+		ImagePullCause{"PlatformMismatch", "the image has no variant for the node's OS/architecture"}},
+}
+
+// ClassifyImagePullError maps a pull error message to a root-cause category.
+func ClassifyImagePullError(message string) ImagePullCause {
+	msg := strings.ToLower(message)
+	for _, p := range imagePullPatterns {
+		for _, needle := range p.needles {
+			if strings.Contains(msg, needle) {
+				return p.cause
+			}
+		}
+	}
+	return ImagePullCause{"Unknown", "no known error pattern matched the message"}
+}
+
+// DockerConfigEntry describes one registry entry of a docker config secret, without its credentials.
+type DockerConfigEntry struct {
+	Host           string
+	HasCredentials bool
+}
+
+type dockerAuth struct {
+	Auth          string `json:"auth"`
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	IdentityToken string `json:"identitytoken"`
+}
+
+// ParseDockerConfig lists the registry hosts of a .dockerconfigjson ({"auths": {...}}) or legacy .dockercfg payload.
+// Credentials are only checked for presence and never returned.
+func ParseDockerConfig(data []byte) ([]DockerConfigEntry, error) {
+	var auths map[string]dockerAuth
+	var cfg struct {
+		Auths map[string]dockerAuth `json:"auths"`
+	}
+	if err := json.Unmarshal(data, &cfg); err == nil && cfg.Auths != nil {
+		auths = cfg.Auths
+	} else if err := json.Unmarshal(data, &auths); err != nil {
+		return nil, fmt.Errorf("invalid docker config: %w", err)
+	}
+
+	entries := make([]DockerConfigEntry, 0, len(auths))
+	for host, a := range auths {
+		has := a.IdentityToken != "" || (a.Username != "" && a.Password != "")
+		if decoded, err := base64.StdEncoding.DecodeString(a.Auth); err == nil {
+			user, pass, ok := strings.Cut(string(decoded), ":")
+			has = has || (ok && user != "" && pass != "")
+		}
+		entries = append(entries, DockerConfigEntry{Host: host, HasCredentials: has})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Host < entries[j].Host })
+	return entries, nil
+}
+
+// RegistryHostMatches reports whether a docker config key (e.g. "https://index.docker.io/v1/") refers to the registry host.
+func RegistryHostMatches(key, registry string) bool {
+	host := strings.TrimPrefix(strings.TrimPrefix(key, "https://"), "http://")
+	host, _, _ = strings.Cut(host, "/")
+	return normalizeRegistryHost(host) == normalizeRegistryHost(registry)
+}
+
+func normalizeRegistryHost(host string) string {
+	switch host {
+	case "index.docker.io", "registry-1.docker.io", "registry.hub.docker.com":
+		return DefaultRegistry
+	}
+	return host
+}
@@ -0,0 +1,73 @@
+package util
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestClassifyImagePullError(t *testing.T) {
+	tests := map[string]string{
+		`Failed to pull image "nginx:nope": rpc error: code = NotFound desc = failed to resolve reference: docker.io/library/nginx:nope: not found`:  "ImageNotFound",
+		`failed to resolve reference "ghcr.io/org/app:v1": failed to authorize: 401 Unauthorized`:                                                    "Unauthorized",
+		`pull access denied for foo, repository does not exist or may require authorization`:                                                         "NotFoundOrUnauthorized",
+		`x509: certificate signed by unknown authority`:                                                                                              "TLS",
+		`dial tcp 10.0.0.5:443: i/o timeout`:                                                                                                         "Network",
+		`toomanyrequests: You have reached your pull rate limit`:                                                                                     "RateLimited",
+		`failed to pull and unpack image "ghcr.io/org/app@sha256:4014015d": failed to resolve reference: ghcr.io/org/app@sha256:4014015d: not found`: "ImageNotFound",
+		`failed to copy: httpReadSeeker: failed open: content at sha256:ab401cd not found`:                                                           "ImageNotFound",
+		`failed to pull "quay.io/org/app:v1": size 84013 bytes, digest sha256:9f401a mismatch`:                                                       "Unknown",
+		`Error response from daemon: denied: requested access to the resource is denied`:                                                             "Unauthorized",
+		`failed to fetch anonymous token: unexpected status: 401 Unauthorized`:                                                                       "Unauthorized",
+		`Back-off pulling image "nginx"`:                                                                                                             "Unknown",
+	}
+	for msg, want := range tests {
+		if got := ClassifyImagePullError(msg); got.Category != want {
+			t.Errorf("ClassifyImagePullError(%q) = %s, want %s", msg, got.Category, want)
+		}
+	}
+}
+
+func TestParseDockerConfig(t *testing.T) {
+	auth := base64.StdEncoding.EncodeToString([]byte("user:pass"))
+	data := []byte(`{"auths":{"https://index.docker.io/v1/":{"auth":"` + auth + `"},"ghcr.io":{"auth":""}}}`)
+	entries, err := ParseDockerConfig(data)
+	if err != nil {
+		t.Fatalf("ParseDockerConfig() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Host != "ghcr.io" || entries[0].HasCredentials {
+		t.Errorf("unexpected ghcr.io entry: %+v", entries[0])
+	}
+	if !entries[1].HasCredentials {
+		t.Errorf("expected docker hub entry to have credentials: %+v", entries[1])
+	}
+
+	legacy, err := ParseDockerConfig([]byte(`{"quay.io":{"username":"u","password":"p"}}`))
+	if err != nil || len(legacy) != 1 || !legacy[0].HasCredentials {
+		t.Errorf("legacy dockercfg not parsed: %+v, %v", legacy, err)
+	}
+
+	if _, err := ParseDockerConfig([]byte("not json")); err == nil {
+		t.Error("expected error for invalid config")
+	}
+}
+
+func TestRegistryHostMatches(t *testing.T) {
+	tests := []struct {
+		key, registry string
+		want          bool
+	}{
+		{"https://index.docker.io/v1/", "docker.io", true},
+		{"docker.io", "docker.io", true},
+		{"ghcr.io", "ghcr.io", true},
+		{"https://ghcr.io", "docker.io", false},
+		{"registry.example.com:5000", "registry.example.com:5000", true},
+	}
+	for _, tt := range tests {
+		if got := RegistryHostMatches(tt.key, tt.registry); got != tt.want {
+			t.Errorf("RegistryHostMatches(%q, %q) = %v, want %v", tt.key, tt.registry, got, tt.want)
+		}
+	}
+}